@@ -15,6 +15,7 @@ import (
 	"github.com/aptd3v/godock/pkg/godock/containeroptions"
 	"github.com/aptd3v/godock/pkg/godock/hostoptions"
 	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
 )
 
 func main() {
@@ -86,7 +87,7 @@ func Cleanup(client *godock.Client, container *container.ContainerConfig) {
 
 	// Remove the container
 	fmt.Println("Removing container...")
-	if err := client.ContainerRemove(ctx, container, true); err != nil {
+	if err := client.ContainerRemove(ctx, container, removeoptions.Force(), removeoptions.RemoveVolumes()); err != nil {
 		log.Fatalf("Failed to remove container: %v", err)
 	}
 
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"os/signal"
@@ -17,6 +16,7 @@ import (
 	"github.com/aptd3v/godock/pkg/godock/exec"
 	"github.com/aptd3v/godock/pkg/godock/execoptions"
 	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
 )
 
 func main() {
@@ -31,15 +31,9 @@ func main() {
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
 	img := image.NewConfig("alpine")
-	rc, err := client.ImagePull(ctx, img)
-	if err != nil {
+	if err := client.EnsureImage(ctx, img, godock.PullPolicyIfNotPresent); err != nil {
 		log.Fatalf("failed to pull image: %v", err)
 	}
-	defer rc.Close()
-	_, err = io.Copy(os.Stdout, rc)
-	if err != nil {
-		log.Fatalf("failed to copy logs: %v", err)
-	}
 	commitContainer := container.NewConfig("commit-test")
 	commitContainer.SetContainerOptions(
 		containeroptions.Image(img),
@@ -88,6 +82,21 @@ func main() {
 	}
 	fmt.Println("commitId", commitId)
 
+	// Set GODOCK_PUSH_REF, e.g. localhost:5000/commit-test:latest, to also push a snapshot of the container.
+	if pushRef := os.Getenv("GODOCK_PUSH_REF"); pushRef != "" {
+		res, err := client.CommitAndPush(ctx, commitContainer, pushRef,
+			godock.WithCommitOptions(commitoptions.Comment("testing commit and push")),
+			godock.WithCommitAndPushProgress(func(p godock.ImageProgress) {
+				fmt.Println(p.ID, p.Status)
+			}),
+		)
+		if err != nil {
+			cleanup(client, commitContainer)
+			log.Fatalf("failed to commit and push container: %v", err)
+		}
+		fmt.Println("pushed", res.Tag, res.Digest)
+	}
+
 	cleanup(client, commitContainer)
 
 	// Create new container from committed image
@@ -141,7 +150,7 @@ func main() {
 func cleanup(client *godock.Client, container *container.ContainerConfig) {
 	ctx := context.Background()
 	fmt.Println("\nCleaning up...")
-	if err := client.ContainerRemove(ctx, container, true); err != nil {
+	if err := client.ContainerRemove(ctx, container, removeoptions.Force(), removeoptions.RemoveVolumes()); err != nil {
 		log.Printf("Failed to remove container: %v", err)
 	}
 }
@@ -50,10 +50,14 @@ func (a *API) runContainer(w http.ResponseWriter, r *http.Request) {
 
 	// Setup container
 	name := req.Name + "_" + uuid.NewString()
-	img := image.NewConfig(req.Image)
+	img, err := image.ParseConfig(req.Image)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid image: %v", err), http.StatusBadRequest)
+		return
+	}
 
-	// Pull image silently
-	if _, err := a.client.ImagePull(context.Background(), img); err != nil {
+	// Pull image unless it is already cached
+	if err := a.client.EnsureImage(context.Background(), img, godock.PullPolicyIfNotPresent); err != nil {
 		http.Error(w, fmt.Sprintf("Failed to pull image: %v", err), http.StatusInternalServerError)
 		return
 	}
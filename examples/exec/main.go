@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"os/signal"
@@ -15,6 +14,7 @@ import (
 	"github.com/aptd3v/godock/pkg/godock/exec"
 	"github.com/aptd3v/godock/pkg/godock/execoptions"
 	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
 )
 
 func main() {
@@ -27,12 +27,7 @@ func main() {
 	// Pull Ubuntu image
 	ubuntuImage := image.NewConfig("ubuntu")
 	fmt.Println(ubuntuImage.Ref)
-	rc, err := client.ImagePull(ctx, ubuntuImage)
-	if err != nil {
-		log.Fatal(err)
-	}
-	_, err = io.Copy(os.Stdout, rc)
-	if err != nil {
+	if err := client.EnsureImage(ctx, ubuntuImage, godock.PullPolicyIfNotPresent); err != nil {
 		log.Fatal(err)
 	}
 
@@ -95,7 +90,7 @@ func cleanup(client *godock.Client, containerConfig *container.ContainerConfig)
 		log.Printf("Failed to stop container: %v", err)
 	}
 
-	if err := client.ContainerRemove(ctx, containerConfig, true); err != nil {
+	if err := client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes()); err != nil {
 		log.Printf("Failed to remove container: %v", err)
 	}
 
@@ -12,6 +12,7 @@ import (
 	"github.com/aptd3v/godock/pkg/godock/containeroptions"
 	"github.com/aptd3v/godock/pkg/godock/hostoptions"
 	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
 )
 
 func main() {
@@ -21,15 +22,9 @@ func main() {
 		log.Fatalf("failed to create client: %v", err)
 	}
 	image := image.NewConfig("alpine")
-	rc, err := client.ImagePull(ctx, image)
-	if err != nil {
+	if err := client.EnsureImage(ctx, image, godock.PullPolicyIfNotPresent); err != nil {
 		log.Fatalf("failed to pull image: %v", err)
 	}
-	defer rc.Close()
-	_, err = io.Copy(os.Stdout, rc)
-	if err != nil {
-		log.Fatalf("failed to copy logs: %v", err)
-	}
 	container := container.NewConfig("export-container-tar-test")
 	container.SetContainerOptions(
 		containeroptions.Image(image),
@@ -62,10 +57,15 @@ func main() {
 		log.Fatalf("failed to write export: %v", err)
 	}
 
+	//export only /etc, gzip compressed
+	if err := client.ExportToFile(ctx, container, "export-etc.tar.gz", godock.ExportPaths("/etc")); err != nil {
+		log.Fatalf("failed to export /etc: %v", err)
+	}
+
 	//load the image from the exported tar file
 	exec.Command("docker", "load", "-i", "export.tar").Run()
 
-	if err := client.ContainerRemove(ctx, container, true); err != nil {
+	if err := client.ContainerRemove(ctx, container, removeoptions.Force(), removeoptions.RemoveVolumes()); err != nil {
 		log.Fatalf("failed to remove container: %v", err)
 	}
 }
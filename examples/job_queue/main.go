@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/jobs"
+)
+
+func main() {
+	ctx := context.Background()
+	client, err := godock.NewClient(ctx)
+	if err != nil {
+		log.Fatalf("failed to create client: %v", err)
+	}
+	img := image.NewConfig("alpine")
+	if err := client.EnsureImage(ctx, img, godock.PullPolicyIfNotPresent); err != nil {
+		log.Fatalf("failed to pull image: %v", err)
+	}
+
+	// Run 10 containers, at most 3 at a time, retrying failures twice.
+	queue := jobs.New(client,
+		jobs.WithConcurrency(3),
+		jobs.WithRetry(2, time.Second),
+		jobs.OnUpdate(func(status jobs.Status) {
+			fmt.Printf("%s: %s\n", status.ID, status.State)
+		}),
+	)
+	for i := 0; i < 10; i++ {
+		c := container.NewConfig(fmt.Sprintf("job-queue-example-%d", i))
+		c.SetContainerOptions(
+			containeroptions.Image(img),
+			containeroptions.CMD("sh", "-c", fmt.Sprintf("sleep %d && echo job %d done", i%4, i)),
+		)
+		if _, err := queue.Submit(ctx, c); err != nil {
+			log.Fatalf("failed to submit job: %v", err)
+		}
+	}
+
+	statuses, err := queue.Wait(ctx)
+	if err != nil {
+		log.Fatalf("failed to wait for jobs: %v", err)
+	}
+	for _, status := range statuses {
+		fmt.Printf("Job: %s\n", status.ID)
+		fmt.Printf("  State: %s after %d attempt(s)\n", status.State, status.Attempts)
+		fmt.Printf("  Exit code: %d\n", status.ExitCode)
+		fmt.Printf("  Output: %s", status.Stdout)
+	}
+}
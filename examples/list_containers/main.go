@@ -3,9 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
-	"os"
 
 	"github.com/aptd3v/godock/pkg/godock"
 	"github.com/aptd3v/godock/pkg/godock/container"
@@ -21,15 +19,9 @@ func main() {
 		log.Fatalf("failed to create client: %v", err)
 	}
 	img := image.NewConfig("alpine")
-	rc, err := client.ImagePull(ctx, img)
-	if err != nil {
+	if err := client.EnsureImage(ctx, img, godock.PullPolicyIfNotPresent); err != nil {
 		log.Fatalf("failed to pull image: %v", err)
 	}
-	defer rc.Close()
-	_, err = io.Copy(os.Stdout, rc)
-	if err != nil {
-		log.Fatalf("failed to copy logs: %v", err)
-	}
 	containerConfigs := make([]*container.ContainerConfig, 10)
 	for i := 0; i < 10; i++ {
 		c := container.NewConfig(fmt.Sprintf("container-list-test-%d", i))
@@ -54,7 +46,7 @@ func main() {
 	}
 
 	containerList, err := client.ContainerList(ctx,
-		godock.WithContainerFilter("status", "running"),
+		godock.WithContainerFilters(godock.FilterByStatus(godock.StatusRunning)),
 		godock.WithContainerLimit(5),
 	)
 	if err != nil {
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"io"
 	"log"
 	"os"
 	"os/signal"
@@ -18,6 +17,7 @@ import (
 	"github.com/aptd3v/godock/pkg/godock/network"
 	"github.com/aptd3v/godock/pkg/godock/networkoptions"
 	"github.com/aptd3v/godock/pkg/godock/networkoptions/endpointoptions"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
 )
 
 func main() {
@@ -29,11 +29,18 @@ func main() {
 	mongoDataDir := filepath.Join(projectRoot, "data", "mongodb")
 
 	// Create MongoDB data directory with all parent directories
-	if err := os.MkdirAll(mongoDataDir, 0755); err != nil {
+	dataMount, err := hostoptions.MountValidated(mongoDataDir, "/data/db", false, hostoptions.CreateSource(0755))
+	if err != nil {
 		log.Fatalf("Failed to create MongoDB data directory: %v", err)
 	}
 	log.Printf("MongoDB data will be stored in: %s\n", mongoDataDir)
 
+	// Set memory limit (2GB)
+	memoryLimit, err := hostoptions.MemoryString("2g")
+	if err != nil {
+		log.Fatalf("Invalid memory limit: %v", err)
+	}
+
 	// Create a context that we can cancel on interrupt
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -67,15 +74,9 @@ func main() {
 
 	// Pull MongoDB image
 	image := image.NewConfig("mongo")
-	rc, err := client.ImagePull(ctx, image)
-	if err != nil {
+	if err := client.EnsureImage(ctx, image, godock.PullPolicyIfNotPresent); err != nil {
 		log.Fatalf("Failed to pull image: %v", err)
 	}
-	defer rc.Close()
-	_, err = io.Copy(os.Stdout, rc)
-	if err != nil {
-		log.Fatalf("failed to copy logs: %v", err)
-	}
 
 	// Create MongoDB container configuration
 	mongo := container.NewConfig("mongodb-server")
@@ -113,16 +114,10 @@ func main() {
 		// Set restart policy
 		hostoptions.RestartAlways(),
 
-		// Set memory limit (2GB)
-		hostoptions.Memory(2*1024*1024*1024),
+		memoryLimit,
 
-		// Create a bind mount for easy host access using absolute path
-		hostoptions.Mount(
-			hostoptions.MountType("bind"),
-			mongoDataDir, // absolute host path
-			"/data/db",   // container path
-			false,        // read-only
-		),
+		// Bind mount the data directory for easy host access
+		dataMount,
 
 		// Connect to the mongo network
 		hostoptions.NetworkMode("bridge"),
@@ -148,7 +143,7 @@ func main() {
 		if err := client.ContainerStop(cleanupCtx, mongo); err != nil {
 			log.Printf("Warning: Failed to stop container: %v", err)
 		}
-		if err := client.ContainerRemove(cleanupCtx, mongo, true); err != nil {
+		if err := client.ContainerRemove(cleanupCtx, mongo, removeoptions.Force(), removeoptions.RemoveVolumes()); err != nil {
 			log.Printf("Warning: Failed to remove container: %v", err)
 		}
 
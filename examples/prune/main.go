@@ -3,9 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
-	"os"
 
 	"github.com/aptd3v/godock/pkg/godock"
 	"github.com/aptd3v/godock/pkg/godock/container"
@@ -20,15 +18,9 @@ func main() {
 		log.Fatalf("failed to create client: %v", err)
 	}
 	img := image.NewConfig("alpine")
-	rc, err := client.ImagePull(ctx, img)
-	if err != nil {
+	if err := client.EnsureImage(ctx, img, godock.PullPolicyIfNotPresent); err != nil {
 		log.Fatalf("failed to pull image: %v", err)
 	}
-	defer rc.Close()
-	_, err = io.Copy(os.Stdout, rc)
-	if err != nil {
-		log.Fatalf("failed to copy logs: %v", err)
-	}
 
 	container := container.NewConfig("prune-test")
 	container.SetContainerOptions(
@@ -39,10 +31,16 @@ func main() {
 		log.Fatalf("failed to create container: %v", err)
 	}
 
-	prune, err := client.ContainerPrune(ctx, godock.WithPruneFilter("label", "prune-test"))
+	prune, err := client.ContainerPrune(ctx, godock.WithPruneFilters(godock.FilterByLabel("prune-test", "")))
 	if err != nil {
 		log.Fatalf("failed to prune containers: %v", err)
 	}
 
 	fmt.Printf("successfully pruned containers: %v.\nspace reclaimed: %v\n", prune.ContainersDeleted, prune.SpaceReclaimed)
+
+	networks, err := client.NetworkPrune(ctx, godock.WithPruneFilters(godock.FilterByLabel("prune-test", "")))
+	if err != nil {
+		log.Fatalf("failed to prune networks: %v", err)
+	}
+	fmt.Printf("successfully pruned networks: %v\n", networks.NetworksDeleted)
 }
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"io"
 	"log"
 	"os"
 	"os/signal"
@@ -17,6 +16,7 @@ import (
 	"github.com/aptd3v/godock/pkg/godock/network"
 	"github.com/aptd3v/godock/pkg/godock/networkoptions"
 	"github.com/aptd3v/godock/pkg/godock/networkoptions/endpointoptions"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
 )
 
 func main() {
@@ -53,15 +53,9 @@ func main() {
 
 	// Pull Redis image
 	image := image.NewConfig("redis")
-	rc, err := client.ImagePull(ctx, image)
-	if err != nil {
+	if err := client.EnsureImage(ctx, image, godock.PullPolicyIfNotPresent); err != nil {
 		log.Fatalf("Failed to pull image: %v", err)
 	}
-	defer rc.Close()
-	_, err = io.Copy(os.Stdout, rc)
-	if err != nil {
-		log.Fatalf("failed to copy logs: %v", err)
-	}
 	// Create Redis container configuration
 	redis := container.NewConfig("redis-server")
 
@@ -90,6 +84,12 @@ func main() {
 		containeroptions.WorkingDir("/data"),
 	)
 
+	// Set memory limit (1GB)
+	memoryLimit, err := hostoptions.MemoryString("1g")
+	if err != nil {
+		log.Fatalf("Invalid memory limit: %v", err)
+	}
+
 	// Set host options
 	redis.SetHostOptions(
 		// Configure port binding
@@ -98,8 +98,7 @@ func main() {
 		// Set restart policy
 		hostoptions.RestartAlways(),
 
-		// Set memory limit (1GB)
-		hostoptions.Memory(1*1024*1024*1024),
+		memoryLimit,
 
 		// Mount volume for persistence
 		hostoptions.Mount(hostoptions.MountType("volume"), "redis-data", "/data", false),
@@ -128,7 +127,7 @@ func main() {
 		if err := client.ContainerStop(cleanupCtx, redis); err != nil {
 			log.Printf("Warning: Failed to stop container: %v", err)
 		}
-		if err := client.ContainerRemove(cleanupCtx, redis, true); err != nil {
+		if err := client.ContainerRemove(cleanupCtx, redis, removeoptions.Force(), removeoptions.RemoveVolumes()); err != nil {
 			log.Printf("Warning: Failed to remove container: %v", err)
 		}
 
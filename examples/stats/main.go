@@ -2,23 +2,38 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/aptd3v/godock/pkg/godock"
 	"github.com/aptd3v/godock/pkg/godock/container"
 	"github.com/aptd3v/godock/pkg/godock/containeroptions"
 	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
 )
 
 func main() {
+	format := flag.String("format", "table", "output format: table, csv or json")
+	flag.Parse()
+
+	var encoder godock.StatsEncoder
+	switch *format {
+	case "table":
+		encoder = godock.StatsTableWriter(os.Stdout)
+	case "csv":
+		encoder = godock.StatsCSVWriter(os.Stdout)
+	case "json":
+		encoder = godock.StatsJSONLWriter(os.Stdout)
+	default:
+		log.Fatalf("Unknown format %q", *format)
+	}
+
 	ctx := context.Background()
-	fmt.Println("Starting stats example...")
+	fmt.Fprintln(os.Stderr, "Starting stats example...")
 	// Create a new Docker client
 	client, err := godock.NewClient(ctx)
 	if err != nil {
@@ -28,15 +43,9 @@ func main() {
 	// Configure and pull Alpine image
 	alpineImg := image.NewConfig("alpine")
 
-	rc, err := client.ImagePull(ctx, alpineImg)
-	if err != nil {
+	if err := client.EnsureImage(ctx, alpineImg, godock.PullPolicyIfNotPresent); err != nil {
 		log.Fatalf("Failed to pull image: %v", err)
 	}
-	defer rc.Close()
-	_, err = io.Copy(os.Stdout, rc)
-	if err != nil {
-		log.Fatalf("failed to copy logs: %v", err)
-	}
 
 	// Configure the container to do some work
 	container := container.NewConfig("stats-test")
@@ -47,7 +56,7 @@ func main() {
 	)
 
 	// Create and start the container
-	fmt.Println("Creating and starting container...")
+	fmt.Fprintln(os.Stderr, "Creating and starting container...")
 	if err := client.ContainerCreate(ctx, container); err != nil {
 		log.Fatalf("Failed to create container: %v", err)
 	}
@@ -67,18 +76,18 @@ func main() {
 	// Get stats stream
 	statsCh, errCh := client.ContainerStatsChan(ctx, container)
 
-	// Print stats every second
-	fmt.Println("Monitoring container stats (Ctrl+C to exit)...")
+	// Write stats as they arrive, about every second
+	fmt.Fprintln(os.Stderr, "Monitoring container stats (Ctrl+C to exit)...")
 	for {
 		select {
 		case stats, ok := <-statsCh:
 			if !ok {
 				return
 			}
-			fmt.Printf("\nContainer Stats at %s:\n", time.Now().Format(time.RFC3339))
-			fmt.Printf("CPU Usage: %s\n", stats.FormatCpuUsagePercentage())
-			fmt.Printf("Memory Usage: %s\n", stats.FormatMemoryUsage())
-			fmt.Printf("Network I/O: %s\n", stats.FormatNetworkIO())
+			if err := encoder.Encode(stats); err != nil {
+				log.Printf("Failed to write stats: %v\n", err)
+				return
+			}
 		case err := <-errCh:
 			if err != nil {
 				log.Printf("Stats error: %v\n", err)
@@ -92,8 +101,8 @@ func main() {
 
 func cleanup(client *godock.Client, container *container.ContainerConfig) {
 	ctx := context.Background()
-	fmt.Println("\nCleaning up...")
-	if err := client.ContainerRemove(ctx, container, true); err != nil {
+	fmt.Fprintln(os.Stderr, "\nCleaning up...")
+	if err := client.ContainerRemove(ctx, container, removeoptions.Force(), removeoptions.RemoveVolumes()); err != nil {
 		log.Printf("Failed to remove container: %v", err)
 	}
 }
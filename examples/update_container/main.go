@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"os/signal"
@@ -13,6 +12,7 @@ import (
 	"github.com/aptd3v/godock/pkg/godock/container"
 	"github.com/aptd3v/godock/pkg/godock/containeroptions"
 	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
 	"github.com/aptd3v/godock/pkg/godock/updateoptions"
 )
 
@@ -23,15 +23,9 @@ func main() {
 		log.Fatalf("failed to create client: %v", err)
 	}
 	img := image.NewConfig("alpine")
-	rc, err := client.ImagePull(ctx, img)
-	if err != nil {
+	if err := client.EnsureImage(ctx, img, godock.PullPolicyIfNotPresent); err != nil {
 		log.Fatalf("failed to pull image: %v", err)
 	}
-	defer rc.Close()
-	_, err = io.Copy(os.Stdout, rc)
-	if err != nil {
-		log.Fatalf("failed to copy logs: %v", err)
-	}
 	container := container.NewConfig("test-container")
 	container.SetContainerOptions(
 		containeroptions.Image(img),
@@ -73,7 +67,7 @@ func main() {
 }
 func cleanup(client *godock.Client, container *container.ContainerConfig) {
 	ctx := context.Background()
-	if err := client.ContainerRemove(ctx, container, true); err != nil {
+	if err := client.ContainerRemove(ctx, container, removeoptions.Force(), removeoptions.RemoveVolumes()); err != nil {
 		log.Fatalf("failed to remove container: %v", err)
 	}
 }
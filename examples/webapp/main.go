@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"io"
 	"log"
 	"os"
 	"os/signal"
@@ -18,6 +17,8 @@ import (
 	"github.com/aptd3v/godock/pkg/godock/network"
 	"github.com/aptd3v/godock/pkg/godock/networkoptions"
 	"github.com/aptd3v/godock/pkg/godock/networkoptions/endpointoptions"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/aptd3v/godock/pkg/godock/stopoptions"
 )
 
 type containerConfig struct {
@@ -64,14 +65,13 @@ func main() {
 	}
 
 	// Create data directories
-	dirs := []string{
-		filepath.Join(projectRoot, "data", "mongodb"),
-		filepath.Join(projectRoot, "data", "redis"),
+	mongoDataMount, err := hostoptions.MountValidated(filepath.Join(projectRoot, "data", "mongodb"), "/data/db", false, hostoptions.CreateSource(0755))
+	if err != nil {
+		log.Fatalf("Failed to create data directory: %v", err)
 	}
-	for _, dir := range dirs {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			log.Fatalf("Failed to create data directory %s: %v", dir, err)
-		}
+	redisDataMount, err := hostoptions.MountValidated(filepath.Join(projectRoot, "data", "redis"), "/data", false, hostoptions.CreateSource(0755))
+	if err != nil {
+		log.Fatalf("Failed to create data directory: %v", err)
 	}
 
 	// Initialize containers
@@ -96,12 +96,7 @@ func main() {
 		hostoptions.PortBindings("127.0.0.1", "27017", "27017"),
 		hostoptions.RestartAlways(),
 		hostoptions.Memory(1*1024*1024*1024),
-		hostoptions.Mount(
-			hostoptions.MountType("bind"),
-			filepath.Join(projectRoot, "data", "mongodb"),
-			"/data/db",
-			false,
-		),
+		mongoDataMount,
 		hostoptions.NetworkMode("bridge"),
 	)
 	containers["mongodb"] = &containerConfig{container: mongoDB, image: mongoImage}
@@ -125,12 +120,7 @@ func main() {
 		hostoptions.PortBindings("127.0.0.1", "6379", "6379"),
 		hostoptions.RestartAlways(),
 		hostoptions.Memory(512*1024*1024),
-		hostoptions.Mount(
-			hostoptions.MountType("bind"),
-			filepath.Join(projectRoot, "data", "redis"),
-			"/data",
-			false,
-		),
+		redisDataMount,
 		hostoptions.NetworkMode("bridge"),
 	)
 	containers["redis"] = &containerConfig{container: redis, image: redisImage}
@@ -169,21 +159,20 @@ func main() {
 		)
 	}
 
-	// Pull images and create containers
+	// Pull the images concurrently, skipping any that are already cached
+	log.Println("Pulling images...")
+	if _, err := client.ImagePullAll(ctx,
+		[]*image.ImageConfig{mongoImage, redisImage, nginxImage},
+		godock.WithConcurrency(3),
+		godock.WithPullAllPolicy(godock.PullPolicyIfNotPresent),
+	); err != nil {
+		log.Fatalf("Failed to pull images: %v", err)
+	}
+
+	// Create and start containers
 	for name, cfg := range containers {
 		log.Printf("Setting up %s...", name)
 
-		// Pull image
-		rc, err := client.ImagePull(ctx, cfg.image)
-		if err != nil {
-			log.Fatalf("Failed to pull %s image: %v", name, err)
-		}
-
-		_, err = io.Copy(os.Stdout, rc)
-		if err != nil {
-			log.Fatalf("Failed to pull image %s\n", err)
-		}
-
 		// Create container
 		if err := client.ContainerCreate(ctx, cfg.container); err != nil {
 			log.Fatalf("Failed to create %s container: %v", name, err)
@@ -202,12 +191,23 @@ func main() {
 		cleanupCtx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 		defer cancel()
 
-		// Stop and remove containers
+		// Stop the frontend before the services it depends on
+		stack := make([]*container.ContainerConfig, 0, len(containers))
+		for _, cfg := range containers {
+			stack = append(stack, cfg.container)
+		}
+		if err := client.StopAll(cleanupCtx, stack,
+			stopoptions.Timeout(10),
+			stopoptions.ContainerTimeout("mongodb", 15),
+			stopoptions.DependsOn("frontend", "mongodb", "redis"),
+			stopoptions.ContinueOnError(),
+		); err != nil {
+			log.Printf("Warning: Failed to stop containers: %v", err)
+		}
+
+		// Remove containers
 		for name, cfg := range containers {
-			if err := client.ContainerStop(cleanupCtx, cfg.container); err != nil {
-				log.Printf("Warning: Failed to stop %s container: %v", name, err)
-			}
-			if err := client.ContainerRemove(cleanupCtx, cfg.container, true); err != nil {
+			if err := client.ContainerRemove(cleanupCtx, cfg.container, removeoptions.Force(), removeoptions.RemoveVolumes()); err != nil {
 				log.Printf("Warning: Failed to remove %s container: %v", name, err)
 			}
 		}
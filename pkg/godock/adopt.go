@@ -0,0 +1,115 @@
+package godock
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/docker/docker/api/types"
+	dockerNetwork "github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+)
+
+/*
+ContainerFromName adopts an existing container, e.g. one created by docker run or compose, so that it can be
+managed through the client. The returned config has the Id and Name of the container, and its container, host
+and networking options are reconstructed from its inspect, so it can also be recreated with ContainerRecreate.
+It returns a ResourceNotFoundError when no container has the name.
+
+Usage example:
+
+	db, err := client.ContainerFromName(ctx, "postgres")
+	if err != nil {
+		return err
+	}
+	running, err := client.IsContainerRunning(ctx, db)
+*/
+func (c *Client) ContainerFromName(ctx context.Context, name string) (*container.ContainerConfig, error) {
+	name = strings.TrimPrefix(name, "/")
+	if name == "" {
+		return nil, &errdefs.ValidationError{Field: "name", Message: "container name cannot be empty"}
+	}
+	inspect, err := c.inspectContainer(ctx, name)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return nil, &errdefs.ResourceNotFoundError{ResourceType: "container", ID: name, Err: err}
+		}
+		return nil, &errdefs.ContainerError{ID: name, Op: "adopt", Message: err.Error(), Err: err}
+	}
+	return configFromInspect(inspect), nil
+}
+
+/*
+ContainersFromLabelSelector adopts every container, running or not, matching a label selector, sorted by name.
+The selector has the format of ContainerInspectByLabel. See ContainerFromName for the returned configs.
+
+Usage example:
+
+	workers, err := client.ContainersFromLabelSelector(ctx, "com.example.role=worker")
+	if err != nil {
+		return err
+	}
+	err = client.StopAll(ctx, workers)
+*/
+func (c *Client) ContainersFromLabelSelector(ctx context.Context, selector string) ([]*container.ContainerConfig, error) {
+	inspects, err := c.ContainerInspectByLabel(ctx, selector)
+	if err != nil {
+		return nil, err
+	}
+	names := slices.Collect(maps.Keys(inspects))
+	sort.Strings(names)
+	configs := make([]*container.ContainerConfig, 0, len(names))
+	for _, name := range names {
+		configs = append(configs, configFromInspect(inspects[name]))
+	}
+	return configs, nil
+}
+
+// configFromInspect reconstructs the config a container was created from.
+func configFromInspect(inspect types.ContainerJSON) *container.ContainerConfig {
+	containerConfig := container.NewConfig("")
+	if inspect.ContainerJSONBase != nil {
+		containerConfig.Id = inspect.ID
+		containerConfig.Name = strings.TrimPrefix(inspect.Name, "/")
+		if inspect.HostConfig != nil {
+			hostConfig := *inspect.HostConfig
+			containerConfig.HostOptions = &hostConfig
+		}
+	}
+	if inspect.Config != nil {
+		options := *inspect.Config
+		options.Labels = maps.Clone(options.Labels)
+		options.Env = slices.Clone(options.Env)
+		containerConfig.Options = &options
+	}
+	if !containerConfig.HostOptions.NetworkMode.IsContainer() {
+		containerConfig.NetworkingOptions = networkingFromInspect(inspect)
+	}
+	return containerConfig
+}
+
+// networkingFromInspect returns the networks a container is connected to with the endpoint settings chosen when
+// connecting it, leaving out the ones assigned by the daemon such as its addresses.
+func networkingFromInspect(inspect types.ContainerJSON) *dockerNetwork.NetworkingConfig {
+	networking := &dockerNetwork.NetworkingConfig{}
+	if inspect.NetworkSettings == nil {
+		return networking
+	}
+	networking.EndpointsConfig = make(map[string]*dockerNetwork.EndpointSettings, len(inspect.NetworkSettings.Networks))
+	for networkName, endpoint := range inspect.NetworkSettings.Networks {
+		if endpoint == nil {
+			continue
+		}
+		networking.EndpointsConfig[networkName] = &dockerNetwork.EndpointSettings{
+			IPAMConfig: endpoint.IPAMConfig,
+			Links:      endpoint.Links,
+			Aliases:    endpoint.Aliases,
+			DriverOpts: endpoint.DriverOpts,
+		}
+	}
+	return networking
+}
@@ -0,0 +1,70 @@
+package godock
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const adoptedInspect = `{"Id":"abc123","Name":"/postgres","State":{"Running":true},
+"Config":{"Image":"postgres:16","Env":["POSTGRES_DB=app"],"Labels":{"com.example.role":"db"}},
+"HostConfig":{"NetworkMode":"backend","RestartPolicy":{"Name":"unless-stopped"}},
+"NetworkSettings":{"Networks":{"backend":{"Aliases":["db"],"IPAddress":"172.18.0.2"}}}}`
+
+func TestContainerFromName(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1.45/containers/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("id") {
+		case "postgres", "abc123":
+			io.WriteString(w, adoptedInspect)
+		case "sidecar":
+			io.WriteString(w, `{"Id":"def456","Name":"/sidecar","Config":{"Image":"envoy"},"HostConfig":{"NetworkMode":"container:abc123"},
+"NetworkSettings":{"Networks":{}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message":"No such container"}`)
+		}
+	})
+	mux.HandleFunc("GET /v1.45/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `[{"Id":"sidecar"},{"Id":"abc123"}]`)
+	})
+	c := newFakeDaemonClient(t, mux)
+	ctx := context.Background()
+
+	db, err := c.ContainerFromName(ctx, "/postgres")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", db.Id)
+	assert.Equal(t, "postgres", db.Name)
+	assert.Equal(t, "postgres:16", db.Options.Image)
+	assert.Equal(t, []string{"POSTGRES_DB=app"}, db.Options.Env)
+	assert.Equal(t, "unless-stopped", string(db.HostOptions.RestartPolicy.Name))
+	require.Contains(t, db.NetworkingOptions.EndpointsConfig, "backend")
+	endpoint := db.NetworkingOptions.EndpointsConfig["backend"]
+	assert.Equal(t, []string{"db"}, endpoint.Aliases)
+	assert.Empty(t, endpoint.IPAddress, "addresses assigned by the daemon are left out")
+	assert.NotNil(t, db.PlatformOptions)
+
+	t.Run("Missing container", func(t *testing.T) {
+		_, err := c.ContainerFromName(ctx, "nope")
+		var notFound *errdefs.ResourceNotFoundError
+		require.ErrorAs(t, err, &notFound)
+		assert.Equal(t, "nope", notFound.ID)
+
+		_, err = c.ContainerFromName(ctx, "")
+		assert.True(t, errdefs.IsInvalidConfig(err))
+	})
+
+	t.Run("By label selector", func(t *testing.T) {
+		configs, err := c.ContainersFromLabelSelector(ctx, "com.example.stack=shop")
+		require.NoError(t, err)
+		require.Len(t, configs, 2)
+		assert.Equal(t, "postgres", configs[0].Name, "configs are sorted by name")
+		assert.Equal(t, "sidecar", configs[1].Name)
+		assert.Empty(t, configs[1].NetworkingOptions.EndpointsConfig, "containers sharing a network namespace have no endpoints")
+	})
+}
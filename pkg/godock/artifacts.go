@@ -0,0 +1,315 @@
+package godock
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/docker/docker/client"
+)
+
+type artifactOptions struct {
+	onFailure bool
+}
+
+// ArtifactOptionFn is a function that configures ExtractArtifact and ExtractArtifactTo.
+type ArtifactOptionFn func(*artifactOptions)
+
+// ExtractOnFailure collects the artifacts of a container that exited with a non-zero code, e.g. test reports of a
+// failed test run. By default extracting from a failed container returns its exit as an error.
+func ExtractOnFailure() ArtifactOptionFn {
+	return func(opts *artifactOptions) {
+		opts.onFailure = true
+	}
+}
+
+// artifactEntry is a tar entry matching an artifact path, named relative to the extraction destination.
+type artifactEntry struct {
+	header *tar.Header
+	name   string
+}
+
+/*
+ExtractArtifact copies files out of a finished container into destDir on the client host and returns the paths of
+the extracted files. containerPath is an absolute file or directory, which is extracted below destDir under its
+base name like docker cp does, or a pattern like "/build/dist/*.tar.gz" whose matches are extracted below destDir.
+Patterns use the syntax of path.Match in any element of the path.
+
+The container must not have failed, unless ExtractOnFailure is given. Entries whose name or link target would
+leave destDir, or that would be written through a link leaving it, are skipped.
+
+Usage example:
+
+	if err := client.RunAndWait(ctx, build); err != nil {
+		return err
+	}
+	files, err := client.ExtractArtifact(ctx, build, "/src/bin/*", "./dist")
+	if err != nil {
+		return err
+	}
+	fmt.Println("built", files)
+*/
+func (c *Client) ExtractArtifact(ctx context.Context, containerConfig *container.ContainerConfig, containerPath, destDir string, artifactOptionFns ...ArtifactOptionFn) ([]string, error) {
+	var files []string
+	err := c.walkArtifact(ctx, containerConfig, containerPath, artifactOptionFns, func(tr *tar.Reader, entry artifactEntry) error {
+		target := filepath.Join(destDir, filepath.FromSlash(entry.name))
+		written, err := extractArtifactEntry(tr, entry, destDir, target)
+		if written {
+			files = append(files, target)
+		}
+		return err
+	})
+	return files, err
+}
+
+/*
+ExtractArtifactTo writes the content of a single file of a finished container to w. containerPath is an absolute
+path or a pattern like ExtractArtifact takes, and must match exactly one regular file. A pattern matching several
+files fails once the second one is found, after the first was written to w.
+
+Usage example:
+
+	var report bytes.Buffer
+	err := client.ExtractArtifactTo(ctx, tests, "/src/report-*.xml", &report, godock.ExtractOnFailure())
+*/
+func (c *Client) ExtractArtifactTo(ctx context.Context, containerConfig *container.ContainerConfig, containerPath string, w io.Writer, artifactOptionFns ...ArtifactOptionFn) error {
+	found := false
+	return c.walkArtifact(ctx, containerConfig, containerPath, artifactOptionFns, func(tr *tar.Reader, entry artifactEntry) error {
+		if entry.header.Typeflag != tar.TypeReg {
+			return nil
+		}
+		if found {
+			return &errdefs.ValidationError{
+				Field:   "containerPath",
+				Message: containerPath + " matches more than one file, extract them to a directory with ExtractArtifact",
+			}
+		}
+		found = true
+		_, err := io.Copy(w, tr)
+		return err
+	})
+}
+
+// walkArtifact copies the archive of an artifact path out of a container and calls fn for every entry matching
+// the path, with tr positioned on the content of the entry.
+func (c *Client) walkArtifact(ctx context.Context, containerConfig *container.ContainerConfig, containerPath string, artifactOptionFns []ArtifactOptionFn,
+	fn func(tr *tar.Reader, entry artifactEntry) error) error {
+	opts := &artifactOptions{}
+	for _, set := range artifactOptionFns {
+		if set != nil {
+			set(opts)
+		}
+	}
+	if err := validateContainerConfig(containerConfig); err != nil {
+		return err
+	}
+	if !path.IsAbs(containerPath) {
+		return &errdefs.ValidationError{Field: "containerPath", Message: "artifact path must be absolute, got " + containerPath}
+	}
+	if !opts.onFailure {
+		info, err := c.ContainerExitInfo(ctx, containerConfig)
+		if err != nil {
+			return err
+		}
+		if !info.Running && info.ExitCode != 0 {
+			return &errdefs.ContainerError{ID: containerConfig.Name, Op: "extract artifact", Message: info.Explain(), Err: &ExitError{Info: info}}
+		}
+	}
+
+	dir, pattern := splitArtifactPath(path.Clean(containerPath))
+	rc, _, err := c.wrapped.CopyFromContainer(ctx, containerConfig.Id, dir)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return &errdefs.ResourceNotFoundError{ResourceType: "artifact", ID: containerPath, Err: err}
+		}
+		return &errdefs.ContainerError{ID: containerConfig.Name, Op: "extract artifact", Message: err.Error(), Err: err}
+	}
+	defer rc.Close()
+
+	// CopyFromContainer names entries relative to the parent of the copied path.
+	parent := path.Dir(dir)
+	matched := false
+	tr := tar.NewReader(rc)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name, ok := matchArtifact(path.Join(parent, header.Name), pattern)
+		if !ok {
+			continue
+		}
+		matched = true
+		if err := fn(tr, artifactEntry{header: header, name: name}); err != nil {
+			return err
+		}
+	}
+	if !matched {
+		return &errdefs.ResourceNotFoundError{ResourceType: "artifact", ID: containerPath}
+	}
+	return nil
+}
+
+// splitArtifactPath splits a cleaned artifact path into the longest directory without pattern characters, which is
+// copied out of the container, and the pattern its entries are matched against. The pattern is the path itself
+// when it has no pattern characters.
+func splitArtifactPath(p string) (dir, pattern string) {
+	elems := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	for i, elem := range elems {
+		if strings.ContainsAny(elem, `*?[\`) {
+			return "/" + path.Join(elems[:i]...), p
+		}
+	}
+	return p, p
+}
+
+// matchArtifact reports whether the absolute container path p is, or is below, a path matching pattern, and
+// returns its name relative to the parent of that path.
+func matchArtifact(p, pattern string) (string, bool) {
+	depth := strings.Count(pattern, "/")
+	elems := strings.SplitAfterN(p, "/", depth+2)
+	if len(elems) < depth+1 {
+		return "", false
+	}
+	root := strings.TrimSuffix(strings.Join(elems[:depth+1], ""), "/")
+	if ok, _ := path.Match(pattern, root); !ok {
+		return "", false
+	}
+	name := path.Base(root)
+	if len(elems) == depth+2 && elems[depth+1] != "" {
+		name = path.Join(name, elems[depth+1])
+	}
+	return name, true
+}
+
+// extractArtifactEntry writes an entry to target below destDir and reports whether it wrote a file.
+func extractArtifactEntry(tr *tar.Reader, entry artifactEntry, destDir, target string) (bool, error) {
+	if !filepath.IsLocal(filepath.FromSlash(entry.name)) {
+		return false, nil
+	}
+	header := entry.header
+	// Nothing is written through a link leaving destDir, whether it was there before or an earlier entry made it.
+	written := target
+	if header.Typeflag == tar.TypeSymlink {
+		written = filepath.Dir(target)
+	}
+	if inside, err := withinDir(destDir, written); err != nil || !inside {
+		return false, err
+	}
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return false, os.MkdirAll(target, 0o755)
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return false, err
+		}
+		file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, header.FileInfo().Mode().Perm())
+		if err != nil {
+			return false, err
+		}
+		if _, err := io.Copy(file, tr); err != nil {
+			file.Close()
+			return false, err
+		}
+		return true, file.Close()
+	case tar.TypeSymlink:
+		if inside, err := artifactLinkInside(destDir, target, header.Linkname); err != nil || !inside {
+			return false, err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return false, err
+		}
+		os.Remove(target)
+		return true, os.Symlink(header.Linkname, target)
+	}
+	// Hard links, devices and other special files are not artifacts.
+	return false, nil
+}
+
+/*
+artifactLinkInside reports whether a link at target pointing to linkname resolves below root. The link name must be
+relative and may only go up with leading ".." elements: links are resolved by the file system, not lexically, so a
+link like "a/.." leaves root when a is itself a link to ".".
+*/
+func artifactLinkInside(root, target, linkname string) (bool, error) {
+	if path.IsAbs(linkname) {
+		return false, nil
+	}
+	parent, err := resolvePath(filepath.Dir(target))
+	if err != nil {
+		return false, err
+	}
+	resolved, descended := parent, false
+	for _, elem := range strings.Split(linkname, "/") {
+		switch {
+		case elem == "" || elem == ".":
+		case elem == "..":
+			if descended {
+				return false, nil
+			}
+			resolved = filepath.Dir(resolved)
+		default:
+			descended = true
+			resolved = filepath.Join(resolved, elem)
+		}
+	}
+	return withinDir(root, resolved)
+}
+
+// withinDir reports whether p is below or at root once the links in the existing parts of both are resolved.
+func withinDir(root, p string) (bool, error) {
+	resolvedRoot, err := resolvePath(root)
+	if err != nil {
+		return false, err
+	}
+	resolved, err := resolvePath(p)
+	if err != nil {
+		return false, err
+	}
+	rel, err := filepath.Rel(resolvedRoot, resolved)
+	return err == nil && filepath.IsLocal(rel), nil
+}
+
+// resolvePath returns the absolute path p with the links of its longest existing prefix resolved. Dangling links are
+// followed to where a file written through them would be created.
+func resolvePath(p string) (string, error) {
+	p, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	existing, rest := p, ""
+	for links := 0; ; {
+		resolved, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			return filepath.Join(resolved, rest), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		if link, linkErr := os.Readlink(existing); linkErr == nil && links < 255 {
+			links++
+			if !filepath.IsAbs(link) {
+				link = filepath.Join(filepath.Dir(existing), link)
+			}
+			existing = link
+			continue
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return "", err
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+}
@@ -0,0 +1,196 @@
+package godock
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchArtifact(t *testing.T) {
+	tests := []struct {
+		path, pattern string
+		want          string
+		ok            bool
+	}{
+		{"/app/dist", "/app/dist", "dist", true},
+		{"/app/dist/js/main.js", "/app/dist", "dist/js/main.js", true},
+		{"/app", "/app/dist", "", false},
+		{"/app/distribution", "/app/dist", "", false},
+		{"/build/app.tar.gz", "/build/*.tar.gz", "app.tar.gz", true},
+		{"/build/app.zip", "/build/*.tar.gz", "", false},
+		{"/build/linux/bin/app", "/build/*/bin", "bin/app", true},
+	}
+	for _, tt := range tests {
+		got, ok := matchArtifact(tt.path, tt.pattern)
+		assert.Equal(t, tt.ok, ok, tt.path)
+		assert.Equal(t, tt.want, got, tt.path)
+	}
+
+	dir, pattern := splitArtifactPath("/build/*/bin")
+	assert.Equal(t, "/build", dir)
+	assert.Equal(t, "/build/*/bin", pattern)
+	dir, _ = splitArtifactPath("/app/dist")
+	assert.Equal(t, "/app/dist", dir)
+}
+
+func TestExtractArtifact(t *testing.T) {
+	exitCode := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1.45/containers/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"Id":"build","State":{"ExitCode":`+string(rune('0'+exitCode))+`,"FinishedAt":"2024-05-01T12:00:00Z"}}`)
+	})
+	// The container has these entries, each archived relative to the parent of the requested path.
+	entries := []*tar.Header{
+		{Name: "build/", Typeflag: tar.TypeDir, Mode: 0o755},
+		{Name: "build/app.tar.gz", Typeflag: tar.TypeReg, Mode: 0o644},
+		{Name: "build/app.zip", Typeflag: tar.TypeReg, Mode: 0o644},
+		{Name: "build/reports/", Typeflag: tar.TypeDir, Mode: 0o755},
+		{Name: "build/reports/unit.xml", Typeflag: tar.TypeReg, Mode: 0o644},
+		{Name: "build/reports/latest", Typeflag: tar.TypeSymlink, Linkname: "unit.xml"},
+		{Name: "build/reports/passwd", Typeflag: tar.TypeSymlink, Linkname: "../../../etc/passwd"},
+	}
+	mux.HandleFunc("GET /v1.45/containers/{id}/archive", func(w http.ResponseWriter, r *http.Request) {
+		dir := strings.TrimPrefix(r.URL.Query().Get("path"), "/")
+		if dir != "build" && dir != "build/reports" {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message":"Could not find the file in container build"}`)
+			return
+		}
+		w.Header().Set("X-Docker-Container-Path-Stat", base64.StdEncoding.EncodeToString([]byte(`{"name":"`+path.Base(dir)+`","mode":2147484141}`)))
+		tw := tar.NewWriter(w)
+		for _, entry := range entries {
+			if !strings.HasPrefix(entry.Name, dir+"/") {
+				continue
+			}
+			name := strings.TrimPrefix(entry.Name, strings.TrimPrefix(path.Dir(dir)+"/", "./"))
+			header := *entry
+			header.Name = name
+			if header.Typeflag == tar.TypeReg {
+				header.Size = int64(len(entry.Name))
+			}
+			tw.WriteHeader(&header)
+			io.WriteString(tw, entry.Name)
+		}
+		tw.Close()
+	})
+	c := newFakeDaemonClient(t, mux)
+	ctx := context.Background()
+	build := &container.ContainerConfig{Id: "build", Name: "build"}
+
+	t.Run("Pattern", func(t *testing.T) {
+		dest := t.TempDir()
+		files, err := c.ExtractArtifact(ctx, build, "/build/*.tar.gz", dest)
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(dest, "app.tar.gz")}, files)
+		data, err := os.ReadFile(files[0])
+		require.NoError(t, err)
+		assert.Equal(t, "build/app.tar.gz", string(data))
+	})
+
+	t.Run("Directory", func(t *testing.T) {
+		dest := t.TempDir()
+		files, err := c.ExtractArtifact(ctx, build, "/build", dest)
+		require.NoError(t, err)
+		assert.Len(t, files, 4)
+		link, err := os.Readlink(filepath.Join(dest, "build", "reports", "latest"))
+		require.NoError(t, err)
+		assert.Equal(t, "unit.xml", link)
+		_, err = os.Lstat(filepath.Join(dest, "build", "reports", "passwd"))
+		assert.True(t, os.IsNotExist(err), "links leaving the destination are skipped")
+	})
+
+	t.Run("To writer", func(t *testing.T) {
+		var report bytes.Buffer
+		require.NoError(t, c.ExtractArtifactTo(ctx, build, "/build/reports/*.xml", &report))
+		assert.Equal(t, "build/reports/unit.xml", report.String())
+
+		err := c.ExtractArtifactTo(ctx, build, "/build/app.*", io.Discard)
+		assert.True(t, errdefs.IsInvalidConfig(err))
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		_, err := c.ExtractArtifact(ctx, build, "/build/*.deb", t.TempDir())
+		assert.True(t, errdefs.IsNotFound(err))
+		_, err = c.ExtractArtifact(ctx, build, "/out", t.TempDir())
+		assert.True(t, errdefs.IsNotFound(err))
+		_, err = c.ExtractArtifact(ctx, build, "build", t.TempDir())
+		assert.True(t, errdefs.IsInvalidConfig(err))
+	})
+
+	t.Run("Failed container", func(t *testing.T) {
+		exitCode = 1
+		defer func() { exitCode = 0 }()
+		_, err := c.ExtractArtifact(ctx, build, "/build/reports/*.xml", t.TempDir())
+		var exit *ExitError
+		require.ErrorAs(t, err, &exit)
+		assert.Equal(t, 1, exit.Info.ExitCode)
+
+		files, err := c.ExtractArtifact(ctx, build, "/build/reports/*.xml", t.TempDir(), ExtractOnFailure())
+		require.NoError(t, err)
+		assert.Len(t, files, 1)
+	})
+}
+
+func TestExtractArtifactEntryTraversal(t *testing.T) {
+	parent := t.TempDir()
+	dest := filepath.Join(parent, "dest")
+	outside := t.TempDir()
+	require.NoError(t, os.MkdirAll(dest, 0o755))
+	// Links already in the destination are not written through either.
+	require.NoError(t, os.Symlink(outside, filepath.Join(dest, "out")))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "dangling"), filepath.Join(dest, "dangling")))
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, header := range []*tar.Header{
+		{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "."},
+		{Name: "e", Typeflag: tar.TypeSymlink, Linkname: "a/.."},
+		{Name: "e/pwned", Typeflag: tar.TypeReg, Mode: 0o644, Size: 6},
+		{Name: "out/pwned", Typeflag: tar.TypeReg, Mode: 0o644, Size: 6},
+		{Name: "dangling", Typeflag: tar.TypeReg, Mode: 0o644, Size: 6},
+		{Name: "sub/", Typeflag: tar.TypeDir, Mode: 0o755},
+		{Name: "sub/up", Typeflag: tar.TypeSymlink, Linkname: "../a"},
+	} {
+		require.NoError(t, tw.WriteHeader(header))
+		io.WriteString(tw, strings.Repeat("x", int(header.Size)))
+	}
+	require.NoError(t, tw.Close())
+
+	tr := tar.NewReader(&buf)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		target := filepath.Join(dest, filepath.FromSlash(header.Name))
+		_, err = extractArtifactEntry(tr, artifactEntry{header: header, name: header.Name}, dest, target)
+		require.NoError(t, err, header.Name)
+	}
+
+	_, err := os.Lstat(filepath.Join(parent, "pwned"))
+	assert.True(t, os.IsNotExist(err), "no file is written next to the destination")
+	_, err = os.Lstat(filepath.Join(outside, "pwned"))
+	assert.True(t, os.IsNotExist(err), "no file is written through a link in the destination")
+	_, err = os.Lstat(filepath.Join(outside, "dangling"))
+	assert.True(t, os.IsNotExist(err), "no file is created at the target of a dangling link")
+	info, err := os.Lstat(filepath.Join(dest, "e"))
+	require.NoError(t, err)
+	assert.True(t, info.IsDir(), "a link climbing through another link is skipped, its entries are written below it")
+	link, err := os.Readlink(filepath.Join(dest, "sub", "up"))
+	require.NoError(t, err)
+	assert.Equal(t, "../a", link, "links going up within the destination are kept")
+}
@@ -0,0 +1,392 @@
+package autoscale
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/aptd3v/godock/pkg/godock/statsmonitor"
+	dockerNetwork "github.com/docker/docker/api/types/network"
+)
+
+// LabelGroup is the label set on every replica of a controller, with the controller name.
+const LabelGroup = "godock.autoscale"
+
+const (
+	// DefaultInterval is the time between two evaluations of a controller by default.
+	DefaultInterval = 15 * time.Second
+	// DefaultWindow is the span of stats the usage of a replica is averaged over by default.
+	DefaultWindow = time.Minute
+	// DefaultCooldown is the minimum time between two scaling actions by default.
+	DefaultCooldown = 2 * time.Minute
+)
+
+// Usage is the resource usage of the replicas of a controller over the stats window.
+type Usage struct {
+	Replicas int
+	// CPUPercent is the mean CPU usage of the replicas, where 100 is one full CPU.
+	CPUPercent float64
+	// MemoryPercent is the mean memory usage of the replicas, as a percentage of their limit.
+	MemoryPercent float64
+	// Samples holds the sample of every replica, by container name.
+	Samples map[string]statsmonitor.Sample
+}
+
+// Policy decides the number of replicas for the current usage. The result is clamped to the controller's
+// minimum and maximum.
+type Policy interface {
+	Desired(usage Usage) int
+}
+
+// PolicyFunc adapts a function to a Policy.
+type PolicyFunc func(usage Usage) int
+
+// Desired calls f.
+func (f PolicyFunc) Desired(usage Usage) int {
+	return f(usage)
+}
+
+// threshold adds a replica when a metric is above up and removes one when it is below down.
+type threshold struct {
+	metric   func(Usage) float64
+	up, down float64
+}
+
+func (p threshold) Desired(usage Usage) int {
+	switch value := p.metric(usage); {
+	case value > p.up:
+		return usage.Replicas + 1
+	case value < p.down:
+		return usage.Replicas - 1
+	}
+	return usage.Replicas
+}
+
+// CPUThreshold adds a replica when the mean CPU usage is above up percent and removes one when it is below
+// down percent, where 100 is one full CPU.
+func CPUThreshold(up, down float64) Policy {
+	return threshold{metric: func(u Usage) float64 { return u.CPUPercent }, up: up, down: down}
+}
+
+// MemoryThreshold adds a replica when the mean memory usage is above up percent of the limit and removes one
+// when it is below down percent.
+func MemoryThreshold(up, down float64) Policy {
+	return threshold{metric: func(u Usage) float64 { return u.MemoryPercent }, up: up, down: down}
+}
+
+// replica is a container of a controller.
+type replica struct {
+	config  *container.ContainerConfig
+	created int64
+	// state is the container state, e.g. "running" or "exited".
+	state string
+}
+
+// live reports whether the replica counts towards the controller's replicas. Replicas that are being
+// created or restarted are live; only exited and dead ones are replaced.
+func (r replica) live() bool {
+	return r.state != "exited" && r.state != "dead"
+}
+
+/*
+Controller scales a template container between a minimum and a maximum number of replicas on a single host,
+based on their stats. Replicas are named after the controller with a random suffix and join a user-defined
+network under the controller name as alias, so the network's DNS balances between them.
+
+Usage example:
+
+	template := container.NewConfig("worker")
+	template.SetContainerOptions(containeroptions.Image(image.NewConfig("worker:latest")))
+	template.SetHostOptions(hostoptions.CPUs(1))
+	ctrl := autoscale.New(client, "worker", "backend", template, 2, 8,
+		autoscale.WithPolicy(autoscale.CPUThreshold(70, 20)),
+	)
+	err := ctrl.Run(ctx)
+*/
+type Controller struct {
+	client   *godock.Client
+	clock    clock.Clock
+	name     string
+	network  string
+	template *container.ContainerConfig
+	min, max int
+	policy   Policy
+	interval time.Duration
+	window   time.Duration
+	cooldown time.Duration
+	onScale  func(ctx context.Context, from, to int)
+
+	windows   map[string]*statsmonitor.Window
+	lastScale time.Time
+	// list, stats, create and remove talk to the docker daemon; replaced in tests.
+	list   func(ctx context.Context) ([]replica, error)
+	stats  func(ctx context.Context, cfg *container.ContainerConfig) (godock.ContainerStats, error)
+	create func(ctx context.Context, cfg *container.ContainerConfig) error
+	remove func(ctx context.Context, cfg *container.ContainerConfig) error
+}
+
+// SetControllerOptFn is a function type that configures a Controller.
+type SetControllerOptFn func(c *Controller)
+
+// New creates a Controller keeping between min and max replicas of template, reachable as name on network.
+// It scales on CPUThreshold(80, 20) unless another policy is set.
+func New(client *godock.Client, name, network string, template *container.ContainerConfig, min, max int, setOptFns ...SetControllerOptFn) *Controller {
+	c := &Controller{
+		client:   client,
+		name:     name,
+		network:  network,
+		template: template,
+		min:      min,
+		max:      max,
+		policy:   CPUThreshold(80, 20),
+		interval: DefaultInterval,
+		window:   DefaultWindow,
+		cooldown: DefaultCooldown,
+		windows:  make(map[string]*statsmonitor.Window),
+	}
+	if client != nil {
+		c.clock = client.Clock()
+	} else {
+		c.clock = clock.Real()
+	}
+	c.list = c.listReplicas
+	c.stats = func(ctx context.Context, cfg *container.ContainerConfig) (godock.ContainerStats, error) {
+		return c.client.ContainerStatsOneShot(ctx, cfg)
+	}
+	c.create = c.createReplica
+	c.remove = func(ctx context.Context, cfg *container.ContainerConfig) error {
+		return c.client.ContainerRemove(ctx, cfg, removeoptions.Force())
+	}
+	for _, set := range setOptFns {
+		if set != nil {
+			set(c)
+		}
+	}
+	return c
+}
+
+// WithPolicy sets the policy deciding the number of replicas.
+func WithPolicy(policy Policy) SetControllerOptFn {
+	return func(c *Controller) {
+		if policy != nil {
+			c.policy = policy
+		}
+	}
+}
+
+// WithInterval sets the time between two evaluations. The default is DefaultInterval.
+func WithInterval(interval time.Duration) SetControllerOptFn {
+	return func(c *Controller) {
+		if interval > 0 {
+			c.interval = interval
+		}
+	}
+}
+
+// WithWindow sets the span of stats the usage of a replica is averaged over. The default is DefaultWindow.
+func WithWindow(window time.Duration) SetControllerOptFn {
+	return func(c *Controller) {
+		if window > 0 {
+			c.window = window
+		}
+	}
+}
+
+// WithCooldown sets the minimum time between two scaling actions, so new replicas can take load before the
+// next decision. The default is DefaultCooldown.
+func WithCooldown(cooldown time.Duration) SetControllerOptFn {
+	return func(c *Controller) {
+		if cooldown >= 0 {
+			c.cooldown = cooldown
+		}
+	}
+}
+
+// OnScale sets a function called after the number of replicas changed.
+func OnScale(fn func(ctx context.Context, from, to int)) SetControllerOptFn {
+	return func(c *Controller) {
+		c.onScale = fn
+	}
+}
+
+// WithClock sets the clock the controller waits on, e.g. a clock.Fake in tests. It defaults to the client's
+// clock.
+func WithClock(clk clock.Clock) SetControllerOptFn {
+	return func(c *Controller) {
+		if clk != nil {
+			c.clock = clk
+		}
+	}
+}
+
+// validate checks the controller configuration.
+func (c *Controller) validate() error {
+	if c.name == "" {
+		return &errdefs.ValidationError{Field: "name", Message: "controller name cannot be empty"}
+	}
+	if c.network == "" {
+		return &errdefs.ValidationError{Field: "network", Message: "controller network cannot be empty, replicas are reached through a network alias"}
+	}
+	if c.template == nil || c.template.Options == nil {
+		return &errdefs.ValidationError{Field: "template", Message: "template cannot be empty"}
+	}
+	if c.min < 0 || c.max < 1 || c.min > c.max {
+		return &errdefs.ValidationError{Field: "replicas", Message: fmt.Sprintf("invalid replica range %d-%d", c.min, c.max)}
+	}
+	return nil
+}
+
+// Run evaluates the controller every interval until ctx is done, or an evaluation fails.
+func (c *Controller) Run(ctx context.Context) error {
+	for {
+		if _, err := c.Step(ctx); err != nil {
+			return err
+		}
+		if err := c.clock.Sleep(ctx, c.interval); err != nil {
+			return err
+		}
+	}
+}
+
+/*
+Step evaluates the controller once and returns the number of replicas afterwards. It samples the stats of
+every replica, then brings the replicas within the minimum and maximum, or applies the policy once every
+replica has a sample and the cooldown has passed. Run calls Step every interval.
+*/
+func (c *Controller) Step(ctx context.Context) (int, error) {
+	if err := c.validate(); err != nil {
+		return 0, err
+	}
+	listed, err := c.list(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// Replicas that exited or died are replaced rather than counted.
+	replicas := make([]replica, 0, len(listed))
+	for _, r := range listed {
+		if r.live() {
+			replicas = append(replicas, r)
+		} else if err := c.remove(ctx, r.config); err != nil {
+			return 0, err
+		}
+	}
+
+	usage := Usage{Replicas: len(replicas), Samples: make(map[string]statsmonitor.Sample, len(replicas))}
+	windows := make(map[string]*statsmonitor.Window, len(replicas))
+	for _, r := range replicas {
+		window, ok := c.windows[r.config.Id]
+		if !ok {
+			window = statsmonitor.NewWindow(c.window)
+		}
+		windows[r.config.Id] = window
+		stats, err := c.stats(ctx, r.config)
+		if err != nil {
+			// The replica may just have exited; it is skipped until the next evaluation.
+			continue
+		}
+		window.Add(stats)
+		if sample, ok := window.Sample(); ok {
+			usage.Samples[r.config.Name] = sample
+			usage.CPUPercent += sample.CPUPercent
+			usage.MemoryPercent += sample.MemoryPercent
+		}
+	}
+	c.windows = windows
+	if n := len(usage.Samples); n > 0 {
+		usage.CPUPercent /= float64(n)
+		usage.MemoryPercent /= float64(n)
+	}
+
+	desired := min(max(len(replicas), c.min), c.max)
+	if desired == len(replicas) && len(usage.Samples) == len(replicas) && len(replicas) > 0 &&
+		c.clock.Now().Sub(c.lastScale) >= c.cooldown {
+		desired = min(max(c.policy.Desired(usage), c.min), c.max)
+	}
+	if desired == len(replicas) {
+		return desired, nil
+	}
+	return c.scale(ctx, replicas, desired)
+}
+
+// scale adds or removes replicas to reach desired, removing the newest replicas first.
+func (c *Controller) scale(ctx context.Context, replicas []replica, desired int) (int, error) {
+	current := len(replicas)
+	defer func() {
+		if current != len(replicas) {
+			c.lastScale = c.clock.Now()
+			if c.onScale != nil {
+				c.onScale(ctx, len(replicas), current)
+			}
+		}
+	}()
+
+	for current < desired {
+		cfg := c.template.Clone()
+		cfg.Id = ""
+		cfg.Name = c.name + "-" + godock.GenerateRandomString(6)
+		cfg.SetContainerOptions(containeroptions.Label(LabelGroup, c.name))
+		cfg.SetHostOptions(hostoptions.NetworkMode(c.network))
+		cfg.NetworkingOptions = &dockerNetwork.NetworkingConfig{
+			EndpointsConfig: map[string]*dockerNetwork.EndpointSettings{
+				c.network: {Aliases: []string{c.name}},
+			},
+		}
+		if err := c.create(ctx, cfg); err != nil {
+			return current, err
+		}
+		current++
+	}
+
+	sort.SliceStable(replicas, func(i, j int) bool { return replicas[i].created > replicas[j].created })
+	for _, r := range replicas[:max(current-desired, 0)] {
+		if err := c.remove(ctx, r.config); err != nil {
+			return current, err
+		}
+		delete(c.windows, r.config.Id)
+		current--
+	}
+	return current, nil
+}
+
+// listReplicas lists the replicas of the controller, including stopped ones.
+func (c *Controller) listReplicas(ctx context.Context) ([]replica, error) {
+	containers, err := c.client.ContainerList(ctx,
+		godock.WithContainerAll(true),
+		godock.WithContainerFilters(godock.FilterByLabel(LabelGroup, c.name)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	replicas := make([]replica, 0, len(containers))
+	for _, summary := range containers {
+		name := summary.ID
+		if len(summary.Names) > 0 {
+			name = strings.TrimPrefix(summary.Names[0], "/")
+		}
+		cfg := container.NewConfig(name)
+		cfg.Id = summary.ID
+		replicas = append(replicas, replica{config: cfg, created: summary.Created, state: summary.State})
+	}
+	return replicas, nil
+}
+
+// createReplica creates and starts a replica, removing it again when it cannot be started.
+func (c *Controller) createReplica(ctx context.Context, cfg *container.ContainerConfig) error {
+	if err := c.client.ContainerCreate(ctx, cfg); err != nil {
+		return err
+	}
+	if err := c.client.ContainerStart(ctx, cfg); err != nil {
+		c.client.ContainerRemove(context.WithoutCancel(ctx), cfg, removeoptions.Force())
+		return err
+	}
+	return nil
+}
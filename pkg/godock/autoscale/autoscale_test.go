@@ -0,0 +1,161 @@
+package autoscale
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHost runs the replicas of a controller under test, each using cpu percent of a CPU.
+type fakeHost struct {
+	fake     *clock.Fake
+	replicas []replica
+	cpu      map[string]float64
+	usage    map[string]uint64
+	created  int64
+}
+
+func newTestController(t *testing.T, min, max int, setOptFns ...SetControllerOptFn) (*Controller, *fakeHost) {
+	host := &fakeHost{
+		fake:  clock.NewFake(time.Date(2026, time.March, 1, 8, 0, 0, 0, time.UTC)),
+		cpu:   make(map[string]float64),
+		usage: make(map[string]uint64),
+	}
+	template := container.NewConfig("worker")
+	c := New(nil, "worker", "backend", template, min, max, append([]SetControllerOptFn{WithClock(host.fake)}, setOptFns...)...)
+	c.list = func(ctx context.Context) ([]replica, error) {
+		return append([]replica(nil), host.replicas...), nil
+	}
+	c.stats = func(ctx context.Context, cfg *container.ContainerConfig) (godock.ContainerStats, error) {
+		// Stats are read every interval, and the CPU counter grows at the replica's CPU usage.
+		host.usage[cfg.Id] += uint64(host.cpu[cfg.Id] / 100 * float64(c.interval))
+		return godock.ContainerStats{
+			Read: host.fake.Now(),
+			CpuStats: godock.CPUStats{
+				CPUUsage:    godock.CPUUsage{TotalUsage: host.usage[cfg.Id]},
+				SystemUsage: uint64(host.fake.Now().UnixNano()),
+				OnlineCPUs:  1,
+			},
+		}, nil
+	}
+	c.create = func(ctx context.Context, cfg *container.ContainerConfig) error {
+		assert.Equal(t, "worker", cfg.Options.Labels[LabelGroup])
+		assert.Equal(t, containerType.NetworkMode("backend"), cfg.HostOptions.NetworkMode)
+		assert.Equal(t, []string{"worker"}, cfg.NetworkingOptions.EndpointsConfig["backend"].Aliases)
+		host.created++
+		cfg.Id = fmt.Sprintf("id-%d", host.created)
+		host.replicas = append(host.replicas, replica{config: cfg, created: host.created, state: "running"})
+		return nil
+	}
+	c.remove = func(ctx context.Context, cfg *container.ContainerConfig) error {
+		for i, r := range host.replicas {
+			if r.config.Id == cfg.Id {
+				host.replicas = append(host.replicas[:i], host.replicas[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("no such container: %s", cfg.Id)
+	}
+	return c, host
+}
+
+// step advances the clock by the interval and evaluates the controller.
+func step(t *testing.T, c *Controller, host *fakeHost) int {
+	host.fake.Advance(c.interval)
+	n, err := c.Step(context.Background())
+	require.NoError(t, err)
+	return n
+}
+
+func TestValidate(t *testing.T) {
+	template := container.NewConfig("worker")
+	for _, c := range []*Controller{
+		New(nil, "", "backend", template, 1, 2),
+		New(nil, "worker", "", template, 1, 2),
+		New(nil, "worker", "backend", nil, 1, 2),
+		New(nil, "worker", "backend", template, 3, 2),
+		New(nil, "worker", "backend", template, 0, 0),
+	} {
+		_, err := c.Step(context.Background())
+		assert.True(t, errdefs.IsInvalidConfig(err))
+	}
+}
+
+func TestThresholdPolicy(t *testing.T) {
+	policy := CPUThreshold(70, 20)
+	assert.Equal(t, 4, policy.Desired(Usage{Replicas: 3, CPUPercent: 90}))
+	assert.Equal(t, 3, policy.Desired(Usage{Replicas: 3, CPUPercent: 50}))
+	assert.Equal(t, 2, policy.Desired(Usage{Replicas: 3, CPUPercent: 10}))
+	assert.Equal(t, 2, MemoryThreshold(80, 30).Desired(Usage{Replicas: 3, CPUPercent: 90, MemoryPercent: 10}))
+}
+
+func TestControllerScales(t *testing.T) {
+	var scaled [][2]int
+	c, host := newTestController(t, 1, 3,
+		WithPolicy(CPUThreshold(70, 20)),
+		WithCooldown(time.Minute),
+		OnScale(func(ctx context.Context, from, to int) { scaled = append(scaled, [2]int{from, to}) }),
+	)
+
+	// The minimum is created right away.
+	assert.Equal(t, 1, step(t, c, host))
+	host.cpu["id-1"] = 90
+
+	// The policy waits for a sample of every replica, then for the cooldown.
+	assert.Equal(t, 1, step(t, c, host))
+	assert.Equal(t, 1, step(t, c, host))
+	assert.Equal(t, 1, step(t, c, host))
+	assert.Equal(t, 2, step(t, c, host))
+	host.cpu["id-2"] = 90
+
+	// Scaling up stops at the maximum.
+	for i := 0; i < 10; i++ {
+		step(t, c, host)
+	}
+	assert.Len(t, host.replicas, 3)
+
+	// Load drops: the newest replicas are removed first, down to the minimum.
+	for id := range host.cpu {
+		host.cpu[id] = 5
+	}
+	for i := 0; i < 30; i++ {
+		step(t, c, host)
+	}
+	require.Len(t, host.replicas, 1)
+	assert.Equal(t, "id-1", host.replicas[0].config.Id)
+	assert.Equal(t, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 2}, {2, 1}}, scaled)
+}
+
+func TestControllerReplacesExitedReplicas(t *testing.T) {
+	c, host := newTestController(t, 2, 4)
+	assert.Equal(t, 2, step(t, c, host))
+	host.replicas[0].state = "exited"
+
+	assert.Equal(t, 2, step(t, c, host))
+	require.Len(t, host.replicas, 2)
+	assert.Equal(t, "id-2", host.replicas[0].config.Id)
+	assert.Equal(t, "id-3", host.replicas[1].config.Id)
+}
+
+func TestControllerKeepsStartingReplicas(t *testing.T) {
+	c, host := newTestController(t, 3, 4)
+	assert.Equal(t, 3, step(t, c, host))
+	host.replicas[0].state = "restarting"
+	host.replicas[1].state = "created"
+	host.replicas[2].state = "dead"
+
+	assert.Equal(t, 3, step(t, c, host))
+	require.Len(t, host.replicas, 3)
+	assert.Equal(t, "id-1", host.replicas[0].config.Id)
+	assert.Equal(t, "id-2", host.replicas[1].config.Id)
+	assert.Equal(t, "id-4", host.replicas[2].config.Id)
+}
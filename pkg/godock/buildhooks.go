@@ -0,0 +1,139 @@
+package godock
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	imageType "github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// buildHookReader watches a build output stream for the built image ID
+// and runs the image's post-build hooks once the stream has been fully read.
+type buildHookReader struct {
+	ctx         context.Context
+	client      *Client
+	imageConfig *image.ImageConfig
+	body        io.ReadCloser
+	scanner     jsonMessageScanner
+	imageID     string
+	failed      bool
+	done        bool
+	hookErr     error
+}
+
+func (r *buildHookReader) Read(p []byte) (int, error) {
+	if r.done {
+		if r.hookErr != nil {
+			return 0, r.hookErr
+		}
+		return 0, io.EOF
+	}
+
+	n, err := r.body.Read(p)
+	if n > 0 {
+		r.scanner.write(p[:n], r.observe)
+	}
+	if err == io.EOF {
+		r.done = true
+		r.scanner.flush(r.observe)
+		if !r.failed {
+			r.hookErr = r.runHooks()
+		}
+		if r.hookErr != nil {
+			// Hand back the remaining output now and report the hook failure on the next read.
+			if n > 0 {
+				return n, nil
+			}
+			return 0, r.hookErr
+		}
+	}
+	return n, err
+}
+
+func (r *buildHookReader) Close() error {
+	return r.body.Close()
+}
+
+// observe records the built image ID and build failures from a build output message.
+func (r *buildHookReader) observe(msg jsonmessage.JSONMessage) error {
+	if msg.Error != nil {
+		r.failed = true
+	}
+	if msg.Aux != nil {
+		var aux struct {
+			ID string `json:"ID"`
+		}
+		if err := json.Unmarshal(*msg.Aux, &aux); err == nil && aux.ID != "" {
+			r.imageID = aux.ID
+		}
+	}
+	return nil
+}
+
+// runHooks runs the OnBuilt hooks for the built image, pushing its tags first when the hooks ask for it.
+func (r *buildHookReader) runHooks() error {
+	tags := r.imageConfig.BuildOptions.Tags
+	imageID := r.imageID
+	if imageID == "" && len(tags) > 0 {
+		inspect, _, err := r.client.wrapped.ImageInspectWithRaw(r.ctx, tags[0])
+		if err != nil {
+			return &errdefs.ImageError{
+				Ref:     tags[0],
+				Op:      "build",
+				Message: err.Error(),
+				Err:     err,
+			}
+		}
+		imageID = inspect.ID
+	}
+	if imageID == "" {
+		return &errdefs.ImageError{
+			Ref:     r.imageConfig.Ref,
+			Op:      "build",
+			Message: "could not determine the built image ID for post-build hooks",
+		}
+	}
+
+	digest := ""
+	if r.imageConfig.BuildHooks.Push {
+		if len(tags) == 0 {
+			return &errdefs.ImageError{
+				Ref:     imageID,
+				Op:      "push",
+				Message: "pushing before post-build hooks requires a tag, use imageoptions.AddTag",
+			}
+		}
+		pushOptions := r.imageConfig.PushOptions
+		if pushOptions == nil {
+			pushOptions = &imageType.PushOptions{}
+		}
+		for _, tag := range tags {
+			res, err := r.client.ImagePushWithProgress(r.ctx, &image.ImageConfig{Ref: tag, PushOptions: pushOptions}, nil)
+			if err != nil {
+				return err
+			}
+			if digest == "" {
+				digest = res.Digest
+			}
+		}
+	}
+
+	for _, hook := range r.imageConfig.BuildHooks.OnBuilt {
+		if hook == nil {
+			continue
+		}
+		if err := hook(r.ctx, imageID, digest); err != nil {
+			return &errdefs.ImageError{
+				Ref:     imageID,
+				Op:      "post-build hook",
+				Message: err.Error(),
+				Err:     err,
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package godock
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/imageoptions"
+	"github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildHookReader_Scan(t *testing.T) {
+	t.Run("Classic builder output", func(t *testing.T) {
+		r := &buildHookReader{}
+		r.scanner.write([]byte(`{"stream":"Step 1/2 : FROM alpine"}`+"\n"+`{"aux":{"ID":"sha256:ab`), r.observe)
+		assert.Empty(t, r.imageID)
+		r.scanner.write([]byte(`c123"}}`+"\n"+`{"stream":"Successfully built abc123"}`+"\n"), r.observe)
+		assert.Equal(t, "sha256:abc123", r.imageID)
+		assert.False(t, r.failed)
+	})
+
+	t.Run("BuildKit output", func(t *testing.T) {
+		r := &buildHookReader{}
+		r.scanner.write([]byte(`{"id":"moby.image.id","aux":{"ID":"sha256:def456"}}`+"\n"), r.observe)
+		assert.Equal(t, "sha256:def456", r.imageID)
+	})
+
+	t.Run("Build error", func(t *testing.T) {
+		r := &buildHookReader{}
+		r.scanner.write([]byte(`{"errorDetail":{"message":"boom"},"error":"boom"}`+"\n"), r.observe)
+		assert.True(t, r.failed)
+		assert.Empty(t, r.imageID)
+	})
+}
+
+// closeRecorder is a build output body that records whether it was closed.
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+// newFakeDaemonClient returns a client talking to a fake daemon served by handler.
+func newFakeDaemonClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	wrapped, err := client.NewClientWithOpts(
+		client.WithHost("tcp://"+strings.TrimPrefix(server.URL, "http://")),
+		client.WithVersion("1.45"),
+	)
+	require.NoError(t, err)
+	return &Client{wrapped: wrapped}
+}
+
+const builtOutput = `{"stream":"Step 1/1 : FROM alpine"}` + "\n" + `{"aux":{"ID":"sha256:abc123"}}` + "\n"
+
+func TestBuildHookReader_ReadClose(t *testing.T) {
+	var gotID, gotDigest string
+	img := image.NewConfig("app")
+	img.SetBuildHooks(imageoptions.OnBuilt(func(ctx context.Context, imageID, digest string) error {
+		gotID, gotDigest = imageID, digest
+		return errors.New("attestation failed")
+	}))
+	body := &closeRecorder{Reader: strings.NewReader(builtOutput)}
+	r := &buildHookReader{ctx: context.Background(), client: &Client{}, imageConfig: img, body: body}
+
+	out, err := io.ReadAll(r)
+	assert.Equal(t, builtOutput, string(out), "the build output is handed back before the hook error")
+	var imageErr *errdefs.ImageError
+	require.ErrorAs(t, err, &imageErr)
+	assert.Equal(t, "post-build hook", imageErr.Op)
+	assert.Contains(t, imageErr.Message, "attestation failed")
+	assert.Equal(t, "sha256:abc123", gotID)
+	assert.Empty(t, gotDigest, "a local build has no repository digest")
+
+	// The error is returned again by later reads.
+	_, err = r.Read(make([]byte, 8))
+	assert.ErrorAs(t, err, &imageErr)
+	require.NoError(t, r.Close())
+	assert.True(t, body.closed)
+
+	t.Run("Failed build skips hooks", func(t *testing.T) {
+		called := false
+		img := image.NewConfig("app")
+		img.SetBuildHooks(imageoptions.OnBuilt(func(ctx context.Context, imageID, digest string) error {
+			called = true
+			return nil
+		}))
+		body := &closeRecorder{Reader: strings.NewReader(`{"errorDetail":{"message":"boom"},"error":"boom"}` + "\n")}
+		r := &buildHookReader{ctx: context.Background(), client: &Client{}, imageConfig: img, body: body}
+		_, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.False(t, called)
+	})
+}
+
+func TestImageBuildHooks(t *testing.T) {
+	var pushed []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1.45/build", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, builtOutput)
+	})
+	mux.HandleFunc("POST /v1.45/images/{repo...}", func(w http.ResponseWriter, r *http.Request) {
+		pushed = append(pushed, strings.TrimSuffix(r.PathValue("repo"), "/push")+":"+r.URL.Query().Get("tag"))
+		io.WriteString(w, `{"status":"Pushing","id":"layer1"}`+"\n"+`{"aux":{"Tag":"1","Digest":"sha256:fed987","Size":525}}`+"\n")
+	})
+	c := newFakeDaemonClient(t, mux)
+
+	t.Run("Hook error is returned from the build output", func(t *testing.T) {
+		img := image.NewConfig("registry.example.com/app:1")
+		img.SetBuildOptions(imageoptions.SetBuildContext(strings.NewReader("")))
+		img.SetBuildHooks(imageoptions.OnBuilt(func(ctx context.Context, imageID, digest string) error {
+			return errors.New("scan found vulnerabilities")
+		}))
+		rc, err := c.ImageBuild(context.Background(), img)
+		require.NoError(t, err)
+		defer rc.Close()
+
+		_, err = io.ReadAll(rc)
+		var imageErr *errdefs.ImageError
+		require.ErrorAs(t, err, &imageErr)
+		assert.Equal(t, "post-build hook", imageErr.Op)
+		assert.Contains(t, imageErr.Message, "scan found vulnerabilities")
+	})
+
+	t.Run("Hooks receive the pushed digest", func(t *testing.T) {
+		var gotDigest string
+		img := image.NewConfig("registry.example.com/app:1")
+		img.SetBuildOptions(
+			imageoptions.SetBuildContext(strings.NewReader("")),
+			imageoptions.AddTag("registry.example.com/app:1"),
+		)
+		img.SetBuildHooks(
+			imageoptions.PushBeforeHooks(),
+			imageoptions.OnBuilt(func(ctx context.Context, imageID, digest string) error {
+				gotDigest = digest
+				return nil
+			}),
+		)
+		rc, err := c.ImageBuild(context.Background(), img)
+		require.NoError(t, err)
+		defer rc.Close()
+
+		_, err = io.ReadAll(rc)
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:fed987", gotDigest)
+		assert.Equal(t, []string{"registry.example.com/app:1"}, pushed)
+	})
+}
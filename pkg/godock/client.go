@@ -3,19 +3,33 @@ package godock
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/aptd3v/godock/pkg/godock/clock"
 	"github.com/aptd3v/godock/pkg/godock/commitoptions"
 	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
 	"github.com/aptd3v/godock/pkg/godock/errdefs"
 	"github.com/aptd3v/godock/pkg/godock/exec"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
 	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/imageoptions"
+	"github.com/aptd3v/godock/pkg/godock/metrics"
 	"github.com/aptd3v/godock/pkg/godock/network"
 	"github.com/aptd3v/godock/pkg/godock/networkoptions/endpointoptions"
+	"github.com/aptd3v/godock/pkg/godock/registryauth"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/aptd3v/godock/pkg/godock/runoptions"
+	"github.com/aptd3v/godock/pkg/godock/stopoptions"
 	"github.com/aptd3v/godock/pkg/godock/terminal"
+	"github.com/aptd3v/godock/pkg/godock/vars"
 	"github.com/aptd3v/godock/pkg/godock/volume"
 	"github.com/docker/docker/api/types"
 	containerType "github.com/docker/docker/api/types/container"
@@ -25,10 +39,29 @@ import (
 	"github.com/docker/docker/api/types/registry"
 	volumeType "github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
+	dockerErrdefs "github.com/docker/docker/errdefs"
 )
 
+// ManagedLabel is set on every container created through godock so that
+// containers created by other tools can be told apart.
+const ManagedLabel = "godock.managed"
+
 type Client struct {
-	wrapped *client.Client
+	wrapped   *client.Client
+	clock     clock.Clock
+	recorders []metrics.Recorder
+	auth      *registryauth.Manager
+	inspects  *inspectCache
+	logStdout io.Writer
+	logStderr io.Writer
+	logger    Logger
+	verifiers []ImageVerifier
+	// vars resolves the placeholders of created containers, nil without variables.
+	vars *vars.Vars
+	// profiles are activated on every created container.
+	profiles []string
+	// limiter paces transfers to the transfer rate limit, nil without a limit.
+	limiter *rateLimiter
 }
 
 func (c *Client) ContainerCreate(ctx context.Context, containerConfig *container.ContainerConfig) error {
@@ -39,9 +72,53 @@ func (c *Client) ContainerCreate(ctx context.Context, containerConfig *container
 		}
 	}
 
+	if containerConfig.Options == nil {
+		return &errdefs.ValidationError{
+			Field:   "containerConfig",
+			Message: "container options cannot be nil",
+		}
+	}
+	// Profiles are applied first, so their options can hold placeholders too.
+	if err := containerConfig.ActivateProfiles(c.profiles...); err != nil {
+		return err
+	}
+	if c.vars != nil {
+		if err := c.vars.Apply(containerConfig); err != nil {
+			return err
+		}
+	}
+	if containerConfig.HostOptions != nil {
+		if err := hostoptions.ValidateDeviceRequests(containerConfig.HostOptions.DeviceRequests); err != nil {
+			return err
+		}
+		if len(containerConfig.HostOptions.Annotations) > 0 {
+			if err := c.wrapped.NewVersionError(ctx, "1.43", "specify annotations"); err != nil {
+				return &errdefs.ValidationError{Field: "Annotations", Message: err.Error()}
+			}
+		}
+	}
+	if err := containeroptions.ValidateHealthCheck(containerConfig.Options.Healthcheck); err != nil {
+		return err
+	}
+	applyContainerMacAddress(containerConfig)
+	if _, ok := containerConfig.Options.Labels[ManagedLabel]; !ok {
+		// The labels may be shared with other configs, e.g. a copied template, so they are not changed in place.
+		labels := maps.Clone(containerConfig.Options.Labels)
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[ManagedLabel] = "true"
+		containerConfig.Options.Labels = labels
+	}
+
+	options, err := secretOptions(containerConfig)
+	if err != nil {
+		return err
+	}
+
 	res, err := c.wrapped.ContainerCreate(
 		ctx,
-		containerConfig.Options,
+		options,
 		containerConfig.HostOptions,
 		containerConfig.NetworkingOptions,
 		containerConfig.PlatformOptions,
@@ -52,54 +129,115 @@ func (c *Client) ContainerCreate(ctx context.Context, containerConfig *container
 			return &errdefs.ResourceNotFoundError{
 				ResourceType: "image",
 				ID:           containerConfig.Options.Image,
+				Err:          err,
 			}
 		}
-		// Check for conflicts
-		if strings.Contains(err.Error(), "Conflict") {
-			if strings.Contains(err.Error(), "is already in use") {
-				return &errdefs.ResourceExistsError{
-					ResourceType: "container",
-					ID:           containerConfig.Name,
-				}
+		if errdefs.IsConflict(err) {
+			return &errdefs.ResourceExistsError{
+				ResourceType: "container",
+				ID:           containerConfig.Name,
+				Err:          err,
 			}
 		}
 		return &errdefs.ContainerError{
 			ID:      containerConfig.Name,
 			Op:      "create",
 			Message: err.Error(),
+			Err:     err,
 		}
 	}
 
 	containerConfig.Id = res.ID
+	if err := c.injectSecretFiles(ctx, containerConfig); err != nil {
+		c.ContainerRemove(context.WithoutCancel(ctx), containerConfig, removeoptions.Force())
+		containerConfig.Id = ""
+		return err
+	}
 	return nil
 }
 
-func (c *Client) ContainerStart(ctx context.Context, containerConfig *container.ContainerConfig) error {
+// applyContainerMacAddress copies the container-wide MAC address onto the endpoint of the container's
+// primary network, like the docker CLI does. Since API 1.44 the container-wide field is ignored.
+func applyContainerMacAddress(containerConfig *container.ContainerConfig) {
+	mac := containerConfig.Options.MacAddress //nolint:staticcheck // still set by containeroptions.MacAddress
+	if mac == "" {
+		return
+	}
+	networkName := "default"
+	if containerConfig.HostOptions != nil && containerConfig.HostOptions.NetworkMode != "" {
+		mode := containerConfig.HostOptions.NetworkMode
+		if mode.IsHost() || mode.IsNone() || mode.IsContainer() {
+			return
+		}
+		networkName = mode.NetworkName()
+	}
+	if containerConfig.NetworkingOptions == nil {
+		containerConfig.NetworkingOptions = &dockerNetwork.NetworkingConfig{}
+	}
+	if containerConfig.NetworkingOptions.EndpointsConfig == nil {
+		containerConfig.NetworkingOptions.EndpointsConfig = make(map[string]*dockerNetwork.EndpointSettings)
+	}
+	endpoint := containerConfig.NetworkingOptions.EndpointsConfig[networkName]
+	if endpoint == nil {
+		endpoint = &dockerNetwork.EndpointSettings{}
+		containerConfig.NetworkingOptions.EndpointsConfig[networkName] = endpoint
+	}
+	if endpoint.MacAddress == "" {
+		endpoint.MacAddress = mac
+	}
+}
+
+// validateContainerConfig returns a ValidationError unless the config identifies an existing container. Every
+// method acting on a created container validates its config with it, so they fail the same way.
+func validateContainerConfig(containerConfig *container.ContainerConfig) error {
 	if containerConfig == nil || containerConfig.Id == "" {
 		return &errdefs.ValidationError{
 			Field:   "containerConfig",
 			Message: "container config or ID cannot be empty",
 		}
 	}
+	return nil
+}
+
+// validateNetworkConfig returns a ValidationError unless the config identifies an existing network.
+func validateNetworkConfig(networkConfig *network.NetworkConfig) error {
+	if networkConfig == nil || networkConfig.Id == "" {
+		return &errdefs.ValidationError{
+			Field:   "networkConfig",
+			Message: "network config or ID cannot be empty",
+		}
+	}
+	return nil
+}
+
+func (c *Client) ContainerStart(ctx context.Context, containerConfig *container.ContainerConfig) error {
+	if err := validateContainerConfig(containerConfig); err != nil {
+		return err
+	}
 
+	defer c.invalidateInspect(containerConfig.Id)
 	err := c.wrapped.ContainerStart(ctx, containerConfig.Id, containerType.StartOptions{})
 	if err != nil {
 		if client.IsErrNotFound(err) {
 			return &errdefs.ResourceNotFoundError{
 				ResourceType: "container",
 				ID:           containerConfig.Name,
+				Err:          err,
 			}
 		}
 		if strings.Contains(err.Error(), "port is already allocated") {
 			return &errdefs.ResourceExistsError{
 				ResourceType: "port",
 				ID:           containerConfig.Name,
+				Err:          err,
+				Hint:         c.portConflictHint(ctx, err),
 			}
 		}
 		return &errdefs.ContainerError{
 			ID:      containerConfig.Name,
 			Op:      "start",
 			Message: err.Error(),
+			Err:     err,
 		}
 	}
 	return nil
@@ -115,13 +253,45 @@ func (c *Client) ContainerStats(ctx context.Context, containerConfig *container.
 	return res.Body, nil
 }
 
+// LogsOptionFn is a function that configures the logs operation.
+type LogsOptionFn func(*containerType.LogsOptions)
+
+// WithLogsSince only returns logs written since the given timestamp or relative duration, e.g. "10m".
+func WithLogsSince(since string) LogsOptionFn {
+	return func(opts *containerType.LogsOptions) {
+		opts.Since = since
+	}
+}
+
+// WithLogsTimestamps prefixes every log line with the RFC3339Nano time it was written, followed by a space.
+func WithLogsTimestamps() LogsOptionFn {
+	return func(opts *containerType.LogsOptions) {
+		opts.Timestamps = true
+	}
+}
+
+// WithLogsTail only returns the last n lines of the existing logs, before following new ones.
+func WithLogsTail(n int) LogsOptionFn {
+	return func(opts *containerType.LogsOptions) {
+		opts.Tail = strconv.Itoa(n)
+	}
+}
+
 // ContainerLogs returns a ReadCloser for container logs. Caller is responsible for closing the returned reader.
-func (c *Client) ContainerLogs(ctx context.Context, containerConfig *container.ContainerConfig) (io.ReadCloser, error) {
-	rc, err := c.wrapped.ContainerLogs(ctx, containerConfig.Id, containerType.LogsOptions{
+// The logs of stdout and stderr are followed until the container stops. Provide option functions to select
+// the logs returned.
+func (c *Client) ContainerLogs(ctx context.Context, containerConfig *container.ContainerConfig, logsOptionFns ...LogsOptionFn) (io.ReadCloser, error) {
+	opts := containerType.LogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
 		Follow:     true,
-	})
+	}
+	for _, fn := range logsOptionFns {
+		if fn != nil {
+			fn(&opts)
+		}
+	}
+	rc, err := c.wrapped.ContainerLogs(ctx, containerConfig.Id, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -129,32 +299,72 @@ func (c *Client) ContainerLogs(ctx context.Context, containerConfig *container.C
 	return rc, nil
 }
 
-func (c *Client) ContainerRemove(ctx context.Context, containerConfig *container.ContainerConfig, force bool) error {
-	return c.wrapped.ContainerRemove(ctx, containerConfig.Id, containerType.RemoveOptions{
-		RemoveVolumes: force,
-		Force:         force,
-	})
+// ContainerRemove removes a container. Provide option functions from the removeoptions package
+// to force removal of a running container, remove its anonymous volumes or remove links.
+func (c *Client) ContainerRemove(ctx context.Context, containerConfig *container.ContainerConfig, removeOptionFns ...removeoptions.SetRemoveOptFn) error {
+	if err := validateContainerConfig(containerConfig); err != nil {
+		return err
+	}
+	opts := containerType.RemoveOptions{}
+	for _, fn := range removeOptionFns {
+		if fn != nil {
+			fn(&opts)
+		}
+	}
+	defer c.invalidateInspect(containerConfig.Id)
+	return containerOpError(containerConfig, "remove", c.wrapped.ContainerRemove(ctx, containerConfig.Id, opts))
 }
 
 func (c *Client) ContainerUnpause(ctx context.Context, containerConfig *container.ContainerConfig) error {
-	return c.wrapped.ContainerUnpause(ctx, containerConfig.Id)
+	if err := validateContainerConfig(containerConfig); err != nil {
+		return err
+	}
+	defer c.invalidateInspect(containerConfig.Id)
+	return containerOpError(containerConfig, "unpause", c.wrapped.ContainerUnpause(ctx, containerConfig.Id))
 }
 
 func (c *Client) ContainerPause(ctx context.Context, containerConfig *container.ContainerConfig) error {
-	return c.wrapped.ContainerPause(ctx, containerConfig.Id)
+	if err := validateContainerConfig(containerConfig); err != nil {
+		return err
+	}
+	defer c.invalidateInspect(containerConfig.Id)
+	return containerOpError(containerConfig, "pause", c.wrapped.ContainerPause(ctx, containerConfig.Id))
 }
 
-func (c *Client) ContainerRestart(ctx context.Context, containerConfig *container.ContainerConfig) error {
-	return c.wrapped.ContainerRestart(ctx, containerConfig.Id, containerType.StopOptions{})
+// ContainerRestart stops and starts a container again. Provide option functions to control the stop timeout and signal.
+func (c *Client) ContainerRestart(ctx context.Context, containerConfig *container.ContainerConfig, stopOptionFns ...stopoptions.SetStopOptFn) error {
+	if err := validateContainerConfig(containerConfig); err != nil {
+		return err
+	}
+	opts := containerType.StopOptions{}
+	for _, fn := range stopOptionFns {
+		if fn != nil {
+			fn(&opts)
+		}
+	}
+	defer c.invalidateInspect(containerConfig.Id)
+	return containerOpError(containerConfig, "restart", c.wrapped.ContainerRestart(ctx, containerConfig.Id, opts))
 }
 
-func (c *Client) ContainerStop(ctx context.Context, containerConfig *container.ContainerConfig) error {
-	return c.wrapped.ContainerStop(ctx, containerConfig.Id, containerType.StopOptions{})
+// ContainerStop stops a container. Provide option functions to control the stop timeout and signal.
+func (c *Client) ContainerStop(ctx context.Context, containerConfig *container.ContainerConfig, stopOptionFns ...stopoptions.SetStopOptFn) error {
+	if err := validateContainerConfig(containerConfig); err != nil {
+		return err
+	}
+	opts := containerType.StopOptions{}
+	for _, fn := range stopOptionFns {
+		if fn != nil {
+			fn(&opts)
+		}
+	}
+	defer c.invalidateInspect(containerConfig.Id)
+	return containerOpError(containerConfig, "stop", c.wrapped.ContainerStop(ctx, containerConfig.Id, opts))
 }
 
-// ContainerWait waits for a container to finish and returns a channel for status and errors
+// ContainerWait waits for a container to finish and returns a channel for status and errors.
+// Use ContainerWaitFor to wait for the next exit or the removal of the container.
 func (c *Client) ContainerWait(ctx context.Context, containerConfig *container.ContainerConfig) (<-chan containerType.WaitResponse, <-chan error) {
-	return c.wrapped.ContainerWait(ctx, containerConfig.Id, containerType.WaitConditionNotRunning)
+	return c.ContainerWaitFor(ctx, containerConfig, WaitConditionNotRunning)
 }
 
 func (c *Client) NetworkCreate(ctx context.Context, networkConfig *network.NetworkConfig) error {
@@ -171,12 +381,14 @@ func (c *Client) NetworkCreate(ctx context.Context, networkConfig *network.Netwo
 			return &errdefs.ResourceNotFoundError{
 				ResourceType: "network driver",
 				ID:           networkConfig.Options.Driver,
+				Err:          err,
 			}
 		}
 		return &errdefs.NetworkError{
 			ID:      networkConfig.Name,
 			Op:      "create",
 			Message: err.Error(),
+			Err:     err,
 		}
 	}
 	networkConfig.Id = res.ID
@@ -191,29 +403,33 @@ func (c *Client) VolumeCreate(ctx context.Context, volumeConfig *volume.VolumeCo
 		}
 	}
 
-	fmt.Printf("Creating volume with options: %+v\n", volumeConfig.Options)
-	fmt.Printf("Volume labels: %+v\n", volumeConfig.Options.Labels)
+	c.log().DebugContext(ctx, "creating volume", "op", "volume create",
+		"volume", volumeConfig.Options.Name, "driver", volumeConfig.Options.Driver, "labels", volumeConfig.Options.Labels)
 	vol, err := c.wrapped.VolumeCreate(ctx, *volumeConfig.Options)
 	if err != nil {
 		if client.IsErrNotFound(err) {
 			return &errdefs.ResourceNotFoundError{
 				ResourceType: "volume driver",
 				ID:           volumeConfig.Options.Driver,
+				Err:          err,
 			}
 		}
 		return &errdefs.VolumeError{
 			Name:    volumeConfig.Options.Name,
 			Op:      "create",
 			Message: err.Error(),
+			Err:     err,
 		}
 	}
-	fmt.Printf("Created volume: %+v\n", vol)
+	c.log().DebugContext(ctx, "created volume", "op", "volume create", "volume", vol.Name, "mountpoint", vol.Mountpoint)
 	return nil
 }
 
-// PullImage requests the docker host to pull an image from a remote registry.
+// ImagePull requests the docker host to pull an image from a remote registry.
 // It executes the privileged function if the operation is unauthorized and it tries one more time.
 // It's up to the caller to handle the io.ReadCloser and close it properly.
+// When the image is pinned with imageoptions.RequireDigest, or the client has image verifiers, the pulled image is
+// checked once the response body has been fully read, and a failed check is returned as the final read error.
 func (c *Client) ImagePull(ctx context.Context, imageConfig *image.ImageConfig) (io.ReadCloser, error) {
 	if imageConfig == nil || imageConfig.Ref == "" {
 		return nil, &errdefs.ValidationError{
@@ -221,62 +437,199 @@ func (c *Client) ImagePull(ctx context.Context, imageConfig *image.ImageConfig)
 			Message: "image config or reference cannot be empty",
 		}
 	}
+	if err := imageConfig.Validate(); err != nil {
+		return nil, err
+	}
+	if err := validateRequiredDigest(imageConfig); err != nil {
+		return nil, err
+	}
 
-	rc, err := c.wrapped.ImagePull(ctx, imageConfig.Ref, *imageConfig.PullOptions)
+	pullOptions := *imageConfig.PullOptions
+	if pullOptions.RegistryAuth == "" {
+		encoded, err := c.registryAuthFor(ctx, imageConfig.Ref)
+		if err != nil {
+			return nil, err
+		}
+		pullOptions.RegistryAuth = encoded
+	}
+
+	start := c.Clock().Now()
+	rc, err := c.wrapped.ImagePull(ctx, imageConfig.Ref, pullOptions)
 	if err != nil {
+		c.recordTransfer(metrics.ImageTransfer{
+			Operation: metrics.OperationPull,
+			Ref:       imageConfig.Ref,
+			Duration:  c.Clock().Now().Sub(start),
+			Err:       err,
+		})
 		if client.IsErrNotFound(err) {
 			return nil, &errdefs.ResourceNotFoundError{
 				ResourceType: "image",
 				ID:           imageConfig.Ref,
+				Err:          err,
 			}
 		}
-		return nil, &errdefs.ImageError{
-			Ref:     imageConfig.Ref,
-			Op:      "pull",
-			Message: err.Error(),
-		}
+		return nil, newImageError(imageConfig.Ref, "pull", err)
 	}
-	return rc, nil
+	return c.meterTransfer(metrics.OperationPull, imageConfig.Ref, start, c.checkPull(ctx, imageConfig, c.paceTransfer(ctx, rc)), nil), nil
 }
 
 // BuildImage builds an image from a directory or a context
 // If the context is not included in the image config, it will return an error
 // Caller is responsible for closing the response body
+// If post-build hooks are registered they run once the response body has been fully read,
+// and a hook failure is returned as the final read error.
+// When metrics recorders are configured the build is recorded once the response body has been read or closed.
 func (c *Client) ImageBuild(ctx context.Context, imageConfig *image.ImageConfig) (io.ReadCloser, error) {
-	res, err := c.wrapped.ImageBuild(ctx, imageConfig.BuildOptions.Context, *imageConfig.BuildOptions)
+	var buildContext io.Reader = imageConfig.BuildOptions.Context
+	var contextSize *countingReader
+	if len(c.recorders) > 0 && buildContext != nil {
+		contextSize = &countingReader{r: buildContext}
+		buildContext = contextSize
+	}
+	ref := imageConfig.Ref
+	if len(imageConfig.BuildOptions.Tags) > 0 {
+		ref = imageConfig.BuildOptions.Tags[0]
+	}
+
+	buildOptions := *imageConfig.BuildOptions
+	if err := imageoptions.ValidateCacheTo(&buildOptions); err != nil {
+		return nil, err
+	}
+	if err := c.prepareMultiPlatformBuild(ctx, &buildOptions); err != nil {
+		return nil, err
+	}
+	if len(buildOptions.AuthConfigs) == 0 && c.auth != nil {
+		authConfigs, err := c.auth.All(ctx)
+		if err != nil {
+			return nil, &errdefs.ImageError{
+				Ref:     ref,
+				Op:      "build",
+				Message: err.Error(),
+				Err:     err,
+			}
+		}
+		buildOptions.AuthConfigs = authConfigs
+	}
+
+	start := c.Clock().Now()
+	res, err := c.wrapped.ImageBuild(ctx, buildContext, buildOptions)
 	if err != nil {
+		c.recordTransfer(metrics.ImageTransfer{
+			Operation: metrics.OperationBuild,
+			Ref:       ref,
+			Duration:  c.Clock().Now().Sub(start),
+			Err:       err,
+		})
 		return nil, err
 	}
-	return res.Body, nil
+	body := c.meterTransfer(metrics.OperationBuild, ref, start, res.Body, contextSize)
+	if imageConfig.BuildHooks != nil && len(imageConfig.BuildHooks.OnBuilt) > 0 {
+		return &buildHookReader{
+			ctx:         ctx,
+			client:      c,
+			imageConfig: imageConfig,
+			body:        body,
+		}, nil
+	}
+	return body, nil
 }
 
 func (c *Client) String() string {
 	return c.wrapped.DaemonHost()
 }
 
-func NewClient(ctx context.Context) (*Client, error) {
-	c, err := client.NewClientWithOpts(
-		client.FromEnv,
-		client.WithAPIVersionNegotiation(),
-	)
+// NewClient creates a client for the docker daemon configured by the environment
+// and verifies that the daemon is reachable. Provide option functions to configure the client.
+// When DOCKER_HOST is unset and the default socket is missing, it connects to the first socket found of
+// Docker Desktop, Colima, Rancher Desktop, OrbStack or rootless docker; see WithoutSocketDiscovery.
+func NewClient(ctx context.Context, clientOptionFns ...ClientOptionFn) (*Client, error) {
+	opts := newClientOptions()
+	for _, fn := range clientOptionFns {
+		if fn != nil {
+			fn(opts)
+		}
+	}
+
+	if opts.transferRate < 0 {
+		return nil, &errdefs.ConfigError{
+			Field:   "transferRate",
+			Message: fmt.Sprintf("transfer rate limit must not be negative, got %d", opts.transferRate),
+		}
+	}
+	dockerOpts, err := transportOpts(opts)
+	if err != nil {
+		return nil, err
+	}
+	var limiter *rateLimiter
+	if opts.transferRate > 0 {
+		limiter = newRateLimiter(opts.transferRate, opts.clock)
+		dockerOpts = append(dockerOpts, withThrottledDial(limiter))
+	}
+
+	c, err := client.NewClientWithOpts(dockerOpts...)
 	if err != nil {
 		return nil, &errdefs.ConfigError{
 			Field:   "client",
 			Message: err.Error(),
 		}
 	}
-	ok, err := isDaemonRunning(ctx, c)
-	if err != nil {
-		return nil, &errdefs.DaemonNotRunningError{
-			Message: err.Error(),
+
+	backoff := opts.daemonBackoff
+	for attempt := 0; ; attempt++ {
+		ok, err := isDaemonRunning(ctx, c)
+		if err == nil && ok {
+			break
 		}
+		if attempt >= opts.daemonRetries {
+			if err != nil {
+				return nil, &errdefs.DaemonNotRunningError{
+					Message: err.Error(),
+				}
+			}
+			return nil, errdefs.ErrDaemonNotRunning
+		}
+		if err := opts.clock.Sleep(ctx, backoff); err != nil {
+			return nil, &errdefs.DaemonNotRunningError{
+				Message: err.Error(),
+			}
+		}
+		backoff *= 2
+	}
+	cli := &Client{
+		wrapped:   c,
+		clock:     opts.clock,
+		recorders: opts.recorders,
+		auth:      opts.auth,
+		logStdout: opts.logStdout,
+		logStderr: opts.logStderr,
+		logger:    opts.logger,
+		verifiers: opts.verifiers,
+		vars:      opts.vars,
+		profiles:  opts.profiles,
+		limiter:   limiter,
 	}
-	if !ok {
-		return nil, errdefs.ErrDaemonNotRunning
+	if opts.inspectCacheTTL > 0 {
+		cli.inspects = newInspectCache(opts.inspectCacheTTL, opts.clock)
 	}
-	return &Client{
-		wrapped: c,
-	}, nil
+	return cli, nil
+}
+
+// Clock returns the clock used by the client for retries, backoff and waiting.
+func (c *Client) Clock() clock.Clock {
+	if c.clock == nil {
+		return clock.Real()
+	}
+	return c.clock
+}
+
+// Close stops the background work of the client, such as watching events for the inspect cache,
+// and closes the connection to the daemon.
+func (c *Client) Close() error {
+	if c.inspects != nil {
+		c.inspects.close()
+	}
+	return c.wrapped.Close()
 }
 
 // Unwraps the abstracted client for use with other docker packages
@@ -296,42 +649,44 @@ var isDaemonRunning = func(ctx context.Context, client client.APIClient) (bool,
 // Network Operations
 
 func (c *Client) NetworkRemove(ctx context.Context, networkID string) error {
-	return c.wrapped.NetworkRemove(ctx, networkID)
+	return networkOpError(networkID, "remove", c.wrapped.NetworkRemove(ctx, networkID))
 }
 
-func (c *Client) NetworkConnect(ctx context.Context, networkConfig *network.NetworkConfig, containerConfig *container.ContainerConfig) error {
-	// Create endpoint settings
-	endpointSettings := &dockerNetwork.EndpointSettings{
-		NetworkID: networkConfig.Id,
-	}
-
-	err := c.wrapped.NetworkConnect(ctx, networkConfig.Id, containerConfig.Id, endpointSettings)
-	if err != nil {
-		return fmt.Errorf("failed to connect container to network: %w", err)
+// NetworkConnect connects a container to a network. Provide an endpoint from the endpointoptions package to set
+// aliases, addresses or driver options of the connection.
+func (c *Client) NetworkConnect(ctx context.Context, networkConfig *network.NetworkConfig, containerConfig *container.ContainerConfig, endpoints ...*endpointoptions.Endpoint) error {
+	if err := validateNetworkConfig(networkConfig); err != nil {
+		return err
 	}
-
-	// Verify connection
-	network, err := c.wrapped.NetworkInspect(ctx, networkConfig.Id, dockerNetwork.InspectOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to verify network connection: %w", err)
+	if err := validateContainerConfig(containerConfig); err != nil {
+		return err
 	}
-
-	// Verify the container is in the network
-	if _, exists := network.Containers[containerConfig.Id]; !exists {
-		return fmt.Errorf("container %s not found in network %s after connection", containerConfig.Id, networkConfig.Id)
+	settings := &dockerNetwork.EndpointSettings{}
+	for _, endpoint := range endpoints {
+		if endpoint != nil && endpoint.Settings != nil {
+			settings = endpoint.Settings
+		}
 	}
-
-	return nil
+	defer c.invalidateInspect(containerConfig.Id)
+	return networkOpError(networkConfig.Id, "connect", c.wrapped.NetworkConnect(ctx, networkConfig.Id, containerConfig.Id, settings))
 }
 
+// NetworkDisconnect disconnects a container from a network. Force disconnects it even when it is not running.
 func (c *Client) NetworkDisconnect(ctx context.Context, networkConfig *network.NetworkConfig, containerConfig *container.ContainerConfig, force bool) error {
-	return c.wrapped.NetworkDisconnect(ctx, networkConfig.Id, containerConfig.Id, force)
+	if err := validateNetworkConfig(networkConfig); err != nil {
+		return err
+	}
+	if err := validateContainerConfig(containerConfig); err != nil {
+		return err
+	}
+	defer c.invalidateInspect(containerConfig.Id)
+	return networkOpError(networkConfig.Id, "disconnect", c.wrapped.NetworkDisconnect(ctx, networkConfig.Id, containerConfig.Id, force))
 }
 
 // Volume Operations
 
 func (c *Client) VolumeRemove(ctx context.Context, name string, force bool) error {
-	return c.wrapped.VolumeRemove(ctx, name, force)
+	return volumeOpError(name, "remove", c.wrapped.VolumeRemove(ctx, name, force))
 }
 
 type PruneVolumeOptionFn func(*filters.Args)
@@ -383,8 +738,10 @@ func (c *Client) VolumePrune(ctx context.Context, pruneVolumeOptionFns ...PruneV
 			fn(&args)
 		}
 	}
-	// Log the filter arguments
-	fmt.Printf("Volume prune filter args: %+v\n", args)
+	if err := volumePruneFilters.validate(args); err != nil {
+		return nil, err
+	}
+	c.log().DebugContext(ctx, "pruning volumes", "op", "volume prune", "filters", args)
 	report, err := c.wrapped.VolumesPrune(ctx, args)
 	if err != nil {
 		return nil, err
@@ -392,61 +749,61 @@ func (c *Client) VolumePrune(ctx context.Context, pruneVolumeOptionFns ...PruneV
 	return &report, nil
 }
 
+// ImagePush requests the docker host to push an image to a remote registry.
+// Caller is responsible for closing the response body. Failures reported inside the stream,
+// such as registry authentication errors, are only visible while reading it; use ImagePushWithProgress
+// to have them returned as errors.
 func (c *Client) ImagePush(ctx context.Context, imageConfig *image.ImageConfig) (io.ReadCloser, error) {
-	rc, err := c.wrapped.ImagePush(ctx, imageConfig.Ref, *imageConfig.PushOptions)
-	if err != nil {
+	if imageConfig == nil || imageConfig.Ref == "" {
+		return nil, &errdefs.ValidationError{
+			Field:   "imageConfig",
+			Message: "image config or reference cannot be empty",
+		}
+	}
+	if err := imageConfig.Validate(); err != nil {
 		return nil, err
 	}
-	return rc, nil
+	pushOptions := *imageConfig.PushOptions
+	if pushOptions.RegistryAuth == "" {
+		encoded, err := c.registryAuthFor(ctx, imageConfig.Ref)
+		if err != nil {
+			return nil, err
+		}
+		pushOptions.RegistryAuth = encoded
+	}
+
+	start := c.Clock().Now()
+	rc, err := c.wrapped.ImagePush(ctx, imageConfig.Ref, pushOptions)
+	if err != nil {
+		c.recordTransfer(metrics.ImageTransfer{
+			Operation: metrics.OperationPush,
+			Ref:       imageConfig.Ref,
+			Duration:  c.Clock().Now().Sub(start),
+			Err:       err,
+		})
+		return nil, newImageError(imageConfig.Ref, "push", err)
+	}
+	return c.meterTransfer(metrics.OperationPush, imageConfig.Ref, start, c.paceTransfer(ctx, rc), nil), nil
 }
 
 func (c *Client) ImageRemove(ctx context.Context, imageID string, force bool, pruneChildren bool) ([]imageType.DeleteResponse, error) {
-	return c.wrapped.ImageRemove(ctx, imageID, imageType.RemoveOptions{
+	deleted, err := c.wrapped.ImageRemove(ctx, imageID, imageType.RemoveOptions{
 		Force:         force,
 		PruneChildren: pruneChildren,
 	})
+	return deleted, imageOpError(imageID, "remove", err)
 }
 
 func (c *Client) ImageTag(ctx context.Context, imageConfig *image.ImageConfig, newTag string) error {
-	return c.wrapped.ImageTag(ctx, imageConfig.Ref, newTag)
-}
-
-func (c *Client) ImageSave(ctx context.Context, imageConfig *image.ImageConfig, outputFile string) error {
-	rc, err := c.wrapped.ImageSave(ctx, []string{imageConfig.Ref})
-	if err != nil {
-		return err
-	}
-	defer rc.Close()
-
-	file, err := os.Create(outputFile)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	_, err = io.Copy(file, rc)
-	return err
-}
-
-func (c *Client) ImageLoad(ctx context.Context, inputFile string) (io.ReadCloser, error) {
-	file, err := os.Open(inputFile)
-	if err != nil {
-		return nil, err
-	}
-
-	res, err := c.wrapped.ImageLoad(ctx, file, true)
-	if err != nil {
-		return nil, err
-	}
-	return res.Body, nil
+	return imageOpError(imageConfig.Ref, "tag", c.wrapped.ImageTag(ctx, imageConfig.Ref, newTag))
 }
 
 type VolumeListOptionFn func(*volumeType.ListOptions)
 
+// WithVolumeFilter adds a filter to the volume list operation.
+// Prefer WithVolumeFilters with the typed Filter builders; use this for filters without a builder.
 func WithVolumeFilter(key, value string) VolumeListOptionFn {
-	return func(opts *volumeType.ListOptions) {
-		opts.Filters.Add(key, value)
-	}
+	return WithVolumeFilters(Filter{Key: key, Value: value})
 }
 
 func (c *Client) VolumeList(ctx context.Context, volumeListOptionFns ...VolumeListOptionFn) (volumeType.ListResponse, error) {
@@ -458,6 +815,9 @@ func (c *Client) VolumeList(ctx context.Context, volumeListOptionFns ...VolumeLi
 			fn(&opts)
 		}
 	}
+	if err := volumeListFilters.validate(opts.Filters); err != nil {
+		return volumeType.ListResponse{}, err
+	}
 	vols, err := c.wrapped.VolumeList(ctx, opts)
 	if err != nil {
 		return volumeType.ListResponse{}, fmt.Errorf("inspect volume failed: %w", err)
@@ -469,13 +829,9 @@ func (c *Client) VolumeList(ctx context.Context, volumeListOptionFns ...VolumeLi
 type ImageListOptionFn func(*imageType.ListOptions)
 
 // WithImageFilter adds a filter to the image list operation.
+// Prefer WithImageFilters with the typed Filter builders; use this for filters without a builder.
 func WithImageFilter(key, value string) ImageListOptionFn {
-	return func(opts *imageType.ListOptions) {
-		if opts.Filters.Get(key) == nil {
-			opts.Filters = filters.NewArgs()
-		}
-		opts.Filters.Add(key, value)
-	}
+	return WithImageFilters(Filter{Key: key, Value: value})
 }
 
 // WithImageAll sets the all flag to true in the image list operation.
@@ -515,6 +871,9 @@ func (c *Client) ImageList(ctx context.Context, imageListOptionFns ...ImageListO
 			fn(&opts)
 		}
 	}
+	if err := imageListFilters.validate(opts.Filters); err != nil {
+		return nil, err
+	}
 	imgs, err := c.wrapped.ImageList(ctx, opts)
 	if err != nil {
 		return nil, fmt.Errorf("inspect image failed: %w", err)
@@ -529,62 +888,164 @@ func (c *Client) ImageList(ctx context.Context, imageListOptionFns ...ImageListO
 // - An error occurs
 // - The context is cancelled
 // Use context with timeout or cancellation to control the maximum wait time.
-func (c *Client) RunAndWait(ctx context.Context, containerConfig *container.ContainerConfig) error {
+// By default a cancelled container keeps running; use runoptions.KillOnCancel or
+// runoptions.RemoveOnCancel to clean it up, which is done on a context detached from ctx.
+// A non-zero exit code is returned as a ContainerError wrapping an ExitError that explains the exit.
+// With runoptions.WithFailureLogTail the error also holds the last lines of output of the container.
+func (c *Client) RunAndWait(ctx context.Context, containerConfig *container.ContainerConfig, runOptFns ...runoptions.SetRunOptFn) error {
+	opts := runoptions.NewRunOptions()
+	for _, fn := range runOptFns {
+		if fn != nil {
+			fn(opts)
+		}
+	}
+
 	if err := c.ContainerCreate(ctx, containerConfig); err != nil {
 		return err
 	}
+	logTail := func() []string { return nil }
+	if opts.FailureLogTail > 0 {
+		var stop func()
+		logTail, stop = c.attachLogTail(ctx, containerConfig, opts.FailureLogTail)
+		defer stop()
+	}
 
 	if err := c.ContainerStart(ctx, containerConfig); err != nil {
+		if ctx.Err() != nil {
+			return c.cancelRun(ctx, containerConfig, opts)
+		}
 		return err
 	}
 
 	statusCh, errCh := c.ContainerWait(ctx, containerConfig)
 	select {
 	case err := <-errCh:
+		// The wait also fails when ctx is done.
+		if ctx.Err() != nil {
+			return c.cancelRun(ctx, containerConfig, opts)
+		}
 		return &errdefs.ContainerError{
 			ID:      containerConfig.Name,
 			Op:      "wait",
 			Message: err.Error(),
+			Err:     err,
+			Logs:    logTail(),
 		}
 	case status := <-statusCh:
 		if status.StatusCode != 0 {
+			info, err := c.ContainerExitInfo(ctx, containerConfig)
+			if err != nil || info.Running || int64(info.ExitCode) != status.StatusCode {
+				// The container is gone or was restarted, the exit code is all there is to report.
+				info = &ContainerExitInfo{ExitCode: int(status.StatusCode)}
+			}
 			return &errdefs.ContainerError{
 				ID:      containerConfig.Name,
 				Op:      "run",
-				Message: fmt.Sprintf("exited with code %d", status.StatusCode),
+				Message: info.Explain(),
+				Err:     &ExitError{Info: info},
+				Logs:    logTail(),
 			}
 		}
 		return nil
 	case <-ctx.Done():
-		switch ctx.Err() {
-		case context.DeadlineExceeded:
-			return errdefs.ErrTimeout
-		case context.Canceled:
-			return errdefs.ErrCanceled
-		default:
-			return ctx.Err()
+		return c.cancelRun(ctx, containerConfig, opts)
+	}
+}
+
+// cancelRun cleans up a container whose run was cancelled, as configured by the run options,
+// and returns the cancellation error joined with any cleanup error.
+func (c *Client) cancelRun(ctx context.Context, containerConfig *container.ContainerConfig, opts *runoptions.RunOptions) error {
+	// Wrap the context error too, so errors.Is works with both it and the errdefs error.
+	cancelErr := ctx.Err()
+	switch cancelErr {
+	case context.DeadlineExceeded:
+		cancelErr = fmt.Errorf("%w: %w", errdefs.ErrTimeout, cancelErr)
+	case context.Canceled:
+		cancelErr = fmt.Errorf("%w: %w", errdefs.ErrCanceled, cancelErr)
+	}
+	if !opts.KillOnCancel && !opts.RemoveOnCancel {
+		return cancelErr
+	}
+
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), opts.CleanupTimeout)
+	defer cancel()
+	var err error
+	if opts.RemoveOnCancel {
+		if err = c.wrapped.ContainerRemove(cleanupCtx, containerConfig.Id, containerType.RemoveOptions{Force: true}); err != nil {
+			err = &errdefs.ContainerError{ID: containerConfig.Name, Op: "remove", Message: err.Error(), Err: err}
 		}
+	} else if err = c.wrapped.ContainerKill(cleanupCtx, containerConfig.Id, "SIGKILL"); err != nil {
+		// The container may have exited on its own in the meantime.
+		if dockerErrdefs.IsConflict(err) {
+			err = nil
+		} else {
+			err = &errdefs.ContainerError{ID: containerConfig.Name, Op: "kill", Message: err.Error(), Err: err}
+		}
+	}
+	if err != nil && !client.IsErrNotFound(err) {
+		return errors.Join(cancelErr, err)
 	}
+	return cancelErr
 }
 
-// IsContainerRunning checks if a container is currently running
+// IsContainerRunning checks if a container is currently running.
+// It is served from the inspect cache when the client was created WithInspectCache.
 func (c *Client) IsContainerRunning(ctx context.Context, containerConfig *container.ContainerConfig) (bool, error) {
-	container, err := c.wrapped.ContainerInspect(ctx, containerConfig.Id)
+	container, err := c.inspectContainer(ctx, containerConfig.Id)
 	if err != nil {
 		return false, fmt.Errorf("inspect container failed: %w", err)
 	}
 	return container.State.Running, nil
 }
 
-// GetContainerExitCode returns the exit code of a container
+// GetContainerExitCode returns the exit code of a container.
+// It is served from the inspect cache when the client was created WithInspectCache.
 func (c *Client) GetContainerExitCode(ctx context.Context, containerConfig *container.ContainerConfig) (int, error) {
-	container, err := c.wrapped.ContainerInspect(ctx, containerConfig.Id)
+	container, err := c.inspectContainer(ctx, containerConfig.Id)
 	if err != nil {
 		return 0, fmt.Errorf("inspect container failed: %w", err)
 	}
 	return container.State.ExitCode, nil
 }
 
+/*
+GetContainerIPAddress returns the IP address of a container on a network. With an empty network name it returns
+the address on the first network the container is connected to, by name. It returns a ResourceNotFoundError when the
+container has no address on the network. It is served from the inspect cache when the client was created
+WithInspectCache.
+
+Usage example:
+
+	ip, err := client.GetContainerIPAddress(ctx, db, "backend")
+*/
+func (c *Client) GetContainerIPAddress(ctx context.Context, containerConfig *container.ContainerConfig, networkName string) (string, error) {
+	if containerConfig == nil || containerConfig.Id == "" {
+		return "", &errdefs.ValidationError{Field: "containerConfig", Message: "container config or id cannot be empty"}
+	}
+	inspect, err := c.inspectContainer(ctx, containerConfig.Id)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return "", &errdefs.ResourceNotFoundError{ResourceType: "container", ID: containerConfig.Id, Err: err}
+		}
+		return "", fmt.Errorf("inspect container failed: %w", err)
+	}
+	if inspect.NetworkSettings != nil {
+		names := make([]string, 0, len(inspect.NetworkSettings.Networks))
+		for name := range inspect.NetworkSettings.Networks {
+			if networkName == "" || name == networkName {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if endpoint := inspect.NetworkSettings.Networks[name]; endpoint != nil && endpoint.IPAddress != "" {
+				return endpoint.IPAddress, nil
+			}
+		}
+	}
+	return "", &errdefs.ResourceNotFoundError{ResourceType: "container address", ID: containerConfig.Id}
+}
+
 // GetImageSize returns the size of an image in bytes
 func (c *Client) GetImageSize(ctx context.Context, imageConfig *image.ImageConfig) (int64, error) {
 	img, _, err := c.wrapped.ImageInspectWithRaw(ctx, imageConfig.Ref)
@@ -685,8 +1146,10 @@ func (c *Client) RunAsync(ctx context.Context, containerConfig *container.Contai
 
 // ContainerExecAttachTerminal attaches to a container exec command and returns a terminal session
 // that can be used to interact with the command. The session handles terminal setup,
-// raw mode, and cleanup automatically.
-func (c *Client) ContainerExecAttachTerminal(ctx context.Context, containerConfig *container.ContainerConfig, execConfig *exec.ExecConfig) (*terminal.Session, error) {
+// raw mode, and cleanup automatically. When stdin is not a terminal, such as in a CI pipeline,
+// the session copies the streams as they are. Option functions from the terminal package,
+// such as terminal.WithRecorder, configure the session.
+func (c *Client) ContainerExecAttachTerminal(ctx context.Context, containerConfig *container.ContainerConfig, execConfig *exec.ExecConfig, sessionOptFns ...terminal.SetSessionOptFn) (*terminal.Session, error) {
 	res, err := c.wrapped.ContainerExecCreate(ctx, containerConfig.Id, *execConfig.Options)
 	execConfig.ID = res.ID
 	if err != nil {
@@ -702,7 +1165,7 @@ func (c *Client) ContainerExecAttachTerminal(ctx context.Context, containerConfi
 	}
 
 	// Create and return a new terminal session
-	session, err := terminal.NewSession(os.Stdin, hijack.Conn, hijack.Reader)
+	session, err := terminal.NewSession(os.Stdin, hijack.Conn, hijack.Reader, sessionOptFns...)
 	if err != nil {
 		hijack.Close()
 		return nil, fmt.Errorf("failed to create terminal session: %w", err)
@@ -739,12 +1202,14 @@ func (c *Client) ContainerExecCreate(ctx context.Context, containerConfig *conta
 			return "", &errdefs.ResourceNotFoundError{
 				ResourceType: "container",
 				ID:           containerConfig.Id,
+				Err:          err,
 			}
 		}
 		return "", &errdefs.ExecError{
 			ID:      containerConfig.Id,
 			Op:      "create",
 			Message: err.Error(),
+			Err:     err,
 		}
 	}
 	execConfig.ID = res.ID
@@ -769,12 +1234,14 @@ func (c *Client) ContainerExecStart(ctx context.Context, containerConfig *contai
 			return &errdefs.ResourceNotFoundError{
 				ResourceType: "exec",
 				ID:           execConfig.ID,
+				Err:          err,
 			}
 		}
 		return &errdefs.ExecError{
 			ID:      execConfig.ID,
 			Op:      "start",
 			Message: err.Error(),
+			Err:     err,
 		}
 	}
 	return nil
@@ -789,6 +1256,37 @@ func (c *Client) ContainerExecInspect(ctx context.Context, execConfig *exec.Exec
 	return &inspect, nil
 }
 
+/*
+ContainerResize resizes the TTY of a container's main process, e.g. when the terminal it is rendered in is
+resized. The container must have been created with a TTY, see containeroptions.TTY; its initial size can be
+set with hostoptions.ConsoleSize.
+
+Usage example:
+
+	if err := client.ContainerResize(ctx, shell, 40, 120); err != nil {
+		return err
+	}
+*/
+func (c *Client) ContainerResize(ctx context.Context, containerConfig *container.ContainerConfig, height, width uint) error {
+	if containerConfig == nil || containerConfig.Id == "" {
+		return &errdefs.ValidationError{Field: "containerConfig", Message: "container config or id cannot be empty"}
+	}
+	if height == 0 || width == 0 {
+		return &errdefs.ValidationError{Field: "size", Message: fmt.Sprintf("console size %dx%d must not be empty", height, width)}
+	}
+	err := c.wrapped.ContainerResize(ctx, containerConfig.Id, containerType.ResizeOptions{
+		Height: height,
+		Width:  width,
+	})
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return &errdefs.ResourceNotFoundError{ResourceType: "container", ID: containerConfig.Id, Err: err}
+		}
+		return &errdefs.ContainerError{ID: containerConfig.Name, Op: "resize", Message: err.Error(), Err: err}
+	}
+	return nil
+}
+
 // ContainerExecResize resizes the TTY of a container exec command.
 func (c *Client) ContainerExecResize(ctx context.Context, containerConfig *container.ContainerConfig, execConfig *exec.ExecConfig, height, width uint) error {
 	return c.wrapped.ContainerExecResize(ctx, execConfig.ID, containerType.ResizeOptions{
@@ -797,19 +1295,18 @@ func (c *Client) ContainerExecResize(ctx context.Context, containerConfig *conta
 	})
 }
 
-// ContainerExport retrieves the raw contents of a container and returns them as an io.ReadCloser. It's up to the caller to close the stream.
-func (c *Client) ContainerExport(ctx context.Context, containerConfig *container.ContainerConfig) (io.ReadCloser, error) {
-	return c.wrapped.ContainerExport(ctx, containerConfig.Id)
-}
-
-// NetworkConnect connects a container to a network.
+// NetworkConnectContainer connects a container to a network by their IDs.
+//
+// Deprecated: use NetworkConnect, which validates and types its errors the same way.
 func (c *Client) NetworkConnectContainer(ctx context.Context, networkID string, containerID string, endpoint *endpointoptions.Endpoint) error {
-	return c.wrapped.NetworkConnect(ctx, networkID, containerID, endpoint.Settings)
+	return c.NetworkConnect(ctx, &network.NetworkConfig{Id: networkID}, &container.ContainerConfig{Id: containerID}, endpoint)
 }
 
-// NetworkDisconnect disconnects a container from a network.
+// NetworkDisconnectContainer disconnects a container from a network by their IDs.
+//
+// Deprecated: use NetworkDisconnect, which validates and types its errors the same way.
 func (c *Client) NetworkDisconnectContainer(ctx context.Context, networkID string, containerID string, force bool) error {
-	return c.wrapped.NetworkDisconnect(ctx, networkID, containerID, force)
+	return c.NetworkDisconnect(ctx, &network.NetworkConfig{Id: networkID}, &container.ContainerConfig{Id: containerID}, force)
 }
 
 type NetworkInspectOptionFn func(*dockerNetwork.InspectOptions)
@@ -840,10 +1337,38 @@ func (c *Client) NetworkInspect(ctx context.Context, networkID string, networkIn
 
 type NetworkListOptionFn func(*dockerNetwork.ListOptions)
 
+// WithNetworkFilter adds a filter to the network list operation.
+// Prefer WithNetworkFilters with the typed Filter builders; use this for filters without a builder.
 func WithNetworkFilter(key, value string) NetworkListOptionFn {
-	return func(opts *dockerNetwork.ListOptions) {
-		opts.Filters.Add(key, value)
+	return WithNetworkFilters(Filter{Key: key, Value: value})
+}
+
+// WithNetworkDriver lists only networks created with the given driver, e.g. "bridge" or "overlay".
+func WithNetworkDriver(driver string) NetworkListOptionFn {
+	return WithNetworkFilter("driver", driver)
+}
+
+// WithNetworkLabel lists only networks with the given label. An empty value matches any value.
+func WithNetworkLabel(key, value string) NetworkListOptionFn {
+	if value == "" {
+		return WithNetworkFilter("label", key)
 	}
+	return WithNetworkFilter("label", key+"="+value)
+}
+
+// WithNetworkDangling lists only networks that are, or are not, used by any container.
+func WithNetworkDangling(dangling bool) NetworkListOptionFn {
+	return WithNetworkFilter("dangling", strconv.FormatBool(dangling))
+}
+
+// WithNetworkScope lists only networks with the given scope: "local", "global" or "swarm".
+func WithNetworkScope(scope string) NetworkListOptionFn {
+	return WithNetworkFilter("scope", scope)
+}
+
+// WithCustomNetworks lists only user-defined networks, leaving out the bridge, host and none networks.
+func WithCustomNetworks() NetworkListOptionFn {
+	return WithNetworkFilter("type", "custom")
 }
 
 func (c *Client) NetworkList(ctx context.Context, networkListOptionFns ...NetworkListOptionFn) ([]dockerNetwork.Summary, error) {
@@ -855,6 +1380,9 @@ func (c *Client) NetworkList(ctx context.Context, networkListOptionFns ...Networ
 			fn(&opts)
 		}
 	}
+	if err := networkListFilters.validate(opts.Filters); err != nil {
+		return nil, err
+	}
 	networks, err := c.wrapped.NetworkList(ctx, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list networks: %w", err)
@@ -865,13 +1393,9 @@ func (c *Client) NetworkList(ctx context.Context, networkListOptionFns ...Networ
 type ListContainerOptionFn func(*containerType.ListOptions)
 
 // WithContainerFilter adds a filter to the container list operation.
+// Prefer WithContainerFilters with the typed Filter builders; use this for filters without a builder.
 func WithContainerFilter(key, value string) ListContainerOptionFn {
-	return func(opts *containerType.ListOptions) {
-		if opts.Filters.Get(key) == nil {
-			opts.Filters = filters.NewArgs()
-		}
-		opts.Filters.Add(key, value)
-	}
+	return WithContainerFilters(Filter{Key: key, Value: value})
 }
 
 // WithContainerAll sets the all flag to true in the container list operation.
@@ -920,6 +1444,9 @@ func (c *Client) ContainerList(ctx context.Context, listOptionFns ...ListContain
 		}
 	}
 
+	if err := containerListFilters.validate(listOpts.Filters); err != nil {
+		return nil, err
+	}
 	containers, err := c.wrapped.ContainerList(ctx, listOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list containers: %w", err)
@@ -1005,7 +1532,8 @@ func (c *Client) ImageCommit(ctx context.Context, containerConfig *container.Con
 // UpdateOptionFn is a function that can be used to update a container.
 type UpdateOptionFn func(*containerType.UpdateConfig)
 
-// ContainerUpdate updates a container with new configuration.
+// ContainerUpdate updates a container with new configuration. The update is checked with
+// ValidateUpdateConfig before it is sent to the daemon.
 func (c *Client) ContainerUpdate(ctx context.Context, containerConfig *container.ContainerConfig, updateOptions ...UpdateOptionFn) (*containerType.ContainerUpdateOKBody, error) {
 	options := containerType.UpdateConfig{}
 	for _, fn := range updateOptions {
@@ -1013,7 +1541,11 @@ func (c *Client) ContainerUpdate(ctx context.Context, containerConfig *container
 			fn(&options)
 		}
 	}
+	if err := ValidateUpdateConfig(options); err != nil {
+		return nil, err
+	}
 
+	defer c.invalidateInspect(containerConfig.Id)
 	res, err := c.wrapped.ContainerUpdate(ctx, containerConfig.Id, options)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update container: %w", err)
@@ -1032,13 +1564,21 @@ func (c *Client) ContainerDiff(ctx context.Context, containerConfig *container.C
 
 // ContainerKill kills a container.
 func (c *Client) ContainerKill(ctx context.Context, containerConfig *container.ContainerConfig, signal string) error {
-	return c.wrapped.ContainerKill(ctx, containerConfig.Id, signal)
+	if err := validateContainerConfig(containerConfig); err != nil {
+		return err
+	}
+	defer c.invalidateInspect(containerConfig.Id)
+	return containerOpError(containerConfig, "kill", c.wrapped.ContainerKill(ctx, containerConfig.Id, signal))
 }
 
 // ContainerRename renames a container.
 func (c *Client) ContainerRename(ctx context.Context, containerConfig *container.ContainerConfig, newName string) error {
+	if err := validateContainerConfig(containerConfig); err != nil {
+		return err
+	}
 	containerConfig.Name = newName
-	return c.wrapped.ContainerRename(ctx, containerConfig.Id, newName)
+	defer c.invalidateInspect(containerConfig.Id)
+	return containerOpError(containerConfig, "rename", c.wrapped.ContainerRename(ctx, containerConfig.Id, newName))
 }
 
 // ContainerTop returns the top process information for a container.
@@ -1063,10 +1603,9 @@ func (c *Client) ContainerInspect(ctx context.Context, containerConfig *containe
 type PruneOptionFn func(*filters.Args)
 
 // WithPruneFilter adds a filter to the prune operation.
+// Prefer WithPruneFilters with the typed Filter builders; use this for filters without a builder.
 func WithPruneFilter(key, value string) PruneOptionFn {
-	return func(filter *filters.Args) {
-		filter.Add(key, value)
-	}
+	return WithPruneFilters(Filter{Key: key, Value: value})
 }
 
 // ContainerPrune prunes containers based on the provided options.
@@ -1079,6 +1618,9 @@ func (c *Client) ContainerPrune(ctx context.Context, pruneOptions ...PruneOption
 			fn(&filter)
 		}
 	}
+	if err := containerPruneFilters.validate(filter); err != nil {
+		return nil, err
+	}
 	prune, err := c.wrapped.ContainersPrune(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prune containers: %w", err)
@@ -1093,6 +1635,9 @@ func (c *Client) ImagesPrune(ctx context.Context, pruneOptions ...PruneOptionFn)
 			fn(&filter)
 		}
 	}
+	if err := imagePruneFilters.validate(filter); err != nil {
+		return nil, err
+	}
 	prune, err := c.wrapped.ImagesPrune(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prune images: %w", err)
@@ -1101,6 +1646,26 @@ func (c *Client) ImagesPrune(ctx context.Context, pruneOptions ...PruneOptionFn)
 	return &prune, nil
 }
 
+// NetworkPrune removes all user-defined networks that are not used by any container and match the filters.
+// The supported filters are "label", "label!" and "until".
+// It returns a PruneReport containing the names of the networks deleted.
+func (c *Client) NetworkPrune(ctx context.Context, pruneOptions ...PruneOptionFn) (*dockerNetwork.PruneReport, error) {
+	filter := filters.NewArgs()
+	for _, fn := range pruneOptions {
+		if fn != nil {
+			fn(&filter)
+		}
+	}
+	if err := networkPruneFilters.validate(filter); err != nil {
+		return nil, err
+	}
+	prune, err := c.wrapped.NetworksPrune(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune networks: %w", err)
+	}
+	return &prune, nil
+}
+
 func (c *Client) ImageHistory(ctx context.Context, imageID string) ([]imageType.HistoryResponseItem, error) {
 	history, err := c.wrapped.ImageHistory(ctx, imageID)
 	if err != nil {
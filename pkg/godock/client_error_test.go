@@ -2,7 +2,9 @@ package godock
 
 import (
 	"context"
+	"errors"
 	"io"
+	"net/http"
 	"os"
 	"testing"
 	"time"
@@ -12,6 +14,9 @@ import (
 	"github.com/aptd3v/godock/pkg/godock/errdefs"
 	"github.com/aptd3v/godock/pkg/godock/hostoptions"
 	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/aptd3v/godock/pkg/godock/volume"
+	dockerErrdefs "github.com/docker/docker/errdefs"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -41,7 +46,7 @@ func TestClienterrdefs(t *testing.T) {
 
 					// Remove container with force
 					removeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-					client.ContainerRemove(removeCtx, &container.ContainerConfig{Id: c.ID}, true)
+					client.ContainerRemove(removeCtx, &container.ContainerConfig{Id: c.ID}, removeoptions.Force(), removeoptions.RemoveVolumes())
 					cancel()
 
 					// Wait a bit to ensure removal is complete
@@ -99,7 +104,7 @@ func TestClienterrdefs(t *testing.T) {
 		require.NoError(t, err)
 		defer func() {
 			client.ContainerStop(ctx, config)
-			client.ContainerRemove(ctx, config, true)
+			client.ContainerRemove(ctx, config, removeoptions.Force(), removeoptions.RemoveVolumes())
 		}()
 
 		// Try to create container with same name
@@ -134,7 +139,7 @@ func TestClienterrdefs(t *testing.T) {
 		require.NoError(t, err)
 		defer func() {
 			client.ContainerStop(ctx, config)
-			client.ContainerRemove(ctx, config, true)
+			client.ContainerRemove(ctx, config, removeoptions.Force(), removeoptions.RemoveVolumes())
 		}()
 
 		time.Sleep(2 * time.Second) // Wait for container to fully start
@@ -149,7 +154,7 @@ func TestClienterrdefs(t *testing.T) {
 		config2.SetHostOptions(hostoptions.PortBindings("0.0.0.0", "8080", "80/tcp"))
 		err = client.ContainerCreate(ctx, config2)
 		require.NoError(t, err) // Creation should succeed
-		defer client.ContainerRemove(ctx, config2, true)
+		defer client.ContainerRemove(ctx, config2, removeoptions.Force(), removeoptions.RemoveVolumes())
 
 		err = client.ContainerStart(ctx, config2)
 		require.Error(t, err)
@@ -183,7 +188,7 @@ func TestClienterrdefs(t *testing.T) {
 		require.NoError(t, err)
 		defer func() {
 			client.ContainerStop(ctx, config)
-			client.ContainerRemove(ctx, config, true)
+			client.ContainerRemove(ctx, config, removeoptions.Force(), removeoptions.RemoveVolumes())
 		}()
 
 		err = client.ContainerStart(ctx, config)
@@ -234,3 +239,60 @@ func TestClienterrdefs(t *testing.T) {
 		cleanup("test-cancel") // Clean up after test
 	})
 }
+
+func TestDaemonErrorMapping(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1.45/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		io.WriteString(w, `{"message":"Conflict. The container name \"/web\" is already in use by container \"abc\"."}`)
+	})
+	mux.HandleFunc("DELETE /v1.45/containers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		io.WriteString(w, `{"message":"You cannot remove a running container abc. Stop the container before attempting removal or force remove"}`)
+	})
+	mux.HandleFunc("POST /v1.45/volumes/create", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, `{"message":"mkdir /var/lib/docker/volumes/data: no space left on device"}`)
+	})
+	mux.HandleFunc("POST /v1.45/containers/{id}/start", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, `{"message":"driver failed programming external connectivity on endpoint web: Bind for 0.0.0.0:8080 failed: port is already allocated"}`)
+	})
+	mux.HandleFunc("GET /v1.45/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `[{"Id":"old","Names":["/old-web"],"Ports":[{"PrivatePort":80,"PublicPort":8080,"Type":"tcp"}]}]`)
+	})
+	c := newFakeDaemonClient(t, mux)
+	ctx := context.Background()
+
+	t.Run("Port conflict names the holder", func(t *testing.T) {
+		err := c.ContainerStart(ctx, &container.ContainerConfig{Id: "new", Name: "web"})
+		assert.ErrorIs(t, err, errdefs.ErrAlreadyExists)
+		assert.Equal(t, "host port 8080 is already allocated, choose another host port or stop container old-web", errdefs.Suggestion(err))
+		assert.Equal(t, 500, errdefs.DetailsOf(err).StatusCode)
+	})
+
+	t.Run("Create conflict", func(t *testing.T) {
+		cfg := container.NewConfig("web")
+		cfg.Options.Image = "alpine"
+		err := c.ContainerCreate(ctx, cfg)
+		assert.ErrorIs(t, err, errdefs.ErrAlreadyExists)
+		assert.True(t, errdefs.IsConflict(err))
+		assert.True(t, dockerErrdefs.IsConflict(errors.Unwrap(err)))
+	})
+
+	t.Run("Remove running container", func(t *testing.T) {
+		err := c.ContainerRemove(ctx, &container.ContainerConfig{Id: "abc", Name: "web"})
+		var ce *errdefs.ContainerError
+		require.ErrorAs(t, err, &ce)
+		assert.Equal(t, "remove", ce.Op)
+		assert.ErrorIs(t, err, errdefs.ErrConflict)
+		assert.False(t, errdefs.IsNotFound(err))
+	})
+
+	t.Run("No space", func(t *testing.T) {
+		err := c.VolumeCreate(ctx, volume.NewConfig("data"))
+		var ve *errdefs.VolumeError
+		require.ErrorAs(t, err, &ve)
+		assert.True(t, errdefs.IsNoSpace(err))
+	})
+}
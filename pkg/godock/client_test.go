@@ -3,23 +3,34 @@ package godock
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/aptd3v/godock/pkg/godock/clock"
 	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
 	"github.com/aptd3v/godock/pkg/godock/errdefs"
 	"github.com/aptd3v/godock/pkg/godock/exec"
 	"github.com/aptd3v/godock/pkg/godock/execoptions"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
 	"github.com/aptd3v/godock/pkg/godock/image"
 	"github.com/aptd3v/godock/pkg/godock/network"
 	"github.com/aptd3v/godock/pkg/godock/networkoptions"
 	"github.com/aptd3v/godock/pkg/godock/networkoptions/endpointoptions"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/aptd3v/godock/pkg/godock/runoptions"
+	"github.com/aptd3v/godock/pkg/godock/stopoptions"
+	"github.com/aptd3v/godock/pkg/godock/vars"
 	"github.com/aptd3v/godock/pkg/godock/volume"
 	"github.com/aptd3v/godock/pkg/godock/volumeoptions"
+	"github.com/docker/docker/api/types"
 	containerType "github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
@@ -80,6 +91,116 @@ func TestClientCreation(t *testing.T) {
 		require.Contains(t, dne.Error(), "docker daemon is not running: connection refused")
 		require.Nil(t, client)
 	})
+
+	t.Run("Daemon Retry", func(t *testing.T) {
+		origIsDaemonRunning := isDaemonRunning
+		defer func() { isDaemonRunning = origIsDaemonRunning }()
+
+		attempts := 0
+		isDaemonRunning = func(ctx context.Context, client client.APIClient) (bool, error) {
+			attempts++
+			if attempts < 3 {
+				return false, fmt.Errorf("connection refused")
+			}
+			return true, nil
+		}
+
+		fake := clock.NewFake(time.Now())
+		waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		type result struct {
+			client *Client
+			err    error
+		}
+		done := make(chan result, 1)
+		go func() {
+			c, err := NewClient(ctx, WithClock(fake), WithDaemonRetry(3, time.Second))
+			done <- result{c, err}
+		}()
+
+		// The second backoff doubles the first
+		require.NoError(t, fake.BlockUntil(waitCtx, 1))
+		fake.Advance(time.Second)
+		require.NoError(t, fake.BlockUntil(waitCtx, 1))
+		fake.Advance(2 * time.Second)
+
+		res := <-done
+		require.NoError(t, res.err)
+		require.Equal(t, 3, attempts)
+		require.Equal(t, fake, res.client.Clock())
+	})
+
+	t.Run("Daemon Retry Exhausted", func(t *testing.T) {
+		origIsDaemonRunning := isDaemonRunning
+		defer func() { isDaemonRunning = origIsDaemonRunning }()
+
+		isDaemonRunning = func(ctx context.Context, client client.APIClient) (bool, error) {
+			return false, fmt.Errorf("connection refused")
+		}
+
+		fake := clock.NewFake(time.Now())
+		done := make(chan error, 1)
+		go func() {
+			_, err := NewClient(ctx, WithClock(fake), WithDaemonRetry(1, time.Second))
+			done <- err
+		}()
+
+		waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		require.NoError(t, fake.BlockUntil(waitCtx, 1))
+		fake.Advance(time.Second)
+		require.ErrorIs(t, <-done, errdefs.ErrDaemonNotRunning)
+	})
+}
+
+func TestApplyContainerMacAddress(t *testing.T) {
+	defaultNetwork := container.NewConfig("mac-default")
+	defaultNetwork.SetContainerOptions(containeroptions.MacAddress("02:42:ac:11:00:02"))
+	applyContainerMacAddress(defaultNetwork)
+	require.Equal(t, "02:42:ac:11:00:02", defaultNetwork.NetworkingOptions.EndpointsConfig["default"].MacAddress)
+
+	customNetwork := container.NewConfig("mac-custom")
+	customNetwork.SetContainerOptions(containeroptions.MacAddress("02:42:ac:11:00:02"))
+	customNetwork.SetHostOptions(hostoptions.NetworkMode("backend"))
+	endpoint := endpointoptions.NewConfig()
+	endpoint.SetEndpointSetting(endpointoptions.MacAddress("02:42:ac:11:00:03"))
+	customNetwork.SetNetworkOptions(networkoptions.Endpoint("backend", endpoint))
+	applyContainerMacAddress(customNetwork)
+	require.Equal(t, "02:42:ac:11:00:03", customNetwork.NetworkingOptions.EndpointsConfig["backend"].MacAddress)
+
+	hostNetwork := container.NewConfig("mac-host")
+	hostNetwork.SetContainerOptions(containeroptions.MacAddress("02:42:ac:11:00:02"))
+	hostNetwork.SetHostOptions(hostoptions.NetworkMode("host"))
+	applyContainerMacAddress(hostNetwork)
+	require.Empty(t, hostNetwork.NetworkingOptions.EndpointsConfig)
+}
+
+func TestSystemOperations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	client := setupTestClient(t)
+
+	ping, err := client.Ping(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, ping.APIVersion)
+	require.NotEmpty(t, ping.OSType)
+
+	info, err := client.SystemInfo(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, info.ID)
+
+	version, err := client.SystemVersion(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, version.APIVersion)
+	require.NotEmpty(t, client.ClientVersion())
+
+	usage, err := client.DiskUsage(ctx, WithDiskUsageType(types.ImageObject))
+	require.NoError(t, err)
+	require.NotNil(t, usage)
 }
 
 func TestContainerLifecycle(t *testing.T) {
@@ -100,7 +221,7 @@ func TestContainerLifecycle(t *testing.T) {
 		err := client.ContainerCreate(ctx, containerConfig)
 		require.NoError(t, err)
 		require.NotEmpty(t, containerConfig.Id)
-		defer client.ContainerRemove(ctx, containerConfig, true)
+		defer client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes())
 
 		// Start container
 		err = client.ContainerStart(ctx, containerConfig)
@@ -128,7 +249,7 @@ func TestContainerLifecycle(t *testing.T) {
 
 		err := client.ContainerCreate(ctx, containerConfig)
 		require.NoError(t, err)
-		defer client.ContainerRemove(ctx, containerConfig, true)
+		defer client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes())
 
 		err = client.ContainerStart(ctx, containerConfig)
 		require.NoError(t, err)
@@ -172,7 +293,7 @@ func TestNetworkOperations(t *testing.T) {
 
 	err = client.ContainerCreate(ctx, containerConfig)
 	require.NoError(t, err)
-	defer client.ContainerRemove(ctx, containerConfig, true)
+	defer client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes())
 
 	err = client.ContainerStart(ctx, containerConfig)
 	require.NoError(t, err)
@@ -200,6 +321,12 @@ func TestNetworkOperations(t *testing.T) {
 	require.NotEmpty(t, containers, "No containers found in network after 5 retries")
 	require.Contains(t, containers, containerConfig.Id)
 
+	// Nothing listens in the container, so its own name resolves but the port is closed
+	res, err := client.CheckConnectivity(ctx, containerConfig, containerConfig.Name, 8080, time.Second)
+	require.NoError(t, err)
+	require.True(t, res.Resolved, res.Message)
+	require.False(t, res.Reachable, res.Message)
+
 	err = client.NetworkDisconnectContainer(ctx, networkConfig.Id, containerConfig.Id, true)
 	require.NoError(t, err)
 }
@@ -350,6 +477,32 @@ func TestImageOperations(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("Ensure and Pull All", func(t *testing.T) {
+		require.NoError(t, client.EnsureImage(ctx, image.NewConfig("alpine"), PullPolicyIfNotPresent))
+		require.NoError(t, client.EnsureImage(ctx, image.NewConfig("alpine"), PullPolicyNever))
+
+		err := client.EnsureImage(ctx, image.NewConfig("godock-missing-image:never"), PullPolicyNever)
+		require.True(t, errdefs.IsNotFound(err))
+
+		var mu sync.Mutex
+		progressRefs := make(map[string]bool)
+		results, err := client.ImagePullAll(ctx,
+			[]*image.ImageConfig{image.NewConfig("alpine"), image.NewConfig("busybox"), image.NewConfig("godock-missing-image:never")},
+			WithConcurrency(2),
+			WithPullAllProgress(func(ref string, progress ImageProgress) {
+				mu.Lock()
+				defer mu.Unlock()
+				progressRefs[ref] = true
+			}),
+		)
+		require.Error(t, err)
+		require.Len(t, results, 3)
+		require.NoError(t, results[0].Err)
+		require.NoError(t, results[1].Err)
+		require.Error(t, results[2].Err)
+		require.True(t, progressRefs["alpine"])
+	})
+
 	t.Run("Image Save and Load", func(t *testing.T) {
 		imageConfig := image.NewConfig("alpine")
 
@@ -377,7 +530,7 @@ func TestContainerUtilities(t *testing.T) {
 		containerConfig := container.NewConfig("test-run-and-wait")
 		containerConfig.Options.Image = imageConfig.Ref
 		containerConfig.Options.Cmd = []string{"echo", "hello"}
-		defer client.ContainerRemove(ctx, containerConfig, true) // Set up cleanup before any operations
+		defer client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes()) // Set up cleanup before any operations
 
 		err := client.RunAndWait(ctx, containerConfig)
 		require.NoError(t, err)
@@ -387,7 +540,7 @@ func TestContainerUtilities(t *testing.T) {
 		containerConfig := container.NewConfig("test-run-and-wait-timeout")
 		containerConfig.Options.Image = imageConfig.Ref
 		containerConfig.Options.Cmd = []string{"sleep", "5"}
-		defer client.ContainerRemove(ctx, containerConfig, true)
+		defer client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes())
 
 		timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
 		defer cancel()
@@ -397,11 +550,44 @@ func TestContainerUtilities(t *testing.T) {
 		require.Contains(t, err.Error(), context.DeadlineExceeded.Error())
 	})
 
+	t.Run("RunAndWait KillOnCancel", func(t *testing.T) {
+		containerConfig := container.NewConfig("test-run-and-wait-kill")
+		containerConfig.Options.Image = imageConfig.Ref
+		containerConfig.Options.Cmd = []string{"sleep", "30"}
+		defer client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes())
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+		defer cancel()
+
+		err := client.RunAndWait(timeoutCtx, containerConfig, runoptions.KillOnCancel())
+		require.ErrorIs(t, err, errdefs.ErrTimeout)
+
+		running, err := client.IsContainerRunning(ctx, containerConfig)
+		require.NoError(t, err)
+		require.False(t, running)
+	})
+
+	t.Run("RunAndWait RemoveOnCancel", func(t *testing.T) {
+		containerConfig := container.NewConfig("test-run-and-wait-remove")
+		containerConfig.Options.Image = imageConfig.Ref
+		containerConfig.Options.Cmd = []string{"sleep", "30"}
+		defer client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes())
+
+		cancelCtx, cancel := context.WithCancel(ctx)
+		time.AfterFunc(500*time.Millisecond, cancel)
+
+		err := client.RunAndWait(cancelCtx, containerConfig, runoptions.RemoveOnCancel())
+		require.ErrorIs(t, err, errdefs.ErrCanceled)
+
+		_, err = client.ContainerInspect(ctx, containerConfig)
+		require.Error(t, err)
+	})
+
 	t.Run("RunAsync Success", func(t *testing.T) {
 		containerConfig := container.NewConfig("test-run-async")
 		containerConfig.Options.Image = imageConfig.Ref
 		containerConfig.Options.Cmd = []string{"echo", "hello"}
-		defer client.ContainerRemove(ctx, containerConfig, true) // Set up cleanup before any operations
+		defer client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes()) // Set up cleanup before any operations
 
 		resultCh, err := client.RunAsync(ctx, containerConfig)
 		require.NoError(t, err)
@@ -418,7 +604,7 @@ func TestContainerUtilities(t *testing.T) {
 
 		err := client.ContainerCreate(ctx, containerConfig)
 		require.NoError(t, err)
-		defer client.ContainerRemove(ctx, containerConfig, true)
+		defer client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes())
 		err = client.ContainerStart(ctx, containerConfig)
 		require.NoError(t, err)
 
@@ -441,7 +627,7 @@ func TestContainerUtilities(t *testing.T) {
 			containerConfig := container.NewConfig("test-exit-success")
 			containerConfig.Options.Image = imageConfig.Ref
 			containerConfig.Options.Cmd = []string{"echo", "hello"}
-			defer client.ContainerRemove(ctx, containerConfig, true) // Set up cleanup before any operations
+			defer client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes()) // Set up cleanup before any operations
 
 			err := client.RunAndWait(ctx, containerConfig)
 			require.NoError(t, err)
@@ -455,7 +641,7 @@ func TestContainerUtilities(t *testing.T) {
 			containerConfig := container.NewConfig("test-exit-error")
 			containerConfig.Options.Image = imageConfig.Ref
 			containerConfig.Options.Cmd = []string{"sh", "-c", "exit 1"}
-			defer client.ContainerRemove(ctx, containerConfig, true) // Set up cleanup before any operations
+			defer client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes()) // Set up cleanup before any operations
 
 			err := client.RunAndWait(ctx, containerConfig)
 			require.Error(t, err)
@@ -534,7 +720,7 @@ func TestNetworkUtilities(t *testing.T) {
 
 		err = client.ContainerCreate(ctx, containerConfig)
 		require.NoError(t, err)
-		defer client.ContainerRemove(ctx, containerConfig, true)
+		defer client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes())
 
 		err = client.ContainerStart(ctx, containerConfig)
 		require.NoError(t, err)
@@ -606,11 +792,13 @@ func TestContainerLogsAndStats(t *testing.T) {
 
 	t.Run("GetContainerLogs", func(t *testing.T) {
 		containerConfig := container.NewConfig("test-logs-" + uuid.New().String())
-		containerConfig.Options.Image = imageConfig.Ref
-		containerConfig.Options.Cmd = []string{"sh", "-c", "echo stdout message && echo stderr message >&2"}
-		containerConfig.Options.AttachStdout = true
-		containerConfig.Options.AttachStderr = true
-		containerConfig.Options.Tty = false
+		containerConfig.SetContainerOptions(
+			containeroptions.Image(imageConfig),
+			containeroptions.CMD("sh", "-c", "echo stdout message && echo stderr message >&2"),
+			containeroptions.SetStdoutAttach(true),
+			containeroptions.SetStderrAttach(true),
+			containeroptions.SetTTY(false),
+		)
 
 		t.Logf("Created container config: name=%s, image=%s, cmd=%v", containerConfig.Name, containerConfig.Options.Image, containerConfig.Options.Cmd)
 
@@ -619,7 +807,7 @@ func TestContainerLogsAndStats(t *testing.T) {
 			if err := client.ContainerStop(ctx, containerConfig); err != nil {
 				t.Logf("Error stopping container: %v", err)
 			}
-			if err := client.ContainerRemove(ctx, containerConfig, true); err != nil {
+			if err := client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes()); err != nil {
 				t.Logf("Error removing container: %v", err)
 			}
 		}()
@@ -664,7 +852,7 @@ func TestContainerLogsAndStats(t *testing.T) {
 			if err := client.ContainerStop(ctx, containerConfig); err != nil {
 				t.Logf("Error stopping container: %v", err)
 			}
-			if err := client.ContainerRemove(ctx, containerConfig, true); err != nil {
+			if err := client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes()); err != nil {
 				t.Logf("Error removing container: %v", err)
 			}
 		}()
@@ -697,7 +885,7 @@ func TestContainerRestartAndLifecycle(t *testing.T) {
 			if err := client.ContainerStop(ctx, containerConfig); err != nil {
 				t.Logf("Error stopping container: %v", err)
 			}
-			if err := client.ContainerRemove(ctx, containerConfig, true); err != nil {
+			if err := client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes()); err != nil {
 				t.Logf("Error removing container: %v", err)
 			}
 		}()
@@ -724,6 +912,18 @@ func TestContainerRestartAndLifecycle(t *testing.T) {
 		running, err = client.IsContainerRunning(ctx, containerConfig)
 		require.NoError(t, err)
 		require.True(t, running)
+
+		// Restart container with a custom signal and timeout
+		err = client.ContainerRestart(ctx, containerConfig, stopoptions.StopSignal("SIGKILL"), stopoptions.StopTimeout(1))
+		require.NoError(t, err)
+
+		// Stop container with a custom timeout
+		err = client.ContainerStop(ctx, containerConfig, stopoptions.StopTimeout(1))
+		require.NoError(t, err)
+
+		running, err = client.IsContainerRunning(ctx, containerConfig)
+		require.NoError(t, err)
+		require.False(t, running)
 	})
 }
 
@@ -738,17 +938,19 @@ func TestContainerExecOperations(t *testing.T) {
 
 	t.Run("ContainerExec Basic Operations", func(t *testing.T) {
 		containerConfig := container.NewConfig("test-exec-container")
-		containerConfig.Options.Image = imageConfig.Ref
-		containerConfig.Options.Cmd = []string{"sleep", "30"}
-		containerConfig.Options.Tty = true
-		containerConfig.Options.OpenStdin = true
-		containerConfig.Options.AttachStdin = true
-		containerConfig.Options.AttachStdout = true
-		containerConfig.Options.AttachStderr = true
+		containerConfig.SetContainerOptions(
+			containeroptions.Image(imageConfig),
+			containeroptions.CMD("sleep", "30"),
+			containeroptions.SetTTY(true),
+			containeroptions.SetStdinOpen(true),
+			containeroptions.SetStdinAttach(true),
+			containeroptions.SetStdoutAttach(true),
+			containeroptions.SetStderrAttach(true),
+		)
 
 		err := client.ContainerCreate(ctx, containerConfig)
 		require.NoError(t, err)
-		defer client.ContainerRemove(ctx, containerConfig, true)
+		defer client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes())
 
 		err = client.ContainerStart(ctx, containerConfig)
 		require.NoError(t, err)
@@ -810,7 +1012,7 @@ func TestContainerAdvancedOperations(t *testing.T) {
 
 		err := client.ContainerCreate(ctx, containerConfig)
 		require.NoError(t, err)
-		defer client.ContainerRemove(ctx, containerConfig, true)
+		defer client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes())
 
 		err = client.ContainerStart(ctx, containerConfig)
 		require.NoError(t, err)
@@ -849,7 +1051,7 @@ func TestContainerAdvancedOperations(t *testing.T) {
 
 		err := client.ContainerCreate(ctx, containerConfig)
 		require.NoError(t, err)
-		defer client.ContainerRemove(ctx, containerConfig, true)
+		defer client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes())
 
 		err = client.ContainerStart(ctx, containerConfig)
 		require.NoError(t, err)
@@ -904,7 +1106,7 @@ func TestContainerAdvancedOperations(t *testing.T) {
 
 		err := client.ContainerCreate(ctx, containerConfig)
 		require.NoError(t, err)
-		defer client.ContainerRemove(ctx, containerConfig, true)
+		defer client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes())
 
 		err = client.ContainerStart(ctx, containerConfig)
 		require.NoError(t, err)
@@ -972,7 +1174,7 @@ func TestImageAdvancedOperations(t *testing.T) {
 
 		err := client.ContainerCreate(ctx, containerConfig)
 		require.NoError(t, err)
-		defer client.ContainerRemove(ctx, containerConfig, true)
+		defer client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes())
 
 		err = client.ContainerStart(ctx, containerConfig)
 		require.NoError(t, err)
@@ -1029,7 +1231,7 @@ func TestListOperations(t *testing.T) {
 		err := client.ContainerCreate(ctx, containerConfig)
 		require.NoError(t, err)
 		t.Logf("Created container with ID: %s", containerConfig.Id)
-		defer client.ContainerRemove(ctx, containerConfig, true)
+		defer client.ContainerRemove(ctx, containerConfig, removeoptions.Force(), removeoptions.RemoveVolumes())
 
 		err = client.ContainerStart(ctx, containerConfig)
 		require.NoError(t, err)
@@ -1124,6 +1326,28 @@ func TestListOperations(t *testing.T) {
 		require.Equal(t, networkConfig.Id, inspect.ID)
 	})
 
+	t.Run("Network Prune", func(t *testing.T) {
+		networkConfig := network.NewConfig("test-prune-network")
+		networkConfig.SetOptions(networkoptions.Label("godock-prune-test", "true"))
+		err := client.NetworkCreate(ctx, networkConfig)
+		require.NoError(t, err)
+		defer client.NetworkRemove(ctx, networkConfig.Id)
+
+		networks, err := client.NetworkList(ctx,
+			WithNetworkDriver("bridge"),
+			WithNetworkLabel("godock-prune-test", "true"),
+			WithNetworkDangling(true),
+			WithCustomNetworks(),
+		)
+		require.NoError(t, err)
+		require.Len(t, networks, 1)
+		require.Equal(t, networkConfig.Id, networks[0].ID)
+
+		report, err := client.NetworkPrune(ctx, WithPruneFilter("label", "godock-prune-test=true"))
+		require.NoError(t, err)
+		require.Contains(t, report.NetworksDeleted, networkConfig.Name)
+	})
+
 	t.Run("Volume List Operations", func(t *testing.T) {
 		// Create a test volume
 		volumeConfig := volume.NewConfig("test-list-volume")
@@ -1255,3 +1479,155 @@ func TestImageSearch(t *testing.T) {
 		require.Empty(t, results)
 	})
 }
+
+func TestContainerResize(t *testing.T) {
+	c := &Client{}
+	ctx := context.Background()
+	require.True(t, errdefs.IsInvalidConfig(c.ContainerResize(ctx, nil, 40, 120)))
+	unsized := container.NewConfig("unsized")
+	unsized.Id = "abc"
+	require.True(t, errdefs.IsInvalidConfig(c.ContainerResize(ctx, unsized, 0, 120)))
+
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+	client := setupTestClient(t)
+	busybox := image.NewConfig(DefaultDoctorProbeImage)
+	require.NoError(t, client.EnsureImage(ctx, busybox, PullPolicyIfNotPresent))
+
+	shell := container.NewConfig("godock-resize-" + GenerateRandomString(8))
+	shell.SetContainerOptions(
+		containeroptions.Image(busybox),
+		containeroptions.TTY(),
+		containeroptions.CMD("sleep", "60"),
+	)
+	shell.SetHostOptions(hostoptions.ConsoleSize(24, 80))
+	require.NoError(t, client.ContainerCreate(ctx, shell))
+	defer client.ContainerRemove(context.WithoutCancel(ctx), shell, removeoptions.Force())
+	require.NoError(t, client.ContainerStart(ctx, shell))
+
+	require.NoError(t, client.ContainerResize(ctx, shell, 40, 120))
+}
+
+func TestLifecycleValidation(t *testing.T) {
+	c := &Client{}
+	ctx := context.Background()
+	unsaved := container.NewConfig("web")
+	for name, call := range map[string]func(*container.ContainerConfig) error{
+		"start":   func(cfg *container.ContainerConfig) error { return c.ContainerStart(ctx, cfg) },
+		"stop":    func(cfg *container.ContainerConfig) error { return c.ContainerStop(ctx, cfg) },
+		"restart": func(cfg *container.ContainerConfig) error { return c.ContainerRestart(ctx, cfg) },
+		"remove":  func(cfg *container.ContainerConfig) error { return c.ContainerRemove(ctx, cfg) },
+		"pause":   func(cfg *container.ContainerConfig) error { return c.ContainerPause(ctx, cfg) },
+		"unpause": func(cfg *container.ContainerConfig) error { return c.ContainerUnpause(ctx, cfg) },
+		"kill":    func(cfg *container.ContainerConfig) error { return c.ContainerKill(ctx, cfg, "SIGTERM") },
+		"rename":  func(cfg *container.ContainerConfig) error { return c.ContainerRename(ctx, cfg, "api") },
+		"connect": func(cfg *container.ContainerConfig) error {
+			return c.NetworkConnect(ctx, &network.NetworkConfig{Id: "backend"}, cfg)
+		},
+		"disconnect": func(cfg *container.ContainerConfig) error {
+			return c.NetworkDisconnect(ctx, &network.NetworkConfig{Id: "backend"}, cfg, false)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.True(t, errdefs.IsInvalidConfig(call(nil)))
+			require.True(t, errdefs.IsInvalidConfig(call(unsaved)), "a config that was never created has no ID")
+		})
+	}
+	require.True(t, errdefs.IsInvalidConfig(c.NetworkConnectContainer(ctx, "", "abc", nil)))
+}
+
+func TestNetworkConnectAliases(t *testing.T) {
+	var connects, disconnects []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1.45/networks/{id}/connect", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		connects = append(connects, r.PathValue("id")+" "+strings.TrimSpace(string(body)))
+	})
+	mux.HandleFunc("POST /v1.45/networks/{id}/disconnect", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "gone" {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message":"network gone not found"}`)
+			return
+		}
+		disconnects = append(disconnects, r.PathValue("id"))
+	})
+	c := newFakeDaemonClient(t, mux)
+	ctx := context.Background()
+
+	endpoint := endpointoptions.NewConfig()
+	endpoint.SetEndpointSetting(endpointoptions.Aliases("api"))
+	require.NoError(t, c.NetworkConnectContainer(ctx, "backend", "abc", endpoint))
+	require.NoError(t, c.NetworkConnect(ctx, &network.NetworkConfig{Id: "backend"}, &container.ContainerConfig{Id: "abc"}, endpoint))
+	require.Len(t, connects, 2)
+	require.Equal(t, connects[0], connects[1], "both names send the same request")
+	require.Contains(t, connects[0], `"Aliases":["api"]`)
+
+	require.NoError(t, c.NetworkDisconnectContainer(ctx, "backend", "abc", true))
+	require.Equal(t, []string{"backend"}, disconnects)
+
+	// Both names type a missing network the same way.
+	err := c.NetworkDisconnectContainer(ctx, "gone", "abc", true)
+	var notFound *errdefs.ResourceNotFoundError
+	require.ErrorAs(t, err, &notFound)
+	require.Equal(t, "network", notFound.ResourceType)
+	require.ErrorAs(t, c.NetworkDisconnect(ctx, &network.NetworkConfig{Id: "gone"}, &container.ContainerConfig{Id: "abc"}, true), &notFound)
+}
+
+func TestContainerCreateVars(t *testing.T) {
+	var created struct {
+		Image string
+		Env   []string
+	}
+	var name string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1.45/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		name = r.URL.Query().Get("name")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+		io.WriteString(w, `{"Id":"abc"}`)
+	})
+	c := newFakeDaemonClient(t, mux)
+	c.vars = vars.New(vars.FromMap(map[string]string{"ENV": "stage", "TAG": "1.4.2"}), vars.Strict())
+	ctx := context.Background()
+
+	cfg := container.NewConfig("api-${ENV}")
+	cfg.SetContainerOptions(
+		containeroptions.Image(image.NewConfig("api:${TAG}")),
+		containeroptions.Env("APP_ENV", "${ENV}"),
+	)
+	require.NoError(t, c.ContainerCreate(ctx, cfg))
+	require.Equal(t, "api-stage", name)
+	require.Equal(t, "api:1.4.2", created.Image)
+	require.Equal(t, []string{"APP_ENV=stage"}, created.Env)
+	require.Equal(t, "api-stage", cfg.Name, "the config holds the resolved values")
+
+	unresolved := container.NewConfig("api-${REGION}")
+	unresolved.Options.Image = "api"
+	err := c.ContainerCreate(ctx, unresolved)
+	require.True(t, errdefs.IsInvalidConfig(err))
+	require.ErrorContains(t, err, "${REGION} in Name")
+}
+
+func TestContainerCreateProfiles(t *testing.T) {
+	var created struct {
+		Env        []string
+		HostConfig struct{ PublishAllPorts bool }
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1.45/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+		io.WriteString(w, `{"Id":"abc"}`)
+	})
+	c := newFakeDaemonClient(t, mux)
+	c.profiles = []string{"debug"}
+	c.vars = vars.New(vars.FromMap(map[string]string{"LEVEL": "trace"}))
+
+	cfg := container.NewConfig("api")
+	cfg.Options.Image = "api"
+	cfg.Profile("debug", containeroptions.Env("LOG_LEVEL", "${LEVEL}"), hostoptions.PublishAllPorts())
+	cfg.Profile("prod", containeroptions.Env("LOG_LEVEL", "warn"))
+	require.NoError(t, c.ContainerCreate(context.Background(), cfg))
+	require.Equal(t, []string{"LOG_LEVEL=trace"}, created.Env, "profile options are resolved like the rest of the config")
+	require.True(t, created.HostConfig.PublishAllPorts)
+	require.Equal(t, []string{"debug"}, cfg.ActiveProfiles())
+}
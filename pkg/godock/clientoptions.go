@@ -0,0 +1,318 @@
+package godock
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/metrics"
+	"github.com/aptd3v/godock/pkg/godock/registryauth"
+	"github.com/aptd3v/godock/pkg/godock/vars"
+)
+
+// ClientOptionFn is a function that configures the client created by NewClient.
+type ClientOptionFn func(*clientOptions)
+
+type clientOptions struct {
+	clock         clock.Clock
+	daemonRetries int
+	daemonBackoff time.Duration
+	recorders     []metrics.Recorder
+	auth          *registryauth.Manager
+	// inspectCacheTTL enables the inspect cache when it is positive.
+	inspectCacheTTL time.Duration
+	logStdout       io.Writer
+	logStderr       io.Writer
+	logger          Logger
+	verifiers       []ImageVerifier
+	vars            *vars.Vars
+	profiles        []string
+	// transferRate limits transfers to bytes per second when it is positive.
+	transferRate int64
+	httpClient   *http.Client
+	daemonHost   string
+	proxy        string
+	customCA     []byte
+	timeouts     Timeouts
+	// noSocketDiscovery disables probing for the sockets of desktop daemons.
+	noSocketDiscovery bool
+}
+
+func newClientOptions() *clientOptions {
+	opts := &clientOptions{
+		clock:     clock.Real(),
+		logStdout: os.Stdout,
+		logStderr: os.Stderr,
+	}
+	opts.auth = registryauth.New(
+		registryauth.WithConfigFile(registryauth.DefaultConfigFile()),
+		registryauth.OnSkip(func(server string, err error) {
+			// The logger is looked up when a registry is skipped, so it is the one set by the options.
+			logger := opts.logger
+			if logger == nil {
+				logger = slog.Default()
+			}
+			logger.WarnContext(context.Background(), "skipping registry credentials", "op", "registry auth", "registry", server, "error", err)
+		}),
+	)
+	return opts
+}
+
+// WithClock sets the clock used by the client for retries, backoff and waiting.
+// Use a clock.Fake in tests to advance time without sleeping.
+func WithClock(clk clock.Clock) ClientOptionFn {
+	return func(opts *clientOptions) {
+		if clk != nil {
+			opts.clock = clk
+		}
+	}
+}
+
+// WithDaemonRetry retries reaching the docker daemon up to the given number of times when creating the client,
+// doubling the backoff after every failed attempt. This is useful when the daemon is still starting up.
+func WithDaemonRetry(retries int, backoff time.Duration) ClientOptionFn {
+	return func(opts *clientOptions) {
+		opts.daemonRetries = retries
+		opts.daemonBackoff = backoff
+	}
+}
+
+// WithMetrics adds recorders that receive the size, layer cache hits and duration
+// of every image pull, push and build made through the client.
+// Measurements are recorded once the response body has been read to the end or closed.
+func WithMetrics(recorders ...metrics.Recorder) ClientOptionFn {
+	return func(opts *clientOptions) {
+		for _, recorder := range recorders {
+			if recorder != nil {
+				opts.recorders = append(opts.recorders, recorder)
+			}
+		}
+	}
+}
+
+/*
+WithImageVerifiers adds verifiers that check every image pulled through the client, and every image EnsureImage
+finds present, before it is used, e.g. that it is signed by a trusted key. Verifiers run in order and the first
+failure fails the pull. Exempt an image, such as the image of a verifier itself, with imageoptions.SkipVerification.
+
+Usage example:
+
+	verifier := signing.NewCosignKeyVerifier(runner, "/etc/cosign", "cosign.pub")
+	client, err := godock.NewClient(ctx, godock.WithImageVerifiers(verifier))
+*/
+func WithImageVerifiers(verifiers ...ImageVerifier) ClientOptionFn {
+	return func(opts *clientOptions) {
+		for _, verifier := range verifiers {
+			if verifier != nil {
+				opts.verifiers = append(opts.verifiers, verifier)
+			}
+		}
+	}
+}
+
+/*
+WithVars resolves ${VAR} placeholders in every container config created through the client, in its name, image,
+command, environment, ports, mounts and more, see vars.Vars.Apply. With strict Vars ContainerCreate fails on an
+unresolved variable.
+
+Usage example:
+
+	v := vars.New(vars.FromMap(map[string]string{"ENV": "stage"}), vars.FromEnv(), vars.Strict())
+	client, err := godock.NewClient(ctx, godock.WithVars(v))
+*/
+func WithVars(v *vars.Vars) ClientOptionFn {
+	return func(opts *clientOptions) {
+		opts.vars = v
+	}
+}
+
+/*
+WithProfiles activates the named profiles of every container config created through the client, see
+container.ContainerConfig.Profile. Configs without a profile of a name are created without it.
+
+Usage example:
+
+	profiles := strings.Split(os.Getenv("APP_PROFILES"), ",")
+	client, err := godock.NewClient(ctx, godock.WithProfiles(profiles...))
+*/
+func WithProfiles(names ...string) ClientOptionFn {
+	return func(opts *clientOptions) {
+		opts.profiles = append(opts.profiles, names...)
+	}
+}
+
+// WithRegistryAuth sets the manager used to resolve registry credentials for pulls, pushes and builds.
+// By default credentials are read from the Docker CLI config file and its credential helpers.
+// Pass nil to disable automatic credentials.
+func WithRegistryAuth(manager *registryauth.Manager) ClientOptionFn {
+	return func(opts *clientOptions) {
+		opts.auth = manager
+	}
+}
+
+/*
+WithInspectCache serves IsContainerRunning, GetContainerExitCode, GetContainerIPAddress and PortAddress from a cache
+of container inspects, so that frequent health polling does not inspect the container on the daemon every time.
+Entries are invalidated by the container and network events of the daemon and by changes made through the client,
+and are kept no longer than ttl in case an event is missed. Call Close on the client to stop watching events.
+
+Usage example:
+
+	client, err := godock.NewClient(ctx, godock.WithInspectCache(2*time.Second))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+*/
+func WithInspectCache(ttl time.Duration) ClientOptionFn {
+	return func(opts *clientOptions) {
+		opts.inspectCacheTTL = ttl
+	}
+}
+
+// WithLogWriter sets the writers CopyContainerLogs writes the stdout and stderr of containers to.
+// If stderr is nil, stdout is used for both streams. By default they are os.Stdout and os.Stderr.
+func WithLogWriter(stdout, stderr io.Writer) ClientOptionFn {
+	return func(opts *clientOptions) {
+		if stdout == nil {
+			return
+		}
+		if stderr == nil {
+			stderr = stdout
+		}
+		opts.logStdout, opts.logStderr = stdout, stderr
+	}
+}
+
+/*
+WithLogger sets the logger receiving the diagnostics of the client, such as skipped registry credentials at the warn
+level and volume operations at the debug level. Every record has an "op" attribute naming the operation. By default
+the client logs to slog.Default.
+
+Usage example:
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client, err := godock.NewClient(ctx, godock.WithLogger(logger.With("component", "godock")))
+*/
+func WithLogger(logger Logger) ClientOptionFn {
+	return func(opts *clientOptions) {
+		opts.logger = logger
+	}
+}
+
+/*
+WithTransferRateLimit caps the bandwidth of the transfers made through the client to bytesPerSec bytes per second,
+shared by all of them, e.g. so CI agents sharing a network do not saturate its uplink during mass pulls. The limit
+applies to everything sent to and received from the daemon, such as build contexts and image archives. Pulls and
+pushes are transferred by the daemon itself, which is held back by reading their progress at the limit; this is
+approximate and allows a short burst at the start. Zero disables the limit.
+
+Usage example:
+
+	// 10 MiB/s
+	client, err := godock.NewClient(ctx, godock.WithTransferRateLimit(10<<20))
+*/
+func WithTransferRateLimit(bytesPerSec int64) ClientOptionFn {
+	return func(opts *clientOptions) {
+		opts.transferRate = bytesPerSec
+	}
+}
+
+/*
+WithHTTPClient sets the HTTP client used to reach the daemon. Its transport is configured for the daemon host when
+it is an *http.Transport or nil; any other transport must connect to the daemon itself, and cannot be combined with
+WithProxy, WithCustomCA, WithTimeouts or WithTransferRateLimit. NewClient does not discover sockets for it.
+
+Usage example:
+
+	client, err := godock.NewClient(ctx, godock.WithHTTPClient(&http.Client{Transport: transport}))
+*/
+func WithHTTPClient(httpClient *http.Client) ClientOptionFn {
+	return func(opts *clientOptions) {
+		opts.httpClient = httpClient
+	}
+}
+
+/*
+WithDaemonHost sets the daemon to connect to, overriding DOCKER_HOST, e.g. a rootless daemon socket or a remote
+daemon. It takes the format of DOCKER_HOST, such as unix:///run/user/1000/docker.sock or tcp://10.0.0.5:2376.
+It also disables the socket discovery of NewClient.
+
+Usage example:
+
+	client, err := godock.NewClient(ctx, godock.WithDaemonHost("unix:///run/user/1000/docker.sock"))
+*/
+func WithDaemonHost(host string) ClientOptionFn {
+	return func(opts *clientOptions) {
+		opts.daemonHost = host
+	}
+}
+
+/*
+WithProxy connects to a remote daemon through an HTTP proxy, instead of the proxy from the environment. It only
+applies to tcp:// daemon hosts; the daemon reaches registries through its own proxy configuration.
+
+Usage example:
+
+	client, err := godock.NewClient(ctx,
+		godock.WithDaemonHost("tcp://build-host.internal:2376"),
+		godock.WithProxy("http://proxy.corp.example.com:3128"),
+	)
+*/
+func WithProxy(proxyURL string) ClientOptionFn {
+	return func(opts *clientOptions) {
+		opts.proxy = proxyURL
+	}
+}
+
+/*
+WithCustomCA trusts the PEM encoded CA certificates in addition to the system ones when connecting to a daemon over
+TLS, e.g. behind an intercepting corporate proxy. It requires a tcp:// daemon host and enables TLS for it.
+
+Usage example:
+
+	ca, err := os.ReadFile("/etc/ssl/corp-ca.pem")
+	if err != nil {
+		return err
+	}
+	client, err := godock.NewClient(ctx, godock.WithDaemonHost("tcp://build-host.internal:2376"), godock.WithCustomCA(ca))
+*/
+func WithCustomCA(pem []byte) ClientOptionFn {
+	return func(opts *clientOptions) {
+		opts.customCA = pem
+	}
+}
+
+/*
+WithTimeouts sets the timeouts of the connections and requests to the daemon.
+
+Usage example:
+
+	client, err := godock.NewClient(ctx, godock.WithTimeouts(godock.Timeouts{
+		Dial:           5 * time.Second,
+		ResponseHeader: 30 * time.Second,
+	}))
+*/
+func WithTimeouts(timeouts Timeouts) ClientOptionFn {
+	return func(opts *clientOptions) {
+		opts.timeouts = timeouts
+	}
+}
+
+/*
+WithoutSocketDiscovery disables the socket discovery of NewClient, so the client always connects to the default
+socket when DOCKER_HOST is unset.
+
+Usage example:
+
+	client, err := godock.NewClient(ctx, godock.WithoutSocketDiscovery())
+*/
+func WithoutSocketDiscovery() ClientOptionFn {
+	return func(opts *clientOptions) {
+		opts.noSocketDiscovery = true
+	}
+}
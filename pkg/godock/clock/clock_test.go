@@ -0,0 +1,80 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Advance fires due timers", func(t *testing.T) {
+		f := NewFake(start)
+		short := f.After(time.Second)
+		long := f.After(time.Minute)
+		assert.Equal(t, 2, f.Waiters())
+
+		f.Advance(time.Second)
+		select {
+		case now := <-short:
+			assert.Equal(t, start.Add(time.Second), now)
+		default:
+			t.Fatal("timer did not fire")
+		}
+		select {
+		case <-long:
+			t.Fatal("timer fired early")
+		default:
+		}
+		assert.Equal(t, 1, f.Waiters())
+		assert.Equal(t, start.Add(time.Second), f.Now())
+	})
+
+	t.Run("Zero duration fires immediately", func(t *testing.T) {
+		f := NewFake(start)
+		select {
+		case <-f.After(0):
+		default:
+			t.Fatal("timer did not fire")
+		}
+	})
+
+	t.Run("Sleep wakes on advance", func(t *testing.T) {
+		f := NewFake(start)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		done := make(chan error, 1)
+		go func() { done <- f.Sleep(ctx, time.Hour) }()
+
+		require.NoError(t, f.BlockUntil(ctx, 1))
+		f.Advance(time.Hour)
+		require.NoError(t, <-done)
+	})
+
+	t.Run("Sleep returns on cancel", func(t *testing.T) {
+		f := NewFake(start)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(t, f.Sleep(ctx, time.Hour), context.Canceled)
+		assert.Equal(t, 0, f.Waiters())
+	})
+
+	t.Run("Canceled Sleep deregisters its timer", func(t *testing.T) {
+		f := NewFake(start)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- f.Sleep(ctx, time.Hour) }()
+
+		waitCtx, waitCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer waitCancel()
+		require.NoError(t, f.BlockUntil(waitCtx, 1))
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+		assert.Equal(t, 0, f.Waiters())
+	})
+}
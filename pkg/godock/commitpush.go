@@ -0,0 +1,110 @@
+package godock
+
+import (
+	"context"
+
+	"github.com/aptd3v/godock/pkg/godock/commitoptions"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/imageoptions"
+	"github.com/distribution/reference"
+)
+
+type commitAndPushOptions struct {
+	commitOptions []commitoptions.CommitOptionsFn
+	pushOptions   []imageoptions.SetPushOptFn
+	onProgress    func(ImageProgress)
+}
+
+// CommitAndPushOptionFn is a function that configures CommitAndPush.
+type CommitAndPushOptionFn func(*commitAndPushOptions)
+
+// WithCommitOptions sets the options used to commit the container. A commitoptions.Reference
+// is ignored, the image is always tagged with the target reference.
+func WithCommitOptions(commitOptionFns ...commitoptions.CommitOptionsFn) CommitAndPushOptionFn {
+	return func(opts *commitAndPushOptions) {
+		opts.commitOptions = append(opts.commitOptions, commitOptionFns...)
+	}
+}
+
+// WithPushOptions sets the options used to push the committed image.
+func WithPushOptions(pushOptionFns ...imageoptions.SetPushOptFn) CommitAndPushOptionFn {
+	return func(opts *commitAndPushOptions) {
+		opts.pushOptions = append(opts.pushOptions, pushOptionFns...)
+	}
+}
+
+// WithCommitAndPushProgress sets a function called for every progress event of the push.
+func WithCommitAndPushProgress(onProgress func(ImageProgress)) CommitAndPushOptionFn {
+	return func(opts *commitAndPushOptions) {
+		opts.onProgress = onProgress
+	}
+}
+
+/*
+CommitAndPush commits a container, tags the new image with targetRef and pushes it,
+returning the tag and repository digest of the pushed image. When tagging or pushing fails
+the committed image is removed again, so failed attempts do not leave dangling images behind.
+
+Usage example:
+
+	res, err := client.CommitAndPush(ctx, ctr, "registry.example.com/app:snapshot",
+		godock.WithCommitOptions(
+			commitoptions.Comment("snapshot"),
+			commitoptions.Author("aptd3v"),
+		),
+		godock.WithCommitAndPushProgress(func(p godock.ImageProgress) {
+			fmt.Println(p.ID, p.Status)
+		}),
+	)
+	if err != nil {
+		return err
+	}
+	fmt.Println("pushed", res.Digest)
+*/
+func (c *Client) CommitAndPush(ctx context.Context, containerConfig *container.ContainerConfig, targetRef string, commitAndPushOptionFns ...CommitAndPushOptionFn) (*ImagePushResult, error) {
+	opts := &commitAndPushOptions{}
+	for _, fn := range commitAndPushOptionFns {
+		if fn != nil {
+			fn(opts)
+		}
+	}
+	if containerConfig == nil || containerConfig.Id == "" {
+		return nil, &errdefs.ValidationError{
+			Field:   "containerConfig",
+			Message: "container config or id cannot be empty",
+		}
+	}
+	if _, err := reference.ParseNormalizedNamed(targetRef); err != nil {
+		return nil, &errdefs.ValidationError{
+			Field:   "targetRef",
+			Message: err.Error(),
+		}
+	}
+
+	// Commit untagged so the image can be removed by id if a later step fails.
+	commitOptions := append(opts.commitOptions[:len(opts.commitOptions):len(opts.commitOptions)], commitoptions.Reference(""))
+	imageID, err := c.ImageCommit(ctx, containerConfig, nil, commitOptions...)
+	if err != nil {
+		return nil, newImageError(targetRef, "commit", err)
+	}
+
+	target := image.NewConfig(targetRef)
+	target.SetPushOptions(opts.pushOptions...)
+	if err := c.ImageTag(ctx, image.NewConfig(imageID), targetRef); err != nil {
+		c.removeCommittedImage(ctx, imageID)
+		return nil, newImageError(targetRef, "tag", err)
+	}
+	res, err := c.ImagePushWithProgress(ctx, target, opts.onProgress)
+	if err != nil {
+		c.removeCommittedImage(ctx, imageID)
+		return nil, err
+	}
+	return res, nil
+}
+
+// removeCommittedImage removes an image committed by CommitAndPush, along with any tags it was given.
+func (c *Client) removeCommittedImage(ctx context.Context, imageID string) {
+	c.ImageRemove(context.WithoutCancel(ctx), imageID, true, true)
+}
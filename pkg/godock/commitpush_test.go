@@ -0,0 +1,26 @@
+package godock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitAndPushValidation(t *testing.T) {
+	c := &Client{}
+	ctx := context.Background()
+
+	_, err := c.CommitAndPush(ctx, nil, "registry.example.com/app:1")
+	assert.True(t, errdefs.IsInvalidConfig(err))
+
+	_, err = c.CommitAndPush(ctx, container.NewConfig("app"), "registry.example.com/app:1")
+	assert.True(t, errdefs.IsInvalidConfig(err))
+
+	ctr := container.NewConfig("app")
+	ctr.Id = "abc"
+	_, err = c.CommitAndPush(ctx, ctr, "not a reference")
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
@@ -0,0 +1,33 @@
+package configoptions
+
+import (
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// SetConfigSpecFn is a function that configures a swarm config before it is created.
+type SetConfigSpecFn func(spec *swarm.ConfigSpec)
+
+// Label sets a label on the config.
+func Label(key, value string) SetConfigSpecFn {
+	return func(spec *swarm.ConfigSpec) {
+		if spec.Labels == nil {
+			spec.Labels = make(map[string]string)
+		}
+		spec.Labels[key] = value
+	}
+}
+
+/*
+GoTemplate evaluates the config data as a Go template when the config is mounted into a task.
+
+Usage example:
+
+	id, err := client.ConfigCreate(ctx, "nginx.conf", tmpl,
+		configoptions.GoTemplate(),
+	)
+*/
+func GoTemplate() SetConfigSpecFn {
+	return func(spec *swarm.ConfigSpec) {
+		spec.Templating = &swarm.Driver{Name: "golang"}
+	}
+}
@@ -0,0 +1,245 @@
+package godock
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net/netip"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/exec"
+	"github.com/aptd3v/godock/pkg/godock/execoptions"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+)
+
+// ConnectivityMethod is how CheckConnectivity ran its check.
+type ConnectivityMethod string
+
+const (
+	// ConnectivityExec ran the check with an exec in the source container.
+	ConnectivityExec ConnectivityMethod = "exec"
+	// ConnectivityProbe ran the check in a probe container sharing the source container's network namespace,
+	// because the source container has no shell or no tool to open a connection.
+	ConnectivityProbe ConnectivityMethod = "probe"
+)
+
+// ConnectivityResult is the outcome of CheckConnectivity.
+type ConnectivityResult struct {
+	// Resolved reports whether the target host name resolved inside the container.
+	// It is always true for IP addresses.
+	Resolved bool
+	// Reachable reports whether a TCP connection to the target port succeeded.
+	Reachable bool
+	Method    ConnectivityMethod
+	Duration  time.Duration
+	// Message describes the outcome, e.g. why the target was not reachable.
+	Message string
+}
+
+type connectivityOptions struct {
+	probeImage string
+	probeOnly  bool
+}
+
+// ConnectivityOptionFn is a function that configures CheckConnectivity.
+type ConnectivityOptionFn func(*connectivityOptions)
+
+// WithConnectivityProbeImage sets the image of the probe container. The image must provide sh, nc and nslookup.
+// The default is DefaultDoctorProbeImage.
+func WithConnectivityProbeImage(ref string) ConnectivityOptionFn {
+	return func(opts *connectivityOptions) {
+		opts.probeImage = ref
+	}
+}
+
+// WithConnectivityProbeOnly always runs the check in a probe container, leaving the source container untouched.
+func WithConnectivityProbeOnly() ConnectivityOptionFn {
+	return func(opts *connectivityOptions) {
+		opts.probeOnly = true
+	}
+}
+
+// connectivityHost matches host names and IP addresses, which are safe to pass to the check script.
+var connectivityHost = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:-]*$`)
+
+/*
+CheckConnectivity verifies that a container can open a TCP connection to targetHost:port, typically the
+name of another container or service on a shared network. The check first runs as an exec in the source
+container; when the container has no shell or no nc or bash, it runs in a short-lived probe container
+that shares the source container's network namespace, so it sees the same networks and DNS.
+
+An unreachable target is not an error: the result reports whether the host resolved and whether the
+port accepted a connection within the timeout. Errors are returned only when the check could not run.
+
+Usage example:
+
+	res, err := client.CheckConnectivity(ctx, api, "db", 5432, 3*time.Second)
+	if err != nil {
+		return err
+	}
+	if !res.Reachable {
+		log.Printf("api cannot reach db: %s", res.Message)
+	}
+*/
+func (c *Client) CheckConnectivity(ctx context.Context, from *container.ContainerConfig, targetHost string, port int, timeout time.Duration, connectivityOptionFns ...ConnectivityOptionFn) (*ConnectivityResult, error) {
+	opts := &connectivityOptions{
+		probeImage: DefaultDoctorProbeImage,
+	}
+	for _, fn := range connectivityOptionFns {
+		if fn != nil {
+			fn(opts)
+		}
+	}
+	if from == nil || from.Id == "" {
+		return nil, &errdefs.ValidationError{Field: "from", Message: "container config or id cannot be empty"}
+	}
+	if !connectivityHost.MatchString(targetHost) {
+		return nil, &errdefs.ValidationError{Field: "targetHost", Message: fmt.Sprintf("invalid host %q", targetHost)}
+	}
+	if port < 1 || port > 65535 {
+		return nil, &errdefs.ValidationError{Field: "port", Message: fmt.Sprintf("port %d is out of range", port)}
+	}
+	script := connectivityScript(targetHost, port, timeout)
+
+	start := c.Clock().Now()
+	if !opts.probeOnly {
+		output, _, err := c.execOutput(ctx, from, "sh", "-c", script)
+		if err == nil {
+			if res := parseConnectivityOutput(output, targetHost, port); res != nil {
+				res.Method = ConnectivityExec
+				res.Duration = c.Clock().Now().Sub(start)
+				return res, nil
+			}
+		} else if errdefs.IsNotFound(err) {
+			return nil, err
+		}
+	}
+
+	output, err := c.runConnectivityProbe(ctx, from, opts.probeImage, script)
+	if err != nil {
+		return nil, err
+	}
+	res := parseConnectivityOutput(output, targetHost, port)
+	if res == nil {
+		return nil, &errdefs.ContainerError{
+			ID:      from.Id,
+			Op:      "connectivity check",
+			Message: "probe image " + opts.probeImage + " provides no tool to open a connection",
+		}
+	}
+	res.Method = ConnectivityProbe
+	res.Duration = c.Clock().Now().Sub(start)
+	return res, nil
+}
+
+// connectivityScript returns a shell script that reports whether host resolves and whether host:port accepts connections.
+func connectivityScript(host string, port int, timeout time.Duration) string {
+	seconds := int(math.Ceil(timeout.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return fmt.Sprintf(`h=%s; p=%d; t=%d
+if getent hosts "$h" >/dev/null 2>&1 || nslookup "$h" >/dev/null 2>&1; then echo dns=ok
+elif command -v getent >/dev/null 2>&1 || command -v nslookup >/dev/null 2>&1; then echo dns=fail
+else echo dns=unknown; fi
+if command -v nc >/dev/null 2>&1; then
+  if nc -z -w "$t" "$h" "$p" </dev/null >/dev/null 2>&1; then echo tcp=open; else echo tcp=closed; fi
+elif command -v bash >/dev/null 2>&1; then
+  if timeout "$t" bash -c "exec 3<>/dev/tcp/$h/$p" >/dev/null 2>&1; then echo tcp=open; else echo tcp=closed; fi
+else echo tcp=notool; fi`, host, port, seconds)
+}
+
+// parseConnectivityOutput parses the output of the connectivity script.
+// It returns nil when the check could not run because no tool was available.
+func parseConnectivityOutput(output, host string, port int) *ConnectivityResult {
+	var dns, tcp string
+	for _, line := range strings.Split(output, "\n") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), "="); ok {
+			switch key {
+			case "dns":
+				dns = value
+			case "tcp":
+				tcp = value
+			}
+		}
+	}
+	if tcp != "open" && tcp != "closed" {
+		return nil
+	}
+
+	_, err := netip.ParseAddr(host)
+	isIP := err == nil
+	res := &ConnectivityResult{
+		Resolved:  isIP || dns == "ok" || tcp == "open",
+		Reachable: tcp == "open",
+	}
+	target := fmt.Sprintf("%s:%d", host, port)
+	switch {
+	case res.Reachable:
+		res.Message = "connected to " + target
+	case !res.Resolved && dns == "fail":
+		res.Message = "could not resolve " + host + ", check that both containers share a network"
+	default:
+		res.Message = "connection to " + target + " was refused or timed out"
+	}
+	return res
+}
+
+// runConnectivityProbe runs the script in a probe container joined to the network namespace of the container.
+func (c *Client) runConnectivityProbe(ctx context.Context, from *container.ContainerConfig, probeImageRef, script string) (string, error) {
+	probeImage := image.NewConfig(probeImageRef)
+	if err := c.EnsureImage(ctx, probeImage, PullPolicyIfNotPresent); err != nil {
+		return "", err
+	}
+
+	probe := container.NewConfig("godock-connectivity-" + GenerateRandomString(8))
+	probe.SetContainerOptions(
+		containeroptions.Image(probeImage),
+		containeroptions.Entrypoint("sh", "-c", script),
+	)
+	probe.SetHostOptions(hostoptions.NetworkMode("container:" + from.Id))
+
+	runErr := c.RunAndWait(ctx, probe)
+	if probe.Id == "" {
+		return "", runErr
+	}
+	defer c.ContainerRemove(context.WithoutCancel(ctx), probe, removeoptions.Force())
+	if runErr != nil {
+		return "", runErr
+	}
+
+	rc, err := c.ContainerLogs(ctx, probe)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	var out bytes.Buffer
+	if _, err := NewLogCopier(&out, &out).Copy(rc); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// execOutput runs a command in a running container and returns its combined output and exit code.
+func (c *Client) execOutput(ctx context.Context, containerConfig *container.ContainerConfig, cmd ...string) (string, int, error) {
+	execConfig := exec.NewConfig()
+	execConfig.SetOptions(
+		execoptions.CMD(cmd...),
+		execoptions.AttachStdout(true),
+		execoptions.AttachStderr(true),
+	)
+	var out bytes.Buffer
+	exitCode, err := c.execRun(ctx, containerConfig, execConfig, &out, &out)
+	if err != nil {
+		return "", 0, err
+	}
+	return out.String(), exitCode, nil
+}
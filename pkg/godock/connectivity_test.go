@@ -0,0 +1,53 @@
+package godock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConnectivityOutput(t *testing.T) {
+	res := parseConnectivityOutput("dns=ok\ntcp=open\n", "db", 5432)
+	require.NotNil(t, res)
+	assert.True(t, res.Resolved)
+	assert.True(t, res.Reachable)
+
+	res = parseConnectivityOutput("dns=fail\ntcp=closed\n", "db", 5432)
+	require.NotNil(t, res)
+	assert.False(t, res.Resolved)
+	assert.False(t, res.Reachable)
+	assert.Contains(t, res.Message, "could not resolve db")
+
+	res = parseConnectivityOutput("dns=fail\ntcp=closed\n", "10.0.0.5", 80)
+	require.NotNil(t, res)
+	assert.True(t, res.Resolved)
+	assert.Contains(t, res.Message, "refused or timed out")
+
+	assert.Nil(t, parseConnectivityOutput("dns=unknown\ntcp=notool\n", "db", 5432))
+	assert.Nil(t, parseConnectivityOutput(`OCI runtime exec failed: exec: "sh": executable file not found`, "db", 5432))
+}
+
+func TestConnectivityScript(t *testing.T) {
+	script := connectivityScript("db", 5432, 1500*time.Millisecond)
+	assert.Contains(t, script, "h=db; p=5432; t=2")
+	assert.Contains(t, connectivityScript("db", 5432, 0), "t=1")
+}
+
+func TestCheckConnectivityValidation(t *testing.T) {
+	c := &Client{}
+	ctx := context.Background()
+	ctr := container.NewConfig("api")
+	ctr.Id = "abc"
+
+	_, err := c.CheckConnectivity(ctx, container.NewConfig("api"), "db", 5432, time.Second)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	_, err = c.CheckConnectivity(ctx, ctr, "db; rm -rf /", 5432, time.Second)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	_, err = c.CheckConnectivity(ctx, ctr, "db", 70000, time.Second)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
@@ -1,12 +1,18 @@
 package container
 
 import (
+	"maps"
+	"slices"
+
 	"github.com/aptd3v/godock/pkg/godock/containeroptions"
 	"github.com/aptd3v/godock/pkg/godock/hostoptions"
 	"github.com/aptd3v/godock/pkg/godock/networkoptions"
 	"github.com/aptd3v/godock/pkg/godock/platformoptions"
+	"github.com/aptd3v/godock/pkg/godock/secrets"
 	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
@@ -18,6 +24,13 @@ type ContainerConfig struct {
 	HostOptions       *containerType.HostConfig
 	NetworkingOptions *network.NetworkingConfig
 	PlatformOptions   *v1.Platform
+	// Secrets are injected by ContainerCreate: environment secrets are added to the
+	// environment and file secrets are copied into the container before it starts.
+	Secrets []*secrets.Secret
+
+	// profiles holds the option bundles added with Profile, activeProfiles the names of the applied ones.
+	profiles       map[string][]any
+	activeProfiles []string
 }
 
 // String returns the name of the Docker container.
@@ -63,6 +76,15 @@ func (c *ContainerConfig) SetPlatformOptions(setPOFns ...platformoptions.SetPlat
 	}
 }
 
+// SetSecrets adds secrets created with the secrets package to the container.
+func (c *ContainerConfig) SetSecrets(containerSecrets ...*secrets.Secret) {
+	for _, secret := range containerSecrets {
+		if secret != nil {
+			c.Secrets = append(c.Secrets, secret)
+		}
+	}
+}
+
 // NewConfig creates a new Container config instance with the specified name.
 // The Container instance contains configuration options for creating a Docker container.
 func NewConfig(name string) *ContainerConfig {
@@ -77,3 +99,164 @@ func NewConfig(name string) *ContainerConfig {
 
 	return container
 }
+
+// Clone returns a copy of the container config that can be changed and created without affecting the original,
+// e.g. to run a second container from the same config. Every slice, map and pointer of the options is copied, so
+// options set on the clone, including the labels and endpoint settings creating a container adds, stay on the clone.
+func (c *ContainerConfig) Clone() *ContainerConfig {
+	clone := *c
+	if c.Options != nil {
+		clone.Options = cloneOptions(c.Options)
+	}
+	if c.HostOptions != nil {
+		clone.HostOptions = cloneHostOptions(c.HostOptions)
+	}
+	if c.NetworkingOptions != nil {
+		networking := &network.NetworkingConfig{}
+		if c.NetworkingOptions.EndpointsConfig != nil {
+			networking.EndpointsConfig = make(map[string]*network.EndpointSettings, len(c.NetworkingOptions.EndpointsConfig))
+			for name, endpoint := range c.NetworkingOptions.EndpointsConfig {
+				if endpoint != nil {
+					endpoint = endpoint.Copy()
+					endpoint.DriverOpts = maps.Clone(endpoint.DriverOpts)
+				}
+				networking.EndpointsConfig[name] = endpoint
+			}
+		}
+		clone.NetworkingOptions = networking
+	}
+	if c.PlatformOptions != nil {
+		platform := *c.PlatformOptions
+		platform.OSFeatures = slices.Clone(platform.OSFeatures)
+		clone.PlatformOptions = &platform
+	}
+	clone.Secrets = slices.Clone(c.Secrets)
+	clone.profiles = maps.Clone(c.profiles)
+	clone.activeProfiles = slices.Clone(c.activeProfiles)
+	return &clone
+}
+
+// cloneOptions returns a deep copy of container options.
+func cloneOptions(o *containerType.Config) *containerType.Config {
+	options := *o
+	options.ExposedPorts = maps.Clone(o.ExposedPorts)
+	options.Env = slices.Clone(o.Env)
+	options.Cmd = slices.Clone(o.Cmd)
+	if o.Healthcheck != nil {
+		healthcheck := *o.Healthcheck
+		healthcheck.Test = slices.Clone(healthcheck.Test)
+		options.Healthcheck = &healthcheck
+	}
+	options.Volumes = maps.Clone(o.Volumes)
+	options.Entrypoint = slices.Clone(o.Entrypoint)
+	options.OnBuild = slices.Clone(o.OnBuild)
+	options.Labels = maps.Clone(o.Labels)
+	options.StopTimeout = clonePointer(o.StopTimeout)
+	options.Shell = slices.Clone(o.Shell)
+	return &options
+}
+
+// cloneHostOptions returns a deep copy of host options.
+func cloneHostOptions(h *containerType.HostConfig) *containerType.HostConfig {
+	host := *h
+	host.Binds = slices.Clone(h.Binds)
+	host.LogConfig.Config = maps.Clone(h.LogConfig.Config)
+	if h.PortBindings != nil {
+		host.PortBindings = make(nat.PortMap, len(h.PortBindings))
+		for port, bindings := range h.PortBindings {
+			host.PortBindings[port] = slices.Clone(bindings)
+		}
+	}
+	host.VolumesFrom = slices.Clone(h.VolumesFrom)
+	host.Annotations = maps.Clone(h.Annotations)
+	host.CapAdd = slices.Clone(h.CapAdd)
+	host.CapDrop = slices.Clone(h.CapDrop)
+	host.DNS = slices.Clone(h.DNS)
+	host.DNSOptions = slices.Clone(h.DNSOptions)
+	host.DNSSearch = slices.Clone(h.DNSSearch)
+	host.ExtraHosts = slices.Clone(h.ExtraHosts)
+	host.GroupAdd = slices.Clone(h.GroupAdd)
+	host.Links = slices.Clone(h.Links)
+	host.SecurityOpt = slices.Clone(h.SecurityOpt)
+	host.StorageOpt = maps.Clone(h.StorageOpt)
+	host.Tmpfs = maps.Clone(h.Tmpfs)
+	host.Sysctls = maps.Clone(h.Sysctls)
+	host.BlkioWeightDevice = clonePointers(h.BlkioWeightDevice)
+	host.BlkioDeviceReadBps = clonePointers(h.BlkioDeviceReadBps)
+	host.BlkioDeviceWriteBps = clonePointers(h.BlkioDeviceWriteBps)
+	host.BlkioDeviceReadIOps = clonePointers(h.BlkioDeviceReadIOps)
+	host.BlkioDeviceWriteIOps = clonePointers(h.BlkioDeviceWriteIOps)
+	host.Devices = slices.Clone(h.Devices)
+	host.DeviceCgroupRules = slices.Clone(h.DeviceCgroupRules)
+	if h.DeviceRequests != nil {
+		host.DeviceRequests = make([]containerType.DeviceRequest, len(h.DeviceRequests))
+		for i, request := range h.DeviceRequests {
+			request.DeviceIDs = slices.Clone(request.DeviceIDs)
+			if request.Capabilities != nil {
+				request.Capabilities = make([][]string, len(h.DeviceRequests[i].Capabilities))
+				for j, capabilities := range h.DeviceRequests[i].Capabilities {
+					request.Capabilities[j] = slices.Clone(capabilities)
+				}
+			}
+			request.Options = maps.Clone(request.Options)
+			host.DeviceRequests[i] = request
+		}
+	}
+	host.MemorySwappiness = clonePointer(h.MemorySwappiness)
+	host.OomKillDisable = clonePointer(h.OomKillDisable)
+	host.PidsLimit = clonePointer(h.PidsLimit)
+	host.Ulimits = clonePointers(h.Ulimits)
+	if h.Mounts != nil {
+		host.Mounts = make([]mount.Mount, len(h.Mounts))
+		for i, m := range h.Mounts {
+			m.BindOptions = clonePointer(m.BindOptions)
+			if m.VolumeOptions != nil {
+				volumeOptions := *m.VolumeOptions
+				volumeOptions.Labels = maps.Clone(volumeOptions.Labels)
+				if volumeOptions.DriverConfig != nil {
+					driver := *volumeOptions.DriverConfig
+					driver.Options = maps.Clone(driver.Options)
+					volumeOptions.DriverConfig = &driver
+				}
+				m.VolumeOptions = &volumeOptions
+			}
+			if m.TmpfsOptions != nil {
+				tmpfsOptions := *m.TmpfsOptions
+				if tmpfsOptions.Options != nil {
+					tmpfsOptions.Options = make([][]string, len(m.TmpfsOptions.Options))
+					for j, option := range m.TmpfsOptions.Options {
+						tmpfsOptions.Options[j] = slices.Clone(option)
+					}
+				}
+				m.TmpfsOptions = &tmpfsOptions
+			}
+			m.ClusterOptions = clonePointer(m.ClusterOptions)
+			host.Mounts[i] = m
+		}
+	}
+	host.MaskedPaths = slices.Clone(h.MaskedPaths)
+	host.ReadonlyPaths = slices.Clone(h.ReadonlyPaths)
+	host.Init = clonePointer(h.Init)
+	return &host
+}
+
+// clonePointer returns a pointer to a copy of the value p points to, nil for nil.
+func clonePointer[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// clonePointers returns a copy of s pointing to copies of its values.
+func clonePointers[T any](s []*T) []*T {
+	if s == nil {
+		return nil
+	}
+	clone := make([]*T, len(s))
+	for i, p := range s {
+		clone[i] = clonePointer(p)
+	}
+	return clone
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/go-connections/nat"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewConfig(t *testing.T) {
@@ -375,3 +376,60 @@ func TestContainerConfig_ComplexConfiguration_EdgeCases(t *testing.T) {
 	assert.Equal(t, "arm64", c.PlatformOptions.Architecture)
 	assert.Equal(t, "linux", c.PlatformOptions.OS)
 }
+
+func TestContainerConfig_Clone(t *testing.T) {
+	original := NewConfig("api")
+	original.SetContainerOptions(
+		containeroptions.Label("tier", "web"),
+		containeroptions.Env("PORT", "8080"),
+	)
+	endpoint := endpointoptions.NewConfig()
+	endpoint.SetEndpointSetting(endpointoptions.Aliases("api"))
+	original.SetNetworkOptions(networkoptions.Endpoint("backend", endpoint))
+
+	clone := original.Clone()
+	clone.Options.Labels["godock.managed"] = "true"
+	clone.Options.Env = append(clone.Options.Env, "EXTRA=1")
+	clone.HostOptions.NetworkMode = "host"
+	clone.NetworkingOptions.EndpointsConfig["backend"].MacAddress = "02:42:ac:11:00:02"
+
+	assert.Equal(t, map[string]string{"tier": "web"}, original.Options.Labels)
+	assert.Equal(t, []string{"PORT=8080"}, original.Options.Env)
+	assert.Empty(t, original.HostOptions.NetworkMode)
+	assert.Empty(t, original.NetworkingOptions.EndpointsConfig["backend"].MacAddress)
+	assert.Equal(t, []string{"api"}, clone.NetworkingOptions.EndpointsConfig["backend"].Aliases)
+}
+
+func TestContainerConfig_CloneDeep(t *testing.T) {
+	original := NewConfig("api")
+	original.SetContainerOptions(
+		containeroptions.Expose("8080"),
+		containeroptions.CMD("serve"),
+		containeroptions.HealthCheckExec(0, 0, 0, 3, "CMD", "true"),
+	)
+	original.SetHostOptions(
+		hostoptions.PortBindings("", "8080", "8080"),
+		hostoptions.Mount(hostoptions.MountType("bind"), "/srv", "/srv", false),
+		hostoptions.Tmpfs("/tmp", ""),
+	)
+
+	clone := original.Clone()
+	clone.SetContainerOptions(containeroptions.Expose("9090"))
+	clone.Options.Cmd[0] = "migrate"
+	clone.Options.Healthcheck.Test[1] = "false"
+	clone.SetHostOptions(
+		hostoptions.PortBindings("", "9090", "9090"),
+		hostoptions.Mount(hostoptions.MountType("volume"), "data", "/data", false),
+		hostoptions.Tmpfs("/run", ""),
+	)
+	clone.HostOptions.PortBindings["8080/tcp"][0].HostPort = "18080"
+	clone.HostOptions.Mounts[0].ReadOnly = true
+
+	assert.Equal(t, nat.PortSet{"8080/tcp": {}}, original.Options.ExposedPorts)
+	assert.Equal(t, []string{"serve"}, []string(original.Options.Cmd))
+	assert.Equal(t, []string{"CMD", "true"}, original.Options.Healthcheck.Test)
+	assert.Equal(t, nat.PortMap{"8080/tcp": {{HostPort: "8080"}}}, original.HostOptions.PortBindings)
+	require.Len(t, original.HostOptions.Mounts, 1)
+	assert.False(t, original.HostOptions.Mounts[0].ReadOnly)
+	assert.Equal(t, map[string]string{"/tmp": ""}, original.HostOptions.Tmpfs)
+}
@@ -0,0 +1,145 @@
+package container
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/aptd3v/godock/pkg/godock/networkoptions"
+	"github.com/aptd3v/godock/pkg/godock/platformoptions"
+	"github.com/aptd3v/godock/pkg/godock/secrets"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+/*
+Profile adds a named bundle of options to the container config that is only applied when the profile is activated,
+with ActivateProfiles or the godock.WithProfiles client option. Options are functions of the containeroptions,
+hostoptions, networkoptions and platformoptions packages, secrets, or functions taking the *ContainerConfig itself.
+Adding options to an existing profile appends them.
+
+Usage example:
+
+	api := container.NewConfig("api")
+	api.SetContainerOptions(containeroptions.Image(image.NewConfig("api:latest")))
+	api.Profile("debug",
+		containeroptions.Env("DEBUG", "1"),
+		hostoptions.PublishAllPorts(),
+	)
+	api.Profile("prod",
+		hostoptions.RestartAlways(),
+		secrets.Env("API_TOKEN", token),
+	)
+	err := api.ActivateProfiles("debug")
+*/
+func (c *ContainerConfig) Profile(name string, options ...any) {
+	if c.profiles == nil {
+		c.profiles = make(map[string][]any)
+	}
+	// Clipped, as clones of the config share the options of their profiles.
+	c.profiles[name] = append(slices.Clip(c.profiles[name]), options...)
+}
+
+// Profiles returns the sorted names of the profiles of the container config.
+func (c *ContainerConfig) Profiles() []string {
+	names := make([]string, 0, len(c.profiles))
+	for name := range c.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ActiveProfiles returns the names of the activated profiles in the order they were applied.
+func (c *ContainerConfig) ActiveProfiles() []string {
+	return slices.Clone(c.activeProfiles)
+}
+
+/*
+ActivateProfiles applies the options of the named profiles in order. Profiles the config does not have are skipped,
+so the same names can be activated for every container of an application, and a profile that is already active is
+not applied again. A profile with an option of an unsupported type fails with a ValidationError before any of the
+profiles is applied.
+*/
+func (c *ContainerConfig) ActivateProfiles(names ...string) error {
+	var activate []string
+	for _, name := range names {
+		if _, ok := c.profiles[name]; !ok || slices.Contains(c.activeProfiles, name) || slices.Contains(activate, name) {
+			continue
+		}
+		for _, option := range c.profiles[name] {
+			if !profileOption(option) {
+				return &errdefs.ValidationError{
+					Field:   "Profile",
+					Message: fmt.Sprintf("profile %q: unsupported option type %T", name, option),
+				}
+			}
+		}
+		activate = append(activate, name)
+	}
+	for _, name := range activate {
+		for _, option := range c.profiles[name] {
+			c.applyProfileOption(option)
+		}
+		c.activeProfiles = append(c.activeProfiles, name)
+	}
+	return nil
+}
+
+// profileOption reports whether option can be applied by a profile.
+func profileOption(option any) bool {
+	switch option.(type) {
+	case containeroptions.SetOptionsFns, func(*containerType.Config),
+		hostoptions.SetHostOptFn, func(*containerType.HostConfig),
+		networkoptions.SetContainerNetworkOptFn, func(*network.NetworkingConfig),
+		platformoptions.SetPlatformOptions, func(*v1.Platform),
+		*secrets.Secret, func(*ContainerConfig):
+		return true
+	}
+	return false
+}
+
+// applyProfileOption applies an option accepted by profileOption, creating the part of the config it sets if the
+// config has none.
+func (c *ContainerConfig) applyProfileOption(option any) {
+	switch option := option.(type) {
+	case func(*containerType.Config):
+		c.applyProfileOption(containeroptions.SetOptionsFns(option))
+	case containeroptions.SetOptionsFns:
+		if c.Options == nil {
+			c.Options = &containerType.Config{}
+		}
+		c.SetContainerOptions(option)
+	case func(*containerType.HostConfig):
+		c.applyProfileOption(hostoptions.SetHostOptFn(option))
+	case hostoptions.SetHostOptFn:
+		if c.HostOptions == nil {
+			c.HostOptions = &containerType.HostConfig{}
+		}
+		c.SetHostOptions(option)
+	case func(*network.NetworkingConfig):
+		c.applyProfileOption(networkoptions.SetContainerNetworkOptFn(option))
+	case networkoptions.SetContainerNetworkOptFn:
+		if c.NetworkingOptions == nil {
+			c.NetworkingOptions = &network.NetworkingConfig{}
+		}
+		c.SetNetworkOptions(option)
+	case func(*v1.Platform):
+		c.applyProfileOption(platformoptions.SetPlatformOptions(option))
+	case platformoptions.SetPlatformOptions:
+		if c.PlatformOptions == nil {
+			c.PlatformOptions = &v1.Platform{}
+		}
+		c.SetPlatformOptions(option)
+	case *secrets.Secret:
+		c.SetSecrets(option)
+	case func(*ContainerConfig):
+		if option != nil {
+			option(c)
+		}
+	}
+}
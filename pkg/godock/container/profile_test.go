@@ -0,0 +1,67 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/aptd3v/godock/pkg/godock/platformoptions"
+	"github.com/aptd3v/godock/pkg/godock/secrets"
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivateProfiles(t *testing.T) {
+	c := NewConfig("api")
+	c.SetContainerOptions(containeroptions.Env("MODE", "base"))
+	c.Profile("debug", containeroptions.Env("DEBUG", "1"), hostoptions.PublishAllPorts())
+	c.Profile("prod",
+		hostoptions.RestartAlways(),
+		secrets.Env("API_TOKEN", "tok"),
+		platformoptions.Arch("arm64"),
+		func(cfg *ContainerConfig) { cfg.Name += "-prod" },
+		func(options *container.Config) { options.User = "app" },
+	)
+	assert.Equal(t, []string{"debug", "prod"}, c.Profiles())
+
+	require.NoError(t, c.ActivateProfiles("missing", "debug"))
+	assert.Equal(t, []string{"MODE=base", "DEBUG=1"}, c.Options.Env)
+	assert.True(t, c.HostOptions.PublishAllPorts)
+	assert.Empty(t, c.HostOptions.RestartPolicy.Name, "an inactive profile is not applied")
+
+	// An active profile is not applied twice.
+	require.NoError(t, c.ActivateProfiles("debug", "prod"))
+	assert.Equal(t, []string{"MODE=base", "DEBUG=1"}, c.Options.Env)
+	assert.Equal(t, container.RestartPolicyAlways, c.HostOptions.RestartPolicy.Name)
+	assert.Len(t, c.Secrets, 1)
+	assert.Equal(t, "arm64", c.PlatformOptions.Architecture)
+	assert.Equal(t, "api-prod", c.Name)
+	assert.Equal(t, "app", c.Options.User)
+	assert.Equal(t, []string{"debug", "prod"}, c.ActiveProfiles())
+}
+
+func TestActivateProfilesClone(t *testing.T) {
+	template := NewConfig("api")
+	template.Profile("debug", containeroptions.Env("DEBUG", "1"))
+
+	clone := template.Clone()
+	clone.Profile("debug", containeroptions.Env("TRACE", "1"))
+	require.NoError(t, clone.ActivateProfiles("debug"))
+	assert.Equal(t, []string{"DEBUG=1", "TRACE=1"}, clone.Options.Env)
+
+	require.NoError(t, template.ActivateProfiles("debug"))
+	assert.Equal(t, []string{"DEBUG=1"}, template.Options.Env, "profiles added to a clone do not change the template")
+	assert.Equal(t, []string{"debug"}, template.Clone().ActiveProfiles())
+}
+
+func TestActivateProfilesInvalid(t *testing.T) {
+	c := NewConfig("api")
+	c.Profile("debug", containeroptions.Env("DEBUG", "1"), "DEBUG=1")
+	err := c.ActivateProfiles("debug")
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	assert.ErrorContains(t, err, `profile "debug": unsupported option type string`)
+	assert.Empty(t, c.Options.Env)
+	assert.Empty(t, c.ActiveProfiles())
+}
@@ -0,0 +1,823 @@
+package container
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-connections/nat"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+ToDockerRunCommand renders the container config as an equivalent docker run command, to share a configuration
+built in Go or to reproduce an issue with the CLI. Values are quoted for POSIX shells.
+
+Not every setting is rendered. Settings docker run has no flag for are left out, such as file secrets and device
+requests --gpus cannot express, like ones with driver options. So are less common settings the renderer does not
+cover, such as links, OOM, blkio and cgroup tuning, and CPU periods and quotas. Environment secrets are rendered as
+"-e NAME", taking the value from the environment of the shell instead of writing it out.
+
+Usage example:
+
+	api := container.NewConfig("api")
+	api.SetContainerOptions(containeroptions.Image(image.NewConfig("api:1.4")), containeroptions.Env("MODE", "prod"))
+	api.SetHostOptions(hostoptions.PortBindings("", "8080", "8080"))
+	fmt.Println(api.ToDockerRunCommand())
+	// docker run --name api -e MODE=prod -p 8080:8080 api:1.4
+*/
+func (c *ContainerConfig) ToDockerRunCommand() string {
+	args := append([]string{"docker", "run"}, c.dockerRunArgs()...)
+	for i, arg := range args {
+		args[i] = shellQuote(arg)
+	}
+	return strings.Join(args, " ")
+}
+
+// dockerRunArgs returns the arguments of docker run, without shell quoting.
+func (c *ContainerConfig) dockerRunArgs() []string {
+	var args []string
+	flag := func(name string, values ...string) {
+		for _, value := range values {
+			if value != "" {
+				args = append(args, name, value)
+			}
+		}
+	}
+	toggle := func(name string, on bool) {
+		if on {
+			args = append(args, name)
+		}
+	}
+	flag("--name", c.Name)
+
+	options := c.Options
+	if options == nil {
+		options = &containerType.Config{}
+	}
+	host := c.HostOptions
+	if host == nil {
+		host = &containerType.HostConfig{}
+	}
+	toggle("-i", options.OpenStdin)
+	toggle("-t", options.Tty)
+	toggle("--rm", host.AutoRemove)
+	flag("--hostname", options.Hostname)
+	flag("--domainname", options.Domainname)
+	flag("--user", options.User)
+	flag("--workdir", options.WorkingDir)
+	flag("-e", options.Env...)
+	flag("-e", c.secretEnvNames()...)
+	for _, key := range sortedKeys(options.Labels) {
+		flag("--label", key+"="+options.Labels[key])
+	}
+	for _, key := range sortedKeys(host.Annotations) {
+		flag("--annotation", key+"="+host.Annotations[key])
+	}
+	var entrypointArgs []string
+	if len(options.Entrypoint) > 0 {
+		// docker run takes a single entrypoint executable, its arguments go before the command.
+		flag("--entrypoint", options.Entrypoint[0])
+		entrypointArgs = options.Entrypoint[1:]
+	}
+	flag("--expose", unboundPorts(options.ExposedPorts, host.PortBindings)...)
+	flag("-p", portBindings(host.PortBindings)...)
+	toggle("-P", host.PublishAllPorts)
+	flag("-v", host.Binds...)
+	for _, m := range host.Mounts {
+		flag("--mount", mountSpec(m))
+	}
+	for _, target := range sortedKeys(host.Tmpfs) {
+		if host.Tmpfs[target] != "" {
+			target += ":" + host.Tmpfs[target]
+		}
+		flag("--tmpfs", target)
+	}
+	args = append(args, c.networkArgs()...)
+	flag("--add-host", host.ExtraHosts...)
+	flag("--dns", host.DNS...)
+	flag("--dns-search", host.DNSSearch...)
+	flag("--restart", restartPolicy(host.RestartPolicy))
+	toggle("--privileged", host.Privileged)
+	toggle("--read-only", host.ReadonlyRootfs)
+	toggle("--init", host.Init != nil && *host.Init)
+	flag("--cap-add", host.CapAdd...)
+	flag("--cap-drop", host.CapDrop...)
+	flag("--security-opt", host.SecurityOpt...)
+	flag("--group-add", host.GroupAdd...)
+	flag("--pid", string(host.PidMode))
+	flag("--ipc", string(host.IpcMode))
+	flag("--userns", string(host.UsernsMode))
+	flag("--runtime", host.Runtime)
+	flag("--volumes-from", host.VolumesFrom...)
+	for _, key := range sortedKeys(host.Sysctls) {
+		flag("--sysctl", key+"="+host.Sysctls[key])
+	}
+	for _, device := range host.Devices {
+		flag("--device", deviceSpec(device))
+	}
+	for _, request := range host.DeviceRequests {
+		flag("--gpus", gpusSpec(request))
+	}
+	for _, ulimit := range host.Ulimits {
+		if ulimit != nil {
+			flag("--ulimit", fmt.Sprintf("%s=%d:%d", ulimit.Name, ulimit.Soft, ulimit.Hard))
+		}
+	}
+	flag("--memory", formatInt(host.Memory))
+	flag("--memory-reservation", formatInt(host.MemoryReservation))
+	flag("--memory-swap", formatInt(host.MemorySwap))
+	flag("--shm-size", formatInt(host.ShmSize))
+	flag("--cpus", formatCPUs(host.NanoCPUs))
+	flag("--cpu-shares", formatInt(host.CPUShares))
+	flag("--cpuset-cpus", host.CpusetCpus)
+	if host.PidsLimit != nil {
+		flag("--pids-limit", formatInt(*host.PidsLimit))
+	}
+	flag("--log-driver", host.LogConfig.Type)
+	for _, key := range sortedKeys(host.LogConfig.Config) {
+		flag("--log-opt", key+"="+host.LogConfig.Config[key])
+	}
+	if hc := options.Healthcheck; hc != nil {
+		if len(hc.Test) > 0 && hc.Test[0] == "NONE" {
+			toggle("--no-healthcheck", true)
+		} else {
+			flag("--health-cmd", healthCommand(hc.Test))
+			flag("--health-interval", formatDuration(hc.Interval))
+			flag("--health-timeout", formatDuration(hc.Timeout))
+			flag("--health-start-period", formatDuration(hc.StartPeriod))
+			flag("--health-start-interval", formatDuration(hc.StartInterval))
+			if hc.Retries > 0 {
+				flag("--health-retries", strconv.Itoa(hc.Retries))
+			}
+		}
+	}
+	flag("--stop-signal", options.StopSignal)
+	if options.StopTimeout != nil {
+		flag("--stop-timeout", strconv.Itoa(*options.StopTimeout))
+	}
+	flag("--platform", c.platform())
+
+	args = append(args, options.Image)
+	args = append(args, entrypointArgs...)
+	return append(args, options.Cmd...)
+}
+
+/*
+ToComposeService renders the container config as a compose file with a single service named after the container,
+to run it with docker compose. Networks and named volumes the container uses are declared external, as they are
+created separately from the container. Device requests are rendered as device reservations of the deploy section.
+Like ToDockerRunCommand, environment secrets are passed from the environment by name and file secrets are left
+out, as are the less common settings ToDockerRunCommand does not render.
+
+Usage example:
+
+	compose, err := api.ToComposeService()
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile("compose.yaml", compose, 0o644)
+*/
+func (c *ContainerConfig) ToComposeService() ([]byte, error) {
+	options := c.Options
+	if options == nil {
+		options = &containerType.Config{}
+	}
+	host := c.HostOptions
+	if host == nil {
+		host = &containerType.HostConfig{}
+	}
+	service := &composeService{
+		Image:         options.Image,
+		ContainerName: c.Name,
+		Hostname:      options.Hostname,
+		Domainname:    options.Domainname,
+		User:          options.User,
+		WorkingDir:    options.WorkingDir,
+		Entrypoint:    options.Entrypoint,
+		Command:       options.Cmd,
+		Environment:   append(slices.Clone(options.Env), c.secretEnvNames()...),
+		Labels:        options.Labels,
+		Annotations:   host.Annotations,
+		Ports:         portBindings(host.PortBindings),
+		Expose:        unboundPorts(options.ExposedPorts, host.PortBindings),
+		NetworkMode:   networkMode(host.NetworkMode),
+		MacAddress:    options.MacAddress, //nolint:staticcheck // still set by containeroptions.MacAddress
+		ExtraHosts:    host.ExtraHosts,
+		DNS:           host.DNS,
+		DNSSearch:     host.DNSSearch,
+		Restart:       restartPolicy(host.RestartPolicy),
+		Privileged:    host.Privileged,
+		ReadOnly:      host.ReadonlyRootfs,
+		Init:          host.Init,
+		CapAdd:        host.CapAdd,
+		CapDrop:       host.CapDrop,
+		SecurityOpt:   host.SecurityOpt,
+		GroupAdd:      host.GroupAdd,
+		Pid:           string(host.PidMode),
+		Ipc:           string(host.IpcMode),
+		Userns:        string(host.UsernsMode),
+		Runtime:       host.Runtime,
+		VolumesFrom:   host.VolumesFrom,
+		Sysctls:       host.Sysctls,
+		MemLimit:      host.Memory,
+		MemReserve:    host.MemoryReservation,
+		MemSwap:       host.MemorySwap,
+		ShmSize:       host.ShmSize,
+		CPUs:          formatCPUs(host.NanoCPUs),
+		CPUShares:     host.CPUShares,
+		CPUSet:        host.CpusetCpus,
+		PidsLimit:     host.PidsLimit,
+		Tty:           options.Tty,
+		StdinOpen:     options.OpenStdin,
+		StopSignal:    options.StopSignal,
+		Platform:      c.platform(),
+	}
+	if options.StopTimeout != nil {
+		service.StopGracePeriod = formatDuration(time.Duration(*options.StopTimeout) * time.Second)
+	}
+	if options.NetworkDisabled && host.NetworkMode == "" {
+		service.NetworkMode = "none"
+	}
+	external := map[string]bool{"external": true}
+	file := &composeFile{Services: map[string]*composeService{c.composeName(): service}}
+
+	for _, bind := range host.Binds {
+		service.Volumes = append(service.Volumes, bind)
+		if source, _, ok := strings.Cut(bind, ":"); ok && namedVolume(source) {
+			file.Volumes = addKey(file.Volumes, source, external)
+		}
+	}
+	for _, m := range host.Mounts {
+		service.Volumes = append(service.Volumes, composeMount(m))
+		if m.Type == mount.TypeVolume && m.Source != "" {
+			file.Volumes = addKey(file.Volumes, m.Source, external)
+		}
+	}
+	for _, target := range sortedKeys(host.Tmpfs) {
+		if host.Tmpfs[target] != "" {
+			target += ":" + host.Tmpfs[target]
+		}
+		service.Tmpfs = append(service.Tmpfs, target)
+	}
+	// Compose does not allow a network mode next to networks, so a user-defined network mode is one of them.
+	endpoints := c.endpoints()
+	if host.NetworkMode != "" && host.NetworkMode.IsUserDefined() {
+		service.NetworkMode = ""
+		if !slices.ContainsFunc(endpoints, func(e endpoint) bool { return e.name == string(host.NetworkMode) }) {
+			endpoints = append([]endpoint{{name: string(host.NetworkMode)}}, endpoints...)
+		}
+	}
+	for _, e := range endpoints {
+		var network *composeNetwork
+		if len(e.aliases) > 0 || e.ipv4 != "" || e.ipv6 != "" || e.mac != "" {
+			network = &composeNetwork{Aliases: e.aliases, IPv4Address: e.ipv4, IPv6Address: e.ipv6, MacAddress: e.mac}
+		}
+		service.Networks = addKey(service.Networks, e.name, network)
+		file.Networks = addKey(file.Networks, e.name, external)
+	}
+	for _, device := range host.Devices {
+		service.Devices = append(service.Devices, deviceSpec(device))
+	}
+	for _, request := range host.DeviceRequests {
+		service.Deploy = addDeviceReservations(service.Deploy, request)
+	}
+	for _, ulimit := range host.Ulimits {
+		if ulimit == nil {
+			continue
+		}
+		if service.Ulimits == nil {
+			service.Ulimits = make(map[string]composeUlimit)
+		}
+		service.Ulimits[ulimit.Name] = composeUlimit{Soft: ulimit.Soft, Hard: ulimit.Hard}
+	}
+	if host.LogConfig.Type != "" || len(host.LogConfig.Config) > 0 {
+		service.Logging = &composeLogging{Driver: host.LogConfig.Type, Options: host.LogConfig.Config}
+	}
+	if hc := options.Healthcheck; hc != nil && len(hc.Test) > 0 {
+		if hc.Test[0] == "NONE" {
+			service.Healthcheck = &composeHealthcheck{Disable: true}
+		} else {
+			service.Healthcheck = &composeHealthcheck{
+				Test:          hc.Test,
+				Interval:      formatDuration(hc.Interval),
+				Timeout:       formatDuration(hc.Timeout),
+				StartPeriod:   formatDuration(hc.StartPeriod),
+				StartInterval: formatDuration(hc.StartInterval),
+				Retries:       hc.Retries,
+			}
+		}
+	}
+	return yaml.Marshal(file)
+}
+
+// composeFile is the compose file rendered by ToComposeService.
+type composeFile struct {
+	Services map[string]*composeService `yaml:"services"`
+	Networks map[string]map[string]bool `yaml:"networks,omitempty"`
+	Volumes  map[string]map[string]bool `yaml:"volumes,omitempty"`
+}
+
+// composeService is a service of a compose file, in the order of docker run flags.
+type composeService struct {
+	Image           string                     `yaml:"image,omitempty"`
+	ContainerName   string                     `yaml:"container_name,omitempty"`
+	Platform        string                     `yaml:"platform,omitempty"`
+	Hostname        string                     `yaml:"hostname,omitempty"`
+	Domainname      string                     `yaml:"domainname,omitempty"`
+	User            string                     `yaml:"user,omitempty"`
+	WorkingDir      string                     `yaml:"working_dir,omitempty"`
+	Entrypoint      []string                   `yaml:"entrypoint,omitempty"`
+	Command         []string                   `yaml:"command,omitempty"`
+	Environment     []string                   `yaml:"environment,omitempty"`
+	Labels          map[string]string          `yaml:"labels,omitempty"`
+	Annotations     map[string]string          `yaml:"annotations,omitempty"`
+	Ports           []string                   `yaml:"ports,omitempty"`
+	Expose          []string                   `yaml:"expose,omitempty"`
+	Volumes         []any                      `yaml:"volumes,omitempty"`
+	Tmpfs           []string                   `yaml:"tmpfs,omitempty"`
+	NetworkMode     string                     `yaml:"network_mode,omitempty"`
+	Networks        map[string]*composeNetwork `yaml:"networks,omitempty"`
+	MacAddress      string                     `yaml:"mac_address,omitempty"`
+	ExtraHosts      []string                   `yaml:"extra_hosts,omitempty"`
+	DNS             []string                   `yaml:"dns,omitempty"`
+	DNSSearch       []string                   `yaml:"dns_search,omitempty"`
+	Restart         string                     `yaml:"restart,omitempty"`
+	Privileged      bool                       `yaml:"privileged,omitempty"`
+	ReadOnly        bool                       `yaml:"read_only,omitempty"`
+	Init            *bool                      `yaml:"init,omitempty"`
+	CapAdd          []string                   `yaml:"cap_add,omitempty"`
+	CapDrop         []string                   `yaml:"cap_drop,omitempty"`
+	SecurityOpt     []string                   `yaml:"security_opt,omitempty"`
+	GroupAdd        []string                   `yaml:"group_add,omitempty"`
+	Pid             string                     `yaml:"pid,omitempty"`
+	Ipc             string                     `yaml:"ipc,omitempty"`
+	Userns          string                     `yaml:"userns_mode,omitempty"`
+	Runtime         string                     `yaml:"runtime,omitempty"`
+	VolumesFrom     []string                   `yaml:"volumes_from,omitempty"`
+	Sysctls         map[string]string          `yaml:"sysctls,omitempty"`
+	Devices         []string                   `yaml:"devices,omitempty"`
+	Deploy          *composeDeploy             `yaml:"deploy,omitempty"`
+	Ulimits         map[string]composeUlimit   `yaml:"ulimits,omitempty"`
+	MemLimit        int64                      `yaml:"mem_limit,omitempty"`
+	MemReserve      int64                      `yaml:"mem_reservation,omitempty"`
+	MemSwap         int64                      `yaml:"memswap_limit,omitempty"`
+	ShmSize         int64                      `yaml:"shm_size,omitempty"`
+	CPUs            string                     `yaml:"cpus,omitempty"`
+	CPUShares       int64                      `yaml:"cpu_shares,omitempty"`
+	CPUSet          string                     `yaml:"cpuset,omitempty"`
+	PidsLimit       *int64                     `yaml:"pids_limit,omitempty"`
+	Logging         *composeLogging            `yaml:"logging,omitempty"`
+	Healthcheck     *composeHealthcheck        `yaml:"healthcheck,omitempty"`
+	StopSignal      string                     `yaml:"stop_signal,omitempty"`
+	StopGracePeriod string                     `yaml:"stop_grace_period,omitempty"`
+	Tty             bool                       `yaml:"tty,omitempty"`
+	StdinOpen       bool                       `yaml:"stdin_open,omitempty"`
+}
+
+type composeNetwork struct {
+	Aliases     []string `yaml:"aliases,omitempty"`
+	IPv4Address string   `yaml:"ipv4_address,omitempty"`
+	IPv6Address string   `yaml:"ipv6_address,omitempty"`
+	MacAddress  string   `yaml:"mac_address,omitempty"`
+}
+
+type composeMountSpec struct {
+	Type     string `yaml:"type"`
+	Source   string `yaml:"source,omitempty"`
+	Target   string `yaml:"target"`
+	ReadOnly bool   `yaml:"read_only,omitempty"`
+	Bind     *struct {
+		Propagation string `yaml:"propagation"`
+	} `yaml:"bind,omitempty"`
+	Volume *struct {
+		NoCopy bool `yaml:"nocopy"`
+	} `yaml:"volume,omitempty"`
+	Tmpfs *struct {
+		Size int64 `yaml:"size"`
+	} `yaml:"tmpfs,omitempty"`
+}
+
+type composeDeploy struct {
+	Resources struct {
+		Reservations struct {
+			Devices []composeDevice `yaml:"devices"`
+		} `yaml:"reservations"`
+	} `yaml:"resources"`
+}
+
+type composeDevice struct {
+	Driver       string            `yaml:"driver,omitempty"`
+	Count        any               `yaml:"count,omitempty"`
+	DeviceIDs    []string          `yaml:"device_ids,omitempty"`
+	Capabilities []string          `yaml:"capabilities,omitempty"`
+	Options      map[string]string `yaml:"options,omitempty"`
+}
+
+type composeUlimit struct {
+	Soft int64 `yaml:"soft"`
+	Hard int64 `yaml:"hard"`
+}
+
+type composeLogging struct {
+	Driver  string            `yaml:"driver,omitempty"`
+	Options map[string]string `yaml:"options,omitempty"`
+}
+
+type composeHealthcheck struct {
+	Test          []string `yaml:"test,omitempty"`
+	Interval      string   `yaml:"interval,omitempty"`
+	Timeout       string   `yaml:"timeout,omitempty"`
+	StartPeriod   string   `yaml:"start_period,omitempty"`
+	StartInterval string   `yaml:"start_interval,omitempty"`
+	Retries       int      `yaml:"retries,omitempty"`
+	Disable       bool     `yaml:"disable,omitempty"`
+}
+
+// composeName returns the name of the compose service, the container name without the leading slash the daemon
+// reports.
+func (c *ContainerConfig) composeName() string {
+	if name := strings.TrimPrefix(c.Name, "/"); name != "" {
+		return name
+	}
+	return "app"
+}
+
+// composeMount returns the long volume syntax of a mount.
+func composeMount(m mount.Mount) composeMountSpec {
+	spec := composeMountSpec{Type: string(m.Type), Source: m.Source, Target: m.Target, ReadOnly: m.ReadOnly}
+	if m.BindOptions != nil && m.BindOptions.Propagation != "" {
+		spec.Bind = &struct {
+			Propagation string `yaml:"propagation"`
+		}{string(m.BindOptions.Propagation)}
+	}
+	if m.VolumeOptions != nil && m.VolumeOptions.NoCopy {
+		spec.Volume = &struct {
+			NoCopy bool `yaml:"nocopy"`
+		}{true}
+	}
+	if m.TmpfsOptions != nil && m.TmpfsOptions.SizeBytes > 0 {
+		spec.Tmpfs = &struct {
+			Size int64 `yaml:"size"`
+		}{m.TmpfsOptions.SizeBytes}
+	}
+	return spec
+}
+
+// endpoint is a network the container is connected to when it is created.
+type endpoint struct {
+	name            string
+	aliases         []string
+	ipv4, ipv6, mac string
+}
+
+// endpoints returns the networks of the networking options sorted by name.
+func (c *ContainerConfig) endpoints() []endpoint {
+	if c.NetworkingOptions == nil {
+		return nil
+	}
+	var endpoints []endpoint
+	for _, name := range sortedKeys(c.NetworkingOptions.EndpointsConfig) {
+		e := endpoint{name: name}
+		if settings := c.NetworkingOptions.EndpointsConfig[name]; settings != nil {
+			e.aliases, e.mac = settings.Aliases, settings.MacAddress
+			if settings.IPAMConfig != nil {
+				e.ipv4, e.ipv6 = settings.IPAMConfig.IPv4Address, settings.IPAMConfig.IPv6Address
+			}
+		}
+		endpoints = append(endpoints, e)
+	}
+	return endpoints
+}
+
+// networkArgs returns the docker run flags of the network mode and the networks. A single network takes its
+// aliases and addresses as flags of their own, more networks need the long syntax of --network. The container-wide
+// MAC address is rendered as --mac-address, which the docker CLI sets on the primary network like ContainerCreate.
+func (c *ContainerConfig) networkArgs() []string {
+	var mode containerType.NetworkMode
+	if c.HostOptions != nil {
+		mode = c.HostOptions.NetworkMode
+	}
+	var mac string
+	if c.Options != nil {
+		mac = c.Options.MacAddress //nolint:staticcheck // still set by containeroptions.MacAddress
+		if mode == "" && c.Options.NetworkDisabled {
+			mode = "none"
+		}
+	}
+	endpoints := c.endpoints()
+	var args []string
+	if mode := networkMode(mode); mode != "" && !slices.ContainsFunc(endpoints, func(e endpoint) bool { return e.name == mode }) {
+		args = append(args, "--network", mode)
+	}
+	if len(args) == 0 && len(endpoints) == 1 {
+		e := endpoints[0]
+		args = append(args, "--network", e.name)
+		for _, alias := range e.aliases {
+			args = append(args, "--network-alias", alias)
+		}
+		if e.ipv4 != "" {
+			args = append(args, "--ip", e.ipv4)
+		}
+		if e.ipv6 != "" {
+			args = append(args, "--ip6", e.ipv6)
+		}
+		if e.mac != "" {
+			mac = e.mac
+		}
+		if mac != "" {
+			args = append(args, "--mac-address", mac)
+		}
+		return args
+	}
+	for _, e := range endpoints {
+		spec := "name=" + e.name
+		for _, alias := range e.aliases {
+			spec += ",alias=" + alias
+		}
+		if e.ipv4 != "" {
+			spec += ",ip=" + e.ipv4
+		}
+		if e.ipv6 != "" {
+			spec += ",ip6=" + e.ipv6
+		}
+		if e.mac != "" {
+			spec += ",mac-address=" + e.mac
+		}
+		if spec == "name="+e.name {
+			spec = e.name
+		}
+		args = append(args, "--network", spec)
+	}
+	if mac != "" {
+		args = append(args, "--mac-address", mac)
+	}
+	return args
+}
+
+// networkMode returns the network mode to render, "" for the default.
+func networkMode(mode containerType.NetworkMode) string {
+	if mode.IsDefault() {
+		return ""
+	}
+	return string(mode)
+}
+
+// secretEnvNames returns the names of the environment secrets, passed from the environment of the caller.
+func (c *ContainerConfig) secretEnvNames() []string {
+	var names []string
+	for _, secret := range c.Secrets {
+		if secret != nil && secret.IsEnv() {
+			names = append(names, secret.Name())
+		}
+	}
+	return names
+}
+
+// platform returns the platform as os/arch[/variant], "" without one.
+func (c *ContainerConfig) platform() string {
+	p := c.PlatformOptions
+	if p == nil || (p.OS == "" && p.Architecture == "") {
+		return ""
+	}
+	platform := p.OS
+	if platform == "" {
+		platform = "linux"
+	}
+	if p.Architecture != "" {
+		platform += "/" + p.Architecture
+	}
+	if p.Variant != "" {
+		platform += "/" + p.Variant
+	}
+	return platform
+}
+
+// portBindings returns the port bindings as [ip:][hostPort:]containerPort[/protocol], sorted by port. TCP is left
+// out as the default protocol.
+func portBindings(bindings nat.PortMap) []string {
+	var specs []string
+	for _, port := range sortedKeys(bindings) {
+		containerPort := portSpec(nat.Port(port))
+		if len(bindings[nat.Port(port)]) == 0 {
+			specs = append(specs, containerPort)
+		}
+		for _, binding := range bindings[nat.Port(port)] {
+			spec := containerPort
+			if binding.HostPort != "" || binding.HostIP != "" {
+				spec = binding.HostPort + ":" + spec
+			}
+			if binding.HostIP != "" {
+				ip := binding.HostIP
+				if strings.Contains(ip, ":") {
+					ip = "[" + ip + "]"
+				}
+				spec = ip + ":" + spec
+			}
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// unboundPorts returns the exposed ports without a port binding, which publishes them already, sorted.
+func unboundPorts(exposed nat.PortSet, bindings nat.PortMap) []string {
+	var ports []string
+	for _, port := range sortedKeys(exposed) {
+		if _, bound := bindings[nat.Port(port)]; !bound {
+			ports = append(ports, portSpec(nat.Port(port)))
+		}
+	}
+	return ports
+}
+
+// portSpec returns a port without the default TCP protocol.
+func portSpec(port nat.Port) string {
+	return strings.TrimSuffix(string(port), "/tcp")
+}
+
+// mountSpec returns a mount in the syntax of the --mount flag.
+func mountSpec(m mount.Mount) string {
+	spec := "type=" + string(m.Type)
+	if m.Source != "" {
+		spec += ",source=" + m.Source
+	}
+	spec += ",target=" + m.Target
+	if m.ReadOnly {
+		spec += ",readonly"
+	}
+	if m.BindOptions != nil && m.BindOptions.Propagation != "" {
+		spec += ",bind-propagation=" + string(m.BindOptions.Propagation)
+	}
+	if m.VolumeOptions != nil && m.VolumeOptions.NoCopy {
+		spec += ",volume-nocopy"
+	}
+	if m.TmpfsOptions != nil && m.TmpfsOptions.SizeBytes > 0 {
+		spec += ",tmpfs-size=" + strconv.FormatInt(m.TmpfsOptions.SizeBytes, 10)
+	}
+	return spec
+}
+
+// namedVolume reports whether the source of a bind is the name of a volume rather than a host path.
+func namedVolume(source string) bool {
+	return source != "" && !strings.HasPrefix(source, "/") && !strings.HasPrefix(source, ".") && !strings.HasPrefix(source, "~")
+}
+
+// deviceSpec returns a device mapping as hostPath[:containerPath[:permissions]].
+func deviceSpec(device containerType.DeviceMapping) string {
+	spec := device.PathOnHost
+	if device.PathInContainer != "" || device.CgroupPermissions != "" {
+		spec += ":" + device.PathInContainer
+	}
+	if device.CgroupPermissions != "" {
+		spec += ":" + device.CgroupPermissions
+	}
+	return spec
+}
+
+// gpusSpec returns a device request in the syntax of the --gpus flag, "" for a request --gpus cannot express. The
+// flag always requests the gpu capability and takes no driver options.
+func gpusSpec(request containerType.DeviceRequest) string {
+	if len(request.Capabilities) != 1 || !slices.Contains(request.Capabilities[0], "gpu") || len(request.Options) > 0 {
+		return ""
+	}
+	var fields []string
+	if request.Driver != "" {
+		fields = append(fields, "driver="+request.Driver)
+	}
+	switch {
+	case request.Count == -1:
+		fields = append(fields, "count=all")
+	case request.Count > 0:
+		fields = append(fields, "count="+strconv.Itoa(request.Count))
+	}
+	if len(request.DeviceIDs) > 0 {
+		fields = append(fields, "device="+strings.Join(request.DeviceIDs, ","))
+	}
+	var capabilities []string
+	for _, capability := range request.Capabilities[0] {
+		if capability != "gpu" {
+			capabilities = append(capabilities, capability)
+		}
+	}
+	if len(capabilities) > 0 {
+		fields = append(fields, "capabilities="+strings.Join(capabilities, ","))
+	}
+	if len(fields) == 1 && strings.HasPrefix(fields[0], "count=") {
+		return strings.TrimPrefix(fields[0], "count=")
+	}
+	// The flag value is a CSV record, fields with a comma are quoted.
+	for i, field := range fields {
+		if strings.Contains(field, ",") {
+			fields[i] = `"` + field + `"`
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// addDeviceReservations adds a device request to the device reservations of deploy, creating deploy if it is nil.
+// Compose takes a single list of capabilities per device, so a request with alternative sets of capabilities is
+// reserved once per set.
+func addDeviceReservations(deploy *composeDeploy, request containerType.DeviceRequest) *composeDeploy {
+	if deploy == nil {
+		deploy = &composeDeploy{}
+	}
+	device := composeDevice{Driver: request.Driver, DeviceIDs: request.DeviceIDs, Options: request.Options}
+	switch {
+	case request.Count == -1:
+		device.Count = "all"
+	case request.Count > 0:
+		device.Count = request.Count
+	}
+	capabilities := request.Capabilities
+	if len(capabilities) == 0 {
+		capabilities = [][]string{nil}
+	}
+	for _, set := range capabilities {
+		device.Capabilities = set
+		deploy.Resources.Reservations.Devices = append(deploy.Resources.Reservations.Devices, device)
+	}
+	return deploy
+}
+
+// restartPolicy returns a restart policy as name[:maxRetries], "" for no restarts.
+func restartPolicy(policy containerType.RestartPolicy) string {
+	if policy.Name == "" || policy.Name == containerType.RestartPolicyDisabled {
+		return ""
+	}
+	if policy.MaximumRetryCount > 0 {
+		return fmt.Sprintf("%s:%d", policy.Name, policy.MaximumRetryCount)
+	}
+	return string(policy.Name)
+}
+
+// healthCommand returns the shell command of a health check test.
+func healthCommand(test []string) string {
+	if len(test) < 2 {
+		return ""
+	}
+	if test[0] == "CMD-SHELL" {
+		return test[1]
+	}
+	args := make([]string, len(test)-1)
+	for i, arg := range test[1:] {
+		args[i] = shellQuote(arg)
+	}
+	return strings.Join(args, " ")
+}
+
+// formatInt returns n in decimal, "" for zero.
+func formatInt(n int64) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// formatDuration returns d like time.Duration.String, "" for zero.
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+// formatCPUs returns nano CPUs as a number of CPUs, "" for zero.
+func formatCPUs(nanoCPUs int64) string {
+	if nanoCPUs == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(float64(nanoCPUs)/1e9, 'f', -1, 64)
+}
+
+// shellQuote quotes s for a POSIX shell unless it only has characters that need no quoting.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_@%+=:,./-") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys[K ~string, V any](m map[K]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, string(key))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// addKey sets a key of m, creating m if it is nil.
+func addKey[V any](m map[string]V, key string, value V) map[string]V {
+	if m == nil {
+		m = make(map[string]V)
+	}
+	m[key] = value
+	return m
+}
@@ -0,0 +1,234 @@
+package container
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/aptd3v/godock/pkg/godock/networkoptions"
+	"github.com/aptd3v/godock/pkg/godock/networkoptions/endpointoptions"
+	"github.com/aptd3v/godock/pkg/godock/secrets"
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// renderTestConfig returns a config using the settings both renderers cover.
+func renderTestConfig() *ContainerConfig {
+	c := NewConfig("api")
+	c.SetContainerOptions(
+		func(options *container.Config) { options.Image = "registry.example.com/api:1.4" },
+		containeroptions.Env("GREETING", "it's me"),
+		containeroptions.Label("tier", "backend"),
+		containeroptions.Expose("9090"),
+		containeroptions.Expose("8080"),
+		containeroptions.CMD("serve", "--verbose"),
+		containeroptions.HealthCheckExec(0, 5*time.Second, 30*time.Second, 3, "CMD", "curl", "-f", "http://localhost:8080/health"),
+	)
+	c.SetHostOptions(
+		hostoptions.PortBindings("127.0.0.1", "8080", "8080"),
+		hostoptions.Bind("data:/var/lib/api"),
+		hostoptions.Mount(hostoptions.MountType("bind"), "/etc/api", "/config", true),
+		hostoptions.RestartAlways(),
+	)
+	endpoint := endpointoptions.NewConfig()
+	endpoint.SetEndpointSetting(endpointoptions.Aliases("api.internal"))
+	c.SetNetworkOptions(networkoptions.Endpoint("backend", endpoint))
+	c.SetSecrets(secrets.Env("API_TOKEN", "tok-123"), secrets.File("db_password", []byte("hunter2"), 0))
+	return c
+}
+
+func TestToDockerRunCommand(t *testing.T) {
+	got := renderTestConfig().ToDockerRunCommand()
+	assert.Equal(t, "docker run --name api -e 'GREETING=it'\\''s me' -e API_TOKEN --label tier=backend --expose 9090"+
+		" -p 127.0.0.1:8080:8080 -v data:/var/lib/api --mount type=bind,source=/etc/api,target=/config,readonly"+
+		" --network backend --network-alias api.internal --restart always"+
+		" --health-cmd 'curl -f http://localhost:8080/health' --health-interval 30s --health-timeout 5s --health-retries 3"+
+		" registry.example.com/api:1.4 serve --verbose", got)
+	assert.NotContains(t, got, "tok-123", "secret values are not written out")
+	assert.NotContains(t, got, "hunter2")
+}
+
+func TestToDockerRunCommandNetworks(t *testing.T) {
+	c := NewConfig("worker")
+	c.Options.Image = "worker"
+	c.Options.Entrypoint = []string{"/bin/sh", "-c"}
+	c.Options.Cmd = []string{"exec worker"}
+	c.SetNetworkOptions(networkoptions.Endpoint("backend", endpointoptions.NewConfig()))
+	aliased := endpointoptions.NewConfig()
+	aliased.SetEndpointSetting(endpointoptions.Aliases("jobs"))
+	c.SetNetworkOptions(networkoptions.Endpoint("queue", aliased))
+	assert.Equal(t, "docker run --name worker --entrypoint /bin/sh --network backend --network name=queue,alias=jobs worker -c 'exec worker'",
+		c.ToDockerRunCommand())
+
+	c = NewConfig("isolated")
+	c.Options.Image = "alpine"
+	c.SetContainerOptions(containeroptions.DisableNetwork())
+	assert.Equal(t, "docker run --name isolated --network none alpine", c.ToDockerRunCommand())
+}
+
+func TestToDockerRunCommandDevicesAndAddresses(t *testing.T) {
+	c := NewConfig("trainer")
+	c.Options.Image = "trainer"
+	c.SetContainerOptions(containeroptions.MacAddress("02:42:ac:11:00:02"), func(options *container.Config) {
+		options.Healthcheck = &container.HealthConfig{
+			Test:          []string{"CMD-SHELL", "nvidia-smi"},
+			StartPeriod:   time.Minute,
+			StartInterval: 2 * time.Second,
+		}
+	})
+	c.SetHostOptions(
+		hostoptions.AllGPUs(),
+		hostoptions.NvidiaGPUs(2),
+		hostoptions.GPUByUUID("GPU-3a23c669", "1"),
+		hostoptions.DeviceRequest("nvidia", 1, nil, []string{"gpu", "compute", "utility"}),
+		hostoptions.DeviceRequest("", -1, nil, []string{"tpu"}),
+		hostoptions.Annotation("io.katacontainers.config.hypervisor.default_memory", "4096"),
+		hostoptions.Annotation("com.example.team", "ml"),
+	)
+	assert.Equal(t, "docker run --name trainer --annotation com.example.team=ml"+
+		" --annotation io.katacontainers.config.hypervisor.default_memory=4096 --mac-address 02:42:ac:11:00:02"+
+		` --gpus all --gpus driver=nvidia,count=2 --gpus 'driver=nvidia,"device=GPU-3a23c669,1"'`+
+		` --gpus 'driver=nvidia,count=1,"capabilities=compute,utility"'`+
+		" --health-cmd nvidia-smi --health-start-period 1m0s --health-start-interval 2s trainer",
+		c.ToDockerRunCommand(), "a request without the gpu capability has no --gpus syntax")
+
+	// A MAC address of an endpoint goes with its network.
+	c = NewConfig("api")
+	c.Options.Image = "api"
+	backend := endpointoptions.NewConfig()
+	backend.SetEndpointSetting(endpointoptions.MacAddress("02:42:ac:11:00:03"))
+	c.SetNetworkOptions(networkoptions.Endpoint("backend", backend))
+	assert.Equal(t, "docker run --name api --network backend --mac-address 02:42:ac:11:00:03 api", c.ToDockerRunCommand())
+	c.SetNetworkOptions(networkoptions.Endpoint("frontend", endpointoptions.NewConfig()))
+	assert.Equal(t, "docker run --name api --network name=backend,mac-address=02:42:ac:11:00:03 --network frontend api",
+		c.ToDockerRunCommand())
+}
+
+func TestToComposeServiceDevicesAndAddresses(t *testing.T) {
+	c := NewConfig("trainer")
+	c.Options.Image = "trainer"
+	c.SetContainerOptions(containeroptions.MacAddress("02:42:ac:11:00:02"), func(options *container.Config) {
+		options.Healthcheck = &container.HealthConfig{Test: []string{"CMD-SHELL", "nvidia-smi"}, StartInterval: 2 * time.Second}
+	})
+	c.SetHostOptions(
+		hostoptions.AllGPUs(),
+		hostoptions.GPUByUUID("GPU-3a23c669"),
+		func(host *container.HostConfig) {
+			host.DeviceRequests = append(host.DeviceRequests, container.DeviceRequest{
+				Driver: "cdi", Count: 1, Capabilities: [][]string{{"tpu"}}, Options: map[string]string{"mode": "shared"},
+			})
+		},
+		hostoptions.Annotation("com.example.team", "ml"),
+	)
+	backend := endpointoptions.NewConfig()
+	backend.SetEndpointSetting(endpointoptions.MacAddress("02:42:ac:11:00:03"))
+	c.SetNetworkOptions(networkoptions.Endpoint("backend", backend))
+	out, err := c.ToComposeService()
+	require.NoError(t, err)
+
+	var file struct {
+		Services map[string]struct {
+			Annotations map[string]string
+			MacAddress  string `yaml:"mac_address"`
+			Networks    map[string]struct {
+				MacAddress string `yaml:"mac_address"`
+			}
+			Deploy struct {
+				Resources struct {
+					Reservations struct {
+						Devices []map[string]any
+					}
+				}
+			}
+			Healthcheck struct {
+				StartInterval string `yaml:"start_interval"`
+			}
+		}
+	}
+	require.NoError(t, yaml.Unmarshal(out, &file), string(out))
+	trainer := file.Services["trainer"]
+	assert.Equal(t, map[string]string{"com.example.team": "ml"}, trainer.Annotations)
+	assert.Equal(t, "02:42:ac:11:00:02", trainer.MacAddress)
+	assert.Equal(t, "02:42:ac:11:00:03", trainer.Networks["backend"].MacAddress)
+	assert.Equal(t, "2s", trainer.Healthcheck.StartInterval)
+	assert.Equal(t, []map[string]any{
+		{"count": "all", "capabilities": []any{"gpu"}},
+		{"driver": "nvidia", "device_ids": []any{"GPU-3a23c669"}, "capabilities": []any{"gpu"}},
+		{"driver": "cdi", "count": 1, "capabilities": []any{"tpu"}, "options": map[string]any{"mode": "shared"}},
+	}, trainer.Deploy.Resources.Reservations.Devices)
+}
+
+func TestToComposeService(t *testing.T) {
+	out, err := renderTestConfig().ToComposeService()
+	require.NoError(t, err)
+
+	var file struct {
+		Services map[string]struct {
+			Image         string
+			ContainerName string `yaml:"container_name"`
+			Command       []string
+			Environment   []string
+			Labels        map[string]string
+			Ports         []string
+			Expose        []string
+			Volumes       []any
+			Networks      map[string]struct{ Aliases []string }
+			Restart       string
+			Healthcheck   struct {
+				Test     []string
+				Interval string
+				Retries  int
+			}
+		}
+		Networks map[string]map[string]bool
+		Volumes  map[string]map[string]bool
+	}
+	require.NoError(t, yaml.Unmarshal(out, &file), string(out))
+	require.Contains(t, file.Services, "api")
+	api := file.Services["api"]
+	assert.Equal(t, "registry.example.com/api:1.4", api.Image)
+	assert.Equal(t, "api", api.ContainerName)
+	assert.Equal(t, []string{"serve", "--verbose"}, api.Command)
+	assert.Equal(t, []string{"GREETING=it's me", "API_TOKEN"}, api.Environment)
+	assert.Equal(t, map[string]string{"tier": "backend"}, api.Labels)
+	assert.Equal(t, []string{"127.0.0.1:8080:8080"}, api.Ports)
+	assert.Equal(t, []string{"9090"}, api.Expose)
+	assert.Equal(t, []any{
+		"data:/var/lib/api",
+		map[string]any{"type": "bind", "source": "/etc/api", "target": "/config", "read_only": true},
+	}, api.Volumes)
+	assert.Equal(t, []string{"api.internal"}, api.Networks["backend"].Aliases)
+	assert.Equal(t, "always", api.Restart)
+	assert.Equal(t, []string{"CMD", "curl", "-f", "http://localhost:8080/health"}, api.Healthcheck.Test)
+	assert.Equal(t, "30s", api.Healthcheck.Interval)
+	assert.Equal(t, 3, api.Healthcheck.Retries)
+	assert.Equal(t, map[string]map[string]bool{"backend": {"external": true}}, file.Networks)
+	assert.Equal(t, map[string]map[string]bool{"data": {"external": true}}, file.Volumes)
+	assert.NotContains(t, string(out), "tok-123")
+}
+
+func TestToComposeServiceNetworkMode(t *testing.T) {
+	c := NewConfig("api")
+	c.Options.Image = "api"
+	c.SetHostOptions(func(host *container.HostConfig) { host.NetworkMode = "backend" })
+	out, err := c.ToComposeService()
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "network_mode", "compose does not allow a network mode next to networks")
+	assert.Contains(t, string(out), "networks:\n            backend: null\n")
+
+	c.SetHostOptions(func(host *container.HostConfig) { host.NetworkMode = "host" })
+	out, err = c.ToComposeService()
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "network_mode: host\n")
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, "plain/path:1.0", shellQuote("plain/path:1.0"))
+	assert.Equal(t, "''", shellQuote(""))
+	assert.Equal(t, "'a b'", shellQuote("a b"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+	assert.Equal(t, "'$HOME'", shellQuote("$HOME"))
+}
@@ -0,0 +1,39 @@
+package godock
+
+import (
+	"context"
+	"io"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+/*
+CopyToContainer extracts a tar archive into the directory containerPath of a container, which must exist. Missing
+directories of the entries are created, so copying into "/" with entries named after their absolute path places
+files anywhere in the container. The container does not need to be running.
+
+Usage example:
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: "etc/app/config.yaml", Mode: 0o644, Size: int64(len(config))})
+	tw.Write(config)
+	tw.Close()
+	err := client.CopyToContainer(ctx, app, "/", &buf)
+*/
+func (c *Client) CopyToContainer(ctx context.Context, containerConfig *container.ContainerConfig, containerPath string, archive io.Reader) error {
+	if err := validateContainerConfig(containerConfig); err != nil {
+		return err
+	}
+	err := c.wrapped.CopyToContainer(ctx, containerConfig.Id, containerPath, archive, containerType.CopyToContainerOptions{})
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return &errdefs.ResourceNotFoundError{ResourceType: "container path", ID: containerPath, Err: err}
+		}
+		return &errdefs.ContainerError{ID: containerConfig.Id, Op: "copy to " + containerPath, Message: err.Error(), Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package godock
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyToContainer(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /v1.45/containers/{id}/archive", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("path") != "/" {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message":"Could not find the file /missing in container app"}`)
+			return
+		}
+		header, err := tar.NewReader(r.Body).Next()
+		require.NoError(t, err)
+		assert.Equal(t, "etc/app/config.yaml", header.Name)
+		w.WriteHeader(http.StatusOK)
+	})
+	c := newFakeDaemonClient(t, mux)
+	ctx := context.Background()
+	app := &container.ContainerConfig{Id: "app", Name: "app"}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "etc/app/config.yaml", Mode: 0o644}))
+	require.NoError(t, tw.Close())
+	require.NoError(t, c.CopyToContainer(ctx, app, "/", &buf))
+
+	err := c.CopyToContainer(ctx, app, "/missing", bytes.NewReader(nil))
+	assert.True(t, errdefs.IsNotFound(err))
+	err = c.CopyToContainer(ctx, nil, "/", bytes.NewReader(nil))
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
@@ -0,0 +1,190 @@
+package godock
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	containerType "github.com/docker/docker/api/types/container"
+)
+
+// Kinds of filesystem changes reported by ContainerDiff.
+const (
+	ChangeModified = containerType.ChangeModify
+	ChangeAdded    = containerType.ChangeAdd
+	ChangeDeleted  = containerType.ChangeDelete
+)
+
+type diffOptions struct {
+	prefixes []string
+	kinds    []containerType.ChangeType
+}
+
+// DiffOptionFn is a function that filters the changes returned by ContainerDiffFiltered and SnapshotAfter.
+type DiffOptionFn func(*diffOptions)
+
+// WithPathPrefix keeps only changes at or below one of the given directories, e.g. "/etc".
+func WithPathPrefix(prefixes ...string) DiffOptionFn {
+	return func(opts *diffOptions) {
+		opts.prefixes = append(opts.prefixes, prefixes...)
+	}
+}
+
+// WithChangeKind keeps only changes of the given kinds.
+func WithChangeKind(kinds ...containerType.ChangeType) DiffOptionFn {
+	return func(opts *diffOptions) {
+		opts.kinds = append(opts.kinds, kinds...)
+	}
+}
+
+func newDiffOptions(diffOptionFns []DiffOptionFn) *diffOptions {
+	opts := &diffOptions{}
+	for _, fn := range diffOptionFns {
+		if fn != nil {
+			fn(opts)
+		}
+	}
+	return opts
+}
+
+// match reports whether a change passes the filters.
+func (opts *diffOptions) match(change containerType.FilesystemChange) bool {
+	if len(opts.kinds) > 0 {
+		found := false
+		for _, kind := range opts.kinds {
+			if change.Kind == kind {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(opts.prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range opts.prefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if prefix == "" || change.Path == prefix || strings.HasPrefix(change.Path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (opts *diffOptions) filter(changes []containerType.FilesystemChange) []containerType.FilesystemChange {
+	filtered := []containerType.FilesystemChange{}
+	for _, change := range changes {
+		if opts.match(change) {
+			filtered = append(filtered, change)
+		}
+	}
+	return filtered
+}
+
+/*
+ContainerDiffFiltered returns the changes on a container's filesystem that pass the given filters.
+A path prefix matches whole path components, so "/etc" matches "/etc/hosts" but not "/etcd".
+
+Usage example:
+
+	added, err := client.ContainerDiffFiltered(ctx, ctr,
+		godock.WithPathPrefix("/etc"),
+		godock.WithChangeKind(godock.ChangeAdded),
+	)
+*/
+func (c *Client) ContainerDiffFiltered(ctx context.Context, containerConfig *container.ContainerConfig, diffOptionFns ...DiffOptionFn) ([]containerType.FilesystemChange, error) {
+	diff, err := c.ContainerDiff(ctx, containerConfig)
+	if err != nil {
+		return nil, err
+	}
+	return newDiffOptions(diffOptionFns).filter(diff), nil
+}
+
+// FilesystemSnapshot records the changes on a container's filesystem at a point in time.
+type FilesystemSnapshot struct {
+	Container *container.ContainerConfig
+	Taken     time.Time
+	changes   map[string]containerType.ChangeType
+}
+
+// SnapshotBefore records the current changes on a container's filesystem, to be compared later with SnapshotAfter.
+func (c *Client) SnapshotBefore(ctx context.Context, containerConfig *container.ContainerConfig) (*FilesystemSnapshot, error) {
+	diff, err := c.ContainerDiff(ctx, containerConfig)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &FilesystemSnapshot{
+		Container: containerConfig,
+		Taken:     c.Clock().Now(),
+		changes:   make(map[string]containerType.ChangeType, len(diff)),
+	}
+	for _, change := range diff {
+		snapshot.changes[change.Path] = change.Kind
+	}
+	return snapshot, nil
+}
+
+/*
+SnapshotAfter returns the changes made to the container's filesystem since the snapshot was taken,
+sorted by path. The daemon only reports which paths differ from the image, so a file that was
+already modified before the snapshot and is modified again is not reported.
+
+Usage example:
+
+	before, err := client.SnapshotBefore(ctx, ctr)
+	if err != nil {
+		return err
+	}
+	// run the installer
+	changes, err := client.SnapshotAfter(ctx, before, godock.WithPathPrefix("/usr/local"))
+*/
+func (c *Client) SnapshotAfter(ctx context.Context, before *FilesystemSnapshot, diffOptionFns ...DiffOptionFn) ([]containerType.FilesystemChange, error) {
+	if before == nil || before.Container == nil {
+		return nil, &errdefs.ValidationError{
+			Field:   "before",
+			Message: "snapshot cannot be empty",
+		}
+	}
+	diff, err := c.ContainerDiff(ctx, before.Container)
+	if err != nil {
+		return nil, err
+	}
+	return newDiffOptions(diffOptionFns).filter(diffSnapshots(before.changes, diff)), nil
+}
+
+// diffSnapshots returns the changes in after that are not in before, sorted by path.
+// Paths that were changed in before but no longer differ from the image were reverted,
+// which is reported as the opposite change.
+func diffSnapshots(before map[string]containerType.ChangeType, after []containerType.FilesystemChange) []containerType.FilesystemChange {
+	var changes []containerType.FilesystemChange
+	seen := make(map[string]struct{}, len(after))
+	for _, change := range after {
+		seen[change.Path] = struct{}{}
+		if kind, ok := before[change.Path]; ok && kind == change.Kind {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	for path, kind := range before {
+		if _, ok := seen[path]; ok {
+			continue
+		}
+		reverted := containerType.FilesystemChange{Path: path, Kind: ChangeModified}
+		switch kind {
+		case ChangeAdded:
+			reverted.Kind = ChangeDeleted
+		case ChangeDeleted:
+			reverted.Kind = ChangeAdded
+		}
+		changes = append(changes, reverted)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
@@ -0,0 +1,51 @@
+package godock
+
+import (
+	"testing"
+
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffOptionsFilter(t *testing.T) {
+	changes := []containerType.FilesystemChange{
+		{Path: "/etc", Kind: ChangeModified},
+		{Path: "/etc/hosts", Kind: ChangeModified},
+		{Path: "/etc/app.conf", Kind: ChangeAdded},
+		{Path: "/etcd/data", Kind: ChangeAdded},
+		{Path: "/tmp/x", Kind: ChangeDeleted},
+	}
+
+	opts := newDiffOptions([]DiffOptionFn{WithPathPrefix("/etc/")})
+	assert.Equal(t, changes[:3], opts.filter(changes))
+
+	opts = newDiffOptions([]DiffOptionFn{WithPathPrefix("/etc"), WithChangeKind(ChangeAdded)})
+	assert.Equal(t, []containerType.FilesystemChange{{Path: "/etc/app.conf", Kind: ChangeAdded}}, opts.filter(changes))
+
+	opts = newDiffOptions([]DiffOptionFn{WithChangeKind(ChangeDeleted, ChangeAdded)})
+	assert.Len(t, opts.filter(changes), 3)
+
+	assert.Equal(t, changes, newDiffOptions(nil).filter(changes))
+	assert.NotNil(t, opts.filter(nil))
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	before := map[string]containerType.ChangeType{
+		"/var/log":         ChangeModified,
+		"/var/log/app.log": ChangeAdded,
+		"/tmp/lock":        ChangeAdded,
+		"/etc/motd":        ChangeDeleted,
+	}
+	after := []containerType.FilesystemChange{
+		{Path: "/var/log", Kind: ChangeModified},
+		{Path: "/var/log/app.log", Kind: ChangeAdded},
+		{Path: "/usr/local/bin/tool", Kind: ChangeAdded},
+		{Path: "/etc/motd", Kind: ChangeModified},
+	}
+
+	assert.Equal(t, []containerType.FilesystemChange{
+		{Path: "/etc/motd", Kind: ChangeModified},
+		{Path: "/tmp/lock", Kind: ChangeDeleted},
+		{Path: "/usr/local/bin/tool", Kind: ChangeAdded},
+	}, diffSnapshots(before, after))
+}
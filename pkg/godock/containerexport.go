@@ -0,0 +1,189 @@
+package godock
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+)
+
+type exportOptions struct {
+	paths    []string
+	gzip     bool
+	progress Progress
+}
+
+// ExportOptionFn is a function that configures ContainerExport and ExportToFile.
+type ExportOptionFn func(*exportOptions)
+
+// ExportPaths exports only the given files and directories instead of the whole container filesystem.
+// Entries keep their absolute location, so "/etc/nginx/nginx.conf" is stored as "etc/nginx/nginx.conf".
+func ExportPaths(paths ...string) ExportOptionFn {
+	return func(opts *exportOptions) {
+		opts.paths = append(opts.paths, paths...)
+	}
+}
+
+// WithGzip compresses the exported tar archive with gzip.
+func WithGzip() ExportOptionFn {
+	return func(opts *exportOptions) {
+		opts.gzip = true
+	}
+}
+
+// WithExportReporter reports the export to progress, as the archive bytes read from the returned stream.
+// The total is not known in advance. The export is done once the stream has been read to the end.
+func WithExportReporter(progress Progress) ExportOptionFn {
+	return func(opts *exportOptions) {
+		opts.progress = progress
+	}
+}
+
+/*
+ContainerExport retrieves the contents of a container as a tar archive and returns them as an io.ReadCloser.
+By default the whole root filesystem is exported; use ExportPaths to export selected paths only.
+It's up to the caller to close the stream.
+
+Usage example:
+
+	rc, err := client.ContainerExport(ctx, ctr, godock.ExportPaths("/app", "/etc/nginx"), godock.WithGzip())
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+*/
+func (c *Client) ContainerExport(ctx context.Context, containerConfig *container.ContainerConfig, exportOptionFns ...ExportOptionFn) (io.ReadCloser, error) {
+	opts := &exportOptions{}
+	for _, fn := range exportOptionFns {
+		if fn != nil {
+			fn(opts)
+		}
+	}
+	for _, p := range opts.paths {
+		if !path.IsAbs(p) || path.Clean(p) == "/" {
+			return nil, &errdefs.ValidationError{
+				Field:   "paths",
+				Message: "export path must be an absolute path below the root, got " + p,
+			}
+		}
+	}
+
+	tracker := newProgressTracker(opts.progress, ProgressOpExport, containerConfig.Name, 0)
+	tracker.start()
+	var rc io.ReadCloser
+	if len(opts.paths) == 0 {
+		full, err := c.wrapped.ContainerExport(ctx, containerConfig.Id)
+		if err != nil {
+			err = &errdefs.ContainerError{ID: containerConfig.Id, Op: "export", Message: err.Error(), Err: err}
+			tracker.finish(err)
+			return nil, err
+		}
+		rc = full
+	} else {
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(c.exportPaths(ctx, containerConfig, opts.paths, pw))
+		}()
+		rc = pr
+	}
+	if !opts.gzip {
+		return trackStream(rc, tracker, true), nil
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer rc.Close()
+		gz := gzip.NewWriter(pw)
+		if _, err := io.Copy(gz, rc); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(gz.Close())
+	}()
+	return trackStream(pr, tracker, true), nil
+}
+
+// exportPaths writes the given paths of a container to w as a single tar archive.
+func (c *Client) exportPaths(ctx context.Context, containerConfig *container.ContainerConfig, paths []string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+	for _, p := range paths {
+		p = path.Clean(p)
+		rc, _, err := c.wrapped.CopyFromContainer(ctx, containerConfig.Id, p)
+		if err != nil {
+			return &errdefs.ContainerError{ID: containerConfig.Id, Op: "export " + p, Message: err.Error(), Err: err}
+		}
+		err = rebaseTarEntries(tar.NewReader(rc), tw, strings.TrimPrefix(path.Dir(p), "/"))
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// rebaseTarEntries copies the entries of tr to tw, placing them below dir.
+// CopyFromContainer names entries relative to the parent of the copied path.
+func rebaseTarEntries(tr *tar.Reader, tw *tar.Writer, dir string) error {
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		header.Name = rebaseTarName(dir, header.Name)
+		if header.Typeflag == tar.TypeLink {
+			header.Linkname = rebaseTarName(dir, header.Linkname)
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func rebaseTarName(dir, name string) string {
+	rebased := path.Join(dir, name)
+	if strings.HasSuffix(name, "/") {
+		rebased += "/"
+	}
+	return rebased
+}
+
+// ExportToFile exports a container filesystem to a tar archive on the client host. The archive is
+// gzip compressed with WithGzip or when the file name ends in ".gz" or ".tgz".
+// A partially written file is removed when the export fails.
+func (c *Client) ExportToFile(ctx context.Context, containerConfig *container.ContainerConfig, outputFile string, exportOptionFns ...ExportOptionFn) (err error) {
+	if compressionForFile(outputFile) == CompressionGzip {
+		exportOptionFns = append(exportOptionFns, WithGzip())
+	}
+	rc, err := c.ContainerExport(ctx, containerConfig, exportOptionFns...)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	file, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(outputFile)
+		}
+	}()
+	if _, err = io.Copy(file, rc); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,61 @@
+package godock
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRebaseTarEntries(t *testing.T) {
+	var src bytes.Buffer
+	tw := tar.NewWriter(&src)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "nginx/", Typeflag: tar.TypeDir, Mode: 0o755}))
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "nginx/nginx.conf", Typeflag: tar.TypeReg, Mode: 0o644, Size: 5}))
+	_, err := tw.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "nginx/default.conf", Typeflag: tar.TypeLink, Linkname: "nginx/nginx.conf"}))
+	require.NoError(t, tw.Close())
+
+	var dst bytes.Buffer
+	out := tar.NewWriter(&dst)
+	require.NoError(t, rebaseTarEntries(tar.NewReader(&src), out, "etc"))
+	require.NoError(t, out.Close())
+
+	tr := tar.NewReader(&dst)
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, header.Name)
+		if header.Typeflag == tar.TypeLink {
+			assert.Equal(t, "etc/nginx/nginx.conf", header.Linkname)
+		}
+		if header.Name == "etc/nginx/nginx.conf" {
+			data, err := io.ReadAll(tr)
+			require.NoError(t, err)
+			assert.Equal(t, "hello", string(data))
+		}
+	}
+	assert.Equal(t, []string{"etc/nginx/", "etc/nginx/nginx.conf", "etc/nginx/default.conf"}, names)
+}
+
+func TestContainerExportValidation(t *testing.T) {
+	c := &Client{}
+	ctr := container.NewConfig("app")
+
+	_, err := c.ContainerExport(context.Background(), ctr, ExportPaths("relative/path"))
+	assert.True(t, errdefs.IsInvalidConfig(err))
+
+	_, err = c.ContainerExport(context.Background(), ctr, ExportPaths("/"))
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
@@ -2,6 +2,7 @@ package containeroptions
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
@@ -11,16 +12,18 @@ import (
 type SetOptionsFns func(options *container.Config)
 
 /*
-Adds a health check to the container configuration that exec arguments directly
+Adds a health check to the container configuration that exec arguments directly.
+The first argument must be CMD or CMD-SHELL. HealthCheckShell and HealthCheckCmd set the
+same fields with named options, which are harder to misorder.
 
 	myContainer := container.NewConfig("my_container")
 	myContainer.SetContainerOptions(
 		containeroptions.HealthCheckExec(
 			time.Second*5,  //start
 			time.Second*20, //timeout
-			time.Second*5, //interval
-			10, //timeout
-			"CMD-SHELL", "curl", "-f", "http://localhost", "||", "exit", "1",
+			time.Second*5,  //interval
+			10,             //retries
+			"CMD-SHELL", "curl -f http://localhost || exit 1",
 		)
 	)
 */
@@ -47,7 +50,7 @@ Disables the health check
 func DisableHealthCheck() SetOptionsFns {
 	return func(Config *container.Config) {
 		Config.Healthcheck = &container.HealthConfig{
-			Test: []string{"None"},
+			Test: []string{"NONE"},
 		}
 	}
 }
@@ -62,7 +65,8 @@ func Env(key string, value string) SetOptionsFns {
 }
 
 /*
-Exposes a port
+Exposes a port. A port without a protocol is exposed as TCP, so "8000" and "8000/tcp" are the same
+port, matching the keys used by hostoptions.PortBindings.
 
 	myContainer := container.NewConfig("my_container")
 	myContainer.SetContainerOptions(
@@ -74,10 +78,67 @@ func Expose(containerPort string) SetOptionsFns {
 		if Config.ExposedPorts == nil {
 			Config.ExposedPorts = make(nat.PortSet)
 		}
-		Config.ExposedPorts[nat.Port(containerPort)] = struct{}{}
+		Config.ExposedPorts[normalizePort(containerPort)] = struct{}{}
 	}
 }
 
+/*
+Exposes a TCP port.
+
+	myContainer := container.NewConfig("my_container")
+	myContainer.SetContainerOptions(
+		containeroptions.ExposeTCP("5432"),
+	)
+*/
+func ExposeTCP(containerPort string) SetOptionsFns {
+	return Expose(containerPort + "/tcp")
+}
+
+/*
+Exposes a UDP port.
+
+	myContainer := container.NewConfig("my_container")
+	myContainer.SetContainerOptions(
+		containeroptions.ExposeUDP("53"),
+	)
+*/
+func ExposeUDP(containerPort string) SetOptionsFns {
+	return Expose(containerPort + "/udp")
+}
+
+/*
+Exposes several ports at once, each given as port, port/protocol or a range such as 7000-7010/udp.
+
+	myContainer := container.NewConfig("my_container")
+	myContainer.SetContainerOptions(
+		containeroptions.ExposeMany("80", "443", "53/udp"),
+	)
+*/
+func ExposeMany(containerPorts ...string) SetOptionsFns {
+	return func(Config *container.Config) {
+		for _, containerPort := range containerPorts {
+			if start, end, err := nat.ParsePortRange(strings.SplitN(containerPort, "/", 2)[0]); err == nil && end > start {
+				proto, _ := nat.SplitProtoPort(containerPort)
+				for port := start; port <= end; port++ {
+					Expose(fmt.Sprintf("%d/%s", port, proto))(Config)
+				}
+				continue
+			}
+			Expose(containerPort)(Config)
+		}
+	}
+}
+
+// normalizePort returns the port/protocol key the daemon uses for a port, defaulting the protocol to tcp.
+func normalizePort(containerPort string) nat.Port {
+	proto, port := nat.SplitProtoPort(containerPort)
+	normalized, err := nat.NewPort(strings.ToLower(proto), port)
+	if err != nil {
+		return nat.Port(containerPort)
+	}
+	return normalized
+}
+
 /*
 Adds a hostname to the container configuration.
 
@@ -479,3 +540,20 @@ func SetStderrAttach(attach bool) SetOptionsFns {
 		Config.AttachStderr = attach
 	}
 }
+
+/*
+Sets the MAC address of the container on its primary network, the one selected by hostoptions.NetworkMode
+or the default bridge network. Use endpointoptions.MacAddress to set the MAC address on other networks.
+
+Usage example:
+
+	myContainer := container.NewConfig("my_container")
+	myContainer.SetContainerOptions(
+		containeroptions.MacAddress("02:42:ac:11:00:02"),
+	)
+*/
+func MacAddress(mac string) SetOptionsFns {
+	return func(Config *container.Config) {
+		Config.MacAddress = mac //nolint:staticcheck // moved onto the network endpoint by ContainerCreate
+	}
+}
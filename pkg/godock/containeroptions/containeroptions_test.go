@@ -0,0 +1,28 @@
+package containeroptions
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpose(t *testing.T) {
+	config := &container.Config{}
+	Expose("8000")(config)
+	Expose("8000/tcp")(config)
+	ExposeTCP("5432")(config)
+	ExposeUDP("53")(config)
+	ExposeMany("443", "7000-7002/udp")(config)
+
+	assert.Equal(t, nat.PortSet{
+		"8000/tcp": {},
+		"5432/tcp": {},
+		"53/udp":   {},
+		"443/tcp":  {},
+		"7000/udp": {},
+		"7001/udp": {},
+		"7002/udp": {},
+	}, config.ExposedPorts)
+}
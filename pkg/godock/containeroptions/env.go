@@ -0,0 +1,174 @@
+package containeroptions
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/docker/docker/api/types/container"
+)
+
+/*
+Adds every entry of a map as an environment variable, in key order.
+
+Usage example:
+
+	myContainer := container.NewConfig("my_container")
+	myContainer.SetContainerOptions(
+		containeroptions.EnvMap(map[string]string{
+			"POSTGRES_USER": "app",
+			"POSTGRES_DB":   "app",
+		}),
+	)
+*/
+func EnvMap(env map[string]string) SetOptionsFns {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return func(options *container.Config) {
+		for _, key := range keys {
+			Env(key, env[key])(options)
+		}
+	}
+}
+
+/*
+Passes the named environment variables of the current process through to the container.
+Variables that are not set are skipped.
+
+Usage example:
+
+	myContainer := container.NewConfig("my_container")
+	myContainer.SetContainerOptions(
+		containeroptions.EnvFromOS("AWS_REGION", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"),
+	)
+*/
+func EnvFromOS(keys ...string) SetOptionsFns {
+	env := make(map[string]string)
+	for _, key := range keys {
+		if value, ok := os.LookupEnv(key); ok {
+			env[key] = value
+		}
+	}
+	return func(options *container.Config) {
+		for _, key := range keys {
+			if value, ok := env[key]; ok {
+				Env(key, value)(options)
+			}
+		}
+	}
+}
+
+/*
+Reads a dotenv file and adds its variables to the container, in file order.
+
+Each line holds KEY=VALUE. Blank lines and lines starting with # are ignored, and an optional
+"export " prefix is allowed. Values may be wrapped in single quotes, taken literally, or double quotes,
+which support \n, \t, \" and \\ escapes. A line with only a KEY passes through the variable of the
+current process, like docker run --env-file, and is skipped when it is not set.
+
+Usage example:
+
+	envFile, err := containeroptions.EnvFromFile(".env")
+	if err != nil {
+		log.Fatal(err)
+	}
+	myContainer.SetContainerOptions(envFile)
+*/
+func EnvFromFile(path string) (SetOptionsFns, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, &errdefs.ValidationError{
+			Field:   "path",
+			Message: fmt.Sprintf("cannot read env file: %v", err),
+		}
+	}
+	defer file.Close()
+
+	var env []string
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		key, value, ok, err := parseEnvLine(scanner.Text())
+		if err != nil {
+			return nil, &errdefs.ValidationError{
+				Field:   "path",
+				Message: fmt.Sprintf("%s:%d: %v", path, lineNumber, err),
+			}
+		}
+		if ok {
+			env = append(env, key+"="+value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &errdefs.ValidationError{
+			Field:   "path",
+			Message: fmt.Sprintf("cannot read env file: %v", err),
+		}
+	}
+	return func(options *container.Config) {
+		options.Env = append(options.Env, env...)
+	}, nil
+}
+
+// parseEnvLine parses one line of a dotenv file. ok is false for lines that set no variable.
+func parseEnvLine(line string) (key, value string, ok bool, err error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false, nil
+	}
+	line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+	key, value, hasValue := strings.Cut(line, "=")
+	key = strings.TrimSpace(key)
+	if key == "" || strings.ContainsAny(key, " \t\"'") {
+		return "", "", false, fmt.Errorf("invalid variable name %q", key)
+	}
+	if !hasValue {
+		value, ok = os.LookupEnv(key)
+		return key, value, ok, nil
+	}
+
+	value = strings.TrimSpace(value)
+	switch {
+	case strings.HasPrefix(value, `"`):
+		end := closingQuote(value)
+		if end < 0 {
+			return "", "", false, fmt.Errorf("unterminated double quote in value of %s", key)
+		}
+		value, err = strconv.Unquote(value[:end+1])
+		if err != nil {
+			return "", "", false, fmt.Errorf("invalid escape in value of %s", key)
+		}
+	case strings.HasPrefix(value, "'"):
+		end := strings.IndexByte(value[1:], '\'')
+		if end < 0 {
+			return "", "", false, fmt.Errorf("unterminated single quote in value of %s", key)
+		}
+		value = value[1 : end+1]
+	default:
+		// An unquoted value ends at an inline comment.
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+	}
+	return key, value, true, nil
+}
+
+// closingQuote returns the index of the double quote closing the string that value starts with, or -1.
+func closingQuote(value string) int {
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
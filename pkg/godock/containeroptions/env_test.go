@@ -0,0 +1,68 @@
+package containeroptions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvMapAndOS(t *testing.T) {
+	t.Setenv("GODOCK_TEST_REGION", "eu-west-1")
+
+	config := &container.Config{}
+	EnvMap(map[string]string{"B": "2", "A": "1"})(config)
+	EnvFromOS("GODOCK_TEST_REGION", "GODOCK_TEST_UNSET")(config)
+
+	assert.Equal(t, []string{"A=1", "B=2", "GODOCK_TEST_REGION=eu-west-1"}, config.Env)
+}
+
+func TestEnvFromFile(t *testing.T) {
+	t.Setenv("GODOCK_TEST_TOKEN", "secret")
+	path := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(path, []byte(`# database
+DB_HOST=db
+export DB_PORT = 5432
+DB_PASSWORD="p@ss \"word\"\n" # trailing comment
+GREETING='hello # not a comment'
+LOG_LEVEL=debug # inline comment
+EMPTY=
+GODOCK_TEST_TOKEN
+GODOCK_TEST_UNSET
+`), 0o644))
+
+	envFile, err := EnvFromFile(path)
+	require.NoError(t, err)
+	config := &container.Config{}
+	envFile(config)
+
+	assert.Equal(t, []string{
+		"DB_HOST=db",
+		"DB_PORT=5432",
+		"DB_PASSWORD=p@ss \"word\"\n",
+		"GREETING=hello # not a comment",
+		"LOG_LEVEL=debug",
+		"EMPTY=",
+		"GODOCK_TEST_TOKEN=secret",
+	}, config.Env)
+}
+
+func TestEnvFromFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"quote": "KEY=\"unterminated\n",
+		"name":  "BAD KEY=value\n",
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		_, err := EnvFromFile(path)
+		assert.True(t, errdefs.IsInvalidConfig(err), name)
+	}
+
+	_, err := EnvFromFile(filepath.Join(dir, "missing"))
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
@@ -0,0 +1,148 @@
+package containeroptions
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/docker/docker/api/types/container"
+)
+
+// minHealthCheckDuration is the smallest non-zero duration the daemon accepts for health check timings.
+const minHealthCheckDuration = time.Millisecond
+
+// HealthCheckOptFn is a function that configures a health check created by HealthCheckShell or HealthCheckCmd.
+// Timings that are not set use the daemon defaults.
+type HealthCheckOptFn func(*container.HealthConfig)
+
+// HealthInterval sets the time between health checks.
+func HealthInterval(interval time.Duration) HealthCheckOptFn {
+	return func(hc *container.HealthConfig) {
+		hc.Interval = interval
+	}
+}
+
+// HealthTimeout sets how long a single health check may run before it is considered failed.
+func HealthTimeout(timeout time.Duration) HealthCheckOptFn {
+	return func(hc *container.HealthConfig) {
+		hc.Timeout = timeout
+	}
+}
+
+// HealthStartPeriod sets the start-up time during which failed health checks do not count towards the retries.
+func HealthStartPeriod(startPeriod time.Duration) HealthCheckOptFn {
+	return func(hc *container.HealthConfig) {
+		hc.StartPeriod = startPeriod
+	}
+}
+
+// HealthStartInterval sets the time between health checks during the start period. It requires API version 1.44 or later.
+func HealthStartInterval(startInterval time.Duration) HealthCheckOptFn {
+	return func(hc *container.HealthConfig) {
+		hc.StartInterval = startInterval
+	}
+}
+
+// HealthRetries sets the number of consecutive failures needed to report the container as unhealthy.
+func HealthRetries(retries int) HealthCheckOptFn {
+	return func(hc *container.HealthConfig) {
+		hc.Retries = retries
+	}
+}
+
+func healthCheck(test []string, healthCheckOptFns []HealthCheckOptFn) SetOptionsFns {
+	return func(Config *container.Config) {
+		hc := &container.HealthConfig{Test: test}
+		for _, fn := range healthCheckOptFns {
+			if fn != nil {
+				fn(hc)
+			}
+		}
+		Config.Healthcheck = hc
+	}
+}
+
+/*
+Adds a health check that runs a command with the container's default shell, like HEALTHCHECK CMD in a Dockerfile.
+
+Usage example:
+
+	myContainer := container.NewConfig("my_container")
+	myContainer.SetContainerOptions(
+		containeroptions.HealthCheckShell("curl -f http://localhost/ || exit 1",
+			containeroptions.HealthInterval(10*time.Second),
+			containeroptions.HealthTimeout(3*time.Second),
+			containeroptions.HealthRetries(3),
+		),
+	)
+*/
+func HealthCheckShell(cmd string, healthCheckOptFns ...HealthCheckOptFn) SetOptionsFns {
+	return healthCheck([]string{"CMD-SHELL", cmd}, healthCheckOptFns)
+}
+
+/*
+Adds a health check that executes a command directly, without a shell.
+
+Usage example:
+
+	myContainer := container.NewConfig("my_container")
+	myContainer.SetContainerOptions(
+		containeroptions.HealthCheckCmd([]string{"redis-cli", "ping"},
+			containeroptions.HealthInterval(5*time.Second),
+			containeroptions.HealthStartPeriod(10*time.Second),
+			containeroptions.HealthStartInterval(time.Second),
+		),
+	)
+*/
+func HealthCheckCmd(args []string, healthCheckOptFns ...HealthCheckOptFn) SetOptionsFns {
+	return healthCheck(append([]string{"CMD"}, args...), healthCheckOptFns)
+}
+
+/*
+Disables any health check defined by the image. It is the same as DisableHealthCheck.
+
+	myContainer := container.NewConfig("my_container")
+	myContainer.SetContainerOptions(
+		containeroptions.HealthCheckDisable(),
+	)
+*/
+func HealthCheckDisable() SetOptionsFns {
+	return DisableHealthCheck()
+}
+
+// ValidateHealthCheck checks the timings and retries of a health check the way the daemon does,
+// so a misconfigured health check fails before the container is created. ContainerCreate runs it.
+func ValidateHealthCheck(hc *container.HealthConfig) error {
+	if hc == nil {
+		return nil
+	}
+	for _, timing := range []struct {
+		field string
+		value time.Duration
+	}{
+		{"Interval", hc.Interval},
+		{"Timeout", hc.Timeout},
+		{"StartPeriod", hc.StartPeriod},
+		{"StartInterval", hc.StartInterval},
+	} {
+		if timing.value != 0 && timing.value < minHealthCheckDuration {
+			return &errdefs.ValidationError{
+				Field:   "Healthcheck." + timing.field,
+				Message: fmt.Sprintf("%s must be at least %s, or 0 for the default", timing.value, minHealthCheckDuration),
+			}
+		}
+	}
+	if hc.Retries < 0 {
+		return &errdefs.ValidationError{
+			Field:   "Healthcheck.Retries",
+			Message: fmt.Sprintf("retries cannot be negative, got %d", hc.Retries),
+		}
+	}
+	if len(hc.Test) == 1 && (hc.Test[0] == "CMD" || hc.Test[0] == "CMD-SHELL") {
+		return &errdefs.ValidationError{
+			Field:   "Healthcheck.Test",
+			Message: hc.Test[0] + " health check has no command",
+		}
+	}
+	return nil
+}
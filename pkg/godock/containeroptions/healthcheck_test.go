@@ -0,0 +1,50 @@
+package containeroptions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthCheckVariants(t *testing.T) {
+	config := &container.Config{}
+	HealthCheckShell("curl -f http://localhost/ || exit 1",
+		HealthInterval(10*time.Second),
+		HealthTimeout(3*time.Second),
+		HealthStartPeriod(30*time.Second),
+		HealthStartInterval(time.Second),
+		HealthRetries(3),
+	)(config)
+	assert.Equal(t, &container.HealthConfig{
+		Test:          []string{"CMD-SHELL", "curl -f http://localhost/ || exit 1"},
+		Interval:      10 * time.Second,
+		Timeout:       3 * time.Second,
+		StartPeriod:   30 * time.Second,
+		StartInterval: time.Second,
+		Retries:       3,
+	}, config.Healthcheck)
+	assert.NoError(t, ValidateHealthCheck(config.Healthcheck))
+
+	HealthCheckCmd([]string{"redis-cli", "ping"})(config)
+	assert.Equal(t, []string{"CMD", "redis-cli", "ping"}, config.Healthcheck.Test)
+	assert.Zero(t, config.Healthcheck.Interval)
+
+	HealthCheckDisable()(config)
+	assert.Equal(t, []string{"NONE"}, config.Healthcheck.Test)
+	assert.NoError(t, ValidateHealthCheck(config.Healthcheck))
+}
+
+func TestValidateHealthCheck(t *testing.T) {
+	for name, hc := range map[string]*container.HealthConfig{
+		"negative interval": {Test: []string{"CMD", "true"}, Interval: -time.Second},
+		"tiny timeout":      {Test: []string{"CMD", "true"}, Timeout: time.Microsecond},
+		"negative retries":  {Test: []string{"CMD", "true"}, Retries: -1},
+		"no command":        {Test: []string{"CMD-SHELL"}},
+	} {
+		assert.True(t, errdefs.IsInvalidConfig(ValidateHealthCheck(hc)), name)
+	}
+	assert.NoError(t, ValidateHealthCheck(nil))
+}
@@ -0,0 +1,81 @@
+package godock
+
+import (
+	"context"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// Conditions ContainerWaitFor can wait for.
+const (
+	// WaitConditionNotRunning returns as soon as the container is not running, immediately for a created or exited container.
+	WaitConditionNotRunning = containerType.WaitConditionNotRunning
+	// WaitConditionNextExit returns when the container next exits, even if it is not running yet.
+	WaitConditionNextExit = containerType.WaitConditionNextExit
+	// WaitConditionRemoved returns when the container is removed, e.g. by AutoRemove after it exits.
+	WaitConditionRemoved = containerType.WaitConditionRemoved
+)
+
+/*
+ContainerWaitFor waits for a container to reach the given condition and returns a channel for the
+exit status and a channel for errors, like ContainerWait.
+
+To await an auto-remove container, start waiting for WaitConditionRemoved before starting the container;
+otherwise the container may already be gone when the wait begins.
+
+Usage example:
+
+	statusCh, errCh := client.ContainerWaitFor(ctx, ctr, godock.WaitConditionRemoved)
+	if err := client.ContainerStart(ctx, ctr); err != nil {
+		return err
+	}
+	select {
+	case status := <-statusCh:
+		fmt.Println("exit code", status.StatusCode)
+	case err := <-errCh:
+		return err
+	}
+*/
+func (c *Client) ContainerWaitFor(ctx context.Context, containerConfig *container.ContainerConfig, condition containerType.WaitCondition) (<-chan containerType.WaitResponse, <-chan error) {
+	if containerConfig == nil || containerConfig.Id == "" {
+		errCh := make(chan error, 1)
+		errCh <- &errdefs.ValidationError{Field: "containerConfig", Message: "container config or id cannot be empty"}
+		return make(chan containerType.WaitResponse), errCh
+	}
+	return c.wrapped.ContainerWait(ctx, containerConfig.Id, condition)
+}
+
+/*
+WaitRemoved blocks until the container is removed and returns its exit code. When the container
+was already removed before the wait began, the exit code is unknown and -1 is returned.
+
+Usage example:
+
+	if err := client.ContainerStart(ctx, job); err != nil {
+		return err
+	}
+	code, err := client.WaitRemoved(ctx, job)
+*/
+func (c *Client) WaitRemoved(ctx context.Context, containerConfig *container.ContainerConfig) (int64, error) {
+	statusCh, errCh := c.ContainerWaitFor(ctx, containerConfig, WaitConditionRemoved)
+	select {
+	case status := <-statusCh:
+		if status.Error != nil && status.Error.Message != "" {
+			return status.StatusCode, &errdefs.ContainerError{ID: containerConfig.Id, Op: "wait", Message: status.Error.Message}
+		}
+		return status.StatusCode, nil
+	case err := <-errCh:
+		if client.IsErrNotFound(err) {
+			return -1, nil
+		}
+		if errdefs.IsInvalidConfig(err) {
+			return 0, err
+		}
+		return 0, &errdefs.ContainerError{ID: containerConfig.Id, Op: "wait", Message: err.Error(), Err: err}
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
@@ -0,0 +1,55 @@
+package godock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerWaitForValidation(t *testing.T) {
+	c := &Client{}
+	_, errCh := c.ContainerWaitFor(context.Background(), container.NewConfig("unstarted"), WaitConditionNextExit)
+	assert.True(t, errdefs.IsInvalidConfig(<-errCh))
+
+	_, err := c.WaitRemoved(context.Background(), nil)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
+
+func TestWaitRemoved(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	client := setupTestClient(t)
+	busybox := image.NewConfig(DefaultDoctorProbeImage)
+	require.NoError(t, client.EnsureImage(ctx, busybox, PullPolicyIfNotPresent))
+
+	ctr := container.NewConfig("godock-wait-" + GenerateRandomString(8))
+	ctr.SetContainerOptions(
+		containeroptions.Image(busybox),
+		containeroptions.CMD("sh", "-c", "sleep 1; exit 3"),
+	)
+	ctr.SetHostOptions(hostoptions.AutoRemove())
+	require.NoError(t, client.ContainerCreate(ctx, ctr))
+
+	statusCh, errCh := client.ContainerWaitFor(ctx, ctr, WaitConditionRemoved)
+	require.NoError(t, client.ContainerStart(ctx, ctr))
+	select {
+	case status := <-statusCh:
+		assert.Equal(t, int64(3), status.StatusCode)
+	case err := <-errCh:
+		t.Fatal(err)
+	}
+
+	code, err := client.WaitRemoved(ctx, ctr)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), code)
+}
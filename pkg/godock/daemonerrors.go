@@ -0,0 +1,88 @@
+package godock
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// The helpers below map an error of the daemon onto the errdefs type of the resource an operation acted on. A
+// missing resource becomes a ResourceNotFoundError and any other failure an operation error, both keeping the
+// error of the daemon so errors.Is and the errdefs helpers can classify it.
+
+func containerOpError(containerConfig *container.ContainerConfig, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if client.IsErrNotFound(err) {
+		return &errdefs.ResourceNotFoundError{ResourceType: "container", ID: containerConfig.Id, Err: err}
+	}
+	name := containerConfig.Name
+	if name == "" {
+		name = containerConfig.Id
+	}
+	return &errdefs.ContainerError{ID: name, Op: op, Message: err.Error(), Err: err}
+}
+
+func networkOpError(networkID, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if client.IsErrNotFound(err) {
+		return &errdefs.ResourceNotFoundError{ResourceType: "network", ID: networkID, Err: err}
+	}
+	return &errdefs.NetworkError{ID: networkID, Op: op, Message: err.Error(), Err: err}
+}
+
+func volumeOpError(name, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if client.IsErrNotFound(err) {
+		return &errdefs.ResourceNotFoundError{ResourceType: "volume", ID: name, Err: err}
+	}
+	return &errdefs.VolumeError{Name: name, Op: op, Message: err.Error(), Err: err}
+}
+
+func imageOpError(ref, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if client.IsErrNotFound(err) {
+		return &errdefs.ResourceNotFoundError{ResourceType: "image", ID: ref, Err: err}
+	}
+	return newImageError(ref, op, err)
+}
+
+// allocatedPort matches the host port in the error of the daemon for a port that is already allocated, e.g.
+// "Bind for 0.0.0.0:8080 failed: port is already allocated".
+var allocatedPort = regexp.MustCompile(`Bind for \S*:(\d+) failed`)
+
+// portConflictHint names the host port a container could not publish and the running container publishing it,
+// when they can be found.
+func (c *Client) portConflictHint(ctx context.Context, err error) string {
+	match := allocatedPort.FindStringSubmatch(err.Error())
+	if match == nil {
+		return ""
+	}
+	port, _ := strconv.ParseUint(match[1], 10, 16)
+	containers, listErr := c.wrapped.ContainerList(ctx, containerType.ListOptions{})
+	if listErr == nil {
+		for _, summary := range containers {
+			for _, published := range summary.Ports {
+				if uint64(published.PublicPort) == port && len(summary.Names) > 0 {
+					return fmt.Sprintf("host port %d is already allocated, choose another host port or stop container %s",
+						port, strings.TrimPrefix(summary.Names[0], "/"))
+				}
+			}
+		}
+	}
+	return fmt.Sprintf("host port %d is already allocated, choose another host port or stop the container publishing it", port)
+}
@@ -0,0 +1,327 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/network"
+	"github.com/aptd3v/godock/pkg/godock/networkoptions/endpointoptions"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/aptd3v/godock/pkg/godock/stopoptions"
+	"github.com/docker/docker/api/types"
+	dockerNetwork "github.com/docker/docker/api/types/network"
+)
+
+const (
+	// LabelService is the label set on every container of a service, with the service name.
+	LabelService = "godock.service"
+	// LabelColor is the label holding the slot of a service container, Blue or Green.
+	LabelColor = "godock.service.color"
+
+	// Blue and Green are the two slots a service alternates between, so the live and the new revision
+	// of a service can run side by side.
+	Blue  = "blue"
+	Green = "green"
+
+	// DefaultHealthTimeout is how long a new revision may take to become healthy by default.
+	DefaultHealthTimeout = time.Minute
+)
+
+// canaryInterval is how often a canary revision is inspected.
+const canaryInterval = time.Second
+
+// ErrRolledBack is wrapped by the error returned when a deployment failed and the live revision kept serving.
+var ErrRolledBack = errors.New("deployment rolled back")
+
+/*
+Service is a named service on a single docker host. Its revisions are containers labeled with the service
+name, created from Template, and traffic reaches them through the network alias Name on Network, so other
+containers on the network address the service by its name. Deploy switches the alias from the live revision
+to a new one.
+
+Usage example:
+
+	template := container.NewConfig("api")
+	template.SetContainerOptions(containeroptions.HealthCheckShell("wget -q -O- localhost:8080/healthz"))
+	api := deploy.NewService("api", "backend", template)
+	api.Canary = 30 * time.Second
+	rev, err := deploy.Deploy(ctx, client, api, image.NewConfig("registry.example.com/api:1.4.2"))
+*/
+type Service struct {
+	Name    string
+	Network string
+	// Template is the config every revision is created from. Its name, image and network settings are
+	// replaced per revision.
+	Template *container.ContainerConfig
+	// HealthTimeout limits how long a new revision may take to become healthy. Zero uses DefaultHealthTimeout.
+	HealthTimeout time.Duration
+	// Canary keeps the live revision serving next to a new one for this long, so the new revision receives
+	// part of the traffic and is rolled back if it stops or becomes unhealthy in that time.
+	Canary time.Duration
+	// StopOptionFns configure how the previous revision is stopped once the new one serves.
+	StopOptionFns []stopoptions.SetStopOptFn
+}
+
+// NewService creates a service served as name on the given user-defined network.
+func NewService(name, network string, template *container.ContainerConfig) *Service {
+	return &Service{
+		Name:          name,
+		Network:       network,
+		Template:      template,
+		HealthTimeout: DefaultHealthTimeout,
+	}
+}
+
+// String returns the name of the service.
+func (s *Service) String() string {
+	return s.Name
+}
+
+// Revision is a container of a service.
+type Revision struct {
+	Container *container.ContainerConfig
+	Color     string
+	Image     string
+}
+
+// validate checks that the service can run two revisions side by side.
+func (s *Service) validate() error {
+	if s.Name == "" {
+		return &errdefs.ValidationError{Field: "Name", Message: "service name cannot be empty"}
+	}
+	if s.Network == "" {
+		return &errdefs.ValidationError{Field: "Network", Message: "service network cannot be empty, traffic is switched through a network alias"}
+	}
+	if s.Template == nil || s.Template.Options == nil {
+		return &errdefs.ValidationError{Field: "Template", Message: "service template cannot be empty"}
+	}
+	if s.Template.HostOptions != nil {
+		for port, bindings := range s.Template.HostOptions.PortBindings {
+			for _, binding := range bindings {
+				if binding.HostPort != "" {
+					return &errdefs.ValidationError{
+						Field:   "Template",
+						Message: fmt.Sprintf("port %s is published on fixed host port %s, which two revisions cannot share; publish it from a proxy on the network instead", port, binding.HostPort),
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+/*
+Current returns the live revision of the service, the one serving its network alias, or nil when the service
+has not been deployed.
+*/
+func Current(ctx context.Context, client *godock.Client, service *Service) (*Revision, error) {
+	if err := service.validate(); err != nil {
+		return nil, err
+	}
+	revisions, err := revisions(ctx, client, service)
+	if err != nil {
+		return nil, err
+	}
+	for _, rev := range revisions {
+		inspect, err := client.ContainerInspect(ctx, rev.Container)
+		if err != nil {
+			return nil, err
+		}
+		if servesAlias(inspect, service) {
+			return rev, nil
+		}
+	}
+	return nil, nil
+}
+
+/*
+Deploy rolls a service out to newImage with a blue/green cutover. The new revision is created in the free slot,
+started and must become healthy before it is added to the service's network alias. With a canary period both
+revisions then serve until it ends. Finally the previous revision is removed from the alias, stopped and
+removed.
+
+If the new revision fails to start, become healthy or survive the canary period, it is removed and the live
+revision keeps serving; the returned error wraps ErrRolledBack.
+
+Usage example:
+
+	rev, err := deploy.Deploy(ctx, client, api, image.NewConfig("registry.example.com/api:1.4.2"))
+	if errors.Is(err, deploy.ErrRolledBack) {
+		log.Printf("api stays on the previous revision: %v", err)
+	}
+*/
+func Deploy(ctx context.Context, client *godock.Client, service *Service, newImage fmt.Stringer) (*Revision, error) {
+	if err := service.validate(); err != nil {
+		return nil, err
+	}
+	if newImage == nil || newImage.String() == "" {
+		return nil, &errdefs.ValidationError{Field: "newImage", Message: "image cannot be empty"}
+	}
+	if err := client.EnsureImage(ctx, image.NewConfig(newImage.String()), godock.PullPolicyIfNotPresent); err != nil {
+		return nil, err
+	}
+
+	live, err := Current(ctx, client, service)
+	if err != nil {
+		return nil, err
+	}
+	color := Blue
+	if live != nil && live.Color == Blue {
+		color = Green
+	}
+	// A revision left in the free slot by an interrupted deployment is replaced.
+	stale, err := revisions(ctx, client, service)
+	if err != nil {
+		return nil, err
+	}
+	for _, rev := range stale {
+		if rev.Color == color {
+			if err := client.ContainerRemove(ctx, rev.Container, removeoptions.Force()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	next := newRevision(service, color, newImage.String())
+	rollback := func(err error) error {
+		cleanupErr := client.ContainerRemove(context.WithoutCancel(ctx), next.Container, removeoptions.Force())
+		if cleanupErr != nil && next.Container.Id != "" {
+			return fmt.Errorf("%w: %w", ErrRolledBack, errors.Join(err, cleanupErr))
+		}
+		return fmt.Errorf("%w: %w", ErrRolledBack, err)
+	}
+	if err := client.ContainerCreate(ctx, next.Container); err != nil {
+		return nil, rollback(err)
+	}
+	if err := client.ContainerStart(ctx, next.Container); err != nil {
+		return nil, rollback(err)
+	}
+	timeout := service.HealthTimeout
+	if timeout <= 0 {
+		timeout = DefaultHealthTimeout
+	}
+	if err := client.WaitHealthy(ctx, next.Container, timeout); err != nil {
+		return nil, rollback(err)
+	}
+
+	// Reconnect with the service alias, which cannot be added to a connected endpoint.
+	if err := connect(ctx, client, service, next.Container, true); err != nil {
+		return nil, rollback(err)
+	}
+	if live != nil && service.Canary > 0 {
+		if err := watchCanary(ctx, client, next.Container, service.Canary); err != nil {
+			return nil, rollback(err)
+		}
+	}
+
+	if live != nil {
+		cleanupCtx := context.WithoutCancel(ctx)
+		if err := connect(cleanupCtx, client, service, live.Container, false); err != nil {
+			return next, err
+		}
+		if err := client.ContainerStop(cleanupCtx, live.Container, service.StopOptionFns...); err != nil {
+			return next, err
+		}
+		if err := client.ContainerRemove(cleanupCtx, live.Container, removeoptions.Force()); err != nil {
+			return next, err
+		}
+	}
+	return next, nil
+}
+
+// newRevision creates the config of a revision from the service template.
+func newRevision(service *Service, color, ref string) *Revision {
+	cfg := service.Template.Clone()
+	cfg.Id = ""
+	cfg.Name = service.Name + "-" + color
+	cfg.Options.Image = ref
+	cfg.SetContainerOptions(
+		containeroptions.Label(LabelService, service.Name),
+		containeroptions.Label(LabelColor, color),
+	)
+	cfg.SetHostOptions(hostoptions.NetworkMode(service.Network))
+	// The revision joins the network without the service alias until it is healthy.
+	cfg.NetworkingOptions = &dockerNetwork.NetworkingConfig{
+		EndpointsConfig: map[string]*dockerNetwork.EndpointSettings{
+			service.Network: {},
+		},
+	}
+	return &Revision{Container: cfg, Color: color, Image: ref}
+}
+
+// revisions lists the containers of the service.
+func revisions(ctx context.Context, client *godock.Client, service *Service) ([]*Revision, error) {
+	containers, err := client.ContainerList(ctx,
+		godock.WithContainerAll(true),
+		godock.WithContainerFilters(godock.FilterByLabel(LabelService, service.Name)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	revisions := make([]*Revision, 0, len(containers))
+	for _, summary := range containers {
+		cfg := container.NewConfig(service.Name + "-" + summary.Labels[LabelColor])
+		cfg.Id = summary.ID
+		revisions = append(revisions, &Revision{Container: cfg, Color: summary.Labels[LabelColor], Image: summary.Image})
+	}
+	return revisions, nil
+}
+
+// servesAlias reports whether a running container has the service alias on the service network.
+func servesAlias(inspect types.ContainerJSON, service *Service) bool {
+	if inspect.State == nil || !inspect.State.Running || inspect.NetworkSettings == nil {
+		return false
+	}
+	endpoint := inspect.NetworkSettings.Networks[service.Network]
+	if endpoint == nil {
+		return false
+	}
+	for _, alias := range endpoint.Aliases {
+		if alias == service.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// connect reconnects a container to the service network, with or without the service alias.
+func connect(ctx context.Context, client *godock.Client, service *Service, cfg *container.ContainerConfig, withAlias bool) error {
+	serviceNetwork := &network.NetworkConfig{Id: service.Network}
+	if err := client.NetworkDisconnect(ctx, serviceNetwork, cfg, true); err != nil {
+		return err
+	}
+	endpoint := endpointoptions.NewConfig()
+	if withAlias {
+		endpoint.SetEndpointSetting(endpointoptions.Aliases(service.Name))
+	}
+	return client.NetworkConnect(ctx, serviceNetwork, cfg, endpoint)
+}
+
+// watchCanary fails when the container stops or becomes unhealthy within the canary period.
+func watchCanary(ctx context.Context, client *godock.Client, cfg *container.ContainerConfig, period time.Duration) error {
+	deadline := client.Clock().Now().Add(period)
+	for client.Clock().Now().Before(deadline) {
+		if err := client.Clock().Sleep(ctx, canaryInterval); err != nil {
+			return err
+		}
+		inspect, err := client.ContainerInspect(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		if inspect.State == nil || !inspect.State.Running {
+			return &errdefs.ContainerError{ID: cfg.Name, Op: "canary", Message: "container stopped during the canary period"}
+		}
+		if inspect.State.Health != nil && inspect.State.Health.Status == types.Unhealthy {
+			return &errdefs.ContainerError{ID: cfg.Name, Op: "canary", Message: "container became unhealthy during the canary period"}
+		}
+	}
+	return nil
+}
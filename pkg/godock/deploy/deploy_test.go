@@ -0,0 +1,126 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/network"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/docker/docker/api/types"
+	containerType "github.com/docker/docker/api/types/container"
+	dockerNetwork "github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	template := container.NewConfig("api")
+	assert.NoError(t, NewService("api", "backend", template).validate())
+
+	assert.True(t, errdefs.IsInvalidConfig(NewService("", "backend", template).validate()))
+	assert.True(t, errdefs.IsInvalidConfig(NewService("api", "", template).validate()))
+	assert.True(t, errdefs.IsInvalidConfig(NewService("api", "backend", nil).validate()))
+
+	published := container.NewConfig("api")
+	published.SetHostOptions(hostoptions.PortBindings("0.0.0.0", "8080", "8080"))
+	err := NewService("api", "backend", published).validate()
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	assert.ErrorContains(t, err, "fixed host port 8080")
+}
+
+func TestNewRevision(t *testing.T) {
+	template := container.NewConfig("api")
+	template.SetContainerOptions(containeroptions.Env("PORT", "8080"))
+	service := NewService("api", "backend", template)
+
+	rev := newRevision(service, Green, "api:2")
+	assert.Equal(t, "api-green", rev.Container.Name)
+	assert.Equal(t, "api:2", rev.Container.Options.Image)
+	assert.Equal(t, "api", rev.Container.Options.Labels[LabelService])
+	assert.Equal(t, Green, rev.Container.Options.Labels[LabelColor])
+	assert.Equal(t, containerType.NetworkMode("backend"), rev.Container.HostOptions.NetworkMode)
+	require.Contains(t, rev.Container.NetworkingOptions.EndpointsConfig, "backend")
+	assert.Empty(t, rev.Container.NetworkingOptions.EndpointsConfig["backend"].Aliases)
+
+	// The template is left unchanged for the next revision.
+	assert.Empty(t, template.Options.Image)
+	assert.Empty(t, template.Options.Labels)
+}
+
+func TestServesAlias(t *testing.T) {
+	service := NewService("api", "backend", container.NewConfig("api"))
+	inspect := types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{State: &types.ContainerState{Running: true}},
+		NetworkSettings: &types.NetworkSettings{
+			Networks: map[string]*dockerNetwork.EndpointSettings{
+				"backend": {Aliases: []string{"api-blue", "api"}},
+			},
+		},
+	}
+	assert.True(t, servesAlias(inspect, service))
+
+	inspect.NetworkSettings.Networks["backend"].Aliases = []string{"api-blue"}
+	assert.False(t, servesAlias(inspect, service))
+
+	inspect.NetworkSettings.Networks["backend"].Aliases = []string{"api"}
+	inspect.State.Running = false
+	assert.False(t, servesAlias(inspect, service))
+}
+
+func TestDeploy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	client, err := godock.NewClient(ctx)
+	if err != nil {
+		t.Skipf("Docker daemon is not running: %v", err)
+	}
+
+	net := network.NewConfig("godock-deploy-" + godock.GenerateRandomString(8))
+	require.NoError(t, client.NetworkCreate(ctx, net))
+	defer client.NetworkRemove(context.WithoutCancel(ctx), net.Id)
+
+	template := container.NewConfig("api")
+	template.SetContainerOptions(containeroptions.CMD("sleep", "300"))
+	service := NewService("godock-deploy-"+godock.GenerateRandomString(8), net.Name, template)
+	defer func() {
+		revisions, _ := revisions(context.WithoutCancel(ctx), client, service)
+		for _, rev := range revisions {
+			client.ContainerRemove(context.WithoutCancel(ctx), rev.Container, removeoptions.Force())
+		}
+	}()
+
+	img := image.NewConfig(godock.DefaultDoctorProbeImage)
+	first, err := Deploy(ctx, client, service, img)
+	require.NoError(t, err)
+	assert.Equal(t, Blue, first.Color)
+
+	second, err := Deploy(ctx, client, service, img)
+	require.NoError(t, err)
+	assert.Equal(t, Green, second.Color)
+	live, err := Current(ctx, client, service)
+	require.NoError(t, err)
+	require.NotNil(t, live)
+	assert.Equal(t, second.Container.Id, live.Container.Id)
+	_, err = client.ContainerInspect(ctx, first.Container)
+	assert.Error(t, err)
+
+	// A revision that exits during the canary period is rolled back and the live revision keeps serving.
+	template.SetContainerOptions(containeroptions.CMD("sh", "-c", "sleep 1; exit 1"))
+	service.Canary = 3 * time.Second
+	_, err = Deploy(ctx, client, service, img)
+	assert.True(t, errors.Is(err, ErrRolledBack))
+	live, err = Current(ctx, client, service)
+	require.NoError(t, err)
+	require.NotNil(t, live)
+	assert.Equal(t, second.Container.Id, live.Container.Id)
+}
@@ -0,0 +1,304 @@
+package devloop
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/filesync"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/imageoptions"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// DefaultDebounce is how long a loop waits for changes to settle before rebuilding by default.
+const DefaultDebounce = 500 * time.Millisecond
+
+/*
+Loop rebuilds an image from a source directory and replaces a container running it whenever the sources change,
+for local development of containerized applications. The build output and the logs of the container are written
+to one output, every line prefixed with "[build]" or the container name. A failed build leaves the running
+container in place until the next change.
+
+Usage example:
+
+	app := container.NewConfig("api")
+	app.SetHostOptions(hostoptions.PortBindings("0.0.0.0", "8080", "8080"))
+	err := devloop.Run(ctx, client, "./api", app,
+		devloop.WithIgnore("*.md", "tmp"),
+		devloop.WithBuildOptions(imageoptions.SetTarget("dev")),
+	)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+*/
+type Loop struct {
+	client    *godock.Client
+	srcDir    string
+	container *container.ContainerConfig
+	tag       string
+	buildOpts []imageoptions.SetBuildOptFn
+	debounce  time.Duration
+	ignore    []string
+	out       io.Writer
+
+	// Daemon operations, replaced in tests.
+	build  func(ctx context.Context, img *image.ImageConfig, out io.Writer) error
+	start  func(ctx context.Context, cfg *container.ContainerConfig) error
+	remove func(ctx context.Context, cfg *container.ContainerConfig) error
+	logs   func(ctx context.Context, cfg *container.ContainerConfig, out io.Writer) error
+}
+
+// SetLoopOptFn is a function type that configures a Loop.
+type SetLoopOptFn func(l *Loop)
+
+// New creates a Loop building srcDir, which must contain a Dockerfile, and running containerConfig from the
+// built image. The image is tagged "<container name>:dev" unless WithImageTag is given. ".git" directories are
+// ignored by default.
+func New(client *godock.Client, srcDir string, containerConfig *container.ContainerConfig, setOptFns ...SetLoopOptFn) *Loop {
+	l := &Loop{
+		client:    client,
+		srcDir:    srcDir,
+		container: containerConfig,
+		debounce:  DefaultDebounce,
+		ignore:    []string{".git"},
+		out:       os.Stdout,
+	}
+	if containerConfig != nil {
+		l.tag = containerConfig.Name + ":dev"
+	}
+	l.build = func(ctx context.Context, img *image.ImageConfig, out io.Writer) error {
+		rc, err := l.client.ImageBuild(ctx, img)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		return jsonmessage.DisplayJSONMessagesStream(rc, out, 0, false, nil)
+	}
+	l.start = func(ctx context.Context, cfg *container.ContainerConfig) error {
+		if err := l.client.ContainerCreate(ctx, cfg); err != nil {
+			return err
+		}
+		return l.client.ContainerStart(ctx, cfg)
+	}
+	l.remove = func(ctx context.Context, cfg *container.ContainerConfig) error {
+		return l.client.ContainerRemove(ctx, cfg, removeoptions.Force())
+	}
+	l.logs = func(ctx context.Context, cfg *container.ContainerConfig, out io.Writer) error {
+		rc, err := l.client.ContainerLogs(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		_, err = godock.NewLogCopier(out, out).Copy(rc)
+		return err
+	}
+	for _, set := range setOptFns {
+		if set != nil {
+			set(l)
+		}
+	}
+	return l
+}
+
+/*
+Run creates a Loop with New and runs it until ctx is done.
+
+Usage example:
+
+	err := devloop.Run(ctx, client, "./api", app)
+*/
+func Run(ctx context.Context, client *godock.Client, srcDir string, containerConfig *container.ContainerConfig, setOptFns ...SetLoopOptFn) error {
+	return New(client, srcDir, containerConfig, setOptFns...).Run(ctx)
+}
+
+// WithImageTag sets the tag of the built image.
+func WithImageTag(tag string) SetLoopOptFn {
+	return func(l *Loop) {
+		if tag != "" {
+			l.tag = tag
+		}
+	}
+}
+
+// WithBuildOptions configures every build with option functions from the imageoptions package, e.g. a target stage.
+func WithBuildOptions(setOptFns ...imageoptions.SetBuildOptFn) SetLoopOptFn {
+	return func(l *Loop) {
+		l.buildOpts = append(l.buildOpts, setOptFns...)
+	}
+}
+
+// WithDebounce sets how long the loop waits after a change for more changes before rebuilding. The default is
+// DefaultDebounce.
+func WithDebounce(d time.Duration) SetLoopOptFn {
+	return func(l *Loop) {
+		if d > 0 {
+			l.debounce = d
+		}
+	}
+}
+
+// WithIgnore skips changes to the files and directories matching any of the patterns, see filesync.WithIgnore.
+func WithIgnore(patterns ...string) SetLoopOptFn {
+	return func(l *Loop) {
+		l.ignore = append(l.ignore, patterns...)
+	}
+}
+
+// WithOutput sets where the build output and the container logs are written. The default is os.Stdout.
+func WithOutput(w io.Writer) SetLoopOptFn {
+	return func(l *Loop) {
+		if w != nil {
+			l.out = w
+		}
+	}
+}
+
+/*
+Run builds the image and starts the container, then rebuilds and replaces it on every batch of source changes
+until ctx is done. The container is removed when Run returns, which is with the context error, or earlier when
+the sources cannot be watched.
+*/
+func (l *Loop) Run(ctx context.Context) error {
+	if l.container == nil || l.container.Name == "" || l.container.Options == nil {
+		return &errdefs.ValidationError{Field: "containerConfig", Message: "container config, name or options cannot be empty"}
+	}
+	watcher, err := filesync.NewWatcher(l.srcDir, l.debounce, l.ignore...)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	out := &lockedWriter{w: l.out}
+	buildOut := &prefixLineWriter{w: out, prefix: "[build] "}
+	r := &runner{loop: l, out: out, buildOut: buildOut}
+	defer r.stop(context.WithoutCancel(ctx))
+
+	r.rebuild(ctx, nil)
+	return watcher.Run(ctx, func(paths []string) {
+		r.rebuild(ctx, paths)
+	})
+}
+
+// runner holds the container of a running loop.
+type runner struct {
+	loop     *Loop
+	out      io.Writer
+	buildOut *prefixLineWriter
+	// current is the running container, nil before the first successful build.
+	current *container.ContainerConfig
+	// stopLogs stops streaming the logs of the current container and waits for the stream to end.
+	stopLogs func()
+}
+
+// rebuild builds the image and replaces the current container with one running it. Failures are written to the
+// output, the loop goes on with the next change.
+func (r *runner) rebuild(ctx context.Context, changed []string) {
+	l := r.loop
+	switch len(changed) {
+	case 0:
+	case 1:
+		fmt.Fprintf(r.buildOut, "%s changed, rebuilding\n", changed[0])
+	default:
+		fmt.Fprintf(r.buildOut, "%d paths changed, rebuilding\n", len(changed))
+	}
+	img, err := image.NewImageFromSrc(l.srcDir)
+	if err == nil {
+		img.SetBuildOptions(l.buildOpts...)
+		img.SetBuildOptions(imageoptions.AddTag(l.tag))
+		err = l.build(ctx, img, r.buildOut)
+	}
+	r.buildOut.Flush()
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Fprintf(r.buildOut, "build failed: %v\n", err)
+		}
+		return
+	}
+
+	r.stop(ctx)
+	cfg := l.container.Clone()
+	cfg.Id = ""
+	cfg.Options.Image = l.tag
+	if err := l.start(ctx, cfg); err != nil {
+		if ctx.Err() == nil {
+			fmt.Fprintf(r.buildOut, "starting %s failed: %v\n", cfg.Name, err)
+		}
+		if cfg.Id != "" {
+			l.remove(context.WithoutCancel(ctx), cfg)
+		}
+		return
+	}
+	r.current = cfg
+
+	logCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	appOut := &prefixLineWriter{w: r.out, prefix: "[" + cfg.Name + "] "}
+	go func() {
+		defer close(done)
+		l.logs(logCtx, cfg, appOut)
+		appOut.Flush()
+	}()
+	r.stopLogs = func() {
+		cancel()
+		<-done
+	}
+}
+
+// stop removes the current container.
+func (r *runner) stop(ctx context.Context) {
+	if r.current == nil {
+		return
+	}
+	r.loop.remove(ctx, r.current)
+	r.stopLogs()
+	r.current, r.stopLogs = nil, nil
+}
+
+// lockedWriter serializes the writes of the build output and the container logs.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// prefixLineWriter writes every complete line with a prefix, so lines of different sources are not interleaved.
+type prefixLineWriter struct {
+	w       io.Writer
+	prefix  string
+	partial []byte
+}
+
+func (w *prefixLineWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		if _, err := w.w.Write(append([]byte(w.prefix), w.partial[:i+1]...)); err != nil {
+			return 0, err
+		}
+		w.partial = w.partial[i+1:]
+	}
+}
+
+// Flush writes an unterminated last line.
+func (w *prefixLineWriter) Flush() {
+	if len(w.partial) > 0 {
+		w.Write([]byte{'\n'})
+	}
+}
@@ -0,0 +1,136 @@
+package devloop
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestPrefixLineWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := &prefixLineWriter{w: &out, prefix: "[api] "}
+	io.WriteString(w, "listening on :8080\nGET /health")
+	assert.Equal(t, "[api] listening on :8080\n", out.String())
+	io.WriteString(w, " 200\n")
+	io.WriteString(w, "shutting down")
+	w.Flush()
+	assert.Equal(t, "[api] listening on :8080\n[api] GET /health 200\n[api] shutting down\n", out.String())
+}
+
+func TestRunValidation(t *testing.T) {
+	err := Run(context.Background(), nil, t.TempDir(), nil)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	err = Run(context.Background(), nil, filepath.Join(t.TempDir(), "missing"), container.NewConfig("api"))
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
+
+func TestLoop(t *testing.T) {
+	srcDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "Dockerfile"), []byte("FROM scratch"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "main.go"), []byte("package main"), 0o644))
+
+	var out syncBuffer
+	l := New(nil, srcDir, container.NewConfig("api"), WithDebounce(20*time.Millisecond), WithOutput(&out))
+	var (
+		mu      sync.Mutex
+		builds  int
+		events  []string
+		started = make(chan *container.ContainerConfig, 10)
+	)
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+	l.build = func(ctx context.Context, img *image.ImageConfig, out io.Writer) error {
+		assert.Equal(t, []string{"api:dev"}, img.BuildOptions.Tags)
+		mu.Lock()
+		builds++
+		build := builds
+		mu.Unlock()
+		io.WriteString(out, "Step 1/1 : FROM scratch\n")
+		if build == 2 {
+			return errors.New("syntax error")
+		}
+		return nil
+	}
+	l.start = func(ctx context.Context, cfg *container.ContainerConfig) error {
+		assert.Equal(t, "api:dev", cfg.Options.Image)
+		mu.Lock()
+		cfg.Id = fmt.Sprintf("c%d", builds)
+		mu.Unlock()
+		record("start " + cfg.Id)
+		started <- cfg
+		return nil
+	}
+	l.remove = func(ctx context.Context, cfg *container.ContainerConfig) error {
+		record("remove " + cfg.Id)
+		return nil
+	}
+	l.logs = func(ctx context.Context, cfg *container.ContainerConfig, out io.Writer) error {
+		fmt.Fprintf(out, "serving from %s\n", cfg.Id)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- l.Run(ctx)
+	}()
+	first := <-started
+	assert.Equal(t, "c1", first.Id)
+
+	// The second build fails and keeps the first container, the third replaces it.
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "main.go"), []byte("package main oops"), 0o644))
+	require.Eventually(t, func() bool {
+		return strings.Contains(out.String(), "[build] build failed: syntax error")
+	}, 4*time.Second, 10*time.Millisecond)
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "main.go"), []byte("package main // fixed"), 0o644))
+	select {
+	case third := <-started:
+		assert.Equal(t, "c3", third.Id)
+	case <-ctx.Done():
+		t.Fatal("the container was not replaced")
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(t, []string{"start c1", "remove c1", "start c3", "remove c3"}, events)
+	assert.Contains(t, out.String(), "[build] Step 1/1 : FROM scratch\n")
+	assert.Contains(t, out.String(), "[api] serving from c1\n")
+	assert.Contains(t, out.String(), "[build] main.go changed, rebuilding\n")
+}
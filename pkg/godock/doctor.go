@@ -0,0 +1,295 @@
+package godock
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/docker/docker/api"
+	"github.com/docker/docker/api/types/versions"
+	"github.com/docker/go-units"
+)
+
+// CheckStatus is the outcome of a single Doctor check.
+type CheckStatus string
+
+const (
+	// CheckPass is reported for checks that found no problem.
+	CheckPass CheckStatus = "pass"
+	// CheckWarn is reported for problems that degrade but do not prevent normal use.
+	CheckWarn CheckStatus = "warn"
+	// CheckFail is reported for problems that prevent normal use.
+	CheckFail CheckStatus = "fail"
+	// CheckSkip is reported for checks that could not run, for example because the daemon is unreachable.
+	CheckSkip CheckStatus = "skip"
+)
+
+// CheckResult is the result of a single Doctor check.
+type CheckResult struct {
+	Name    string
+	Status  CheckStatus
+	Message string
+}
+
+// DoctorReport holds the results of every Doctor check in the order they ran.
+type DoctorReport struct {
+	Checks []CheckResult
+}
+
+// Healthy reports whether no check failed.
+func (r *DoctorReport) Healthy() bool {
+	for _, check := range r.Checks {
+		if check.Status == CheckFail {
+			return false
+		}
+	}
+	return true
+}
+
+// Check returns the result of the named check.
+func (r *DoctorReport) Check(name string) (CheckResult, bool) {
+	for _, check := range r.Checks {
+		if check.Name == name {
+			return check, true
+		}
+	}
+	return CheckResult{}, false
+}
+
+// String formats the report with one line per check.
+func (r *DoctorReport) String() string {
+	var b strings.Builder
+	for _, check := range r.Checks {
+		fmt.Fprintf(&b, "[%s] %s: %s\n", strings.ToUpper(string(check.Status)), check.Name, check.Message)
+	}
+	return b.String()
+}
+
+func (r *DoctorReport) add(name string, status CheckStatus, format string, args ...any) {
+	r.Checks = append(r.Checks, CheckResult{
+		Name:    name,
+		Status:  status,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Names of the checks run by Doctor.
+const (
+	CheckDaemon     = "daemon"
+	CheckAPIVersion = "api-version"
+	CheckCgroup     = "cgroup"
+	CheckWarnings   = "daemon-warnings"
+	CheckIPv6       = "ipv6"
+	CheckDiskSpace  = "disk-space"
+	CheckDNS        = "dns"
+)
+
+// DefaultDoctorProbeImage is the image used for the probe container when none is configured.
+const DefaultDoctorProbeImage = "busybox:1.36"
+
+type doctorOptions struct {
+	probe        bool
+	probeImage   string
+	dnsHost      string
+	minFreeSpace int64
+}
+
+// DoctorOptionFn is a function that configures the Doctor checks.
+type DoctorOptionFn func(*doctorOptions)
+
+// WithDoctorProbeImage sets the image of the probe container used for the disk space and DNS checks.
+// The image must provide sh, df and nslookup.
+func WithDoctorProbeImage(ref string) DoctorOptionFn {
+	return func(opts *doctorOptions) {
+		opts.probeImage = ref
+	}
+}
+
+// WithDoctorDNSHost sets the host name resolved inside the probe container.
+func WithDoctorDNSHost(host string) DoctorOptionFn {
+	return func(opts *doctorOptions) {
+		opts.dnsHost = host
+	}
+}
+
+// WithDoctorMinFreeSpace sets the free space, in bytes, below which the disk space check warns.
+func WithDoctorMinFreeSpace(bytes int64) DoctorOptionFn {
+	return func(opts *doctorOptions) {
+		opts.minFreeSpace = bytes
+	}
+}
+
+// WithoutDoctorProbe skips the checks that need a probe container, for hosts that cannot pull images.
+func WithoutDoctorProbe() DoctorOptionFn {
+	return func(opts *doctorOptions) {
+		opts.probe = false
+	}
+}
+
+// Doctor runs a battery of checks against the docker host and returns a report with a
+// pass, warn, fail or skip result per check: daemon reachability, API version compatibility,
+// cgroup version, daemon warnings, IPv6 on the default bridge, and disk space and DNS
+// resolution from inside a short-lived probe container.
+// Doctor never returns an error; problems are reported as failed checks.
+func (c *Client) Doctor(ctx context.Context, doctorOptionFns ...DoctorOptionFn) *DoctorReport {
+	opts := &doctorOptions{
+		probe:        true,
+		probeImage:   DefaultDoctorProbeImage,
+		dnsHost:      "docker.com",
+		minFreeSpace: 2 * units.GiB,
+	}
+	for _, fn := range doctorOptionFns {
+		if fn != nil {
+			fn(opts)
+		}
+	}
+
+	report := &DoctorReport{}
+	ping, err := c.Ping(ctx)
+	if err != nil {
+		report.add(CheckDaemon, CheckFail, "daemon at %s is not reachable: %v", c, err)
+		for _, name := range []string{CheckAPIVersion, CheckCgroup, CheckWarnings, CheckIPv6, CheckDiskSpace, CheckDNS} {
+			report.add(name, CheckSkip, "daemon is not reachable")
+		}
+		return report
+	}
+	report.add(CheckDaemon, CheckPass, "daemon at %s is reachable (%s)", c, ping.OSType)
+
+	version, err := c.SystemVersion(ctx)
+	if err != nil {
+		report.add(CheckAPIVersion, CheckFail, "%v", err)
+	} else {
+		status, message := checkAPIVersion(c.ClientVersion(), version.MinAPIVersion, version.APIVersion)
+		report.add(CheckAPIVersion, status, "%s", message)
+	}
+
+	info, err := c.SystemInfo(ctx)
+	if err != nil {
+		report.add(CheckCgroup, CheckFail, "%v", err)
+		report.add(CheckWarnings, CheckSkip, "system info is unavailable")
+	} else {
+		switch info.CgroupVersion {
+		case "2":
+			report.add(CheckCgroup, CheckPass, "cgroup v2 with %s driver", info.CgroupDriver)
+		case "1":
+			report.add(CheckCgroup, CheckWarn, "cgroup v1 is deprecated, some resource limits are unavailable")
+		default:
+			report.add(CheckCgroup, CheckSkip, "cgroup version not reported by the daemon")
+		}
+		if len(info.Warnings) > 0 {
+			report.add(CheckWarnings, CheckWarn, "%s", strings.Join(info.Warnings, "; "))
+		} else {
+			report.add(CheckWarnings, CheckPass, "no warnings")
+		}
+	}
+
+	bridge, err := c.NetworkInspect(ctx, "bridge")
+	switch {
+	case err != nil:
+		report.add(CheckIPv6, CheckSkip, "default bridge network not found: %v", err)
+	case bridge.EnableIPv6:
+		report.add(CheckIPv6, CheckPass, "IPv6 is enabled on the default bridge")
+	default:
+		report.add(CheckIPv6, CheckWarn, "IPv6 is disabled on the default bridge, containers have no IPv6 connectivity")
+	}
+
+	if !opts.probe {
+		report.add(CheckDiskSpace, CheckSkip, "probe container disabled")
+		report.add(CheckDNS, CheckSkip, "probe container disabled")
+		return report
+	}
+	output, err := c.runDoctorProbe(ctx, opts)
+	if err != nil {
+		report.add(CheckDiskSpace, CheckFail, "probe container failed: %v", err)
+		report.add(CheckDNS, CheckFail, "probe container failed: %v", err)
+		return report
+	}
+	status, message := checkProbeDisk(output, opts.minFreeSpace)
+	report.add(CheckDiskSpace, status, "%s", message)
+	if strings.Contains(output, "dns=ok") {
+		report.add(CheckDNS, CheckPass, "resolved %s inside a container", opts.dnsHost)
+	} else {
+		report.add(CheckDNS, CheckFail, "could not resolve %s inside a container", opts.dnsHost)
+	}
+	return report
+}
+
+// runDoctorProbe runs the probe container and returns its output.
+func (c *Client) runDoctorProbe(ctx context.Context, opts *doctorOptions) (string, error) {
+	probeImage := image.NewConfig(opts.probeImage)
+	if err := c.EnsureImage(ctx, probeImage, PullPolicyIfNotPresent); err != nil {
+		return "", err
+	}
+
+	script := fmt.Sprintf("nslookup %s >/dev/null 2>&1 && echo dns=ok || echo dns=fail; df -Pk / | tail -n 1", opts.dnsHost)
+	probe := container.NewConfig("godock-doctor-" + GenerateRandomString(8))
+	probe.SetContainerOptions(
+		containeroptions.Image(probeImage),
+		containeroptions.Entrypoint("sh", "-c", script),
+	)
+
+	runErr := c.RunAndWait(ctx, probe)
+	if probe.Id == "" {
+		return "", runErr
+	}
+	defer c.ContainerRemove(context.WithoutCancel(ctx), probe, removeoptions.Force())
+	if runErr != nil {
+		return "", runErr
+	}
+
+	rc, err := c.ContainerLogs(ctx, probe)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	var out bytes.Buffer
+	if _, err := NewLogCopier(&out, &out).Copy(rc); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// checkAPIVersion compares the negotiated client API version with the range supported by the daemon.
+func checkAPIVersion(clientVersion, minVersion, maxVersion string) (CheckStatus, string) {
+	switch {
+	case minVersion != "" && versions.LessThan(clientVersion, minVersion):
+		return CheckFail, fmt.Sprintf("client API %s is older than the daemon minimum %s", clientVersion, minVersion)
+	case versions.GreaterThan(clientVersion, maxVersion):
+		return CheckFail, fmt.Sprintf("client API %s is newer than the daemon maximum %s", clientVersion, maxVersion)
+	case versions.LessThan(maxVersion, api.DefaultVersion):
+		return CheckWarn, fmt.Sprintf("daemon API %s is older than the client default %s, newer features are unavailable", maxVersion, api.DefaultVersion)
+	}
+	return CheckPass, fmt.Sprintf("client API %s, daemon supports %s to %s", clientVersion, minVersion, maxVersion)
+}
+
+// checkProbeDisk parses the df line printed by the probe container.
+func checkProbeDisk(output string, minFreeSpace int64) (CheckStatus, string) {
+	var fields []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if f := strings.Fields(line); len(f) >= 6 {
+			fields = f
+		}
+	}
+	if fields == nil {
+		return CheckSkip, "could not read free space from the probe container"
+	}
+	availableKB, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return CheckSkip, fmt.Sprintf("could not parse free space %q", fields[3])
+	}
+	available := availableKB * 1024
+	message := fmt.Sprintf("%s free (%s used) on the container filesystem", units.BytesSize(float64(available)), fields[4])
+	if used, err := strconv.Atoi(strings.TrimSuffix(fields[4], "%")); err == nil && used >= 98 {
+		return CheckFail, message
+	}
+	if available < minFreeSpace {
+		return CheckWarn, message
+	}
+	return CheckPass, message
+}
@@ -0,0 +1,56 @@
+package godock
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api"
+	"github.com/docker/go-units"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAPIVersion(t *testing.T) {
+	status, _ := checkAPIVersion("1.45", "1.24", "1.47")
+	assert.Equal(t, CheckPass, status)
+
+	status, _ = checkAPIVersion("1.12", "1.24", "1.47")
+	assert.Equal(t, CheckFail, status)
+
+	status, _ = checkAPIVersion("1.48", "1.24", "1.47")
+	assert.Equal(t, CheckFail, status)
+
+	status, msg := checkAPIVersion("1.41", "1.12", "1.41")
+	if api.DefaultVersion != "1.41" {
+		assert.Equal(t, CheckWarn, status)
+		assert.Contains(t, msg, "older than the client default")
+	}
+}
+
+func TestCheckProbeDisk(t *testing.T) {
+	output := "dns=ok\noverlay 61255492 40000000 10485760 80% /\n"
+	status, msg := checkProbeDisk(output, 2*units.GiB)
+	assert.Equal(t, CheckPass, status)
+	assert.Contains(t, msg, "10GiB free")
+
+	status, _ = checkProbeDisk(output, 20*units.GiB)
+	assert.Equal(t, CheckWarn, status)
+
+	status, _ = checkProbeDisk("overlay 61255492 60000000 1048576 99% /", 0)
+	assert.Equal(t, CheckFail, status)
+
+	status, _ = checkProbeDisk("dns=fail", 0)
+	assert.Equal(t, CheckSkip, status)
+}
+
+func TestDoctorReport(t *testing.T) {
+	report := &DoctorReport{}
+	report.add(CheckDaemon, CheckPass, "reachable")
+	report.add(CheckIPv6, CheckWarn, "disabled")
+	assert.True(t, report.Healthy())
+	assert.Equal(t, "[PASS] daemon: reachable\n[WARN] ipv6: disabled\n", report.String())
+
+	report.add(CheckDNS, CheckFail, "no dns")
+	assert.False(t, report.Healthy())
+	check, ok := report.Check(CheckDNS)
+	assert.True(t, ok)
+	assert.Equal(t, "no dns", check.Message)
+}
@@ -0,0 +1,136 @@
+package godock
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// PullPolicy controls when EnsureImage pulls an image.
+type PullPolicy string
+
+const (
+	// PullPolicyIfNotPresent pulls the image only when it is not available locally.
+	PullPolicyIfNotPresent PullPolicy = "IfNotPresent"
+	// PullPolicyAlways pulls the image every time, picking up updates to mutable tags.
+	PullPolicyAlways PullPolicy = "Always"
+	// PullPolicyNever never pulls and fails when the image is not available locally.
+	PullPolicyNever PullPolicy = "Never"
+)
+
+/*
+EnsureImage makes sure an image is available locally according to the pull policy
+and waits for any pull to complete. The image is looked up by its reference, which may be a
+tag or a digest; when the pull options request a platform, a local image for another platform
+does not count as present, and neither does one without the digest required with
+imageoptions.RequireDigest. With PullPolicyNever a missing image is reported as an
+*errdefs.ResourceNotFoundError, and one without the required digest as an *errdefs.DigestMismatchError.
+The image verifiers of the client check the image whether it was pulled or already present.
+
+Usage example:
+
+	img := image.NewConfig("nginx:1.27")
+	if err := client.EnsureImage(ctx, img, godock.PullPolicyIfNotPresent); err != nil {
+		return err
+	}
+*/
+func (c *Client) EnsureImage(ctx context.Context, imageConfig *image.ImageConfig, policy PullPolicy) error {
+	return c.ensureImage(ctx, imageConfig, policy, nil)
+}
+
+// ensureImage applies the pull policy, calling onProgress for every progress event of a pull.
+func (c *Client) ensureImage(ctx context.Context, imageConfig *image.ImageConfig, policy PullPolicy, onProgress func(ImageProgress)) error {
+	if imageConfig == nil || imageConfig.Ref == "" {
+		return &errdefs.ValidationError{
+			Field:   "imageConfig",
+			Message: "image config or reference cannot be empty",
+		}
+	}
+
+	switch policy {
+	case PullPolicyAlways:
+	case PullPolicyIfNotPresent, PullPolicyNever:
+		present, err := c.imagePresent(ctx, imageConfig)
+		if err != nil {
+			return err
+		}
+		if present {
+			// A local image that does not have the pinned digest is pulled again, and the pull checks it.
+			err := c.checkImage(ctx, imageConfig, "")
+			if err == nil || policy == PullPolicyNever || !errdefs.IsDigestMismatch(err) {
+				return err
+			}
+		} else if policy == PullPolicyNever {
+			return &errdefs.ResourceNotFoundError{
+				ResourceType: "image",
+				ID:           imageConfig.Ref,
+			}
+		}
+	default:
+		return &errdefs.ValidationError{
+			Field:   "policy",
+			Message: "unknown pull policy " + string(policy),
+		}
+	}
+
+	return c.pullImage(ctx, imageConfig, onProgress)
+}
+
+// pullImage pulls an image and waits for the pull to complete, calling onProgress for every progress event.
+func (c *Client) pullImage(ctx context.Context, imageConfig *image.ImageConfig, onProgress func(ImageProgress)) error {
+	rc, err := c.ImagePull(ctx, imageConfig)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	err = decodeJSONMessages(rc, func(msg jsonmessage.JSONMessage) error {
+		if msg.Error != nil {
+			return newImageError(imageConfig.Ref, "pull", errors.New(msg.Error.Message))
+		}
+		if onProgress != nil && msg.Status != "" {
+			onProgress(newImageProgress(msg))
+		}
+		return nil
+	})
+	if err != nil {
+		var imageErr *errdefs.ImageError
+		if errors.As(err, &imageErr) || errdefs.IsDigestMismatch(err) {
+			return err
+		}
+		return newImageError(imageConfig.Ref, "pull", err)
+	}
+	return nil
+}
+
+// imagePresent reports whether the image is available locally for the requested platform.
+func (c *Client) imagePresent(ctx context.Context, imageConfig *image.ImageConfig) (bool, error) {
+	inspect, _, err := c.wrapped.ImageInspectWithRaw(ctx, imageConfig.Ref)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return false, nil
+		}
+		return false, newImageError(imageConfig.Ref, "inspect", err)
+	}
+	if platform := imageConfig.PullOptions.Platform; platform != "" {
+		return platformMatches(platform, inspect.Os, inspect.Architecture, inspect.Variant), nil
+	}
+	return true, nil
+}
+
+// platformMatches reports whether an image built for os/arch/variant satisfies a platform such as "linux/arm64/v8".
+func platformMatches(platform, os, arch, variant string) bool {
+	parts := append(strings.Split(platform, "/"), "", "", "")
+	if parts[0] != "" && parts[0] != os {
+		return false
+	}
+	if parts[1] != "" && parts[1] != arch {
+		return false
+	}
+	return parts[2] == "" || parts[2] == variant
+}
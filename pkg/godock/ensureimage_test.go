@@ -0,0 +1,30 @@
+package godock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnsureImageValidation(t *testing.T) {
+	c := &Client{}
+	ctx := context.Background()
+
+	err := c.EnsureImage(ctx, nil, PullPolicyIfNotPresent)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+
+	err = c.EnsureImage(ctx, image.NewConfig("alpine"), PullPolicy("Sometimes"))
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
+
+func TestPlatformMatches(t *testing.T) {
+	assert.True(t, platformMatches("linux/amd64", "linux", "amd64", ""))
+	assert.True(t, platformMatches("linux/arm64", "linux", "arm64", "v8"))
+	assert.True(t, platformMatches("linux/arm64/v8", "linux", "arm64", "v8"))
+	assert.False(t, platformMatches("linux/arm/v7", "linux", "arm", "v6"))
+	assert.False(t, platformMatches("linux/amd64", "linux", "arm64", ""))
+	assert.False(t, platformMatches("windows", "linux", "amd64", ""))
+}
@@ -0,0 +1,304 @@
+package errdefs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	dockerErrdefs "github.com/docker/docker/errdefs"
+)
+
+/*
+Details is the structured context of an error: the operation that failed, the resource it acted on and how the
+daemon answered. Use DetailsOf to collect it from an error returned by the client, e.g. to log it as fields.
+
+Usage example:
+
+	if err := client.ContainerStart(ctx, web); err != nil {
+		d := errdefs.DetailsOf(err)
+		logger.Error("start failed", "op", d.Op, "resource", d.Resource, "status", d.StatusCode)
+	}
+*/
+type Details struct {
+	// Op is the operation that failed, e.g. "start". It is empty when the error does not name one.
+	Op string
+	// ResourceType is the kind of resource, e.g. "container" or "image".
+	ResourceType string
+	// Resource is the name, ID or reference of the resource.
+	Resource string
+	// StatusCode is the HTTP status code of the daemon response, or 0 when the error is not from a daemon response.
+	StatusCode int
+	// Kind is the sentinel the error is classified as, e.g. ErrConflict, or nil.
+	Kind error
+}
+
+// DetailsOf returns the details of the first errdefs error in the chain of err, with the status code and kind of
+// the whole chain.
+func DetailsOf(err error) Details {
+	d := Details{StatusCode: StatusCode(err), Kind: kindOf(err)}
+	var (
+		notFound   *ResourceNotFoundError
+		exists     *ResourceExistsError
+		containerE *ContainerError
+		networkE   *NetworkError
+		volumeE    *VolumeError
+		imageE     *ImageError
+		digestE    *DigestMismatchError
+		execE      *ExecError
+		swarmE     *SwarmError
+	)
+	switch {
+	case errors.As(err, &notFound):
+		d.ResourceType, d.Resource = notFound.ResourceType, notFound.ID
+	case errors.As(err, &exists):
+		d.ResourceType, d.Resource = exists.ResourceType, exists.ID
+	case errors.As(err, &containerE):
+		d.Op, d.ResourceType, d.Resource = containerE.Op, "container", containerE.ID
+	case errors.As(err, &networkE):
+		d.Op, d.ResourceType, d.Resource = networkE.Op, "network", networkE.ID
+	case errors.As(err, &volumeE):
+		d.Op, d.ResourceType, d.Resource = volumeE.Op, "volume", volumeE.Name
+	case errors.As(err, &imageE):
+		d.Op, d.ResourceType, d.Resource = imageE.Op, "image", imageE.Ref
+	case errors.As(err, &digestE):
+		d.Op, d.ResourceType, d.Resource = "verify digest", "image", digestE.Ref
+	case errors.As(err, &execE):
+		d.Op, d.ResourceType, d.Resource = execE.Op, "exec", execE.ID
+	case errors.As(err, &swarmE):
+		d.Op, d.ResourceType, d.Resource = swarmE.Op, "swarm", swarmE.ID
+	}
+	return d
+}
+
+// kindOf returns the sentinel err matches, checking the errdefs types before classifying the daemon error.
+func kindOf(err error) error {
+	for _, kind := range []error{
+		ErrInvalidConfig, ErrDaemonNotRunning, ErrAlreadyExists, ErrNotFound, ErrUnauthorized, ErrConflict,
+		ErrNoSpace, ErrPlatformMismatch, ErrDigestMismatch, ErrTimeout, ErrCanceled,
+	} {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+	return Classify(err)
+}
+
+// StatusCode returns the HTTP status code the daemon answered with, or 0 when err is not from a daemon response.
+// The docker client keeps the class of a response rather than its code, so every code of a class is reported as the
+// code of the class, e.g. 500 for any server error.
+func StatusCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	switch {
+	case dockerErrdefs.IsNotModified(err):
+		return http.StatusNotModified
+	case dockerErrdefs.IsInvalidParameter(err):
+		return http.StatusBadRequest
+	case dockerErrdefs.IsUnauthorized(err):
+		return http.StatusUnauthorized
+	case dockerErrdefs.IsForbidden(err):
+		return http.StatusForbidden
+	case dockerErrdefs.IsNotFound(err):
+		return http.StatusNotFound
+	case dockerErrdefs.IsConflict(err):
+		return http.StatusConflict
+	case dockerErrdefs.IsNotImplemented(err):
+		return http.StatusNotImplemented
+	case dockerErrdefs.IsUnavailable(err):
+		return http.StatusServiceUnavailable
+	case dockerErrdefs.IsSystem(err):
+		return http.StatusInternalServerError
+	}
+	return 0
+}
+
+/*
+Suggestion returns a remediation for err, e.g. "stop container web first, or remove it with force", or an empty
+string when there is none. It returns the suggestion of the first error in the chain that has one, and otherwise a
+suggestion for the kind of the error.
+
+Usage example:
+
+	if err := client.ContainerRemove(ctx, web); err != nil {
+		if hint := errdefs.Suggestion(err); hint != "" {
+			fmt.Fprintln(os.Stderr, "hint:", hint)
+		}
+		return err
+	}
+*/
+func Suggestion(err error) string {
+	var suggester interface{ Suggestion() string }
+	if errors.As(err, &suggester) {
+		if s := suggester.Suggestion(); s != "" {
+			return s
+		}
+	}
+	return suggest("", "", "", err)
+}
+
+/*
+Render formats err for the terminal: its message followed by its details and suggestion, one per indented line,
+and the captured output of a failed container.
+Errors joined with errors.Join are rendered one after the other.
+
+Usage example:
+
+	if err := run(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, errdefs.Render(err))
+		os.Exit(1)
+	}
+*/
+func Render(err error) string {
+	if err == nil {
+		return ""
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		rendered := make([]string, 0, len(joined.Unwrap()))
+		for _, e := range joined.Unwrap() {
+			if e != nil {
+				rendered = append(rendered, Render(e))
+			}
+		}
+		return strings.Join(rendered, "\n")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Error: %s", err)
+	d := DetailsOf(err)
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "\n  %-11s %s", name+":", value)
+		}
+	}
+	field("Operation", d.Op)
+	field("Resource", strings.TrimSpace(d.ResourceType+" "+d.Resource))
+	if d.StatusCode != 0 {
+		field("Status", fmt.Sprintf("%d %s", d.StatusCode, http.StatusText(d.StatusCode)))
+	}
+	field("Suggestion", Suggestion(err))
+	var containerE *ContainerError
+	if errors.As(err, &containerE) && len(containerE.Logs) > 0 {
+		b.WriteString("\n  Logs:")
+		for _, line := range containerE.Logs {
+			fmt.Fprintf(&b, "\n    %s", line)
+		}
+	}
+	return b.String()
+}
+
+// suggest returns a remediation for the kind of err, which failed an operation on a resource. Any of
+// resourceType, id and op may be empty.
+func suggest(resourceType, id, op string, err error) string {
+	resource := strings.TrimSpace(resourceType + " " + id)
+	if resource == "" {
+		resource = "the resource"
+	}
+	switch Classify(err) {
+	case ErrNotFound:
+		return fmt.Sprintf("check that %s exists and that its name or ID is spelled correctly", resource)
+	case ErrConflict:
+		switch {
+		case resourceType == "container" && op == "remove":
+			return fmt.Sprintf("stop %s first, or remove it with force", resource)
+		case resourceType == "network" && op == "remove":
+			return fmt.Sprintf("disconnect the containers from %s first", resource)
+		case resourceType == "volume" && op == "remove":
+			return fmt.Sprintf("remove the containers using %s first", resource)
+		case resourceType == "image" && op == "remove":
+			return fmt.Sprintf("remove the containers using %s first, or remove it with force", resource)
+		}
+		return fmt.Sprintf("%s is in a state that does not allow this operation; inspect it and retry", resource)
+	case ErrUnauthorized:
+		return "log in to the registry, e.g. with docker login, and check that the repository exists and you have access to it"
+	case ErrNoSpace:
+		return "free disk space on the docker host, e.g. with docker system prune"
+	case ErrPlatformMismatch:
+		return "use an image built for the platform of the docker host, or select the platform of the image explicitly"
+	}
+	return ""
+}
+
+// Suggestion returns a remediation for the missing resource.
+func (e *ResourceNotFoundError) Suggestion() string {
+	switch e.ResourceType {
+	case "image":
+		return fmt.Sprintf("pull %s first, or check the reference for typos", e.ID)
+	case "network driver", "volume driver":
+		return fmt.Sprintf("install the %s plugin, or use a built-in driver", e.ID)
+	}
+	return fmt.Sprintf("check that %s %s exists and that its name or ID is spelled correctly", e.ResourceType, e.ID)
+}
+
+// Suggestion returns the Hint, or a remediation for the existing resource.
+func (e *ResourceExistsError) Suggestion() string {
+	if e.Hint != "" {
+		return e.Hint
+	}
+	if e.ResourceType == "port" {
+		return "choose another host port, or stop the container publishing it"
+	}
+	return fmt.Sprintf("choose another name, or remove the existing %s %s first", e.ResourceType, e.ID)
+}
+
+// Suggestion returns an empty string, the message of a ConfigError already says what to fix.
+func (e *ConfigError) Suggestion() string {
+	return ""
+}
+
+// Suggestion returns an empty string, the message of a ValidationError already says what to fix.
+func (e *ValidationError) Suggestion() string {
+	return ""
+}
+
+// Suggestion returns a remediation for an unreachable daemon.
+func (e *DaemonNotRunningError) Suggestion() string {
+	return "start the docker daemon, or set DOCKER_HOST to the address of a running one"
+}
+
+// Suggestion returns a remediation for the kind of the underlying error, the suggestion of the underlying error
+// itself, or an empty string.
+func (e *ContainerError) Suggestion() string {
+	if s := suggest("container", e.ID, e.Op, e.Err); s != "" {
+		return s
+	}
+	var suggester interface{ Suggestion() string }
+	if errors.As(e.Err, &suggester) {
+		return suggester.Suggestion()
+	}
+	return ""
+}
+
+// Suggestion returns a remediation for the kind of the underlying error, or an empty string.
+func (e *NetworkError) Suggestion() string {
+	return suggest("network", e.ID, e.Op, e.Err)
+}
+
+// Suggestion returns a remediation for the kind of the underlying error, or an empty string.
+func (e *VolumeError) Suggestion() string {
+	return suggest("volume", e.Name, e.Op, e.Err)
+}
+
+// Suggestion returns a remediation for the kind of the underlying error, or an empty string.
+func (e *ImageError) Suggestion() string {
+	if e.Unauthorized {
+		return suggest("image", e.Ref, e.Op, ErrUnauthorized)
+	}
+	return suggest("image", e.Ref, e.Op, e.Err)
+}
+
+// Suggestion returns a remediation for an image that changed in the registry.
+func (e *DigestMismatchError) Suggestion() string {
+	return fmt.Sprintf("the registry serves other content for %s than the pinned digest; find out why before pinning the new digest", e.Ref)
+}
+
+// Suggestion returns a remediation for the kind of the underlying error, or an empty string.
+func (e *ExecError) Suggestion() string {
+	return suggest("exec", e.ID, e.Op, e.Err)
+}
+
+// Suggestion returns a remediation for the kind of the underlying error, or an empty string.
+func (e *SwarmError) Suggestion() string {
+	return suggest("swarm", e.ID, e.Op, e.Err)
+}
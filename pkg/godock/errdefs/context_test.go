@@ -0,0 +1,119 @@
+package errdefs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDetailsOf(t *testing.T) {
+	cause := conflictError{"You cannot remove a running container abc"}
+	err := fmt.Errorf("teardown: %w", &ContainerError{ID: "web", Op: "remove", Message: cause.Error(), Err: cause})
+
+	got := DetailsOf(err)
+	want := Details{Op: "remove", ResourceType: "container", Resource: "web", StatusCode: 409, Kind: ErrConflict}
+	if got != want {
+		t.Errorf("DetailsOf() = %+v, want %+v", got, want)
+	}
+
+	got = DetailsOf(&ResourceNotFoundError{ResourceType: "image", ID: "nginx:1.27"})
+	want = Details{ResourceType: "image", Resource: "nginx:1.27", Kind: ErrNotFound}
+	if got != want {
+		t.Errorf("DetailsOf() = %+v, want %+v", got, want)
+	}
+
+	if got := DetailsOf(errors.New("boom")); got != (Details{}) {
+		t.Errorf("DetailsOf() of a plain error = %+v, want zero details", got)
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, 0},
+		{"plain", errors.New("boom"), 0},
+		{"not found", notFoundError{"No such image"}, 404},
+		{"forbidden", forbiddenError{"forbidden"}, 403},
+		{"wrapped conflict", &VolumeError{Name: "data", Op: "remove", Err: conflictError{"volume is in use"}}, 409},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StatusCode(tt.err); got != tt.want {
+				t.Errorf("StatusCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestion(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			"remove running container",
+			&ContainerError{ID: "web", Op: "remove", Err: conflictError{"container is running"}},
+			"stop container web first, or remove it with force",
+		},
+		{
+			"network in use",
+			&NetworkError{ID: "backend", Op: "remove", Err: conflictError{"error while removing network: network backend has active endpoints"}},
+			"disconnect the containers from network backend first",
+		},
+		{
+			"unauthorized pull",
+			&ImageError{Ref: "private/app", Op: "pull", Unauthorized: true},
+			"log in to the registry",
+		},
+		{
+			"no space",
+			fmt.Errorf("build: %w", &ImageError{Ref: "app", Op: "build", Err: errors.New("no space left on device")}),
+			"free disk space on the docker host",
+		},
+		{
+			"port hint",
+			&ResourceExistsError{ResourceType: "port", ID: "web", Hint: "host port 80 is already allocated, choose another host port or stop container old-web"},
+			"host port 80 is already allocated, choose another host port or stop container old-web",
+		},
+		{"existing container", &ResourceExistsError{ResourceType: "container", ID: "web"}, "choose another name, or remove the existing container web first"},
+		{"missing image", &ResourceNotFoundError{ResourceType: "image", ID: "nginx:1.27"}, "pull nginx:1.27 first"},
+		{"daemon", &DaemonNotRunningError{Message: "connection refused"}, "start the docker daemon"},
+		{"plain daemon error", fmt.Errorf("pull: %w", errors.New("no matching manifest for linux/arm64")), "use an image built for the platform"},
+		{"validation", &ValidationError{Field: "name", Message: "cannot be empty"}, ""},
+		{"unknown", &ContainerError{ID: "web", Op: "start", Err: errors.New("boom")}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Suggestion(tt.err)
+			if tt.want == "" && got != "" || !strings.HasPrefix(got, tt.want) {
+				t.Errorf("Suggestion() = %q, want prefix %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	cause := conflictError{"You cannot remove a running container abc"}
+	err := &ContainerError{ID: "web", Op: "remove", Message: cause.Error(), Err: cause}
+	want := `Error: container web: remove failed: You cannot remove a running container abc
+  Operation:  remove
+  Resource:   container web
+  Status:     409 Conflict
+  Suggestion: stop container web first, or remove it with force`
+	if got := Render(err); got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+
+	joined := Render(errors.Join(&ValidationError{Field: "name", Message: "cannot be empty"}, errors.New("boom")))
+	if joined != "Error: validation failed for name: cannot be empty\nError: boom" {
+		t.Errorf("Render() of joined errors = %q", joined)
+	}
+	if Render(nil) != "" {
+		t.Errorf("Render(nil) is not empty")
+	}
+}
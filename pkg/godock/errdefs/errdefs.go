@@ -3,6 +3,9 @@ package errdefs
 import (
 	"errors"
 	"fmt"
+	"strings"
+
+	dockerErrdefs "github.com/docker/docker/errdefs"
 )
 
 var (
@@ -18,12 +21,105 @@ var (
 	ErrTimeout = errors.New("operation timed out")
 	// ErrCanceled is returned when an operation is canceled
 	ErrCanceled = errors.New("operation canceled")
+	// ErrUnauthorized is returned when a registry rejects the credentials or denies access
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrConflict is returned when an operation conflicts with the state of a resource, e.g. a name in use
+	// or removing a running container
+	ErrConflict = errors.New("conflict")
+	// ErrNoSpace is returned when the daemon host has run out of disk space
+	ErrNoSpace = errors.New("no space left on device")
+	// ErrPlatformMismatch is returned when an image is not available for, or cannot run on, the requested platform
+	ErrPlatformMismatch = errors.New("platform mismatch")
+	// ErrDigestMismatch is returned when a pulled image does not have the digest it is pinned to
+	ErrDigestMismatch = errors.New("digest mismatch")
 )
 
+// Markers of the failures that registries and the daemon report inside messages, e.g. in progress streams,
+// rather than with a status code.
+var (
+	unauthorizedMarkers = []string{
+		"unauthorized",
+		"authentication required",
+		"requested access to the resource is denied",
+		"no basic auth credentials",
+		"denied:",
+	}
+	conflictMarkers         = []string{"port is already allocated"}
+	noSpaceMarkers          = []string{"no space left on device"}
+	platformMismatchMarkers = []string{
+		"does not match the specified platform",
+		"no matching manifest for",
+		"exec format error",
+	}
+)
+
+/*
+Classify returns the sentinel error matching an error of the docker daemon or a registry: ErrUnauthorized,
+ErrNotFound, ErrConflict, ErrNoSpace or ErrPlatformMismatch, or nil when it matches none. The status code of the
+daemon response decides when it is known. The message is only looked at for errors without a status code, as
+failures inside progress streams have none, and for server errors, which the daemon returns for failures like a
+full disk. Wrapped errors are looked through.
+
+Usage example:
+
+	switch errdefs.Classify(err) {
+	case errdefs.ErrNoSpace:
+		// prune images and retry
+	case errdefs.ErrPlatformMismatch:
+		// pull for another platform
+	}
+*/
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch {
+	case dockerErrdefs.IsUnauthorized(err) || dockerErrdefs.IsForbidden(err):
+		return ErrUnauthorized
+	case dockerErrdefs.IsNotFound(err):
+		return ErrNotFound
+	case dockerErrdefs.IsConflict(err):
+		return ErrConflict
+	case dockerErrdefs.IsInvalidParameter(err), dockerErrdefs.IsUnavailable(err), dockerErrdefs.IsNotModified(err),
+		dockerErrdefs.IsNotImplemented(err), dockerErrdefs.IsCancelled(err), dockerErrdefs.IsDeadline(err),
+		dockerErrdefs.IsDataLoss(err):
+		// The status code names another class, whatever the message mentions.
+		return nil
+	}
+	message := strings.ToLower(err.Error())
+	switch {
+	case containsAny(message, unauthorizedMarkers):
+		return ErrUnauthorized
+	case containsAny(message, conflictMarkers):
+		return ErrConflict
+	case containsAny(message, noSpaceMarkers):
+		return ErrNoSpace
+	case containsAny(message, platformMismatchMarkers):
+		return ErrPlatformMismatch
+	}
+	return nil
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the underlying error of an operation error is classified as target.
+func matches(err, target error) bool {
+	return err != nil && target != nil && Classify(err) == target
+}
+
 // ResourceNotFoundError represents a not found error for a specific resource
 type ResourceNotFoundError struct {
 	ResourceType string
 	ID           string
+	// Err is the error of the daemon, if any.
+	Err error
 }
 
 func (e *ResourceNotFoundError) Error() string {
@@ -36,19 +132,33 @@ func (e *ResourceNotFoundError) Is(target error) bool {
 	return target == ErrNotFound
 }
 
+// Unwrap returns the error of the daemon.
+func (e *ResourceNotFoundError) Unwrap() error {
+	return e.Err
+}
+
 // ResourceExistsError represents an already exists error for a specific resource
 type ResourceExistsError struct {
 	ResourceType string
 	ID           string
+	// Err is the error of the daemon, if any.
+	Err error
+	// Hint replaces the default Suggestion when set, e.g. to name the container holding a port.
+	Hint string
 }
 
 func (e *ResourceExistsError) Error() string {
 	return fmt.Sprintf("%s already exists: %s", e.ResourceType, e.ID)
 }
 
-// Is implements the errors.Is interface
+// Is implements the errors.Is interface. A resource that already exists is also a conflict.
 func (e *ResourceExistsError) Is(target error) bool {
-	return target == ErrAlreadyExists
+	return target == ErrAlreadyExists || target == ErrConflict
+}
+
+// Unwrap returns the error of the daemon.
+func (e *ResourceExistsError) Unwrap() error {
+	return e.Err
 }
 
 // ConfigError represents an invalid configuration error
@@ -71,56 +181,173 @@ type ContainerError struct {
 	ID      string
 	Op      string
 	Message string
+	// Err is the underlying error, e.g. of the daemon. It is matched by errors.Is against ErrNotFound,
+	// ErrConflict, ErrUnauthorized, ErrNoSpace and ErrPlatformMismatch.
+	Err error
+	// Logs holds the last lines of output of the container, when the operation captured them.
+	Logs []string
 }
 
 func (e *ContainerError) Error() string {
 	return fmt.Sprintf("container %s: %s failed: %s", e.ID, e.Op, e.Message)
 }
 
+// Is implements the errors.Is interface
+func (e *ContainerError) Is(target error) bool {
+	return matches(e.Err, target)
+}
+
+// Unwrap returns the underlying error.
+func (e *ContainerError) Unwrap() error {
+	return e.Err
+}
+
 // NetworkError represents a network-specific error
 type NetworkError struct {
 	ID      string
 	Op      string
 	Message string
+	// Err is the underlying error, e.g. of the daemon. It is matched by errors.Is against ErrNotFound,
+	// ErrConflict, ErrUnauthorized, ErrNoSpace and ErrPlatformMismatch.
+	Err error
 }
 
 func (e *NetworkError) Error() string {
 	return fmt.Sprintf("network %s: %s failed: %s", e.ID, e.Op, e.Message)
 }
 
+// Is implements the errors.Is interface
+func (e *NetworkError) Is(target error) bool {
+	return matches(e.Err, target)
+}
+
+// Unwrap returns the underlying error.
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
 // VolumeError represents a volume-specific error
 type VolumeError struct {
 	Name    string
 	Op      string
 	Message string
+	// Err is the underlying error, e.g. of the daemon. It is matched by errors.Is against ErrNotFound,
+	// ErrConflict, ErrUnauthorized, ErrNoSpace and ErrPlatformMismatch.
+	Err error
 }
 
 func (e *VolumeError) Error() string {
 	return fmt.Sprintf("volume %s: %s failed: %s", e.Name, e.Op, e.Message)
 }
 
+// Is implements the errors.Is interface
+func (e *VolumeError) Is(target error) bool {
+	return matches(e.Err, target)
+}
+
+// Unwrap returns the underlying error.
+func (e *VolumeError) Unwrap() error {
+	return e.Err
+}
+
 // ImageError represents an image-specific error
 type ImageError struct {
 	Ref     string
 	Op      string
 	Message string
+	// Unauthorized is set when the registry rejected the credentials or denied access to the repository.
+	Unauthorized bool
+	// Err is the underlying error, e.g. of the daemon or the registry. It is matched by errors.Is like the Err
+	// of a ContainerError.
+	Err error
 }
 
 func (e *ImageError) Error() string {
 	return fmt.Sprintf("image %s: %s failed: %s", e.Ref, e.Op, e.Message)
 }
 
+// Is implements the errors.Is interface
+func (e *ImageError) Is(target error) bool {
+	return (e.Unauthorized && target == ErrUnauthorized) || matches(e.Err, target)
+}
+
+// Unwrap returns the underlying error.
+func (e *ImageError) Unwrap() error {
+	return e.Err
+}
+
+// DigestMismatchError is returned when a pulled image does not have the repository digest it is pinned to.
+// Got is the digest the image has in the repository of Ref, or empty when it has none, e.g. a locally built image.
+type DigestMismatchError struct {
+	Ref  string
+	Want string
+	Got  string
+}
+
+func (e *DigestMismatchError) Error() string {
+	if e.Got == "" {
+		return fmt.Sprintf("image %s: digest mismatch: want %s, image has no repository digest", e.Ref, e.Want)
+	}
+	return fmt.Sprintf("image %s: digest mismatch: want %s, got %s", e.Ref, e.Want, e.Got)
+}
+
+// Is implements the errors.Is interface
+func (e *DigestMismatchError) Is(target error) bool {
+	return target == ErrDigestMismatch
+}
+
 // ExecError represents an exec-specific error
 type ExecError struct {
 	ID      string
 	Op      string
 	Message string
+	// Err is the underlying error, e.g. of the daemon. It is matched by errors.Is against ErrNotFound,
+	// ErrConflict, ErrUnauthorized, ErrNoSpace and ErrPlatformMismatch.
+	Err error
 }
 
 func (e *ExecError) Error() string {
 	return fmt.Sprintf("exec %s: %s failed: %s", e.ID, e.Op, e.Message)
 }
 
+// Is implements the errors.Is interface
+func (e *ExecError) Is(target error) bool {
+	return matches(e.Err, target)
+}
+
+// Unwrap returns the underlying error.
+func (e *ExecError) Unwrap() error {
+	return e.Err
+}
+
+// SwarmError represents a swarm or node error. ID is the node, service, secret or config it concerns,
+// or empty for operations on the swarm itself.
+type SwarmError struct {
+	ID      string
+	Op      string
+	Message string
+	// Err is the underlying error, e.g. of the daemon. It is matched by errors.Is against ErrNotFound,
+	// ErrConflict, ErrUnauthorized, ErrNoSpace and ErrPlatformMismatch.
+	Err error
+}
+
+func (e *SwarmError) Error() string {
+	if e.ID == "" {
+		return fmt.Sprintf("swarm: %s failed: %s", e.Op, e.Message)
+	}
+	return fmt.Sprintf("swarm %s: %s failed: %s", e.ID, e.Op, e.Message)
+}
+
+// Is implements the errors.Is interface
+func (e *SwarmError) Is(target error) bool {
+	return matches(e.Err, target)
+}
+
+// Unwrap returns the underlying error.
+func (e *SwarmError) Unwrap() error {
+	return e.Err
+}
+
 // ValidationError represents a validation error
 type ValidationError struct {
 	Field   string
@@ -160,9 +387,9 @@ func Wrap(err error, message string) error {
 	return fmt.Errorf("%s: %w", message, err)
 }
 
-// IsNotFound returns true if the error is a not found error
+// IsNotFound returns true if the error is a not found error, including a not found error of the daemon
 func IsNotFound(err error) bool {
-	return errors.Is(err, ErrNotFound)
+	return errors.Is(err, ErrNotFound) || Classify(err) == ErrNotFound
 }
 
 // IsAlreadyExists returns true if the error is an already exists error
@@ -189,3 +416,29 @@ func IsTimeout(err error) bool {
 func IsCanceled(err error) bool {
 	return errors.Is(err, ErrCanceled)
 }
+
+// IsUnauthorized returns true if the error is a registry or daemon authentication or authorization error
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized) || Classify(err) == ErrUnauthorized
+}
+
+// IsConflict returns true if the error is a conflict with the state of a resource, including an already
+// existing resource
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict) || Classify(err) == ErrConflict
+}
+
+// IsNoSpace returns true if the daemon host has run out of disk space
+func IsNoSpace(err error) bool {
+	return errors.Is(err, ErrNoSpace) || Classify(err) == ErrNoSpace
+}
+
+// IsDigestMismatch returns true if a pulled image does not have the digest it is pinned to
+func IsDigestMismatch(err error) bool {
+	return errors.Is(err, ErrDigestMismatch)
+}
+
+// IsPlatformMismatch returns true if an image is not available for, or cannot run on, the requested platform
+func IsPlatformMismatch(err error) bool {
+	return errors.Is(err, ErrPlatformMismatch) || Classify(err) == ErrPlatformMismatch
+}
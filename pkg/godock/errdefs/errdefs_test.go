@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+
+	dockerErrdefs "github.com/docker/docker/errdefs"
 )
 
 func TestResourceNotFoundError(t *testing.T) {
@@ -190,6 +192,23 @@ func TestOperationalErrors(t *testing.T) {
 			},
 			wantMessage: "exec abc123: start failed: command not found",
 		},
+		{
+			name: "swarm error",
+			err: &SwarmError{
+				Op:      "init",
+				Message: "node is already part of a swarm",
+			},
+			wantMessage: "swarm: init failed: node is already part of a swarm",
+		},
+		{
+			name: "swarm node error",
+			err: &SwarmError{
+				ID:      "node1",
+				Op:      "update",
+				Message: "update out of sequence",
+			},
+			wantMessage: "swarm node1: update failed: update out of sequence",
+		},
 	}
 
 	for _, tt := range tests {
@@ -300,6 +319,38 @@ func TestErrorHelpers(t *testing.T) {
 				{IsInvalidConfig, true, "IsInvalidConfig"},
 			},
 		},
+		{
+			name: "unauthorized image error",
+			err: &ImageError{
+				Ref:          "registry.example.com/app",
+				Op:           "push",
+				Message:      "unauthorized: authentication required",
+				Unauthorized: true,
+			},
+			checks: []struct {
+				fn   func(error) bool
+				want bool
+				desc string
+			}{
+				{IsUnauthorized, true, "IsUnauthorized"},
+				{IsNotFound, false, "IsNotFound"},
+			},
+		},
+		{
+			name: "image error",
+			err: &ImageError{
+				Ref:     "registry.example.com/app",
+				Op:      "push",
+				Message: "connection reset",
+			},
+			checks: []struct {
+				fn   func(error) bool
+				want bool
+				desc string
+			}{
+				{IsUnauthorized, false, "IsUnauthorized"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -415,3 +466,116 @@ func TestDaemonNotRunningError(t *testing.T) {
 		})
 	}
 }
+
+// The daemon errors below carry their status code the way errors of the docker client do.
+type notFoundError struct{ message string }
+
+func (e notFoundError) Error() string { return e.message }
+func (e notFoundError) NotFound()     {}
+
+type conflictError struct{ message string }
+
+func (e conflictError) Error() string { return e.message }
+func (e conflictError) Conflict()     {}
+
+type invalidParameterError struct{ message string }
+
+func (e invalidParameterError) Error() string     { return e.message }
+func (e invalidParameterError) InvalidParameter() {}
+
+type forbiddenError struct{ message string }
+
+func (e forbiddenError) Error() string { return e.message }
+func (e forbiddenError) Forbidden()    {}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"nil", nil, nil},
+		{"not found status", notFoundError{"No such container: web"}, ErrNotFound},
+		{"conflict status", conflictError{`Conflict. The container name "/web" is already in use`}, ErrConflict},
+		{"forbidden status", forbiddenError{"forbidden"}, ErrUnauthorized},
+		{"wrapped status", fmt.Errorf("remove: %w", conflictError{"container is running"}), ErrConflict},
+		{"not found status naming a marker", dockerErrdefs.NotFound(errors.New("No such container: unauthorized-proxy")), ErrNotFound},
+		{"conflict status naming a marker", conflictError{`Conflict. The container name "/no-space-left-on-device" is already in use`}, ErrConflict},
+		{"invalid parameter status naming a marker", invalidParameterError{`invalid reference format: repository name "denied:" must be lowercase`}, nil},
+		{"server error message", dockerErrdefs.System(errors.New("write /var/lib/docker/tmp/layer: no space left on device")), ErrNoSpace},
+		{"unauthorized message", errors.New("pull access denied, repository does not exist or may require 'docker login': denied: requested access to the resource is denied"), ErrUnauthorized},
+		{"port allocated", errors.New("driver failed programming external connectivity: Bind for 0.0.0.0:8080 failed: port is already allocated"), ErrConflict},
+		{"no space", errors.New("write /var/lib/docker/tmp/layer: no space left on device"), ErrNoSpace},
+		{"no matching manifest", errors.New("no matching manifest for linux/arm64/v8 in the manifest list entries"), ErrPlatformMismatch},
+		{"platform", errors.New(`image with reference alpine was found but does not match the specified platform: wanted linux/arm64, actual: linux/amd64`), ErrPlatformMismatch},
+		{"exec format", errors.New("exec /bin/sh: exec format error"), ErrPlatformMismatch},
+		{"unknown", errors.New("connection reset by peer"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.err); got != tt.want {
+				t.Errorf("Classify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOperationalErrorsUnwrap(t *testing.T) {
+	cause := conflictError{"You cannot remove a running container"}
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"container error", &ContainerError{ID: "web", Op: "remove", Message: cause.Error(), Err: cause}},
+		{"network error", &NetworkError{ID: "backend", Op: "remove", Message: cause.Error(), Err: cause}},
+		{"volume error", &VolumeError{Name: "data", Op: "remove", Message: cause.Error(), Err: cause}},
+		{"image error", &ImageError{Ref: "nginx", Op: "remove", Message: cause.Error(), Err: cause}},
+		{"exec error", &ExecError{ID: "abc", Op: "start", Message: cause.Error(), Err: cause}},
+		{"swarm error", &SwarmError{Op: "init", Message: cause.Error(), Err: cause}},
+		{"exists error", &ResourceExistsError{ResourceType: "container", ID: "web", Err: cause}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, cause) {
+				t.Errorf("errors.Is() does not find the underlying error")
+			}
+			if !IsConflict(tt.err) {
+				t.Errorf("IsConflict() = false, want true")
+			}
+			if IsNotFound(tt.err) || IsNoSpace(tt.err) || IsUnauthorized(tt.err) {
+				t.Errorf("error matches an unrelated sentinel")
+			}
+		})
+	}
+
+	t.Run("without underlying error", func(t *testing.T) {
+		err := &ContainerError{ID: "web", Op: "start", Message: "no space left on device"}
+		if errors.Unwrap(err) != nil || errors.Is(err, ErrNoSpace) {
+			t.Errorf("an error without Err matches by its message")
+		}
+	})
+
+	t.Run("plain wrapped daemon error", func(t *testing.T) {
+		err := fmt.Errorf("failed to pull: %w", errors.New("no matching manifest for windows/amd64"))
+		if !IsPlatformMismatch(err) {
+			t.Errorf("IsPlatformMismatch() = false, want true")
+		}
+	})
+}
+
+func TestDigestMismatchError(t *testing.T) {
+	err := fmt.Errorf("pull: %w", &DigestMismatchError{Ref: "nginx:1.27", Want: "sha256:aaa", Got: "sha256:bbb"})
+	if !IsDigestMismatch(err) || IsNotFound(err) {
+		t.Errorf("IsDigestMismatch() = false, want true")
+	}
+	if want := "pull: image nginx:1.27: digest mismatch: want sha256:aaa, got sha256:bbb"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	unpinned := &DigestMismatchError{Ref: "app", Want: "sha256:aaa"}
+	if want := "image app: digest mismatch: want sha256:aaa, image has no repository digest"; unpinned.Error() != want {
+		t.Errorf("Error() = %q, want %q", unpinned.Error(), want)
+	}
+	if d := DetailsOf(err); d.Resource != "nginx:1.27" || d.Kind != ErrDigestMismatch {
+		t.Errorf("DetailsOf() = %+v", d)
+	}
+}
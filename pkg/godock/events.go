@@ -0,0 +1,46 @@
+package godock
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// EventsOptionFn is a function that configures the events operation.
+type EventsOptionFn func(*events.ListOptions)
+
+// WithEventFilter adds a filter to the events operation, e.g. WithEventFilter("type", "container").
+func WithEventFilter(key, value string) EventsOptionFn {
+	return func(opts *events.ListOptions) {
+		addFilters(&opts.Filters, []Filter{{Key: key, Value: value}})
+	}
+}
+
+// WithEventsSince only returns events created since the given timestamp or relative duration, e.g. "10m".
+func WithEventsSince(since string) EventsOptionFn {
+	return func(opts *events.ListOptions) {
+		opts.Since = since
+	}
+}
+
+// WithEventsUntil stops the event stream at the given timestamp or relative duration.
+func WithEventsUntil(until string) EventsOptionFn {
+	return func(opts *events.ListOptions) {
+		opts.Until = until
+	}
+}
+
+// Events streams real-time events from the docker daemon. Provide option functions to filter the events.
+// The stream ends when the context is cancelled or an error is sent on the error channel.
+func (c *Client) Events(ctx context.Context, eventsOptionFns ...EventsOptionFn) (<-chan events.Message, <-chan error) {
+	opts := events.ListOptions{
+		Filters: filters.NewArgs(),
+	}
+	for _, fn := range eventsOptionFns {
+		if fn != nil {
+			fn(&opts)
+		}
+	}
+	return c.wrapped.Events(ctx, opts)
+}
@@ -7,12 +7,14 @@ import (
 
 type ExecConfig struct {
 	Options *containerType.ExecOptions
+	Streams *execoptions.Streams
 	ID      string
 }
 
 func NewConfig() *ExecConfig {
 	return &ExecConfig{
 		Options: &containerType.ExecOptions{},
+		Streams: &execoptions.Streams{},
 	}
 }
 
@@ -24,6 +26,19 @@ func (c *ExecConfig) SetOptions(options ...execoptions.ExecOptionsFn) {
 	}
 }
 
+// SetStreams configures the streams piped into the exec, such as execoptions.WithStdin.
+func (c *ExecConfig) SetStreams(streams ...execoptions.SetStreamsFn) *ExecConfig {
+	if c.Streams == nil {
+		c.Streams = &execoptions.Streams{}
+	}
+	for _, set := range streams {
+		if set != nil {
+			set(c.Streams)
+		}
+	}
+	return c
+}
+
 // SetUser sets the user that will run the command
 func (c *ExecConfig) SetUser(user string) *ExecConfig {
 	c.Options.User = user
@@ -2,37 +2,52 @@ package execoptions
 
 import (
 	"fmt"
+	"io"
+	"sort"
+	"strings"
 
 	containerType "github.com/docker/docker/api/types/container"
 )
 
+// ExecOptionsFn is a function type that configures the options of an exec.
 type ExecOptionsFn func(options *containerType.ExecOptions)
 
+// TTY allocates a pseudo-TTY for the exec, which merges stderr into stdout.
 func TTY(tty bool) ExecOptionsFn {
 	return func(options *containerType.ExecOptions) {
 		options.Tty = tty
 	}
 }
+
+// AttachStdin attaches the standard input of the exec.
 func AttachStdin(attachStdin bool) ExecOptionsFn {
 	return func(options *containerType.ExecOptions) {
 		options.AttachStdin = attachStdin
 	}
 }
+
+// AttachStdout attaches the standard output of the exec.
 func AttachStdout(attachStdout bool) ExecOptionsFn {
 	return func(options *containerType.ExecOptions) {
 		options.AttachStdout = attachStdout
 	}
 }
+
+// AttachStderr attaches the standard error of the exec.
 func AttachStderr(attachStderr bool) ExecOptionsFn {
 	return func(options *containerType.ExecOptions) {
 		options.AttachStderr = attachStderr
 	}
 }
+
+// Detach starts the exec without waiting for it to finish.
 func Detach(detach bool) ExecOptionsFn {
 	return func(options *containerType.ExecOptions) {
 		options.Detach = detach
 	}
 }
+
+// CMD appends the command and its arguments to run.
 func CMD(cmd ...string) ExecOptionsFn {
 	return func(options *containerType.ExecOptions) {
 		if options.Cmd == nil {
@@ -41,12 +56,16 @@ func CMD(cmd ...string) ExecOptionsFn {
 		options.Cmd = append(options.Cmd, cmd...)
 	}
 }
+
+// User sets the user the command runs as, as a name or uid, optionally with a group, e.g. "1000:1000".
 func User(user string) ExecOptionsFn {
 	return func(options *containerType.ExecOptions) {
 		options.User = user
 	}
 }
-func ENV(key, value string) ExecOptionsFn {
+
+// Env adds an environment variable to the command's environment.
+func Env(key, value string) ExecOptionsFn {
 	return func(options *containerType.ExecOptions) {
 		if options.Env == nil {
 			options.Env = []string{}
@@ -54,21 +73,63 @@ func ENV(key, value string) ExecOptionsFn {
 		options.Env = append(options.Env, fmt.Sprintf("%s=%s", key, value))
 	}
 }
+
+/*
+EnvMap adds every entry of a map as an environment variable, in key order.
+
+Usage example:
+
+	execConfig := exec.NewConfig()
+	execConfig.SetOptions(
+		execoptions.CMD("./migrate"),
+		execoptions.EnvMap(map[string]string{
+			"DB_HOST": "db",
+			"DB_NAME": "app",
+		}),
+	)
+*/
+func EnvMap(env map[string]string) ExecOptionsFn {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return func(options *containerType.ExecOptions) {
+		for _, key := range keys {
+			Env(key, env[key])(options)
+		}
+	}
+}
+
+// ENV adds an environment variable to the command's environment.
+//
+// Deprecated: use Env.
+func ENV(key, value string) ExecOptionsFn {
+	return Env(key, value)
+}
+
+// WorkingDir sets the working directory the command runs in.
 func WorkingDir(workingDir string) ExecOptionsFn {
 	return func(options *containerType.ExecOptions) {
 		options.WorkingDir = workingDir
 	}
 }
+
+// Privileged gives the command extended privileges, even in an unprivileged container.
 func Privileged(privileged bool) ExecOptionsFn {
 	return func(options *containerType.ExecOptions) {
 		options.Privileged = privileged
 	}
 }
+
+// DetachKeys overrides the key sequence for detaching from the exec, e.g. "ctrl-p,ctrl-q".
 func DetachKeys(detachKeys string) ExecOptionsFn {
 	return func(options *containerType.ExecOptions) {
 		options.DetachKeys = detachKeys
 	}
 }
+
+// ConsoleSize sets the initial size of the TTY.
 func ConsoleSize(width, height uint) ExecOptionsFn {
 	return func(options *containerType.ExecOptions) {
 		if options.ConsoleSize == nil {
@@ -77,3 +138,41 @@ func ConsoleSize(width, height uint) ExecOptionsFn {
 		options.ConsoleSize = &[2]uint{width, height}
 	}
 }
+
+// STREAMS
+
+// Streams holds the streams piped into an exec by the client, which the daemon options cannot carry.
+type Streams struct {
+	// Stdin is copied into the exec, which then sees end of file once it is exhausted.
+	Stdin io.Reader
+}
+
+// SetStreamsFn is a function type that configures the streams of an exec.
+type SetStreamsFn func(streams *Streams)
+
+/*
+WithStdin pipes stdin into the exec when it is run with Client.ContainerExecRun, like a shell redirect.
+The exec's stdin is attached automatically.
+
+Usage example:
+
+	dump, err := os.Open("dump.sql")
+	if err != nil {
+		return err
+	}
+	defer dump.Close()
+	execConfig := exec.NewConfig()
+	execConfig.SetCmd("psql", "-U", "postgres")
+	execConfig.SetStreams(execoptions.WithStdin(dump))
+	res, err := client.ContainerExecRun(ctx, db, execConfig)
+*/
+func WithStdin(stdin io.Reader) SetStreamsFn {
+	return func(streams *Streams) {
+		streams.Stdin = stdin
+	}
+}
+
+// WithStdinString pipes s into the exec, like a shell here-document.
+func WithStdinString(s string) SetStreamsFn {
+	return WithStdin(strings.NewReader(s))
+}
@@ -0,0 +1,28 @@
+package execoptions
+
+import (
+	"testing"
+
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecOptions(t *testing.T) {
+	options := &containerType.ExecOptions{}
+	for _, fn := range []ExecOptionsFn{
+		Env("A", "1"),
+		EnvMap(map[string]string{"C": "3", "B": "2"}),
+		ENV("D", "4"),
+		User("1000:1000"),
+		WorkingDir("/app"),
+		Privileged(true),
+		DetachKeys("ctrl-x"),
+	} {
+		fn(options)
+	}
+	assert.Equal(t, []string{"A=1", "B=2", "C=3", "D=4"}, options.Env)
+	assert.Equal(t, "1000:1000", options.User)
+	assert.Equal(t, "/app", options.WorkingDir)
+	assert.True(t, options.Privileged)
+	assert.Equal(t, "ctrl-x", options.DetachKeys)
+}
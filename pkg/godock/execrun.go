@@ -0,0 +1,163 @@
+package godock
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/exec"
+	"github.com/aptd3v/godock/pkg/godock/execoptions"
+	containerType "github.com/docker/docker/api/types/container"
+)
+
+// ExecResult is the outcome of a command run with ExecRun.
+type ExecResult struct {
+	Stdout string
+	// Stderr is empty when the exec has a TTY, which merges stderr into Stdout.
+	Stderr   string
+	ExitCode int
+}
+
+/*
+ExecRun runs a command in a running container until it exits and returns its output and exit code. Stdout
+and stderr are attached and demultiplexed; option functions from the execoptions package can set the user,
+environment or working directory of the command. A non-zero exit code is not an error.
+
+Usage example:
+
+	res, err := client.ExecRun(ctx, db, []string{"pg_isready", "-U", "postgres"})
+	if err != nil {
+		return err
+	}
+	if res.ExitCode != 0 {
+		return fmt.Errorf("postgres is not ready: %s", res.Stderr)
+	}
+*/
+func (c *Client) ExecRun(ctx context.Context, containerConfig *container.ContainerConfig, cmd []string, execOptionFns ...execoptions.ExecOptionsFn) (ExecResult, error) {
+	if containerConfig == nil || containerConfig.Id == "" {
+		return ExecResult{}, &errdefs.ValidationError{Field: "containerConfig", Message: "container config or id cannot be empty"}
+	}
+	if len(cmd) == 0 {
+		return ExecResult{}, &errdefs.ValidationError{Field: "cmd", Message: "command cannot be empty"}
+	}
+
+	execConfig := exec.NewConfig()
+	execConfig.SetOptions(execOptionFns...)
+	execConfig.SetCmd(cmd...)
+	return c.ContainerExecRun(ctx, containerConfig, execConfig)
+}
+
+/*
+ContainerExecRun runs a configured exec in a running container until it exits, like ExecRun. Streams set
+with ExecConfig.SetStreams are piped into the exec, which sees end of file on its stdin once the reader is
+exhausted.
+
+Usage example:
+
+	execConfig := exec.NewConfig()
+	execConfig.SetCmd("psql", "-U", "postgres", "-d", "app")
+	execConfig.SetStreams(execoptions.WithStdinString("SELECT count(*) FROM users;"))
+	res, err := client.ContainerExecRun(ctx, db, execConfig)
+	if err != nil {
+		return err
+	}
+	fmt.Print(res.Stdout)
+*/
+func (c *Client) ContainerExecRun(ctx context.Context, containerConfig *container.ContainerConfig, execConfig *exec.ExecConfig) (ExecResult, error) {
+	if containerConfig == nil || containerConfig.Id == "" {
+		return ExecResult{}, &errdefs.ValidationError{Field: "containerConfig", Message: "container config or id cannot be empty"}
+	}
+	if execConfig == nil || len(execConfig.Options.Cmd) == 0 {
+		return ExecResult{}, &errdefs.ValidationError{Field: "execConfig", Message: "exec config or command cannot be empty"}
+	}
+	execConfig.SetAttachStdout(true)
+	execConfig.SetAttachStderr(true)
+	execConfig.SetDetach(false)
+	if execConfig.Streams != nil && execConfig.Streams.Stdin != nil {
+		execConfig.SetAttachStdin(true)
+	}
+
+	var stdout, stderr bytes.Buffer
+	exitCode, err := c.execRun(ctx, containerConfig, execConfig, &stdout, &stderr)
+	if err != nil {
+		return ExecResult{}, err
+	}
+	return ExecResult{Stdout: stdout.String(), Stderr: stderr.String(), ExitCode: exitCode}, nil
+}
+
+// stdinReader records the error of the reader it wraps, to tell a failing stdin apart from an exec that
+// stopped reading it.
+type stdinReader struct {
+	r   io.Reader
+	err error
+}
+
+func (s *stdinReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if err != nil && err != io.EOF {
+		s.err = err
+	}
+	return n, err
+}
+
+// execRun creates and attaches to an exec, copies its output to stdout and stderr until it ends, and returns
+// its exit code.
+func (c *Client) execRun(ctx context.Context, containerConfig *container.ContainerConfig, execConfig *exec.ExecConfig, stdout, stderr io.Writer) (int, error) {
+	if _, err := c.ContainerExecCreate(ctx, containerConfig, execConfig); err != nil {
+		return 0, err
+	}
+	hijack, err := c.wrapped.ContainerExecAttach(ctx, execConfig.ID, containerType.ExecAttachOptions{
+		ConsoleSize: execConfig.Options.ConsoleSize,
+		Tty:         execConfig.Options.Tty,
+	})
+	if err != nil {
+		return 0, &errdefs.ExecError{ID: execConfig.ID, Op: "attach", Message: err.Error(), Err: err}
+	}
+	defer hijack.Close()
+
+	var stdinErrCh chan error
+	if execConfig.Streams != nil && execConfig.Streams.Stdin != nil {
+		stdinErrCh = make(chan error, 1)
+		go func() {
+			stdin := &stdinReader{r: execConfig.Streams.Stdin}
+			// Errors writing to the exec mean it exited before reading all of stdin, which is up to the command.
+			io.Copy(hijack.Conn, stdin)
+			hijack.CloseWrite()
+			stdinErrCh <- stdin.err
+		}()
+	}
+
+	// With a TTY the output is a single raw stream, otherwise it is multiplexed.
+	if execConfig.Options.Tty {
+		_, err = io.Copy(stdout, hijack.Reader)
+	} else {
+		_, err = NewLogCopier(stdout, stderr).Copy(hijack.Reader)
+	}
+	if err != nil {
+		return 0, &errdefs.ExecError{ID: execConfig.ID, Op: "read output", Message: err.Error(), Err: err}
+	}
+	select {
+	case err := <-stdinErrCh:
+		if err != nil {
+			return 0, &errdefs.ExecError{ID: execConfig.ID, Op: "read stdin", Message: err.Error(), Err: err}
+		}
+	default:
+	}
+
+	// The exec may still be reported as running for a moment after its output ends.
+	for {
+		inspect, err := c.ContainerExecInspect(ctx, execConfig)
+		if err != nil {
+			return 0, err
+		}
+		if !inspect.Running {
+			return inspect.ExitCode, nil
+		}
+		if err := c.Clock().Sleep(ctx, 50*time.Millisecond); err != nil {
+			return 0, err
+		}
+	}
+}
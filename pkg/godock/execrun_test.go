@@ -0,0 +1,94 @@
+package godock
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/exec"
+	"github.com/aptd3v/godock/pkg/godock/execoptions"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecRunValidation(t *testing.T) {
+	c := &Client{}
+	_, err := c.ExecRun(context.Background(), nil, []string{"true"})
+	assert.True(t, errdefs.IsInvalidConfig(err))
+
+	ctr := container.NewConfig("app")
+	ctr.Id = "abc"
+	_, err = c.ExecRun(context.Background(), ctr, nil)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+
+	_, err = c.ContainerExecRun(context.Background(), ctr, exec.NewConfig())
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	_, err = c.ContainerExecRun(context.Background(), ctr, nil)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
+
+func TestExecConfigSetStreams(t *testing.T) {
+	execConfig := exec.NewConfig()
+	execConfig.SetStreams(execoptions.WithStdinString("hello"))
+	b, err := io.ReadAll(execConfig.Streams.Stdin)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(b))
+}
+
+func TestStdinReader(t *testing.T) {
+	stdin := &stdinReader{r: strings.NewReader("data")}
+	_, err := io.ReadAll(stdin)
+	require.NoError(t, err)
+	assert.NoError(t, stdin.err)
+
+	failing := errors.New("disk error")
+	stdin = &stdinReader{r: io.MultiReader(strings.NewReader("data"), iotest.ErrReader(failing))}
+	_, err = io.ReadAll(stdin)
+	assert.ErrorIs(t, err, failing)
+	assert.ErrorIs(t, stdin.err, failing)
+}
+
+func TestExecRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	client := setupTestClient(t)
+	busybox := image.NewConfig(DefaultDoctorProbeImage)
+	require.NoError(t, client.EnsureImage(ctx, busybox, PullPolicyIfNotPresent))
+
+	ctr := container.NewConfig("godock-execrun-" + GenerateRandomString(8))
+	ctr.SetContainerOptions(
+		containeroptions.Image(busybox),
+		containeroptions.CMD("sleep", "60"),
+	)
+	require.NoError(t, client.ContainerCreate(ctx, ctr))
+	defer client.ContainerRemove(context.WithoutCancel(ctx), ctr, removeoptions.Force())
+	require.NoError(t, client.ContainerStart(ctx, ctr))
+
+	res, err := client.ExecRun(ctx, ctr, []string{"sh", "-c", "echo out; echo err >&2; echo $GREETING; exit 3"},
+		execoptions.Env("GREETING", "hello"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "out\nhello\n", res.Stdout)
+	assert.Equal(t, "err\n", res.Stderr)
+	assert.Equal(t, 3, res.ExitCode)
+
+	execConfig := exec.NewConfig()
+	execConfig.SetCmd("sh", "-c", "wc -l; cat >&2")
+	execConfig.SetStreams(execoptions.WithStdinString("one\ntwo\nthree\n"))
+	res, err = client.ContainerExecRun(ctx, ctr, execConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "3", strings.TrimSpace(res.Stdout))
+	assert.Empty(t, res.Stderr)
+	assert.Equal(t, 0, res.ExitCode)
+}
@@ -0,0 +1,162 @@
+package godock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-units"
+)
+
+// exitSignals names the signals containers commonly die from, by their Linux number.
+var exitSignals = map[int]string{
+	1:  "SIGHUP",
+	2:  "SIGINT",
+	3:  "SIGQUIT",
+	4:  "SIGILL",
+	6:  "SIGABRT",
+	7:  "SIGBUS",
+	8:  "SIGFPE",
+	9:  "SIGKILL",
+	11: "SIGSEGV",
+	13: "SIGPIPE",
+	15: "SIGTERM",
+}
+
+// ContainerExitInfo describes how a container stopped.
+type ContainerExitInfo struct {
+	// Running reports whether the container is still running, in which case it has no exit information.
+	Running  bool
+	ExitCode int
+	// OOMKilled reports whether the kernel killed the container for running out of memory.
+	OOMKilled bool
+	// Error is the error the daemon reported for the container, e.g. when its command could not be started.
+	Error string
+	// FinishedAt is when the container stopped, zero when it has not run.
+	FinishedAt time.Time
+	// Signal is the signal that killed the main process, e.g. "SIGKILL", derived from an exit code above 128.
+	Signal string
+	// MemoryLimit is the memory limit of the container in bytes, zero when it has none.
+	MemoryLimit int64
+}
+
+// Explain returns a human explanation of how the container stopped, e.g.
+// "exited with code 137: killed for running out of memory (limit 256MiB)".
+func (i *ContainerExitInfo) Explain() string {
+	switch {
+	case i.Running:
+		return "is still running"
+	case i.FinishedAt.IsZero() && i.ExitCode == 0 && i.Error == "":
+		return "has not run"
+	}
+
+	explanation := fmt.Sprintf("exited with code %d", i.ExitCode)
+	var reason string
+	switch {
+	case i.OOMKilled && i.MemoryLimit > 0:
+		reason = fmt.Sprintf("killed for running out of memory (limit %s)", units.BytesSize(float64(i.MemoryLimit)))
+	case i.OOMKilled:
+		reason = "killed for running out of memory"
+	case i.Error != "":
+		reason = i.Error
+	case i.ExitCode == 126:
+		reason = "command cannot be executed"
+	case i.ExitCode == 127:
+		reason = "command not found"
+	case i.Signal != "":
+		reason = "killed by " + i.Signal
+	}
+	if reason == "" {
+		return explanation
+	}
+	return explanation + ": " + reason
+}
+
+// Suggestion returns a remediation for the common causes of a failed container, or an empty string.
+func (i *ContainerExitInfo) Suggestion() string {
+	switch {
+	case i.OOMKilled:
+		return "raise the memory limit of the container, e.g. with hostoptions.Memory, or reduce its memory use"
+	case i.ExitCode == 126:
+		return "check that the command is an executable file"
+	case i.ExitCode == 127:
+		return "check that the command exists in the image and is on its PATH"
+	}
+	return ""
+}
+
+/*
+ExitError is the cause of the ContainerError RunAndWait returns for a non-zero exit code. Retrieve it with
+errors.As to tell why the container failed.
+
+Usage example:
+
+	var exit *godock.ExitError
+	if err := client.RunAndWait(ctx, job); errors.As(err, &exit) && exit.Info.OOMKilled {
+		return fmt.Errorf("job needs more than %s of memory", units.BytesSize(float64(exit.Info.MemoryLimit)))
+	}
+*/
+type ExitError struct {
+	Info *ContainerExitInfo
+}
+
+func (e *ExitError) Error() string {
+	return e.Info.Explain()
+}
+
+// Suggestion returns a remediation for the exit, see ContainerExitInfo.Suggestion.
+func (e *ExitError) Suggestion() string {
+	return e.Info.Suggestion()
+}
+
+/*
+ContainerExitInfo returns how a container stopped: its exit code, whether it ran out of memory, the signal that
+killed it and the error reported by the daemon. Explain formats it for humans.
+
+Usage example:
+
+	info, err := client.ContainerExitInfo(ctx, job)
+	if err != nil {
+		return err
+	}
+	fmt.Println(job.Name, info.Explain())
+*/
+func (c *Client) ContainerExitInfo(ctx context.Context, containerConfig *container.ContainerConfig) (*ContainerExitInfo, error) {
+	if err := validateContainerConfig(containerConfig); err != nil {
+		return nil, err
+	}
+	inspect, err := c.wrapped.ContainerInspect(ctx, containerConfig.Id)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return nil, &errdefs.ResourceNotFoundError{ResourceType: "container", ID: containerConfig.Id, Err: err}
+		}
+		return nil, &errdefs.ContainerError{ID: containerConfig.Id, Op: "exit info", Message: err.Error(), Err: err}
+	}
+
+	info := &ContainerExitInfo{}
+	if inspect.ContainerJSONBase == nil || inspect.State == nil {
+		return info, nil
+	}
+	state := inspect.State
+	info.Running = state.Running || state.Restarting
+	info.ExitCode = state.ExitCode
+	info.OOMKilled = state.OOMKilled
+	info.Error = state.Error
+	if finished, err := time.Parse(time.RFC3339Nano, state.FinishedAt); err == nil && finished.Year() > 1 {
+		info.FinishedAt = finished
+	}
+	if state.ExitCode > 128 {
+		signal := state.ExitCode - 128
+		info.Signal = exitSignals[signal]
+		if info.Signal == "" {
+			info.Signal = fmt.Sprintf("signal %d", signal)
+		}
+	}
+	if inspect.HostConfig != nil {
+		info.MemoryLimit = inspect.HostConfig.Memory
+	}
+	return info, nil
+}
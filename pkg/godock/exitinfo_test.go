@@ -0,0 +1,85 @@
+package godock
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerExitInfoExplain(t *testing.T) {
+	finished := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		info ContainerExitInfo
+		want string
+	}{
+		{"Running", ContainerExitInfo{Running: true}, "is still running"},
+		{"Not run", ContainerExitInfo{}, "has not run"},
+		{"Success", ContainerExitInfo{FinishedAt: finished}, "exited with code 0"},
+		{"Failure", ContainerExitInfo{ExitCode: 2, FinishedAt: finished}, "exited with code 2"},
+		{"OOM", ContainerExitInfo{ExitCode: 137, OOMKilled: true, Signal: "SIGKILL", MemoryLimit: 256 << 20, FinishedAt: finished},
+			"exited with code 137: killed for running out of memory (limit 256MiB)"},
+		{"Killed", ContainerExitInfo{ExitCode: 137, Signal: "SIGKILL", FinishedAt: finished}, "exited with code 137: killed by SIGKILL"},
+		{"Command not found", ContainerExitInfo{ExitCode: 127, FinishedAt: finished}, "exited with code 127: command not found"},
+		{"Daemon error", ContainerExitInfo{ExitCode: 128, Error: `exec: "serve": executable file not found in $PATH`},
+			`exited with code 128: exec: "serve": executable file not found in $PATH`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.info.Explain())
+		})
+	}
+}
+
+func TestContainerExitInfo(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1.45/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"Id":"job"}`)
+	})
+	mux.HandleFunc("POST /v1.45/containers/{id}/start", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /v1.45/containers/{id}/wait", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"StatusCode":137}`)
+	})
+	mux.HandleFunc("GET /v1.45/containers/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"Id":"job","State":{"Running":false,"OOMKilled":true,"ExitCode":137,`+
+			`"FinishedAt":"2024-05-01T12:00:00.123Z"},"HostConfig":{"Memory":268435456}}`)
+	})
+	c := newFakeDaemonClient(t, mux)
+	ctx := context.Background()
+
+	t.Run("Exit info", func(t *testing.T) {
+		info, err := c.ContainerExitInfo(ctx, &container.ContainerConfig{Id: "job"})
+		require.NoError(t, err)
+		assert.Equal(t, &ContainerExitInfo{
+			ExitCode:    137,
+			OOMKilled:   true,
+			FinishedAt:  time.Date(2024, 5, 1, 12, 0, 0, 123000000, time.UTC),
+			Signal:      "SIGKILL",
+			MemoryLimit: 256 << 20,
+		}, info)
+
+		_, err = c.ContainerExitInfo(ctx, nil)
+		assert.True(t, errdefs.IsInvalidConfig(err))
+	})
+
+	t.Run("RunAndWait explains the exit", func(t *testing.T) {
+		job := container.NewConfig("job")
+		job.Options.Image = "alpine"
+		err := c.RunAndWait(ctx, job)
+		assert.EqualError(t, err, "container job: run failed: exited with code 137: killed for running out of memory (limit 256MiB)")
+
+		var exit *ExitError
+		require.ErrorAs(t, err, &exit)
+		assert.True(t, exit.Info.OOMKilled)
+		assert.Contains(t, errdefs.Suggestion(err), "raise the memory limit")
+	})
+}
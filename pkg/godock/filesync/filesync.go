@@ -0,0 +1,298 @@
+package filesync
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+)
+
+// DefaultDebounce is how long a syncer waits for changes to settle by default.
+const DefaultDebounce = 200 * time.Millisecond
+
+// Result is the outcome of a sync.
+type Result struct {
+	// Copied holds the files copied into the container, relative to the host directory.
+	Copied []string
+	// Removed holds the paths removed from the container, relative to the host directory.
+	Removed []string
+	// Err holds the error of the copy, the removal or the after-sync command, if any.
+	Err  error
+	Time time.Time
+}
+
+/*
+Syncer copies a host directory into a running container and keeps it in sync, a lightweight dev loop for code
+that the container reloads or reads on every request. Changes are collected until none happened for the
+debounce duration and then copied with a single archive; removed files are deleted with rm, which the image must
+provide.
+
+Usage example:
+
+	syncer := filesync.New(client, app, "./web", "/usr/share/nginx/html",
+		filesync.WithIgnore("node_modules", "*.swp"),
+		filesync.AfterSync("nginx", "-s", "reload"),
+		filesync.OnSync(func(result filesync.Result) {
+			log.Printf("synced %d files, removed %d: %v", len(result.Copied), len(result.Removed), result.Err)
+		}),
+	)
+	if err := syncer.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+*/
+type Syncer struct {
+	client        *godock.Client
+	container     *container.ContainerConfig
+	hostDir       string
+	containerPath string
+	debounce      time.Duration
+	ignore        []string
+	afterSync     []string
+	onSync        func(Result)
+	clock         clock.Clock
+
+	// Daemon operations, replaced in tests.
+	copy func(ctx context.Context, archive io.Reader) error
+	exec func(ctx context.Context, cmd []string) error
+}
+
+// SetSyncOptFn is a function type that configures a Syncer.
+type SetSyncOptFn func(s *Syncer)
+
+// New creates a Syncer copying hostDir into the directory containerPath of a running container. Files are owned by
+// root in the container, like docker cp creates them. ".git" directories are ignored by default.
+func New(client *godock.Client, containerConfig *container.ContainerConfig, hostDir, containerPath string, setOptFns ...SetSyncOptFn) *Syncer {
+	s := &Syncer{
+		client:        client,
+		container:     containerConfig,
+		hostDir:       hostDir,
+		containerPath: containerPath,
+		debounce:      DefaultDebounce,
+		ignore:        []string{".git"},
+	}
+	if client != nil {
+		s.clock = client.Clock()
+	} else {
+		s.clock = clock.Real()
+	}
+	s.copy = func(ctx context.Context, archive io.Reader) error {
+		return s.client.CopyToContainer(ctx, s.container, "/", archive)
+	}
+	s.exec = func(ctx context.Context, cmd []string) error {
+		res, err := s.client.ExecRun(ctx, s.container, cmd)
+		if err != nil {
+			return err
+		}
+		if res.ExitCode != 0 {
+			return &errdefs.ExecError{
+				ID:      s.container.Name,
+				Op:      strings.Join(cmd, " "),
+				Message: fmt.Sprintf("exited with code %d: %s", res.ExitCode, strings.TrimSpace(res.Stderr)),
+			}
+		}
+		return nil
+	}
+	for _, set := range setOptFns {
+		if set != nil {
+			set(s)
+		}
+	}
+	return s
+}
+
+// WithDebounce sets how long the syncer waits after a change for more changes before syncing. The default is
+// DefaultDebounce.
+func WithDebounce(d time.Duration) SetSyncOptFn {
+	return func(s *Syncer) {
+		if d > 0 {
+			s.debounce = d
+		}
+	}
+}
+
+// WithIgnore skips files and directories matching any of the patterns, in the syntax of path.Match. A pattern
+// without a slash is matched against every element of a path, one with a slash against the whole path relative
+// to the host directory.
+func WithIgnore(patterns ...string) SetSyncOptFn {
+	return func(s *Syncer) {
+		s.ignore = append(s.ignore, patterns...)
+	}
+}
+
+/*
+AfterSync runs a command in the container after every sync that changed files, e.g. to make the application
+reload them. A failing command is reported in the result of the sync.
+
+Usage example:
+
+	syncer := filesync.New(client, app, "./config", "/etc/app", filesync.AfterSync("kill", "-HUP", "1"))
+*/
+func AfterSync(cmd ...string) SetSyncOptFn {
+	return func(s *Syncer) {
+		s.afterSync = cmd
+	}
+}
+
+// OnSync sets a function called with the result of every sync, including the initial one.
+func OnSync(fn func(Result)) SetSyncOptFn {
+	return func(s *Syncer) {
+		s.onSync = fn
+	}
+}
+
+/*
+Run copies the whole host directory into the container, then watches it and syncs every change until ctx is
+done, returning the context error. Errors of a sync are reported through OnSync and do not stop the syncer;
+Run returns early when the host directory cannot be watched.
+*/
+func (s *Syncer) Run(ctx context.Context) error {
+	if s.container == nil || s.container.Id == "" {
+		return &errdefs.ValidationError{Field: "containerConfig", Message: "container config or ID cannot be empty"}
+	}
+	if !path.IsAbs(s.containerPath) {
+		return &errdefs.ValidationError{Field: "containerPath", Message: "container path must be absolute, got " + s.containerPath}
+	}
+
+	// Watch before the initial sync so changes made during it are not missed.
+	watcher, err := NewWatcher(s.hostDir, s.debounce, s.ignore...)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	watcher.clock = s.clock
+	s.report(s.sync(ctx, []string{"."}))
+	return watcher.Run(ctx, func(paths []string) {
+		s.report(s.sync(ctx, paths))
+	})
+}
+
+// ignored reports whether a slash separated path relative to the host directory matches an ignore pattern.
+func (s *Syncer) ignored(rel string) bool {
+	return matchIgnore(s.ignore, rel)
+}
+
+// report passes the result of a sync to the OnSync function.
+func (s *Syncer) report(result Result) {
+	if s.onSync != nil {
+		s.onSync(result)
+	}
+}
+
+// sync copies the given paths, relative to the host directory, into the container and removes the ones that no
+// longer exist on the host.
+func (s *Syncer) sync(ctx context.Context, paths []string) Result {
+	result := Result{Time: s.clock.Now()}
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	entries := 0
+	for _, rel := range paths {
+		hostPath := filepath.Join(s.hostDir, filepath.FromSlash(rel))
+		if _, err := os.Lstat(hostPath); errors.Is(err, fs.ErrNotExist) {
+			result.Removed = append(result.Removed, rel)
+			continue
+		}
+		err := filepath.WalkDir(hostPath, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(s.hostDir, p)
+			rel = filepath.ToSlash(rel)
+			if rel != "." && s.ignored(rel) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			copied, err := s.addEntry(tw, p, rel, d)
+			if copied {
+				result.Copied = append(result.Copied, rel)
+			}
+			entries++
+			return err
+		})
+		if err != nil {
+			result.Err = err
+			return result
+		}
+	}
+	if err := tw.Close(); err != nil {
+		result.Err = err
+		return result
+	}
+
+	if entries > 0 {
+		if err := s.copy(ctx, &buf); err != nil {
+			result.Err = err
+			return result
+		}
+	}
+	if len(result.Removed) > 0 {
+		cmd := []string{"rm", "-rf", "--"}
+		for _, rel := range result.Removed {
+			cmd = append(cmd, path.Join(s.containerPath, rel))
+		}
+		if err := s.exec(ctx, cmd); err != nil {
+			result.Err = err
+			return result
+		}
+	}
+	if len(s.afterSync) > 0 && (len(result.Copied) > 0 || len(result.Removed) > 0) {
+		result.Err = s.exec(ctx, s.afterSync)
+	}
+	return result
+}
+
+// addEntry writes a file, directory or symlink of the host directory to tw, named after its path in the container,
+// and reports whether it is a file.
+func (s *Syncer) addEntry(tw *tar.Writer, hostPath, rel string, d fs.DirEntry) (bool, error) {
+	info, err := d.Info()
+	if err != nil {
+		return false, err
+	}
+	var link string
+	if info.Mode()&fs.ModeSymlink != 0 {
+		if link, err = os.Readlink(hostPath); err != nil {
+			return false, err
+		}
+	} else if !info.Mode().IsRegular() && !info.IsDir() {
+		// Sockets, pipes and devices are not synced.
+		return false, nil
+	}
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return false, err
+	}
+	header.Name = strings.TrimPrefix(path.Join(s.containerPath, rel), "/")
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	// The host user does not exist in the container.
+	header.Uid, header.Gid, header.Uname, header.Gname = 0, 0, "", ""
+	if err := tw.WriteHeader(header); err != nil {
+		return false, err
+	}
+	if !info.Mode().IsRegular() {
+		return !info.IsDir(), nil
+	}
+	file, err := os.Open(hostPath)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	// The file may have changed since it was listed, the next sync picks up the rest.
+	_, err = io.CopyN(tw, file, header.Size)
+	return true, err
+}
@@ -0,0 +1,209 @@
+package filesync
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeContainer records what a syncer copies and runs in the container.
+type fakeContainer struct {
+	mu       sync.Mutex
+	files    map[string]string
+	commands [][]string
+}
+
+func newTestSyncer(t *testing.T, hostDir string, setOptFns ...SetSyncOptFn) (*Syncer, *fakeContainer) {
+	t.Helper()
+	fake := &fakeContainer{files: make(map[string]string)}
+	s := New(nil, &container.ContainerConfig{Id: "app", Name: "app"}, hostDir, "/srv/app", setOptFns...)
+	s.copy = func(ctx context.Context, archive io.Reader) error {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		tr := tar.NewReader(archive)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				return nil
+			}
+			require.NoError(t, err)
+			data, err := io.ReadAll(tr)
+			require.NoError(t, err)
+			if header.Typeflag == tar.TypeReg {
+				fake.files[header.Name] = string(data)
+			}
+		}
+	}
+	s.exec = func(ctx context.Context, cmd []string) error {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		fake.commands = append(fake.commands, cmd)
+		return nil
+	}
+	return s, fake
+}
+
+func TestIgnored(t *testing.T) {
+	s := New(nil, nil, ".", "/app", WithIgnore("node_modules", "*.swp", "build/tmp"))
+	assert.True(t, s.ignored(".git"))
+	assert.True(t, s.ignored("web/node_modules/react/index.js"))
+	assert.True(t, s.ignored("src/.main.go.swp"))
+	assert.True(t, s.ignored("build/tmp"))
+	assert.False(t, s.ignored("build/app"))
+	assert.False(t, s.ignored("src/main.go"))
+}
+
+func TestSync(t *testing.T) {
+	hostDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(hostDir, "src"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(hostDir, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(hostDir, "src", "main.go"), []byte("package main"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(hostDir, ".git", "HEAD"), []byte("ref: main"), 0o644))
+
+	s, fake := newTestSyncer(t, hostDir, AfterSync("kill", "-HUP", "1"))
+	ctx := context.Background()
+
+	result := s.sync(ctx, []string{"."})
+	require.NoError(t, result.Err)
+	assert.Equal(t, []string{"src/main.go"}, result.Copied)
+	assert.Equal(t, map[string]string{"srv/app/src/main.go": "package main"}, fake.files)
+	assert.Equal(t, [][]string{{"kill", "-HUP", "1"}}, fake.commands)
+
+	require.NoError(t, os.Remove(filepath.Join(hostDir, "src", "main.go")))
+	result = s.sync(ctx, []string{"src/main.go"})
+	require.NoError(t, result.Err)
+	assert.Equal(t, []string{"src/main.go"}, result.Removed)
+	assert.Equal(t, []string{"rm", "-rf", "--", "/srv/app/src/main.go"}, fake.commands[1])
+}
+
+func TestRun(t *testing.T) {
+	hostDir := t.TempDir()
+	results := make(chan Result, 10)
+	s, fake := newTestSyncer(t, hostDir, WithDebounce(20*time.Millisecond), OnSync(func(result Result) {
+		results <- result
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+
+	initial := <-results
+	require.NoError(t, initial.Err)
+	assert.Empty(t, initial.Copied)
+
+	// Changes in a new directory are picked up once it is watched.
+	require.NoError(t, os.MkdirAll(filepath.Join(hostDir, "static"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(hostDir, "static", "index.html"), []byte("<h1>hi</h1>"), 0o644))
+	for {
+		select {
+		case result := <-results:
+			require.NoError(t, result.Err)
+		case <-ctx.Done():
+			t.Fatal("the change was not synced")
+		}
+		fake.mu.Lock()
+		synced := fake.files["srv/app/static/index.html"]
+		fake.mu.Unlock()
+		if synced == "<h1>hi</h1>" {
+			break
+		}
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWatcherRun(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWatcher(dir, time.Second)
+	require.NoError(t, err)
+	defer w.Close()
+	fake := clock.NewFake(time.Unix(0, 0))
+	w.clock = fake
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	calls, release := make(chan []string), make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Run(ctx, func(paths []string) {
+			calls <- paths
+			<-release
+		})
+	}()
+	// send hands a change to Run and waits for it to wait for the changes to settle.
+	send := func(send func()) {
+		t.Helper()
+		waiters := fake.Waiters()
+		send()
+		require.NoError(t, fake.BlockUntil(ctx, waiters+1))
+	}
+	event := func(name string) {
+		send(func() {
+			select {
+			case w.watcher.Events <- fsnotify.Event{Name: filepath.Join(dir, name), Op: fsnotify.Write}:
+			case <-ctx.Done():
+				t.Fatalf("the change of %s was not received", name)
+			}
+		})
+	}
+	settle := func() { fake.Advance(time.Second) }
+	next := func() []string {
+		t.Helper()
+		select {
+		case paths := <-calls:
+			return paths
+		case <-ctx.Done():
+			t.Fatal("fn was not called")
+			return nil
+		}
+	}
+
+	event("b.txt")
+	event("a.txt")
+	settle()
+	assert.Equal(t, []string{"a.txt", "b.txt"}, next())
+
+	// Changes are collected while fn runs and reported once it returned.
+	event("c.txt")
+	settle()
+	event("d.txt")
+	settle()
+	release <- struct{}{}
+	assert.Equal(t, []string{"c.txt", "d.txt"}, next())
+	release <- struct{}{}
+
+	// An overflow resyncs the whole directory instead of stopping.
+	event("e.txt")
+	send(func() { w.watcher.Errors <- fsnotify.ErrEventOverflow })
+	event("f.txt")
+	settle()
+	assert.Equal(t, []string{"."}, next())
+	release <- struct{}{}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestRunValidation(t *testing.T) {
+	s := New(nil, nil, t.TempDir(), "/app")
+	assert.True(t, errdefs.IsInvalidConfig(s.Run(context.Background())))
+	s = New(nil, &container.ContainerConfig{Id: "app"}, t.TempDir(), "app")
+	assert.True(t, errdefs.IsInvalidConfig(s.Run(context.Background())))
+	s = New(nil, &container.ContainerConfig{Id: "app"}, filepath.Join(t.TempDir(), "missing"), "/app")
+	assert.True(t, errdefs.IsInvalidConfig(s.Run(context.Background())))
+}
@@ -0,0 +1,189 @@
+package filesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/fsnotify/fsnotify"
+)
+
+/*
+Watcher reports the changes below a host directory in batches: changes are collected until none happened for the
+debounce duration. It watches the directories below the host directory too, including the ones created later.
+
+Usage example:
+
+	watcher, err := filesync.NewWatcher("./src", 500*time.Millisecond, "node_modules")
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	err = watcher.Run(ctx, func(paths []string) {
+		log.Printf("changed: %v", paths)
+	})
+*/
+type Watcher struct {
+	dir      string
+	debounce time.Duration
+	ignore   []string
+	clock    clock.Clock
+	watcher  *fsnotify.Watcher
+}
+
+// NewWatcher starts watching dir, skipping the files and directories matching the ignore patterns, see WithIgnore.
+// A debounce of zero or less is DefaultDebounce. Close the watcher once done with it.
+func NewWatcher(dir string, debounce time.Duration, ignore ...string) (*Watcher, error) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, &errdefs.ValidationError{Field: "dir", Message: dir + " is not a directory"}
+	}
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{dir: dir, debounce: debounce, ignore: ignore, clock: clock.Real(), watcher: watcher}
+	if err := w.watchTree(dir); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Close stops watching.
+func (w *Watcher) Close() error {
+	return w.watcher.Close()
+}
+
+/*
+Run calls fn with the sorted, slash separated paths relative to the directory that changed, once per batch of
+changes, until ctx is done, returning the context error once fn returned. fn runs on a goroutine of its own and is
+not called again before it returns, changes keep being collected meanwhile and make up the next batch. When the
+events of the file system overflow the queue of the watcher, changes are lost and the next batch is "." to have the
+whole directory looked at again.
+*/
+func (w *Watcher) Run(ctx context.Context, fn func(paths []string)) error {
+	batches := make(chan []string, 1)
+	// Receives once fn returned, buffered as a batch is only sent while fn is not running.
+	finished := make(chan struct{}, 1)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for paths := range batches {
+			fn(paths)
+			finished <- struct{}{}
+		}
+	}()
+	defer func() {
+		close(batches)
+		<-stopped
+	}()
+
+	pending := make(map[string]bool)
+	var settled <-chan time.Time
+	// due is set when the changes settled while fn was running.
+	running, due := false, false
+	flush := func() {
+		paths := make([]string, 0, len(pending))
+		for rel := range pending {
+			paths = append(paths, rel)
+		}
+		clear(pending)
+		slices.Sort(paths)
+		batches <- paths
+		running, due = true, false
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return ctx.Err()
+			}
+			if !errors.Is(err, fsnotify.ErrEventOverflow) {
+				return fmt.Errorf("watching %s: %w", w.dir, err)
+			}
+			// The lost events may have created directories, watching the tree again adds them.
+			w.watchTree(w.dir)
+			clear(pending)
+			pending["."] = true
+			settled = w.clock.After(w.debounce)
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return ctx.Err()
+			}
+			rel, err := filepath.Rel(w.dir, event.Name)
+			if err != nil || matchIgnore(w.ignore, filepath.ToSlash(rel)) {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				// fsnotify does not watch subdirectories, new ones are added as they appear.
+				if info, err := os.Lstat(event.Name); err == nil && info.IsDir() {
+					w.watchTree(event.Name)
+				}
+			}
+			if !pending["."] {
+				pending[filepath.ToSlash(rel)] = true
+			}
+			settled, due = w.clock.After(w.debounce), false
+		case <-settled:
+			settled = nil
+			if running {
+				due = true
+				continue
+			}
+			flush()
+		case <-finished:
+			running = false
+			if due {
+				flush()
+			}
+		}
+	}
+}
+
+// watchTree watches dir and the directories below it that are not ignored.
+func (w *Watcher) watchTree(dir string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if rel, _ := filepath.Rel(w.dir, p); rel != "." && matchIgnore(w.ignore, filepath.ToSlash(rel)) {
+			return filepath.SkipDir
+		}
+		return w.watcher.Add(p)
+	})
+}
+
+// matchIgnore reports whether a slash separated relative path matches any of the patterns, see WithIgnore.
+func matchIgnore(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, rel); ok {
+				return true
+			}
+			continue
+		}
+		for _, elem := range strings.Split(rel, "/") {
+			if ok, _ := path.Match(pattern, elem); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package godock
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	imageType "github.com/docker/docker/api/types/image"
+	dockerNetwork "github.com/docker/docker/api/types/network"
+	volumeType "github.com/docker/docker/api/types/volume"
+)
+
+// ContainerStatus is the status of a container, used with FilterByStatus.
+type ContainerStatus string
+
+const (
+	StatusCreated    ContainerStatus = "created"
+	StatusRestarting ContainerStatus = "restarting"
+	StatusRunning    ContainerStatus = "running"
+	StatusRemoving   ContainerStatus = "removing"
+	StatusPaused     ContainerStatus = "paused"
+	StatusExited     ContainerStatus = "exited"
+	StatusDead       ContainerStatus = "dead"
+)
+
+// HealthStatus is the health of a container, used with FilterByHealth.
+type HealthStatus string
+
+const (
+	HealthStarting HealthStatus = "starting"
+	Healthy        HealthStatus = "healthy"
+	Unhealthy      HealthStatus = "unhealthy"
+	// NoHealthcheck matches containers without a health check.
+	NoHealthcheck HealthStatus = "none"
+)
+
+/*
+Filter is a typed filter for the list and prune calls of containers, images, networks and volumes.
+Add filters to a call with WithContainerFilters, WithImageFilters, WithNetworkFilters, WithVolumeFilters or
+WithPruneFilters. Every call validates its filters against the filters its endpoint supports and returns a
+ValidationError for the others, e.g. for FilterByStatus on an image list.
+
+Usage example:
+
+	running, err := client.ContainerList(ctx, godock.WithContainerFilters(
+		godock.FilterByLabel("com.example.stack", "shop"),
+		godock.FilterByStatus(godock.StatusRunning),
+		godock.FilterByHealth(godock.Unhealthy),
+	))
+*/
+type Filter struct {
+	Key   string
+	Value string
+}
+
+// FilterByLabel matches objects with the label. An empty value matches any value.
+func FilterByLabel(key, value string) Filter {
+	if value == "" {
+		return Filter{Key: "label", Value: key}
+	}
+	return Filter{Key: "label", Value: key + "=" + value}
+}
+
+// FilterByStatus matches containers with the status.
+func FilterByStatus(status ContainerStatus) Filter {
+	return Filter{Key: "status", Value: string(status)}
+}
+
+// FilterByAncestor matches containers created from the image, or from an image built on top of it.
+func FilterByAncestor(imageRef string) Filter {
+	return Filter{Key: "ancestor", Value: imageRef}
+}
+
+// FilterByName matches containers, networks and volumes whose name contains pattern. The daemon treats
+// the pattern as a regular expression for containers, e.g. "^web-" matches names starting with web-.
+func FilterByName(pattern string) Filter {
+	return Filter{Key: "name", Value: pattern}
+}
+
+// FilterByHealth matches containers with the health status.
+func FilterByHealth(health HealthStatus) Filter {
+	return Filter{Key: "health", Value: string(health)}
+}
+
+// FilterByDangling matches images that are untagged, or networks and volumes that are not used by any container.
+func FilterByDangling(dangling bool) Filter {
+	return Filter{Key: "dangling", Value: strconv.FormatBool(dangling)}
+}
+
+// filterEndpoint is a list or prune endpoint of the daemon and the filters it supports.
+type filterEndpoint struct {
+	name string
+	keys []string
+}
+
+var (
+	containerListFilters = filterEndpoint{"container list", []string{
+		"ancestor", "before", "expose", "exited", "health", "id", "isolation", "is-task", "label", "name", "network",
+		"publish", "since", "status", "volume",
+	}}
+	imageListFilters      = filterEndpoint{"image list", []string{"before", "dangling", "label", "reference", "since", "until"}}
+	networkListFilters    = filterEndpoint{"network list", []string{"dangling", "driver", "id", "label", "name", "scope", "type"}}
+	volumeListFilters     = filterEndpoint{"volume list", []string{"dangling", "driver", "label", "name"}}
+	containerPruneFilters = filterEndpoint{"container prune", []string{"label", "label!", "until"}}
+	imagePruneFilters     = filterEndpoint{"image prune", []string{"dangling", "label", "label!", "until"}}
+	networkPruneFilters   = filterEndpoint{"network prune", []string{"label", "label!", "until"}}
+	volumePruneFilters    = filterEndpoint{"volume prune", []string{"all", "label", "label!"}}
+)
+
+// validate returns a ValidationError for the first filter the endpoint does not support, or for an unknown
+// container status or health.
+func (e filterEndpoint) validate(args filters.Args) error {
+	keys := args.Keys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !slices.Contains(e.keys, key) {
+			return &errdefs.ValidationError{
+				Field:   "filters",
+				Message: fmt.Sprintf("%s does not support the %q filter, supported filters are %s", e.name, key, strings.Join(e.keys, ", ")),
+			}
+		}
+	}
+	for _, status := range args.Get("status") {
+		switch ContainerStatus(status) {
+		case StatusCreated, StatusRestarting, StatusRunning, StatusRemoving, StatusPaused, StatusExited, StatusDead:
+		default:
+			return &errdefs.ValidationError{Field: "filters", Message: fmt.Sprintf("unknown container status %q", status)}
+		}
+	}
+	for _, health := range args.Get("health") {
+		switch HealthStatus(health) {
+		case HealthStarting, Healthy, Unhealthy, NoHealthcheck:
+		default:
+			return &errdefs.ValidationError{Field: "filters", Message: fmt.Sprintf("unknown health status %q", health)}
+		}
+	}
+	return nil
+}
+
+// addFilters adds the filters to args, keeping the filters added before. Every list and filter option adds its
+// filters through it, so options compose in any order and also work on a zero filters.Args.
+func addFilters(args *filters.Args, fs []Filter) {
+	if args.Len() == 0 {
+		// A zero Args has no map to add to.
+		*args = filters.NewArgs()
+	}
+	for _, f := range fs {
+		args.Add(f.Key, f.Value)
+	}
+}
+
+// WithContainerFilters adds typed filters to the container list operation.
+func WithContainerFilters(fs ...Filter) ListContainerOptionFn {
+	return func(opts *containerType.ListOptions) {
+		addFilters(&opts.Filters, fs)
+	}
+}
+
+// WithImageFilters adds typed filters to the image list operation.
+func WithImageFilters(fs ...Filter) ImageListOptionFn {
+	return func(opts *imageType.ListOptions) {
+		addFilters(&opts.Filters, fs)
+	}
+}
+
+// WithNetworkFilters adds typed filters to the network list operation.
+func WithNetworkFilters(fs ...Filter) NetworkListOptionFn {
+	return func(opts *dockerNetwork.ListOptions) {
+		addFilters(&opts.Filters, fs)
+	}
+}
+
+// WithVolumeFilters adds typed filters to the volume list operation.
+func WithVolumeFilters(fs ...Filter) VolumeListOptionFn {
+	return func(opts *volumeType.ListOptions) {
+		addFilters(&opts.Filters, fs)
+	}
+}
+
+// WithPruneFilters adds typed filters to a container, image or network prune operation.
+func WithPruneFilters(fs ...Filter) PruneOptionFn {
+	return func(args *filters.Args) {
+		addFilters(args, fs)
+	}
+}
+
+// WithVolumePruneFilters adds typed filters to the volume prune operation.
+func WithVolumePruneFilters(fs ...Filter) PruneVolumeOptionFn {
+	return func(args *filters.Args) {
+		addFilters(args, fs)
+	}
+}
@@ -0,0 +1,157 @@
+package godock
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	imageType "github.com/docker/docker/api/types/image"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterBuilders(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		want   Filter
+	}{
+		{"Label with value", FilterByLabel("app", "web"), Filter{Key: "label", Value: "app=web"}},
+		{"Label with any value", FilterByLabel("app", ""), Filter{Key: "label", Value: "app"}},
+		{"Status", FilterByStatus(StatusRunning), Filter{Key: "status", Value: "running"}},
+		{"Ancestor", FilterByAncestor("nginx:1.27"), Filter{Key: "ancestor", Value: "nginx:1.27"}},
+		{"Name", FilterByName("^web-"), Filter{Key: "name", Value: "^web-"}},
+		{"Health", FilterByHealth(Unhealthy), Filter{Key: "health", Value: "unhealthy"}},
+		{"Dangling", FilterByDangling(true), Filter{Key: "dangling", Value: "true"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter)
+		})
+	}
+}
+
+func TestFilterEndpointValidate(t *testing.T) {
+	args := func(fs ...Filter) filters.Args {
+		var a filters.Args
+		addFilters(&a, fs)
+		return a
+	}
+
+	assert.NoError(t, containerListFilters.validate(args(
+		FilterByLabel("app", "web"), FilterByStatus(StatusExited), FilterByHealth(NoHealthcheck), FilterByAncestor("alpine"),
+	)))
+	assert.NoError(t, imageListFilters.validate(filters.NewArgs()))
+	assert.NoError(t, volumeListFilters.validate(args(FilterByDangling(true), FilterByName("data"))))
+
+	err := imageListFilters.validate(args(FilterByStatus(StatusRunning)))
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	assert.ErrorContains(t, err, `image list does not support the "status" filter`)
+
+	err = networkPruneFilters.validate(args(FilterByName("backend")))
+	assert.True(t, errdefs.IsInvalidConfig(err))
+
+	err = containerListFilters.validate(args(FilterByStatus("up")))
+	assert.ErrorContains(t, err, `unknown container status "up"`)
+
+	err = containerListFilters.validate(args(FilterByHealth("ok")))
+	assert.ErrorContains(t, err, `unknown health status "ok"`)
+}
+
+func TestListFilters(t *testing.T) {
+	var got map[string]map[string]bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1.45/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.Unmarshal([]byte(r.URL.Query().Get("filters")), &got))
+		io.WriteString(w, `[]`)
+	})
+	c := newFakeDaemonClient(t, mux)
+	ctx := context.Background()
+
+	_, err := c.ContainerList(ctx, WithContainerFilters(
+		FilterByLabel("com.example.stack", "shop"),
+		FilterByStatus(StatusRunning),
+		FilterByHealth(Healthy),
+	))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]bool{
+		"label":  {"com.example.stack=shop": true},
+		"status": {"running": true},
+		"health": {"healthy": true},
+	}, got)
+
+	t.Run("Unsupported filters are not sent", func(t *testing.T) {
+		got = nil
+		_, err := c.ContainerList(ctx, WithContainerFilters(FilterByDangling(true)))
+		assert.True(t, errdefs.IsInvalidConfig(err))
+		assert.Nil(t, got)
+	})
+}
+
+func TestFilterOptionsCompose(t *testing.T) {
+	t.Run("Container list", func(t *testing.T) {
+		var opts containerType.ListOptions
+		for _, fn := range []ListContainerOptionFn{
+			WithContainerFilter("label", "app=web"),
+			WithContainerFilter("status", "running"),
+			WithContainerFilters(FilterByLabel("tier", ""), FilterByStatus(StatusPaused)),
+			WithContainerFilter("name", "web"),
+		} {
+			fn(&opts)
+		}
+		assert.ElementsMatch(t, []string{"app=web", "tier"}, opts.Filters.Get("label"))
+		assert.ElementsMatch(t, []string{"running", "paused"}, opts.Filters.Get("status"))
+		assert.Equal(t, []string{"web"}, opts.Filters.Get("name"))
+	})
+
+	t.Run("Image list", func(t *testing.T) {
+		var opts imageType.ListOptions
+		WithImageFilter("reference", "nginx:*")(&opts)
+		WithImageFilter("dangling", "false")(&opts)
+		WithImageFilters(FilterByLabel("maintainer", "ops"))(&opts)
+		assert.Equal(t, []string{"nginx:*"}, opts.Filters.Get("reference"))
+		assert.Equal(t, []string{"false"}, opts.Filters.Get("dangling"))
+		assert.Equal(t, []string{"maintainer=ops"}, opts.Filters.Get("label"))
+	})
+
+	t.Run("Prune and events", func(t *testing.T) {
+		var args filters.Args
+		WithPruneFilter("until", "24h")(&args)
+		WithPruneFilters(FilterByLabel("env", "dev"))(&args)
+		assert.Equal(t, 2, args.Len())
+
+		var eventOpts events.ListOptions
+		WithEventFilter("type", "container")(&eventOpts)
+		WithEventFilter("event", "start")(&eventOpts)
+		WithEventFilter("event", "die")(&eventOpts)
+		assert.Equal(t, []string{"container"}, eventOpts.Filters.Get("type"))
+		assert.ElementsMatch(t, []string{"start", "die"}, eventOpts.Filters.Get("event"))
+	})
+}
+
+func TestListMultipleFilters(t *testing.T) {
+	var got map[string]map[string]bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1.45/images/json", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.Unmarshal([]byte(r.URL.Query().Get("filters")), &got))
+		io.WriteString(w, `[]`)
+	})
+	c := newFakeDaemonClient(t, mux)
+
+	_, err := c.ImageList(context.Background(),
+		WithImageFilter("reference", "nginx"),
+		WithImageFilter("label", "a=1"),
+		WithImageFilter("label", "b=2"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]bool{
+		"reference": {"nginx": true},
+		"label":     {"a=1": true, "b=2": true},
+	}, got)
+}
@@ -0,0 +1,304 @@
+// Package godocktest runs throwaway containers for Go tests: it starts an image, waits until the container
+// is ready, exposes the addresses of its published ports and removes it when the test ends.
+package godocktest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+)
+
+// DefaultStartupTimeout bounds how long Run waits for the wait strategies of a container.
+const DefaultStartupTimeout = time.Minute
+
+// LabelTest is the label set on every container started by Run, with the name of the test as value.
+const LabelTest = "godock.test"
+
+// WaitStrategy blocks until a started container is ready, or returns an error. ctx carries the startup timeout.
+type WaitStrategy func(ctx context.Context, client *godock.Client, ctr *container.ContainerConfig) error
+
+// ForLog waits until a log line of the container matches pattern, see godock.Client.WaitForLog.
+func ForLog(pattern string) WaitStrategy {
+	return func(ctx context.Context, client *godock.Client, ctr *container.ContainerConfig) error {
+		_, err := client.WaitForLog(ctx, ctr, pattern, 0)
+		return err
+	}
+}
+
+// ForPort waits until the container accepts TCP connections on containerPort, see godock.Client.WaitForPort.
+func ForPort(containerPort int) WaitStrategy {
+	return func(ctx context.Context, client *godock.Client, ctr *container.ContainerConfig) error {
+		return client.WaitForPort(ctx, ctr, containerPort, 0)
+	}
+}
+
+type options struct {
+	client         *godock.Client
+	image          string
+	name           string
+	containerOpts  []containeroptions.SetOptionsFns
+	hostOpts       []hostoptions.SetHostOptFn
+	ports          []int
+	waits          []WaitStrategy
+	startupTimeout time.Duration
+	pullPolicy     godock.PullPolicy
+}
+
+// OptionFn is a function that configures a container started by Run.
+type OptionFn func(*options)
+
+// WithClient runs the container with client instead of a client created from the environment.
+func WithClient(client *godock.Client) OptionFn {
+	return func(opts *options) {
+		opts.client = client
+	}
+}
+
+// WithImage runs imageRef instead of the image passed to Run, e.g. another tag of a preset's image.
+func WithImage(imageRef string) OptionFn {
+	return func(opts *options) {
+		opts.image = imageRef
+	}
+}
+
+// WithName sets the name of the container. By default the name is derived from the test name.
+func WithName(name string) OptionFn {
+	return func(opts *options) {
+		opts.name = name
+	}
+}
+
+// WithContainerOptions applies container options from the containeroptions package.
+func WithContainerOptions(setOptionsFns ...containeroptions.SetOptionsFns) OptionFn {
+	return func(opts *options) {
+		opts.containerOpts = append(opts.containerOpts, setOptionsFns...)
+	}
+}
+
+// WithHostOptions applies host options from the hostoptions package.
+func WithHostOptions(setHostOptFns ...hostoptions.SetHostOptFn) OptionFn {
+	return func(opts *options) {
+		opts.hostOpts = append(opts.hostOpts, setHostOptFns...)
+	}
+}
+
+// WithEnv sets an environment variable in the container.
+func WithEnv(key, value string) OptionFn {
+	return WithContainerOptions(containeroptions.Env(key, value))
+}
+
+// WithCmd sets the command of the container.
+func WithCmd(cmd ...string) OptionFn {
+	return WithContainerOptions(containeroptions.CMD(cmd...))
+}
+
+// WithPorts publishes TCP ports of the container on random ports of the loopback interface.
+// Use MappedPort or Endpoint to find them.
+func WithPorts(containerPorts ...int) OptionFn {
+	return func(opts *options) {
+		opts.ports = append(opts.ports, containerPorts...)
+	}
+}
+
+// WithWait adds wait strategies, which run in order after the container starts.
+func WithWait(strategies ...WaitStrategy) OptionFn {
+	return func(opts *options) {
+		opts.waits = append(opts.waits, strategies...)
+	}
+}
+
+// WithStartupTimeout bounds how long the wait strategies may take. The default is DefaultStartupTimeout.
+func WithStartupTimeout(timeout time.Duration) OptionFn {
+	return func(opts *options) {
+		opts.startupTimeout = timeout
+	}
+}
+
+// WithPullPolicy sets when the image is pulled. The default is godock.PullPolicyIfNotPresent.
+func WithPullPolicy(policy godock.PullPolicy) OptionFn {
+	return func(opts *options) {
+		opts.pullPolicy = policy
+	}
+}
+
+// Container is a container started by Run for the duration of a test.
+type Container struct {
+	// Config is the configuration the container was created with.
+	Config *container.ContainerConfig
+	client *godock.Client
+	t      testing.TB
+}
+
+/*
+Run pulls imageRef if needed, creates and starts a container and runs its wait strategies. The container
+and its anonymous volumes are removed when the test and its subtests complete. Run skips the test when no
+docker daemon is reachable and fails it when the container cannot be started or does not become ready.
+
+Usage example:
+
+	func TestRepository(t *testing.T) {
+		db := godocktest.Run(t, "postgres:16-alpine",
+			godocktest.WithEnv("POSTGRES_PASSWORD", "secret"),
+			godocktest.WithPorts(5432),
+			godocktest.WithWait(
+				godocktest.ForLog("database system is ready to accept connections"),
+				godocktest.ForPort(5432),
+			),
+		)
+		dsn := db.ConnectionString("postgres://postgres:secret@%s/postgres?sslmode=disable", 5432)
+		...
+	}
+*/
+func Run(t testing.TB, imageRef string, optionFns ...OptionFn) *Container {
+	t.Helper()
+	opts := &options{
+		startupTimeout: DefaultStartupTimeout,
+		pullPolicy:     godock.PullPolicyIfNotPresent,
+	}
+	for _, fn := range optionFns {
+		if fn != nil {
+			fn(opts)
+		}
+	}
+	if opts.image != "" {
+		imageRef = opts.image
+	}
+	ctx := context.Background()
+
+	client := opts.client
+	if client == nil {
+		var err error
+		client, err = godock.NewClient(ctx)
+		if err != nil {
+			t.Skipf("docker daemon is not reachable: %v", err)
+		}
+	}
+
+	img := image.NewConfig(imageRef)
+	if err := client.EnsureImage(ctx, img, opts.pullPolicy); err != nil {
+		t.Fatalf("godocktest: pull %s: %v", imageRef, err)
+	}
+
+	name := opts.name
+	if name == "" {
+		name = containerName(t.Name())
+	}
+	ctr := container.NewConfig(name)
+	ctr.SetContainerOptions(
+		containeroptions.Image(img),
+		containeroptions.Label(LabelTest, t.Name()),
+	)
+	ctr.SetContainerOptions(opts.containerOpts...)
+	for _, port := range opts.ports {
+		ctr.SetContainerOptions(containeroptions.ExposeTCP(strconv.Itoa(port)))
+		ctr.SetHostOptions(hostoptions.PortBindings("127.0.0.1", "", strconv.Itoa(port)))
+	}
+	ctr.SetHostOptions(opts.hostOpts...)
+
+	if err := client.ContainerCreate(ctx, ctr); err != nil {
+		t.Fatalf("godocktest: create %s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		if err := client.ContainerRemove(context.Background(), ctr, removeoptions.Force(), removeoptions.RemoveVolumes()); err != nil {
+			t.Logf("godocktest: remove %s: %v", name, err)
+		}
+	})
+	if err := client.ContainerStart(ctx, ctr); err != nil {
+		t.Fatalf("godocktest: start %s: %v", name, err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, opts.startupTimeout)
+	defer cancel()
+	for _, wait := range opts.waits {
+		if err := wait(waitCtx, client, ctr); err != nil {
+			t.Fatalf("godocktest: %s did not become ready: %v", name, err)
+		}
+	}
+
+	return &Container{
+		Config: ctr,
+		client: client,
+		t:      t,
+	}
+}
+
+// containerName derives a unique container name from a test name.
+func containerName(testName string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		}
+		return '-'
+	}, testName)
+	if len(name) > 48 {
+		name = name[:48]
+	}
+	return "godocktest-" + strings.Trim(name, "-.") + "-" + godock.GenerateRandomString(8)
+}
+
+// Client returns the client the container runs on.
+func (c *Container) Client() *godock.Client {
+	return c.client
+}
+
+// ID returns the id of the container.
+func (c *Container) ID() string {
+	return c.Config.Id
+}
+
+// Env returns the value of an environment variable the container was created with, or "" when it is not set.
+// It does not see variables set by the image.
+func (c *Container) Env(key string) string {
+	value := ""
+	for _, env := range c.Config.Options.Env {
+		if k, v, ok := strings.Cut(env, "="); ok && k == key {
+			value = v
+		}
+	}
+	return value
+}
+
+// Endpoint returns the host:port address of a port published with WithPorts. It fails the test when the
+// port is not published.
+func (c *Container) Endpoint(containerPort int) string {
+	c.t.Helper()
+	addr, err := c.client.PortAddress(context.Background(), c.Config, containerPort)
+	if err != nil {
+		c.t.Fatalf("godocktest: port %d of %s: %v", containerPort, c.Config.Name, err)
+	}
+	return addr
+}
+
+// Host returns the host published ports of the container are reachable on.
+func (c *Container) Host(containerPort int) string {
+	c.t.Helper()
+	host, _, _ := net.SplitHostPort(c.Endpoint(containerPort))
+	return host
+}
+
+// MappedPort returns the host port a port published with WithPorts is mapped to.
+func (c *Container) MappedPort(containerPort int) int {
+	c.t.Helper()
+	_, port, _ := net.SplitHostPort(c.Endpoint(containerPort))
+	mapped, _ := strconv.Atoi(port)
+	return mapped
+}
+
+// ConnectionString formats format with the host:port address of containerPort as its only argument,
+// e.g. ConnectionString("redis://%s/0", 6379).
+func (c *Container) ConnectionString(format string, containerPort int) string {
+	c.t.Helper()
+	return fmt.Sprintf(format, c.Endpoint(containerPort))
+}
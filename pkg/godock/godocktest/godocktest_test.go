@@ -0,0 +1,122 @@
+package godocktest
+
+import (
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerName(t *testing.T) {
+	name := containerName("TestRepository/insert user#01")
+	assert.Regexp(t, regexp.MustCompile(`^godocktest-TestRepository-insert-user-01-[A-Za-z0-9]{8}$`), name)
+	assert.NotEqual(t, name, containerName("TestRepository/insert user#01"))
+
+	long := containerName(strings.Repeat("a", 100))
+	assert.LessOrEqual(t, len(long), len("godocktest-")+48+1+8)
+}
+
+func TestOptions(t *testing.T) {
+	opts := &options{}
+	for _, fn := range []OptionFn{
+		WithName("db"),
+		WithPorts(5432, 8080),
+		WithWait(ForPort(5432), ForLog("ready")),
+		WithStartupTimeout(10 * time.Second),
+		WithPullPolicy(godock.PullPolicyAlways),
+		WithEnv("KEY", "value"),
+		WithCmd("sleep", "infinity"),
+		nil,
+	} {
+		if fn != nil {
+			fn(opts)
+		}
+	}
+	assert.Equal(t, "db", opts.name)
+	assert.Equal(t, []int{5432, 8080}, opts.ports)
+	assert.Len(t, opts.waits, 2)
+	assert.Equal(t, 10*time.Second, opts.startupTimeout)
+	assert.Equal(t, godock.PullPolicyAlways, opts.pullPolicy)
+	assert.Len(t, opts.containerOpts, 2)
+}
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctr := Run(t, godock.DefaultDoctorProbeImage,
+		WithCmd("sh", "-c", "echo booting; sleep 1; echo ready; while true; do nc -l -p 8080 </dev/null; done"),
+		WithPorts(8080),
+		WithWait(ForLog("^ready$"), ForPort(8080)),
+		WithStartupTimeout(30*time.Second),
+	)
+	assert.NotEmpty(t, ctr.ID())
+	assert.Equal(t, "127.0.0.1", ctr.Host(8080))
+	assert.NotZero(t, ctr.MappedPort(8080))
+	assert.Equal(t, "tcp://"+ctr.Endpoint(8080), ctr.ConnectionString("tcp://%s", 8080))
+
+	conn, err := net.DialTimeout("tcp", ctr.Endpoint(8080), 5*time.Second)
+	require.NoError(t, err)
+	conn.Close()
+}
+
+func TestContainerEnv(t *testing.T) {
+	ctr := &Container{Config: container.NewConfig("env")}
+	ctr.Config.SetContainerOptions(
+		containeroptions.Env("POSTGRES_USER", "test"),
+		containeroptions.Env("POSTGRES_DB", "test"),
+		containeroptions.Env("POSTGRES_USER", "app"),
+		containeroptions.Env("EMPTY", ""),
+	)
+	assert.Equal(t, "app", ctr.Env("POSTGRES_USER"))
+	assert.Equal(t, "test", ctr.Env("POSTGRES_DB"))
+	assert.Equal(t, "", ctr.Env("EMPTY"))
+	assert.Equal(t, "", ctr.Env("MISSING"))
+}
+
+func TestFreePort(t *testing.T) {
+	port, err := freePort()
+	require.NoError(t, err)
+	l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	require.NoError(t, err)
+	l.Close()
+}
+
+func TestRedis(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	cache := Redis(t)
+	assert.Equal(t, "redis://"+cache.Addr()+"/0", cache.URL())
+
+	conn, err := net.DialTimeout("tcp", cache.Addr(), 5*time.Second)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("PING\r\n"))
+	require.NoError(t, err)
+	reply := make([]byte, 7)
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	assert.Equal(t, "+PONG\r\n", string(reply))
+}
+
+func TestPostgres(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db := Postgres(t, WithEnv("POSTGRES_DB", "orders"))
+	assert.Equal(t, "orders", db.Database)
+	assert.Equal(t, "postgres://test:test@"+db.Endpoint(5432)+"/orders?sslmode=disable", db.DSN())
+}
@@ -0,0 +1,72 @@
+package godocktest
+
+import (
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+)
+
+// DefaultKafkaImage is the image run by Kafka unless WithImage is set.
+const DefaultKafkaImage = "apache/kafka:3.8.0"
+
+// KafkaContainer is a single-node Kafka broker in KRaft mode started by Kafka.
+type KafkaContainer struct {
+	*Container
+}
+
+/*
+Kafka starts a single-node Kafka broker in KRaft mode, without ZooKeeper, with topic auto-creation enabled.
+
+Kafka clients connect to the address the broker advertises, so the broker must know its host port before
+it starts: Kafka reserves a free port on the loopback interface and publishes the broker on it. This only
+works with a local docker daemon.
+
+Usage example:
+
+	broker := godocktest.Kafka(t)
+	client, err := kgo.NewClient(kgo.SeedBrokers(broker.Brokers()...))
+*/
+func Kafka(t testing.TB, optionFns ...OptionFn) *KafkaContainer {
+	t.Helper()
+	hostPort, err := freePort()
+	if err != nil {
+		t.Fatalf("godocktest: reserve a port for kafka: %v", err)
+	}
+	port := strconv.Itoa(hostPort)
+	defaults := []OptionFn{
+		WithEnv("KAFKA_NODE_ID", "1"),
+		WithEnv("KAFKA_PROCESS_ROLES", "broker,controller"),
+		WithEnv("KAFKA_LISTENERS", "PLAINTEXT://:9092,CONTROLLER://:9093"),
+		WithEnv("KAFKA_ADVERTISED_LISTENERS", "PLAINTEXT://127.0.0.1:"+port),
+		WithEnv("KAFKA_CONTROLLER_LISTENER_NAMES", "CONTROLLER"),
+		WithEnv("KAFKA_LISTENER_SECURITY_PROTOCOL_MAP", "CONTROLLER:PLAINTEXT,PLAINTEXT:PLAINTEXT"),
+		WithEnv("KAFKA_CONTROLLER_QUORUM_VOTERS", "1@localhost:9093"),
+		WithEnv("KAFKA_OFFSETS_TOPIC_REPLICATION_FACTOR", "1"),
+		WithEnv("KAFKA_TRANSACTION_STATE_LOG_REPLICATION_FACTOR", "1"),
+		WithEnv("KAFKA_TRANSACTION_STATE_LOG_MIN_ISR", "1"),
+		WithEnv("KAFKA_GROUP_INITIAL_REBALANCE_DELAY_MS", "0"),
+		WithEnv("KAFKA_AUTO_CREATE_TOPICS_ENABLE", "true"),
+		WithContainerOptions(containeroptions.ExposeTCP("9092")),
+		WithHostOptions(hostoptions.PortBindings("127.0.0.1", port, "9092")),
+		WithWait(ForLog("Kafka Server started"), ForPort(9092)),
+	}
+	return &KafkaContainer{Container: Run(t, DefaultKafkaImage, append(defaults, optionFns...)...)}
+}
+
+// Brokers returns the bootstrap addresses of the broker.
+func (k *KafkaContainer) Brokers() []string {
+	return []string{k.Endpoint(9092)}
+}
+
+// freePort returns a TCP port that is free on the loopback interface.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
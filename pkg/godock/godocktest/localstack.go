@@ -0,0 +1,52 @@
+package godocktest
+
+import "testing"
+
+// DefaultLocalStackImage is the image run by LocalStack unless WithImage is set.
+const DefaultLocalStackImage = "localstack/localstack:3"
+
+// LocalStackContainer is a LocalStack AWS emulator started by LocalStack.
+type LocalStackContainer struct {
+	*Container
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+/*
+LocalStack starts the LocalStack AWS emulator with every service on its edge port, published on a random port.
+Limit the services with WithEnv("SERVICES", "s3,sqs") to start faster. LocalStack accepts any credentials.
+
+Usage example:
+
+	stack := godocktest.LocalStack(t, godocktest.WithEnv("SERVICES", "s3"))
+	cfg, err := config.LoadDefaultConfig(ctx,
+		config.WithRegion(stack.Region),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(stack.AccessKeyID, stack.SecretAccessKey, "")),
+	)
+	s3Client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(stack.EndpointURL())
+		o.UsePathStyle = true
+	})
+*/
+func LocalStack(t testing.TB, optionFns ...OptionFn) *LocalStackContainer {
+	t.Helper()
+	defaults := []OptionFn{
+		WithEnv("AWS_DEFAULT_REGION", "us-east-1"),
+		WithPorts(4566),
+		WithWait(ForLog("Ready."), ForPort(4566)),
+		WithStartupTimeout(2 * DefaultStartupTimeout),
+	}
+	ctr := Run(t, DefaultLocalStackImage, append(defaults, optionFns...)...)
+	return &LocalStackContainer{
+		Container:       ctr,
+		Region:          ctr.Env("AWS_DEFAULT_REGION"),
+		AccessKeyID:     "test",
+		SecretAccessKey: "test",
+	}
+}
+
+// EndpointURL returns the http:// URL of the edge port, to use as the endpoint of every AWS service client.
+func (l *LocalStackContainer) EndpointURL() string {
+	return "http://" + l.Endpoint(4566)
+}
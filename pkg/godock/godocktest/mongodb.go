@@ -0,0 +1,56 @@
+package godocktest
+
+import (
+	"net/url"
+	"testing"
+)
+
+// DefaultMongoDBImage is the image run by MongoDB unless WithImage is set.
+const DefaultMongoDBImage = "mongo:7"
+
+// MongoDBContainer is a MongoDB server started by MongoDB.
+type MongoDBContainer struct {
+	*Container
+	User     string
+	Password string
+}
+
+/*
+MongoDB starts a MongoDB server with the root user "test" and password "test", published on a random port.
+Override the credentials with WithEnv and MONGO_INITDB_ROOT_USERNAME or MONGO_INITDB_ROOT_PASSWORD.
+The server is ready when it accepts TCP connections, which the image allows only after initialization.
+
+Usage example:
+
+	mongo := godocktest.MongoDB(t)
+	client, err := mongo.Connect(options.Client().ApplyURI(mongo.URI()))
+*/
+func MongoDB(t testing.TB, optionFns ...OptionFn) *MongoDBContainer {
+	t.Helper()
+	defaults := []OptionFn{
+		WithEnv("MONGO_INITDB_ROOT_USERNAME", "test"),
+		WithEnv("MONGO_INITDB_ROOT_PASSWORD", "test"),
+		WithPorts(27017),
+		WithWait(ForPort(27017)),
+	}
+	ctr := Run(t, DefaultMongoDBImage, append(defaults, optionFns...)...)
+	return &MongoDBContainer{
+		Container: ctr,
+		User:      ctr.Env("MONGO_INITDB_ROOT_USERNAME"),
+		Password:  ctr.Env("MONGO_INITDB_ROOT_PASSWORD"),
+	}
+}
+
+// URI returns a mongodb:// connection URI, authenticating against the admin database.
+func (m *MongoDBContainer) URI() string {
+	u := url.URL{
+		Scheme: "mongodb",
+		Host:   m.Endpoint(27017),
+		Path:   "/",
+	}
+	if m.User != "" {
+		u.User = url.UserPassword(m.User, m.Password)
+		u.RawQuery = "authSource=admin"
+	}
+	return u.String()
+}
@@ -0,0 +1,54 @@
+package godocktest
+
+import (
+	"fmt"
+	"testing"
+)
+
+// DefaultMySQLImage is the image run by MySQL unless WithImage is set.
+const DefaultMySQLImage = "mysql:8.4"
+
+// MySQLContainer is a MySQL server started by MySQL.
+type MySQLContainer struct {
+	*Container
+	User         string
+	Password     string
+	RootPassword string
+	Database     string
+}
+
+/*
+MySQL starts a MySQL server with the user, password and database "test", published on a random port.
+Override the credentials with WithEnv and MYSQL_USER, MYSQL_PASSWORD, MYSQL_ROOT_PASSWORD or MYSQL_DATABASE.
+The server is ready when it accepts TCP connections, which the image allows only after initialization.
+
+Usage example:
+
+	db := godocktest.MySQL(t)
+	conn, err := sql.Open("mysql", db.DSN())
+*/
+func MySQL(t testing.TB, optionFns ...OptionFn) *MySQLContainer {
+	t.Helper()
+	defaults := []OptionFn{
+		WithEnv("MYSQL_USER", "test"),
+		WithEnv("MYSQL_PASSWORD", "test"),
+		WithEnv("MYSQL_ROOT_PASSWORD", "test"),
+		WithEnv("MYSQL_DATABASE", "test"),
+		WithPorts(3306),
+		WithWait(ForPort(3306)),
+		WithStartupTimeout(2 * DefaultStartupTimeout),
+	}
+	ctr := Run(t, DefaultMySQLImage, append(defaults, optionFns...)...)
+	return &MySQLContainer{
+		Container:    ctr,
+		User:         ctr.Env("MYSQL_USER"),
+		Password:     ctr.Env("MYSQL_PASSWORD"),
+		RootPassword: ctr.Env("MYSQL_ROOT_PASSWORD"),
+		Database:     ctr.Env("MYSQL_DATABASE"),
+	}
+}
+
+// DSN returns a connection string for the database in the format of go-sql-driver/mysql.
+func (m *MySQLContainer) DSN() string {
+	return fmt.Sprintf("%s:%s@tcp(%s)/%s?parseTime=true", m.User, m.Password, m.Endpoint(3306), m.Database)
+}
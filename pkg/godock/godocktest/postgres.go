@@ -0,0 +1,64 @@
+package godocktest
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+// DefaultPostgresImage is the image run by Postgres unless WithImage is set.
+const DefaultPostgresImage = "postgres:16-alpine"
+
+// PostgresContainer is a PostgreSQL server started by Postgres.
+type PostgresContainer struct {
+	*Container
+	User     string
+	Password string
+	Database string
+}
+
+/*
+Postgres starts a PostgreSQL server with the user, password and database "test", published on a random
+port. Override the credentials with WithEnv and POSTGRES_USER, POSTGRES_PASSWORD or POSTGRES_DB.
+The server is ready when it accepts TCP connections, which the image allows only after initialization.
+
+Usage example:
+
+	db := godocktest.Postgres(t)
+	pool, err := pgxpool.New(ctx, db.DSN())
+*/
+func Postgres(t testing.TB, optionFns ...OptionFn) *PostgresContainer {
+	t.Helper()
+	defaults := []OptionFn{
+		WithEnv("POSTGRES_USER", "test"),
+		WithEnv("POSTGRES_PASSWORD", "test"),
+		WithEnv("POSTGRES_DB", "test"),
+		WithPorts(5432),
+		WithWait(ForPort(5432)),
+	}
+	ctr := Run(t, DefaultPostgresImage, append(defaults, optionFns...)...)
+	return &PostgresContainer{
+		Container: ctr,
+		User:      ctr.Env("POSTGRES_USER"),
+		Password:  ctr.Env("POSTGRES_PASSWORD"),
+		Database:  ctr.Env("POSTGRES_DB"),
+	}
+}
+
+// DSN returns a postgres:// connection URL for the database, with TLS disabled.
+func (p *PostgresContainer) DSN() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(p.User, p.Password),
+		Host:     p.Endpoint(5432),
+		Path:     "/" + p.Database,
+		RawQuery: "sslmode=disable",
+	}
+	return u.String()
+}
+
+// KeywordDSN returns a keyword/value connection string for the database, as used by lib/pq.
+func (p *PostgresContainer) KeywordDSN() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+		p.Host(5432), p.MappedPort(5432), p.User, p.Password, p.Database)
+}
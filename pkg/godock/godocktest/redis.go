@@ -0,0 +1,38 @@
+package godocktest
+
+import "testing"
+
+// DefaultRedisImage is the image run by Redis unless WithImage is set.
+const DefaultRedisImage = "redis:7-alpine"
+
+// RedisContainer is a Redis server started by Redis.
+type RedisContainer struct {
+	*Container
+}
+
+/*
+Redis starts a Redis server without authentication, published on a random port.
+
+Usage example:
+
+	cache := godocktest.Redis(t)
+	rdb := redis.NewClient(&redis.Options{Addr: cache.Addr()})
+*/
+func Redis(t testing.TB, optionFns ...OptionFn) *RedisContainer {
+	t.Helper()
+	defaults := []OptionFn{
+		WithPorts(6379),
+		WithWait(ForLog("Ready to accept connections"), ForPort(6379)),
+	}
+	return &RedisContainer{Container: Run(t, DefaultRedisImage, append(defaults, optionFns...)...)}
+}
+
+// Addr returns the host:port address of the server.
+func (r *RedisContainer) Addr() string {
+	return r.Endpoint(6379)
+}
+
+// URL returns a redis:// connection URL for database 0.
+func (r *RedisContainer) URL() string {
+	return "redis://" + r.Addr() + "/0"
+}
@@ -0,0 +1,207 @@
+package godock
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+// ContainerHealth is the health of a container and the results of its recent health checks.
+type ContainerHealth struct {
+	// Status is the health status, NoHealthcheck for a container without a health check.
+	Status HealthStatus
+	// FailingStreak is the number of health checks that failed in a row.
+	FailingStreak int
+	// Probes are the recent health checks, oldest first. The daemon keeps the last five.
+	Probes []HealthProbe
+}
+
+// HealthProbe is the result of a health check.
+type HealthProbe struct {
+	Start    time.Time
+	End      time.Time
+	ExitCode int
+	Output   string
+}
+
+// LastProbe returns the most recent health check, or nil when none has run yet.
+func (h *ContainerHealth) LastProbe() *HealthProbe {
+	if len(h.Probes) == 0 {
+		return nil
+	}
+	return &h.Probes[len(h.Probes)-1]
+}
+
+// HealthTransition is a change of the health status of a container.
+type HealthTransition struct {
+	// From is the previous status, empty for the first transition of a stream.
+	From HealthStatus
+	// To is the new status.
+	To   HealthStatus
+	Time time.Time
+	// Health is the health of the container after the transition, including the check that caused it.
+	Health ContainerHealth
+}
+
+// healthFromState returns the health of a container from its inspected state.
+func healthFromState(state *types.ContainerState) ContainerHealth {
+	if state == nil || state.Health == nil {
+		return ContainerHealth{Status: NoHealthcheck}
+	}
+	health := ContainerHealth{
+		Status:        HealthStatus(state.Health.Status),
+		FailingStreak: state.Health.FailingStreak,
+		Probes:        make([]HealthProbe, 0, len(state.Health.Log)),
+	}
+	for _, result := range state.Health.Log {
+		if result == nil {
+			continue
+		}
+		health.Probes = append(health.Probes, HealthProbe{
+			Start:    result.Start,
+			End:      result.End,
+			ExitCode: result.ExitCode,
+			Output:   result.Output,
+		})
+	}
+	return health
+}
+
+/*
+ContainerHealth returns the current health of a container with its recent health checks. A container without a
+health check has the status NoHealthcheck.
+
+Usage example:
+
+	health, err := client.ContainerHealth(ctx, api)
+	if err != nil {
+		return err
+	}
+	if health.Status == godock.Unhealthy {
+		fmt.Println(health.LastProbe().Output)
+	}
+*/
+func (c *Client) ContainerHealth(ctx context.Context, containerConfig *container.ContainerConfig) (*ContainerHealth, error) {
+	if err := validateContainerConfig(containerConfig); err != nil {
+		return nil, err
+	}
+	inspect, err := c.wrapped.ContainerInspect(ctx, containerConfig.Id)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return nil, &errdefs.ResourceNotFoundError{ResourceType: "container", ID: containerConfig.Id, Err: err}
+		}
+		return nil, &errdefs.ContainerError{ID: containerConfig.Id, Op: "health", Message: err.Error(), Err: err}
+	}
+	var state *types.ContainerState
+	if inspect.ContainerJSONBase != nil {
+		state = inspect.State
+	}
+	health := healthFromState(state)
+	return &health, nil
+}
+
+/*
+ContainerHealthChan streams the health transitions of a container, driven by the events of the daemon. The first
+transition reports the health when the stream started, so no change is missed between checking and watching.
+Both channels are closed when ctx is done, the container is removed or reading the events fails; the error, if
+any, is sent first.
+
+Usage example:
+
+	transitions, errCh := client.ContainerHealthChan(ctx, api)
+	for transition := range transitions {
+		if transition.To == godock.Unhealthy {
+			log.Printf("%s is unhealthy: %s", api.Name, transition.Health.LastProbe().Output)
+		}
+	}
+	if err := <-errCh; err != nil {
+		return err
+	}
+*/
+func (c *Client) ContainerHealthChan(ctx context.Context, containerConfig *container.ContainerConfig) (<-chan HealthTransition, <-chan error) {
+	transitionCh := make(chan HealthTransition)
+	errCh := make(chan error, 1)
+	if err := validateContainerConfig(containerConfig); err != nil {
+		errCh <- err
+		close(transitionCh)
+		close(errCh)
+		return transitionCh, errCh
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	// Events are watched before the current health is read, so a transition in between is not lost.
+	eventCh, eventErrCh := c.wrapped.Events(ctx, events.ListOptions{Filters: filters.NewArgs(
+		filters.Arg("type", string(events.ContainerEventType)),
+		filters.Arg("container", containerConfig.Id),
+		filters.Arg("event", string(events.ActionHealthStatus)),
+		filters.Arg("event", string(events.ActionDestroy)),
+	)})
+	go func() {
+		defer close(errCh)
+		defer close(transitionCh)
+		defer cancel()
+
+		send := func(transition HealthTransition) bool {
+			select {
+			case transitionCh <- transition:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		health, err := c.ContainerHealth(ctx, containerConfig)
+		if err != nil {
+			if ctx.Err() == nil {
+				errCh <- err
+			}
+			return
+		}
+		current := health.Status
+		if !send(HealthTransition{To: current, Time: c.Clock().Now(), Health: *health}) {
+			return
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-eventErrCh:
+				if err != nil && ctx.Err() == nil {
+					errCh <- &errdefs.ContainerError{ID: containerConfig.Id, Op: "watch health", Message: err.Error(), Err: err}
+				}
+				return
+			case event := <-eventCh:
+				if event.Action == events.ActionDestroy {
+					return
+				}
+				status, ok := strings.CutPrefix(string(event.Action), string(events.ActionHealthStatus)+":")
+				if !ok {
+					continue
+				}
+				to := HealthStatus(strings.TrimSpace(status))
+				if to == current {
+					continue
+				}
+				transition := HealthTransition{From: current, To: to, Time: time.Unix(0, event.TimeNano)}
+				if health, err := c.ContainerHealth(ctx, containerConfig); err == nil && health.Status == to {
+					transition.Health = *health
+				} else {
+					// The container changed again or is gone; the event is all there is to report.
+					transition.Health = ContainerHealth{Status: to}
+				}
+				current = to
+				if !send(transition) {
+					return
+				}
+			}
+		}
+	}()
+	return transitionCh, errCh
+}
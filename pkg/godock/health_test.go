@@ -0,0 +1,115 @@
+package godock
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerHealth(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		health = `null`
+	)
+	setHealth := func(h string) {
+		mu.Lock()
+		defer mu.Unlock()
+		health = h
+	}
+	eventCh := make(chan string)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1.45/containers/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message":"No such container: missing"}`)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(w, `{"Id":"c1","State":{"Running":true,"Health":%s}}`, health)
+	})
+	mux.HandleFunc("GET /v1.45/events", func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Query().Get("filters"), `"health_status":true`)
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case action := <-eventCh:
+				fmt.Fprintf(w, `{"Type":"container","Action":%q,"Actor":{"ID":"c1"},"timeNano":%d}`+"\n", action, time.Now().UnixNano())
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	c := newFakeDaemonClient(t, mux)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	app := &container.ContainerConfig{Id: "c1", Name: "app"}
+
+	t.Run("Current health", func(t *testing.T) {
+		setHealth(`{"Status":"unhealthy","FailingStreak":2,"Log":[` +
+			`{"ExitCode":0,"Output":"ok"},{"ExitCode":1,"Output":"connection refused"}]}`)
+		got, err := c.ContainerHealth(ctx, app)
+		require.NoError(t, err)
+		assert.Equal(t, Unhealthy, got.Status)
+		assert.Equal(t, 2, got.FailingStreak)
+		require.Len(t, got.Probes, 2)
+		assert.Equal(t, &HealthProbe{ExitCode: 1, Output: "connection refused"}, got.LastProbe())
+
+		setHealth(`null`)
+		got, err = c.ContainerHealth(ctx, app)
+		require.NoError(t, err)
+		assert.Equal(t, NoHealthcheck, got.Status)
+		assert.Nil(t, got.LastProbe())
+
+		_, err = c.ContainerHealth(ctx, &container.ContainerConfig{Id: "missing"})
+		var notFound *errdefs.ResourceNotFoundError
+		assert.ErrorAs(t, err, &notFound)
+	})
+
+	t.Run("Transitions", func(t *testing.T) {
+		setHealth(`{"Status":"starting"}`)
+		transitions, errCh := c.ContainerHealthChan(ctx, app)
+
+		first := <-transitions
+		assert.Equal(t, HealthStatus(""), first.From)
+		assert.Equal(t, HealthStarting, first.To)
+
+		setHealth(`{"Status":"healthy","Log":[{"ExitCode":0,"Output":"ok"}]}`)
+		eventCh <- "health_status: healthy"
+		healthy := <-transitions
+		assert.Equal(t, HealthStarting, healthy.From)
+		assert.Equal(t, Healthy, healthy.To)
+		assert.Equal(t, "ok", healthy.Health.LastProbe().Output)
+
+		eventCh <- "health_status: healthy"
+		setHealth(`{"Status":"unhealthy","FailingStreak":3,"Log":[{"ExitCode":1,"Output":"timeout"}]}`)
+		eventCh <- "health_status: unhealthy"
+		unhealthy := <-transitions
+		assert.Equal(t, Healthy, unhealthy.From, "repeated statuses are not transitions")
+		assert.Equal(t, Unhealthy, unhealthy.To)
+		assert.Equal(t, 3, unhealthy.Health.FailingStreak)
+
+		eventCh <- "destroy"
+		_, open := <-transitions
+		assert.False(t, open, "the stream ends when the container is removed")
+		assert.NoError(t, <-errCh)
+	})
+
+	t.Run("Invalid config", func(t *testing.T) {
+		transitions, errCh := c.ContainerHealthChan(ctx, nil)
+		assert.True(t, errdefs.IsInvalidConfig(<-errCh))
+		_, open := <-transitions
+		assert.False(t, open)
+	})
+}
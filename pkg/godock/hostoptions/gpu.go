@@ -0,0 +1,103 @@
+package hostoptions
+
+import (
+	"fmt"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/docker/docker/api/types/container"
+)
+
+// gpuCapabilities are the capabilities the daemon matches against GPU device drivers,
+// the same as those requested by the docker CLI's --gpus flag.
+var gpuCapabilities = [][]string{{"gpu"}}
+
+func addDeviceRequest(request container.DeviceRequest) SetHostOptFn {
+	return func(opt *container.HostConfig) {
+		opt.DeviceRequests = append(opt.DeviceRequests, request)
+	}
+}
+
+/*
+AllGPUs gives the container access to every GPU on the host, like docker run --gpus all.
+
+Usage example:
+
+	myContainer := container.NewConfig("trainer")
+	myContainer.SetHostOptions(
+		hostoptions.AllGPUs(),
+	)
+*/
+func AllGPUs() SetHostOptFn {
+	return addDeviceRequest(container.DeviceRequest{
+		Count:        -1,
+		Capabilities: gpuCapabilities,
+	})
+}
+
+/*
+NvidiaGPUs gives the container access to count NVIDIA GPUs, like docker run --gpus count.
+A count of -1 requests all GPUs.
+
+Usage example:
+
+	myContainer := container.NewConfig("trainer")
+	myContainer.SetHostOptions(
+		hostoptions.NvidiaGPUs(2),
+	)
+*/
+func NvidiaGPUs(count int) SetHostOptFn {
+	return addDeviceRequest(container.DeviceRequest{
+		Driver:       "nvidia",
+		Count:        count,
+		Capabilities: gpuCapabilities,
+	})
+}
+
+/*
+GPUByUUID gives the container access to specific NVIDIA GPUs, by UUID or index as reported by nvidia-smi,
+like docker run --gpus '"device=GPU-3a23c669,1"'.
+
+Usage example:
+
+	myContainer := container.NewConfig("trainer")
+	myContainer.SetHostOptions(
+		hostoptions.GPUByUUID("GPU-3a23c669-1f69-c64e-cf85-44e9b07e7a2a"),
+	)
+*/
+func GPUByUUID(ids ...string) SetHostOptFn {
+	return addDeviceRequest(container.DeviceRequest{
+		Driver:       "nvidia",
+		DeviceIDs:    ids,
+		Capabilities: gpuCapabilities,
+	})
+}
+
+// ValidateDeviceRequests checks device requests for combinations the daemon rejects at container start,
+// such as setting both a count and device ids. ContainerCreate runs it before creating the container.
+func ValidateDeviceRequests(requests []container.DeviceRequest) error {
+	for i, request := range requests {
+		switch {
+		case request.Count != 0 && len(request.DeviceIDs) > 0:
+			return &errdefs.ValidationError{
+				Field:   "DeviceRequests",
+				Message: fmt.Sprintf("device request %d sets both a count and device ids, use one or the other", i),
+			}
+		case request.Count < -1:
+			return &errdefs.ValidationError{
+				Field:   "DeviceRequests",
+				Message: fmt.Sprintf("device request %d has count %d, use -1 for all devices", i, request.Count),
+			}
+		case request.Count == 0 && len(request.DeviceIDs) == 0:
+			return &errdefs.ValidationError{
+				Field:   "DeviceRequests",
+				Message: fmt.Sprintf("device request %d requests no devices, set a count or device ids", i),
+			}
+		case request.Driver == "" && len(request.Capabilities) == 0:
+			return &errdefs.ValidationError{
+				Field:   "DeviceRequests",
+				Message: fmt.Sprintf("device request %d sets neither a driver nor capabilities", i),
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package hostoptions
+
+import (
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// ALL refers to every capability, for use with CapDrop and CapAdd.
+const ALL Capability = "ALL"
+
+// DefaultHardenedPidsLimit is the PIDs limit applied by Hardened unless changed with HardenedPidsLimit.
+const DefaultHardenedPidsLimit int64 = 256
+
+// hardenedMaskedPaths are the paths masked by the default docker runtime configuration.
+// Setting MaskedPaths replaces the defaults, so Hardened lists them explicitly.
+var hardenedMaskedPaths = []string{
+	"/proc/asound",
+	"/proc/acpi",
+	"/proc/kcore",
+	"/proc/keys",
+	"/proc/latency_stats",
+	"/proc/timer_list",
+	"/proc/timer_stats",
+	"/proc/sched_debug",
+	"/proc/scsi",
+	"/sys/firmware",
+	"/sys/devices/virtual/powercap",
+}
+
+type hardenedOptions struct {
+	keepCapabilities []Capability
+	writableRootfs   bool
+	newPrivileges    bool
+	unmaskedPaths    bool
+	seccompProfile   string
+	pidsLimit        int64
+	tmpfsTmp         bool
+}
+
+// HardenedOptFn is a function that relaxes one of the settings applied by Hardened.
+type HardenedOptFn func(*hardenedOptions)
+
+// KeepCapabilities adds the given capabilities back after all others are dropped,
+// e.g. NET_BIND_SERVICE for a server listening on a port below 1024.
+func KeepCapabilities(caps ...Capability) HardenedOptFn {
+	return func(opts *hardenedOptions) {
+		opts.keepCapabilities = append(opts.keepCapabilities, caps...)
+	}
+}
+
+// WritableRootfs leaves the root filesystem writable.
+func WritableRootfs() HardenedOptFn {
+	return func(opts *hardenedOptions) {
+		opts.writableRootfs = true
+	}
+}
+
+// AllowNewPrivileges leaves no-new-privileges unset, so setuid binaries such as sudo keep working.
+func AllowNewPrivileges() HardenedOptFn {
+	return func(opts *hardenedOptions) {
+		opts.newPrivileges = true
+	}
+}
+
+// KeepDefaultMaskedPaths leaves MaskedPaths to the daemon instead of setting the hardened list.
+func KeepDefaultMaskedPaths() HardenedOptFn {
+	return func(opts *hardenedOptions) {
+		opts.unmaskedPaths = true
+	}
+}
+
+// SeccompProfile applies a custom seccomp profile instead of the daemon's default one.
+// The profile is the JSON content of the profile, not a path.
+func SeccompProfile(profile string) HardenedOptFn {
+	return func(opts *hardenedOptions) {
+		opts.seccompProfile = profile
+	}
+}
+
+// HardenedPidsLimit sets the PIDs limit applied by Hardened. A limit of 0 leaves the PIDs limit unset.
+func HardenedPidsLimit(limit int64) HardenedOptFn {
+	return func(opts *hardenedOptions) {
+		opts.pidsLimit = limit
+	}
+}
+
+// WithoutTmpfsTmp does not mount a tmpfs on /tmp when the root filesystem is read-only.
+func WithoutTmpfsTmp() HardenedOptFn {
+	return func(opts *hardenedOptions) {
+		opts.tmpfsTmp = false
+	}
+}
+
+/*
+Hardened applies a set of security settings suited to most application containers:
+
+1. All capabilities are dropped; use KeepCapabilities to add back the ones the application needs.
+
+2. no-new-privileges is set, so processes cannot gain privileges through setuid binaries.
+
+3. The root filesystem is read-only, with a small noexec tmpfs mounted on /tmp.
+
+4. Sensitive /proc and /sys paths are masked.
+
+5. The daemon's default seccomp profile is enforced; any seccomp=unconfined option is removed.
+
+6. The number of processes is limited to DefaultHardenedPidsLimit.
+
+Privileged mode is turned off. Each setting can be relaxed with a HardenedOptFn.
+
+Usage example:
+
+	myContainer := container.NewConfig("web")
+	myContainer.SetHostOptions(
+		hostoptions.Hardened(
+			hostoptions.KeepCapabilities(hostoptions.NET_BIND_SERVICE),
+			hostoptions.HardenedPidsLimit(512),
+		),
+	)
+*/
+func Hardened(hardenedOptFns ...HardenedOptFn) SetHostOptFn {
+	opts := &hardenedOptions{
+		pidsLimit: DefaultHardenedPidsLimit,
+		tmpfsTmp:  true,
+	}
+	for _, fn := range hardenedOptFns {
+		if fn != nil {
+			fn(opts)
+		}
+	}
+	return func(opt *container.HostConfig) {
+		opt.Privileged = false
+
+		CapDrop(ALL)(opt)
+		if len(opts.keepCapabilities) > 0 {
+			CapAdd(opts.keepCapabilities...)(opt)
+		}
+
+		if !opts.newPrivileges {
+			NoNewPrivileges()(opt)
+		}
+
+		if !opts.writableRootfs {
+			ReadonlyRootfs()(opt)
+			if opts.tmpfsTmp {
+				if _, ok := opt.Tmpfs["/tmp"]; !ok {
+					Tmpfs("/tmp", "rw,noexec,nosuid,size=64m")(opt)
+				}
+			}
+		}
+
+		if !opts.unmaskedPaths {
+			opt.MaskedPaths = append([]string(nil), hardenedMaskedPaths...)
+		}
+
+		securityOpts := make([]string, 0, len(opt.SecurityOpt)+1)
+		for _, securityOpt := range opt.SecurityOpt {
+			if strings.HasPrefix(securityOpt, "seccomp=") || strings.HasPrefix(securityOpt, "seccomp:") {
+				continue
+			}
+			securityOpts = append(securityOpts, securityOpt)
+		}
+		if opts.seccompProfile != "" {
+			securityOpts = append(securityOpts, "seccomp="+opts.seccompProfile)
+		}
+		opt.SecurityOpt = securityOpts
+
+		if opts.pidsLimit > 0 {
+			limit := opts.pidsLimit
+			opt.PidsLimit = &limit
+		}
+	}
+}
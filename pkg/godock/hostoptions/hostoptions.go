@@ -1,7 +1,7 @@
 package hostoptions
 
 import (
-	"log"
+	"math"
 	"runtime"
 	"strings"
 
@@ -207,6 +207,9 @@ Valid restart policy options:
 - "no" (default policy)
 - "on-failure"[:maxRetryCount]
 - "always"
+- "unless-stopped"
+
+Any other mode falls back to "no".
 
 Usage example:
 
@@ -229,9 +232,8 @@ func RestartPolicy(mode string, maxRetryCount int) SetHostOptFn {
 		policyMode = container.RestartPolicyOnFailure
 	case "always":
 		policyMode = container.RestartPolicyAlways
-	default:
-		log.Printf("%s is not a valid policy defaulting to RestartPolicyDisabled aka 'no'", mode)
-		policyMode = container.RestartPolicyDisabled
+	case "unless-stopped":
+		policyMode = container.RestartPolicyUnlessStopped
 	}
 	return func(opt *container.HostConfig) {
 		opt.RestartPolicy = container.RestartPolicy{
@@ -315,6 +317,9 @@ Usage example:
 This function allows you to specify port mappings for forwarding traffic between the host and the container.
 You can map a specific host IP address and port to a container port. The host IP can be "0.0.0.0" to bind to all available interfaces.
 
+A container port without a protocol is bound as TCP, so "80" and "80/tcp" are the same port,
+matching the keys used by containeroptions.Expose.
+
 Note: Each call to this function adds a port mapping configuration to the host configuration.
 */
 func PortBindings(hostIP, hostPort, containerPort string) SetHostOptFn {
@@ -322,7 +327,7 @@ func PortBindings(hostIP, hostPort, containerPort string) SetHostOptFn {
 		if opt.PortBindings == nil {
 			opt.PortBindings = make(nat.PortMap)
 		}
-		opt.PortBindings[nat.Port(containerPort)] = []nat.PortBinding{
+		opt.PortBindings[normalizePort(containerPort)] = []nat.PortBinding{
 			{
 				HostIP:   hostIP,
 				HostPort: hostPort,
@@ -331,6 +336,16 @@ func PortBindings(hostIP, hostPort, containerPort string) SetHostOptFn {
 	}
 }
 
+// normalizePort returns the port/protocol key the daemon uses for a port, defaulting the protocol to tcp.
+func normalizePort(containerPort string) nat.Port {
+	proto, port := nat.SplitProtoPort(containerPort)
+	normalized, err := nat.NewPort(strings.ToLower(proto), port)
+	if err != nil {
+		return nat.Port(containerPort)
+	}
+	return normalized
+}
+
 /*
 MountType is constant for the type of mount
 
@@ -839,7 +854,7 @@ IPC sharing mode for the container. Possible values are:
 	"none"
 	"private"
 	"shareable"
-	"container"
+	"container:<name|id>"
 	"host"
 
 	myContainer := container.NewConfig("my_container")
@@ -848,8 +863,9 @@ IPC sharing mode for the container. Possible values are:
 	)
 */
 func IpcMode(mode string) SetHostOptFn {
-	switch mode {
-	case "none", "private", "shareable", "container", "host":
+	switch {
+	case mode == "none", mode == "private", mode == "shareable", mode == "container", mode == "host",
+		strings.HasPrefix(mode, "container:"):
 		return func(opt *container.HostConfig) {
 			opt.IpcMode = container.IpcMode(mode)
 		}
@@ -1107,6 +1123,30 @@ func CPUQuota(quota int64) SetHostOptFn {
 	}
 }
 
+/*
+CPUs limits the container to a number of CPUs, like docker run --cpus.
+Fractions are allowed, e.g. 1.5 lets the container use one and a half CPUs.
+It sets NanoCPUs and clears CPUPeriod and CPUQuota, which cannot be combined with it.
+A value of 0 or less removes the limit.
+
+Usage example:
+
+	myContainer := container.NewConfig("my_container")
+	myContainer.SetHostOptions(
+		hostoptions.CPUs(1.5),
+	)
+*/
+func CPUs(cpus float64) SetHostOptFn {
+	return func(opt *container.HostConfig) {
+		opt.NanoCPUs = 0
+		if cpus > 0 {
+			opt.NanoCPUs = int64(math.Round(cpus * 1e9))
+		}
+		opt.CPUPeriod = 0
+		opt.CPUQuota = 0
+	}
+}
+
 /*
 CpusetCpus sets the CPUs in which execution is allowed
 */
@@ -1,9 +1,12 @@
 package hostoptions
 
 import (
+	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/go-connections/nat"
@@ -46,6 +49,7 @@ func TestRestartPolicy(t *testing.T) {
 		{"No restart", "no", 0, "no"},
 		{"Always restart", "always", 0, "always"},
 		{"On-failure restart", "on-failure", 5, "on-failure"},
+		{"Unless-stopped restart", "unless-stopped", 0, "unless-stopped"},
 		{"Invalid mode", "invalid", 0, "no"},
 		{"Empty mode", "", 0, "no"},
 	}
@@ -101,6 +105,16 @@ func TestCPUSettings(t *testing.T) {
 	CPUQuota(50000)(hostConfig)
 	assert.Equal(t, int64(50000), hostConfig.CPUQuota)
 
+	// Test fractional CPUs replace the period and quota
+	CPUs(1.5)(hostConfig)
+	assert.Equal(t, int64(1_500_000_000), hostConfig.NanoCPUs)
+	assert.Zero(t, hostConfig.CPUPeriod)
+	assert.Zero(t, hostConfig.CPUQuota)
+	CPUs(0.001)(hostConfig)
+	assert.Equal(t, int64(1_000_000), hostConfig.NanoCPUs)
+	CPUs(0)(hostConfig)
+	assert.Zero(t, hostConfig.NanoCPUs)
+
 	// Test CPUset CPUs
 	CpusetCpus("0-3")(hostConfig)
 	assert.Equal(t, "0-3", hostConfig.CpusetCpus)
@@ -139,7 +153,7 @@ func TestPortBindings(t *testing.T) {
 
 	// Test single port binding
 	PortBindings("127.0.0.1", "8080", "80")(hostConfig)
-	binding := hostConfig.PortBindings[nat.Port("80")]
+	binding := hostConfig.PortBindings[nat.Port("80/tcp")]
 	assert.Len(t, binding, 1)
 	assert.Equal(t, "127.0.0.1", binding[0].HostIP)
 	assert.Equal(t, "8080", binding[0].HostPort)
@@ -147,9 +161,13 @@ func TestPortBindings(t *testing.T) {
 	// Test multiple port bindings
 	PortBindings("0.0.0.0", "9090", "90")(hostConfig)
 	assert.Len(t, hostConfig.PortBindings, 2)
-	binding = hostConfig.PortBindings[nat.Port("90")]
+	binding = hostConfig.PortBindings[nat.Port("90/tcp")]
 	assert.Equal(t, "0.0.0.0", binding[0].HostIP)
 	assert.Equal(t, "9090", binding[0].HostPort)
+
+	// Test protocols are kept and normalized
+	PortBindings("0.0.0.0", "5353", "53/UDP")(hostConfig)
+	assert.Contains(t, hostConfig.PortBindings, nat.Port("53/udp"))
 }
 
 func TestMountSettings(t *testing.T) {
@@ -379,6 +397,9 @@ func TestNamespaceSettings(t *testing.T) {
 	IpcMode("host")(hostConfig)
 	assert.Equal(t, container.IpcMode("host"), hostConfig.IpcMode)
 
+	IpcMode("container:test")(hostConfig)
+	assert.Equal(t, container.IpcMode("container:test"), hostConfig.IpcMode)
+
 	// Test invalid IpcMode
 	IpcMode("invalid")(hostConfig)
 	assert.Equal(t, container.IpcMode("private"), hostConfig.IpcMode)
@@ -597,3 +618,166 @@ func TestNoNewPrivilegesExtended(t *testing.T) {
 	assert.Equal(t, 2, len(hostConfig.SecurityOpt), "Should not add duplicate no-new-privileges")
 	assert.Contains(t, hostConfig.SecurityOpt, "no-new-privileges")
 }
+
+func TestMountAdvanced(t *testing.T) {
+	hostConfig := &container.HostConfig{}
+
+	MountAdvanced(MountTypeBind, "/mnt/media", "/media",
+		BindPropagation(PropagationRSlave),
+		BindCreateMountpoint(),
+		MountReadOnly(),
+		TmpfsSize(1024),
+	)(hostConfig)
+	MountAdvanced(MountTypeVolume, "app-data", "/etc/app",
+		VolumeSubpath("config"),
+		VolumeNoCopy(),
+		VolumeLabels(map[string]string{"app": "web"}),
+		MountConsistency(ConsistencyCached),
+	)(hostConfig)
+	MountAdvanced(MountTypeTmpfs, "", "/scratch",
+		TmpfsSize(64*1024*1024),
+		TmpfsMode(01777),
+	)(hostConfig)
+
+	assert.Len(t, hostConfig.Mounts, 3)
+
+	bind := hostConfig.Mounts[0]
+	assert.True(t, bind.ReadOnly)
+	assert.Equal(t, &mount.BindOptions{Propagation: mount.PropagationRSlave, CreateMountpoint: true}, bind.BindOptions)
+	assert.Nil(t, bind.TmpfsOptions, "tmpfs setters are ignored on bind mounts")
+
+	vol := hostConfig.Mounts[1]
+	assert.Equal(t, mount.TypeVolume, vol.Type)
+	assert.Equal(t, mount.ConsistencyCached, vol.Consistency)
+	assert.Equal(t, &mount.VolumeOptions{NoCopy: true, Subpath: "config", Labels: map[string]string{"app": "web"}}, vol.VolumeOptions)
+
+	tmpfs := hostConfig.Mounts[2]
+	assert.Equal(t, &mount.TmpfsOptions{SizeBytes: 64 * 1024 * 1024, Mode: 01777}, tmpfs.TmpfsOptions)
+}
+
+func TestMountValidated(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := MountValidated(filepath.Join(dir, "missing"), "/data", false, RequireSource())
+	assert.True(t, errdefs.IsInvalidConfig(err))
+
+	source := filepath.Join(dir, "data", "mongodb")
+	opt, err := MountValidated(source, "/data/db", true, CreateSource(0o750))
+	assert.NoError(t, err)
+	info, err := os.Stat(source)
+	assert.NoError(t, err)
+	assert.True(t, info.IsDir())
+
+	hostConfig := &container.HostConfig{}
+	opt(hostConfig)
+	assert.Equal(t, []mount.Mount{{Type: mount.TypeBind, Source: source, Target: "/data/db", ReadOnly: true}}, hostConfig.Mounts)
+
+	_, err = MountValidated(source, "/data/db", false, RequireSource())
+	assert.NoError(t, err)
+}
+
+func TestGPUPresets(t *testing.T) {
+	hostConfig := &container.HostConfig{}
+	AllGPUs()(hostConfig)
+	NvidiaGPUs(2)(hostConfig)
+	GPUByUUID("GPU-3a23c669", "1")(hostConfig)
+
+	assert.Equal(t, []container.DeviceRequest{
+		{Count: -1, Capabilities: [][]string{{"gpu"}}},
+		{Driver: "nvidia", Count: 2, Capabilities: [][]string{{"gpu"}}},
+		{Driver: "nvidia", DeviceIDs: []string{"GPU-3a23c669", "1"}, Capabilities: [][]string{{"gpu"}}},
+	}, hostConfig.DeviceRequests)
+	assert.NoError(t, ValidateDeviceRequests(hostConfig.DeviceRequests))
+}
+
+func TestValidateDeviceRequests(t *testing.T) {
+	for name, request := range map[string]container.DeviceRequest{
+		"count and ids": {Driver: "nvidia", Count: 1, DeviceIDs: []string{"0"}},
+		"bad count":     {Driver: "nvidia", Count: -2},
+		"no devices":    {Driver: "nvidia"},
+		"no driver":     {Count: 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateDeviceRequests([]container.DeviceRequest{request})
+			assert.True(t, errdefs.IsInvalidConfig(err))
+		})
+	}
+}
+
+func TestHardened(t *testing.T) {
+	hostConfig := &container.HostConfig{
+		Privileged:  true,
+		SecurityOpt: []string{"label:disable", "seccomp=unconfined"},
+	}
+	Hardened()(hostConfig)
+
+	assert.False(t, hostConfig.Privileged)
+	assert.Equal(t, []string{"ALL"}, []string(hostConfig.CapDrop))
+	assert.Empty(t, hostConfig.CapAdd)
+	assert.Equal(t, []string{"label:disable", "no-new-privileges"}, hostConfig.SecurityOpt)
+	assert.True(t, hostConfig.ReadonlyRootfs)
+	assert.Contains(t, hostConfig.Tmpfs, "/tmp")
+	assert.Contains(t, hostConfig.MaskedPaths, "/proc/kcore")
+	assert.Equal(t, DefaultHardenedPidsLimit, *hostConfig.PidsLimit)
+
+	hostConfig = &container.HostConfig{}
+	Hardened(
+		KeepCapabilities(NET_BIND_SERVICE),
+		WritableRootfs(),
+		AllowNewPrivileges(),
+		KeepDefaultMaskedPaths(),
+		SeccompProfile(`{"defaultAction":"SCMP_ACT_ALLOW"}`),
+		HardenedPidsLimit(0),
+	)(hostConfig)
+
+	assert.Equal(t, []string{"NET_BIND_SERVICE"}, []string(hostConfig.CapAdd))
+	assert.False(t, hostConfig.ReadonlyRootfs)
+	assert.Empty(t, hostConfig.Tmpfs)
+	assert.Nil(t, hostConfig.MaskedPaths)
+	assert.Equal(t, []string{`seccomp={"defaultAction":"SCMP_ACT_ALLOW"}`}, hostConfig.SecurityOpt)
+	assert.Nil(t, hostConfig.PidsLimit)
+}
+
+func TestSizeStrings(t *testing.T) {
+	hostConfig := &container.HostConfig{}
+
+	memory, err := MemoryString("512m")
+	assert.NoError(t, err)
+	memory(hostConfig)
+	assert.Equal(t, int64(512*1024*1024), hostConfig.Memory)
+
+	reservation, err := MemoryReservationString("1.5g")
+	assert.NoError(t, err)
+	reservation(hostConfig)
+	assert.Equal(t, int64(1536*1024*1024), hostConfig.MemoryReservation)
+
+	shm, err := ShmSizeString("64MB")
+	assert.NoError(t, err)
+	shm(hostConfig)
+	assert.Equal(t, int64(64*1024*1024), hostConfig.ShmSize)
+
+	for _, size := range []string{"", "lots", "12x", "-1g"} {
+		_, err := MemoryString(size)
+		assert.True(t, errdefs.IsInvalidConfig(err), size)
+	}
+}
+
+func TestAnnotationsAndRuntimes(t *testing.T) {
+	hostConfig := &container.HostConfig{}
+	Annotation("com.example.team", "platform")(hostConfig)
+	Annotations(map[string]string{"com.example.owner": "aptd3v"})(hostConfig)
+	assert.Equal(t, map[string]string{
+		"com.example.team":  "platform",
+		"com.example.owner": "aptd3v",
+	}, hostConfig.Annotations)
+
+	gvisor := &container.HostConfig{}
+	GVisor(map[string]string{"network": "host"})(gvisor)
+	assert.Equal(t, RuntimeGVisor, gvisor.Runtime)
+	assert.Equal(t, "host", gvisor.Annotations["dev.gvisor.flag.network"])
+
+	kata := &container.HostConfig{}
+	Kata(map[string]string{"hypervisor.default_vcpus": "2"})(kata)
+	assert.Equal(t, RuntimeKata, kata.Runtime)
+	assert.Equal(t, "2", kata.Annotations["io.katacontainers.config.hypervisor.default_vcpus"])
+}
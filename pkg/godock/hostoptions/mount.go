@@ -0,0 +1,257 @@
+package hostoptions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+)
+
+// Mount types accepted by Mount and MountAdvanced.
+const (
+	MountTypeBind   = MountType(mount.TypeBind)
+	MountTypeVolume = MountType(mount.TypeVolume)
+	MountTypeTmpfs  = MountType(mount.TypeTmpfs)
+)
+
+// MountOptFn is a function that configures a single mount added with MountAdvanced.
+type MountOptFn func(m *mount.Mount)
+
+// Propagation is the mount propagation mode of a bind mount.
+type Propagation = mount.Propagation
+
+// Bind mount propagation modes.
+const (
+	PropagationPrivate  = mount.PropagationPrivate
+	PropagationRPrivate = mount.PropagationRPrivate
+	PropagationShared   = mount.PropagationShared
+	PropagationRShared  = mount.PropagationRShared
+	PropagationSlave    = mount.PropagationSlave
+	PropagationRSlave   = mount.PropagationRSlave
+)
+
+// Consistency is the consistency requirement of a mount, used by Docker Desktop on macOS.
+type Consistency = mount.Consistency
+
+// Mount consistency requirements.
+const (
+	ConsistencyFull      = mount.ConsistencyFull
+	ConsistencyCached    = mount.ConsistencyCached
+	ConsistencyDelegated = mount.ConsistencyDelegated
+)
+
+/*
+MountAdvanced adds a mount configured with typed setters, for settings that Mount does not cover.
+Setters for another mount type are ignored, e.g. TmpfsSize on a bind mount.
+
+Usage example:
+
+	myContainer := container.NewConfig("my_container")
+	myContainer.SetHostOptions(
+		hostoptions.MountAdvanced(hostoptions.MountTypeBind, "/mnt/media", "/media",
+			hostoptions.BindPropagation(hostoptions.PropagationRSlave),
+			hostoptions.MountReadOnly(),
+		),
+		hostoptions.MountAdvanced(hostoptions.MountTypeVolume, "app-data", "/etc/app",
+			hostoptions.VolumeSubpath("config"),
+			hostoptions.VolumeNoCopy(),
+		),
+		hostoptions.MountAdvanced(hostoptions.MountTypeTmpfs, "", "/scratch",
+			hostoptions.TmpfsSize(64*1024*1024),
+			hostoptions.TmpfsMode(01777),
+		),
+	)
+*/
+func MountAdvanced(mountType MountType, source, target string, mountOptFns ...MountOptFn) SetHostOptFn {
+	return func(opt *container.HostConfig) {
+		m := mount.Mount{
+			Type:   mount.Type(mountType),
+			Source: source,
+			Target: target,
+		}
+		for _, fn := range mountOptFns {
+			if fn != nil {
+				fn(&m)
+			}
+		}
+		switch m.Type {
+		case mount.TypeBind:
+			m.VolumeOptions, m.TmpfsOptions = nil, nil
+		case mount.TypeVolume:
+			m.BindOptions, m.TmpfsOptions = nil, nil
+		case mount.TypeTmpfs:
+			m.BindOptions, m.VolumeOptions = nil, nil
+		}
+		opt.Mounts = append(opt.Mounts, m)
+	}
+}
+
+// MountReadOnly mounts the source read-only.
+func MountReadOnly() MountOptFn {
+	return func(m *mount.Mount) {
+		m.ReadOnly = true
+	}
+}
+
+// MountConsistency sets the consistency requirement of the mount. It only has an effect on Docker Desktop for macOS.
+func MountConsistency(consistency Consistency) MountOptFn {
+	return func(m *mount.Mount) {
+		m.Consistency = consistency
+	}
+}
+
+func bindOptions(m *mount.Mount) *mount.BindOptions {
+	if m.BindOptions == nil {
+		m.BindOptions = &mount.BindOptions{}
+	}
+	return m.BindOptions
+}
+
+func volumeOptions(m *mount.Mount) *mount.VolumeOptions {
+	if m.VolumeOptions == nil {
+		m.VolumeOptions = &mount.VolumeOptions{}
+	}
+	return m.VolumeOptions
+}
+
+func tmpfsOptions(m *mount.Mount) *mount.TmpfsOptions {
+	if m.TmpfsOptions == nil {
+		m.TmpfsOptions = &mount.TmpfsOptions{}
+	}
+	return m.TmpfsOptions
+}
+
+// BindPropagation sets the propagation mode of a bind mount, e.g. PropagationRShared so that
+// mounts made inside the container appear on the host.
+func BindPropagation(propagation Propagation) MountOptFn {
+	return func(m *mount.Mount) {
+		bindOptions(m).Propagation = propagation
+	}
+}
+
+// BindNonRecursive does not bind mount the mounts below the source directory.
+func BindNonRecursive() MountOptFn {
+	return func(m *mount.Mount) {
+		bindOptions(m).NonRecursive = true
+	}
+}
+
+// BindCreateMountpoint creates the source directory on the host when it does not exist, instead of failing.
+func BindCreateMountpoint() MountOptFn {
+	return func(m *mount.Mount) {
+		bindOptions(m).CreateMountpoint = true
+	}
+}
+
+// VolumeNoCopy does not populate a new volume with the content of the image at the mount target.
+func VolumeNoCopy() MountOptFn {
+	return func(m *mount.Mount) {
+		volumeOptions(m).NoCopy = true
+	}
+}
+
+// VolumeSubpath mounts only the given path within the volume. The path must exist in the volume.
+// It requires API version 1.45 or later.
+func VolumeSubpath(subpath string) MountOptFn {
+	return func(m *mount.Mount) {
+		volumeOptions(m).Subpath = subpath
+	}
+}
+
+// VolumeLabels sets the labels of a volume that is created by the mount.
+func VolumeLabels(labels map[string]string) MountOptFn {
+	return func(m *mount.Mount) {
+		opts := volumeOptions(m)
+		if opts.Labels == nil {
+			opts.Labels = make(map[string]string)
+		}
+		for k, v := range labels {
+			opts.Labels[k] = v
+		}
+	}
+}
+
+// VolumeDriverConfig sets the driver and driver options of a volume that is created by the mount.
+func VolumeDriverConfig(driver string, options map[string]string) MountOptFn {
+	return func(m *mount.Mount) {
+		volumeOptions(m).DriverConfig = &mount.Driver{
+			Name:    driver,
+			Options: options,
+		}
+	}
+}
+
+// TmpfsSize sets the size limit of a tmpfs mount in bytes. Without it the tmpfs is unlimited.
+func TmpfsSize(sizeBytes int64) MountOptFn {
+	return func(m *mount.Mount) {
+		tmpfsOptions(m).SizeBytes = sizeBytes
+	}
+}
+
+// TmpfsMode sets the permissions of the tmpfs mount point in octal unix form, e.g. 01777 for a
+// world-writable directory with the sticky bit set, as the daemon passes the value to the kernel as is.
+// The default is 01777.
+func TmpfsMode(mode os.FileMode) MountOptFn {
+	return func(m *mount.Mount) {
+		tmpfsOptions(m).Mode = mode
+	}
+}
+
+// SourcePolicy controls how MountValidated treats a missing bind mount source.
+type SourcePolicy struct {
+	create bool
+	mode   os.FileMode
+}
+
+// RequireSource fails when the bind mount source does not exist.
+func RequireSource() SourcePolicy {
+	return SourcePolicy{}
+}
+
+// CreateSource creates the bind mount source directory, and any missing parents, with the given mode
+// when it does not exist.
+func CreateSource(mode os.FileMode) SourcePolicy {
+	return SourcePolicy{create: true, mode: mode}
+}
+
+/*
+MountValidated checks a bind mount source on the local file system while the container is configured,
+instead of when the daemon starts the container, and returns the bind mount option. The source is
+made absolute. A missing source is created or reported as an *errdefs.ValidationError depending on the policy.
+Only use it when the daemon runs on the same host, since bind mount sources are paths on the daemon host.
+
+Usage example:
+
+	dataMount, err := hostoptions.MountValidated("./data/mongodb", "/data/db", false,
+		hostoptions.CreateSource(0o755),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	myContainer.SetHostOptions(dataMount)
+*/
+func MountValidated(source, target string, readOnly bool, policy SourcePolicy, mountOptFns ...MountOptFn) (SetHostOptFn, error) {
+	abs, err := filepath.Abs(source)
+	if err != nil {
+		return nil, &errdefs.ValidationError{Field: "source", Message: err.Error()}
+	}
+	if _, err := os.Stat(abs); err != nil {
+		switch {
+		case os.IsNotExist(err) && policy.create:
+			if err := os.MkdirAll(abs, policy.mode); err != nil {
+				return nil, &errdefs.ValidationError{Field: "source", Message: fmt.Sprintf("creating bind mount source: %v", err)}
+			}
+		case os.IsNotExist(err):
+			return nil, &errdefs.ValidationError{Field: "source", Message: fmt.Sprintf("bind mount source %s does not exist", abs)}
+		default:
+			return nil, &errdefs.ValidationError{Field: "source", Message: err.Error()}
+		}
+	}
+	if readOnly {
+		mountOptFns = append(mountOptFns, MountReadOnly())
+	}
+	return MountAdvanced(MountTypeBind, abs, target, mountOptFns...), nil
+}
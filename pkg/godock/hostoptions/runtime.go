@@ -0,0 +1,109 @@
+package hostoptions
+
+import "github.com/docker/docker/api/types/container"
+
+// Names of common OCI runtimes, as registered with the daemon in the default configuration of each runtime.
+const (
+	// RuntimeRunc is the default runtime.
+	RuntimeRunc = "runc"
+	// RuntimeGVisor runs the container in the gVisor user-space kernel.
+	RuntimeGVisor = "runsc"
+	// RuntimeKata runs the container in a lightweight virtual machine with Kata Containers.
+	RuntimeKata = "io.containerd.kata.v2"
+)
+
+// Annotation key prefixes read by alternative runtimes.
+const (
+	gvisorFlagPrefix = "dev.gvisor.flag."
+	kataConfigPrefix = "io.katacontainers.config."
+)
+
+/*
+Annotation adds an OCI annotation to the container. Annotations are passed to the runtime in the OCI spec,
+where alternative runtimes and admission tooling read them. They require API version 1.43 or later,
+ContainerCreate fails on older daemons instead of silently dropping them.
+
+Usage example:
+
+	myContainer := container.NewConfig("my_container")
+	myContainer.SetHostOptions(
+		hostoptions.Annotation("org.opencontainers.image.source", "https://github.com/aptd3v/godock"),
+	)
+*/
+func Annotation(key, value string) SetHostOptFn {
+	return func(opt *container.HostConfig) {
+		if opt.Annotations == nil {
+			opt.Annotations = make(map[string]string)
+		}
+		opt.Annotations[key] = value
+	}
+}
+
+/*
+Annotations adds every entry of a map as an OCI annotation.
+
+Usage example:
+
+	myContainer := container.NewConfig("my_container")
+	myContainer.SetHostOptions(
+		hostoptions.Annotations(map[string]string{
+			"com.example.team":  "platform",
+			"com.example.owner": "aptd3v",
+		}),
+	)
+*/
+func Annotations(annotations map[string]string) SetHostOptFn {
+	return func(opt *container.HostConfig) {
+		for key, value := range annotations {
+			Annotation(key, value)(opt)
+		}
+	}
+}
+
+/*
+GVisor runs the container with the gVisor runtime, and sets runsc flags for this container only.
+Flags are passed as dev.gvisor.flag annotations, which runsc applies only when it is configured
+with --allow-flag-override.
+
+Usage example:
+
+	myContainer := container.NewConfig("sandboxed")
+	myContainer.SetHostOptions(
+		hostoptions.GVisor(map[string]string{
+			"network":  "host",
+			"platform": "kvm",
+		}),
+	)
+*/
+func GVisor(flags map[string]string) SetHostOptFn {
+	return func(opt *container.HostConfig) {
+		Runtime(RuntimeGVisor)(opt)
+		for name, value := range flags {
+			Annotation(gvisorFlagPrefix+name, value)(opt)
+		}
+	}
+}
+
+/*
+Kata runs the container with the Kata Containers runtime, and sets Kata configuration for this
+container only. Keys are relative to io.katacontainers.config, e.g. "hypervisor.default_vcpus";
+Kata applies only the keys enabled by enable_annotations in its configuration.
+
+Usage example:
+
+	myContainer := container.NewConfig("isolated")
+	myContainer.SetHostOptions(
+		hostoptions.Kata(map[string]string{
+			"hypervisor.default_vcpus":  "2",
+			"hypervisor.default_memory": "2048",
+		}),
+	)
+*/
+func Kata(config map[string]string) SetHostOptFn {
+	return func(opt *container.HostConfig) {
+		Runtime(RuntimeKata)(opt)
+		for key, value := range config {
+			Annotation(kataConfigPrefix+key, value)(opt)
+		}
+	}
+}
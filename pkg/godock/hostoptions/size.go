@@ -0,0 +1,86 @@
+package hostoptions
+
+import (
+	"fmt"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/docker/go-units"
+)
+
+// parseSize parses a human-readable size such as "512m" or "2g" the way the docker CLI does,
+// with binary units: "1k" is 1024 bytes.
+func parseSize(field, size string) (int64, error) {
+	bytes, err := units.RAMInBytes(size)
+	if err != nil {
+		return 0, &errdefs.ValidationError{
+			Field:   field,
+			Message: fmt.Sprintf("invalid size %q, expected a number with an optional unit such as 512m or 2g", size),
+		}
+	}
+	if bytes < 0 {
+		return 0, &errdefs.ValidationError{
+			Field:   field,
+			Message: fmt.Sprintf("size %q cannot be negative", size),
+		}
+	}
+	return bytes, nil
+}
+
+/*
+MemoryString sets the memory limit of the container from a human-readable size, like docker run --memory.
+Units are b, k, m, g, t and p, and are binary: "1g" is 1024 * 1024 * 1024 bytes.
+
+Usage example:
+
+	memory, err := hostoptions.MemoryString("512m")
+	if err != nil {
+		log.Fatal(err)
+	}
+	myContainer.SetHostOptions(memory)
+*/
+func MemoryString(size string) (SetHostOptFn, error) {
+	memory, err := parseSize("Memory", size)
+	if err != nil {
+		return nil, err
+	}
+	return Memory(memory), nil
+}
+
+/*
+MemoryReservationString sets the soft memory limit of the container from a human-readable size,
+like docker run --memory-reservation.
+
+Usage example:
+
+	reservation, err := hostoptions.MemoryReservationString("256m")
+	if err != nil {
+		log.Fatal(err)
+	}
+	myContainer.SetHostOptions(reservation)
+*/
+func MemoryReservationString(size string) (SetHostOptFn, error) {
+	reservation, err := parseSize("MemoryReservation", size)
+	if err != nil {
+		return nil, err
+	}
+	return MemoryReservation(reservation), nil
+}
+
+/*
+ShmSizeString sets the size of /dev/shm from a human-readable size, like docker run --shm-size.
+
+Usage example:
+
+	shm, err := hostoptions.ShmSizeString("1g")
+	if err != nil {
+		log.Fatal(err)
+	}
+	myContainer.SetHostOptions(shm)
+*/
+func ShmSizeString(size string) (SetHostOptFn, error) {
+	shmSize, err := parseSize("ShmSize", size)
+	if err != nil {
+		return nil, err
+	}
+	return ShmSize(shmSize), nil
+}
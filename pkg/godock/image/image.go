@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/aptd3v/godock/pkg/godock/imageoptions"
 	"github.com/docker/docker/api/types"
@@ -19,6 +22,8 @@ type ImageConfig struct {
 	BuildOptions *types.ImageBuildOptions
 	PullOptions  *image.PullOptions
 	PushOptions  *image.PushOptions
+	BuildHooks   *imageoptions.BuildHooks
+	PullChecks   *imageoptions.PullChecks
 }
 
 // SetPullOptions configures pull options for the Docker image.
@@ -51,6 +56,32 @@ func (img *ImageConfig) SetBuildOptions(setOFns ...imageoptions.SetBuildOptFn) {
 	}
 }
 
+// SetBuildHooks configures hooks that run after the Docker image has been built.
+// Use this method to register post-build hooks using functions from the imageoptions package.
+func (img *ImageConfig) SetBuildHooks(setHFns ...imageoptions.SetBuildHookFn) {
+	if img.BuildHooks == nil {
+		img.BuildHooks = &imageoptions.BuildHooks{}
+	}
+	for _, set := range setHFns {
+		if set != nil {
+			set(img.BuildHooks)
+		}
+	}
+}
+
+// SetPullChecks configures the checks a pulled Docker image must pass.
+// Use this method to pin the image digest using functions from the imageoptions package.
+func (img *ImageConfig) SetPullChecks(setCFns ...imageoptions.SetPullCheckFn) {
+	if img.PullChecks == nil {
+		img.PullChecks = &imageoptions.PullChecks{}
+	}
+	for _, set := range setCFns {
+		if set != nil {
+			set(img.PullChecks)
+		}
+	}
+}
+
 // String returns the reference of the Docker image.
 func (img *ImageConfig) String() string {
 	return img.Ref
@@ -58,12 +89,15 @@ func (img *ImageConfig) String() string {
 
 // NewConfig creates a new Image configuration with the specified image reference.
 // The Image instance contains pull, push, and build options for the Docker image.
+// The reference is not validated, use ParseConfig for references from user input.
 func NewConfig(ref string) *ImageConfig {
 	return &ImageConfig{
 		Ref:          ref,
 		BuildOptions: &types.ImageBuildOptions{},
 		PullOptions:  &image.PullOptions{},
 		PushOptions:  &image.PushOptions{},
+		BuildHooks:   &imageoptions.BuildHooks{},
+		PullChecks:   &imageoptions.PullChecks{},
 	}
 }
 
@@ -101,9 +135,92 @@ func NewImageFromSrc(dir string) (*ImageConfig, error) {
 		},
 		PullOptions: &image.PullOptions{},
 		PushOptions: &image.PushOptions{},
+		BuildHooks:  &imageoptions.BuildHooks{},
+		PullChecks:  &imageoptions.PullChecks{},
+	}, nil
+}
+
+/*
+NewImageFromFiles creates a new Image configuration whose build context is built in memory
+from a map of file paths to contents, without touching disk. The map must contain a Dockerfile
+at its root. Paths use forward slashes and are relative to the context root.
+
+Usage example:
+
+	img, err := image.NewImageFromFiles(map[string][]byte{
+		"Dockerfile": []byte("FROM alpine\nCOPY hello.txt /\nCMD [\"cat\", \"/hello.txt\"]"),
+		"hello.txt":  []byte("hello"),
+	})
+	if err != nil {
+		return err
+	}
+	img.SetBuildOptions(
+		imageoptions.AddTag("hello:latest"),
+	)
+*/
+func NewImageFromFiles(files map[string][]byte) (*ImageConfig, error) {
+	if _, ok := files["Dockerfile"]; !ok {
+		return nil, fmt.Errorf("the Dockerfile is required in the build context files")
+	}
+
+	context, err := createMemoryBuildContext(files)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImageConfig{
+		Ref: "",
+		BuildOptions: &types.ImageBuildOptions{
+			Context: context,
+		},
+		PullOptions: &image.PullOptions{},
+		PushOptions: &image.PushOptions{},
+		BuildHooks:  &imageoptions.BuildHooks{},
+		PullChecks:  &imageoptions.PullChecks{},
 	}, nil
 }
 
+// Archives in-memory files for docker build context
+func createMemoryBuildContext(files map[string][]byte) (io.ReadCloser, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	cleaned := make(map[string][]byte, len(files))
+	names := make([]string, 0, len(files))
+	for name, content := range files {
+		clean := path.Clean(strings.TrimPrefix(name, "/"))
+		if clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+			return nil, fmt.Errorf("invalid build context file path: %s", name)
+		}
+		cleaned[clean] = content
+		names = append(names, clean)
+	}
+	// Write the files in a stable order so identical inputs produce identical contexts
+	sort.Strings(names)
+
+	for _, name := range names {
+		content := cleaned[name]
+		header := &tar.Header{
+			Name:     name,
+			Mode:     0o644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(&buf), nil
+}
+
 // Archives a directory for docker build context
 func createLocalBuildContext(src string) (io.ReadCloser, error) {
 	var buf bytes.Buffer
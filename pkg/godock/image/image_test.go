@@ -0,0 +1,53 @@
+package image
+
+import (
+	"archive/tar"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewImageFromFiles(t *testing.T) {
+	t.Run("Builds a tar context", func(t *testing.T) {
+		img, err := NewImageFromFiles(map[string][]byte{
+			"Dockerfile":     []byte("FROM alpine\nCOPY . /app\n"),
+			"app/main.sh":    []byte("echo hi"),
+			"/etc/hello.txt": []byte("hello"),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, img.BuildOptions.Context)
+
+		tr := tar.NewReader(img.BuildOptions.Context)
+		contents := make(map[string]string)
+		var names []string
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			data, err := io.ReadAll(tr)
+			require.NoError(t, err)
+			names = append(names, header.Name)
+			contents[header.Name] = string(data)
+		}
+		assert.Equal(t, []string{"Dockerfile", "app/main.sh", "etc/hello.txt"}, names)
+		assert.Equal(t, "echo hi", contents["app/main.sh"])
+		assert.Equal(t, "hello", contents["etc/hello.txt"])
+	})
+
+	t.Run("Requires a Dockerfile", func(t *testing.T) {
+		_, err := NewImageFromFiles(map[string][]byte{"main.go": nil})
+		assert.Error(t, err)
+	})
+
+	t.Run("Rejects paths outside the context", func(t *testing.T) {
+		_, err := NewImageFromFiles(map[string][]byte{
+			"Dockerfile":    []byte("FROM alpine"),
+			"../secret.txt": []byte("nope"),
+		})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,104 @@
+package image
+
+import (
+	"fmt"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/distribution/reference"
+)
+
+/*
+ParseConfig creates a new Image configuration like NewConfig, after checking that ref is a valid image reference.
+Short references are normalized the way the docker CLI does, so "nginx" refers to docker.io/library/nginx. It
+returns a ValidationError for an empty or malformed reference, e.g. one with upper case letters in its repository.
+
+Usage example:
+
+	img, err := image.ParseConfig(req.Image)
+	if err != nil {
+		return err
+	}
+	fmt.Println(img.Registry(), img.Repository(), img.Tag())
+*/
+func ParseConfig(ref string) (*ImageConfig, error) {
+	if _, err := parseRef(ref); err != nil {
+		return nil, err
+	}
+	return NewConfig(ref), nil
+}
+
+// MustNewConfig is like ParseConfig but panics if ref is not a valid image reference. It is meant for tests and
+// for references known at compile time.
+func MustNewConfig(ref string) *ImageConfig {
+	img, err := ParseConfig(ref)
+	if err != nil {
+		panic(err)
+	}
+	return img
+}
+
+// Validate checks that Ref is a valid image reference.
+func (img *ImageConfig) Validate() error {
+	_, err := parseRef(img.Ref)
+	return err
+}
+
+// Registry returns the registry of the image, e.g. "docker.io" for "nginx", or an empty string when Ref is not
+// a valid reference.
+func (img *ImageConfig) Registry() string {
+	named, err := parseRef(img.Ref)
+	if err != nil {
+		return ""
+	}
+	return reference.Domain(named)
+}
+
+// Repository returns the repository of the image within its registry, e.g. "library/nginx" for "nginx", or an
+// empty string when Ref is not a valid reference.
+func (img *ImageConfig) Repository() string {
+	named, err := parseRef(img.Ref)
+	if err != nil {
+		return ""
+	}
+	return reference.Path(named)
+}
+
+// Tag returns the tag of the image. A reference with neither a tag nor a digest refers to the "latest" tag, as it
+// does for the daemon. It returns an empty string for a reference pinned by digest only, or one that is not valid.
+func (img *ImageConfig) Tag() string {
+	named, err := parseRef(img.Ref)
+	if err != nil {
+		return ""
+	}
+	if tagged, ok := named.(reference.Tagged); ok {
+		return tagged.Tag()
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return ""
+	}
+	return "latest"
+}
+
+// Digest returns the digest the image is pinned to, e.g. "sha256:…", or an empty string when it is not pinned.
+func (img *ImageConfig) Digest() string {
+	named, err := parseRef(img.Ref)
+	if err != nil {
+		return ""
+	}
+	if digested, ok := named.(reference.Digested); ok {
+		return digested.Digest().String()
+	}
+	return ""
+}
+
+// parseRef parses an image reference, normalizing short references.
+func parseRef(ref string) (reference.Named, error) {
+	if ref == "" {
+		return nil, &errdefs.ValidationError{Field: "ref", Message: "image reference cannot be empty"}
+	}
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return nil, &errdefs.ValidationError{Field: "ref", Message: fmt.Sprintf("invalid image reference %q: %s", ref, err)}
+	}
+	return named, nil
+}
@@ -0,0 +1,50 @@
+package image
+
+import (
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	const digest = "sha256:4bcff63911fcb4448bd4fdacec207030997caf25e9bea4045fa6c8c44de311d1"
+	tests := []struct {
+		ref                               string
+		registry, repository, tag, digest string
+	}{
+		{"nginx", "docker.io", "library/nginx", "latest", ""},
+		{"nginx:1.27", "docker.io", "library/nginx", "1.27", ""},
+		{"ghcr.io/acme/api:v2", "ghcr.io", "acme/api", "v2", ""},
+		{"localhost:5000/app", "localhost:5000", "app", "latest", ""},
+		{"alpine@" + digest, "docker.io", "library/alpine", "", digest},
+		{"alpine:3.20@" + digest, "docker.io", "library/alpine", "3.20", digest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			img, err := ParseConfig(tt.ref)
+			require.NoError(t, err)
+			assert.Equal(t, tt.ref, img.Ref, "the reference is kept as given")
+			assert.NotNil(t, img.PullOptions)
+			assert.Equal(t, tt.registry, img.Registry())
+			assert.Equal(t, tt.repository, img.Repository())
+			assert.Equal(t, tt.tag, img.Tag())
+			assert.Equal(t, tt.digest, img.Digest())
+			assert.NoError(t, img.Validate())
+		})
+	}
+
+	for _, ref := range []string{"", "Nginx", "nginx:", "nginx@sha256:abc", "-nginx"} {
+		t.Run("invalid "+ref, func(t *testing.T) {
+			_, err := ParseConfig(ref)
+			assert.True(t, errdefs.IsInvalidConfig(err))
+			assert.Panics(t, func() { MustNewConfig(ref) })
+		})
+	}
+
+	unparsed := NewConfig("Not A Ref")
+	assert.Error(t, unparsed.Validate())
+	assert.Empty(t, unparsed.Registry())
+	assert.Empty(t, unparsed.Tag())
+}
@@ -0,0 +1,569 @@
+package godock
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/distribution/reference"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression is the compression applied to an image archive written by ImageSave.
+type Compression string
+
+const (
+	// CompressionNone writes a plain tar archive.
+	CompressionNone Compression = "none"
+	// CompressionGzip writes a gzip compressed tar archive.
+	CompressionGzip Compression = "gzip"
+	// CompressionZstd writes a zstd compressed tar archive.
+	CompressionZstd Compression = "zstd"
+)
+
+type imageSaveOptions struct {
+	images      []string
+	compression Compression
+	onProgress  func(written int64)
+	progress    Progress
+	checksum    bool
+}
+
+// ImageSaveOptionFn is a function that configures ImageSave.
+type ImageSaveOptionFn func(*imageSaveOptions)
+
+// WithSaveImages adds more images to the archive, so several images can be saved and loaded together.
+func WithSaveImages(refs ...string) ImageSaveOptionFn {
+	return func(opts *imageSaveOptions) {
+		opts.images = append(opts.images, refs...)
+	}
+}
+
+// WithSaveCompression sets the compression of the archive. By default it is chosen from the file extension:
+// ".gz" and ".tgz" use gzip, ".zst" uses zstd and anything else is written uncompressed.
+func WithSaveCompression(compression Compression) ImageSaveOptionFn {
+	return func(opts *imageSaveOptions) {
+		opts.compression = compression
+	}
+}
+
+// WithSaveProgress sets a function called with the number of uncompressed bytes received from the daemon so far.
+func WithSaveProgress(onProgress func(written int64)) ImageSaveOptionFn {
+	return func(opts *imageSaveOptions) {
+		opts.onProgress = onProgress
+	}
+}
+
+// WithSaveReporter reports the save to progress, as the uncompressed bytes received from the daemon.
+// The total is not known in advance.
+func WithSaveReporter(progress Progress) ImageSaveOptionFn {
+	return func(opts *imageSaveOptions) {
+		opts.progress = progress
+	}
+}
+
+// WithSaveChecksum verifies the written archive against the SHA-256 checksum computed while writing it,
+// and stores the checksum next to the archive in "<file>.sha256", in the format used by sha256sum.
+func WithSaveChecksum() ImageSaveOptionFn {
+	return func(opts *imageSaveOptions) {
+		opts.checksum = true
+	}
+}
+
+/*
+ImageSave saves one or more images to a tar archive on the client host, optionally compressed.
+A partially written archive is removed when saving fails.
+
+Usage example:
+
+	err := client.ImageSave(ctx, appImage, "images.tar.zst",
+		godock.WithSaveImages("redis:7", "nginx:1.27"),
+		godock.WithSaveChecksum(),
+		godock.WithSaveProgress(func(written int64) {
+			fmt.Printf("\rsaved %s", units.HumanSize(float64(written)))
+		}),
+	)
+*/
+func (c *Client) ImageSave(ctx context.Context, imageConfig *image.ImageConfig, outputFile string, imageSaveOptionFns ...ImageSaveOptionFn) (err error) {
+	opts := &imageSaveOptions{
+		compression: compressionForFile(outputFile),
+	}
+	for _, fn := range imageSaveOptionFns {
+		if fn != nil {
+			fn(opts)
+		}
+	}
+
+	tracker := newProgressTracker(opts.progress, ProgressOpSave, imageConfig.Ref, 0)
+	tracker.start()
+	defer func() {
+		tracker.finish(err)
+	}()
+
+	refs := append([]string{imageConfig.Ref}, opts.images...)
+	rc, err := c.wrapped.ImageSave(ctx, refs)
+	if err != nil {
+		return newImageError(imageConfig.Ref, "save", err)
+	}
+	defer rc.Close()
+
+	file, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(outputFile)
+		}
+	}()
+
+	hash := sha256.New()
+	cw, err := newCompressor(io.MultiWriter(file, hash), opts.compression)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	var src io.Reader = rc
+	if onProgress := tracker.chain(opts.onProgress); onProgress != nil {
+		src = &progressReader{r: rc, onProgress: onProgress}
+	}
+	if _, err = io.Copy(cw, src); err != nil {
+		cw.Close()
+		file.Close()
+		return newImageError(imageConfig.Ref, "save", err)
+	}
+	if err = cw.Close(); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	if !opts.checksum {
+		return nil
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if err = verifyFileChecksum(outputFile, sum); err != nil {
+		return err
+	}
+	return os.WriteFile(outputFile+".sha256", []byte(fmt.Sprintf("%s  %s\n", sum, filepath.Base(outputFile))), 0o644)
+}
+
+type imageLoadOptions struct {
+	images       []string
+	onProgress   func(read int64)
+	progress     Progress
+	checksum     string
+	checksumFile bool
+}
+
+// ImageLoadOptionFn is a function that configures ImageLoad.
+type ImageLoadOptionFn func(*imageLoadOptions)
+
+// WithLoadImages loads only the given images from a multi-image archive.
+func WithLoadImages(refs ...string) ImageLoadOptionFn {
+	return func(opts *imageLoadOptions) {
+		opts.images = append(opts.images, refs...)
+	}
+}
+
+// WithLoadProgress sets a function called with the number of archive bytes read from disk so far.
+func WithLoadProgress(onProgress func(read int64)) ImageLoadOptionFn {
+	return func(opts *imageLoadOptions) {
+		opts.onProgress = onProgress
+	}
+}
+
+// WithLoadReporter reports the load to progress, as the archive bytes read from disk against the size of the
+// archive. The load is done once the returned reader has been read to the end.
+func WithLoadReporter(progress Progress) ImageLoadOptionFn {
+	return func(opts *imageLoadOptions) {
+		opts.progress = progress
+	}
+}
+
+// WithLoadChecksum verifies the archive against a SHA-256 checksum, in hex, before loading it.
+func WithLoadChecksum(sum string) ImageLoadOptionFn {
+	return func(opts *imageLoadOptions) {
+		opts.checksum = sum
+	}
+}
+
+// WithLoadChecksumFile verifies the archive against the "<file>.sha256" checksum file written by WithSaveChecksum.
+func WithLoadChecksumFile() ImageLoadOptionFn {
+	return func(opts *imageLoadOptions) {
+		opts.checksumFile = true
+	}
+}
+
+/*
+ImageLoad loads images from a tar archive on the client host. Gzip and zstd compressed archives
+are detected and decompressed automatically. The caller is responsible for closing the returned reader,
+which also closes the archive.
+
+Usage example:
+
+	rc, err := client.ImageLoad(ctx, "images.tar.zst",
+		godock.WithLoadChecksumFile(),
+		godock.WithLoadImages("redis:7"),
+	)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	io.Copy(os.Stdout, rc)
+*/
+func (c *Client) ImageLoad(ctx context.Context, inputFile string, imageLoadOptionFns ...ImageLoadOptionFn) (rc io.ReadCloser, err error) {
+	opts := &imageLoadOptions{}
+	for _, fn := range imageLoadOptionFns {
+		if fn != nil {
+			fn(opts)
+		}
+	}
+	var tracker *progressTracker
+	if opts.progress != nil {
+		var size int64
+		if info, err := os.Stat(inputFile); err == nil {
+			size = info.Size()
+		}
+		tracker = newProgressTracker(opts.progress, ProgressOpLoad, inputFile, size)
+		tracker.start()
+		defer func() {
+			if err != nil {
+				tracker.finish(err)
+			}
+		}()
+	}
+
+	sum := opts.checksum
+	if sum == "" && opts.checksumFile {
+		data, err := os.ReadFile(inputFile + ".sha256")
+		if err != nil {
+			return nil, err
+		}
+		sum, _, _ = strings.Cut(strings.TrimSpace(string(data)), " ")
+	}
+	if sum != "" {
+		if err := verifyFileChecksum(inputFile, sum); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return nil, err
+	}
+	var src io.Reader = file
+	if onProgress := tracker.chain(opts.onProgress); onProgress != nil {
+		src = &progressReader{r: file, onProgress: onProgress}
+	}
+	archive, closeArchive, err := decompress(src)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	closers := []func() error{file.Close, closeArchive}
+
+	if len(opts.images) > 0 {
+		selected, err := selectArchiveImages(inputFile, opts.images)
+		if err != nil {
+			closeAll(closers)
+			return nil, err
+		}
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(filterArchive(archive, pw, selected))
+		}()
+		archive = pr
+		closers = append([]func() error{pr.Close}, closers...)
+	}
+
+	res, err := c.wrapped.ImageLoad(ctx, archive, true)
+	if err != nil {
+		closeAll(closers)
+		return nil, newImageError(inputFile, "load", err)
+	}
+	return trackStream(&archiveLoadReader{ReadCloser: res.Body, closers: closers}, tracker, false), nil
+}
+
+// archiveLoadReader closes the archive along with the load response.
+type archiveLoadReader struct {
+	io.ReadCloser
+	closers []func() error
+}
+
+func (r *archiveLoadReader) Close() error {
+	err := r.ReadCloser.Close()
+	closeAll(r.closers)
+	return err
+}
+
+func closeAll(closers []func() error) {
+	for _, closeFn := range closers {
+		closeFn()
+	}
+}
+
+// progressReader reports the running number of bytes read.
+type progressReader struct {
+	r          io.Reader
+	n          int64
+	onProgress func(int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.n += int64(n)
+		p.onProgress(p.n)
+	}
+	return n, err
+}
+
+// compressionForFile chooses the compression from a file name.
+func compressionForFile(name string) Compression {
+	switch {
+	case strings.HasSuffix(name, ".gz"), strings.HasSuffix(name, ".tgz"):
+		return CompressionGzip
+	case strings.HasSuffix(name, ".zst"):
+		return CompressionZstd
+	}
+	return CompressionNone
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// newCompressor wraps w with the given compression.
+func newCompressor(w io.Writer, compression Compression) (io.WriteCloser, error) {
+	switch compression {
+	case CompressionNone, "":
+		return nopWriteCloser{w}, nil
+	case CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case CompressionZstd:
+		return zstd.NewWriter(w)
+	}
+	return nil, &errdefs.ValidationError{
+		Field:   "compression",
+		Message: "unknown compression " + string(compression),
+	}
+}
+
+// decompress detects gzip and zstd compressed input from its magic bytes.
+func decompress(r io.Reader) (io.Reader, func() error, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(4)
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, nil, err
+		}
+		return gz, gz.Close, nil
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		zr, err := zstd.NewReader(buffered)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zr, func() error { zr.Close(); return nil }, nil
+	}
+	return buffered, func() error { return nil }, nil
+}
+
+// verifyFileChecksum compares the SHA-256 checksum of a file with the expected hex checksum.
+func verifyFileChecksum(name, expected string) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return err
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(actual, expected) {
+		return &errdefs.ValidationError{
+			Field:   "checksum",
+			Message: fmt.Sprintf("%s has checksum %s, expected %s", name, actual, expected),
+		}
+	}
+	return nil
+}
+
+// archiveManifestEntry is an entry of the manifest.json file of an image archive.
+type archiveManifestEntry struct {
+	Config   string
+	RepoTags []string
+	Layers   []string
+}
+
+// archiveSelection holds the rewritten manifest and the files needed for the selected images.
+type archiveSelection struct {
+	manifest []byte
+	files    map[string]struct{}
+	dirs     map[string]struct{}
+}
+
+// selectArchiveImages reads the manifest of an archive and selects the entries tagged with the given references.
+func selectArchiveImages(inputFile string, refs []string) (*archiveSelection, error) {
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	archive, closeArchive, err := decompress(file)
+	if err != nil {
+		return nil, err
+	}
+	defer closeArchive()
+
+	var entries []archiveManifestEntry
+	tr := tar.NewReader(archive)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if path.Clean(header.Name) == "manifest.json" {
+			if err := json.NewDecoder(tr).Decode(&entries); err != nil {
+				return nil, fmt.Errorf("invalid manifest.json in %s: %w", inputFile, err)
+			}
+			break
+		}
+	}
+	if entries == nil {
+		return nil, &errdefs.ValidationError{
+			Field:   "archive",
+			Message: inputFile + " has no manifest.json, images cannot be selected",
+		}
+	}
+	return selectManifestEntries(entries, refs)
+}
+
+// selectManifestEntries keeps the manifest entries tagged with the given references.
+func selectManifestEntries(entries []archiveManifestEntry, refs []string) (*archiveSelection, error) {
+	selection := &archiveSelection{
+		files: make(map[string]struct{}),
+		dirs:  make(map[string]struct{}),
+	}
+	var selected []archiveManifestEntry
+	var available []string
+	for _, ref := range refs {
+		want := normalizeTag(ref)
+		found := false
+		for _, entry := range entries {
+			for _, tag := range entry.RepoTags {
+				if normalizeTag(tag) != want {
+					continue
+				}
+				found = true
+				entry.RepoTags = []string{tag}
+				selected = append(selected, entry)
+				selection.files[entry.Config] = struct{}{}
+				for _, layer := range entry.Layers {
+					selection.files[layer] = struct{}{}
+					// Legacy archives keep the layer metadata next to the layer tarball.
+					if path.Base(layer) == "layer.tar" {
+						selection.dirs[path.Dir(layer)] = struct{}{}
+					}
+				}
+			}
+		}
+		if !found {
+			for _, entry := range entries {
+				available = append(available, entry.RepoTags...)
+			}
+			return nil, &errdefs.ResourceNotFoundError{
+				ResourceType: "image in archive",
+				ID:           fmt.Sprintf("%s (available: %s)", ref, strings.Join(available, ", ")),
+			}
+		}
+	}
+
+	manifest, err := json.Marshal(selected)
+	if err != nil {
+		return nil, err
+	}
+	selection.manifest = manifest
+	return selection, nil
+}
+
+// filterArchive copies the files of the selected images and the rewritten manifest to w.
+// The OCI index is dropped so the daemon loads the images from manifest.json.
+func filterArchive(r io.Reader, w io.Writer, selection *archiveSelection) error {
+	tr := tar.NewReader(r)
+	tw := tar.NewWriter(w)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean(header.Name)
+		if name == "manifest.json" {
+			continue
+		}
+		_, keepFile := selection.files[name]
+		_, keepDir := selection.dirs[name]
+		_, inDir := selection.dirs[path.Dir(name)]
+		if !keepFile && !keepDir && !inDir {
+			continue
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     "manifest.json",
+		Mode:     0o644,
+		Size:     int64(len(selection.manifest)),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(selection.manifest); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// normalizeTag expands a reference such as "redis" to "docker.io/library/redis:latest" for comparison.
+func normalizeTag(ref string) string {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return ref
+	}
+	return reference.TagNameOnly(named).String()
+}
@@ -0,0 +1,120 @@
+package godock
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestArchive builds an image archive holding two images that share a layer.
+func writeTestArchive(t *testing.T) []byte {
+	t.Helper()
+	manifest, err := json.Marshal([]archiveManifestEntry{
+		{Config: "blobs/sha256/c1", RepoTags: []string{"redis:7"}, Layers: []string{"blobs/sha256/shared", "blobs/sha256/l1"}},
+		{Config: "blobs/sha256/c2", RepoTags: []string{"nginx:1.27"}, Layers: []string{"blobs/sha256/shared", "blobs/sha256/l2"}},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{
+		{"oci-layout", []byte(`{"imageLayoutVersion":"1.0.0"}`)},
+		{"index.json", []byte(`{}`)},
+		{"blobs/sha256/c1", []byte("config1")},
+		{"blobs/sha256/c2", []byte("config2")},
+		{"blobs/sha256/shared", []byte("shared")},
+		{"blobs/sha256/l1", []byte("layer1")},
+		{"blobs/sha256/l2", []byte("layer2")},
+		{"manifest.json", manifest},
+	} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0o644, Size: int64(len(f.data)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(f.data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func TestArchiveImageSelection(t *testing.T) {
+	archive := writeTestArchive(t)
+	file := filepath.Join(t.TempDir(), "images.tar")
+	require.NoError(t, os.WriteFile(file, archive, 0o644))
+
+	selection, err := selectArchiveImages(file, []string{"docker.io/library/redis:7"})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, filterArchive(bytes.NewReader(archive), &out, selection))
+
+	var names []string
+	var entries []archiveManifestEntry
+	tr := tar.NewReader(&out)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, header.Name)
+		if header.Name == "manifest.json" {
+			require.NoError(t, json.NewDecoder(tr).Decode(&entries))
+		}
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"blobs/sha256/c1", "blobs/sha256/l1", "blobs/sha256/shared", "manifest.json"}, names)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []string{"redis:7"}, entries[0].RepoTags)
+
+	_, err = selectArchiveImages(file, []string{"postgres:16"})
+	assert.True(t, errdefs.IsNotFound(err))
+}
+
+func TestArchiveCompressionRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("godock"), 1024)
+	for _, compression := range []Compression{CompressionNone, CompressionGzip, CompressionZstd} {
+		t.Run(string(compression), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := newCompressor(&buf, compression)
+			require.NoError(t, err)
+			_, err = w.Write(data)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+
+			r, closeFn, err := decompress(&buf)
+			require.NoError(t, err)
+			defer closeFn()
+			got, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, data, got)
+		})
+	}
+
+	_, err := newCompressor(io.Discard, Compression("lz4"))
+	assert.True(t, errdefs.IsInvalidConfig(err))
+
+	assert.Equal(t, CompressionGzip, compressionForFile("images.tar.gz"))
+	assert.Equal(t, CompressionGzip, compressionForFile("images.tgz"))
+	assert.Equal(t, CompressionZstd, compressionForFile("images.tar.zst"))
+	assert.Equal(t, CompressionNone, compressionForFile("images.tar"))
+}
+
+func TestVerifyFileChecksum(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "images.tar")
+	require.NoError(t, os.WriteFile(file, []byte("hello\n"), 0o644))
+
+	assert.NoError(t, verifyFileChecksum(file, "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"))
+	assert.NoError(t, verifyFileChecksum(file, "5891B5B522D5DF086D0FF0B110FBD9D21BB4FC7163AF34D08286A2E846F6BE03"))
+	assert.True(t, errdefs.IsInvalidConfig(verifyFileChecksum(file, "deadbeef")))
+}
@@ -0,0 +1,76 @@
+package godock
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types"
+)
+
+/*
+ResolveDigest asks the registry for the digest an image reference currently points to, without pulling it. Pin
+the digest with imageoptions.RequireDigest, or pull ref@digest, so later pulls get exactly the same image.
+
+Usage example:
+
+	digest, err := client.ResolveDigest(ctx, "nginx:1.27")
+	if err != nil {
+		return err
+	}
+	img := image.NewConfig("nginx:1.27")
+	img.SetPullChecks(imageoptions.RequireDigest(digest))
+*/
+func (c *Client) ResolveDigest(ctx context.Context, ref string) (string, error) {
+	if _, err := image.ParseConfig(ref); err != nil {
+		return "", err
+	}
+	encoded, err := c.registryAuthFor(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	inspect, err := c.wrapped.DistributionInspect(ctx, ref, encoded)
+	if err != nil {
+		return "", imageOpError(ref, "resolve digest", err)
+	}
+	return inspect.Descriptor.Digest.String(), nil
+}
+
+// requiredDigest returns the digest the image config is pinned to, or an empty string.
+func requiredDigest(imageConfig *image.ImageConfig) string {
+	if imageConfig.PullChecks == nil {
+		return ""
+	}
+	return imageConfig.PullChecks.Digest
+}
+
+// validateRequiredDigest returns a ValidationError when the image config is pinned to a malformed digest.
+func validateRequiredDigest(imageConfig *image.ImageConfig) error {
+	digest := requiredDigest(imageConfig)
+	if digest != "" && reference.DigestRegexp.FindString(digest) != digest {
+		return &errdefs.ValidationError{Field: "PullChecks.Digest", Message: fmt.Sprintf("invalid digest %q", digest)}
+	}
+	return nil
+}
+
+// repoDigests returns the digests of an image in the repository of ref. An image pulled several times can have
+// more than one.
+func repoDigests(inspect types.ImageInspect, ref string) []string {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return nil
+	}
+	var digests []string
+	for _, repoDigest := range inspect.RepoDigests {
+		pinned, err := reference.ParseNormalizedNamed(repoDigest)
+		if err != nil || pinned.Name() != named.Name() {
+			continue
+		}
+		if digested, ok := pinned.(reference.Digested); ok {
+			digests = append(digests, digested.Digest().String())
+		}
+	}
+	return digests
+}
@@ -0,0 +1,128 @@
+package godock
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/imageoptions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireDigest(t *testing.T) {
+	const (
+		pinned = "sha256:4bcff63911fcb4448bd4fdacec207030997caf25e9bea4045fa6c8c44de311d1"
+		moved  = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	)
+	var (
+		mu       sync.Mutex
+		local    string // the repository digest of the local image, empty when there is none
+		served   = pinned
+		pulls    int
+		pullFail bool
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1.45/images/create", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		pulls++
+		if pullFail {
+			io.WriteString(w, `{"errorDetail":{"message":"manifest unknown"},"error":"manifest unknown"}`+"\n")
+			return
+		}
+		local = served
+		fmt.Fprintf(w, "{\"status\":\"Pulling from library/alpine\"}\n{\"status\":\"Digest: %s\"}\n", served)
+	})
+	mux.HandleFunc("GET /v1.45/images/{name}/json", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if local == "" {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message":"No such image: alpine:3.20"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"Id":"sha256:abc","RepoDigests":["busybox@%s","alpine@%s"]}`, pinned, local)
+	})
+	mux.HandleFunc("GET /v1.45/distribution/{name}/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"Descriptor":{"mediaType":"application/vnd.oci.image.index.v1+json","digest":"%s","size":1}}`, served)
+	})
+	c := newFakeDaemonClient(t, mux)
+	ctx := context.Background()
+	pinnedConfig := func() *image.ImageConfig {
+		img := image.NewConfig("alpine:3.20")
+		img.SetPullChecks(imageoptions.RequireDigest(pinned))
+		return img
+	}
+	reset := func(localDigest, servedDigest string) {
+		mu.Lock()
+		defer mu.Unlock()
+		local, served, pulls, pullFail = localDigest, servedDigest, 0, false
+	}
+
+	t.Run("Resolve", func(t *testing.T) {
+		reset("", pinned)
+		digest, err := c.ResolveDigest(ctx, "alpine:3.20")
+		require.NoError(t, err)
+		assert.Equal(t, pinned, digest)
+
+		_, err = c.ResolveDigest(ctx, "Alpine")
+		assert.True(t, errdefs.IsInvalidConfig(err))
+	})
+
+	t.Run("Pull with the pinned digest", func(t *testing.T) {
+		reset("", pinned)
+		require.NoError(t, c.EnsureImage(ctx, pinnedConfig(), PullPolicyAlways))
+	})
+
+	t.Run("Pull of a moved tag fails", func(t *testing.T) {
+		reset("", moved)
+		err := c.EnsureImage(ctx, pinnedConfig(), PullPolicyAlways)
+		var mismatch *errdefs.DigestMismatchError
+		require.ErrorAs(t, err, &mismatch)
+		assert.Equal(t, pinned, mismatch.Want)
+		assert.Equal(t, moved, mismatch.Got, "only digests in the repository of the reference count")
+
+		rc, err := c.ImagePull(ctx, pinnedConfig())
+		require.NoError(t, err)
+		output, err := io.ReadAll(rc)
+		assert.True(t, errdefs.IsDigestMismatch(err), "ImagePull reports the mismatch as the final read error")
+		assert.Contains(t, string(output), "Digest: "+moved)
+		rc.Close()
+	})
+
+	t.Run("Stale local image is pulled again", func(t *testing.T) {
+		reset(moved, pinned)
+		require.NoError(t, c.EnsureImage(ctx, pinnedConfig(), PullPolicyIfNotPresent))
+		assert.Equal(t, 1, pulls)
+
+		require.NoError(t, c.EnsureImage(ctx, pinnedConfig(), PullPolicyIfNotPresent))
+		assert.Equal(t, 1, pulls, "a local image with the digest is not pulled")
+	})
+
+	t.Run("Pull never", func(t *testing.T) {
+		reset(moved, pinned)
+		assert.True(t, errdefs.IsDigestMismatch(c.EnsureImage(ctx, pinnedConfig(), PullPolicyNever)))
+		assert.Zero(t, pulls)
+	})
+
+	t.Run("Failed pull is not checked", func(t *testing.T) {
+		reset("", pinned)
+		pullFail = true
+		err := c.EnsureImage(ctx, pinnedConfig(), PullPolicyAlways)
+		assert.ErrorContains(t, err, "manifest unknown")
+		assert.False(t, errdefs.IsDigestMismatch(err))
+	})
+
+	t.Run("Invalid digest", func(t *testing.T) {
+		img := image.NewConfig("alpine:3.20")
+		img.SetPullChecks(imageoptions.RequireDigest("sha256:abc"))
+		_, err := c.ImagePull(ctx, img)
+		assert.True(t, errdefs.IsInvalidConfig(err))
+	})
+}
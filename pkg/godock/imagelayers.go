@@ -0,0 +1,120 @@
+package godock
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	imageType "github.com/docker/docker/api/types/image"
+)
+
+// ImageLayer is one entry of an image's history, oldest first.
+type ImageLayer struct {
+	// Digest is the content digest of the layer's uncompressed filesystem diff, e.g. "sha256:...".
+	// It is empty for history entries that did not create a layer, such as ENV or LABEL,
+	// and when the daemon does not report enough information to match entries to layers.
+	Digest string
+	// ID is the image id recorded for the entry. It is empty for entries from a parent image
+	// that is not available locally.
+	ID        string
+	Size      int64
+	CreatedBy string
+	Created   time.Time
+	Comment   string
+	Tags      []string
+}
+
+// Empty reports whether the entry did not add any content to the image filesystem.
+func (l ImageLayer) Empty() bool {
+	return l.Size == 0
+}
+
+/*
+ImageLayers returns the history of an image as typed layers, oldest first,
+with the size, creating command and layer digest of each entry.
+
+Usage example:
+
+	layers, err := client.ImageLayers(ctx, "nginx:1.27")
+	if err != nil {
+		return err
+	}
+	for _, layer := range layers {
+		fmt.Printf("%-72s %10s %s\n", layer.Digest, units.HumanSize(float64(layer.Size)), layer.CreatedBy)
+	}
+*/
+func (c *Client) ImageLayers(ctx context.Context, ref string) ([]ImageLayer, error) {
+	history, err := c.wrapped.ImageHistory(ctx, ref)
+	if err != nil {
+		return nil, newImageError(ref, "history", err)
+	}
+	inspect, _, err := c.wrapped.ImageInspectWithRaw(ctx, ref)
+	if err != nil {
+		return nil, newImageError(ref, "inspect", err)
+	}
+	var diffIDs []string
+	if inspect.RootFS.Type == "layers" {
+		diffIDs = inspect.RootFS.Layers
+	}
+	return newImageLayers(history, diffIDs), nil
+}
+
+// newImageLayers converts the history reported by the daemon, newest first, into layers, oldest first.
+// The layer digests are matched to the history entries that added content; when the number of
+// such entries does not match the number of layers the digests are left empty rather than guessed.
+func newImageLayers(history []imageType.HistoryResponseItem, diffIDs []string) []ImageLayer {
+	layers := make([]ImageLayer, 0, len(history))
+	nonEmpty := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		item := history[i]
+		layer := ImageLayer{
+			Size:      item.Size,
+			CreatedBy: strings.TrimPrefix(item.CreatedBy, "/bin/sh -c #(nop) "),
+			Created:   time.Unix(item.Created, 0),
+			Comment:   item.Comment,
+			Tags:      item.Tags,
+		}
+		if item.ID != "<missing>" {
+			layer.ID = item.ID
+		}
+		if !layer.Empty() {
+			nonEmpty++
+		}
+		layers = append(layers, layer)
+	}
+
+	if nonEmpty == len(diffIDs) {
+		next := 0
+		for i := range layers {
+			if !layers[i].Empty() {
+				layers[i].Digest = diffIDs[next]
+				next++
+			}
+		}
+	}
+	return layers
+}
+
+// ImageTotalSize returns the size of an image in bytes, including the layers shared with other images.
+func (c *Client) ImageTotalSize(ctx context.Context, ref string) (int64, error) {
+	inspect, _, err := c.wrapped.ImageInspectWithRaw(ctx, ref)
+	if err != nil {
+		return 0, newImageError(ref, "inspect", err)
+	}
+	return inspect.Size, nil
+}
+
+// ImageLabels returns the labels of an image. The map is empty, not nil, for images without labels.
+func (c *Client) ImageLabels(ctx context.Context, ref string) (map[string]string, error) {
+	inspect, _, err := c.wrapped.ImageInspectWithRaw(ctx, ref)
+	if err != nil {
+		return nil, newImageError(ref, "inspect", err)
+	}
+	labels := make(map[string]string)
+	if inspect.Config != nil {
+		for k, v := range inspect.Config.Labels {
+			labels[k] = v
+		}
+	}
+	return labels, nil
+}
@@ -0,0 +1,36 @@
+package godock
+
+import (
+	"testing"
+
+	imageType "github.com/docker/docker/api/types/image"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewImageLayers(t *testing.T) {
+	history := []imageType.HistoryResponseItem{
+		{ID: "sha256:top", Created: 300, CreatedBy: "/bin/sh -c #(nop)  CMD [\"nginx\"]", Tags: []string{"nginx:1.27"}},
+		{ID: "<missing>", Created: 200, CreatedBy: "RUN /bin/sh -c apt-get install -y nginx", Size: 2048},
+		{ID: "<missing>", Created: 100, CreatedBy: "/bin/sh -c #(nop) ADD file:abc in / ", Size: 1024},
+	}
+
+	layers := newImageLayers(history, []string{"sha256:base", "sha256:nginx"})
+	require.Len(t, layers, 3)
+	assert.Equal(t, "sha256:base", layers[0].Digest)
+	assert.Equal(t, "ADD file:abc in / ", layers[0].CreatedBy)
+	assert.Equal(t, "", layers[0].ID)
+	assert.Equal(t, int64(100), layers[0].Created.Unix())
+	assert.Equal(t, "sha256:nginx", layers[1].Digest)
+	assert.Equal(t, int64(2048), layers[1].Size)
+	assert.True(t, layers[2].Empty())
+	assert.Equal(t, "", layers[2].Digest)
+	assert.Equal(t, "sha256:top", layers[2].ID)
+	assert.Equal(t, []string{"nginx:1.27"}, layers[2].Tags)
+
+	// Digests are not guessed when the layers cannot be matched to history entries.
+	layers = newImageLayers(history, []string{"sha256:base"})
+	for _, layer := range layers {
+		assert.Empty(t, layer.Digest)
+	}
+}
@@ -0,0 +1,147 @@
+package godock
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/metrics"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// errTransferIncomplete is recorded when a transfer stream is closed before it was fully read.
+var errTransferIncomplete = errors.New("stream closed before the transfer completed")
+
+// transferMeter measures an image pull, push or build from its JSON progress stream
+// and reports the result to the client's metrics recorders once the stream ends.
+type transferMeter struct {
+	client    *Client
+	transfer  metrics.ImageTransfer
+	start     time.Time
+	body      io.ReadCloser
+	scanner   jsonMessageScanner
+	layers    map[string]struct{}
+	cached    map[string]struct{}
+	sizes     map[string]int64
+	contextSz *countingReader
+	once      sync.Once
+}
+
+// meterTransfer wraps body so the transfer is recorded when it has been read to the end or closed.
+// It returns body as is when no recorders are configured.
+func (c *Client) meterTransfer(op metrics.Operation, ref string, start time.Time, body io.ReadCloser, contextSize *countingReader) io.ReadCloser {
+	if len(c.recorders) == 0 {
+		return body
+	}
+	return &transferMeter{
+		client:    c,
+		transfer:  metrics.ImageTransfer{Operation: op, Ref: ref},
+		start:     start,
+		body:      body,
+		layers:    make(map[string]struct{}),
+		cached:    make(map[string]struct{}),
+		sizes:     make(map[string]int64),
+		contextSz: contextSize,
+	}
+}
+
+// recordTransfer reports a transfer to every configured recorder.
+func (c *Client) recordTransfer(transfer metrics.ImageTransfer) {
+	for _, recorder := range c.recorders {
+		recorder.RecordImageTransfer(transfer)
+	}
+}
+
+func (m *transferMeter) Read(p []byte) (int, error) {
+	n, err := m.body.Read(p)
+	if n > 0 {
+		m.scanner.write(p[:n], m.observe)
+	}
+	if err == io.EOF {
+		m.scanner.flush(m.observe)
+		m.finish(nil)
+	} else if err != nil {
+		m.finish(err)
+	}
+	return n, err
+}
+
+func (m *transferMeter) Close() error {
+	m.finish(errTransferIncomplete)
+	return m.body.Close()
+}
+
+// finish records the transfer once. readErr is used when the daemon did not report an error.
+func (m *transferMeter) finish(readErr error) {
+	m.once.Do(func() {
+		if m.transfer.Err == nil {
+			m.transfer.Err = readErr
+		}
+		m.transfer.Duration = m.client.Clock().Now().Sub(m.start)
+		if m.transfer.Operation == metrics.OperationBuild {
+			if m.contextSz != nil {
+				m.transfer.Bytes = m.contextSz.n.Load()
+			}
+		} else {
+			m.transfer.Layers = len(m.layers)
+			m.transfer.CachedLayers = len(m.cached)
+			for id, size := range m.sizes {
+				if _, ok := m.cached[id]; !ok {
+					m.transfer.Bytes += size
+				}
+			}
+		}
+		m.client.recordTransfer(m.transfer)
+	})
+}
+
+// observe updates the measurements from a single progress message.
+func (m *transferMeter) observe(msg jsonmessage.JSONMessage) error {
+	if msg.Error != nil {
+		m.transfer.Err = msg.Error
+		return nil
+	}
+
+	if m.transfer.Operation == metrics.OperationBuild {
+		stream := strings.TrimSpace(msg.Stream)
+		switch {
+		case strings.HasPrefix(stream, "Step "):
+			m.transfer.Layers++
+		case strings.HasPrefix(stream, "---> Using cache"):
+			m.transfer.CachedLayers++
+		}
+		return nil
+	}
+
+	if msg.ID == "" {
+		return nil
+	}
+	switch {
+	case msg.Status == "Pulling fs layer", msg.Status == "Waiting", msg.Status == "Preparing":
+		m.layers[msg.ID] = struct{}{}
+	case msg.Status == "Already exists", msg.Status == "Layer already exists", strings.HasPrefix(msg.Status, "Mounted from"):
+		m.layers[msg.ID] = struct{}{}
+		m.cached[msg.ID] = struct{}{}
+	case msg.Status == "Downloading", msg.Status == "Pushing":
+		m.layers[msg.ID] = struct{}{}
+		if msg.Progress != nil && msg.Progress.Total > m.sizes[msg.ID] {
+			m.sizes[msg.ID] = msg.Progress.Total
+		}
+	}
+	return nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,107 @@
+package godock
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMeteredClient(t *testing.T) (*Client, *clock.Fake, *[]metrics.ImageTransfer) {
+	t.Helper()
+	fake := clock.NewFake(time.Now())
+	var recorded []metrics.ImageTransfer
+	c := &Client{
+		clock: fake,
+		recorders: []metrics.Recorder{metrics.RecorderFunc(func(transfer metrics.ImageTransfer) {
+			recorded = append(recorded, transfer)
+		})},
+	}
+	return c, fake, &recorded
+}
+
+func TestTransferMeter(t *testing.T) {
+	t.Run("Pull", func(t *testing.T) {
+		c, fake, recorded := newMeteredClient(t)
+		stream := strings.Join([]string{
+			`{"status":"Pulling from library/alpine","id":"latest"}`,
+			`{"status":"Already exists","progressDetail":{},"id":"aaa"}`,
+			`{"status":"Pulling fs layer","progressDetail":{},"id":"bbb"}`,
+			`{"status":"Downloading","progressDetail":{"current":100,"total":2000},"id":"bbb"}`,
+			`{"status":"Downloading","progressDetail":{"current":2000,"total":2000},"id":"bbb"}`,
+			`{"status":"Pull complete","progressDetail":{},"id":"bbb"}`,
+			`{"status":"Status: Downloaded newer image for alpine:latest"}`,
+		}, "\n")
+
+		rc := c.meterTransfer(metrics.OperationPull, "alpine:latest", fake.Now(), io.NopCloser(strings.NewReader(stream)), nil)
+		fake.Advance(2 * time.Second)
+		_, err := io.Copy(io.Discard, rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+
+		require.Len(t, *recorded, 1)
+		transfer := (*recorded)[0]
+		assert.Equal(t, metrics.OperationPull, transfer.Operation)
+		assert.Equal(t, "alpine:latest", transfer.Ref)
+		assert.Equal(t, int64(2000), transfer.Bytes)
+		assert.Equal(t, 2, transfer.Layers)
+		assert.Equal(t, 1, transfer.CachedLayers)
+		assert.Equal(t, 2*time.Second, transfer.Duration)
+		assert.NoError(t, transfer.Err)
+	})
+
+	t.Run("Push error", func(t *testing.T) {
+		c, fake, recorded := newMeteredClient(t)
+		stream := `{"status":"Preparing","id":"aaa"}` + "\n" +
+			`{"status":"Layer already exists","id":"aaa"}` + "\n" +
+			`{"errorDetail":{"message":"denied"},"error":"denied"}` + "\n"
+
+		rc := c.meterTransfer(metrics.OperationPush, "registry.example.com/app", fake.Now(), io.NopCloser(strings.NewReader(stream)), nil)
+		_, err := io.Copy(io.Discard, rc)
+		require.NoError(t, err)
+
+		require.Len(t, *recorded, 1)
+		assert.EqualError(t, (*recorded)[0].Err, "denied")
+		assert.Equal(t, 1, (*recorded)[0].CachedLayers)
+	})
+
+	t.Run("Build", func(t *testing.T) {
+		c, fake, recorded := newMeteredClient(t)
+		contextSize := &countingReader{r: strings.NewReader("0123456789")}
+		_, err := io.Copy(io.Discard, contextSize)
+		require.NoError(t, err)
+
+		stream := `{"stream":"Step 1/2 : FROM alpine"}` + "\n" +
+			`{"stream":" ---> Using cache\n"}` + "\n" +
+			`{"stream":"Step 2/2 : RUN true"}` + "\n"
+		rc := c.meterTransfer(metrics.OperationBuild, "app:latest", fake.Now(), io.NopCloser(strings.NewReader(stream)), contextSize)
+		_, err = io.Copy(io.Discard, rc)
+		require.NoError(t, err)
+
+		require.Len(t, *recorded, 1)
+		assert.Equal(t, int64(10), (*recorded)[0].Bytes)
+		assert.Equal(t, 2, (*recorded)[0].Layers)
+		assert.Equal(t, 1, (*recorded)[0].CachedLayers)
+	})
+
+	t.Run("Closed early", func(t *testing.T) {
+		c, fake, recorded := newMeteredClient(t)
+		rc := c.meterTransfer(metrics.OperationPull, "alpine", fake.Now(), io.NopCloser(strings.NewReader("")), nil)
+		require.NoError(t, rc.Close())
+		require.NoError(t, rc.Close())
+
+		require.Len(t, *recorded, 1)
+		assert.ErrorIs(t, (*recorded)[0].Err, errTransferIncomplete)
+	})
+
+	t.Run("No recorders", func(t *testing.T) {
+		c := &Client{}
+		body := io.NopCloser(strings.NewReader(""))
+		assert.Equal(t, body, c.meterTransfer(metrics.OperationPull, "alpine", time.Now(), body, nil))
+	})
+}
@@ -4,12 +4,17 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
 	"runtime"
+	"sort"
+	"strings"
 
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
 )
 
 // SetPullOptFn is a function type that configures pull options for a Docker image.
@@ -119,6 +124,95 @@ func CacheEnabled(enabled bool) SetBuildOptFn {
 	}
 }
 
+// CacheType is the kind of build cache exported with CacheTo.
+type CacheType string
+
+const (
+	// CacheInline embeds the cache metadata in the built image, so pushing the image also publishes its cache.
+	CacheInline CacheType = "inline"
+	// CacheRegistry exports the cache to a registry reference. It requires a standalone BuildKit (buildx)
+	// and cannot be exported through the daemon, so builds using it fail validation.
+	CacheRegistry CacheType = "registry"
+)
+
+// inlineCacheArg is the build arg that makes BuildKit embed cache metadata in the built image.
+const inlineCacheArg = "BUILDKIT_INLINE_CACHE"
+
+/*
+CacheFrom adds images whose layers may be reused as build cache, such as an image pushed by a previous CI run.
+With BuildKit the references are pulled from their registry as needed.
+
+Usage example:
+
+	img := image.NewConfig("my-image")
+	img.SetBuildOptions(
+		imageoptions.CacheFrom("registry.example.com/my-image:buildcache"),
+	)
+*/
+func CacheFrom(refs ...string) SetBuildOptFn {
+	return func(options *types.ImageBuildOptions) {
+		options.CacheFrom = append(options.CacheFrom, refs...)
+	}
+}
+
+/*
+CacheTo exports the build cache so later builds can import it with CacheFrom.
+The Docker Engine API only exports inline cache without attributes, and only with BuildKit: the build must
+use SetBuilderVersion(BuilderV2) or SetPlatforms, otherwise ValidateCacheTo fails the build. Other cache
+types and attributes such as mode=max require a standalone BuildKit (buildx) and fail validation too.
+To publish the cache to a registry, tag the image and push it with the client once the build completes.
+
+Usage example:
+
+	img := image.NewConfig("my-image")
+	img.SetBuildOptions(
+		imageoptions.AddTag("registry.example.com/my-image:buildcache"),
+		imageoptions.SetBuilderVersion(imageoptions.BuilderV2),
+		imageoptions.CacheFrom("registry.example.com/my-image:buildcache"),
+		imageoptions.CacheTo(imageoptions.CacheInline, nil),
+	)
+*/
+func CacheTo(cacheType CacheType, attrs map[string]string) SetBuildOptFn {
+	return func(options *types.ImageBuildOptions) {
+		if options.BuildArgs == nil {
+			options.BuildArgs = make(map[string]*string)
+		}
+		value := "1"
+		if cacheType != CacheInline || len(attrs) > 0 {
+			// The unsupported export is kept in the build arg so ValidateCacheTo can report it.
+			keys := make([]string, 0, len(attrs))
+			for key, attr := range attrs {
+				keys = append(keys, key+"="+attr)
+			}
+			sort.Strings(keys)
+			value = strings.Join(append([]string{"type=" + string(cacheType)}, keys...), ",")
+		}
+		options.BuildArgs[inlineCacheArg] = &value
+	}
+}
+
+// ValidateCacheTo checks that the cache export requested with CacheTo can be made through the daemon,
+// so an unsupported export fails before the build starts. ImageBuild runs it.
+func ValidateCacheTo(options *types.ImageBuildOptions) error {
+	value, ok := options.BuildArgs[inlineCacheArg]
+	if !ok || value == nil {
+		return nil
+	}
+	if *value != "1" {
+		return &errdefs.ValidationError{
+			Field:   "CacheTo",
+			Message: fmt.Sprintf("cache export %s requires a standalone BuildKit, the daemon only exports inline cache without attributes", *value),
+		}
+	}
+	if options.Version != types.BuilderBuildKit {
+		return &errdefs.ValidationError{
+			Field:   "CacheTo",
+			Message: "exporting inline cache requires BuildKit, use imageoptions.SetBuilderVersion(imageoptions.BuilderV2)",
+		}
+	}
+	return nil
+}
+
 /*
 SetDockerfile specifies the path to the Dockerfile.
 
@@ -667,6 +761,29 @@ func SetBuildPlatform(platform string) SetBuildOptFn {
 	}
 }
 
+/*
+SetPlatforms builds the image for several platforms in a single build using BuildKit,
+producing a multi-platform image (manifest list). The daemon must use the containerd image store
+to keep multi-platform images locally. Push the result with the client once the build completes;
+the containerd image store pushes the whole manifest list. Pushing from the build itself is rejected,
+as BuildKit takes registry credentials from a client session that godock does not attach.
+SetPlatforms switches the build to BuildKit.
+
+Usage example:
+
+	img := image.NewConfig("registry.example.com/my-image:latest")
+	img.SetBuildOptions(
+		imageoptions.AddTag("registry.example.com/my-image:latest"),
+		imageoptions.SetPlatforms("linux/amd64", "linux/arm64"),
+	)
+*/
+func SetPlatforms(platforms ...string) SetBuildOptFn {
+	return func(options *types.ImageBuildOptions) {
+		options.Platform = strings.Join(platforms, ",")
+		options.Version = types.BuilderBuildKit
+	}
+}
+
 /*
 SetPullPolicy sets the pull policy for the build.
 
@@ -770,9 +887,69 @@ func SetPrivilegeFunc(authFn func(ctx context.Context) (string, error)) SetPullO
 	}
 }
 
+/*
+PullRegistryAuth sets the registry credentials used to pull the image.
+Credentials are otherwise resolved by the client's registry auth manager.
+
+Usage example:
+
+	img.SetPullOptions(
+		imageoptions.PullRegistryAuth("username", "password"),
+	)
+*/
+func PullRegistryAuth(username, password string) SetPullOptFn {
+	encodedAuth := encodeAuthToBase64(Auth{Username: username, Password: password})
+	return func(options *image.PullOptions) {
+		options.RegistryAuth = encodedAuth
+	}
+}
+
+/*
+PushRegistryAuth sets the registry credentials used to push the image.
+Credentials are otherwise resolved by the client's registry auth manager.
+
+Usage example:
+
+	img.SetPushOptions(
+		imageoptions.PushRegistryAuth("username", "password"),
+	)
+*/
+func PushRegistryAuth(username, password string) SetPushOptFn {
+	encodedAuth := encodeAuthToBase64(Auth{Username: username, Password: password})
+	return func(options *image.PushOptions) {
+		options.RegistryAuth = encodedAuth
+	}
+}
+
+/*
+BuildRegistryAuth adds registry credentials used to pull base images during the build.
+When no build credentials are set, the client's registry auth manager provides them.
+
+Usage example:
+
+	img.SetBuildOptions(
+		imageoptions.BuildRegistryAuth("registry.example.com", "username", "password"),
+	)
+*/
+func BuildRegistryAuth(server, username, password string) SetBuildOptFn {
+	return func(options *types.ImageBuildOptions) {
+		if options.AuthConfigs == nil {
+			options.AuthConfigs = make(map[string]registry.AuthConfig)
+		}
+		options.AuthConfigs[server] = registry.AuthConfig{
+			Username:      username,
+			Password:      password,
+			ServerAddress: server,
+		}
+	}
+}
+
 /*
 SetRegistryAuth sets the registry authentication credentials for pushing or pulling images.
 
+Deprecated: the result must be type asserted before use. Use PullRegistryAuth, PushRegistryAuth
+or BuildRegistryAuth, or let the client resolve credentials with its registry auth manager.
+
 Usage example for pull:
 
 	image.SetPullOptions(
@@ -852,3 +1029,112 @@ func AddOutput(outputType OutputType, attrs map[string]string) SetBuildOptFn {
 		})
 	}
 }
+
+// BUILD HOOKS
+
+// BuiltHook is called once an image has been built successfully.
+// imageID is the ID of the built image. digest is the repository digest of the pushed image when the
+// hooks push it first, see PushBeforeHooks, and empty otherwise, since a local build has no repository digest.
+// Returning an error fails the build.
+type BuiltHook func(ctx context.Context, imageID string, digest string) error
+
+// BuildHooks holds the hooks that run after an image build completes.
+type BuildHooks struct {
+	OnBuilt []BuiltHook
+	// Push pushes every tag of the built image before the hooks run.
+	Push bool
+}
+
+// SetBuildHookFn is a function type that configures post-build hooks for a Docker image.
+type SetBuildHookFn func(hooks *BuildHooks)
+
+/*
+OnBuilt registers a hook that runs after the image has been built successfully.
+Hooks run in the order they were registered once the build output has been fully read,
+and an error returned by a hook is returned from the build output reader.
+This can be used to attest or sign images as part of the build pipeline.
+
+Usage example:
+
+	img := image.NewConfig("my-image")
+	img.SetBuildHooks(
+		imageoptions.OnBuilt(func(ctx context.Context, imageID, digest string) error {
+			log.Printf("built %s (%s)", imageID, digest)
+			return nil
+		}),
+	)
+*/
+func OnBuilt(hook BuiltHook) SetBuildHookFn {
+	return func(hooks *BuildHooks) {
+		hooks.OnBuilt = append(hooks.OnBuilt, hook)
+	}
+}
+
+/*
+PushBeforeHooks pushes every tag of the built image once the build succeeds and before the OnBuilt hooks
+run, so the hooks receive the repository digest of the pushed image, e.g. to sign it. The image is pushed
+with the image config's push options and the client's registry credentials, and a failed push fails the build.
+
+Usage example:
+
+	img := image.NewConfig("registry.example.com/my-image:latest")
+	img.SetBuildOptions(
+		imageoptions.AddTag("registry.example.com/my-image:latest"),
+	)
+	img.SetBuildHooks(
+		imageoptions.PushBeforeHooks(),
+		imageoptions.OnBuilt(signer.Hook()),
+	)
+*/
+func PushBeforeHooks() SetBuildHookFn {
+	return func(hooks *BuildHooks) {
+		hooks.Push = true
+	}
+}
+
+// PULL CHECKS
+
+// PullChecks holds the checks a pulled image must pass for the pull to succeed.
+type PullChecks struct {
+	// Digest is the repository digest the image must have, e.g. "sha256:…". An empty digest is not checked.
+	Digest string
+	// SkipVerification exempts the image from the image verifiers of the client.
+	SkipVerification bool
+}
+
+// SetPullCheckFn is a function type that configures the checks of a pulled Docker image.
+type SetPullCheckFn func(checks *PullChecks)
+
+/*
+RequireDigest pins the image to a repository digest. Once a pull completes the image is inspected, and the pull
+fails with an *errdefs.DigestMismatchError when the registry served an image with another digest, e.g. because a
+tag was moved. EnsureImage also pulls again when the local image does not have the digest.
+
+Usage example:
+
+	img := image.NewConfig("nginx:1.27")
+	img.SetPullChecks(
+		imageoptions.RequireDigest("sha256:4bcff63911fcb4448bd4fdacec207030997caf25e9bea4045fa6c8c44de311d1"),
+	)
+*/
+func RequireDigest(digest string) SetPullCheckFn {
+	return func(checks *PullChecks) {
+		checks.Digest = digest
+	}
+}
+
+/*
+SkipVerification exempts the image from the image verifiers the client was created with, see
+godock.WithImageVerifiers. It is meant for the images the verifiers run themselves, which would otherwise have
+to verify themselves first. The pinned digest is still checked.
+
+Usage example:
+
+	cosignImage := image.NewConfig("gcr.io/projectsigstore/cosign:v2.4.1")
+	cosignImage.SetPullChecks(imageoptions.SkipVerification())
+*/
+func SkipVerification() SetPullCheckFn {
+	return func(checks *PullChecks) {
+		checks.SkipVerification = true
+	}
+}
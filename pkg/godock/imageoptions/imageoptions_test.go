@@ -0,0 +1,56 @@
+package imageoptions
+
+import (
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheFrom(t *testing.T) {
+	options := &types.ImageBuildOptions{}
+	CacheFrom("app:cache-1", "app:cache-2")(options)
+	CacheFrom("app:cache-3")(options)
+	assert.Equal(t, []string{"app:cache-1", "app:cache-2", "app:cache-3"}, options.CacheFrom)
+}
+
+func TestCacheTo(t *testing.T) {
+	t.Run("Inline", func(t *testing.T) {
+		options := &types.ImageBuildOptions{}
+		CacheTo(CacheInline, nil)(options)
+		require.NotNil(t, options.BuildArgs["BUILDKIT_INLINE_CACHE"])
+		assert.Equal(t, "1", *options.BuildArgs["BUILDKIT_INLINE_CACHE"])
+		assert.Empty(t, options.Version, "the builder must not be switched")
+		assert.Empty(t, options.Outputs)
+
+		err := ValidateCacheTo(options)
+		assert.True(t, errdefs.IsInvalidConfig(err))
+		SetBuilderVersion(BuilderV2)(options)
+		assert.NoError(t, ValidateCacheTo(options))
+	})
+
+	t.Run("Registry", func(t *testing.T) {
+		options := &types.ImageBuildOptions{Tags: []string{"registry.example.com/app:latest"}}
+		SetBuilderVersion(BuilderV2)(options)
+		CacheTo(CacheRegistry, map[string]string{"ref": "registry.example.com/app:buildcache"})(options)
+		assert.Equal(t, []string{"registry.example.com/app:latest"}, options.Tags)
+		assert.Empty(t, options.Outputs)
+
+		err := ValidateCacheTo(options)
+		assert.True(t, errdefs.IsInvalidConfig(err))
+		assert.Contains(t, err.Error(), "type=registry,ref=registry.example.com/app:buildcache")
+	})
+
+	t.Run("Inline with attributes", func(t *testing.T) {
+		options := &types.ImageBuildOptions{}
+		SetBuilderVersion(BuilderV2)(options)
+		CacheTo(CacheInline, map[string]string{"mode": "max"})(options)
+		assert.True(t, errdefs.IsInvalidConfig(ValidateCacheTo(options)))
+	})
+
+	t.Run("Without export", func(t *testing.T) {
+		assert.NoError(t, ValidateCacheTo(&types.ImageBuildOptions{}))
+	})
+}
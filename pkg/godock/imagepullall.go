@@ -0,0 +1,159 @@
+package godock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+)
+
+// ImagePullResult is the outcome of pulling one image with ImagePullAll.
+type ImagePullResult struct {
+	Image    *image.ImageConfig
+	Duration time.Duration
+	// Err is nil when the image was pulled, or was already present with the PullPolicyIfNotPresent policy.
+	Err error
+}
+
+type pullAllOptions struct {
+	concurrency int
+	policy      PullPolicy
+	onProgress  func(ref string, progress ImageProgress)
+	progress    Progress
+}
+
+// PullAllOptionFn is a function that configures ImagePullAll.
+type PullAllOptionFn func(*pullAllOptions)
+
+// WithConcurrency sets how many images are pulled at the same time. The default is 3.
+func WithConcurrency(n int) PullAllOptionFn {
+	return func(opts *pullAllOptions) {
+		if n > 0 {
+			opts.concurrency = n
+		}
+	}
+}
+
+// WithPullAllPolicy sets the pull policy applied to every image. The default is PullPolicyAlways.
+func WithPullAllPolicy(policy PullPolicy) PullAllOptionFn {
+	return func(opts *pullAllOptions) {
+		opts.policy = policy
+	}
+}
+
+// WithPullAllProgress sets a function called with the progress events of every image.
+// Calls are serialized, so the function does not need to be safe for concurrent use.
+func WithPullAllProgress(onProgress func(ref string, progress ImageProgress)) PullAllOptionFn {
+	return func(opts *pullAllOptions) {
+		opts.onProgress = onProgress
+	}
+}
+
+// WithPullAllReporter reports a pull operation per image to progress, with the image reference as its ID.
+// Calls are serialized, so progress does not need to be safe for concurrent use.
+func WithPullAllReporter(progress Progress) PullAllOptionFn {
+	return func(opts *pullAllOptions) {
+		opts.progress = progress
+	}
+}
+
+/*
+ImagePullAll pulls several images concurrently and waits for all of them to finish.
+It returns one result per image, in the order given, and an error joining every failed pull.
+A failed pull does not stop the others.
+
+Usage example:
+
+	results, err := client.ImagePullAll(ctx,
+		[]*image.ImageConfig{mongoImage, redisImage, nginxImage},
+		godock.WithConcurrency(3),
+		godock.WithPullAllPolicy(godock.PullPolicyIfNotPresent),
+	)
+	for _, res := range results {
+		if res.Err != nil {
+			log.Printf("pulling %s failed: %v", res.Image, res.Err)
+		}
+	}
+*/
+func (c *Client) ImagePullAll(ctx context.Context, images []*image.ImageConfig, pullAllOptionFns ...PullAllOptionFn) ([]ImagePullResult, error) {
+	opts := &pullAllOptions{
+		concurrency: 3,
+		policy:      PullPolicyAlways,
+	}
+	for _, fn := range pullAllOptionFns {
+		if fn != nil {
+			fn(opts)
+		}
+	}
+	for i, img := range images {
+		if img == nil || img.Ref == "" {
+			return nil, &errdefs.ValidationError{
+				Field:   "images",
+				Message: fmt.Sprintf("image config or reference cannot be empty at index %d", i),
+			}
+		}
+	}
+
+	var progressMu sync.Mutex
+	var progress Progress
+	if opts.progress != nil {
+		progress = ProgressFunc(func(event ProgressEvent) {
+			progressMu.Lock()
+			defer progressMu.Unlock()
+			opts.progress.OnProgress(event)
+		})
+	}
+	results := make([]ImagePullResult, len(images))
+	sem := make(chan struct{}, opts.concurrency)
+	var wg sync.WaitGroup
+	for i, img := range images {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i].Image = img
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i].Err = newImageError(img.Ref, "pull", ctx.Err())
+				return
+			}
+
+			tracker := newProgressTracker(progress, ProgressOpPull, img.Ref, 0)
+			updates := tracker.pullUpdates()
+			var onProgress func(ImageProgress)
+			if opts.onProgress != nil || updates != nil {
+				onProgress = func(event ImageProgress) {
+					if updates != nil {
+						updates(event)
+					}
+					if opts.onProgress == nil {
+						return
+					}
+					progressMu.Lock()
+					defer progressMu.Unlock()
+					opts.onProgress(img.Ref, event)
+				}
+			}
+
+			tracker.start()
+			start := c.Clock().Now()
+			results[i].Err = c.ensureImage(ctx, img, opts.policy, onProgress)
+			results[i].Duration = c.Clock().Now().Sub(start)
+			tracker.finish(results[i].Err)
+		}()
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, res := range results {
+		if res.Err != nil {
+			errs = append(errs, res.Err)
+		}
+	}
+	return results, errors.Join(errs...)
+}
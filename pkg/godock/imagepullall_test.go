@@ -0,0 +1,21 @@
+package godock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImagePullAllValidation(t *testing.T) {
+	c := &Client{}
+	_, err := c.ImagePullAll(context.Background(), []*image.ImageConfig{image.NewConfig("alpine"), nil})
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	assert.ErrorContains(t, err, "index 1")
+
+	results, err := c.ImagePullAll(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
@@ -0,0 +1,119 @@
+package godock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// ImageProgress is a progress event reported while an image is transferred.
+type ImageProgress struct {
+	// ID is the layer the event refers to. It is empty for events about the whole image.
+	ID string
+	// Status is the status reported by the daemon, such as "Pushing" or "Layer already exists".
+	Status string
+	// Current and Total are the transferred and total bytes of the layer, when known.
+	Current int64
+	Total   int64
+}
+
+// newImageProgress converts a progress message from the daemon.
+func newImageProgress(msg jsonmessage.JSONMessage) ImageProgress {
+	event := ImageProgress{ID: msg.ID, Status: msg.Status}
+	if msg.Progress != nil {
+		event.Current = msg.Progress.Current
+		event.Total = msg.Progress.Total
+	}
+	return event
+}
+
+// ImagePushResult describes the pushed image.
+type ImagePushResult struct {
+	Tag string
+	// Digest is the repository digest of the pushed manifest, e.g. "sha256:...".
+	Digest string
+	// Size is the size of the manifest in bytes.
+	Size int
+}
+
+/*
+ImagePushWithProgress pushes an image, calling onProgress for every progress event,
+and returns the tag and repository digest of the pushed image once the push has completed.
+onProgress may be nil. Registry authentication and authorization failures are returned as an
+*errdefs.ImageError for which errdefs.IsUnauthorized reports true.
+
+Usage example:
+
+	res, err := client.ImagePushWithProgress(ctx, img, func(p godock.ImageProgress) {
+		fmt.Printf("%s %s %d/%d\n", p.ID, p.Status, p.Current, p.Total)
+	})
+	if errdefs.IsUnauthorized(err) {
+		// log in and retry
+	}
+*/
+func (c *Client) ImagePushWithProgress(ctx context.Context, imageConfig *image.ImageConfig, onProgress func(ImageProgress)) (*ImagePushResult, error) {
+	rc, err := c.ImagePush(ctx, imageConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return decodePushStream(rc, imageConfig.Ref, onProgress)
+}
+
+// decodePushStream reads a push progress stream until it ends or reports an error.
+func decodePushStream(r io.Reader, ref string, onProgress func(ImageProgress)) (*ImagePushResult, error) {
+	result := &ImagePushResult{}
+	err := decodeJSONMessages(r, func(msg jsonmessage.JSONMessage) error {
+		if msg.Error != nil {
+			return newImageError(ref, "push", errors.New(msg.Error.Message))
+		}
+		if msg.Aux != nil {
+			var aux ImagePushResult
+			if err := json.Unmarshal(*msg.Aux, &aux); err == nil && aux.Digest != "" {
+				result = &aux
+			}
+			return nil
+		}
+		if onProgress != nil && msg.Status != "" {
+			onProgress(newImageProgress(msg))
+		}
+		return nil
+	})
+	if err != nil {
+		var imageErr *errdefs.ImageError
+		if errors.As(err, &imageErr) {
+			return nil, err
+		}
+		return nil, newImageError(ref, "push", err)
+	}
+	if result.Digest == "" {
+		return nil, &errdefs.ImageError{
+			Ref:     ref,
+			Op:      "push",
+			Message: "push completed without reporting a digest",
+		}
+	}
+	return result, nil
+}
+
+// newImageError wraps an image operation failure, marking registry authentication
+// and authorization failures as unauthorized.
+func newImageError(ref, op string, err error) *errdefs.ImageError {
+	return &errdefs.ImageError{
+		Ref:          ref,
+		Op:           op,
+		Message:      err.Error(),
+		Err:          err,
+		Unauthorized: isUnauthorized(err),
+	}
+}
+
+// isUnauthorized reports whether an error from the daemon or a registry is an authentication or authorization failure.
+func isUnauthorized(err error) bool {
+	return errdefs.Classify(err) == errdefs.ErrUnauthorized
+}
@@ -0,0 +1,63 @@
+package godock
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodePushStream(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		stream := strings.Join([]string{
+			`{"status":"The push refers to repository [registry.example.com/app]"}`,
+			`{"status":"Preparing","progressDetail":{},"id":"aaa"}`,
+			`{"status":"Pushing","progressDetail":{"current":512,"total":1024},"id":"aaa"}`,
+			`{"status":"Pushed","progressDetail":{},"id":"aaa"}`,
+			`{"status":"latest: digest: sha256:abc size: 528"}`,
+			`{"progressDetail":{},"aux":{"Tag":"latest","Digest":"sha256:abc","Size":528}}`,
+		}, "\n")
+
+		var events []ImageProgress
+		res, err := decodePushStream(strings.NewReader(stream), "registry.example.com/app", func(p ImageProgress) {
+			events = append(events, p)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &ImagePushResult{Tag: "latest", Digest: "sha256:abc", Size: 528}, res)
+		require.Len(t, events, 5)
+		assert.Equal(t, ImageProgress{ID: "aaa", Status: "Pushing", Current: 512, Total: 1024}, events[2])
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		stream := `{"status":"Preparing","id":"aaa"}` + "\n" +
+			`{"errorDetail":{"message":"unauthorized: authentication required"},"error":"unauthorized: authentication required"}`
+		_, err := decodePushStream(strings.NewReader(stream), "registry.example.com/app", nil)
+		require.Error(t, err)
+		assert.True(t, errdefs.IsUnauthorized(err))
+
+		var imageErr *errdefs.ImageError
+		require.ErrorAs(t, err, &imageErr)
+		assert.Equal(t, "push", imageErr.Op)
+	})
+
+	t.Run("Other error", func(t *testing.T) {
+		stream := `{"errorDetail":{"message":"blob upload unknown"},"error":"blob upload unknown"}`
+		_, err := decodePushStream(strings.NewReader(stream), "app", nil)
+		require.Error(t, err)
+		assert.False(t, errdefs.IsUnauthorized(err))
+	})
+
+	t.Run("No digest", func(t *testing.T) {
+		_, err := decodePushStream(strings.NewReader(`{"status":"Preparing","id":"aaa"}`), "app", nil)
+		assert.ErrorContains(t, err, "without reporting a digest")
+	})
+}
+
+func TestIsUnauthorized(t *testing.T) {
+	assert.True(t, isUnauthorized(errors.New("denied: requested access to the resource is denied")))
+	assert.True(t, isUnauthorized(errors.New("Head \"https://registry/v2/\": no basic auth credentials")))
+	assert.False(t, isUnauthorized(errors.New("manifest unknown")))
+}
@@ -0,0 +1,148 @@
+package godock
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+/*
+ImageVerifier checks an image before it is used, e.g. its signature. ref is the reference the image was pulled by
+and digest its repository digest, or an empty string for an image that has none, such as a local build. Returning
+an error rejects the image. The signing package has verifiers for cosign.
+
+Usage example:
+
+	type allowRegistry string
+
+	func (r allowRegistry) Verify(ctx context.Context, ref, digest string) error {
+		if !strings.HasPrefix(ref, string(r)+"/") {
+			return fmt.Errorf("images must come from %s", r)
+		}
+		return nil
+	}
+
+	client, err := godock.NewClient(ctx, godock.WithImageVerifiers(allowRegistry("registry.example.com")))
+*/
+type ImageVerifier interface {
+	Verify(ctx context.Context, ref string, digest string) error
+}
+
+// checksImage reports whether a local image has anything to check.
+func (c *Client) checksImage(imageConfig *image.ImageConfig) bool {
+	return requiredDigest(imageConfig) != "" || c.verifiesImage(imageConfig)
+}
+
+// verifiesImage reports whether the image verifiers of the client check the image.
+func (c *Client) verifiesImage(imageConfig *image.ImageConfig) bool {
+	return len(c.verifiers) > 0 && (imageConfig.PullChecks == nil || !imageConfig.PullChecks.SkipVerification)
+}
+
+// checkImage checks a local image against the digest it is pinned to, then runs the image verifiers of the client
+// on it. pulledDigest is the digest reported by the pull that fetched the image, if any; a local image can have
+// several repository digests, and the pulled one is the one verified.
+func (c *Client) checkImage(ctx context.Context, imageConfig *image.ImageConfig, pulledDigest string) error {
+	if !c.checksImage(imageConfig) {
+		return nil
+	}
+	inspect, _, err := c.wrapped.ImageInspectWithRaw(ctx, imageConfig.Ref)
+	if err != nil {
+		return imageOpError(imageConfig.Ref, "verify", err)
+	}
+	digests := repoDigests(inspect, imageConfig.Ref)
+
+	digest := requiredDigest(imageConfig)
+	if digest != "" && !slices.Contains(digests, digest) {
+		mismatch := &errdefs.DigestMismatchError{Ref: imageConfig.Ref, Want: digest}
+		if len(digests) > 0 {
+			mismatch.Got = digests[0]
+		}
+		return mismatch
+	}
+	if !c.verifiesImage(imageConfig) {
+		return nil
+	}
+	switch {
+	case digest != "":
+	case slices.Contains(digests, pulledDigest):
+		digest = pulledDigest
+	case len(digests) > 0:
+		digest = digests[0]
+	}
+	for _, verifier := range c.verifiers {
+		if err := verifier.Verify(ctx, imageConfig.Ref, digest); err != nil {
+			return &errdefs.ImageError{Ref: imageConfig.Ref, Op: "verify", Message: err.Error(), Err: err}
+		}
+	}
+	return nil
+}
+
+// pullCheckReader watches a pull output stream and checks the pulled image once the stream has been fully read.
+type pullCheckReader struct {
+	ctx          context.Context
+	client       *Client
+	imageConfig  *image.ImageConfig
+	body         io.ReadCloser
+	scanner      jsonMessageScanner
+	pulledDigest string
+	failed       bool
+	done         bool
+	checkErr     error
+}
+
+// checkPull wraps the output of a pull to check the pulled image, if there is anything to check.
+func (c *Client) checkPull(ctx context.Context, imageConfig *image.ImageConfig, body io.ReadCloser) io.ReadCloser {
+	if !c.checksImage(imageConfig) {
+		return body
+	}
+	return &pullCheckReader{ctx: ctx, client: c, imageConfig: imageConfig, body: body}
+}
+
+func (r *pullCheckReader) Read(p []byte) (int, error) {
+	if r.done {
+		if r.checkErr != nil {
+			return 0, r.checkErr
+		}
+		return 0, io.EOF
+	}
+
+	n, err := r.body.Read(p)
+	if n > 0 {
+		r.scanner.write(p[:n], r.observe)
+	}
+	if err == io.EOF {
+		r.done = true
+		r.scanner.flush(r.observe)
+		if !r.failed {
+			r.checkErr = r.client.checkImage(r.ctx, r.imageConfig, r.pulledDigest)
+		}
+		if r.checkErr != nil {
+			// Hand back the remaining output now and report the failed check on the next read.
+			if n > 0 {
+				return n, nil
+			}
+			return 0, r.checkErr
+		}
+	}
+	return n, err
+}
+
+func (r *pullCheckReader) Close() error {
+	return r.body.Close()
+}
+
+// observe records the digest of the pulled image and failed pulls, which leave no image to check.
+func (r *pullCheckReader) observe(msg jsonmessage.JSONMessage) error {
+	if msg.Error != nil {
+		r.failed = true
+	}
+	if digest, ok := strings.CutPrefix(msg.Status, "Digest: "); ok {
+		r.pulledDigest = digest
+	}
+	return nil
+}
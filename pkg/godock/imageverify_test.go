@@ -0,0 +1,87 @@
+package godock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/imageoptions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingVerifier records the images it verifies and rejects them when err is set.
+type recordingVerifier struct {
+	verified []string
+	err      error
+}
+
+func (v *recordingVerifier) Verify(ctx context.Context, ref string, digest string) error {
+	v.verified = append(v.verified, ref+"@"+digest)
+	return v.err
+}
+
+func TestImageVerifiers(t *testing.T) {
+	const (
+		pulled = "sha256:4bcff63911fcb4448bd4fdacec207030997caf25e9bea4045fa6c8c44de311d1"
+		other  = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	)
+	pulls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1.45/images/create", func(w http.ResponseWriter, r *http.Request) {
+		pulls++
+		fmt.Fprintf(w, "{\"status\":\"Digest: %s\"}\n", pulled)
+	})
+	mux.HandleFunc("GET /v1.45/images/{name}/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"Id":"sha256:abc","RepoDigests":["alpine@%s","alpine@%s"]}`, other, pulled)
+	})
+	c := newFakeDaemonClient(t, mux)
+	verifier := &recordingVerifier{}
+	c.verifiers = []ImageVerifier{verifier}
+	ctx := context.Background()
+	reset := func(err error) {
+		verifier.verified, verifier.err, pulls = nil, err, 0
+	}
+
+	t.Run("Pulled digest is verified", func(t *testing.T) {
+		reset(nil)
+		require.NoError(t, c.EnsureImage(ctx, image.NewConfig("alpine:3.20"), PullPolicyAlways))
+		assert.Equal(t, []string{"alpine:3.20@" + pulled}, verifier.verified)
+	})
+
+	t.Run("Present image is verified", func(t *testing.T) {
+		reset(nil)
+		require.NoError(t, c.EnsureImage(ctx, image.NewConfig("alpine:3.20"), PullPolicyIfNotPresent))
+		assert.Zero(t, pulls)
+		assert.Len(t, verifier.verified, 1)
+	})
+
+	t.Run("Rejected image", func(t *testing.T) {
+		rejected := errors.New("no matching signatures")
+		reset(rejected)
+		err := c.EnsureImage(ctx, image.NewConfig("alpine:3.20"), PullPolicyAlways)
+		var imageErr *errdefs.ImageError
+		require.ErrorAs(t, err, &imageErr)
+		assert.Equal(t, "verify", imageErr.Op)
+		assert.ErrorIs(t, err, rejected)
+
+		rc, err := c.ImagePull(ctx, image.NewConfig("alpine:3.20"))
+		require.NoError(t, err)
+		_, err = io.ReadAll(rc)
+		assert.ErrorIs(t, err, rejected, "ImagePull reports the rejection as the final read error")
+		rc.Close()
+	})
+
+	t.Run("Skip verification", func(t *testing.T) {
+		reset(errors.New("no matching signatures"))
+		img := image.NewConfig("alpine:3.20")
+		img.SetPullChecks(imageoptions.SkipVerification())
+		require.NoError(t, c.EnsureImage(ctx, img, PullPolicyAlways))
+		assert.Empty(t, verifier.verified)
+	})
+}
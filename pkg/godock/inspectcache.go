@@ -0,0 +1,175 @@
+package godock
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+// inspectCache holds container inspects for a short time, so that frequent polling of the state of a container
+// does not reach the daemon every time. Entries are invalidated by the container and network events of the daemon,
+// and expire after the TTL in case an event was missed.
+type inspectCache struct {
+	ttl   time.Duration
+	clock clock.Clock
+
+	mu      sync.Mutex
+	entries map[string]inspectEntry
+	// generation is incremented by every invalidation, so that an inspect started before an invalidation is not cached.
+	generation uint64
+	// watching is set while the event stream is read. Entries are only served while it is set.
+	watching bool
+	cancel   context.CancelFunc
+}
+
+type inspectEntry struct {
+	inspect types.ContainerJSON
+	fetched time.Time
+}
+
+func newInspectCache(ttl time.Duration, clk clock.Clock) *inspectCache {
+	return &inspectCache{ttl: ttl, clock: clk, entries: make(map[string]inspectEntry)}
+}
+
+// inspect returns the inspect of the container with the given id or name, from the cache when it is fresh.
+func (ic *inspectCache) inspect(ctx context.Context, wrapped client.APIClient, id string) (types.ContainerJSON, error) {
+	ic.mu.Lock()
+	if entry, ok := ic.entries[id]; ok && ic.watching && ic.clock.Now().Sub(entry.fetched) < ic.ttl {
+		ic.mu.Unlock()
+		return entry.inspect, nil
+	}
+	if !ic.watching {
+		ic.watch(wrapped)
+	}
+	generation := ic.generation
+	ic.mu.Unlock()
+
+	inspect, err := wrapped.ContainerInspect(ctx, id)
+	if err != nil {
+		return inspect, err
+	}
+
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	if ic.watching && ic.generation == generation {
+		ic.entries[id] = inspectEntry{inspect: inspect, fetched: ic.clock.Now()}
+	}
+	return inspect, nil
+}
+
+// watch starts reading the event stream of the daemon. It must be called with mu held.
+func (ic *inspectCache) watch(wrapped client.APIClient) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ic.watching = true
+	ic.cancel = cancel
+
+	args := filters.NewArgs(
+		filters.Arg("type", string(events.ContainerEventType)),
+		filters.Arg("type", string(events.NetworkEventType)),
+	)
+	msgCh, errCh := wrapped.Events(ctx, events.ListOptions{Filters: args})
+	go func() {
+		defer cancel()
+		for {
+			select {
+			case msg := <-msgCh:
+				ic.observe(msg)
+			case <-errCh:
+				if ctx.Err() != nil {
+					// The cache was closed.
+					return
+				}
+				// Without events the entries can no longer be trusted. The stream is reopened by the next inspect.
+				ic.mu.Lock()
+				ic.watching = false
+				ic.invalidateLocked("")
+				ic.mu.Unlock()
+				return
+			}
+		}
+	}()
+}
+
+// observe invalidates the container an event is about.
+func (ic *inspectCache) observe(msg events.Message) {
+	id := msg.Actor.ID
+	switch msg.Type {
+	case events.NetworkEventType:
+		// Network events are about the network; the connected or disconnected container is an attribute.
+		id = msg.Actor.Attributes["container"]
+	case events.ContainerEventType:
+		// Exec events, e.g. of health checks, do not change the inspect. A changed health status has its own event.
+		if strings.HasPrefix(string(msg.Action), "exec_") {
+			return
+		}
+	}
+	if id == "" {
+		return
+	}
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	ic.invalidateLocked(id)
+}
+
+// invalidateLocked removes the entries of a container, looked up by id or name, or every entry when id is empty.
+// It must be called with mu held.
+func (ic *inspectCache) invalidateLocked(id string) {
+	ic.generation++
+	for key, entry := range ic.entries {
+		matches := id == "" || key == id
+		if base := entry.inspect.ContainerJSONBase; base != nil {
+			matches = matches || base.ID == id || strings.TrimPrefix(base.Name, "/") == id
+		}
+		if matches {
+			delete(ic.entries, key)
+		}
+	}
+}
+
+// close stops reading the event stream and empties the cache.
+func (ic *inspectCache) close() {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	if ic.cancel != nil {
+		ic.cancel()
+	}
+	ic.watching = false
+	ic.invalidateLocked("")
+}
+
+// inspectContainer inspects a container, through the inspect cache when it is enabled.
+func (c *Client) inspectContainer(ctx context.Context, id string) (types.ContainerJSON, error) {
+	if c.inspects == nil {
+		return c.wrapped.ContainerInspect(ctx, id)
+	}
+	return c.inspects.inspect(ctx, c.wrapped, id)
+}
+
+// invalidateInspect removes the cached inspect of a container changed through the client, so that the change is
+// seen before its event arrives.
+func (c *Client) invalidateInspect(id string) {
+	if c.inspects == nil {
+		return
+	}
+	c.inspects.mu.Lock()
+	defer c.inspects.mu.Unlock()
+	c.inspects.invalidateLocked(id)
+}
+
+// InvalidateInspectCache removes every cached container inspect, e.g. after changing a container through
+// Unwrap. It does nothing when the client has no inspect cache.
+func (c *Client) InvalidateInspectCache() {
+	if c.inspects == nil {
+		return
+	}
+	c.inspects.mu.Lock()
+	defer c.inspects.mu.Unlock()
+	c.inspects.invalidateLocked("")
+}
@@ -0,0 +1,127 @@
+package godock
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const webInspect = `{"Id":"abc123","Name":"/web","State":{"Running":true,"ExitCode":0},` +
+	`"NetworkSettings":{"Networks":{"backend":{"IPAddress":"172.18.0.2"}}}}`
+
+func TestInspectCache(t *testing.T) {
+	var inspects atomic.Int32
+	events := make(chan string)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1.45/containers/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		inspects.Add(1)
+		io.WriteString(w, webInspect)
+	})
+	mux.HandleFunc("POST /v1.45/containers/{id}/stop", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /v1.45/events", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case event := <-events:
+				io.WriteString(w, event+"\n")
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	c := newFakeDaemonClient(t, mux)
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c.clock = fake
+	c.inspects = newInspectCache(time.Second, fake)
+	t.Cleanup(c.inspects.close)
+	web := &container.ContainerConfig{Id: "abc123", Name: "web"}
+	ctx := context.Background()
+
+	running, err := c.IsContainerRunning(ctx, web)
+	require.NoError(t, err)
+	assert.True(t, running)
+	code, err := c.GetContainerExitCode(ctx, web)
+	require.NoError(t, err)
+	assert.Zero(t, code)
+	ip, err := c.GetContainerIPAddress(ctx, web, "")
+	require.NoError(t, err)
+	assert.Equal(t, "172.18.0.2", ip)
+	assert.EqualValues(t, 1, inspects.Load(), "the getters are served from the cache")
+
+	t.Run("Events invalidate the container", func(t *testing.T) {
+		events <- `{"Type":"container","Action":"exec_start: healthcheck","Actor":{"ID":"abc123"}}`
+		events <- `{"Type":"network","Action":"connect","Actor":{"ID":"net1","Attributes":{"container":"abc123"}}}`
+		require.Eventually(t, func() bool {
+			c.inspects.mu.Lock()
+			defer c.inspects.mu.Unlock()
+			return len(c.inspects.entries) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+		_, err := c.IsContainerRunning(ctx, web)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, inspects.Load())
+	})
+
+	t.Run("Entries expire", func(t *testing.T) {
+		fake.Advance(time.Second)
+		_, err := c.IsContainerRunning(ctx, web)
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, inspects.Load())
+	})
+
+	t.Run("Changes through the client invalidate the container", func(t *testing.T) {
+		require.NoError(t, c.ContainerStop(ctx, web))
+		_, err := c.IsContainerRunning(ctx, web)
+		require.NoError(t, err)
+		assert.EqualValues(t, 4, inspects.Load())
+	})
+
+	t.Run("Disabled cache inspects every time", func(t *testing.T) {
+		uncached := &Client{wrapped: c.wrapped}
+		before := inspects.Load()
+		for i := 0; i < 2; i++ {
+			_, err := uncached.IsContainerRunning(ctx, web)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, before+2, inspects.Load())
+	})
+}
+
+func TestInspectCache_Invalidate(t *testing.T) {
+	entry := func(id, name string) inspectEntry {
+		return inspectEntry{inspect: types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{ID: id, Name: name}}}
+	}
+	ic := newInspectCache(time.Minute, clock.Real())
+	ic.entries["web"] = entry("abc123", "/web")
+	ic.entries["abc1"] = entry("abc123", "/web")
+	ic.entries["db"] = entry("def456", "/db")
+	ic.entries["missing"] = inspectEntry{}
+
+	ic.mu.Lock()
+	ic.invalidateLocked("abc123")
+	ic.mu.Unlock()
+	assert.NotContains(t, ic.entries, "web", "entries are matched by container id")
+	assert.NotContains(t, ic.entries, "abc1")
+	assert.Contains(t, ic.entries, "db")
+
+	ic.mu.Lock()
+	ic.invalidateLocked("db")
+	ic.mu.Unlock()
+	assert.NotContains(t, ic.entries, "db", "entries are matched by container name")
+	assert.Contains(t, ic.entries, "missing")
+
+	ic.close()
+	assert.Empty(t, ic.entries)
+}
@@ -0,0 +1,140 @@
+package godock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/docker/docker/api/types"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+// inspectManyConcurrency is the number of containers ContainerInspectMany and ContainerInspectByLabel inspect at the
+// same time.
+const inspectManyConcurrency = 8
+
+/*
+ContainerInspectMany inspects several containers concurrently and returns their inspects keyed by container name.
+Containers are named by their config, or by their inspect when the config has no name. A failed inspect does not
+stop the others: it returns the inspects that succeeded and an error joining every failure. Containers that do not
+exist fail with a ResourceNotFoundError.
+
+Usage example:
+
+	inspects, err := client.ContainerInspectMany(ctx, web, worker, db)
+	if err != nil {
+		return err
+	}
+	if !inspects["db"].State.Running {
+		return client.ContainerStart(ctx, db)
+	}
+*/
+func (c *Client) ContainerInspectMany(ctx context.Context, containerConfigs ...*container.ContainerConfig) (map[string]types.ContainerJSON, error) {
+	ids := make([]string, len(containerConfigs))
+	names := make([]string, len(containerConfigs))
+	for i, containerConfig := range containerConfigs {
+		if containerConfig == nil || containerConfig.Id == "" {
+			return nil, &errdefs.ValidationError{
+				Field:   "containerConfigs",
+				Message: fmt.Sprintf("container config at index %d is nil or has no ID", i),
+			}
+		}
+		ids[i], names[i] = containerConfig.Id, containerConfig.Name
+	}
+	return c.inspectMany(ctx, ids, names)
+}
+
+/*
+ContainerInspectByLabel inspects every container, running or not, matching a label selector and returns their
+inspects keyed by container name. The selector is a comma separated list of labels, each either key=value or a key
+matching any value; a container must match all of them. See ContainerInspectMany for the handling of failures.
+
+Usage example:
+
+	inspects, err := client.ContainerInspectByLabel(ctx, "com.example.stack=shop,com.example.tier")
+	if err != nil {
+		return err
+	}
+	for name, inspect := range inspects {
+		fmt.Println(name, inspect.State.Status)
+	}
+*/
+func (c *Client) ContainerInspectByLabel(ctx context.Context, selector string) (map[string]types.ContainerJSON, error) {
+	args := filters.NewArgs()
+	for _, label := range strings.Split(selector, ",") {
+		label = strings.TrimSpace(label)
+		if label == "" || strings.HasPrefix(label, "=") {
+			return nil, &errdefs.ValidationError{
+				Field:   "selector",
+				Message: fmt.Sprintf("invalid label selector %q", selector),
+			}
+		}
+		args.Add("label", label)
+	}
+
+	containers, err := c.wrapped.ContainerList(ctx, containerType.ListOptions{All: true, Filters: args})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	ids := make([]string, len(containers))
+	for i, summary := range containers {
+		ids[i] = summary.ID
+	}
+	return c.inspectMany(ctx, ids, make([]string, len(ids)))
+}
+
+// inspectMany inspects the containers with a bounded number of workers. names holds the name each inspect is keyed
+// by, or an empty string to key it by the name of the container.
+func (c *Client) inspectMany(ctx context.Context, ids, names []string) (map[string]types.ContainerJSON, error) {
+	var (
+		mu       sync.Mutex
+		inspects = make(map[string]types.ContainerJSON, len(ids))
+		errs     []error
+	)
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(inspectManyConcurrency, len(ids)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				inspect, err := c.inspectContainer(ctx, ids[i])
+				mu.Lock()
+				switch {
+				case client.IsErrNotFound(err):
+					errs = append(errs, &errdefs.ResourceNotFoundError{ResourceType: "container", ID: ids[i], Err: err})
+				case err != nil:
+					errs = append(errs, &errdefs.ContainerError{ID: ids[i], Op: "inspect", Message: err.Error(), Err: err})
+				default:
+					name := names[i]
+					if name == "" && inspect.ContainerJSONBase != nil {
+						name = strings.TrimPrefix(inspect.Name, "/")
+					}
+					if name == "" {
+						name = ids[i]
+					}
+					inspects[name] = inspect
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range ids {
+		if err := ctx.Err(); err != nil {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+			break
+		}
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	return inspects, errors.Join(errs...)
+}
@@ -0,0 +1,87 @@
+package godock
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerInspectMany(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1.45/containers/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if id == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message":"No such container: missing"}`)
+			return
+		}
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		fmt.Fprintf(w, `{"Id":"%s","Name":"/%s-name","State":{"Running":true}}`, id, id)
+	})
+	mux.HandleFunc("GET /v1.45/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Query().Get("filters"), `"com.example.stack=shop":true`)
+		assert.Contains(t, r.URL.Query().Get("filters"), `"com.example.tier":true`)
+		assert.Equal(t, "1", r.URL.Query().Get("all"))
+		io.WriteString(w, `[{"Id":"c1"},{"Id":"c2"}]`)
+	})
+	c := newFakeDaemonClient(t, mux)
+	ctx := context.Background()
+
+	t.Run("Bounded concurrency", func(t *testing.T) {
+		configs := make([]*container.ContainerConfig, 20)
+		for i := range configs {
+			configs[i] = &container.ContainerConfig{Id: fmt.Sprintf("c%d", i), Name: fmt.Sprintf("app-%d", i)}
+		}
+		inspects, err := c.ContainerInspectMany(ctx, configs...)
+		require.NoError(t, err)
+		assert.Len(t, inspects, 20)
+		assert.Equal(t, "c7", inspects["app-7"].ID, "inspects are keyed by the config name")
+		assert.LessOrEqual(t, maxInFlight, inspectManyConcurrency)
+		assert.Greater(t, maxInFlight, 1)
+	})
+
+	t.Run("Failures do not stop the others", func(t *testing.T) {
+		inspects, err := c.ContainerInspectMany(ctx,
+			&container.ContainerConfig{Id: "c1"},
+			&container.ContainerConfig{Id: "missing", Name: "gone"},
+		)
+		var notFound *errdefs.ResourceNotFoundError
+		require.ErrorAs(t, err, &notFound)
+		assert.Equal(t, "missing", notFound.ID)
+		assert.Contains(t, inspects, "c1-name", "inspects are keyed by the container name without a config name")
+		assert.NotContains(t, inspects, "gone")
+	})
+
+	t.Run("Invalid config", func(t *testing.T) {
+		_, err := c.ContainerInspectMany(ctx, &container.ContainerConfig{Id: "c1"}, nil)
+		assert.True(t, errdefs.IsInvalidConfig(err))
+	})
+
+	t.Run("By label", func(t *testing.T) {
+		inspects, err := c.ContainerInspectByLabel(ctx, "com.example.stack=shop, com.example.tier")
+		require.NoError(t, err)
+		assert.Len(t, inspects, 2)
+		assert.Equal(t, "c2", inspects["c2-name"].ID)
+
+		_, err = c.ContainerInspectByLabel(ctx, "")
+		assert.True(t, errdefs.IsInvalidConfig(err))
+	})
+}
@@ -0,0 +1,459 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/aptd3v/godock/pkg/godock/runoptions"
+)
+
+// LabelQueue is the label set on every container run by a queue, with the job ID.
+const LabelQueue = "godock.jobs.id"
+
+const (
+	// DefaultConcurrency is the number of jobs a queue runs at the same time by default.
+	DefaultConcurrency = 4
+	// DefaultOutputLimit is the number of bytes of stdout and of stderr kept per job by default.
+	DefaultOutputLimit = 64 << 10
+)
+
+// State is the state of a job in a queue.
+type State string
+
+const (
+	// Pending jobs wait for a free slot.
+	Pending State = "pending"
+	// Running jobs have a container running.
+	Running State = "running"
+	// Retrying jobs failed and wait for the backoff before their next attempt.
+	Retrying State = "retrying"
+	// Succeeded jobs exited with code 0.
+	Succeeded State = "succeeded"
+	// Failed jobs failed their last attempt.
+	Failed State = "failed"
+	// Canceled jobs were cancelled before they finished.
+	Canceled State = "canceled"
+)
+
+// Done reports whether the state is final.
+func (s State) Done() bool {
+	return s == Succeeded || s == Failed || s == Canceled
+}
+
+// Status is a snapshot of a job.
+type Status struct {
+	// ID is the name of the submitted container config.
+	ID    string
+	State State
+	// Attempts is the number of containers started for the job so far.
+	Attempts int
+	// ContainerID is the container of the last attempt.
+	ContainerID string
+	// ExitCode is the exit code of the last attempt, or -1 when it did not exit on its own.
+	ExitCode int
+	// Stdout and Stderr hold the output of the last attempt, up to the output limit of the queue.
+	Stdout string
+	Stderr string
+	// Artifacts holds the host paths of the files extracted from the last attempt.
+	Artifacts []string
+	Err       error
+
+	Submitted time.Time
+	Started   time.Time
+	Finished  time.Time
+}
+
+// Job is a submitted container config.
+type Job struct {
+	queue  *Queue
+	config *container.ContainerConfig
+	done   chan struct{}
+	status Status
+}
+
+// Status returns a snapshot of the job.
+func (j *Job) Status() Status {
+	j.queue.mu.Lock()
+	defer j.queue.mu.Unlock()
+	return j.status
+}
+
+// Done returns a channel closed when the job has succeeded, failed or been cancelled.
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// outcome is the result of a single attempt.
+type outcome struct {
+	exitCode       int
+	stdout, stderr string
+	artifacts      []string
+	err            error
+}
+
+/*
+Queue runs batches of containers to completion, at most a fixed number at a time. Failed jobs are retried with
+an exponential backoff, and the exit code and output of every job are kept for inspection.
+
+Usage example:
+
+	queue := jobs.New(client, jobs.WithConcurrency(3), jobs.WithRetry(2, 5*time.Second))
+	for i, shard := range shards {
+		cfg := container.NewConfig(fmt.Sprintf("transcode-%d", i))
+		cfg.SetContainerOptions(
+			containeroptions.Image(image.NewConfig("transcoder:latest")),
+			containeroptions.CMD("transcode", shard),
+		)
+		if _, err := queue.Submit(ctx, cfg); err != nil {
+			return err
+		}
+	}
+	statuses, err := queue.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		fmt.Printf("%s: %s (exit code %d)\n", status.ID, status.State, status.ExitCode)
+	}
+*/
+type Queue struct {
+	client         *godock.Client
+	clock          clock.Clock
+	retries        int
+	backoff        time.Duration
+	outputLimit    int
+	keepContainers bool
+	artifacts      []artifact
+	onUpdate       func(Status)
+	// run runs one attempt of a job; replaced in tests.
+	run func(ctx context.Context, cfg *container.ContainerConfig) outcome
+
+	slots chan struct{}
+	mu    sync.Mutex
+	jobs  []*Job
+	byID  map[string]*Job
+	wg    sync.WaitGroup
+}
+
+// SetQueueOptFn is a function type that configures a Queue.
+type SetQueueOptFn func(q *Queue)
+
+// New creates a Queue running containers through client.
+func New(client *godock.Client, setOptFns ...SetQueueOptFn) *Queue {
+	q := &Queue{
+		client:      client,
+		backoff:     time.Second,
+		outputLimit: DefaultOutputLimit,
+		slots:       make(chan struct{}, DefaultConcurrency),
+		byID:        make(map[string]*Job),
+	}
+	if client != nil {
+		q.clock = client.Clock()
+	} else {
+		q.clock = clock.Real()
+	}
+	q.run = q.runContainer
+	for _, set := range setOptFns {
+		if set != nil {
+			set(q)
+		}
+	}
+	return q
+}
+
+// WithConcurrency sets the number of jobs run at the same time. The default is DefaultConcurrency.
+func WithConcurrency(n int) SetQueueOptFn {
+	return func(q *Queue) {
+		if n > 0 {
+			q.slots = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithRetry retries a failed job up to retries times, waiting backoff before the first retry and doubling it
+// after every further attempt. By default failed jobs are not retried.
+func WithRetry(retries int, backoff time.Duration) SetQueueOptFn {
+	return func(q *Queue) {
+		if retries >= 0 {
+			q.retries = retries
+		}
+		if backoff > 0 {
+			q.backoff = backoff
+		}
+	}
+}
+
+// WithOutputLimit sets the number of bytes of stdout and of stderr kept per job; the rest is dropped.
+// A limit of 0 disables output collection. The default is DefaultOutputLimit.
+func WithOutputLimit(n int) SetQueueOptFn {
+	return func(q *Queue) {
+		if n >= 0 {
+			q.outputLimit = n
+		}
+	}
+}
+
+// KeepContainers keeps the containers of finished attempts. By default they are removed once their exit
+// code and output have been collected.
+func KeepContainers() SetQueueOptFn {
+	return func(q *Queue) {
+		q.keepContainers = true
+	}
+}
+
+// artifact is a path collected from the container of every job.
+type artifact struct {
+	containerPath string
+	destDir       string
+	opts          []godock.ArtifactOptionFn
+}
+
+/*
+WithArtifacts extracts containerPath, a path or pattern, from the container of every attempt into a directory
+named after the job below destDir, see Client.ExtractArtifact. Failing to extract the artifact fails the attempt.
+Pass godock.ExtractOnFailure to collect it from failed attempts too.
+
+Usage example:
+
+	queue := jobs.New(client, jobs.WithArtifacts("/out/*.mp4", "./videos"))
+*/
+func WithArtifacts(containerPath, destDir string, artifactOptionFns ...godock.ArtifactOptionFn) SetQueueOptFn {
+	return func(q *Queue) {
+		q.artifacts = append(q.artifacts, artifact{containerPath: containerPath, destDir: destDir, opts: artifactOptionFns})
+	}
+}
+
+// WithClock sets the clock the retry backoff waits on, e.g. a clock.Fake in tests. It defaults to the
+// client's clock.
+func WithClock(c clock.Clock) SetQueueOptFn {
+	return func(q *Queue) {
+		if c != nil {
+			q.clock = c
+		}
+	}
+}
+
+// OnUpdate sets a function called with the status of a job every time its state changes. It is called from
+// the goroutine running the job, so it must not block for long.
+func OnUpdate(fn func(Status)) SetQueueOptFn {
+	return func(q *Queue) {
+		q.onUpdate = fn
+	}
+}
+
+/*
+Submit queues a container config and returns its job, which runs as soon as a slot is free. The config is
+copied, so it may be reused, and its name is the job ID, which must be unique in the queue. Cancelling ctx
+cancels the job, removing its container.
+*/
+func (q *Queue) Submit(ctx context.Context, containerConfig *container.ContainerConfig) (*Job, error) {
+	if containerConfig == nil || containerConfig.Name == "" || containerConfig.Options == nil {
+		return nil, &errdefs.ValidationError{Field: "containerConfig", Message: "container config, name or options cannot be empty"}
+	}
+
+	q.mu.Lock()
+	if _, ok := q.byID[containerConfig.Name]; ok {
+		q.mu.Unlock()
+		return nil, &errdefs.ValidationError{Field: "Name", Message: fmt.Sprintf("job %s is already submitted", containerConfig.Name)}
+	}
+	job := &Job{
+		queue:  q,
+		config: containerConfig.Clone(),
+		done:   make(chan struct{}),
+		status: Status{ID: containerConfig.Name, State: Pending, ExitCode: -1, Submitted: q.clock.Now()},
+	}
+	q.jobs = append(q.jobs, job)
+	q.byID[job.status.ID] = job
+	q.wg.Add(1)
+	q.mu.Unlock()
+
+	go func() {
+		defer q.wg.Done()
+		defer close(job.done)
+		q.process(ctx, job)
+	}()
+	return job, nil
+}
+
+// Status returns a snapshot of a job, and false when no job with the ID was submitted.
+func (q *Queue) Status(id string) (Status, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.byID[id]
+	if !ok {
+		return Status{}, false
+	}
+	return job.status, true
+}
+
+// Statuses returns a snapshot of every job, in submission order.
+func (q *Queue) Statuses() []Status {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	statuses := make([]Status, len(q.jobs))
+	for i, job := range q.jobs {
+		statuses[i] = job.status
+	}
+	return statuses
+}
+
+// Wait waits until every submitted job is done and returns their statuses in submission order. It returns
+// early with the context error when ctx is done, which leaves the jobs running.
+func (q *Queue) Wait(ctx context.Context) ([]Status, error) {
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return q.Statuses(), nil
+	case <-ctx.Done():
+		return q.Statuses(), ctx.Err()
+	}
+}
+
+// process runs a job in a slot, retrying it as configured.
+func (q *Queue) process(ctx context.Context, job *Job) {
+	select {
+	case q.slots <- struct{}{}:
+	case <-ctx.Done():
+		q.finish(job, Canceled, outcome{exitCode: -1, err: ctx.Err()})
+		return
+	}
+	defer func() { <-q.slots }()
+
+	backoff := q.backoff
+	for attempt := 1; ; attempt++ {
+		cfg := job.config.Clone()
+		cfg.Id = ""
+		if attempt > 1 {
+			// Kept containers of earlier attempts still hold the job's name.
+			cfg.Name = fmt.Sprintf("%s-%d", job.status.ID, attempt)
+		}
+		cfg.SetContainerOptions(containeroptions.Label(LabelQueue, job.status.ID))
+
+		q.update(job, func(status *Status) {
+			status.State = Running
+			status.Attempts = attempt
+			if attempt == 1 {
+				status.Started = q.clock.Now()
+			}
+		})
+		result := q.run(ctx, cfg)
+		q.update(job, func(status *Status) {
+			status.ContainerID = cfg.Id
+			status.ExitCode = result.exitCode
+			status.Stdout = result.stdout
+			status.Stderr = result.stderr
+			status.Artifacts = result.artifacts
+			status.Err = result.err
+		})
+
+		switch {
+		case result.err == nil:
+			q.finish(job, Succeeded, result)
+			return
+		case ctx.Err() != nil:
+			q.finish(job, Canceled, result)
+			return
+		case attempt > q.retries || errdefs.IsInvalidConfig(result.err):
+			q.finish(job, Failed, result)
+			return
+		}
+
+		q.update(job, func(status *Status) {
+			status.State = Retrying
+		})
+		if err := q.clock.Sleep(ctx, backoff); err != nil {
+			q.finish(job, Canceled, result)
+			return
+		}
+		backoff *= 2
+	}
+}
+
+// update changes the status of a job and reports it.
+func (q *Queue) update(job *Job, fn func(status *Status)) {
+	q.mu.Lock()
+	previous := job.status.State
+	fn(&job.status)
+	status := job.status
+	q.mu.Unlock()
+	if q.onUpdate != nil && status.State != previous {
+		q.onUpdate(status)
+	}
+}
+
+// finish sets the final state of a job.
+func (q *Queue) finish(job *Job, state State, result outcome) {
+	q.update(job, func(status *Status) {
+		status.State = state
+		status.Err = result.err
+		status.Finished = q.clock.Now()
+	})
+}
+
+// runContainer runs one attempt of a job to completion and collects its exit code and output.
+func (q *Queue) runContainer(ctx context.Context, cfg *container.ContainerConfig) outcome {
+	result := outcome{exitCode: -1}
+	result.err = q.client.RunAndWait(ctx, cfg, runoptions.RemoveOnCancel())
+	if cfg.Id == "" || ctx.Err() != nil {
+		return result
+	}
+
+	cleanupCtx := context.WithoutCancel(ctx)
+	if exitCode, err := q.client.GetContainerExitCode(cleanupCtx, cfg); err == nil {
+		result.exitCode = exitCode
+	}
+	if q.outputLimit > 0 {
+		stdout := &limitedBuffer{limit: q.outputLimit}
+		stderr := &limitedBuffer{limit: q.outputLimit}
+		// The container has exited, so the followed log stream ends with its output.
+		if logs, err := q.client.ContainerLogs(cleanupCtx, cfg); err == nil {
+			godock.NewLogCopier(stdout, stderr).Copy(logs)
+			logs.Close()
+		}
+		result.stdout = stdout.String()
+		result.stderr = stderr.String()
+	}
+	for _, a := range q.artifacts {
+		// Attempts after the first are named after the job and the attempt, the artifacts go with the job.
+		files, err := q.client.ExtractArtifact(cleanupCtx, cfg, a.containerPath, filepath.Join(a.destDir, cfg.Options.Labels[LabelQueue]), a.opts...)
+		result.artifacts = append(result.artifacts, files...)
+		if err != nil && result.err == nil {
+			result.err = err
+		}
+	}
+	if !q.keepContainers {
+		q.client.ContainerRemove(cleanupCtx, cfg, removeoptions.Force())
+	}
+	return result
+}
+
+// limitedBuffer keeps the first limit bytes written to it and drops the rest.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
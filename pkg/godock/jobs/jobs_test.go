@@ -0,0 +1,142 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmitValidation(t *testing.T) {
+	q := New(nil)
+	_, err := q.Submit(context.Background(), nil)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	_, err = q.Submit(context.Background(), &container.ContainerConfig{Name: "job"})
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
+
+func TestQueueConcurrency(t *testing.T) {
+	q := New(nil, WithConcurrency(2))
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	q.run = func(ctx context.Context, cfg *container.ContainerConfig) outcome {
+		assert.Equal(t, cfg.Name, cfg.Options.Labels[LabelQueue])
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		cfg.Id = "id-" + cfg.Name
+		return outcome{exitCode: 0, stdout: "done " + cfg.Name}
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 6; i++ {
+		_, err := q.Submit(ctx, container.NewConfig(fmt.Sprintf("job-%d", i)))
+		require.NoError(t, err)
+	}
+	_, err := q.Submit(ctx, container.NewConfig("job-0"))
+	assert.True(t, errdefs.IsInvalidConfig(err))
+
+	statuses, err := q.Wait(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 6)
+	assert.Equal(t, 2, maxRunning)
+	for i, status := range statuses {
+		assert.Equal(t, fmt.Sprintf("job-%d", i), status.ID)
+		assert.Equal(t, Succeeded, status.State)
+		assert.Equal(t, 1, status.Attempts)
+		assert.Equal(t, "id-"+status.ID, status.ContainerID)
+		assert.Equal(t, "done "+status.ID, status.Stdout)
+	}
+}
+
+func TestQueueRetry(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, time.March, 1, 8, 0, 0, 0, time.UTC))
+	var states []State
+	q := New(nil, WithClock(fake), WithRetry(2, time.Second), OnUpdate(func(status Status) {
+		states = append(states, status.State)
+	}))
+	var names []string
+	q.run = func(ctx context.Context, cfg *container.ContainerConfig) outcome {
+		names = append(names, cfg.Name)
+		return outcome{exitCode: 1, stderr: "boom", err: errors.New("exited with code 1")}
+	}
+
+	job, err := q.Submit(context.Background(), container.NewConfig("flaky"))
+	require.NoError(t, err)
+	waitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, fake.BlockUntil(waitCtx, 1))
+	fake.Advance(time.Second)
+	// The backoff doubles after every attempt.
+	require.NoError(t, fake.BlockUntil(waitCtx, 1))
+	fake.Advance(time.Second)
+	assert.Equal(t, 1, fake.Waiters())
+	fake.Advance(time.Second)
+	<-job.Done()
+
+	status := job.Status()
+	assert.Equal(t, Failed, status.State)
+	assert.Equal(t, 3, status.Attempts)
+	assert.Equal(t, 1, status.ExitCode)
+	assert.Equal(t, "boom", status.Stderr)
+	assert.Error(t, status.Err)
+	assert.Equal(t, []string{"flaky", "flaky-2", "flaky-3"}, names)
+	assert.Equal(t, []State{Running, Retrying, Running, Retrying, Running, Failed}, states)
+}
+
+func TestQueueCancel(t *testing.T) {
+	q := New(nil, WithConcurrency(1))
+	started := make(chan struct{})
+	q.run = func(ctx context.Context, cfg *container.ContainerConfig) outcome {
+		close(started)
+		<-ctx.Done()
+		return outcome{exitCode: -1, err: ctx.Err()}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first, err := q.Submit(ctx, container.NewConfig("first"))
+	require.NoError(t, err)
+	<-started
+	second, err := q.Submit(ctx, container.NewConfig("second"))
+	require.NoError(t, err)
+	assert.Equal(t, Pending, second.Status().State)
+
+	cancel()
+	statuses, err := q.Wait(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Canceled, statuses[0].State)
+	assert.Equal(t, 1, first.Status().Attempts)
+	assert.Equal(t, Canceled, statuses[1].State)
+	assert.Equal(t, 0, statuses[1].Attempts)
+	assert.ErrorIs(t, statuses[1].Err, context.Canceled)
+
+	status, ok := q.Status("second")
+	assert.True(t, ok)
+	assert.Equal(t, statuses[1], status)
+	_, ok = q.Status("third")
+	assert.False(t, ok)
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	b := &limitedBuffer{limit: 5}
+	n, err := b.Write([]byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	n, err = b.Write([]byte("defgh"))
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, "abcde", b.String())
+}
@@ -0,0 +1,66 @@
+package godock
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// jsonMessageScanner splits the JSON progress stream of a pull, push or build into messages.
+// The stream may be fed in chunks of any size: an incomplete last line is kept until it is completed,
+// and lines that are not JSON messages are skipped.
+type jsonMessageScanner struct {
+	pending []byte
+}
+
+// write consumes the complete lines of p, calling fn for every message. It stops at the first error returned by fn.
+func (s *jsonMessageScanner) write(p []byte, fn func(msg jsonmessage.JSONMessage) error) error {
+	s.pending = append(s.pending, p...)
+	for {
+		i := bytes.IndexByte(s.pending, '\n')
+		if i < 0 {
+			return nil
+		}
+		line := bytes.TrimSpace(s.pending[:i])
+		s.pending = s.pending[i+1:]
+		if len(line) == 0 {
+			continue
+		}
+
+		var msg jsonmessage.JSONMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			continue
+		}
+		if err := fn(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// flush consumes an unterminated last line once the stream has ended.
+func (s *jsonMessageScanner) flush(fn func(msg jsonmessage.JSONMessage) error) error {
+	return s.write([]byte{'\n'}, fn)
+}
+
+// decodeJSONMessages reads a progress stream to the end, calling fn for every message.
+// It returns the first error returned by fn or by reading the stream.
+func decodeJSONMessages(r io.Reader, fn func(msg jsonmessage.JSONMessage) error) error {
+	var scanner jsonMessageScanner
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if err := scanner.write(buf[:n], fn); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return scanner.flush(fn)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package godock
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONMessageScanner(t *testing.T) {
+	var statuses []string
+	collect := func(msg jsonmessage.JSONMessage) error {
+		statuses = append(statuses, msg.Status)
+		return nil
+	}
+
+	var s jsonMessageScanner
+	require.NoError(t, s.write([]byte(`{"status":"Pulling"}`+"\r\n"+`not json`+"\n"+`{"status":"Down`), collect))
+	assert.Equal(t, []string{"Pulling"}, statuses)
+	require.NoError(t, s.write([]byte(`loading"}`+"\n"+`{"status":"Done"}`), collect))
+	assert.Equal(t, []string{"Pulling", "Downloading"}, statuses)
+	require.NoError(t, s.flush(collect))
+	assert.Equal(t, []string{"Pulling", "Downloading", "Done"}, statuses)
+}
+
+func TestDecodeJSONMessages(t *testing.T) {
+	stream := `{"status":"Pulling"}` + "\n" + `{"error":"denied","errorDetail":{"message":"denied"}}` + "\n" + `{"status":"Done"}` + "\n"
+	var statuses []string
+	err := decodeJSONMessages(strings.NewReader(stream), func(msg jsonmessage.JSONMessage) error {
+		if msg.Error != nil {
+			return errors.New(msg.Error.Message)
+		}
+		statuses = append(statuses, msg.Status)
+		return nil
+	})
+	assert.EqualError(t, err, "denied")
+	assert.Equal(t, []string{"Pulling"}, statuses)
+
+	readErr := errors.New("connection reset")
+	err = decodeJSONMessages(&failingReader{data: `{"status":"Pulling"}` + "\n", err: readErr}, func(jsonmessage.JSONMessage) error { return nil })
+	assert.ErrorIs(t, err, readErr)
+}
+
+// failingReader returns data and then fails.
+type failingReader struct {
+	data string
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
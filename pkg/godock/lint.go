@@ -0,0 +1,258 @@
+package godock
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/distribution/reference"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+)
+
+// LintSeverity is how serious a LintWarning is.
+type LintSeverity string
+
+const (
+	// LintError is reported for settings the daemon rejects or that silently do not take effect.
+	LintError LintSeverity = "error"
+	// LintWarn is reported for settings that work but weaken isolation or reproducibility.
+	LintWarn LintSeverity = "warn"
+)
+
+// LintWarning is a problem found by Lint.
+type LintWarning struct {
+	Rule     string
+	Severity LintSeverity
+	Message  string
+}
+
+// String formats the warning as a single line.
+func (w LintWarning) String() string {
+	return fmt.Sprintf("[%s] %s: %s", strings.ToUpper(string(w.Severity)), w.Rule, w.Message)
+}
+
+// Names of the rules checked by Lint.
+const (
+	LintRuleNoImage          = "no-image"
+	LintRuleLatestTag        = "latest-tag"
+	LintRulePrivileged       = "privileged"
+	LintRulePrivilegedUserNS = "privileged-userns"
+	LintRuleHostNetworkPorts = "host-network-ports"
+	LintRuleMemorySwap       = "memory-swap"
+	LintRuleDockerSocket     = "docker-socket"
+	LintRuleHostRootMount    = "host-root-mount"
+	LintRuleDangerousCaps    = "dangerous-capabilities"
+	LintRuleUnconfined       = "unconfined-security"
+	LintRuleHostNamespaces   = "host-namespaces"
+	LintRuleDeviceRequests   = "device-requests"
+	LintRuleReadonlyRootfs   = "readonly-rootfs-no-tmpfs"
+)
+
+// dockerSockets are the host paths of the docker daemon socket.
+var dockerSockets = []string{"/var/run/docker.sock", "/run/docker.sock"}
+
+// dangerousCapabilities are capabilities that give a container close to root access on the host.
+var dangerousCapabilities = []hostoptions.Capability{
+	hostoptions.ALL,
+	hostoptions.SYS_ADMIN,
+	hostoptions.SYS_MODULE,
+	hostoptions.SYS_PTRACE,
+	hostoptions.SYS_RAWIO,
+	hostoptions.NET_ADMIN,
+	hostoptions.DAC_READ_SEARCH,
+}
+
+type lintOptions struct {
+	ignore map[string]bool
+}
+
+// LintOptionFn is a function that configures Lint.
+type LintOptionFn func(*lintOptions)
+
+// WithLintIgnore skips the named rules, e.g. LintRuleDockerSocket for a CI runner that needs the socket.
+func WithLintIgnore(rules ...string) LintOptionFn {
+	return func(opts *lintOptions) {
+		for _, rule := range rules {
+			opts.ignore[rule] = true
+		}
+	}
+}
+
+/*
+Lint checks a container config for insecure or conflicting settings without contacting the daemon.
+It returns the problems found, or nil when there are none.
+
+Usage example:
+
+	warnings := godock.Lint(myContainer, godock.WithLintIgnore(godock.LintRuleLatestTag))
+	for _, w := range warnings {
+		fmt.Println(w)
+	}
+	if godock.LintHasErrors(warnings) {
+		os.Exit(1)
+	}
+*/
+func Lint(containerConfig *container.ContainerConfig, lintOptionFns ...LintOptionFn) []LintWarning {
+	opts := &lintOptions{ignore: make(map[string]bool)}
+	for _, fn := range lintOptionFns {
+		if fn != nil {
+			fn(opts)
+		}
+	}
+	if containerConfig == nil {
+		return nil
+	}
+	config := containerConfig.Options
+	if config == nil {
+		config = &containerType.Config{}
+	}
+	hostConfig := containerConfig.HostOptions
+	if hostConfig == nil {
+		hostConfig = &containerType.HostConfig{}
+	}
+
+	var warnings []LintWarning
+	add := func(rule string, severity LintSeverity, format string, args ...any) {
+		if opts.ignore[rule] {
+			return
+		}
+		warnings = append(warnings, LintWarning{Rule: rule, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	lintImage(config.Image, add)
+
+	if hostConfig.Privileged {
+		add(LintRulePrivileged, LintWarn, "privileged mode gives the container full access to the host")
+		if mode := hostConfig.UsernsMode; mode != "" && !mode.IsHost() {
+			add(LintRulePrivilegedUserNS, LintError, "privileged mode cannot be combined with user namespace mode %q", mode)
+		}
+	}
+
+	if hostConfig.NetworkMode.IsHost() && (len(hostConfig.PortBindings) > 0 || hostConfig.PublishAllPorts) {
+		add(LintRuleHostNetworkPorts, LintError, "published ports are ignored in host network mode")
+	}
+
+	switch {
+	case hostConfig.MemorySwap > 0 && hostConfig.Memory == 0:
+		add(LintRuleMemorySwap, LintError, "memory swap is set without a memory limit")
+	case hostConfig.MemorySwap > 0 && hostConfig.MemorySwap < hostConfig.Memory:
+		add(LintRuleMemorySwap, LintError, "memory swap %d is lower than the memory limit %d, it must include the memory limit", hostConfig.MemorySwap, hostConfig.Memory)
+	}
+
+	for _, source := range bindSources(hostConfig) {
+		clean := path.Clean(source)
+		for _, socket := range dockerSockets {
+			if clean == socket {
+				add(LintRuleDockerSocket, LintWarn, "mounting %s gives the container control of the docker daemon", source)
+			}
+		}
+		if clean == "/" {
+			add(LintRuleHostRootMount, LintWarn, "the host root filesystem is mounted into the container")
+		}
+	}
+
+	for _, capability := range hostConfig.CapAdd {
+		name := strings.TrimPrefix(strings.ToUpper(capability), "CAP_")
+		for _, dangerous := range dangerousCapabilities {
+			if name == string(dangerous) {
+				add(LintRuleDangerousCaps, LintWarn, "capability %s gives the container close to root access on the host", name)
+			}
+		}
+	}
+
+	for _, securityOpt := range hostConfig.SecurityOpt {
+		switch strings.Replace(securityOpt, ":", "=", 1) {
+		case "seccomp=unconfined", "apparmor=unconfined", "label=disable":
+			add(LintRuleUnconfined, LintWarn, "security option %q disables a kernel security profile", securityOpt)
+		}
+	}
+
+	for _, ns := range []struct {
+		name string
+		host bool
+	}{
+		{"pid", hostConfig.PidMode.IsHost()},
+		{"ipc", hostConfig.IpcMode.IsHost()},
+		{"uts", hostConfig.UTSMode.IsHost()},
+		{"user", hostConfig.UsernsMode.IsHost()},
+	} {
+		if ns.host {
+			add(LintRuleHostNamespaces, LintWarn, "the container shares the host %s namespace", ns.name)
+		}
+	}
+
+	if err := hostoptions.ValidateDeviceRequests(hostConfig.DeviceRequests); err != nil {
+		add(LintRuleDeviceRequests, LintError, "%s", err.Error())
+	}
+
+	if hostConfig.ReadonlyRootfs && len(hostConfig.Tmpfs) == 0 && !hasWritableMount(hostConfig) {
+		add(LintRuleReadonlyRootfs, LintWarn, "the root filesystem is read-only and no tmpfs is mounted, writes to /tmp will fail")
+	}
+
+	return warnings
+}
+
+// LintHasErrors reports whether any warning has LintError severity.
+func LintHasErrors(warnings []LintWarning) bool {
+	for _, w := range warnings {
+		if w.Severity == LintError {
+			return true
+		}
+	}
+	return false
+}
+
+func lintImage(ref string, add func(rule string, severity LintSeverity, format string, args ...any)) {
+	if ref == "" {
+		add(LintRuleNoImage, LintError, "no image is set")
+		return
+	}
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		add(LintRuleNoImage, LintError, "invalid image reference %q: %v", ref, err)
+		return
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return
+	}
+	if tagged, ok := named.(reference.Tagged); !ok || tagged.Tag() == "latest" {
+		add(LintRuleLatestTag, LintWarn, "image %s uses the latest tag, pin a version or digest", ref)
+	}
+}
+
+// bindSources returns the host paths of every bind mount of the host config.
+func bindSources(hostConfig *containerType.HostConfig) []string {
+	var sources []string
+	for _, bind := range hostConfig.Binds {
+		source, _, _ := strings.Cut(bind, ":")
+		if strings.HasPrefix(source, "/") {
+			sources = append(sources, source)
+		}
+	}
+	for _, m := range hostConfig.Mounts {
+		if m.Type == mount.TypeBind {
+			sources = append(sources, m.Source)
+		}
+	}
+	return sources
+}
+
+// hasWritableMount reports whether the host config mounts anything the container could write to.
+func hasWritableMount(hostConfig *containerType.HostConfig) bool {
+	for _, m := range hostConfig.Mounts {
+		if !m.ReadOnly {
+			return true
+		}
+	}
+	for _, bind := range hostConfig.Binds {
+		parts := strings.Split(bind, ":")
+		if len(parts) < 3 || !slices.Contains(strings.Split(parts[2], ","), "ro") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package godock
+
+import (
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/stretchr/testify/assert"
+)
+
+func lintRules(warnings []LintWarning) []string {
+	var rules []string
+	for _, w := range warnings {
+		rules = append(rules, w.Rule)
+	}
+	return rules
+}
+
+func TestLint(t *testing.T) {
+	clean := container.NewConfig("clean")
+	clean.SetContainerOptions(containeroptions.Image(image.NewConfig("redis:7")))
+	clean.SetHostOptions(hostoptions.Hardened())
+	assert.Empty(t, Lint(clean))
+
+	risky := container.NewConfig("risky")
+	risky.SetContainerOptions(containeroptions.Image(image.NewConfig("redis")))
+	risky.SetHostOptions(
+		hostoptions.Privileged(),
+		hostoptions.UserNSMode("private"),
+		hostoptions.NetworkMode("host"),
+		hostoptions.PortBindings("", "8080", "80"),
+		hostoptions.Memory(512*1024*1024),
+		hostoptions.MemorySwap(256*1024*1024),
+		hostoptions.Bind("/var/run/docker.sock:/var/run/docker.sock"),
+		hostoptions.CapAdd(hostoptions.SYS_ADMIN),
+		hostoptions.SecurityOpt("seccomp=unconfined"),
+		hostoptions.PidMode("host"),
+	)
+	warnings := Lint(risky)
+	assert.Equal(t, []string{
+		LintRuleLatestTag,
+		LintRulePrivileged,
+		LintRulePrivilegedUserNS,
+		LintRuleHostNetworkPorts,
+		LintRuleMemorySwap,
+		LintRuleDockerSocket,
+		LintRuleDangerousCaps,
+		LintRuleUnconfined,
+		LintRuleHostNamespaces,
+	}, lintRules(warnings))
+	assert.True(t, LintHasErrors(warnings))
+
+	ignored := Lint(risky, WithLintIgnore(LintRulePrivilegedUserNS, LintRuleHostNetworkPorts, LintRuleMemorySwap))
+	assert.False(t, LintHasErrors(ignored))
+	assert.NotContains(t, lintRules(ignored), LintRuleMemorySwap)
+}
+
+func TestLintImageAndMounts(t *testing.T) {
+	for ref, want := range map[string][]string{
+		"":             {LintRuleNoImage},
+		"nginx:latest": {LintRuleLatestTag},
+		"nginx:1.27":   nil,
+		"nginx@sha256:" + "0123456789012345678901234567890123456789012345678901234567890123": nil,
+	} {
+		ctr := container.NewConfig("img")
+		ctr.Options.Image = ref
+		assert.Equal(t, want, lintRules(Lint(ctr)), ref)
+	}
+
+	readonly := container.NewConfig("readonly")
+	readonly.Options.Image = "nginx:1.27"
+	readonly.SetHostOptions(hostoptions.ReadonlyRootfs(), hostoptions.Bind("/srv/config:/etc/app:ro"))
+	assert.Equal(t, []string{LintRuleReadonlyRootfs}, lintRules(Lint(readonly)))
+	readonly.SetHostOptions(hostoptions.Bind("/srv/data:/data"))
+	assert.Empty(t, Lint(readonly))
+
+	root := container.NewConfig("root")
+	root.Options.Image = "nginx:1.27"
+	root.SetHostOptions(hostoptions.Bind("/:/host:ro"))
+	assert.Equal(t, []string{LintRuleHostRootMount}, lintRules(Lint(root)))
+}
@@ -1,8 +1,12 @@
 package godock
 
 import (
+	"context"
+	"errors"
 	"io"
+	"os"
 
+	"github.com/aptd3v/godock/pkg/godock/container"
 	"github.com/docker/docker/pkg/stdcopy"
 )
 
@@ -52,3 +56,51 @@ func (w *prefixWriter) Write(p []byte) (n int, err error) {
 	}
 	return len(p), nil
 }
+
+/*
+CopyContainerLogs copies the logs of a container to the writers set with WithLogWriter, os.Stdout and os.Stderr by
+default, and returns once the logs end. The logs are followed until the container stops, like ContainerLogs, so it
+blocks while the container runs; it returns the context's error as soon as ctx is done.
+
+Usage example:
+
+	client, err := godock.NewClient(ctx, godock.WithLogWriter(&stdout, &stderr))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	err = client.CopyContainerLogs(ctx, app, godock.WithLogsTail(100))
+*/
+func (c *Client) CopyContainerLogs(ctx context.Context, containerConfig *container.ContainerConfig, logsOptionFns ...LogsOptionFn) error {
+	inspect, err := c.inspectContainer(ctx, containerConfig.Id)
+	if err != nil {
+		return err
+	}
+	rc, err := c.ContainerLogs(ctx, containerConfig, logsOptionFns...)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	// Closing the stream unblocks a read waiting for the next line of a followed container.
+	stop := context.AfterFunc(ctx, func() { rc.Close() })
+	defer stop()
+
+	stdout, stderr := c.logStdout, c.logStderr
+	if stdout == nil {
+		stdout, stderr = os.Stdout, os.Stderr
+	}
+	if inspect.Config != nil && inspect.Config.Tty {
+		// The logs of a container with a TTY are not multiplexed.
+		_, err = io.Copy(stdout, rc)
+	} else {
+		_, err = NewLogCopier(stdout, stderr).Copy(rc)
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
@@ -2,11 +2,17 @@ package godock
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"io"
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewLogCopier(t *testing.T) {
@@ -120,3 +126,43 @@ type errorWriter struct{}
 func (w *errorWriter) Write(p []byte) (n int, err error) {
 	return 0, io.ErrShortWrite
 }
+
+func TestCopyContainerLogs(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1.45/containers/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"Id":"`+r.PathValue("id")+`","Config":{"Tty":false}}`)
+	})
+	mux.HandleFunc("GET /v1.45/containers/{id}/logs", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		stdcopy.NewStdWriter(w, stdcopy.Stdout).Write([]byte("ready\n"))
+		stdcopy.NewStdWriter(w, stdcopy.Stderr).Write([]byte("warning\n"))
+		w.(http.Flusher).Flush()
+		if r.PathValue("id") == "running" {
+			// A followed container that keeps running.
+			<-r.Context().Done()
+		}
+	})
+	c := newFakeDaemonClient(t, mux)
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	c.logStdout, c.logStderr = stdout, stderr
+
+	require.NoError(t, c.CopyContainerLogs(context.Background(), &container.ContainerConfig{Id: "exited"}))
+	assert.Equal(t, "ready\n", stdout.String())
+	assert.Equal(t, "warning\n", stderr.String())
+
+	t.Run("Cancelled while following", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- c.CopyContainerLogs(ctx, &container.ContainerConfig{Id: "running"})
+		}()
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+		select {
+		case err := <-done:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(5 * time.Second):
+			t.Fatal("CopyContainerLogs did not return after the context was cancelled")
+		}
+	})
+}
@@ -0,0 +1,23 @@
+package godock
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Logger receives the diagnostics of the client, with the operation and the resource it concerns as attributes.
+// *slog.Logger implements it.
+type Logger interface {
+	DebugContext(ctx context.Context, msg string, args ...any)
+	InfoContext(ctx context.Context, msg string, args ...any)
+	WarnContext(ctx context.Context, msg string, args ...any)
+	ErrorContext(ctx context.Context, msg string, args ...any)
+}
+
+// log returns the logger set with WithLogger, or slog.Default.
+func (c *Client) log() Logger {
+	if c.logger == nil {
+		return slog.Default()
+	}
+	return c.logger
+}
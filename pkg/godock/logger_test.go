@@ -0,0 +1,29 @@
+package godock
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/volume"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientLogger(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1.45/volumes/create", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"Name":"data","Mountpoint":"/var/lib/docker/volumes/data/_data"}`)
+	})
+	c := newFakeDaemonClient(t, mux)
+	var out bytes.Buffer
+	c.logger = slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	require.NoError(t, c.VolumeCreate(context.Background(), volume.NewConfig("data")))
+	assert.Contains(t, out.String(), `msg="creating volume" op="volume create" volume=data`)
+	assert.Contains(t, out.String(), `msg="created volume" op="volume create" volume=data mountpoint=/var/lib/docker/volumes/data/_data`)
+
+}
@@ -0,0 +1,409 @@
+package logshipper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+const (
+	// DefaultBatchSize is the number of lines shipped to the sinks at once by default.
+	DefaultBatchSize = 100
+	// DefaultFlushInterval is the longest a line waits for its batch to fill by default.
+	DefaultFlushInterval = time.Second
+)
+
+// Line is a log line of a container.
+type Line struct {
+	ContainerID   string            `json:"container_id"`
+	ContainerName string            `json:"container_name"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	// Stream is "stdout" or "stderr".
+	Stream string    `json:"stream"`
+	Time   time.Time `json:"time"`
+	Text   string    `json:"text"`
+}
+
+// Sink receives batches of log lines. Ship is called from a single goroutine, in the order the lines were read.
+type Sink interface {
+	Ship(ctx context.Context, lines []Line) error
+}
+
+// SinkFunc adapts a function to a Sink.
+type SinkFunc func(ctx context.Context, lines []Line) error
+
+// Ship calls f.
+func (f SinkFunc) Ship(ctx context.Context, lines []Line) error {
+	return f(ctx, lines)
+}
+
+// target is a followed container.
+type target struct {
+	id     string
+	name   string
+	labels map[string]string
+	tty    bool
+}
+
+/*
+Shipper follows the logs of the containers matching its label selector and ships every line to its sinks in
+batches. Containers are picked up when they start, and a restarted container is followed again from its last
+shipped line, so no line is shipped twice.
+
+Usage example:
+
+	files, err := logshipper.NewFileSink("/var/log/containers.log", 100<<20, 5)
+	if err != nil {
+		return err
+	}
+	defer files.Close()
+	shipper := logshipper.New(client, []logshipper.Sink{
+		files,
+		logshipper.NewLokiSink("http://loki:3100/loki/api/v1/push", map[string]string{"host": hostname}),
+	},
+		logshipper.WithLabel("com.example.ship-logs", "true"),
+		logshipper.OnError(func(err error) { log.Printf("log shipping: %v", err) }),
+	)
+	err = shipper.Run(ctx)
+*/
+type Shipper struct {
+	client        *godock.Client
+	clock         clock.Clock
+	sinks         []Sink
+	labels        []string
+	batchSize     int
+	flushInterval time.Duration
+	onError       func(err error)
+
+	mu sync.Mutex
+	// following holds the followed containers, and whether they restarted while their logs were read.
+	following map[string]bool
+	// last holds the time of the last line read per container, to resume after a restart.
+	last  map[string]time.Time
+	lines chan Line
+	wg    sync.WaitGroup
+}
+
+// SetShipperOptFn is a function type that configures a Shipper.
+type SetShipperOptFn func(s *Shipper)
+
+// New creates a Shipper shipping to sinks. Without a label selector it follows every container.
+func New(client *godock.Client, sinks []Sink, setOptFns ...SetShipperOptFn) *Shipper {
+	s := &Shipper{
+		client:        client,
+		sinks:         sinks,
+		batchSize:     DefaultBatchSize,
+		flushInterval: DefaultFlushInterval,
+		following:     make(map[string]bool),
+		last:          make(map[string]time.Time),
+	}
+	if client != nil {
+		s.clock = client.Clock()
+	} else {
+		s.clock = clock.Real()
+	}
+	for _, set := range setOptFns {
+		if set != nil {
+			set(s)
+		}
+	}
+	return s
+}
+
+// WithLabel only follows containers with the label. An empty value matches any value. Several labels must
+// all match.
+func WithLabel(key, value string) SetShipperOptFn {
+	return func(s *Shipper) {
+		if value == "" {
+			s.labels = append(s.labels, key)
+		} else {
+			s.labels = append(s.labels, key+"="+value)
+		}
+	}
+}
+
+// WithBatch sets the number of lines shipped at once and the longest a line waits for its batch to fill.
+// The defaults are DefaultBatchSize and DefaultFlushInterval.
+func WithBatch(size int, flushInterval time.Duration) SetShipperOptFn {
+	return func(s *Shipper) {
+		if size > 0 {
+			s.batchSize = size
+		}
+		if flushInterval > 0 {
+			s.flushInterval = flushInterval
+		}
+	}
+}
+
+// OnError sets a function called when following a container or shipping a batch to a sink fails. The batch
+// is not shipped to that sink again.
+func OnError(fn func(err error)) SetShipperOptFn {
+	return func(s *Shipper) {
+		s.onError = fn
+	}
+}
+
+// WithClock sets the clock batches are flushed on, e.g. a clock.Fake in tests. It defaults to the client's
+// clock.
+func WithClock(c clock.Clock) SetShipperOptFn {
+	return func(s *Shipper) {
+		if c != nil {
+			s.clock = c
+		}
+	}
+}
+
+/*
+Run follows the matching containers and ships their logs until ctx is done or the event stream fails.
+Containers running when Run starts are followed from their next line; containers started later are followed
+from their first line. The lines read before Run returns are still shipped.
+*/
+func (s *Shipper) Run(ctx context.Context) error {
+	if len(s.sinks) == 0 {
+		return errors.New("log shipper has no sinks")
+	}
+	s.lines = make(chan Line, s.batchSize)
+	shipped := make(chan struct{})
+	go func() {
+		defer close(shipped)
+		s.ship(ctx)
+	}()
+	defer func() {
+		s.wg.Wait()
+		close(s.lines)
+		<-shipped
+	}()
+
+	filters := []godock.EventsOptionFn{
+		godock.WithEventFilter("type", string(events.ContainerEventType)),
+		godock.WithEventFilter("event", string(events.ActionStart)),
+		godock.WithEventFilter("event", string(events.ActionDestroy)),
+	}
+	listFilters := []godock.ListContainerOptionFn{godock.WithContainerFilters(godock.FilterByStatus(godock.StatusRunning))}
+	for _, label := range s.labels {
+		filters = append(filters, godock.WithEventFilter("label", label))
+		listFilters = append(listFilters, godock.WithContainerFilter("label", label))
+	}
+	msgCh, errCh := s.client.Events(ctx, filters...)
+
+	running, err := s.client.ContainerList(ctx, listFilters...)
+	if err != nil {
+		return err
+	}
+	for _, c := range running {
+		s.attach(ctx, c.ID, true)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errCh:
+			if errors.Is(err, context.Canceled) {
+				return ctx.Err()
+			}
+			return fmt.Errorf("log shipper event stream failed: %w", err)
+		case msg := <-msgCh:
+			if msg.Action == events.ActionDestroy {
+				s.mu.Lock()
+				delete(s.last, msg.Actor.ID)
+				s.mu.Unlock()
+				continue
+			}
+			s.attach(ctx, msg.Actor.ID, false)
+		}
+	}
+}
+
+// attach starts following a container. When it is followed already, because the stream of its previous run
+// is still being read, it is followed again once that stream ends. tailOnly skips the existing logs.
+func (s *Shipper) attach(ctx context.Context, id string, tailOnly bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.following[id]; ok {
+		s.following[id] = true
+		return
+	}
+	s.following[id] = false
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			s.mu.Lock()
+			since := s.last[id]
+			s.mu.Unlock()
+			err := s.follow(ctx, id, since, tailOnly)
+			if err != nil && ctx.Err() == nil {
+				s.reportError(err)
+			}
+
+			s.mu.Lock()
+			again := s.following[id] && ctx.Err() == nil
+			if !again {
+				delete(s.following, id)
+				s.mu.Unlock()
+				return
+			}
+			s.following[id] = false
+			s.mu.Unlock()
+			tailOnly = false
+		}
+	}()
+}
+
+// follow reads the logs of a container until it stops, starting after since when it is set.
+func (s *Shipper) follow(ctx context.Context, id string, since time.Time, tailOnly bool) error {
+	inspect, err := s.client.ContainerInspect(ctx, &container.ContainerConfig{Id: id})
+	if err != nil {
+		return err
+	}
+	t := target{id: id, name: strings.TrimPrefix(inspect.Name, "/")}
+	if inspect.Config != nil {
+		t.labels = inspect.Config.Labels
+		t.tty = inspect.Config.Tty
+	}
+
+	opts := []godock.LogsOptionFn{godock.WithLogsTimestamps()}
+	switch {
+	case !since.IsZero():
+		// Since is inclusive, so resume just after the last line.
+		since = since.Add(time.Nanosecond)
+		opts = append(opts, godock.WithLogsSince(fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond())))
+	case tailOnly:
+		opts = append(opts, godock.WithLogsTail(0))
+	}
+	rc, err := s.client.ContainerLogs(ctx, &container.ContainerConfig{Id: id, Name: t.name}, opts...)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return s.read(ctx, t, rc)
+}
+
+// read splits a log stream into lines and queues them for shipping.
+func (s *Shipper) read(ctx context.Context, t target, r io.Reader) error {
+	emit := func(stream string, raw []byte) {
+		line := Line{ContainerID: t.id, ContainerName: t.name, Labels: t.labels, Stream: stream}
+		line.Time, line.Text = parseTimestamp(raw)
+		if !line.Time.IsZero() {
+			s.mu.Lock()
+			s.last[t.id] = line.Time
+			s.mu.Unlock()
+		}
+		select {
+		case s.lines <- line:
+		case <-ctx.Done():
+		}
+	}
+	stdout := &lineWriter{stream: "stdout", emit: emit}
+	stderr := &lineWriter{stream: "stderr", emit: emit}
+	var err error
+	if t.tty {
+		_, err = io.Copy(stdout, r)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, r)
+	}
+	stdout.flush()
+	stderr.flush()
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// parseTimestamp splits the RFC3339Nano timestamp prefixed to a log line from its text.
+func parseTimestamp(raw []byte) (time.Time, string) {
+	text := strings.TrimSuffix(string(raw), "\r")
+	prefix, rest, ok := strings.Cut(text, " ")
+	if !ok {
+		prefix, rest = text, ""
+	}
+	ts, err := time.Parse(time.RFC3339Nano, prefix)
+	if err != nil {
+		return time.Time{}, text
+	}
+	return ts, rest
+}
+
+// ship batches the queued lines and ships them to every sink.
+func (s *Shipper) ship(ctx context.Context) {
+	// Lines read before Run returned are still shipped.
+	ctx = context.WithoutCancel(ctx)
+	batch := make([]Line, 0, s.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		for _, sink := range s.sinks {
+			if err := sink.Ship(ctx, batch); err != nil {
+				s.reportError(err)
+			}
+		}
+		batch = make([]Line, 0, s.batchSize)
+	}
+
+	var tick <-chan time.Time
+	for {
+		select {
+		case line, ok := <-s.lines:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, line)
+			if len(batch) >= s.batchSize {
+				flush()
+				tick = nil
+			} else if tick == nil {
+				tick = s.clock.After(s.flushInterval)
+			}
+		case <-tick:
+			flush()
+			tick = nil
+		}
+	}
+}
+
+func (s *Shipper) reportError(err error) {
+	if s.onError != nil {
+		s.onError(err)
+	}
+}
+
+// lineWriter splits written bytes into lines, keeping an unterminated line until it is completed or flushed.
+type lineWriter struct {
+	stream string
+	buf    []byte
+	emit   func(stream string, line []byte)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(w.stream, w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush emits an unterminated last line.
+func (w *lineWriter) flush() {
+	if len(w.buf) > 0 {
+		w.emit(w.stream, w.buf)
+		w.buf = nil
+	}
+}
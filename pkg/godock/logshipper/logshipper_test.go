@@ -0,0 +1,190 @@
+package logshipper
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	ts, text := parseTimestamp([]byte("2026-03-01T08:00:00.123456789Z listening on :8080\r"))
+	assert.Equal(t, time.Date(2026, time.March, 1, 8, 0, 0, 123456789, time.UTC), ts)
+	assert.Equal(t, "listening on :8080", text)
+
+	ts, text = parseTimestamp([]byte("2026-03-01T08:00:00Z"))
+	assert.False(t, ts.IsZero())
+	assert.Empty(t, text)
+
+	ts, text = parseTimestamp([]byte("no timestamp"))
+	assert.True(t, ts.IsZero())
+	assert.Equal(t, "no timestamp", text)
+}
+
+func TestRead(t *testing.T) {
+	var stream bytes.Buffer
+	stdout := stdcopy.NewStdWriter(&stream, stdcopy.Stdout)
+	stderr := stdcopy.NewStdWriter(&stream, stdcopy.Stderr)
+	stdout.Write([]byte("2026-03-01T08:00:01Z starting\n2026-03-01T08:00:02Z lis"))
+	stderr.Write([]byte("2026-03-01T08:00:03Z warning: slow disk\n"))
+	stdout.Write([]byte("tening\n2026-03-01T08:00:04Z unterminated"))
+
+	s := New(nil, nil)
+	s.lines = make(chan Line, 10)
+	tgt := target{id: "abc", name: "api", labels: map[string]string{"team": "core"}}
+	require.NoError(t, s.read(context.Background(), tgt, &stream))
+	close(s.lines)
+
+	var got []string
+	for line := range s.lines {
+		assert.Equal(t, "api", line.ContainerName)
+		assert.Equal(t, "core", line.Labels["team"])
+		got = append(got, line.Stream+" "+line.Time.Format(time.TimeOnly)+" "+line.Text)
+	}
+	assert.Equal(t, []string{
+		"stdout 08:00:01 starting",
+		"stderr 08:00:03 warning: slow disk",
+		"stdout 08:00:02 listening",
+		"stdout 08:00:04 unterminated",
+	}, got)
+	assert.Equal(t, time.Date(2026, time.March, 1, 8, 0, 4, 0, time.UTC), s.last["abc"])
+}
+
+func TestReadTTY(t *testing.T) {
+	s := New(nil, nil)
+	s.lines = make(chan Line, 10)
+	tty := strings.NewReader("2026-03-01T08:00:01Z $ ls\r\n2026-03-01T08:00:02Z bin\r\n")
+	require.NoError(t, s.read(context.Background(), target{id: "abc", tty: true}, tty))
+	close(s.lines)
+
+	var got []string
+	for line := range s.lines {
+		assert.Equal(t, "stdout", line.Stream)
+		got = append(got, line.Text)
+	}
+	assert.Equal(t, []string{"$ ls", "bin"}, got)
+}
+
+func TestShipBatches(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, time.March, 1, 8, 0, 0, 0, time.UTC))
+	batches := make(chan []string, 10)
+	sink := SinkFunc(func(ctx context.Context, lines []Line) error {
+		var texts []string
+		for _, line := range lines {
+			texts = append(texts, line.Text)
+		}
+		batches <- texts
+		return nil
+	})
+	s := New(nil, []Sink{sink}, WithClock(fake), WithBatch(3, time.Second))
+	s.lines = make(chan Line)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.ship(context.Background())
+	}()
+
+	for _, text := range []string{"1", "2", "3", "4"} {
+		s.lines <- Line{Text: text}
+	}
+	assert.Equal(t, []string{"1", "2", "3"}, <-batches)
+
+	// An incomplete batch is shipped after the flush interval. The timer of the first, full batch is
+	// still pending on the fake clock.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, fake.BlockUntil(ctx, 2))
+	fake.Advance(time.Second)
+	assert.Equal(t, []string{"4"}, <-batches)
+
+	// The rest is shipped when the shipper stops.
+	s.lines <- Line{Text: "5"}
+	close(s.lines)
+	<-done
+	assert.Equal(t, []string{"5"}, <-batches)
+}
+
+func TestShipperRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	client, err := godock.NewClient(ctx)
+	if err != nil {
+		t.Skipf("Docker daemon is not running: %v", err)
+	}
+	img := image.NewConfig(godock.DefaultDoctorProbeImage)
+	require.NoError(t, client.EnsureImage(ctx, img, godock.PullPolicyIfNotPresent))
+
+	label := "godock.test.logshipper." + godock.GenerateRandomString(8)
+	lines := make(chan Line, 100)
+	s := New(client, []Sink{SinkFunc(func(ctx context.Context, batch []Line) error {
+		for _, line := range batch {
+			lines <- line
+		}
+		return nil
+	})}, WithLabel(label, "true"), WithBatch(1, 100*time.Millisecond))
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(runCtx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	time.Sleep(500 * time.Millisecond)
+
+	ctr := container.NewConfig("godock-logshipper-" + godock.GenerateRandomString(8))
+	ctr.SetContainerOptions(
+		containeroptions.Image(img),
+		containeroptions.Label(label, "true"),
+		containeroptions.CMD("sh", "-c", "echo run; echo oops >&2"),
+	)
+	require.NoError(t, client.ContainerCreate(ctx, ctr))
+	defer client.ContainerRemove(context.WithoutCancel(ctx), ctr, removeoptions.Force())
+
+	// Every run is shipped once, the restart resuming after the last shipped line.
+	next := func() Line {
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(30 * time.Second):
+			t.Fatal("no log line shipped")
+			return Line{}
+		}
+	}
+	for run := 0; run < 2; run++ {
+		require.NoError(t, client.ContainerStart(ctx, ctr))
+		got := map[string]string{}
+		for i := 0; i < 2; i++ {
+			line := next()
+			assert.Equal(t, ctr.Name, line.ContainerName)
+			got[line.Stream] = line.Text
+		}
+		assert.Equal(t, map[string]string{"stdout": "run", "stderr": "oops"}, got)
+		statusCh, errCh := client.ContainerWait(ctx, ctr)
+		select {
+		case <-statusCh:
+		case err := <-errCh:
+			require.NoError(t, err)
+		}
+	}
+	select {
+	case line := <-lines:
+		t.Fatalf("line shipped twice: %+v", line)
+	case <-time.After(time.Second):
+	}
+}
@@ -0,0 +1,354 @@
+package logshipper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/*
+FileSink writes log lines to a file, one line per log line with its time, container and stream, and rotates
+the file when it would grow beyond a maximum size. Rotated files are suffixed .1, .2 and so on, .1 being the
+newest.
+
+Usage example:
+
+	files, err := logshipper.NewFileSink("/var/log/containers.log", 100<<20, 5)
+	if err != nil {
+		return err
+	}
+	defer files.Close()
+*/
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink opens path for appending. The file is rotated once it would exceed maxSize bytes, keeping
+// maxBackups rotated files; a maxSize of 0 never rotates.
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	s := &FileSink{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	s.file, s.size = file, info.Size()
+	return nil
+}
+
+// Ship appends the lines to the file, rotating it when needed.
+func (s *FileSink) Ship(ctx context.Context, lines []Line) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return fmt.Errorf("log file %s is closed", s.path)
+	}
+	for _, line := range lines {
+		entry := fmt.Sprintf("%s %s %s %s\n", line.Time.UTC().Format(time.RFC3339Nano), line.ContainerName, line.Stream, line.Text)
+		if s.maxSize > 0 && s.size > 0 && s.size+int64(len(entry)) > s.maxSize {
+			if err := s.rotate(); err != nil {
+				return err
+			}
+		}
+		n, err := io.WriteString(s.file, entry)
+		s.size += int64(n)
+		if err != nil {
+			return fmt.Errorf("failed to write log file: %w", err)
+		}
+	}
+	return nil
+}
+
+// rotate shifts the rotated files, moves the current file to .1 and opens a new one.
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	s.file = nil
+	if s.maxBackups <= 0 {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+		return s.open()
+	}
+	os.Remove(fmt.Sprintf("%s.%d", s.path, s.maxBackups))
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", s.path, i), fmt.Sprintf("%s.%d", s.path, i+1))
+	}
+	if err := os.Rename(s.path, s.path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	return s.open()
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// Syslog facility and severities used by SyslogSink.
+const (
+	syslogFacilityUser = 1
+	syslogSeverityErr  = 3
+	syslogSeverityInfo = 6
+)
+
+/*
+SyslogSink sends log lines to a syslog server as RFC 5424 messages, with the container name as process ID.
+stdout lines are sent with severity info and stderr lines with severity err, both with facility user.
+
+Usage example:
+
+	syslog, err := logshipper.NewSyslogSink("udp", "logs.example.com:514", "godock")
+	if err != nil {
+		return err
+	}
+	defer syslog.Close()
+*/
+type SyslogSink struct {
+	network  string
+	address  string
+	tag      string
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink connects to a syslog server over network, "udp", "tcp" or "unix". tag is the app name of
+// the messages.
+func NewSyslogSink(network, address, tag string) (*SyslogSink, error) {
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	if tag == "" {
+		tag = "godock"
+	}
+	s := &SyslogSink{network: network, address: address, tag: tag, hostname: hostname}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SyslogSink) connect() error {
+	conn, err := net.Dial(s.network, s.address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	s.conn = conn
+	return nil
+}
+
+// Ship sends every line as a message. A broken stream connection is reconnected once per batch.
+func (s *SyslogSink) Ship(ctx context.Context, lines []Line) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reconnected := false
+	for _, line := range lines {
+		severity := syslogSeverityInfo
+		if line.Stream == "stderr" {
+			severity = syslogSeverityErr
+		}
+		procID := line.ContainerName
+		if procID == "" {
+			procID = "-"
+		}
+		msg := fmt.Sprintf("<%d>1 %s %s %s %s - - %s",
+			syslogFacilityUser*8+severity, line.Time.UTC().Format(time.RFC3339Nano), s.hostname, s.tag, procID, line.Text)
+		if s.network != "udp" && s.network != "unixgram" {
+			// Stream transports frame messages by newline.
+			msg += "\n"
+		}
+		for {
+			if s.conn == nil {
+				if err := s.connect(); err != nil {
+					return err
+				}
+			}
+			_, err := io.WriteString(s.conn, msg)
+			if err == nil {
+				break
+			}
+			s.conn.Close()
+			s.conn = nil
+			if reconnected {
+				return fmt.Errorf("failed to write to syslog: %w", err)
+			}
+			reconnected = true
+		}
+	}
+	return nil
+}
+
+// Close closes the connection.
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+/*
+HTTPSink posts batches of log lines to an HTTP endpoint as newline delimited JSON, one Line object per line.
+A response status of 300 or above fails the batch.
+
+Usage example:
+
+	sink := logshipper.NewHTTPSink("https://logs.example.com/ingest")
+	sink.Header.Set("Authorization", "Bearer "+token)
+*/
+type HTTPSink struct {
+	URL string
+	// Header is sent with every request.
+	Header http.Header
+	// Client sends the requests. It defaults to a client with a 30 second timeout.
+	Client *http.Client
+}
+
+// NewHTTPSink creates an HTTPSink posting to url.
+func NewHTTPSink(url string) *HTTPSink {
+	return &HTTPSink{URL: url, Header: make(http.Header), Client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Ship posts the lines in one request.
+func (s *HTTPSink) Ship(ctx context.Context, lines []Line) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, line := range lines {
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+	return post(ctx, s.Client, s.URL, "application/x-ndjson", s.Header, &body)
+}
+
+/*
+LokiSink pushes log lines to Grafana Loki through its push API. Every container and stream is a Loki stream
+labeled with container, stream and the static labels of the sink.
+
+Usage example:
+
+	loki := logshipper.NewLokiSink("http://loki:3100/loki/api/v1/push", map[string]string{"host": hostname})
+	loki.Header.Set("X-Scope-OrgID", "tenant-1")
+*/
+type LokiSink struct {
+	URL string
+	// Labels are added to every stream.
+	Labels map[string]string
+	// Header is sent with every request.
+	Header http.Header
+	// Client sends the requests. It defaults to a client with a 30 second timeout.
+	Client *http.Client
+}
+
+// NewLokiSink creates a LokiSink pushing to the push API at url.
+func NewLokiSink(url string, labels map[string]string) *LokiSink {
+	return &LokiSink{URL: url, Labels: labels, Header: make(http.Header), Client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// lokiPush is the body of a Loki push request.
+type lokiPush struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// Ship pushes the lines in one request.
+func (s *LokiSink) Ship(ctx context.Context, lines []Line) error {
+	streams := make(map[[2]string]*lokiStream)
+	var keys [][2]string
+	for _, line := range lines {
+		key := [2]string{line.ContainerName, line.Stream}
+		stream, ok := streams[key]
+		if !ok {
+			labels := map[string]string{"container": line.ContainerName, "stream": line.Stream}
+			for k, v := range s.Labels {
+				labels[k] = v
+			}
+			stream = &lokiStream{Stream: labels}
+			streams[key] = stream
+			keys = append(keys, key)
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(line.Time.UnixNano(), 10), line.Text})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i][0] < keys[j][0] || (keys[i][0] == keys[j][0] && keys[i][1] < keys[j][1])
+	})
+	push := lokiPush{Streams: make([]lokiStream, 0, len(keys))}
+	for _, key := range keys {
+		push.Streams = append(push.Streams, *streams[key])
+	}
+
+	body, err := json.Marshal(push)
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.Client, s.URL, "application/json", s.Header, bytes.NewReader(body))
+}
+
+// post sends a request body and fails on a response status of 300 or above.
+func post(ctx context.Context, client *http.Client, url, contentType string, header http.Header, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", contentType)
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to ship logs to %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to ship logs to %s: %s: %s", url, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package logshipper
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var lineTime = time.Date(2026, time.March, 1, 8, 0, 0, 500, time.UTC)
+
+func testLines(texts ...string) []Line {
+	lines := make([]Line, len(texts))
+	for i, text := range texts {
+		stream := "stdout"
+		if strings.HasPrefix(text, "error") {
+			stream = "stderr"
+		}
+		lines[i] = Line{ContainerID: "abc", ContainerName: "api", Stream: stream, Time: lineTime, Text: text}
+	}
+	return lines
+}
+
+func TestFileSinkRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "containers.log")
+	entry := "2026-03-01T08:00:00.0000005Z api stdout "
+	// Every entry takes len(entry) plus three bytes, so three fit in a file.
+	sink, err := NewFileSink(path, int64(3*(len(entry)+3)), 2)
+	require.NoError(t, err)
+	defer sink.Close()
+
+	ctx := context.Background()
+	require.NoError(t, sink.Ship(ctx, testLines("a1", "a2", "a3")))
+	require.NoError(t, sink.Ship(ctx, testLines("b1", "b2", "b3", "c1")))
+	require.NoError(t, sink.Ship(ctx, testLines("d1", "d2", "d3")))
+
+	read := func(name string) string {
+		data, err := os.ReadFile(name)
+		require.NoError(t, err)
+		return string(data)
+	}
+	assert.Equal(t, entry+"d3\n", read(path))
+	assert.Equal(t, entry+"c1\n"+entry+"d1\n"+entry+"d2\n", read(path+".1"))
+	assert.Equal(t, entry+"b1\n"+entry+"b2\n"+entry+"b3\n", read(path+".2"))
+	assert.NoFileExists(t, path+".3")
+
+	require.NoError(t, sink.Close())
+	assert.Error(t, sink.Ship(ctx, testLines("e1")))
+}
+
+func TestSyslogSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	sink, err := NewSyslogSink("udp", conn.LocalAddr().String(), "shipper")
+	require.NoError(t, err)
+	defer sink.Close()
+	require.NoError(t, sink.Ship(context.Background(), testLines("started", "error: boom")))
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var messages []string
+	for i := 0; i < 2; i++ {
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		messages = append(messages, string(buf[:n]))
+	}
+	assert.Equal(t, "<14>1 2026-03-01T08:00:00.0000005Z "+sink.hostname+" shipper api - - started", messages[0])
+	assert.Equal(t, "<11>1 2026-03-01T08:00:00.0000005Z "+sink.hostname+" shipper api - - error: boom", messages[1])
+}
+
+func TestHTTPSink(t *testing.T) {
+	var received []Line
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var line Line
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+			received = append(received, line)
+		}
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL)
+	sink.Header.Set("Authorization", "Bearer token")
+	lines := testLines("started", "error: boom")
+	require.NoError(t, sink.Ship(context.Background(), lines))
+	assert.Equal(t, lines, received)
+}
+
+func TestHTTPSinkStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	err := NewHTTPSink(server.URL).Ship(context.Background(), testLines("started"))
+	assert.ErrorContains(t, err, "429 Too Many Requests: quota exceeded")
+}
+
+func TestLokiSink(t *testing.T) {
+	var push map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "tenant-1", r.Header.Get("X-Scope-OrgID"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &push))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink := NewLokiSink(server.URL, map[string]string{"host": "node-1"})
+	sink.Header.Set("X-Scope-OrgID", "tenant-1")
+	require.NoError(t, sink.Ship(context.Background(), testLines("started", "error: boom", "ready")))
+
+	ts := "1772352000000000500"
+	assert.Equal(t, map[string]any{"streams": []any{
+		map[string]any{
+			"stream": map[string]any{"container": "api", "stream": "stderr", "host": "node-1"},
+			"values": []any{[]any{ts, "error: boom"}},
+		},
+		map[string]any{
+			"stream": map[string]any{"container": "api", "stream": "stdout", "host": "node-1"},
+			"values": []any{[]any{ts, "started"}, []any{ts, "ready"}},
+		},
+	}}, push)
+}
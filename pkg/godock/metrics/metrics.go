@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// Operation is the kind of image transfer that was measured.
+type Operation string
+
+const (
+	// OperationPull is an image pull from a registry.
+	OperationPull Operation = "pull"
+	// OperationPush is an image push to a registry.
+	OperationPush Operation = "push"
+	// OperationBuild is an image build. Bytes holds the size of the build context sent to the daemon.
+	OperationBuild Operation = "build"
+)
+
+// ImageTransfer holds the measurements of a single pull, push or build.
+type ImageTransfer struct {
+	Operation Operation
+	// Ref is the image reference that was transferred.
+	Ref string
+	// Bytes is the number of bytes transferred. Layers that were already present are not counted.
+	Bytes int64
+	// Layers is the number of layers (or build steps) involved in the transfer.
+	Layers int
+	// CachedLayers is the number of layers that were already present, or build steps that hit the cache.
+	CachedLayers int
+	// Duration is the time from the start of the request until the response was fully read or closed.
+	Duration time.Duration
+	// Err holds the error reported by the daemon, if any.
+	Err error
+}
+
+// BytesPerSecond returns the average transfer rate.
+func (t ImageTransfer) BytesPerSecond() float64 {
+	if t.Duration <= 0 {
+		return 0
+	}
+	return float64(t.Bytes) / t.Duration.Seconds()
+}
+
+// Recorder receives the measurements of every image transfer made through a client.
+// Implementations must be safe for concurrent use.
+type Recorder interface {
+	RecordImageTransfer(transfer ImageTransfer)
+}
+
+/*
+RecorderFunc adapts a function to a Recorder, for use as a hook.
+
+Usage example:
+
+	client, err := godock.NewClient(ctx,
+		godock.WithMetrics(metrics.RecorderFunc(func(t metrics.ImageTransfer) {
+			log.Printf("%s %s: %d bytes in %s", t.Operation, t.Ref, t.Bytes, t.Duration)
+		})),
+	)
+*/
+type RecorderFunc func(transfer ImageTransfer)
+
+// RecordImageTransfer calls f(transfer).
+func (f RecorderFunc) RecordImageTransfer(transfer ImageTransfer) {
+	f(transfer)
+}
+
+// Totals aggregates the transfers of one operation.
+type Totals struct {
+	Count        int
+	Failures     int
+	Bytes        int64
+	Layers       int
+	CachedLayers int
+	Duration     time.Duration
+}
+
+// CacheHitRate returns the fraction of layers that were already present.
+func (t Totals) CacheHitRate() float64 {
+	if t.Layers == 0 {
+		return 0
+	}
+	return float64(t.CachedLayers) / float64(t.Layers)
+}
+
+// BytesPerSecond returns the average transfer rate over all transfers.
+func (t Totals) BytesPerSecond() float64 {
+	if t.Duration <= 0 {
+		return 0
+	}
+	return float64(t.Bytes) / t.Duration.Seconds()
+}
+
+/*
+Collector is a Recorder that keeps running totals per operation in memory.
+
+Usage example:
+
+	collector := metrics.NewCollector()
+	client, err := godock.NewClient(ctx, godock.WithMetrics(collector))
+	...
+	pulls := collector.Snapshot()[metrics.OperationPull]
+	fmt.Printf("cache hit rate: %.2f\n", pulls.CacheHitRate())
+*/
+type Collector struct {
+	mu     sync.Mutex
+	totals map[Operation]Totals
+}
+
+// NewCollector creates an empty Collector.
+func NewCollector() *Collector {
+	return &Collector{
+		totals: make(map[Operation]Totals),
+	}
+}
+
+// RecordImageTransfer adds the transfer to the totals of its operation.
+func (c *Collector) RecordImageTransfer(transfer ImageTransfer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := c.totals[transfer.Operation]
+	t.Count++
+	if transfer.Err != nil {
+		t.Failures++
+	}
+	t.Bytes += transfer.Bytes
+	t.Layers += transfer.Layers
+	t.CachedLayers += transfer.CachedLayers
+	t.Duration += transfer.Duration
+	c.totals[transfer.Operation] = t
+}
+
+// Snapshot returns a copy of the current totals.
+func (c *Collector) Snapshot() map[Operation]Totals {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := make(map[Operation]Totals, len(c.totals))
+	for op, t := range c.totals {
+		snapshot[op] = t
+	}
+	return snapshot
+}
+
+// Reset clears the totals.
+func (c *Collector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.totals = make(map[Operation]Totals)
+}
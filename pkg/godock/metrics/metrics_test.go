@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollector()
+	c.RecordImageTransfer(ImageTransfer{Operation: OperationPull, Bytes: 100, Layers: 4, CachedLayers: 1, Duration: time.Second})
+	c.RecordImageTransfer(ImageTransfer{Operation: OperationPull, Bytes: 300, Layers: 4, CachedLayers: 3, Duration: time.Second})
+	c.RecordImageTransfer(ImageTransfer{Operation: OperationPush, Err: errors.New("denied")})
+
+	snapshot := c.Snapshot()
+	pulls := snapshot[OperationPull]
+	assert.Equal(t, 2, pulls.Count)
+	assert.Equal(t, int64(400), pulls.Bytes)
+	assert.Equal(t, 0.5, pulls.CacheHitRate())
+	assert.Equal(t, 200.0, pulls.BytesPerSecond())
+	assert.Equal(t, 1, snapshot[OperationPush].Failures)
+
+	c.Reset()
+	assert.Empty(t, c.Snapshot())
+}
+
+func TestImageTransfer_BytesPerSecond(t *testing.T) {
+	assert.Equal(t, 0.0, ImageTransfer{Bytes: 10}.BytesPerSecond())
+	assert.Equal(t, 5.0, ImageTransfer{Bytes: 10, Duration: 2 * time.Second}.BytesPerSecond())
+}
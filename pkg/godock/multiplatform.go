@@ -0,0 +1,61 @@
+package godock
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/imageoptions"
+	"github.com/docker/docker/api/types"
+)
+
+// containerdSnapshotter is the driver type reported by daemons that use the containerd image store.
+const containerdSnapshotter = "io.containerd.snapshotter.v1"
+
+// prepareMultiPlatformBuild validates a build for several platforms and rejects outputs pushed by BuildKit.
+// Multi-platform images can only be kept by daemons that use the containerd image store.
+func (c *Client) prepareMultiPlatformBuild(ctx context.Context, buildOptions *types.ImageBuildOptions) error {
+	// BuildKit asks the client session for registry credentials when it pushes, and without a session the
+	// push fails or goes out anonymously, so images are pushed with ImagePush after the build instead.
+	for _, output := range buildOptions.Outputs {
+		if output.Type == string(imageoptions.ImageOutput) && output.Attrs["push"] == "true" {
+			return &errdefs.ValidationError{
+				Field:   "outputs",
+				Message: "pushing from a build is not supported, push the image with ImagePush once the build completes",
+			}
+		}
+	}
+
+	if !strings.Contains(buildOptions.Platform, ",") {
+		return nil
+	}
+	if buildOptions.Version != types.BuilderBuildKit {
+		return &errdefs.ValidationError{
+			Field:   "platform",
+			Message: "building for several platforms requires BuildKit, use imageoptions.SetPlatforms",
+		}
+	}
+	info, err := c.wrapped.Info(ctx)
+	if err != nil {
+		return &errdefs.DaemonNotRunningError{
+			Message: err.Error(),
+		}
+	}
+	if !usesContainerdStore(info.DriverStatus) {
+		return &errdefs.ValidationError{
+			Field:   "platform",
+			Message: "building for several platforms requires the containerd image store, enable it in the daemon configuration",
+		}
+	}
+	return nil
+}
+
+// usesContainerdStore reports whether the daemon's storage driver status shows the containerd image store.
+func usesContainerdStore(driverStatus [][2]string) bool {
+	for _, status := range driverStatus {
+		if status[0] == "driver-type" && status[1] == containerdSnapshotter {
+			return true
+		}
+	}
+	return false
+}
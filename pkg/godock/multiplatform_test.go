@@ -0,0 +1,58 @@
+package godock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/imageoptions"
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareMultiPlatformBuild(t *testing.T) {
+	c := &Client{}
+	ctx := context.Background()
+
+	t.Run("Rejects pushing outputs", func(t *testing.T) {
+		img := image.NewConfig("app")
+		img.SetBuildOptions(
+			imageoptions.AddTag("registry.example.com/app:1"),
+			imageoptions.AddOutput(imageoptions.ImageOutput, map[string]string{"push": "true"}),
+		)
+		opts := *img.BuildOptions
+		err := c.prepareMultiPlatformBuild(ctx, &opts)
+		assert.True(t, errdefs.IsInvalidConfig(err))
+	})
+
+	t.Run("Single platform", func(t *testing.T) {
+		img := image.NewConfig("app")
+		img.SetBuildOptions(
+			imageoptions.AddTag("registry.example.com/app:1"),
+			imageoptions.SetPlatforms("linux/amd64"),
+		)
+		opts := *img.BuildOptions
+		require.NoError(t, c.prepareMultiPlatformBuild(ctx, &opts))
+		assert.Equal(t, types.BuilderBuildKit, opts.Version)
+	})
+
+	t.Run("Several platforms require BuildKit", func(t *testing.T) {
+		img := image.NewConfig("app")
+		img.SetBuildOptions(
+			imageoptions.SetPlatforms("linux/amd64", "linux/arm64"),
+			imageoptions.SetBuilderVersion(imageoptions.BuilderV1),
+		)
+		assert.Equal(t, "linux/amd64,linux/arm64", img.BuildOptions.Platform)
+
+		opts := *img.BuildOptions
+		err := c.prepareMultiPlatformBuild(ctx, &opts)
+		assert.True(t, errdefs.IsInvalidConfig(err))
+	})
+}
+
+func TestUsesContainerdStore(t *testing.T) {
+	assert.True(t, usesContainerdStore([][2]string{{"driver-type", "io.containerd.snapshotter.v1"}}))
+	assert.False(t, usesContainerdStore([][2]string{{"Backing Filesystem", "extfs"}}))
+}
@@ -0,0 +1,162 @@
+package network
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/aptd3v/godock/pkg/godock/networkoptions"
+)
+
+// MacvlanMode is the mode of a macvlan network.
+type MacvlanMode string
+
+const (
+	// MacvlanBridge lets containers on the same parent interface talk to each other directly. It is the default.
+	MacvlanBridge MacvlanMode = "bridge"
+	// MacvlanVEPA sends all traffic through the external switch.
+	MacvlanVEPA MacvlanMode = "vepa"
+	// MacvlanPrivate isolates containers on the same parent interface from each other.
+	MacvlanPrivate MacvlanMode = "private"
+	// MacvlanPassthru attaches a single container directly to the parent interface.
+	MacvlanPassthru MacvlanMode = "passthru"
+)
+
+// IPvlanMode is the mode of an ipvlan network.
+type IPvlanMode string
+
+const (
+	// IPvlanL2 shares the parent's MAC address and switches at layer 2. It is the default.
+	IPvlanL2 IPvlanMode = "l2"
+	// IPvlanL3 routes at layer 3. The parent interface acts as the router, so no gateway is used.
+	IPvlanL3 IPvlanMode = "l3"
+	// IPvlanL3S routes at layer 3 through the host's netfilter rules.
+	IPvlanL3S IPvlanMode = "l3s"
+)
+
+/*
+NewMacvlanConfig creates a macvlan network configuration that gives containers their own MAC address
+on the physical network attached to parentIface, e.g. "eth0" or "eth0.10" for a VLAN sub-interface.
+The subnet and gateway must be those of the physical network, and the gateway must be inside the subnet.
+IPv6 is enabled when the subnet is an IPv6 subnet. Further options are applied after the preset.
+
+Usage example:
+
+	lan, err := network.NewMacvlanConfig("lan", "eth0", "192.168.1.0/24", "192.168.1.1",
+		networkoptions.Options("macvlan_mode", string(network.MacvlanPrivate)),
+	)
+	if err != nil {
+		return err
+	}
+	err = client.NetworkCreate(ctx, lan)
+*/
+func NewMacvlanConfig(name, parentIface, subnet, gateway string, setNOFns ...networkoptions.SetNetworkOptions) (*NetworkConfig, error) {
+	if parentIface == "" {
+		return nil, fmt.Errorf("macvlan network %s requires a parent interface", name)
+	}
+	ipam, err := subnetOptions(subnet, gateway)
+	if err != nil {
+		return nil, fmt.Errorf("macvlan network %s: %w", name, err)
+	}
+	n := NewConfig(name)
+	n.SetOptions(append([]networkoptions.SetNetworkOptions{
+		networkoptions.Driver("macvlan"),
+		networkoptions.Options("parent", parentIface),
+		networkoptions.Options("macvlan_mode", string(MacvlanBridge)),
+	}, ipam...)...)
+	n.SetOptions(setNOFns...)
+	return n, nil
+}
+
+/*
+NewIPvlanConfig creates an ipvlan network configuration on parentIface. Unlike macvlan, containers share
+the parent's MAC address, which suits switches that limit the number of addresses per port.
+In IPvlanL3 mode the gateway must be empty, since the parent interface routes the traffic.
+
+Usage example:
+
+	routed, err := network.NewIPvlanConfig("routed", "eth0", "10.10.0.0/24", "", network.IPvlanL3)
+*/
+func NewIPvlanConfig(name, parentIface, subnet, gateway string, mode IPvlanMode, setNOFns ...networkoptions.SetNetworkOptions) (*NetworkConfig, error) {
+	if parentIface == "" {
+		return nil, fmt.Errorf("ipvlan network %s requires a parent interface", name)
+	}
+	switch mode {
+	case "":
+		mode = IPvlanL2
+	case IPvlanL2:
+	case IPvlanL3, IPvlanL3S:
+		if gateway != "" {
+			return nil, fmt.Errorf("ipvlan network %s: a gateway cannot be set in %s mode", name, mode)
+		}
+	default:
+		return nil, fmt.Errorf("ipvlan network %s: unknown ipvlan mode %s", name, mode)
+	}
+	ipam, err := subnetOptions(subnet, gateway)
+	if err != nil {
+		return nil, fmt.Errorf("ipvlan network %s: %w", name, err)
+	}
+	n := NewConfig(name)
+	n.SetOptions(append([]networkoptions.SetNetworkOptions{
+		networkoptions.Driver("ipvlan"),
+		networkoptions.Options("parent", parentIface),
+		networkoptions.Options("ipvlan_mode", string(mode)),
+	}, ipam...)...)
+	n.SetOptions(setNOFns...)
+	return n, nil
+}
+
+/*
+NewOverlayConfig creates an attachable, swarm scoped overlay network configuration, so standalone
+containers as well as services can join it. The subnet may be empty to let the daemon choose one.
+The daemon must be part of a swarm to create overlay networks.
+
+Usage example:
+
+	backend, err := network.NewOverlayConfig("backend", "10.20.0.0/16",
+		networkoptions.Options("encrypted", ""),
+	)
+*/
+func NewOverlayConfig(name, subnet string, setNOFns ...networkoptions.SetNetworkOptions) (*NetworkConfig, error) {
+	n := NewConfig(name)
+	n.SetOptions(
+		networkoptions.Driver("overlay"),
+		networkoptions.Scope("swarm"),
+		networkoptions.Attachable(),
+	)
+	if subnet != "" {
+		ipam, err := subnetOptions(subnet, "")
+		if err != nil {
+			return nil, fmt.Errorf("overlay network %s: %w", name, err)
+		}
+		n.SetOptions(ipam...)
+	}
+	n.SetOptions(setNOFns...)
+	return n, nil
+}
+
+// subnetOptions validates a subnet and gateway and returns the IPAM options for them.
+func subnetOptions(subnet, gateway string) ([]networkoptions.SetNetworkOptions, error) {
+	prefix, err := netip.ParsePrefix(subnet)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subnet %q: %w", subnet, err)
+	}
+	if prefix.Masked() != prefix {
+		return nil, fmt.Errorf("subnet %s has host bits set, use %s", subnet, prefix.Masked())
+	}
+	if gateway != "" {
+		addr, err := netip.ParseAddr(gateway)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gateway %q: %w", gateway, err)
+		}
+		if !prefix.Contains(addr) {
+			return nil, fmt.Errorf("gateway %s is not in subnet %s", gateway, subnet)
+		}
+	}
+	opts := []networkoptions.SetNetworkOptions{
+		networkoptions.IPAMConfig(prefix.String(), "", gateway),
+	}
+	if prefix.Addr().Is6() {
+		opts = append(opts, networkoptions.EnableIPV6(true))
+	}
+	return opts, nil
+}
@@ -0,0 +1,58 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/networkoptions"
+	dockerNetwork "github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMacvlanConfig(t *testing.T) {
+	n, err := NewMacvlanConfig("lan", "eth0.10", "192.168.1.0/24", "192.168.1.1",
+		networkoptions.Options("macvlan_mode", string(MacvlanPrivate)),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "macvlan", n.Options.Driver)
+	assert.Equal(t, map[string]string{"parent": "eth0.10", "macvlan_mode": "private"}, n.Options.Options)
+	assert.Equal(t, []dockerNetwork.IPAMConfig{{Subnet: "192.168.1.0/24", Gateway: "192.168.1.1"}}, n.Options.IPAM.Config)
+	assert.Nil(t, n.Options.EnableIPv6)
+
+	_, err = NewMacvlanConfig("lan", "", "192.168.1.0/24", "192.168.1.1")
+	assert.Error(t, err)
+	_, err = NewMacvlanConfig("lan", "eth0", "192.168.1.0/24", "192.168.2.1")
+	assert.ErrorContains(t, err, "not in subnet")
+	_, err = NewMacvlanConfig("lan", "eth0", "192.168.1.5/24", "192.168.1.1")
+	assert.ErrorContains(t, err, "host bits")
+	_, err = NewMacvlanConfig("lan", "eth0", "192.168.1.0", "")
+	assert.ErrorContains(t, err, "invalid subnet")
+}
+
+func TestNewIPvlanConfig(t *testing.T) {
+	n, err := NewIPvlanConfig("v6", "eth0", "2001:db8::/64", "2001:db8::1", "")
+	require.NoError(t, err)
+	assert.Equal(t, "ipvlan", n.Options.Driver)
+	assert.Equal(t, "l2", n.Options.Options["ipvlan_mode"])
+	require.NotNil(t, n.Options.EnableIPv6)
+	assert.True(t, *n.Options.EnableIPv6)
+
+	_, err = NewIPvlanConfig("routed", "eth0", "10.10.0.0/24", "10.10.0.1", IPvlanL3)
+	assert.ErrorContains(t, err, "gateway")
+	_, err = NewIPvlanConfig("routed", "eth0", "10.10.0.0/24", "", IPvlanMode("l4"))
+	assert.ErrorContains(t, err, "unknown ipvlan mode")
+}
+
+func TestNewOverlayConfig(t *testing.T) {
+	n, err := NewOverlayConfig("backend", "", networkoptions.Options("encrypted", ""))
+	require.NoError(t, err)
+	assert.Equal(t, "overlay", n.Options.Driver)
+	assert.Equal(t, "swarm", n.Options.Scope)
+	assert.True(t, n.Options.Attachable)
+	assert.Nil(t, n.Options.IPAM)
+	assert.Contains(t, n.Options.Options, "encrypted")
+
+	n, err = NewOverlayConfig("backend", "10.20.0.0/16")
+	require.NoError(t, err)
+	assert.Equal(t, "10.20.0.0/16", n.Options.IPAM.Config[0].Subnet)
+}
@@ -32,7 +32,7 @@ DriverOpt adds a driver-specific option to the endpoint settings.
 
 Usage example:
 
-	endpoint := endpointoptions.NewEndpoint()
+	endpoint := endpointoptions.NewConfig()
 	endpoint.SetEndpointSetting(
 		endpointoptions.DriverOpt("com.docker.network.driver.mtu", "1500"),
 	)
@@ -47,17 +47,47 @@ func DriverOpt(key, value string) SetEndpointSettingsFn {
 }
 
 /*
-IPv4Address sets the IPv4 address for the endpoint.
+DriverOpts adds driver-specific options to the endpoint settings.
 
 Usage example:
 
-	endpoint := endpointoptions.NewEndpoint()
+	endpoint := endpointoptions.NewConfig()
+	endpoint.SetEndpointSetting(
+		endpointoptions.DriverOpts(map[string]string{
+			"com.docker.network.endpoint.sysctls": "net.ipv4.conf.IFNAME.forwarding=1",
+		}),
+	)
+*/
+func DriverOpts(opts map[string]string) SetEndpointSettingsFn {
+	return func(es *network.EndpointSettings) {
+		for key, value := range opts {
+			DriverOpt(key, value)(es)
+		}
+	}
+}
+
+// ipamConfig returns the IPAM configuration of the endpoint, creating it if needed.
+func ipamConfig(es *network.EndpointSettings) *network.EndpointIPAMConfig {
+	if es.IPAMConfig == nil {
+		es.IPAMConfig = &network.EndpointIPAMConfig{}
+	}
+	return es.IPAMConfig
+}
+
+/*
+IPv4Address assigns a static IPv4 address to the endpoint. The address must be within
+a subnet configured on a user-defined network.
+
+Usage example:
+
+	endpoint := endpointoptions.NewConfig()
 	endpoint.SetEndpointSetting(
 		endpointoptions.IPv4Address("172.20.0.2"),
 	)
 */
 func IPv4Address(addr string) SetEndpointSettingsFn {
 	return func(es *network.EndpointSettings) {
+		ipamConfig(es).IPv4Address = addr
 		es.IPAddress = addr
 	}
 }
@@ -67,7 +97,7 @@ IPv4Gateway sets the IPv4 gateway for the endpoint.
 
 Usage example:
 
-	endpoint := endpointoptions.NewEndpoint()
+	endpoint := endpointoptions.NewConfig()
 	endpoint.SetEndpointSetting(
 		endpointoptions.IPv4Gateway("172.20.0.1"),
 	)
@@ -83,7 +113,7 @@ IPv4PrefixLen sets the IPv4 subnet prefix length for the endpoint.
 
 Usage example:
 
-	endpoint := endpointoptions.NewEndpoint()
+	endpoint := endpointoptions.NewConfig()
 	endpoint.SetEndpointSetting(
 		endpointoptions.IPv4PrefixLen(24), // for a /24 subnet
 	)
@@ -95,27 +125,46 @@ func IPv4PrefixLen(prefixLen int) SetEndpointSettingsFn {
 }
 
 /*
-IPv6Address sets the IPv6 address for the endpoint.
+IPv6Address assigns a static IPv6 address to the endpoint. The address must be within
+a subnet configured on a user-defined network with IPv6 enabled.
 
 Usage example:
 
-	endpoint := endpointoptions.NewEndpoint()
+	endpoint := endpointoptions.NewConfig()
 	endpoint.SetEndpointSetting(
 		endpointoptions.IPv6Address("2001:db8::2"),
 	)
 */
 func IPv6Address(addr string) SetEndpointSettingsFn {
 	return func(es *network.EndpointSettings) {
+		ipamConfig(es).IPv6Address = addr
 		es.GlobalIPv6Address = addr
 	}
 }
 
+/*
+LinkLocalIPs adds link-local addresses to the endpoint.
+
+Usage example:
+
+	endpoint := endpointoptions.NewConfig()
+	endpoint.SetEndpointSetting(
+		endpointoptions.LinkLocalIPs("169.254.10.2", "fe80::2"),
+	)
+*/
+func LinkLocalIPs(ips ...string) SetEndpointSettingsFn {
+	return func(es *network.EndpointSettings) {
+		ipam := ipamConfig(es)
+		ipam.LinkLocalIPs = append(ipam.LinkLocalIPs, ips...)
+	}
+}
+
 /*
 IPv6Gateway sets the IPv6 gateway for the endpoint.
 
 Usage example:
 
-	endpoint := endpointoptions.NewEndpoint()
+	endpoint := endpointoptions.NewConfig()
 	endpoint.SetEndpointSetting(
 		endpointoptions.IPv6Gateway("2001:db8::1"),
 	)
@@ -131,7 +180,7 @@ IPv6PrefixLen sets the IPv6 subnet prefix length for the endpoint.
 
 Usage example:
 
-	endpoint := endpointoptions.NewEndpoint()
+	endpoint := endpointoptions.NewConfig()
 	endpoint.SetEndpointSetting(
 		endpointoptions.IPv6PrefixLen(64), // for a /64 subnet
 	)
@@ -143,11 +192,11 @@ func IPv6PrefixLen(prefixLen int) SetEndpointSettingsFn {
 }
 
 /*
-MacAddress sets the MAC address for the endpoint.
+MacAddress sets the MAC address for the endpoint. It requires API version 1.44 or later.
 
 Usage example:
 
-	endpoint := endpointoptions.NewEndpoint()
+	endpoint := endpointoptions.NewConfig()
 	endpoint.SetEndpointSetting(
 		endpointoptions.MacAddress("02:42:ac:11:00:02"),
 	)
@@ -164,7 +213,7 @@ Links allow containers to discover and securely communicate with each other.
 
 Usage example:
 
-	endpoint := endpointoptions.NewEndpoint()
+	endpoint := endpointoptions.NewConfig()
 	endpoint.SetEndpointSetting(
 		endpointoptions.Links("redis:cache", "postgres:db"),
 	)
@@ -184,7 +233,7 @@ Aliases allow the container to be discovered using additional names.
 
 Usage example:
 
-	endpoint := endpointoptions.NewEndpoint()
+	endpoint := endpointoptions.NewConfig()
 	endpoint.SetEndpointSetting(
 		endpointoptions.Aliases("web", "api"),
 	)
@@ -203,7 +252,7 @@ NetworkID sets the ID of the network this endpoint belongs to.
 
 Usage example:
 
-	endpoint := endpointoptions.NewEndpoint()
+	endpoint := endpointoptions.NewConfig()
 	endpoint.SetEndpointSetting(
 		endpointoptions.NetworkID("n1230984jf02jf"),
 	)
@@ -219,7 +268,7 @@ EndpointID sets the ID for this endpoint.
 
 Usage example:
 
-	endpoint := endpointoptions.NewEndpoint()
+	endpoint := endpointoptions.NewConfig()
 	endpoint.SetEndpointSetting(
 		endpointoptions.EndpointID("e2309rj203j"),
 	)
@@ -236,7 +285,7 @@ This allows you to specify static IP addresses for the endpoint.
 
 Usage example:
 
-	endpoint := endpointoptions.NewEndpoint()
+	endpoint := endpointoptions.NewConfig()
 	endpoint.SetEndpointSetting(
 		endpointoptions.IPAMConfig(
 			"172.20.0.2",    // IPv4 Address
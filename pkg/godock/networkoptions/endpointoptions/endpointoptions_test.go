@@ -0,0 +1,38 @@
+package endpointoptions
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticAddresses(t *testing.T) {
+	endpoint := NewConfig()
+	endpoint.SetEndpointSetting(
+		IPv4Address("172.20.0.2"),
+		IPv6Address("2001:db8::2"),
+		LinkLocalIPs("169.254.10.2"),
+		MacAddress("02:42:ac:14:00:02"),
+		Links("redis:cache"),
+		DriverOpts(map[string]string{"com.docker.network.driver.mtu": "1400"}),
+	)
+
+	assert.Equal(t, &network.EndpointIPAMConfig{
+		IPv4Address:  "172.20.0.2",
+		IPv6Address:  "2001:db8::2",
+		LinkLocalIPs: []string{"169.254.10.2"},
+	}, endpoint.Settings.IPAMConfig)
+	assert.Equal(t, "02:42:ac:14:00:02", endpoint.Settings.MacAddress)
+	assert.Equal(t, []string{"redis:cache"}, endpoint.Settings.Links)
+	assert.Equal(t, map[string]string{"com.docker.network.driver.mtu": "1400"}, endpoint.Settings.DriverOpts)
+}
+
+func TestIPAMConfigReplacesAddresses(t *testing.T) {
+	endpoint := NewConfig()
+	endpoint.SetEndpointSetting(
+		IPv4Address("172.20.0.2"),
+		IPAMConfig("172.20.0.3", "", nil),
+	)
+	assert.Equal(t, "172.20.0.3", endpoint.Settings.IPAMConfig.IPv4Address)
+}
@@ -0,0 +1,74 @@
+package nodeoptions
+
+import (
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// SetNodeSpecFn is a function that changes the spec of a swarm node.
+type SetNodeSpecFn func(spec *swarm.NodeSpec)
+
+/*
+Availability sets whether the scheduler places tasks on the node:
+swarm.NodeAvailabilityActive, swarm.NodeAvailabilityPause or swarm.NodeAvailabilityDrain.
+
+Usage example:
+
+	err := client.NodeUpdate(ctx, nodeID, nodeoptions.Availability(swarm.NodeAvailabilityDrain))
+*/
+func Availability(availability swarm.NodeAvailability) SetNodeSpecFn {
+	return func(spec *swarm.NodeSpec) {
+		spec.Availability = availability
+	}
+}
+
+// Drain stops new tasks from being scheduled on the node and moves its running tasks elsewhere.
+func Drain() SetNodeSpecFn {
+	return Availability(swarm.NodeAvailabilityDrain)
+}
+
+// Pause stops new tasks from being scheduled on the node, leaving its running tasks in place.
+func Pause() SetNodeSpecFn {
+	return Availability(swarm.NodeAvailabilityPause)
+}
+
+// Activate lets the scheduler place tasks on the node again.
+func Activate() SetNodeSpecFn {
+	return Availability(swarm.NodeAvailabilityActive)
+}
+
+// Role promotes the node to a manager or demotes it to a worker.
+func Role(role swarm.NodeRole) SetNodeSpecFn {
+	return func(spec *swarm.NodeSpec) {
+		spec.Role = role
+	}
+}
+
+// Name sets the name of the node.
+func Name(name string) SetNodeSpecFn {
+	return func(spec *swarm.NodeSpec) {
+		spec.Name = name
+	}
+}
+
+/*
+Label sets a label on the node, for use in placement constraints such as node.labels.zone==eu-1.
+
+Usage example:
+
+	err := client.NodeUpdate(ctx, nodeID, nodeoptions.Label("zone", "eu-1"))
+*/
+func Label(key, value string) SetNodeSpecFn {
+	return func(spec *swarm.NodeSpec) {
+		if spec.Labels == nil {
+			spec.Labels = make(map[string]string)
+		}
+		spec.Labels[key] = value
+	}
+}
+
+// RemoveLabel removes a label from the node.
+func RemoveLabel(key string) SetNodeSpecFn {
+	return func(spec *swarm.NodeSpec) {
+		delete(spec.Labels, key)
+	}
+}
@@ -0,0 +1,32 @@
+package nodeoptions
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeSpec(t *testing.T) {
+	spec := &swarm.NodeSpec{
+		Annotations: swarm.Annotations{Labels: map[string]string{"old": "x"}},
+		Role:        swarm.NodeRoleWorker,
+	}
+	for _, fn := range []SetNodeSpecFn{
+		Drain(),
+		Role(swarm.NodeRoleManager),
+		Name("edge-1"),
+		Label("zone", "eu-1"),
+		RemoveLabel("old"),
+	} {
+		fn(spec)
+	}
+
+	assert.Equal(t, swarm.NodeAvailabilityDrain, spec.Availability)
+	assert.Equal(t, swarm.NodeRoleManager, spec.Role)
+	assert.Equal(t, "edge-1", spec.Name)
+	assert.Equal(t, map[string]string{"zone": "eu-1"}, spec.Labels)
+
+	Activate()(spec)
+	assert.Equal(t, swarm.NodeAvailabilityActive, spec.Availability)
+}
@@ -0,0 +1,112 @@
+package godock
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/docker/docker/client"
+)
+
+// PauseAll pauses a group of containers in the order StopAll stops them, so a container is paused before
+// the containers it depends on and an application stops writing before its database is frozen.
+// Containers that are already paused are left as they are.
+//
+// If a container fails to pause, or ctx is done before all are paused, the containers paused so far are
+// unpaused again in reverse order and the error is returned, so the group is never left partially paused.
+func (c *Client) PauseAll(ctx context.Context, containerConfigs ...*container.ContainerConfig) error {
+	_, err := c.pauseAll(ctx, containerConfigs)
+	return err
+}
+
+// UnpauseAll unpauses a group of containers in the reverse of the order PauseAll pauses them, so a
+// container resumes after the containers it depends on. Containers that are not paused or no longer
+// exist are skipped. Every container is attempted and the errors are joined.
+func (c *Client) UnpauseAll(ctx context.Context, containerConfigs ...*container.ContainerConfig) error {
+	ordered, err := stopOrder(containerConfigs, nil)
+	if err != nil {
+		return err
+	}
+	var paused []*container.ContainerConfig
+	for _, containerConfig := range ordered {
+		inspect, err := c.wrapped.ContainerInspect(ctx, containerConfig.Id)
+		if err != nil {
+			if client.IsErrNotFound(err) {
+				continue
+			}
+			return &errdefs.ContainerError{ID: containerConfig.Name, Op: "unpause", Message: err.Error(), Err: err}
+		}
+		if inspect.State != nil && inspect.State.Paused {
+			paused = append(paused, containerConfig)
+		}
+	}
+	return c.unpauseAll(ctx, paused)
+}
+
+/*
+Freeze pauses a group of containers like PauseAll, runs fn and unpauses the containers it paused, whatever
+fn returns. This gives fn a consistent view of the containers' volumes, e.g. to back them up. The containers
+are unpaused even when ctx is done, so an expired deadline never leaves them frozen.
+
+Usage example:
+
+	err := client.Freeze(ctx, []*container.ContainerConfig{app, db}, func(ctx context.Context) error {
+		return client.VolumeBackup(ctx, "db-data", backup)
+	})
+*/
+func (c *Client) Freeze(ctx context.Context, containerConfigs []*container.ContainerConfig, fn func(ctx context.Context) error) error {
+	paused, err := c.pauseAll(ctx, containerConfigs)
+	if err != nil {
+		return err
+	}
+	fnErr := fn(ctx)
+	if err := c.unpauseAll(context.WithoutCancel(ctx), paused); err != nil {
+		return errors.Join(fnErr, err)
+	}
+	return fnErr
+}
+
+// pauseAll pauses the containers in stop order and returns those it paused, in the order they were paused.
+func (c *Client) pauseAll(ctx context.Context, containerConfigs []*container.ContainerConfig) ([]*container.ContainerConfig, error) {
+	ordered, err := stopOrder(containerConfigs, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var paused []*container.ContainerConfig
+	rollback := func(err error) error {
+		if unpauseErr := c.unpauseAll(context.WithoutCancel(ctx), paused); unpauseErr != nil {
+			return errors.Join(err, unpauseErr)
+		}
+		return err
+	}
+	for _, containerConfig := range ordered {
+		if err := ctx.Err(); err != nil {
+			return nil, rollback(err)
+		}
+		inspect, err := c.wrapped.ContainerInspect(ctx, containerConfig.Id)
+		if err != nil {
+			return nil, rollback(&errdefs.ContainerError{ID: containerConfig.Name, Op: "pause", Message: err.Error(), Err: err})
+		}
+		if inspect.State != nil && inspect.State.Paused {
+			continue
+		}
+		if err := c.wrapped.ContainerPause(ctx, containerConfig.Id); err != nil {
+			return nil, rollback(&errdefs.ContainerError{ID: containerConfig.Name, Op: "pause", Message: err.Error(), Err: err})
+		}
+		paused = append(paused, containerConfig)
+	}
+	return paused, nil
+}
+
+// unpauseAll unpauses the containers in the reverse of the given order, attempting every container.
+func (c *Client) unpauseAll(ctx context.Context, paused []*container.ContainerConfig) error {
+	var errs []error
+	for i := len(paused) - 1; i >= 0; i-- {
+		if err := c.wrapped.ContainerUnpause(ctx, paused[i].Id); err != nil && !client.IsErrNotFound(err) {
+			errs = append(errs, &errdefs.ContainerError{ID: paused[i].Name, Op: "unpause", Message: err.Error(), Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,78 @@
+package godock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseAllValidation(t *testing.T) {
+	c := &Client{}
+	ctx := context.Background()
+	assert.True(t, errdefs.IsInvalidConfig(c.PauseAll(ctx, nil)))
+	assert.True(t, errdefs.IsInvalidConfig(c.UnpauseAll(ctx, container.NewConfig("no-id"))))
+
+	called := false
+	err := c.Freeze(ctx, []*container.ContainerConfig{nil}, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	assert.False(t, called)
+}
+
+func TestFreeze(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	client := setupTestClient(t)
+	busybox := image.NewConfig(DefaultDoctorProbeImage)
+	require.NoError(t, client.EnsureImage(ctx, busybox, PullPolicyIfNotPresent))
+
+	var group []*container.ContainerConfig
+	for _, name := range []string{"db", "app"} {
+		ctr := container.NewConfig("godock-freeze-" + name + "-" + GenerateRandomString(8))
+		ctr.SetContainerOptions(
+			containeroptions.Image(busybox),
+			containeroptions.CMD("sleep", "60"),
+		)
+		require.NoError(t, client.ContainerCreate(ctx, ctr))
+		defer client.ContainerRemove(context.WithoutCancel(ctx), ctr, removeoptions.Force())
+		require.NoError(t, client.ContainerStart(ctx, ctr))
+		group = append(group, ctr)
+	}
+
+	isPaused := func(ctr *container.ContainerConfig) bool {
+		inspect, err := client.wrapped.ContainerInspect(ctx, ctr.Id)
+		require.NoError(t, err)
+		return inspect.State.Paused
+	}
+
+	// A container paused beforehand stays paused after the freeze.
+	require.NoError(t, client.ContainerPause(ctx, group[0]))
+	fnErr := errors.New("backup failed")
+	err := client.Freeze(ctx, group, func(ctx context.Context) error {
+		assert.True(t, isPaused(group[0]))
+		assert.True(t, isPaused(group[1]))
+		return fnErr
+	})
+	assert.ErrorIs(t, err, fnErr)
+	assert.True(t, isPaused(group[0]))
+	assert.False(t, isPaused(group[1]))
+
+	require.NoError(t, client.PauseAll(ctx, group...))
+	assert.True(t, isPaused(group[1]))
+	require.NoError(t, client.UnpauseAll(ctx, group...))
+	assert.False(t, isPaused(group[0]))
+	assert.False(t, isPaused(group[1]))
+}
@@ -0,0 +1,511 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/aptd3v/godock/pkg/godock/runoptions"
+	"github.com/aptd3v/godock/pkg/godock/volume"
+)
+
+// LabelPipeline is the label set on the artifacts volume and every container of a pipeline run, with the name
+// of the volume.
+const LabelPipeline = "godock.pipeline.run"
+
+const (
+	// DefaultArtifactsPath is where the artifacts volume is mounted in every step by default.
+	DefaultArtifactsPath = "/artifacts"
+	// DefaultLogTail is the number of last output lines kept for a failed step by default.
+	DefaultLogTail = 20
+)
+
+// ErrStepFailed is wrapped by the error Run returns when a step failed.
+var ErrStepFailed = errors.New("pipeline step failed")
+
+// State is the state of a step in a report.
+type State string
+
+const (
+	// Succeeded steps exited with code 0.
+	Succeeded State = "succeeded"
+	// Failed steps failed their last attempt.
+	Failed State = "failed"
+	// Skipped steps did not run because an earlier stage failed.
+	Skipped State = "skipped"
+	// Canceled steps were cancelled before they finished.
+	Canceled State = "canceled"
+)
+
+// Step is a container run as part of a pipeline.
+type Step struct {
+	name         string
+	config       *container.ContainerConfig
+	timeout      time.Duration
+	retries      int
+	backoff      time.Duration
+	allowFailure bool
+	artifacts    []artifact
+}
+
+// artifact is a path collected from the container of a step.
+type artifact struct {
+	containerPath string
+	destDir       string
+	opts          []godock.ArtifactOptionFn
+}
+
+// SetStepOptFn is a function type that configures a Step.
+type SetStepOptFn func(s *Step)
+
+/*
+NewStep creates a step running a copy of a container config. The step name identifies it in the report, the
+config name is the name of its container.
+
+Usage example:
+
+	test := pipeline.NewStep("test", testConfig, pipeline.StepTimeout(10*time.Minute), pipeline.StepRetries(1, time.Second))
+*/
+func NewStep(name string, containerConfig *container.ContainerConfig, setOptFns ...SetStepOptFn) *Step {
+	s := &Step{name: name, backoff: time.Second}
+	if containerConfig != nil {
+		s.config = containerConfig.Clone()
+	}
+	for _, set := range setOptFns {
+		if set != nil {
+			set(s)
+		}
+	}
+	return s
+}
+
+// StepTimeout limits every attempt of the step; an attempt running longer is removed and fails.
+func StepTimeout(timeout time.Duration) SetStepOptFn {
+	return func(s *Step) {
+		if timeout > 0 {
+			s.timeout = timeout
+		}
+	}
+}
+
+// StepRetries retries a failed step up to retries times, waiting backoff before the first retry and doubling it
+// after every further attempt. By default failed steps are not retried.
+func StepRetries(retries int, backoff time.Duration) SetStepOptFn {
+	return func(s *Step) {
+		if retries >= 0 {
+			s.retries = retries
+		}
+		if backoff > 0 {
+			s.backoff = backoff
+		}
+	}
+}
+
+// AllowFailure lets the pipeline go on when the step fails. The step is still reported as failed.
+func AllowFailure() SetStepOptFn {
+	return func(s *Step) {
+		s.allowFailure = true
+	}
+}
+
+/*
+StepArtifact extracts containerPath, a path or pattern, into destDir on the client host once the step has run, see
+Client.ExtractArtifact. Failing to extract an artifact fails the step. Pass godock.ExtractOnFailure to collect it
+from failed attempts too, e.g. test reports.
+
+Usage example:
+
+	test := pipeline.NewStep("test", testConfig,
+		pipeline.StepArtifact("/src/reports/*.xml", "./reports", godock.ExtractOnFailure()),
+	)
+*/
+func StepArtifact(containerPath, destDir string, artifactOptionFns ...godock.ArtifactOptionFn) SetStepOptFn {
+	return func(s *Step) {
+		s.artifacts = append(s.artifacts, artifact{containerPath: containerPath, destDir: destDir, opts: artifactOptionFns})
+	}
+}
+
+// StepResult is the outcome of a step.
+type StepResult struct {
+	Name string
+	// Stage is the index of the stage the step belongs to.
+	Stage int
+	State State
+	// Attempts is the number of containers started for the step.
+	Attempts int
+	// ExitCode is the exit code of the last attempt, or -1 when it did not exit on its own.
+	ExitCode int
+	// Logs holds the last output lines of the last attempt when it failed.
+	Logs []string
+	// Artifacts holds the host paths of the files extracted from the last attempt.
+	Artifacts []string
+	Err       error
+
+	Started  time.Time
+	Finished time.Time
+
+	allowFailure bool
+}
+
+// Duration returns how long the step ran, retries included.
+func (r StepResult) Duration() time.Duration {
+	if r.Started.IsZero() {
+		return 0
+	}
+	return r.Finished.Sub(r.Started)
+}
+
+// Report is the outcome of a pipeline run.
+type Report struct {
+	// Volume is the name of the artifacts volume of the run.
+	Volume string
+	// Steps holds the result of every step, in the order they were added.
+	Steps    []StepResult
+	Started  time.Time
+	Finished time.Time
+}
+
+// Succeeded reports whether every step succeeded or was allowed to fail.
+func (r *Report) Succeeded() bool {
+	for _, step := range r.Steps {
+		if step.State != Succeeded && !(step.State == Failed && step.allowFailure) {
+			return false
+		}
+	}
+	return true
+}
+
+// Step returns the result of a step, and false when the pipeline has no step with that name.
+func (r *Report) Step(name string) (StepResult, bool) {
+	for _, step := range r.Steps {
+		if step.Name == name {
+			return step, true
+		}
+	}
+	return StepResult{}, false
+}
+
+// failed returns the first step that stopped the run, or nil.
+func (r *Report) failed() *StepResult {
+	for i, step := range r.Steps {
+		if step.State == Canceled || (step.State == Failed && !step.allowFailure) {
+			return &r.Steps[i]
+		}
+	}
+	return nil
+}
+
+// outcome is the result of a single attempt.
+type outcome struct {
+	exitCode  int
+	logs      []string
+	artifacts []string
+	err       error
+}
+
+/*
+Pipeline runs steps as a sequence of stages, like a lightweight CI runner. The steps of a stage run in parallel and
+a stage starts once every step of the previous one has succeeded. Every step mounts the same volume, so a step can
+leave artifacts for the steps of later stages.
+
+Usage example:
+
+	p := pipeline.New(client)
+	p.Then(pipeline.NewStep("build", buildConfig, pipeline.StepTimeout(15*time.Minute)))
+	p.Then(
+		pipeline.NewStep("unit tests", unitConfig, pipeline.StepRetries(1, 5*time.Second)),
+		pipeline.NewStep("lint", lintConfig, pipeline.AllowFailure()),
+	)
+	p.Then(pipeline.NewStep("package", packageConfig))
+
+	report, err := p.Run(ctx)
+	for _, step := range report.Steps {
+		fmt.Printf("%-12s %-9s %s\n", step.Name, step.State, step.Duration())
+	}
+	if err != nil {
+		return err
+	}
+*/
+type Pipeline struct {
+	client        *godock.Client
+	clock         clock.Clock
+	artifactsPath string
+	keepArtifacts bool
+	logTail       int
+	onUpdate      func(StepResult)
+	stages        [][]*Step
+
+	// run runs one attempt of a step, createVolume and removeVolume manage the artifacts volume; replaced in tests.
+	run          func(ctx context.Context, step *Step, cfg *container.ContainerConfig) outcome
+	createVolume func(ctx context.Context, name string) error
+	removeVolume func(ctx context.Context, name string) error
+}
+
+// SetPipelineOptFn is a function type that configures a Pipeline.
+type SetPipelineOptFn func(p *Pipeline)
+
+// New creates a Pipeline running containers through client.
+func New(client *godock.Client, setOptFns ...SetPipelineOptFn) *Pipeline {
+	p := &Pipeline{
+		client:        client,
+		artifactsPath: DefaultArtifactsPath,
+		logTail:       DefaultLogTail,
+	}
+	if client != nil {
+		p.clock = client.Clock()
+	} else {
+		p.clock = clock.Real()
+	}
+	p.run = p.runContainer
+	p.createVolume = p.createArtifactsVolume
+	p.removeVolume = p.removeArtifactsVolume
+	for _, set := range setOptFns {
+		if set != nil {
+			set(p)
+		}
+	}
+	return p
+}
+
+// WithArtifactsPath sets where the artifacts volume is mounted in every step. The default is
+// DefaultArtifactsPath.
+func WithArtifactsPath(path string) SetPipelineOptFn {
+	return func(p *Pipeline) {
+		if path != "" {
+			p.artifactsPath = path
+		}
+	}
+}
+
+// KeepArtifacts keeps the artifacts volume after the run, e.g. to copy the artifacts out of it. Its name is in
+// the report. By default the volume is removed once the run is over.
+func KeepArtifacts() SetPipelineOptFn {
+	return func(p *Pipeline) {
+		p.keepArtifacts = true
+	}
+}
+
+// WithLogTail sets the number of last output lines kept for a failed step. A value of 0 keeps none. The default
+// is DefaultLogTail.
+func WithLogTail(n int) SetPipelineOptFn {
+	return func(p *Pipeline) {
+		if n >= 0 {
+			p.logTail = n
+		}
+	}
+}
+
+// WithClock sets the clock the retry backoff waits on and the report times come from, e.g. a clock.Fake in tests.
+// It defaults to the client's clock.
+func WithClock(c clock.Clock) SetPipelineOptFn {
+	return func(p *Pipeline) {
+		if c != nil {
+			p.clock = c
+		}
+	}
+}
+
+// OnUpdate sets a function called with the result of every step once it is final. Steps of a stage run in
+// parallel, so it may be called from several goroutines at once.
+func OnUpdate(fn func(StepResult)) SetPipelineOptFn {
+	return func(p *Pipeline) {
+		p.onUpdate = fn
+	}
+}
+
+// Then adds a stage running steps in parallel after the stages added before it.
+func (p *Pipeline) Then(steps ...*Step) *Pipeline {
+	p.stages = append(p.stages, steps)
+	return p
+}
+
+// validate checks that every step has a unique name and a container config.
+func (p *Pipeline) validate() error {
+	if len(p.stages) == 0 {
+		return &errdefs.ValidationError{Field: "stages", Message: "pipeline has no steps"}
+	}
+	names := make(map[string]bool)
+	for _, stage := range p.stages {
+		if len(stage) == 0 {
+			return &errdefs.ValidationError{Field: "stages", Message: "pipeline stage has no steps"}
+		}
+		for _, step := range stage {
+			if step == nil || step.name == "" {
+				return &errdefs.ValidationError{Field: "Step", Message: "step or step name cannot be empty"}
+			}
+			if step.config == nil || step.config.Name == "" || step.config.Options == nil {
+				return &errdefs.ValidationError{Field: "containerConfig", Message: fmt.Sprintf("step %s: container config, name or options cannot be empty", step.name)}
+			}
+			if names[step.name] {
+				return &errdefs.ValidationError{Field: "Step", Message: fmt.Sprintf("step %s is added twice", step.name)}
+			}
+			names[step.name] = true
+		}
+	}
+	return nil
+}
+
+/*
+Run creates the artifacts volume and runs the stages in order. Once a step fails, the steps of later stages are
+skipped; cancelling ctx removes the running containers and cancels the rest. The report holds every step, and the
+error wraps ErrStepFailed and the error of the first step that failed the run.
+*/
+func (p *Pipeline) Run(ctx context.Context) (*Report, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Volume:  "godock-pipeline-" + godock.GenerateRandomString(12),
+		Started: p.clock.Now(),
+	}
+	for i, stage := range p.stages {
+		for _, step := range stage {
+			report.Steps = append(report.Steps, StepResult{Name: step.name, Stage: i, State: Skipped, ExitCode: -1, allowFailure: step.allowFailure})
+		}
+	}
+	if err := p.createVolume(ctx, report.Volume); err != nil {
+		report.Finished = p.clock.Now()
+		return report, err
+	}
+	if !p.keepArtifacts {
+		defer p.removeVolume(context.WithoutCancel(ctx), report.Volume)
+	}
+
+	first := 0
+	for _, stage := range p.stages {
+		results := report.Steps[first : first+len(stage)]
+		first += len(stage)
+		if report.failed() != nil {
+			// The later stages stay skipped.
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			for i := range results {
+				results[i].State = Canceled
+				results[i].Err = err
+			}
+			break
+		}
+
+		var wg sync.WaitGroup
+		for i, step := range stage {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.runStep(ctx, step, report.Volume, &results[i])
+				if p.onUpdate != nil {
+					p.onUpdate(results[i])
+				}
+			}()
+		}
+		wg.Wait()
+	}
+	report.Finished = p.clock.Now()
+
+	if failed := report.failed(); failed != nil {
+		return report, fmt.Errorf("%w: %s: %w", ErrStepFailed, failed.Name, failed.Err)
+	}
+	return report, nil
+}
+
+// runStep runs a step, retrying it as configured, and fills in its result.
+func (p *Pipeline) runStep(ctx context.Context, step *Step, volumeName string, result *StepResult) {
+	result.Started = p.clock.Now()
+	defer func() { result.Finished = p.clock.Now() }()
+
+	backoff := step.backoff
+	for attempt := 1; ; attempt++ {
+		cfg := step.config.Clone()
+		cfg.Id = ""
+		cfg.SetHostOptions(hostoptions.Mount(hostoptions.MountType("volume"), volumeName, p.artifactsPath, false))
+		cfg.SetContainerOptions(containeroptions.Label(LabelPipeline, volumeName))
+
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if step.timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, step.timeout)
+		}
+		out := p.run(attemptCtx, step, cfg)
+		timedOut := attemptCtx.Err() != nil && ctx.Err() == nil
+		cancel()
+		if timedOut && out.err != nil {
+			out.err = fmt.Errorf("timed out after %s: %w", step.timeout, out.err)
+		}
+
+		result.Attempts = attempt
+		result.ExitCode = out.exitCode
+		result.Logs = out.logs
+		result.Artifacts = out.artifacts
+		result.Err = out.err
+		switch {
+		case out.err == nil:
+			result.State = Succeeded
+			return
+		case ctx.Err() != nil:
+			result.State = Canceled
+			return
+		case attempt > step.retries || errdefs.IsInvalidConfig(out.err):
+			result.State = Failed
+			return
+		}
+
+		if err := p.clock.Sleep(ctx, backoff); err != nil {
+			result.State = Canceled
+			return
+		}
+		backoff *= 2
+	}
+}
+
+// runContainer runs one attempt of a step to completion, collects its artifacts and removes its container.
+func (p *Pipeline) runContainer(ctx context.Context, step *Step, cfg *container.ContainerConfig) outcome {
+	result := outcome{exitCode: -1}
+	result.err = p.client.RunAndWait(ctx, cfg, runoptions.RemoveOnCancel(), runoptions.WithFailureLogTail(p.logTail))
+
+	var exit *godock.ExitError
+	var containerErr *errdefs.ContainerError
+	switch {
+	case result.err == nil:
+		result.exitCode = 0
+	case errors.As(result.err, &exit):
+		result.exitCode = exit.Info.ExitCode
+	}
+	if errors.As(result.err, &containerErr) {
+		result.logs = containerErr.Logs
+	}
+	if cfg.Id == "" || ctx.Err() != nil {
+		return result
+	}
+
+	cleanupCtx := context.WithoutCancel(ctx)
+	for _, a := range step.artifacts {
+		files, err := p.client.ExtractArtifact(cleanupCtx, cfg, a.containerPath, a.destDir, a.opts...)
+		result.artifacts = append(result.artifacts, files...)
+		if err != nil && result.err == nil {
+			result.err = err
+		}
+	}
+	p.client.ContainerRemove(cleanupCtx, cfg, removeoptions.Force())
+	return result
+}
+
+// createArtifactsVolume creates the volume shared by the steps.
+func (p *Pipeline) createArtifactsVolume(ctx context.Context, name string) error {
+	vol := volume.NewConfig(name)
+	vol.Options.Labels[LabelPipeline] = name
+	return p.client.VolumeCreate(ctx, vol)
+}
+
+// removeArtifactsVolume removes the volume shared by the steps.
+func (p *Pipeline) removeArtifactsVolume(ctx context.Context, name string) error {
+	return p.client.VolumeRemove(ctx, name, true)
+}
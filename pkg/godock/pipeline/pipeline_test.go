@@ -0,0 +1,189 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPipeline returns a pipeline whose volume calls are recorded instead of sent to a daemon.
+func newTestPipeline(t *testing.T, setOptFns ...SetPipelineOptFn) (p *Pipeline, volumes *[]string) {
+	t.Helper()
+	volumes = &[]string{}
+	p = New(nil, setOptFns...)
+	p.createVolume = func(ctx context.Context, name string) error {
+		*volumes = append(*volumes, "create "+name)
+		return nil
+	}
+	p.removeVolume = func(ctx context.Context, name string) error {
+		*volumes = append(*volumes, "remove "+name)
+		return nil
+	}
+	return p, volumes
+}
+
+func TestRunValidation(t *testing.T) {
+	p, _ := newTestPipeline(t)
+	_, err := p.Run(context.Background())
+	assert.True(t, errdefs.IsInvalidConfig(err))
+
+	p.Then(NewStep("build", container.NewConfig("build")), NewStep("build", container.NewConfig("build-2")))
+	_, err = p.Run(context.Background())
+	assert.True(t, errdefs.IsInvalidConfig(err))
+
+	p, _ = newTestPipeline(t)
+	p.Then(NewStep("build", nil))
+	_, err = p.Run(context.Background())
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
+
+func TestRunStages(t *testing.T) {
+	var updates []string
+	var mu sync.Mutex
+	p, volumes := newTestPipeline(t, WithArtifactsPath("/out"), OnUpdate(func(result StepResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		updates = append(updates, result.Name)
+	}))
+	var order []string
+	bothRunning := make(chan struct{})
+	var running sync.WaitGroup
+	running.Add(2)
+	go func() {
+		running.Wait()
+		close(bothRunning)
+	}()
+	p.run = func(ctx context.Context, step *Step, cfg *container.ContainerConfig) outcome {
+		require.Len(t, cfg.HostOptions.Mounts, 1)
+		assert.Equal(t, "/out", cfg.HostOptions.Mounts[0].Target)
+		assert.Equal(t, cfg.HostOptions.Mounts[0].Source, cfg.Options.Labels[LabelPipeline])
+		if cfg.Name == "test" || cfg.Name == "lint" {
+			// The steps of a stage run in parallel.
+			running.Done()
+			<-bothRunning
+		}
+		mu.Lock()
+		order = append(order, cfg.Name)
+		mu.Unlock()
+		if cfg.Name == "lint" {
+			return outcome{exitCode: 1, logs: []string{"unused variable"}, err: errors.New("exited with code 1")}
+		}
+		return outcome{exitCode: 0}
+	}
+
+	p.Then(NewStep("build", container.NewConfig("build")))
+	p.Then(NewStep("test", container.NewConfig("test")), NewStep("lint", container.NewConfig("lint"), AllowFailure()))
+	p.Then(NewStep("package", container.NewConfig("package")))
+	report, err := p.Run(context.Background())
+	require.NoError(t, err)
+	assert.True(t, report.Succeeded())
+
+	assert.Equal(t, "build", order[0])
+	assert.ElementsMatch(t, []string{"test", "lint"}, order[1:3])
+	assert.Equal(t, "package", order[3])
+	assert.ElementsMatch(t, order, updates)
+	assert.Equal(t, []string{"create " + report.Volume, "remove " + report.Volume}, *volumes)
+
+	lint, ok := report.Step("lint")
+	require.True(t, ok)
+	assert.Equal(t, Failed, lint.State)
+	assert.Equal(t, 1, lint.Stage)
+	assert.Equal(t, 1, lint.ExitCode)
+	assert.Equal(t, []string{"unused variable"}, lint.Logs)
+	pkg, _ := report.Step("package")
+	assert.Equal(t, Succeeded, pkg.State)
+	assert.Equal(t, 0, pkg.ExitCode)
+}
+
+func TestRunFailureSkipsLaterStages(t *testing.T) {
+	p, volumes := newTestPipeline(t, KeepArtifacts())
+	p.run = func(ctx context.Context, step *Step, cfg *container.ContainerConfig) outcome {
+		if cfg.Name == "test" {
+			return outcome{exitCode: 2, err: errors.New("exited with code 2")}
+		}
+		return outcome{exitCode: 0}
+	}
+
+	p.Then(NewStep("build", container.NewConfig("build")))
+	p.Then(NewStep("test", container.NewConfig("test")))
+	p.Then(NewStep("package", container.NewConfig("package")))
+	report, err := p.Run(context.Background())
+	assert.ErrorIs(t, err, ErrStepFailed)
+	assert.EqualError(t, err, "pipeline step failed: test: exited with code 2")
+	assert.False(t, report.Succeeded())
+	assert.Equal(t, []string{"create " + report.Volume}, *volumes, "kept artifacts are not removed")
+
+	states := make([]State, len(report.Steps))
+	for i, step := range report.Steps {
+		states[i] = step.State
+	}
+	assert.Equal(t, []State{Succeeded, Failed, Skipped}, states)
+	assert.Equal(t, 0, report.Steps[2].Attempts)
+}
+
+func TestRunRetriesAndTimeout(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, time.March, 1, 8, 0, 0, 0, time.UTC))
+	p, _ := newTestPipeline(t, WithClock(fake))
+	attempts := 0
+	p.run = func(ctx context.Context, step *Step, cfg *container.ContainerConfig) outcome {
+		attempts++
+		if attempts < 3 {
+			// The step hangs until its timeout.
+			<-ctx.Done()
+			return outcome{exitCode: -1, err: ctx.Err()}
+		}
+		return outcome{exitCode: 0}
+	}
+	p.Then(NewStep("flaky", container.NewConfig("flaky"), StepTimeout(10*time.Millisecond), StepRetries(2, time.Second)))
+
+	done := make(chan struct{})
+	var report *Report
+	var err error
+	go func() {
+		defer close(done)
+		report, err = p.Run(context.Background())
+	}()
+	waitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, fake.BlockUntil(waitCtx, 1))
+	fake.Advance(time.Second)
+	// The backoff doubles after every attempt.
+	require.NoError(t, fake.BlockUntil(waitCtx, 1))
+	fake.Advance(time.Second)
+	assert.Equal(t, 1, fake.Waiters())
+	fake.Advance(time.Second)
+	<-done
+
+	require.NoError(t, err)
+	flaky := report.Steps[0]
+	assert.Equal(t, Succeeded, flaky.State)
+	assert.Equal(t, 3, flaky.Attempts)
+	assert.Equal(t, 3*time.Second, flaky.Duration())
+}
+
+func TestRunCancel(t *testing.T) {
+	p, volumes := newTestPipeline(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	p.run = func(ctx context.Context, step *Step, cfg *container.ContainerConfig) outcome {
+		cancel()
+		<-ctx.Done()
+		return outcome{exitCode: -1, err: ctx.Err()}
+	}
+	p.Then(NewStep("build", container.NewConfig("build"), StepRetries(3, time.Second)))
+	p.Then(NewStep("test", container.NewConfig("test")))
+
+	report, err := p.Run(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, Canceled, report.Steps[0].State)
+	assert.Equal(t, 1, report.Steps[0].Attempts)
+	assert.Equal(t, Skipped, report.Steps[1].State)
+	assert.Equal(t, []string{"create " + report.Volume, "remove " + report.Volume}, *volumes, "the volume is removed after a cancelled run")
+}
@@ -0,0 +1,241 @@
+package pod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/aptd3v/godock/pkg/godock/stopoptions"
+	containerType "github.com/docker/docker/api/types/container"
+)
+
+const (
+	// DefaultInfraImage is the image used for the infra container when none is configured.
+	DefaultInfraImage = "registry.k8s.io/pause:3.9"
+	// LabelPod is the label set on every container that belongs to a pod.
+	LabelPod = "godock.pod"
+	// LabelPodInfra is the label set on the infra container of a pod.
+	LabelPodInfra = "godock.pod.infra"
+)
+
+// Pod is a named group of containers that share the network and IPC namespaces
+// of an infra container, giving them Kubernetes-pod-like co-location on a plain Docker host.
+// Members reach each other on localhost, and ports are published on the infra container.
+type Pod struct {
+	Name    string
+	Infra   *container.ContainerConfig
+	Members []*container.ContainerConfig
+	// SharePID additionally shares the infra container's PID namespace with the members.
+	SharePID bool
+}
+
+// New creates a new pod configuration with the specified name.
+// The infra container is named "<name>-infra" and runs the pause image.
+func New(name string) *Pod {
+	infraImage := image.NewConfig(DefaultInfraImage)
+	infra := container.NewConfig(name + "-infra")
+	infra.SetContainerOptions(
+		containeroptions.Image(infraImage),
+		containeroptions.Label(LabelPod, name),
+		containeroptions.Label(LabelPodInfra, "true"),
+	)
+	infra.SetHostOptions(
+		hostoptions.IpcMode("shareable"),
+	)
+	return &Pod{
+		Name:  name,
+		Infra: infra,
+	}
+}
+
+// String returns the name of the pod.
+func (p *Pod) String() string {
+	return p.Name
+}
+
+/*
+SetInfraImage replaces the image run by the infra container.
+
+Usage example:
+
+	myPod := pod.New("my_pod")
+	myPod.SetInfraImage(image.NewConfig("my-registry/pause:3.9"))
+*/
+func (p *Pod) SetInfraImage(img fmt.Stringer) {
+	p.Infra.SetContainerOptions(containeroptions.Image(img))
+}
+
+/*
+PublishPort publishes a port of the pod on the host.
+Ports must be published on the infra container because members share its network namespace.
+
+Usage example:
+
+	myPod := pod.New("my_pod")
+	myPod.PublishPort("0.0.0.0", "8080", "80")
+*/
+func (p *Pod) PublishPort(hostIP, hostPort, containerPort string) {
+	p.Infra.SetContainerOptions(containeroptions.Expose(containerPort))
+	p.Infra.SetHostOptions(hostoptions.PortBindings(hostIP, hostPort, containerPort))
+}
+
+/*
+Add adds containers to the pod and wires their network, IPC and optionally PID namespaces
+to the infra container.
+
+Usage example:
+
+	myPod := pod.New("my_pod")
+	app := container.NewConfig("app")
+	sidecar := container.NewConfig("sidecar")
+	myPod.Add(app, sidecar)
+*/
+func (p *Pod) Add(members ...*container.ContainerConfig) {
+	for _, member := range members {
+		if member == nil {
+			continue
+		}
+		p.Members = append(p.Members, member)
+	}
+}
+
+// join wires a member's namespaces to the infra container.
+func (p *Pod) join(member *container.ContainerConfig) error {
+	if member.Options == nil {
+		return &errdefs.ValidationError{
+			Field:   member.Name,
+			Message: "pod member options cannot be empty",
+		}
+	}
+	if member.HostOptions == nil {
+		member.HostOptions = &containerType.HostConfig{}
+	}
+	if len(member.HostOptions.PortBindings) > 0 {
+		return &errdefs.ValidationError{
+			Field:   member.Name,
+			Message: "pod members cannot publish ports, use Pod.PublishPort instead",
+		}
+	}
+	if member.Options.Hostname != "" || member.Options.Domainname != "" {
+		return &errdefs.ValidationError{
+			Field:   member.Name,
+			Message: "pod members cannot set a hostname or domain name",
+		}
+	}
+
+	infraRef := "container:" + p.Infra.Name
+	member.SetContainerOptions(containeroptions.Label(LabelPod, p.Name))
+	member.SetHostOptions(
+		hostoptions.NetworkMode(infraRef),
+		hostoptions.IpcMode(infraRef),
+	)
+	if p.SharePID {
+		member.SetHostOptions(hostoptions.PidMode(infraRef))
+	}
+	// Endpoint settings belong to the infra container in a shared network namespace.
+	if member.NetworkingOptions != nil {
+		member.NetworkingOptions.EndpointsConfig = nil
+	}
+	return nil
+}
+
+// Create creates the infra container and every member of the pod.
+// The infra image is pulled if it is not present locally. When a member cannot be created,
+// the infra container and the members created so far are removed again.
+func (p *Pod) Create(ctx context.Context, client *godock.Client) error {
+	if err := client.EnsureImage(ctx, image.NewConfig(p.Infra.Options.Image), godock.PullPolicyIfNotPresent); err != nil {
+		return err
+	}
+	if err := client.ContainerCreate(ctx, p.Infra); err != nil {
+		return err
+	}
+	created := []*container.ContainerConfig{p.Infra}
+	for _, member := range p.Members {
+		err := p.join(member)
+		if err == nil {
+			err = client.ContainerCreate(ctx, member)
+		}
+		if err != nil {
+			return errors.Join(err, p.removeCreated(context.WithoutCancel(ctx), client, created))
+		}
+		created = append(created, member)
+	}
+	return nil
+}
+
+// removeCreated removes containers created by a failed Create, members first.
+func (p *Pod) removeCreated(ctx context.Context, client *godock.Client, created []*container.ContainerConfig) error {
+	var errs []error
+	for i := len(created) - 1; i >= 0; i-- {
+		if err := client.ContainerRemove(ctx, created[i], removeoptions.Force(), removeoptions.RemoveVolumes()); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		created[i].Id = ""
+	}
+	return errors.Join(errs...)
+}
+
+// Start starts the infra container followed by every member of the pod.
+func (p *Pod) Start(ctx context.Context, client *godock.Client) error {
+	if err := client.ContainerStart(ctx, p.Infra); err != nil {
+		return err
+	}
+	for _, member := range p.Members {
+		if err := client.ContainerStart(ctx, member); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run creates and starts the pod.
+func (p *Pod) Run(ctx context.Context, client *godock.Client) error {
+	if err := p.Create(ctx, client); err != nil {
+		return err
+	}
+	return p.Start(ctx, client)
+}
+
+// Stop stops every member of the pod before stopping the infra container.
+func (p *Pod) Stop(ctx context.Context, client *godock.Client, stopOptions ...stopoptions.SetStopAllOptFn) error {
+	return client.StopAll(ctx, p.containers(), stopOptions...)
+}
+
+// Freeze pauses the members of the pod and the infra container, runs fn and unpauses them, like
+// Client.Freeze. It gives fn a consistent view of the pod's volumes, e.g. to back them up.
+func (p *Pod) Freeze(ctx context.Context, client *godock.Client, fn func(ctx context.Context) error) error {
+	return client.Freeze(ctx, p.containers(), fn)
+}
+
+// Remove force removes every member of the pod and the infra container.
+func (p *Pod) Remove(ctx context.Context, client *godock.Client) error {
+	var errs []error
+	for _, c := range p.containers() {
+		if c.Id == "" {
+			continue
+		}
+		if err := client.ContainerRemove(ctx, c, removeoptions.Force(), removeoptions.RemoveVolumes()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// containers returns the infra container followed by the members that have been created.
+func (p *Pod) containers() []*container.ContainerConfig {
+	containers := []*container.ContainerConfig{p.Infra}
+	for _, member := range p.Members {
+		if member.Id != "" {
+			containers = append(containers, member)
+		}
+	}
+	return containers
+}
@@ -0,0 +1,81 @@
+package pod
+
+import (
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	p := New("web")
+
+	assert.Equal(t, "web", p.String())
+	assert.Equal(t, "web-infra", p.Infra.Name)
+	assert.Equal(t, DefaultInfraImage, p.Infra.Options.Image)
+	assert.Equal(t, "web", p.Infra.Options.Labels[LabelPod])
+	assert.Equal(t, containerType.IpcMode("shareable"), p.Infra.HostOptions.IpcMode)
+}
+
+func TestPublishPort(t *testing.T) {
+	p := New("web")
+	p.PublishPort("127.0.0.1", "8080", "80")
+
+	assert.Contains(t, p.Infra.Options.ExposedPorts, nat.Port("80/tcp"))
+	assert.Equal(t, "8080", p.Infra.HostOptions.PortBindings[nat.Port("80/tcp")][0].HostPort)
+}
+
+func TestJoin(t *testing.T) {
+	t.Run("Wires namespaces", func(t *testing.T) {
+		p := New("web")
+		p.SharePID = true
+		app := container.NewConfig("app")
+		p.Add(app, nil)
+
+		require.Len(t, p.Members, 1)
+		require.NoError(t, p.join(app))
+		assert.Equal(t, containerType.NetworkMode("container:web-infra"), app.HostOptions.NetworkMode)
+		assert.Equal(t, containerType.IpcMode("container:web-infra"), app.HostOptions.IpcMode)
+		assert.Equal(t, containerType.PidMode("container:web-infra"), app.HostOptions.PidMode)
+		assert.Equal(t, "web", app.Options.Labels[LabelPod])
+	})
+
+	t.Run("Rejects published ports", func(t *testing.T) {
+		p := New("web")
+		app := container.NewConfig("app")
+		app.SetHostOptions(hostoptions.PortBindings("0.0.0.0", "80", "80"))
+
+		err := p.join(app)
+		require.Error(t, err)
+		assert.True(t, errdefs.IsInvalidConfig(err))
+	})
+
+	t.Run("Initializes missing options", func(t *testing.T) {
+		p := New("web")
+		app := &container.ContainerConfig{Name: "app", Options: &containerType.Config{}}
+
+		require.NoError(t, p.join(app))
+		assert.Equal(t, containerType.NetworkMode("container:web-infra"), app.HostOptions.NetworkMode)
+	})
+
+	t.Run("Rejects missing container options", func(t *testing.T) {
+		p := New("web")
+		err := p.join(&container.ContainerConfig{Name: "app"})
+		require.Error(t, err)
+		assert.True(t, errdefs.IsInvalidConfig(err))
+	})
+
+	t.Run("Rejects hostname", func(t *testing.T) {
+		p := New("web")
+		app := container.NewConfig("app")
+		app.SetContainerOptions(containeroptions.Hostname("app.local"))
+
+		require.Error(t, p.join(app))
+	})
+}
@@ -0,0 +1,223 @@
+package godock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/exec"
+	"github.com/aptd3v/godock/pkg/godock/execoptions"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// DefaultPortForwardImage is the image of the forwarder container used by PortForward when none is configured.
+const DefaultPortForwardImage = "alpine/socat:latest"
+
+type portForwardOptions struct {
+	localAddress string
+	image        string
+}
+
+// PortForwardOptionFn is a function that configures PortForward.
+type PortForwardOptionFn func(*portForwardOptions)
+
+// WithLocalAddress sets the local address to listen on, e.g. "127.0.0.1:15432".
+// The default is a random port on 127.0.0.1.
+func WithLocalAddress(address string) PortForwardOptionFn {
+	return func(opts *portForwardOptions) {
+		opts.localAddress = address
+	}
+}
+
+// WithPortForwardImage sets the image of the forwarder container. The image must provide sleep and socat.
+func WithPortForwardImage(ref string) PortForwardOptionFn {
+	return func(opts *portForwardOptions) {
+		opts.image = ref
+	}
+}
+
+/*
+PortForward listens on a local address and forwards every accepted connection to containerPort inside the
+container, without publishing the port. It works for containers on any network, including internal ones,
+and also reaches ports the container only binds on its loopback interface.
+
+Connections are relayed by a forwarder container that shares the container's network namespace, so the
+container itself needs no extra tools. Closing the returned listener, or cancelling ctx, stops accepting
+connections, closes the active ones and removes the forwarder container.
+
+Usage example:
+
+	l, err := client.PortForward(ctx, db, 5432)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	conn, err := pgx.Connect(ctx, fmt.Sprintf("postgres://app@%s/app", l.Addr()))
+*/
+func (c *Client) PortForward(ctx context.Context, containerConfig *container.ContainerConfig, containerPort int, portForwardOptionFns ...PortForwardOptionFn) (net.Listener, error) {
+	opts := &portForwardOptions{
+		localAddress: "127.0.0.1:0",
+		image:        DefaultPortForwardImage,
+	}
+	for _, fn := range portForwardOptionFns {
+		if fn != nil {
+			fn(opts)
+		}
+	}
+	if containerConfig == nil || containerConfig.Id == "" {
+		return nil, &errdefs.ValidationError{Field: "containerConfig", Message: "container config or id cannot be empty"}
+	}
+	if containerPort < 1 || containerPort > 65535 {
+		return nil, &errdefs.ValidationError{Field: "containerPort", Message: fmt.Sprintf("port %d is out of range", containerPort)}
+	}
+
+	forwarderImage := image.NewConfig(opts.image)
+	if err := c.EnsureImage(ctx, forwarderImage, PullPolicyIfNotPresent); err != nil {
+		return nil, err
+	}
+	forwarder := container.NewConfig("godock-forward-" + GenerateRandomString(8))
+	forwarder.SetContainerOptions(
+		containeroptions.Image(forwarderImage),
+		containeroptions.Entrypoint("sleep", "infinity"),
+	)
+	forwarder.SetHostOptions(hostoptions.NetworkMode("container:" + containerConfig.Id))
+	if err := c.ContainerCreate(ctx, forwarder); err != nil {
+		return nil, err
+	}
+	if err := c.ContainerStart(ctx, forwarder); err != nil {
+		c.ContainerRemove(context.WithoutCancel(ctx), forwarder, removeoptions.Force())
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", opts.localAddress)
+	if err != nil {
+		c.ContainerRemove(context.WithoutCancel(ctx), forwarder, removeoptions.Force())
+		return nil, err
+	}
+
+	forwardCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	pf := &portForward{
+		Listener:  listener,
+		client:    c,
+		forwarder: forwarder,
+		target:    "TCP:127.0.0.1:" + strconv.Itoa(containerPort),
+		ctx:       forwardCtx,
+		cancel:    cancel,
+		conns:     make(map[net.Conn]struct{}),
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			pf.Close()
+		case <-forwardCtx.Done():
+		}
+	}()
+	go pf.serve()
+	return pf, nil
+}
+
+// portForward is the listener returned by PortForward.
+type portForward struct {
+	net.Listener
+	client    *Client
+	forwarder *container.ContainerConfig
+	target    string
+	ctx       context.Context
+	cancel    context.CancelFunc
+
+	mu        sync.Mutex
+	conns     map[net.Conn]struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// Accept always fails: connections are accepted and forwarded by PortForward itself.
+// The listener is returned for its address and to close the forward.
+func (pf *portForward) Accept() (net.Conn, error) {
+	return nil, errors.New("port forward connections cannot be accepted by the caller")
+}
+
+// Close stops forwarding, closes the active connections and removes the forwarder container.
+func (pf *portForward) Close() error {
+	pf.closeOnce.Do(func() {
+		pf.cancel()
+		pf.closeErr = pf.Listener.Close()
+		pf.mu.Lock()
+		for conn := range pf.conns {
+			conn.Close()
+		}
+		pf.mu.Unlock()
+		pf.wg.Wait()
+		if err := pf.client.ContainerRemove(context.Background(), pf.forwarder, removeoptions.Force()); err != nil && !client.IsErrNotFound(err) && pf.closeErr == nil {
+			pf.closeErr = err
+		}
+	})
+	return pf.closeErr
+}
+
+func (pf *portForward) serve() {
+	for {
+		conn, err := pf.Listener.Accept()
+		if err != nil {
+			return
+		}
+		pf.mu.Lock()
+		if pf.ctx.Err() != nil {
+			pf.mu.Unlock()
+			conn.Close()
+			return
+		}
+		pf.conns[conn] = struct{}{}
+		pf.wg.Add(1)
+		pf.mu.Unlock()
+
+		go func() {
+			defer pf.wg.Done()
+			pf.forward(conn)
+			pf.mu.Lock()
+			delete(pf.conns, conn)
+			pf.mu.Unlock()
+			conn.Close()
+		}()
+	}
+}
+
+// forward relays one connection through a socat exec in the forwarder container.
+func (pf *portForward) forward(conn net.Conn) {
+	execConfig := exec.NewConfig()
+	execConfig.SetOptions(
+		execoptions.CMD("socat", "STDIO", pf.target),
+		execoptions.AttachStdin(true),
+		execoptions.AttachStdout(true),
+		execoptions.AttachStderr(true),
+	)
+	if _, err := pf.client.ContainerExecCreate(pf.ctx, pf.forwarder, execConfig); err != nil {
+		return
+	}
+	hijack, err := pf.client.wrapped.ContainerExecAttach(pf.ctx, execConfig.ID, containerType.ExecAttachOptions{})
+	if err != nil {
+		return
+	}
+	defer hijack.Close()
+
+	go func() {
+		io.Copy(hijack.Conn, conn)
+		hijack.CloseWrite()
+	}()
+	// Without a TTY the exec output is multiplexed; socat's diagnostics on stderr are dropped.
+	// The output ends when the container side closes the connection, and the caller then closes conn.
+	stdcopy.StdCopy(conn, io.Discard, hijack.Reader)
+}
@@ -0,0 +1,71 @@
+package godock
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortForwardValidation(t *testing.T) {
+	c := &Client{}
+	ctx := context.Background()
+
+	_, err := c.PortForward(ctx, container.NewConfig("db"), 5432)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+
+	ctr := container.NewConfig("db")
+	ctr.Id = "abc"
+	_, err = c.PortForward(ctx, ctr, 0)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
+
+func TestPortForward(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	client := setupTestClient(t)
+
+	img := image.NewConfig(DefaultDoctorProbeImage)
+	require.NoError(t, client.EnsureImage(ctx, img, PullPolicyIfNotPresent))
+	web := container.NewConfig("godock-port-forward-test")
+	web.SetContainerOptions(
+		containeroptions.Image(img),
+		containeroptions.CMD("sh", "-c", "echo hello > /tmp/index.html && httpd -f -p 127.0.0.1:8080 -h /tmp"),
+	)
+	require.NoError(t, client.ContainerCreate(ctx, web))
+	defer client.ContainerRemove(ctx, web, removeoptions.Force())
+	require.NoError(t, client.ContainerStart(ctx, web))
+
+	l, err := client.PortForward(ctx, web, 8080)
+	require.NoError(t, err)
+	defer l.Close()
+
+	// The server binds to the container's loopback interface, so it is only reachable through the forward
+	var body []byte
+	require.Eventually(t, func() bool {
+		res, err := http.Get("http://" + l.Addr().String() + "/index.html")
+		if err != nil {
+			return false
+		}
+		defer res.Body.Close()
+		body, err = io.ReadAll(res.Body)
+		return err == nil && res.StatusCode == http.StatusOK
+	}, 10*time.Second, 200*time.Millisecond)
+	assert.Equal(t, "hello\n", string(body))
+
+	require.NoError(t, l.Close())
+	_, err = http.Get("http://" + l.Addr().String() + "/index.html")
+	assert.Error(t, err)
+}
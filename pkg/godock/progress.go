@@ -0,0 +1,341 @@
+package godock
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// ProgressEventType is the kind of a ProgressEvent.
+type ProgressEventType string
+
+const (
+	// ProgressStarted is reported once when an operation starts.
+	ProgressStarted ProgressEventType = "started"
+	// ProgressUpdate is reported whenever an operation advances.
+	ProgressUpdate ProgressEventType = "progress"
+	// ProgressDone is reported once when an operation completes successfully.
+	ProgressDone ProgressEventType = "done"
+	// ProgressError is reported once when an operation fails. No event follows it.
+	ProgressError ProgressEventType = "error"
+)
+
+// Operations reported in ProgressEvent.Operation.
+const (
+	ProgressOpPull          = "pull"
+	ProgressOpBuild         = "build"
+	ProgressOpExport        = "export"
+	ProgressOpSave          = "save"
+	ProgressOpLoad          = "load"
+	ProgressOpVolumeBackup  = "volume backup"
+	ProgressOpVolumeRestore = "volume restore"
+)
+
+// ProgressEvent is an event of a long running operation, in the same form for every operation.
+type ProgressEvent struct {
+	Type ProgressEventType
+	// Operation is one of the ProgressOp constants.
+	Operation string
+	// ID identifies what the operation works on: an image reference, container name, file or volume name.
+	ID string
+	// Current and Total measure the progress. They are bytes, except for builds, where they count build steps.
+	// Total is 0 when it is not known.
+	Current int64
+	Total   int64
+	// Err is set on ProgressError events.
+	Err error
+}
+
+/*
+Progress receives the events of long running operations: pulls, builds, exports, image saves and loads
+and volume backups and restores. Every operation reports ProgressStarted first and ends with either
+ProgressDone or ProgressError, so a UI can render one progress component for all of them.
+Events of a single operation are reported in order from one goroutine at a time.
+
+Usage example:
+
+	progress := godock.ProgressFunc(func(e godock.ProgressEvent) {
+		switch e.Type {
+		case godock.ProgressUpdate:
+			bar.Set(e.ID, e.Current, e.Total)
+		case godock.ProgressDone, godock.ProgressError:
+			bar.Finish(e.ID, e.Err)
+		}
+	})
+	err := client.ImagePullWithProgress(ctx, img, progress)
+*/
+type Progress interface {
+	OnProgress(event ProgressEvent)
+}
+
+// ProgressFunc adapts a function to the Progress interface.
+type ProgressFunc func(event ProgressEvent)
+
+func (f ProgressFunc) OnProgress(event ProgressEvent) {
+	f(event)
+}
+
+// errStreamClosed is reported when a stream is closed before its operation completed.
+var errStreamClosed = errors.New("stream closed before the operation completed")
+
+// progressTracker reports the events of one operation. A nil tracker reports nothing, so operations
+// can use it whether or not progress was requested.
+type progressTracker struct {
+	progress  Progress
+	operation string
+	id        string
+	total     int64
+	once      sync.Once
+}
+
+// newProgressTracker returns a tracker for the operation, or nil when progress is nil.
+func newProgressTracker(progress Progress, operation, id string, total int64) *progressTracker {
+	if progress == nil {
+		return nil
+	}
+	return &progressTracker{progress: progress, operation: operation, id: id, total: total}
+}
+
+func (t *progressTracker) event(eventType ProgressEventType, current int64, err error) ProgressEvent {
+	return ProgressEvent{
+		Type:      eventType,
+		Operation: t.operation,
+		ID:        t.id,
+		Current:   current,
+		Total:     t.total,
+		Err:       err,
+	}
+}
+
+func (t *progressTracker) start() {
+	if t == nil {
+		return
+	}
+	t.progress.OnProgress(t.event(ProgressStarted, 0, nil))
+}
+
+func (t *progressTracker) update(current int64) {
+	if t == nil {
+		return
+	}
+	t.progress.OnProgress(t.event(ProgressUpdate, current, nil))
+}
+
+// updateTotal reports progress against a total that is only learned while the operation runs.
+func (t *progressTracker) updateTotal(current, total int64) {
+	if t == nil {
+		return
+	}
+	t.total = total
+	t.update(current)
+}
+
+// finish reports ProgressDone, or ProgressError when err is not nil. Only the first call reports.
+func (t *progressTracker) finish(err error) {
+	if t == nil {
+		return
+	}
+	t.once.Do(func() {
+		if err != nil {
+			t.progress.OnProgress(t.event(ProgressError, 0, err))
+			return
+		}
+		t.progress.OnProgress(t.event(ProgressDone, t.total, nil))
+	})
+}
+
+// chain returns a byte count callback calling onProgress, which may be nil, and reporting an update.
+func (t *progressTracker) chain(onProgress func(int64)) func(int64) {
+	if t == nil {
+		return onProgress
+	}
+	return func(n int64) {
+		if onProgress != nil {
+			onProgress(n)
+		}
+		t.update(n)
+	}
+}
+
+// trackedReadCloser finishes the operation of a returned stream once it has been read to the end,
+// fails it on a read error, and on Close before either.
+type trackedReadCloser struct {
+	io.ReadCloser
+	tracker *progressTracker
+}
+
+func (r *trackedReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err == io.EOF {
+		r.tracker.finish(nil)
+	} else if err != nil {
+		r.tracker.finish(err)
+	}
+	return n, err
+}
+
+func (r *trackedReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.tracker.finish(errStreamClosed)
+	return err
+}
+
+// trackStream wraps a stream returned to the caller so that reading it reports the operation's progress.
+// The byte count reported is the data read from rc unless count is set.
+func trackStream(rc io.ReadCloser, tracker *progressTracker, count bool) io.ReadCloser {
+	if tracker == nil {
+		return rc
+	}
+	if count {
+		rc = struct {
+			io.Reader
+			io.Closer
+		}{&progressReader{r: rc, onProgress: tracker.update}, rc}
+	}
+	return &trackedReadCloser{ReadCloser: rc, tracker: tracker}
+}
+
+// pullProgress adds up the layer progress of a pull to the progress of the whole image.
+type pullProgress struct {
+	layers map[string]*ImageProgress
+}
+
+func newPullProgress() *pullProgress {
+	return &pullProgress{layers: make(map[string]*ImageProgress)}
+}
+
+// add records a progress event of the pull and returns the bytes downloaded and the size of the
+// layers seen so far.
+func (p *pullProgress) add(event ImageProgress) (current, total int64) {
+	if event.ID != "" {
+		layer, ok := p.layers[event.ID]
+		if !ok {
+			layer = &ImageProgress{ID: event.ID}
+			p.layers[event.ID] = layer
+		}
+		switch event.Status {
+		case "Downloading":
+			layer.Current, layer.Total = event.Current, event.Total
+		case "Download complete", "Pull complete", "Already exists":
+			layer.Current = layer.Total
+		}
+	}
+	for _, layer := range p.layers {
+		current += layer.Current
+		total += layer.Total
+	}
+	return current, total
+}
+
+// buildStepPattern matches the step lines of the classic builder, e.g. "Step 3/7 : RUN make".
+var buildStepPattern = regexp.MustCompile(`^Step (\d+)/(\d+) :`)
+
+// buildStep parses a build output line into the current step and the number of steps.
+func buildStep(line string) (current, total int64, ok bool) {
+	m := buildStepPattern.FindStringSubmatch(line)
+	if m == nil {
+		return 0, 0, false
+	}
+	current, _ = strconv.ParseInt(m[1], 10, 64)
+	total, _ = strconv.ParseInt(m[2], 10, 64)
+	return current, total, true
+}
+
+// decodeBuildStream reads build output to the end, reporting the build steps to the tracker.
+func decodeBuildStream(r io.Reader, ref string, tracker *progressTracker) error {
+	err := decodeJSONMessages(r, func(msg jsonmessage.JSONMessage) error {
+		if msg.Error != nil {
+			return newImageError(ref, "build", errors.New(msg.Error.Message))
+		}
+		if current, total, ok := buildStep(msg.Stream); ok {
+			tracker.updateTotal(current, total)
+		}
+		return nil
+	})
+	if err != nil {
+		// Daemon and post-build hook failures are already image errors.
+		var imageErr *errdefs.ImageError
+		if errors.As(err, &imageErr) {
+			return err
+		}
+		return newImageError(ref, "build", err)
+	}
+	return nil
+}
+
+/*
+ImagePullWithProgress pulls an image and waits for the pull to complete, reporting the bytes downloaded
+against the size of the layers to progress. The total grows as the daemon reports the layers to download.
+
+Usage example:
+
+	err := client.ImagePullWithProgress(ctx, img, godock.ProgressFunc(func(e godock.ProgressEvent) {
+		fmt.Printf("\r%s %s %d/%d", e.Operation, e.ID, e.Current, e.Total)
+	}))
+*/
+func (c *Client) ImagePullWithProgress(ctx context.Context, imageConfig *image.ImageConfig, progress Progress) error {
+	if imageConfig == nil || imageConfig.Ref == "" {
+		return &errdefs.ValidationError{
+			Field:   "imageConfig",
+			Message: "image config or reference cannot be empty",
+		}
+	}
+	tracker := newProgressTracker(progress, ProgressOpPull, imageConfig.Ref, 0)
+	tracker.start()
+	err := c.pullImage(ctx, imageConfig, tracker.pullUpdates())
+	tracker.finish(err)
+	return err
+}
+
+/*
+ImageBuildWithProgress builds an image like ImageBuild and waits for the build to complete, reporting
+the build steps to progress. Only the classic builder numbers its steps; with BuildKit progress reports
+only the start and end of the build.
+
+Usage example:
+
+	err := client.ImageBuildWithProgress(ctx, img, godock.ProgressFunc(func(e godock.ProgressEvent) {
+		fmt.Printf("step %d/%d\n", e.Current, e.Total)
+	}))
+*/
+func (c *Client) ImageBuildWithProgress(ctx context.Context, imageConfig *image.ImageConfig, progress Progress) error {
+	if imageConfig == nil || imageConfig.BuildOptions == nil {
+		return &errdefs.ValidationError{
+			Field:   "imageConfig",
+			Message: "image config or build options cannot be empty",
+		}
+	}
+	ref := imageConfig.Ref
+	if len(imageConfig.BuildOptions.Tags) > 0 {
+		ref = imageConfig.BuildOptions.Tags[0]
+	}
+	tracker := newProgressTracker(progress, ProgressOpBuild, ref, 0)
+	tracker.start()
+	rc, err := c.ImageBuild(ctx, imageConfig)
+	if err != nil {
+		tracker.finish(err)
+		return err
+	}
+	defer rc.Close()
+	err = decodeBuildStream(rc, ref, tracker)
+	tracker.finish(err)
+	return err
+}
+
+// pullUpdates returns a pull progress callback reporting the progress of the whole image, or nil.
+func (t *progressTracker) pullUpdates() func(ImageProgress) {
+	if t == nil {
+		return nil
+	}
+	pull := newPullProgress()
+	return func(event ImageProgress) {
+		t.updateTotal(pull.add(event))
+	}
+}
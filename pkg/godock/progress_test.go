@@ -0,0 +1,161 @@
+package godock
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordProgress returns a Progress collecting the events it receives.
+func recordProgress() (Progress, *[]ProgressEvent) {
+	var events []ProgressEvent
+	return ProgressFunc(func(e ProgressEvent) {
+		events = append(events, e)
+	}), &events
+}
+
+func eventTypes(events []ProgressEvent) []ProgressEventType {
+	types := make([]ProgressEventType, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+func TestProgressTracker(t *testing.T) {
+	t.Run("nil progress", func(t *testing.T) {
+		tracker := newProgressTracker(nil, ProgressOpSave, "app", 0)
+		assert.Nil(t, tracker)
+		tracker.start()
+		tracker.update(1)
+		tracker.finish(nil)
+		assert.Nil(t, tracker.chain(nil))
+	})
+
+	t.Run("finishes once", func(t *testing.T) {
+		progress, events := recordProgress()
+		tracker := newProgressTracker(progress, ProgressOpLoad, "images.tar", 100)
+		tracker.start()
+		tracker.chain(nil)(40)
+		tracker.finish(nil)
+		tracker.finish(errors.New("late"))
+
+		assert.Equal(t, []ProgressEventType{ProgressStarted, ProgressUpdate, ProgressDone}, eventTypes(*events))
+		assert.Equal(t, ProgressEvent{Type: ProgressUpdate, Operation: ProgressOpLoad, ID: "images.tar", Current: 40, Total: 100}, (*events)[1])
+		assert.Equal(t, int64(100), (*events)[2].Current)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		progress, events := recordProgress()
+		tracker := newProgressTracker(progress, ProgressOpSave, "app", 0)
+		tracker.start()
+		tracker.finish(io.ErrUnexpectedEOF)
+		require.Len(t, *events, 2)
+		assert.Equal(t, ProgressError, (*events)[1].Type)
+		assert.ErrorIs(t, (*events)[1].Err, io.ErrUnexpectedEOF)
+	})
+}
+
+func TestTrackStream(t *testing.T) {
+	t.Run("read to the end", func(t *testing.T) {
+		progress, events := recordProgress()
+		tracker := newProgressTracker(progress, ProgressOpExport, "app", 0)
+		rc := trackStream(io.NopCloser(strings.NewReader("archive")), tracker, true)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+
+		assert.Equal(t, "archive", string(data))
+		last := (*events)[len(*events)-1]
+		assert.Equal(t, ProgressDone, last.Type)
+		assert.Equal(t, int64(7), (*events)[len(*events)-2].Current)
+	})
+
+	t.Run("closed early", func(t *testing.T) {
+		progress, events := recordProgress()
+		tracker := newProgressTracker(progress, ProgressOpExport, "app", 0)
+		rc := trackStream(io.NopCloser(strings.NewReader("archive")), tracker, false)
+		require.NoError(t, rc.Close())
+		assert.Equal(t, []ProgressEventType{ProgressError}, eventTypes(*events))
+		assert.ErrorIs(t, (*events)[0].Err, errStreamClosed)
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		progress, events := recordProgress()
+		tracker := newProgressTracker(progress, ProgressOpLoad, "images.tar", 0)
+		rc := trackStream(io.NopCloser(iotest.ErrReader(io.ErrClosedPipe)), tracker, false)
+		_, err := io.ReadAll(rc)
+		assert.ErrorIs(t, err, io.ErrClosedPipe)
+		assert.Equal(t, []ProgressEventType{ProgressError}, eventTypes(*events))
+	})
+}
+
+func TestPullProgress(t *testing.T) {
+	pull := newPullProgress()
+	pull.add(ImageProgress{Status: "Pulling from library/redis"})
+	pull.add(ImageProgress{ID: "aaa", Status: "Pulling fs layer"})
+	pull.add(ImageProgress{ID: "aaa", Status: "Downloading", Current: 10, Total: 100})
+	current, total := pull.add(ImageProgress{ID: "bbb", Status: "Downloading", Current: 5, Total: 50})
+	assert.Equal(t, int64(15), current)
+	assert.Equal(t, int64(150), total)
+
+	current, total = pull.add(ImageProgress{ID: "aaa", Status: "Download complete"})
+	assert.Equal(t, int64(105), current)
+	assert.Equal(t, int64(150), total)
+
+	// Extracting reports the extracted bytes, which do not count towards the download.
+	current, _ = pull.add(ImageProgress{ID: "aaa", Status: "Extracting", Current: 1, Total: 300})
+	assert.Equal(t, int64(105), current)
+}
+
+func TestDecodeBuildStream(t *testing.T) {
+	stream := `{"stream":"Step 1/2 : FROM busybox"}
+{"stream":"\n"}
+{"stream":" ---> 65ad0d468eb1\n"}
+{"stream":"Step 2/2 : RUN false"}
+{"errorDetail":{"code":1,"message":"The command '/bin/sh -c false' returned a non-zero code: 1"},"error":"The command '/bin/sh -c false' returned a non-zero code: 1"}
+`
+	progress, events := recordProgress()
+	tracker := newProgressTracker(progress, ProgressOpBuild, "app", 0)
+	err := decodeBuildStream(strings.NewReader(stream), "app", tracker)
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "non-zero code")
+
+	require.Len(t, *events, 2)
+	assert.Equal(t, ProgressEvent{Type: ProgressUpdate, Operation: ProgressOpBuild, ID: "app", Current: 2, Total: 2}, (*events)[1])
+
+	_, _, ok := buildStep("Successfully built 65ad0d468eb1")
+	assert.False(t, ok)
+}
+
+func TestProgressValidation(t *testing.T) {
+	c := &Client{}
+	progress, events := recordProgress()
+	err := c.ImagePullWithProgress(context.Background(), image.NewConfig(""), progress)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	err = c.ImageBuildWithProgress(context.Background(), nil, progress)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	assert.Empty(t, *events)
+}
+
+func TestImagePullWithProgress(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+	client := setupTestClient(t)
+	ctx := context.Background()
+
+	progress, events := recordProgress()
+	require.NoError(t, client.ImagePullWithProgress(ctx, image.NewConfig(DefaultDoctorProbeImage), progress))
+	require.NotEmpty(t, *events)
+	assert.Equal(t, ProgressStarted, (*events)[0].Type)
+	assert.Equal(t, ProgressDone, (*events)[len(*events)-1].Type)
+}
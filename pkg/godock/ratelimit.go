@@ -0,0 +1,157 @@
+package godock
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// rateLimitChunk is the largest number of bytes a throttled connection reads or writes at once, so that transfers
+// are paced in small steps instead of long pauses.
+const rateLimitChunk = 32 * 1024
+
+// rateLimiter paces transfers to a number of bytes per second. It is shared by every transfer of a client, so the
+// limit applies to all of them together.
+type rateLimiter struct {
+	bytesPerSec int64
+	clock       clock.Clock
+
+	mu sync.Mutex
+	// next is the time at which the bytes reserved so far have been transferred at the limit.
+	next time.Time
+}
+
+func newRateLimiter(bytesPerSec int64, clk clock.Clock) *rateLimiter {
+	return &rateLimiter{bytesPerSec: bytesPerSec, clock: clk}
+}
+
+// reserve accounts for n transferred bytes and returns how long to wait to stay within the limit.
+func (l *rateLimiter) reserve(n int64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	if l.next.Before(now) {
+		// Time spent idle is not saved up for a later burst.
+		l.next = now
+	}
+	l.next = l.next.Add(time.Duration(n) * time.Second / time.Duration(l.bytesPerSec))
+	return l.next.Sub(now)
+}
+
+// wait accounts for n transferred bytes and sleeps until they are within the limit.
+func (l *rateLimiter) wait(ctx context.Context, n int64) error {
+	if delay := l.reserve(n); delay > 0 {
+		return l.clock.Sleep(ctx, delay)
+	}
+	return nil
+}
+
+// throttledConn is a connection to the daemon whose reads and writes are paced by a rateLimiter.
+type throttledConn struct {
+	net.Conn
+	limiter *rateLimiter
+}
+
+func (c *throttledConn) Read(p []byte) (int, error) {
+	if len(p) > rateLimitChunk {
+		p = p[:rateLimitChunk]
+	}
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		if waitErr := c.limiter.wait(context.Background(), int64(n)); waitErr != nil && err == nil {
+			err = waitErr
+		}
+	}
+	return n, err
+}
+
+func (c *throttledConn) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:min(len(p), rateLimitChunk)]
+		if err := c.limiter.wait(context.Background(), int64(len(chunk))); err != nil {
+			return written, err
+		}
+		n, err := c.Conn.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// withThrottledDial paces every connection the docker client opens to the daemon.
+// It must come after the options that configure the host, which set the dialer it wraps.
+func withThrottledDial(limiter *rateLimiter) client.Opt {
+	return withTransport("transfer rate limit", func(c *client.Client, transport *http.Transport) error {
+		if transport.DialContext == nil {
+			return errors.New("cannot apply transfer rate limit to a transport without a dialer")
+		}
+		dial := transport.DialContext
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dial(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return &throttledConn{Conn: conn, limiter: limiter}, nil
+		}
+		return nil
+	})
+}
+
+// pacedTransfer paces the reading of a pull or push progress stream to the bytes the daemon reports transferring.
+// The daemon blocks on writing progress that is not read, which holds back its transfer from or to the registry.
+type pacedTransfer struct {
+	ctx     context.Context
+	limiter *rateLimiter
+	body    io.ReadCloser
+	scanner jsonMessageScanner
+	current map[string]int64
+}
+
+// paceTransfer wraps the progress stream of a pull or push to pace it to the transfer rate limit of the client.
+// It returns body as is when the client has no limit.
+func (c *Client) paceTransfer(ctx context.Context, body io.ReadCloser) io.ReadCloser {
+	if c.limiter == nil {
+		return body
+	}
+	return &pacedTransfer{ctx: ctx, limiter: c.limiter, body: body, current: make(map[string]int64)}
+}
+
+func (r *pacedTransfer) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	if n > 0 {
+		if waitErr := r.scanner.write(p[:n], r.observe); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+func (r *pacedTransfer) Close() error {
+	return r.body.Close()
+}
+
+// observe waits for the bytes a layer has been transferred by since its last progress message.
+func (r *pacedTransfer) observe(msg jsonmessage.JSONMessage) error {
+	if msg.Progress == nil || (msg.Status != "Downloading" && msg.Status != "Pushing") {
+		return nil
+	}
+	transferred := msg.Progress.Current - r.current[msg.ID]
+	if transferred <= 0 {
+		return nil
+	}
+	r.current[msg.ID] = msg.Progress.Current
+	return r.limiter.wait(r.ctx, transferred)
+}
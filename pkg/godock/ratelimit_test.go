@@ -0,0 +1,80 @@
+package godock
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	limiter := newRateLimiter(1000, fake)
+
+	assert.Equal(t, 500*time.Millisecond, limiter.reserve(500))
+	assert.Equal(t, time.Second, limiter.reserve(500), "reservations add up")
+
+	fake.Advance(3 * time.Second)
+	assert.Equal(t, 100*time.Millisecond, limiter.reserve(100), "idle time is not saved up")
+}
+
+func TestTransferRateLimit(t *testing.T) {
+	const stream = `{"status":"Pulling fs layer","id":"a"}
+{"status":"Downloading","progressDetail":{"current":1000,"total":4000},"id":"a"}
+{"status":"Downloading","progressDetail":{"current":3000,"total":4000},"id":"a"}
+{"status":"Extracting","progressDetail":{"current":3000,"total":4000},"id":"a"}
+{"status":"Downloading","progressDetail":{"current":500,"total":500},"id":"b"}
+{"status":"Digest: sha256:abc"}
+`
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1.45/images/create", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, stream)
+	})
+	c := newFakeDaemonClient(t, mux)
+	fake := clock.NewFake(time.Unix(0, 0))
+	c.limiter = newRateLimiter(1000, fake)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rc, err := c.ImagePull(ctx, image.NewConfig("alpine"))
+	require.NoError(t, err)
+	defer rc.Close()
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.ReadAll(rc)
+		done <- err
+	}()
+
+	// Every downloaded byte is paced, extracting is not.
+	for _, step := range []time.Duration{time.Second, 2 * time.Second, 500 * time.Millisecond} {
+		require.NoError(t, fake.BlockUntil(ctx, 1))
+		fake.Advance(step - time.Millisecond)
+		assert.Equal(t, 1, fake.Waiters())
+		fake.Advance(time.Millisecond)
+	}
+	require.NoError(t, <-done)
+
+	t.Run("Connections", func(t *testing.T) {
+		local, remote := net.Pipe()
+		defer local.Close()
+		defer remote.Close()
+		conn := &throttledConn{Conn: local, limiter: newRateLimiter(1000, fake)}
+		go io.Copy(io.Discard, remote)
+
+		written := make(chan error, 1)
+		go func() {
+			_, err := conn.Write(make([]byte, 2000))
+			written <- err
+		}()
+		require.NoError(t, fake.BlockUntil(ctx, 1))
+		fake.Advance(2 * time.Second)
+		require.NoError(t, <-written)
+	})
+}
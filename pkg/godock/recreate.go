@@ -0,0 +1,207 @@
+package godock
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/stopoptions"
+	"github.com/docker/docker/api/types"
+	containerType "github.com/docker/docker/api/types/container"
+	dockerNetwork "github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+)
+
+// ContainerRecreate replaces a container with a new one created from the same configuration,
+// for example after its image has been updated. The container config's Id is updated to the new container.
+//
+// Containers that share the replaced container's namespaces (container:<name|id> network, pid or ipc modes)
+// or mount its volumes (VolumesFrom) would silently keep pointing at the old container, so they are
+// recreated as well and started again if they were running. This repeats for their own dependents.
+// The returned map holds the old ID to new ID of every recreated dependent.
+//
+// Anonymous volumes of recreated containers are not carried over; use named volumes for data that must persist.
+func (c *Client) ContainerRecreate(ctx context.Context, containerConfig *container.ContainerConfig, stopOptionFns ...stopoptions.SetStopOptFn) (map[string]string, error) {
+	if containerConfig == nil || containerConfig.Id == "" {
+		return nil, &errdefs.ValidationError{
+			Field:   "containerConfig",
+			Message: "container config or ID cannot be empty",
+		}
+	}
+
+	oldID := containerConfig.Id
+	inspect, err := c.wrapped.ContainerInspect(ctx, oldID)
+	if err != nil {
+		return nil, &errdefs.ContainerError{
+			ID:      containerConfig.Name,
+			Op:      "recreate",
+			Message: err.Error(),
+			Err:     err,
+		}
+	}
+
+	if err := c.ContainerStop(ctx, containerConfig, stopOptionFns...); err != nil && !client.IsErrNotFound(err) {
+		return nil, err
+	}
+	if err := c.wrapped.ContainerRemove(ctx, oldID, containerType.RemoveOptions{Force: true}); err != nil && !client.IsErrNotFound(err) {
+		return nil, &errdefs.ContainerError{
+			ID:      containerConfig.Name,
+			Op:      "remove",
+			Message: err.Error(),
+			Err:     err,
+		}
+	}
+
+	containerConfig.Id = ""
+	if err := c.ContainerCreate(ctx, containerConfig); err != nil {
+		return nil, err
+	}
+	if inspect.State != nil && inspect.State.Running {
+		if err := c.ContainerStart(ctx, containerConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	recreated := make(map[string]string)
+	err = c.recreateDependents(ctx, oldID, strings.TrimPrefix(inspect.Name, "/"), containerConfig.Id, recreated)
+	return recreated, err
+}
+
+// recreateDependents recreates every container that depends on the container that was replaced.
+func (c *Client) recreateDependents(ctx context.Context, oldID, name, newID string, recreated map[string]string) error {
+	dependents, err := c.dependentsOf(ctx, oldID, name)
+	if err != nil {
+		return err
+	}
+
+	for _, dependent := range dependents {
+		dependentName := strings.TrimPrefix(dependent.Name, "/")
+		hostConfig := dependent.HostConfig
+		hostConfig.NetworkMode = containerType.NetworkMode(rewriteContainerMode(string(hostConfig.NetworkMode), oldID, newID))
+		hostConfig.PidMode = containerType.PidMode(rewriteContainerMode(string(hostConfig.PidMode), oldID, newID))
+		hostConfig.IpcMode = containerType.IpcMode(rewriteContainerMode(string(hostConfig.IpcMode), oldID, newID))
+		for i, from := range hostConfig.VolumesFrom {
+			ref, mode, hasMode := strings.Cut(from, ":")
+			if matchesContainer(ref, oldID, "") {
+				ref = newID
+			}
+			if hasMode {
+				ref += ":" + mode
+			}
+			hostConfig.VolumesFrom[i] = ref
+		}
+
+		networking := &dockerNetwork.NetworkingConfig{}
+		if !hostConfig.NetworkMode.IsContainer() {
+			networking = networkingFromInspect(dependent)
+		}
+
+		if err := c.wrapped.ContainerRemove(ctx, dependent.ID, containerType.RemoveOptions{Force: true}); err != nil && !client.IsErrNotFound(err) {
+			return &errdefs.ContainerError{
+				ID:      dependentName,
+				Op:      "remove",
+				Message: err.Error(),
+				Err:     err,
+			}
+		}
+
+		res, err := c.wrapped.ContainerCreate(ctx, dependent.Config, hostConfig, networking, nil, dependentName)
+		if err != nil {
+			return &errdefs.ContainerError{
+				ID:      dependentName,
+				Op:      "recreate",
+				Message: err.Error(),
+				Err:     err,
+			}
+		}
+		recreated[dependent.ID] = res.ID
+
+		if dependent.State != nil && dependent.State.Running {
+			if err := c.wrapped.ContainerStart(ctx, res.ID, containerType.StartOptions{}); err != nil {
+				return &errdefs.ContainerError{
+					ID:      dependentName,
+					Op:      "start",
+					Message: err.Error(),
+					Err:     err,
+				}
+			}
+		}
+
+		if err := c.recreateDependents(ctx, dependent.ID, dependentName, res.ID, recreated); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dependentsOf returns the containers that share namespaces with, or mount volumes from, the given container.
+func (c *Client) dependentsOf(ctx context.Context, id, name string) ([]types.ContainerJSON, error) {
+	containers, err := c.wrapped.ContainerList(ctx, containerType.ListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var dependents []types.ContainerJSON
+	for _, summary := range containers {
+		if summary.ID == id {
+			continue
+		}
+		inspect, err := c.wrapped.ContainerInspect(ctx, summary.ID)
+		if err != nil {
+			if client.IsErrNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if dependsOnContainer(inspect.HostConfig, id, name) {
+			dependents = append(dependents, inspect)
+		}
+	}
+	return dependents, nil
+}
+
+// dependsOnContainer reports whether a host configuration references the given container.
+func dependsOnContainer(hostConfig *containerType.HostConfig, id, name string) bool {
+	if hostConfig == nil {
+		return false
+	}
+	if hostConfig.NetworkMode.IsContainer() && matchesContainer(hostConfig.NetworkMode.ConnectedContainer(), id, name) {
+		return true
+	}
+	if hostConfig.PidMode.IsContainer() && matchesContainer(hostConfig.PidMode.Container(), id, name) {
+		return true
+	}
+	if hostConfig.IpcMode.IsContainer() && matchesContainer(hostConfig.IpcMode.Container(), id, name) {
+		return true
+	}
+	for _, from := range hostConfig.VolumesFrom {
+		ref, _, _ := strings.Cut(from, ":")
+		if matchesContainer(ref, id, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesContainer reports whether ref refers to the container by name, full ID or short ID.
+func matchesContainer(ref, id, name string) bool {
+	ref = strings.TrimPrefix(ref, "/")
+	if ref == "" {
+		return false
+	}
+	if name != "" && ref == name {
+		return true
+	}
+	return ref == id || (len(ref) >= 12 && strings.HasPrefix(id, ref))
+}
+
+// rewriteContainerMode points a container:<id> namespace mode at the new container ID.
+// Modes that reference the container by name are left as is since the name is preserved.
+func rewriteContainerMode(mode, oldID, newID string) string {
+	ref, ok := strings.CutPrefix(mode, "container:")
+	if !ok || !matchesContainer(ref, oldID, "") {
+		return mode
+	}
+	return "container:" + newID
+}
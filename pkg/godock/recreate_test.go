@@ -0,0 +1,44 @@
+package godock
+
+import (
+	"testing"
+
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDependsOnContainer(t *testing.T) {
+	id := "0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name       string
+		hostConfig *containerType.HostConfig
+		want       bool
+	}{
+		{"Nil host config", nil, false},
+		{"Unrelated", &containerType.HostConfig{NetworkMode: "bridge"}, false},
+		{"Network by name", &containerType.HostConfig{NetworkMode: "container:db"}, true},
+		{"Network by ID", &containerType.HostConfig{NetworkMode: containerType.NetworkMode("container:" + id)}, true},
+		{"Pid by short ID", &containerType.HostConfig{PidMode: "container:0123456789ab"}, true},
+		{"Ipc by name", &containerType.HostConfig{IpcMode: "container:db"}, true},
+		{"Too short ID prefix", &containerType.HostConfig{IpcMode: "container:0123"}, false},
+		{"Volumes from with mode", &containerType.HostConfig{VolumesFrom: []string{"db:ro"}}, true},
+		{"Volumes from other", &containerType.HostConfig{VolumesFrom: []string{"cache"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, dependsOnContainer(tt.hostConfig, id, "db"))
+		})
+	}
+}
+
+func TestRewriteContainerMode(t *testing.T) {
+	oldID := "0123456789abcdef0123456789abcdef"
+	newID := "fedcba9876543210fedcba9876543210"
+
+	assert.Equal(t, "container:"+newID, rewriteContainerMode("container:"+oldID, oldID, newID))
+	assert.Equal(t, "container:"+newID, rewriteContainerMode("container:0123456789ab", oldID, newID))
+	assert.Equal(t, "container:db", rewriteContainerMode("container:db", oldID, newID))
+	assert.Equal(t, "host", rewriteContainerMode("host", oldID, newID))
+}
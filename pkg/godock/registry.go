@@ -0,0 +1,62 @@
+package godock
+
+import (
+	"context"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/registryauth"
+	"github.com/docker/docker/api/types/registry"
+)
+
+// RegistryLogin authenticates against a registry and stores the credentials in the client's
+// registry auth manager, so later pulls, pushes and builds use them automatically.
+// Clients created with WithRegistryAuth(nil) only authenticate and keep automatic credentials disabled.
+// An empty server logs in to Docker Hub. It is safe for concurrent use.
+func (c *Client) RegistryLogin(ctx context.Context, server, username, password string) (registry.AuthenticateOKBody, error) {
+	if server == "" {
+		server = registryauth.DockerHubServer
+	}
+	auth := registry.AuthConfig{
+		Username:      username,
+		Password:      password,
+		ServerAddress: server,
+	}
+	res, err := c.wrapped.RegistryLogin(ctx, auth)
+	if err != nil {
+		return registry.AuthenticateOKBody{}, &errdefs.ConfigError{
+			Field:   "registry",
+			Message: err.Error(),
+		}
+	}
+	if res.IdentityToken != "" {
+		auth.Password = ""
+		auth.IdentityToken = res.IdentityToken
+	}
+	// The manager is set once when the client is created and locks itself, so it is never replaced here.
+	if c.auth != nil {
+		c.auth.Set(server, auth)
+	}
+	return res, nil
+}
+
+// RegistryAuth returns the manager used to resolve registry credentials, or nil if automatic credentials are disabled.
+func (c *Client) RegistryAuth() *registryauth.Manager {
+	return c.auth
+}
+
+// registryAuthFor returns the encoded credentials for an image reference, or an empty string if there are none.
+func (c *Client) registryAuthFor(ctx context.Context, ref string) (string, error) {
+	if c.auth == nil {
+		return "", nil
+	}
+	encoded, err := c.auth.EncodedForImage(ctx, ref)
+	if err != nil {
+		return "", &errdefs.ImageError{
+			Ref:     ref,
+			Op:      "auth",
+			Message: err.Error(),
+			Err:     err,
+		}
+	}
+	return encoded, nil
+}
@@ -0,0 +1,401 @@
+package registryauth
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/registry"
+)
+
+// DockerHubServer is the server key Docker uses for Docker Hub credentials.
+const DockerHubServer = "https://index.docker.io/v1/"
+
+// ConfigFile is the subset of a Docker CLI config.json that holds registry credentials.
+type ConfigFile struct {
+	Auths       map[string]registry.AuthConfig `json:"auths"`
+	CredsStore  string                         `json:"credsStore,omitempty"`
+	CredHelpers map[string]string              `json:"credHelpers,omitempty"`
+}
+
+// DefaultConfigFile returns the path of the Docker CLI config file,
+// honouring the DOCKER_CONFIG environment variable.
+func DefaultConfigFile() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".docker", "config.json")
+}
+
+// LoadConfigFile reads a Docker CLI config file. A missing file yields an empty config.
+func LoadConfigFile(path string) (*ConfigFile, error) {
+	config := &ConfigFile{
+		Auths:       make(map[string]registry.AuthConfig),
+		CredHelpers: make(map[string]string),
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return config, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse docker config %s: %w", path, err)
+	}
+	for server, auth := range config.Auths {
+		if auth.Auth != "" && auth.Username == "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("invalid auth for %s in docker config %s: %w", server, path, err)
+			}
+			auth.Username, auth.Password, _ = strings.Cut(string(decoded), ":")
+			auth.Auth = ""
+		}
+		auth.ServerAddress = server
+		config.Auths[server] = auth
+	}
+	return config, nil
+}
+
+// Manager resolves registry credentials from logins, a Docker CLI config file and credential helpers.
+// It is safe for concurrent use.
+type Manager struct {
+	mu          sync.RWMutex
+	configFile  string
+	credentials map[string]registry.AuthConfig
+	helpers     map[string]string
+	credsStore  string
+	onSkip      func(server string, err error)
+}
+
+// SetManagerOptFn is a function type that configures a Manager.
+type SetManagerOptFn func(m *Manager)
+
+// New creates a Manager. Without options it only knows credentials added with Set.
+func New(setOptFns ...SetManagerOptFn) *Manager {
+	m := &Manager{
+		credentials: make(map[string]registry.AuthConfig),
+		helpers:     make(map[string]string),
+		onSkip: func(server string, err error) {
+			log.Printf("registryauth: skipping credentials for %s: %v", server, err)
+		},
+	}
+	for _, set := range setOptFns {
+		if set != nil {
+			set(m)
+		}
+	}
+	return m
+}
+
+/*
+WithConfigFile reads credentials, the credential store and credential helpers from a Docker CLI config file.
+The file is read on every lookup, so logins made with the docker CLI are picked up.
+
+Usage example:
+
+	auth := registryauth.New(
+		registryauth.WithConfigFile(registryauth.DefaultConfigFile()),
+	)
+*/
+func WithConfigFile(path string) SetManagerOptFn {
+	return func(m *Manager) {
+		m.configFile = path
+	}
+}
+
+/*
+WithCredentials adds static credentials for a registry server.
+
+Usage example:
+
+	auth := registryauth.New(
+		registryauth.WithCredentials("registry.example.com", "user", os.Getenv("REGISTRY_TOKEN")),
+	)
+*/
+func WithCredentials(server, username, password string) SetManagerOptFn {
+	return func(m *Manager) {
+		m.credentials[NormalizeServer(server)] = registry.AuthConfig{
+			Username:      username,
+			Password:      password,
+			ServerAddress: server,
+		}
+	}
+}
+
+/*
+WithCredentialHelper uses the docker-credential-<helper> binary for a registry server.
+
+Usage example:
+
+	auth := registryauth.New(
+		registryauth.WithCredentialHelper("123456789.dkr.ecr.us-east-1.amazonaws.com", "ecr-login"),
+	)
+*/
+func WithCredentialHelper(server, helper string) SetManagerOptFn {
+	return func(m *Manager) {
+		m.helpers[NormalizeServer(server)] = helper
+	}
+}
+
+/*
+WithCredentialStore uses the docker-credential-<store> binary for every registry without a more specific helper.
+
+Usage example:
+
+	auth := registryauth.New(
+		registryauth.WithCredentialStore("osxkeychain"),
+	)
+*/
+func WithCredentialStore(store string) SetManagerOptFn {
+	return func(m *Manager) {
+		m.credsStore = store
+	}
+}
+
+/*
+OnSkip sets the function called when All skips a registry because its credential helper failed.
+By default the skipped registry is logged with the standard logger.
+
+Usage example:
+
+	auth := registryauth.New(
+		registryauth.WithConfigFile(registryauth.DefaultConfigFile()),
+		registryauth.OnSkip(func(server string, err error) {
+			logger.Warn("registry credentials unavailable", "server", server, "error", err)
+		}),
+	)
+*/
+func OnSkip(fn func(server string, err error)) SetManagerOptFn {
+	return func(m *Manager) {
+		m.onSkip = fn
+	}
+}
+
+// Set stores credentials for a registry server, replacing any previous credentials for it.
+func (m *Manager) Set(server string, auth registry.AuthConfig) {
+	if auth.ServerAddress == "" {
+		auth.ServerAddress = server
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.credentials[NormalizeServer(server)] = auth
+}
+
+// Remove forgets the stored credentials for a registry server.
+func (m *Manager) Remove(server string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.credentials, NormalizeServer(server))
+}
+
+// Lookup returns the credentials for a registry server.
+// Stored credentials take precedence over credential helpers, which take precedence over the config file.
+func (m *Manager) Lookup(ctx context.Context, server string) (registry.AuthConfig, bool, error) {
+	key := NormalizeServer(server)
+
+	m.mu.RLock()
+	auth, ok := m.credentials[key]
+	helper := m.helpers[key]
+	credsStore := m.credsStore
+	configFile := m.configFile
+	m.mu.RUnlock()
+	if ok {
+		return auth, true, nil
+	}
+
+	var config *ConfigFile
+	if configFile != "" {
+		var err error
+		config, err = LoadConfigFile(configFile)
+		if err != nil {
+			return registry.AuthConfig{}, false, err
+		}
+		if helper == "" {
+			helper = lookupServer(config.CredHelpers, key)
+		}
+		if credsStore == "" {
+			credsStore = config.CredsStore
+		}
+	}
+
+	if helper == "" {
+		helper = credsStore
+	}
+	if helper != "" {
+		auth, ok, err := helperGet(ctx, helper, server)
+		if err != nil || ok {
+			return auth, ok, err
+		}
+	}
+
+	if config != nil {
+		for configServer, auth := range config.Auths {
+			if NormalizeServer(configServer) == key {
+				return auth, true, nil
+			}
+		}
+	}
+	return registry.AuthConfig{}, false, nil
+}
+
+// ForImage returns the credentials for the registry an image reference points at.
+func (m *Manager) ForImage(ctx context.Context, ref string) (registry.AuthConfig, bool, error) {
+	server, err := ServerForImage(ref)
+	if err != nil {
+		return registry.AuthConfig{}, false, err
+	}
+	return m.Lookup(ctx, server)
+}
+
+// EncodedForImage returns the base64 encoded credentials for an image reference,
+// as expected by the RegistryAuth field of pull and push options. It returns an empty string when there are none.
+func (m *Manager) EncodedForImage(ctx context.Context, ref string) (string, error) {
+	auth, ok, err := m.ForImage(ctx, ref)
+	if err != nil || !ok {
+		return "", err
+	}
+	return registry.EncodeAuthConfig(auth)
+}
+
+// All returns the credentials for every known registry keyed by server address,
+// as expected by the AuthConfigs field of build options.
+// Credential helpers are queried for the servers they are configured for. A registry whose helper fails
+// is skipped and reported to the OnSkip function, so one broken helper does not fail every build; use
+// Lookup or ForImage to get the error for a specific registry.
+func (m *Manager) All(ctx context.Context) (map[string]registry.AuthConfig, error) {
+	m.mu.RLock()
+	servers := make(map[string]string)
+	for key, auth := range m.credentials {
+		servers[key] = auth.ServerAddress
+	}
+	for key := range m.helpers {
+		servers[key] = key
+	}
+	configFile := m.configFile
+	m.mu.RUnlock()
+
+	if configFile != "" {
+		config, err := LoadConfigFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+		for server := range config.Auths {
+			servers[NormalizeServer(server)] = server
+		}
+		for server := range config.CredHelpers {
+			servers[NormalizeServer(server)] = server
+		}
+	}
+
+	all := make(map[string]registry.AuthConfig, len(servers))
+	for _, server := range servers {
+		auth, ok, err := m.Lookup(ctx, server)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if m.onSkip != nil {
+				m.onSkip(server, err)
+			}
+			continue
+		}
+		if ok {
+			if auth.ServerAddress == "" {
+				auth.ServerAddress = server
+			}
+			all[auth.ServerAddress] = auth
+		}
+	}
+	return all, nil
+}
+
+// ServerForImage returns the registry server of an image reference.
+// Docker Hub images map to DockerHubServer.
+func ServerForImage(ref string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference %q: %w", ref, err)
+	}
+	domain := reference.Domain(named)
+	if domain == "docker.io" {
+		return DockerHubServer, nil
+	}
+	return domain, nil
+}
+
+// NormalizeServer reduces a registry server address to its host so that
+// "https://registry.example.com/v2/" and "registry.example.com" match.
+func NormalizeServer(server string) string {
+	host := server
+	if _, rest, ok := strings.Cut(host, "://"); ok {
+		host = rest
+	}
+	host, _, _ = strings.Cut(host, "/")
+	host = strings.ToLower(host)
+	switch host {
+	case "docker.io", "index.docker.io", "registry-1.docker.io":
+		return "docker.io"
+	}
+	return host
+}
+
+// lookupServer finds a value keyed by a server address in any of its forms.
+func lookupServer(values map[string]string, key string) string {
+	for server, value := range values {
+		if NormalizeServer(server) == key {
+			return value
+		}
+	}
+	return ""
+}
+
+// helperGet asks a docker credential helper for the credentials of a server.
+func helperGet(ctx context.Context, helper, server string) (registry.AuthConfig, bool, error) {
+	cmd := exec.CommandContext(ctx, "docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(server)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		output := strings.TrimSpace(stdout.String() + stderr.String())
+		if strings.Contains(output, "credentials not found") {
+			return registry.AuthConfig{}, false, nil
+		}
+		return registry.AuthConfig{}, false, fmt.Errorf("credential helper %s failed for %s: %w: %s", helper, server, err, output)
+	}
+
+	var creds struct {
+		ServerURL string
+		Username  string
+		Secret    string
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return registry.AuthConfig{}, false, fmt.Errorf("credential helper %s returned invalid output: %w", helper, err)
+	}
+
+	auth := registry.AuthConfig{ServerAddress: server}
+	if creds.Username == "<token>" {
+		auth.IdentityToken = creds.Secret
+	} else {
+		auth.Username = creds.Username
+		auth.Password = creds.Secret
+	}
+	return auth, true, nil
+}
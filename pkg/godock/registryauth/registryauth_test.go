@@ -0,0 +1,152 @@
+package registryauth
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/docker/docker/api/types/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("alice:s3cret"))
+	path := writeConfig(t, `{
+		"auths": {"https://index.docker.io/v1/": {"auth": "`+auth+`"}},
+		"credsStore": "desktop",
+		"credHelpers": {"gcr.io": "gcloud"}
+	}`)
+
+	config, err := LoadConfigFile(path)
+	require.NoError(t, err)
+	hub := config.Auths[DockerHubServer]
+	assert.Equal(t, "alice", hub.Username)
+	assert.Equal(t, "s3cret", hub.Password)
+	assert.Equal(t, DockerHubServer, hub.ServerAddress)
+	assert.Equal(t, "desktop", config.CredsStore)
+	assert.Equal(t, "gcloud", config.CredHelpers["gcr.io"])
+
+	missing, err := LoadConfigFile(filepath.Join(t.TempDir(), "missing.json"))
+	require.NoError(t, err)
+	assert.Empty(t, missing.Auths)
+}
+
+func TestServerForImage(t *testing.T) {
+	tests := map[string]string{
+		"alpine":                                DockerHubServer,
+		"docker.io/library/alpine:3":            DockerHubServer,
+		"registry.example.com:5000/team/app:v1": "registry.example.com:5000",
+		"ghcr.io/org/app@sha256:" + sha:         "ghcr.io",
+	}
+	for ref, want := range tests {
+		got, err := ServerForImage(ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, want, got, ref)
+	}
+
+	_, err := ServerForImage("not a reference")
+	assert.Error(t, err)
+}
+
+const sha = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestNormalizeServer(t *testing.T) {
+	assert.Equal(t, "docker.io", NormalizeServer(DockerHubServer))
+	assert.Equal(t, "docker.io", NormalizeServer("registry-1.docker.io"))
+	assert.Equal(t, "registry.example.com", NormalizeServer("https://Registry.example.com/v2/"))
+}
+
+func TestManagerLookup(t *testing.T) {
+	ctx := context.Background()
+	auth := base64.StdEncoding.EncodeToString([]byte("bob:hunter2"))
+	path := writeConfig(t, `{"auths": {"registry.example.com": {"auth": "`+auth+`"}}}`)
+
+	m := New(WithConfigFile(path))
+	got, ok, err := m.ForImage(ctx, "registry.example.com/app:latest")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "bob", got.Username)
+
+	m.Set("https://registry.example.com", registry.AuthConfig{Username: "carol", Password: "pw"})
+	got, ok, err = m.Lookup(ctx, "registry.example.com")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "carol", got.Username)
+
+	_, ok, err = m.Lookup(ctx, "other.example.com")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	encoded, err := m.EncodedForImage(ctx, "alpine")
+	require.NoError(t, err)
+	assert.Empty(t, encoded)
+
+	all, err := m.All(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, "carol", all["https://registry.example.com"].Username)
+}
+
+func TestManagerCredentialHelper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper script requires a POSIX shell")
+	}
+	dir := t.TempDir()
+	script := `#!/bin/sh
+read server
+case "$server" in
+  token.example.com) echo '{"ServerURL":"token.example.com","Username":"<token>","Secret":"tok"}' ;;
+  user.example.com) echo '{"ServerURL":"user.example.com","Username":"dave","Secret":"pw"}' ;;
+  *) echo "credentials not found in native keychain"; exit 1 ;;
+esac
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker-credential-test"), []byte(script), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	ctx := context.Background()
+	m := New(WithCredentialStore("test"))
+
+	got, ok, err := m.Lookup(ctx, "token.example.com")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "tok", got.IdentityToken)
+	assert.Empty(t, got.Username)
+
+	got, ok, err = m.Lookup(ctx, "user.example.com")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "dave", got.Username)
+	assert.Equal(t, "pw", got.Password)
+
+	_, ok, err = m.Lookup(ctx, "missing.example.com")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	m = New(WithCredentialHelper("user.example.com", "does-not-exist"))
+	_, _, err = m.Lookup(ctx, "user.example.com")
+	assert.Error(t, err)
+
+	// All skips registries whose helper fails.
+	var skipped []string
+	m = New(
+		WithCredentialHelper("user.example.com", "test"),
+		WithCredentialHelper("broken.example.com", "does-not-exist"),
+		OnSkip(func(server string, err error) { skipped = append(skipped, server) }),
+	)
+	all, err := m.All(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "dave", all["user.example.com"].Username)
+	assert.Len(t, all, 1)
+	assert.Equal(t, []string{"broken.example.com"}, skipped)
+}
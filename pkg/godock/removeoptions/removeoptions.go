@@ -0,0 +1,57 @@
+package removeoptions
+
+import (
+	"github.com/docker/docker/api/types/container"
+)
+
+// SetRemoveOptFn is a function type that configures options for removing a container.
+type SetRemoveOptFn func(options *container.RemoveOptions)
+
+/*
+Force kills the container if it is running before removing it.
+
+Usage example:
+
+	err := client.ContainerRemove(ctx, myContainer,
+		removeoptions.Force(),
+	)
+*/
+func Force() SetRemoveOptFn {
+	return func(options *container.RemoveOptions) {
+		options.Force = true
+	}
+}
+
+/*
+RemoveVolumes removes the anonymous volumes associated with the container.
+Named volumes are never removed.
+
+Usage example:
+
+	err := client.ContainerRemove(ctx, myContainer,
+		removeoptions.Force(),
+		removeoptions.RemoveVolumes(),
+	)
+*/
+func RemoveVolumes() SetRemoveOptFn {
+	return func(options *container.RemoveOptions) {
+		options.RemoveVolumes = true
+	}
+}
+
+/*
+RemoveLinks removes the specified link between containers instead of the container itself.
+The container config's Id should hold the link name, e.g. "/webapp/db".
+
+Usage example:
+
+	link := &container.ContainerConfig{Id: "/webapp/db"}
+	err := client.ContainerRemove(ctx, link,
+		removeoptions.RemoveLinks(),
+	)
+*/
+func RemoveLinks() SetRemoveOptFn {
+	return func(options *container.RemoveOptions) {
+		options.RemoveLinks = true
+	}
+}
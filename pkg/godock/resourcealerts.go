@@ -0,0 +1,230 @@
+package godock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+)
+
+// Resources watched by WatchResourceLimits.
+const (
+	AlertResourceCPU    = "cpu"
+	AlertResourceMemory = "memory"
+)
+
+// AlertRule is a threshold on the resource usage of a container. A threshold of 0 is not watched.
+type AlertRule struct {
+	// Name identifies the rule in alerts. It defaults to a description of the thresholds.
+	Name string
+	// CPUAbove fires when the CPU usage exceeds this percentage, where 100 is one full CPU.
+	CPUAbove float64
+	// MemAbovePct fires when the memory usage, without inactive page cache, exceeds this percentage of the
+	// memory limit. It never fires for containers without a memory limit.
+	MemAbovePct float64
+	// For is how long a threshold must be exceeded without interruption before the alert fires.
+	For time.Duration
+}
+
+// name returns the name of the rule, describing its thresholds when none is set.
+func (r AlertRule) name() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	name := ""
+	if r.CPUAbove > 0 {
+		name = fmt.Sprintf("cpu>%g%%", r.CPUAbove)
+	}
+	if r.MemAbovePct > 0 {
+		if name != "" {
+			name += ","
+		}
+		name += fmt.Sprintf("mem>%g%%", r.MemAbovePct)
+	}
+	if r.For > 0 {
+		name += " for " + r.For.String()
+	}
+	return name
+}
+
+// ResourceAlert is a change of state of an AlertRule for one resource of a container.
+type ResourceAlert struct {
+	ContainerID string
+	Rule        string
+	// Resource is AlertResourceCPU or AlertResourceMemory.
+	Resource  string
+	Value     float64
+	Threshold float64
+	// Firing is true when the threshold has been exceeded for the duration of the rule, and false when the
+	// usage dropped back below the threshold after the alert fired.
+	Firing bool
+	// Since is when the usage first exceeded the threshold.
+	Since time.Time
+	// Time is when the daemon read the stats that changed the state.
+	Time time.Time
+}
+
+func (a ResourceAlert) String() string {
+	state := "resolved"
+	if a.Firing {
+		state = "firing"
+	}
+	return fmt.Sprintf("%s %s: %s %.2f%% (threshold %.2f%%) on %s", a.Rule, state, a.Resource, a.Value, a.Threshold, a.ContainerID)
+}
+
+// alertState tracks one threshold of a rule.
+type alertState struct {
+	rule      AlertRule
+	resource  string
+	threshold float64
+	since     time.Time
+	firing    bool
+}
+
+// alertEvaluator turns a stream of stats into alerts.
+type alertEvaluator struct {
+	containerID string
+	states      []*alertState
+}
+
+func newAlertEvaluator(containerID string, rules []AlertRule) (*alertEvaluator, error) {
+	if len(rules) == 0 {
+		return nil, &errdefs.ValidationError{Field: "rules", Message: "at least one alert rule is required"}
+	}
+	e := &alertEvaluator{containerID: containerID}
+	for _, rule := range rules {
+		if rule.CPUAbove < 0 || rule.MemAbovePct < 0 || rule.For < 0 {
+			return nil, &errdefs.ValidationError{Field: "rules", Message: fmt.Sprintf("alert rule %q has a negative threshold or duration", rule.name())}
+		}
+		if rule.CPUAbove == 0 && rule.MemAbovePct == 0 {
+			return nil, &errdefs.ValidationError{Field: "rules", Message: fmt.Sprintf("alert rule %q sets no threshold", rule.name())}
+		}
+		if rule.CPUAbove > 0 {
+			e.states = append(e.states, &alertState{rule: rule, resource: AlertResourceCPU, threshold: rule.CPUAbove})
+		}
+		if rule.MemAbovePct > 0 {
+			e.states = append(e.states, &alertState{rule: rule, resource: AlertResourceMemory, threshold: rule.MemAbovePct})
+		}
+	}
+	return e, nil
+}
+
+// observe evaluates the rules against stats and returns the alerts whose state changed.
+func (e *alertEvaluator) observe(stats ContainerStats) []ResourceAlert {
+	var alerts []ResourceAlert
+	for _, state := range e.states {
+		var value float64
+		switch state.resource {
+		case AlertResourceCPU:
+			// The first stats of a stream have no previous CPU stats to compare with.
+			if stats.PreCPUStats.SystemUsage == 0 {
+				continue
+			}
+			value = stats.CPUPercent()
+		case AlertResourceMemory:
+			value = stats.MemoryPercent()
+		}
+
+		if value <= state.threshold {
+			if state.firing {
+				alerts = append(alerts, e.alert(state, value, stats.Read, false))
+			}
+			state.since = time.Time{}
+			state.firing = false
+			continue
+		}
+		if state.since.IsZero() {
+			state.since = stats.Read
+		}
+		if !state.firing && stats.Read.Sub(state.since) >= state.rule.For {
+			state.firing = true
+			alerts = append(alerts, e.alert(state, value, stats.Read, true))
+		}
+	}
+	return alerts
+}
+
+func (e *alertEvaluator) alert(state *alertState, value float64, at time.Time, firing bool) ResourceAlert {
+	return ResourceAlert{
+		ContainerID: e.containerID,
+		Rule:        state.rule.name(),
+		Resource:    state.resource,
+		Value:       value,
+		Threshold:   state.threshold,
+		Firing:      firing,
+		Since:       state.since,
+		Time:        at,
+	}
+}
+
+/*
+WatchResourceLimits follows the stats of a container and sends an alert when a rule's threshold has been
+exceeded for the rule's duration, and another when the usage drops back below it. Both channels are closed
+when ctx is done, the container stops or reading the stats fails; the error, if any, is sent first.
+
+Usage example:
+
+	alerts, errCh := client.WatchResourceLimits(ctx, app, godock.AlertRule{
+		CPUAbove:    90,
+		MemAbovePct: 80,
+		For:         30 * time.Second,
+	})
+	for alert := range alerts {
+		if alert.Firing && alert.Resource == godock.AlertResourceMemory {
+			client.ContainerRestart(ctx, app)
+		}
+	}
+	if err := <-errCh; err != nil {
+		return err
+	}
+*/
+func (c *Client) WatchResourceLimits(ctx context.Context, containerConfig *container.ContainerConfig, rules ...AlertRule) (<-chan ResourceAlert, <-chan error) {
+	alertCh := make(chan ResourceAlert)
+	errCh := make(chan error, 1)
+	if containerConfig == nil || containerConfig.Id == "" {
+		errCh <- &errdefs.ValidationError{Field: "containerConfig", Message: "container config or id cannot be empty"}
+		close(alertCh)
+		close(errCh)
+		return alertCh, errCh
+	}
+	evaluator, err := newAlertEvaluator(containerConfig.Id, rules)
+	if err != nil {
+		errCh <- err
+		close(alertCh)
+		close(errCh)
+		return alertCh, errCh
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	statsCh, statsErrCh := c.ContainerStatsChan(ctx, containerConfig)
+	go func() {
+		defer close(errCh)
+		defer close(alertCh)
+		defer cancel()
+		if statsCh == nil {
+			if err := <-statsErrCh; err != nil {
+				errCh <- &errdefs.ContainerError{ID: containerConfig.Id, Op: "watch resource limits", Message: err.Error(), Err: err}
+			}
+			return
+		}
+		for stats := range statsCh {
+			for _, alert := range evaluator.observe(stats) {
+				select {
+				case alertCh <- alert:
+				case <-ctx.Done():
+					go func() {
+						for range statsCh {
+						}
+					}()
+					return
+				}
+			}
+		}
+		if err, ok := <-statsErrCh; ok && err != nil && ctx.Err() == nil {
+			errCh <- &errdefs.ContainerError{ID: containerConfig.Id, Op: "watch resource limits", Message: err.Error(), Err: err}
+		}
+	}()
+	return alertCh, errCh
+}
@@ -0,0 +1,102 @@
+package godock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var alertStart = time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+
+// alertStats builds stats read at alertStart+second with the given CPU and memory percentages.
+func alertStats(second int, cpu, mem float64) ContainerStats {
+	return ContainerStats{
+		Read: alertStart.Add(time.Duration(second) * time.Second),
+		CpuStats: CPUStats{
+			CPUUsage:    CPUUsage{TotalUsage: uint64(second)*1000 + uint64(cpu*10)},
+			SystemUsage: uint64(second)*1000 + 1000,
+			OnlineCPUs:  1,
+		},
+		PreCPUStats: CPUStats{
+			CPUUsage:    CPUUsage{TotalUsage: uint64(second) * 1000},
+			SystemUsage: uint64(second) * 1000,
+		},
+		MemoryStats: MemoryStats{Usage: uint64(mem * 10), Limit: 1000},
+	}
+}
+
+func TestContainerStatsPercentages(t *testing.T) {
+	stats := alertStats(1, 45, 50)
+	assert.InDelta(t, 45.0, stats.CPUPercent(), 0.001)
+	assert.InDelta(t, 50.0, stats.MemoryPercent(), 0.001)
+
+	stats.MemoryStats.Stats = map[string]uint64{"inactive_file": 100}
+	assert.Equal(t, uint64(400), stats.MemoryUsageNoCache())
+	assert.InDelta(t, 40.0, stats.MemoryPercent(), 0.001)
+
+	stats.MemoryStats.Limit = 0
+	assert.Zero(t, stats.MemoryPercent())
+}
+
+func TestAlertEvaluator(t *testing.T) {
+	e, err := newAlertEvaluator("abc", []AlertRule{{CPUAbove: 90, MemAbovePct: 80, For: 3 * time.Second}})
+	require.NoError(t, err)
+
+	assert.Empty(t, e.observe(alertStats(0, 95, 10)))
+	assert.Empty(t, e.observe(alertStats(1, 95, 10)))
+	// A dip below the threshold restarts the duration.
+	assert.Empty(t, e.observe(alertStats(2, 50, 10)))
+	assert.Empty(t, e.observe(alertStats(3, 95, 10)))
+	assert.Empty(t, e.observe(alertStats(5, 95, 10)))
+
+	alerts := e.observe(alertStats(6, 96, 85))
+	require.Len(t, alerts, 1)
+	assert.Equal(t, AlertResourceCPU, alerts[0].Resource)
+	assert.True(t, alerts[0].Firing)
+	assert.Equal(t, alertStart.Add(3*time.Second), alerts[0].Since)
+	assert.InDelta(t, 96.0, alerts[0].Value, 0.001)
+	assert.Equal(t, "cpu>90%,mem>80% for 3s", alerts[0].Rule)
+
+	// Firing alerts are not repeated.
+	assert.Empty(t, e.observe(alertStats(7, 97, 85)))
+
+	alerts = e.observe(alertStats(9, 10, 85))
+	require.Len(t, alerts, 2)
+	assert.Equal(t, AlertResourceCPU, alerts[0].Resource)
+	assert.False(t, alerts[0].Firing)
+	assert.Equal(t, AlertResourceMemory, alerts[1].Resource)
+	assert.True(t, alerts[1].Firing)
+}
+
+func TestAlertEvaluatorImmediate(t *testing.T) {
+	e, err := newAlertEvaluator("abc", []AlertRule{{Name: "oom-risk", MemAbovePct: 90}})
+	require.NoError(t, err)
+	alerts := e.observe(alertStats(0, 0, 95))
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "oom-risk", alerts[0].Rule)
+}
+
+func TestWatchResourceLimitsValidation(t *testing.T) {
+	c := &Client{}
+	ctr := container.NewConfig("app")
+	ctr.Id = "abc"
+
+	for _, rules := range [][]AlertRule{
+		nil,
+		{{For: time.Second}},
+		{{CPUAbove: -1}},
+	} {
+		alerts, errCh := c.WatchResourceLimits(context.Background(), ctr, rules...)
+		assert.True(t, errdefs.IsInvalidConfig(<-errCh))
+		_, ok := <-alerts
+		assert.False(t, ok)
+	}
+
+	_, errCh := c.WatchResourceLimits(context.Background(), nil, AlertRule{CPUAbove: 90})
+	assert.True(t, errdefs.IsInvalidConfig(<-errCh))
+}
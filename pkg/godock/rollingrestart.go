@@ -0,0 +1,188 @@
+package godock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/stopoptions"
+	"github.com/docker/docker/api/types"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// waitHealthyInterval is how often WaitHealthy inspects the container.
+const waitHealthyInterval = 500 * time.Millisecond
+
+// DrainFn is called by ContainerRollingRestart once the replacement container is healthy and before the old
+// container is stopped, to move traffic from old to replacement, e.g. by updating a load balancer.
+type DrainFn func(ctx context.Context, old, replacement *container.ContainerConfig) error
+
+type rollingRestartOptions struct {
+	healthTimeout time.Duration
+	stopOptionFns []stopoptions.SetStopOptFn
+}
+
+// RollingRestartOptionFn is a function that configures ContainerRollingRestart.
+type RollingRestartOptionFn func(*rollingRestartOptions)
+
+// WithHealthTimeout sets how long the replacement container may take to become healthy. The default is one minute.
+func WithHealthTimeout(timeout time.Duration) RollingRestartOptionFn {
+	return func(opts *rollingRestartOptions) {
+		if timeout > 0 {
+			opts.healthTimeout = timeout
+		}
+	}
+}
+
+// WithRollingRestartStop sets how the old container is stopped once it has been drained.
+func WithRollingRestartStop(stopOptionFns ...stopoptions.SetStopOptFn) RollingRestartOptionFn {
+	return func(opts *rollingRestartOptions) {
+		opts.stopOptionFns = append(opts.stopOptionFns, stopOptionFns...)
+	}
+}
+
+/*
+ContainerRollingRestart replaces a running container without downtime, as a single-host blue/green restart.
+It starts a replacement container from the same config under a new name, waits for it to be healthy, calls
+drain, then stops and removes the old container and gives the replacement the original name. The container
+config's Id is updated to the replacement. A container without a health check is healthy once it is running.
+
+If the replacement fails to become healthy or drain returns an error, the replacement is removed and the old
+container is left running. drain may be nil, e.g. when both containers share a network alias, through which
+the network's DNS already balances between them.
+
+Both containers run at the same time, so the config must not publish fixed host ports; publish ephemeral
+ports or put a reverse proxy in front of the container.
+
+Usage example:
+
+	err := client.ContainerRollingRestart(ctx, api, func(ctx context.Context, old, replacement *container.ContainerConfig) error {
+		addr, err := client.PortAddress(ctx, replacement, 8080)
+		if err != nil {
+			return err
+		}
+		return proxy.SwitchTo(ctx, addr)
+	}, godock.WithHealthTimeout(2*time.Minute))
+*/
+func (c *Client) ContainerRollingRestart(ctx context.Context, containerConfig *container.ContainerConfig, drain DrainFn, rollingRestartOptionFns ...RollingRestartOptionFn) error {
+	if containerConfig == nil || containerConfig.Id == "" || containerConfig.Options == nil {
+		return &errdefs.ValidationError{Field: "containerConfig", Message: "container config, options or id cannot be empty"}
+	}
+	opts := &rollingRestartOptions{healthTimeout: time.Minute}
+	for _, fn := range rollingRestartOptionFns {
+		if fn != nil {
+			fn(opts)
+		}
+	}
+
+	old := &container.ContainerConfig{Id: containerConfig.Id, Name: containerConfig.Name}
+	inspect, err := c.wrapped.ContainerInspect(ctx, old.Id)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return &errdefs.ResourceNotFoundError{ResourceType: "container", ID: old.Id, Err: err}
+		}
+		return &errdefs.ContainerError{ID: old.Name, Op: "rolling restart", Message: err.Error(), Err: err}
+	}
+	if old.Name == "" {
+		old.Name = strings.TrimPrefix(inspect.Name, "/")
+	}
+
+	replacement := containerConfig.Clone()
+	replacement.Id = ""
+	replacement.Name = old.Name + "-" + GenerateRandomString(8)
+	if err := c.ContainerCreate(ctx, replacement); err != nil {
+		return err
+	}
+	discard := func(err error) error {
+		cleanupErr := c.wrapped.ContainerRemove(context.WithoutCancel(ctx), replacement.Id, containerType.RemoveOptions{Force: true})
+		if cleanupErr != nil && !client.IsErrNotFound(cleanupErr) {
+			return errors.Join(err, &errdefs.ContainerError{ID: replacement.Name, Op: "remove", Message: cleanupErr.Error()})
+		}
+		return err
+	}
+	if err := c.ContainerStart(ctx, replacement); err != nil {
+		return discard(err)
+	}
+	if err := c.WaitHealthy(ctx, replacement, opts.healthTimeout); err != nil {
+		return discard(err)
+	}
+	if drain != nil {
+		if err := drain(ctx, old, replacement); err != nil {
+			return discard(&errdefs.ContainerError{ID: old.Name, Op: "drain", Message: err.Error(), Err: err})
+		}
+	}
+
+	// The replacement serves now, so the config follows it even if the old container cannot be cleaned up.
+	containerConfig.Id = replacement.Id
+	if err := c.ContainerStop(ctx, old, opts.stopOptionFns...); err != nil && !client.IsErrNotFound(err) {
+		return &errdefs.ContainerError{ID: old.Name, Op: "stop", Message: err.Error(), Err: err}
+	}
+	if err := c.wrapped.ContainerRemove(ctx, old.Id, containerType.RemoveOptions{Force: true}); err != nil && !client.IsErrNotFound(err) {
+		return &errdefs.ContainerError{ID: old.Name, Op: "remove", Message: err.Error(), Err: err}
+	}
+	if err := c.wrapped.ContainerRename(ctx, replacement.Id, old.Name); err != nil {
+		return &errdefs.ContainerError{ID: replacement.Name, Op: "rename", Message: err.Error(), Err: err}
+	}
+	return nil
+}
+
+/*
+WaitHealthy waits until a started container is healthy, or running when it has no health check. It returns a
+ContainerError when the container stops or becomes unhealthy, and an error wrapping errdefs.ErrTimeout when it
+is not healthy within timeout.
+
+Usage example:
+
+	if err := client.ContainerStart(ctx, api); err != nil {
+		return err
+	}
+	if err := client.WaitHealthy(ctx, api, time.Minute); err != nil {
+		return err
+	}
+*/
+func (c *Client) WaitHealthy(ctx context.Context, containerConfig *container.ContainerConfig, timeout time.Duration) error {
+	if containerConfig == nil || containerConfig.Id == "" {
+		return &errdefs.ValidationError{Field: "containerConfig", Message: "container config or id cannot be empty"}
+	}
+	deadline := c.Clock().Now().Add(timeout)
+	for {
+		inspect, err := c.wrapped.ContainerInspect(ctx, containerConfig.Id)
+		if err != nil {
+			return &errdefs.ContainerError{ID: containerConfig.Name, Op: "wait healthy", Message: err.Error(), Err: err}
+		}
+		state := inspect.State
+		if state == nil || (!state.Running && !state.Restarting) {
+			return &errdefs.ContainerError{ID: containerConfig.Name, Op: "wait healthy", Message: "container is not running"}
+		}
+		switch {
+		case state.Health == nil || state.Health.Status == types.NoHealthcheck:
+			if state.Running {
+				return nil
+			}
+		case state.Health.Status == types.Healthy:
+			return nil
+		case state.Health.Status == types.Unhealthy:
+			return &errdefs.ContainerError{ID: containerConfig.Name, Op: "wait healthy", Message: "container is unhealthy" + lastHealthOutput(state.Health)}
+		}
+
+		if !c.Clock().Now().Before(deadline) {
+			return fmt.Errorf("%w: %s did not become healthy within %s", errdefs.ErrTimeout, containerConfig.Name, timeout)
+		}
+		if err := c.Clock().Sleep(ctx, waitHealthyInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// lastHealthOutput returns the output of the last health check, for error messages.
+func lastHealthOutput(health *types.Health) string {
+	if len(health.Log) == 0 {
+		return ""
+	}
+	return ": " + health.Log[len(health.Log)-1].Output
+}
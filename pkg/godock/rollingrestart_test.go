@@ -0,0 +1,63 @@
+package godock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerRollingRestartValidation(t *testing.T) {
+	c := &Client{}
+	err := c.ContainerRollingRestart(context.Background(), nil, nil)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	err = c.ContainerRollingRestart(context.Background(), container.NewConfig("api"), nil)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
+
+func TestContainerRollingRestart(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+	client := setupTestClient(t)
+	ctx := context.Background()
+	busybox := image.NewConfig(DefaultDoctorProbeImage)
+	require.NoError(t, client.EnsureImage(ctx, busybox, PullPolicyIfNotPresent))
+
+	name := "godock-rolling-" + GenerateRandomString(8)
+	api := container.NewConfig(name)
+	api.SetContainerOptions(
+		containeroptions.Image(busybox),
+		containeroptions.CMD("sleep", "300"),
+	)
+	require.NoError(t, client.ContainerCreate(ctx, api))
+	oldID := api.Id
+	defer client.ContainerRemove(context.WithoutCancel(ctx), &container.ContainerConfig{Id: oldID}, removeoptions.Force())
+	require.NoError(t, client.ContainerStart(ctx, api))
+
+	var drained bool
+	err := client.ContainerRollingRestart(ctx, api, func(ctx context.Context, old, replacement *container.ContainerConfig) error {
+		drained = true
+		assert.Equal(t, oldID, old.Id)
+		running, err := client.IsContainerRunning(ctx, replacement)
+		require.NoError(t, err)
+		assert.True(t, running)
+		return nil
+	})
+	defer client.ContainerRemove(context.WithoutCancel(ctx), api, removeoptions.Force())
+	require.NoError(t, err)
+	assert.True(t, drained)
+	assert.NotEqual(t, oldID, api.Id)
+
+	inspect, err := client.ContainerInspect(ctx, api)
+	require.NoError(t, err)
+	assert.Equal(t, "/"+name, inspect.Name)
+	_, err = client.ContainerInspect(ctx, &container.ContainerConfig{Id: oldID})
+	assert.Error(t, err)
+}
@@ -0,0 +1,108 @@
+package godock
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// logTailGrace is how long a failed run waits for the rest of the output of its container.
+const logTailGrace = 2 * time.Second
+
+// logTail is a writer keeping the last lines written to it.
+type logTail struct {
+	mu      sync.Mutex
+	n       int
+	lines   []string
+	partial []byte
+}
+
+func (t *logTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.partial = append(t.partial, p...)
+	for {
+		i := bytes.IndexByte(t.partial, '\n')
+		if i < 0 {
+			break
+		}
+		t.add(strings.TrimRight(string(t.partial[:i]), "\r"))
+		t.partial = t.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// add keeps line, dropping the oldest line beyond n. The lock must be held.
+func (t *logTail) add(line string) {
+	t.lines = append(t.lines, line)
+	if len(t.lines) > t.n {
+		t.lines = t.lines[len(t.lines)-t.n:]
+	}
+}
+
+// Lines returns the last lines, including an unterminated last line.
+func (t *logTail) Lines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lines := t.lines
+	if len(t.partial) > 0 {
+		lines = append(lines[:len(lines):len(lines)], string(t.partial))
+		if len(lines) > t.n {
+			lines = lines[len(lines)-t.n:]
+		}
+	}
+	return append([]string(nil), lines...)
+}
+
+// attachLogTail attaches to a created container to keep the last n lines of its output. Attaching before the
+// container starts captures all of its output, even when it is removed as soon as it exits. tail waits briefly for
+// the output to end and returns the last lines, stop detaches right away. Both return nothing when attaching
+// failed, since the tail only adds to the error of a failed run.
+func (c *Client) attachLogTail(ctx context.Context, containerConfig *container.ContainerConfig, n int) (tail func() []string, stop func()) {
+	hijack, err := c.wrapped.ContainerAttach(ctx, containerConfig.Id, containerType.AttachOptions{
+		Stream: true,
+		Stdout: true,
+		Stderr: true,
+	})
+	if err != nil {
+		return func() []string { return nil }, func() {}
+	}
+	lines := &logTail{n: n}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if containerConfig.Options != nil && containerConfig.Options.Tty {
+			// The output of a container with a TTY is not multiplexed.
+			io.Copy(lines, hijack.Reader)
+		} else {
+			stdcopy.StdCopy(lines, lines, hijack.Reader)
+		}
+	}()
+
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			hijack.Close()
+			<-done
+		})
+	}
+	tail = func() []string {
+		select {
+		case <-done:
+		case <-c.Clock().After(logTailGrace):
+		case <-ctx.Done():
+		}
+		stop()
+		return lines.Lines()
+	}
+	return tail, stop
+}
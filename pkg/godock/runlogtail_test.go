@@ -0,0 +1,69 @@
+package godock
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/runoptions"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogTail(t *testing.T) {
+	tail := &logTail{n: 2}
+	io.WriteString(tail, "one\ntwo\r\nthr")
+	assert.Equal(t, []string{"two", "thr"}, tail.Lines(), "an unterminated line counts")
+	io.WriteString(tail, "ee\nfour\n")
+	assert.Equal(t, []string{"three", "four"}, tail.Lines())
+}
+
+func TestRunAndWaitFailureLogTail(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1.45/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"Id":"job"}`)
+	})
+	mux.HandleFunc("POST /v1.45/containers/{id}/attach", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1", r.URL.Query().Get("stream"))
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		buf.WriteString("HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.multiplexed-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+		stdout := stdcopy.NewStdWriter(buf, stdcopy.Stdout)
+		stderr := stdcopy.NewStdWriter(buf, stdcopy.Stderr)
+		for i := 1; i <= 4; i++ {
+			fmt.Fprintf(stdout, "applying migration %d\n", i)
+		}
+		io.WriteString(stderr, "migration 4 failed: relation \"users\" already exists\n")
+		buf.Flush()
+	})
+	mux.HandleFunc("POST /v1.45/containers/{id}/start", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /v1.45/containers/{id}/wait", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"StatusCode":1}`)
+	})
+	mux.HandleFunc("GET /v1.45/containers/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		// The container was removed as soon as it exited.
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"message":"No such container: job"}`)
+	})
+	c := newFakeDaemonClient(t, mux)
+
+	job := container.NewConfig("migrations")
+	job.Options.Image = "app"
+	err := c.RunAndWait(context.Background(), job, runoptions.WithFailureLogTail(2))
+	var ce *errdefs.ContainerError
+	require.ErrorAs(t, err, &ce)
+	assert.Equal(t, "exited with code 1", ce.Message)
+	assert.Equal(t, []string{
+		"applying migration 4",
+		`migration 4 failed: relation "users" already exists`,
+	}, ce.Logs)
+	assert.Contains(t, errdefs.Render(err), "\n  Logs:\n    applying migration 4\n")
+}
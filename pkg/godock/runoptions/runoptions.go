@@ -0,0 +1,96 @@
+package runoptions
+
+import "time"
+
+// DefaultCleanupTimeout is how long the cleanup after a cancelled run may take by default.
+const DefaultCleanupTimeout = 30 * time.Second
+
+// RunOptions configures how Client.RunAndWait cleans up a container when its context is done, and what it reports
+// when the container fails.
+type RunOptions struct {
+	// KillOnCancel kills the container with SIGKILL when the context is done before it exits.
+	KillOnCancel bool
+	// RemoveOnCancel forcibly removes the container, killing it if it is still running,
+	// when the context is done before it exits.
+	RemoveOnCancel bool
+	// CleanupTimeout limits the cleanup, which runs on a context detached from the cancelled one.
+	CleanupTimeout time.Duration
+	// FailureLogTail is the number of last output lines included in the error of a failed run, none when zero.
+	FailureLogTail int
+}
+
+// SetRunOptFn is a function type that configures options for Client.RunAndWait.
+type SetRunOptFn func(options *RunOptions)
+
+// NewRunOptions returns RunOptions with the default cleanup timeout.
+func NewRunOptions() *RunOptions {
+	return &RunOptions{
+		CleanupTimeout: DefaultCleanupTimeout,
+	}
+}
+
+/*
+KillOnCancel kills the container when the context is cancelled or its deadline passes before the
+container exits, so it does not keep running after the caller gave up on it. The container is kept
+and can still be inspected.
+
+Usage example:
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	err := client.RunAndWait(ctx, job,
+		runoptions.KillOnCancel(),
+	)
+*/
+func KillOnCancel() SetRunOptFn {
+	return func(options *RunOptions) {
+		options.KillOnCancel = true
+	}
+}
+
+/*
+RemoveOnCancel forcibly removes the container when the context is cancelled or its deadline passes
+before the container exits, killing it if it is still running.
+
+Usage example:
+
+	err := client.RunAndWait(ctx, job,
+		runoptions.RemoveOnCancel(),
+	)
+*/
+func RemoveOnCancel() SetRunOptFn {
+	return func(options *RunOptions) {
+		options.RemoveOnCancel = true
+	}
+}
+
+// CleanupTimeout sets how long killing or removing a cancelled container may take. The default is
+// DefaultCleanupTimeout.
+func CleanupTimeout(timeout time.Duration) SetRunOptFn {
+	return func(options *RunOptions) {
+		if timeout > 0 {
+			options.CleanupTimeout = timeout
+		}
+	}
+}
+
+/*
+WithFailureLogTail includes the last n lines of output of the container in the ContainerError returned when it
+exits with a non-zero code, e.g. to debug CI failures. The output is captured while the container runs, so it is
+available even when the container is removed as soon as it exits.
+
+Usage example:
+
+	err := client.RunAndWait(ctx, migrations, runoptions.WithFailureLogTail(50))
+	var ce *errdefs.ContainerError
+	if errors.As(err, &ce) {
+		fmt.Println(strings.Join(ce.Logs, "\n"))
+	}
+*/
+func WithFailureLogTail(n int) SetRunOptFn {
+	return func(options *RunOptions) {
+		if n > 0 {
+			options.FailureLogTail = n
+		}
+	}
+}
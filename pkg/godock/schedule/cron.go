@@ -0,0 +1,227 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+)
+
+// Schedule returns the activation times of a job.
+type Schedule interface {
+	// Next returns the first activation time after t, or the zero time when there is none.
+	Next(t time.Time) time.Time
+}
+
+// cronSchedule is a parsed five field cron expression. Each field is a bit set of the allowed values.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record an unrestricted day field, since cron matches either day field
+	// when both are restricted.
+	domStar, dowStar bool
+	location         *time.Location
+}
+
+// everySchedule activates at a fixed interval.
+type everySchedule struct {
+	interval time.Duration
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Truncate(time.Second).Add(s.interval)
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a cron expression in the local time zone. It accepts the five standard fields, minute, hour,
+// day of month, month and day of week, each a "*", a value, a range "1-5", a list "1,15" or a step "*/15",
+// with month and weekday names such as "jan" and "mon". Sunday is 0 or 7. The macros @yearly, @monthly,
+// @weekly, @daily and @hourly are accepted, as is "@every <duration>" for a fixed interval. A leading
+// "CRON_TZ=<zone>" sets the time zone.
+//
+// Usage example:
+//
+//	nightly, err := schedule.Parse("30 2 * * mon-fri")
+func Parse(expr string) (Schedule, error) {
+	location := time.Local
+	expr = strings.TrimSpace(expr)
+	if rest, ok := strings.CutPrefix(expr, "CRON_TZ="); ok {
+		zone, fields, _ := strings.Cut(rest, " ")
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil, invalidExpr(expr, err.Error())
+		}
+		location = loc
+		expr = strings.TrimSpace(fields)
+	}
+
+	if rest, ok := strings.CutPrefix(expr, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || interval < time.Second {
+			return nil, invalidExpr(expr, "@every needs a duration of at least one second")
+		}
+		return everySchedule{interval: interval}, nil
+	}
+	if macro, ok := cronMacros[expr]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, invalidExpr(expr, fmt.Sprintf("expected 5 fields, got %d", len(fields)))
+	}
+	s := &cronSchedule{location: location}
+	var err error
+	if s.minute, err = parseField(fields[0], minuteField); err != nil {
+		return nil, invalidExpr(expr, err.Error())
+	}
+	if s.hour, err = parseField(fields[1], hourField); err != nil {
+		return nil, invalidExpr(expr, err.Error())
+	}
+	if s.dom, err = parseField(fields[2], domField); err != nil {
+		return nil, invalidExpr(expr, err.Error())
+	}
+	if s.month, err = parseField(fields[3], monthField); err != nil {
+		return nil, invalidExpr(expr, err.Error())
+	}
+	if s.dow, err = parseField(fields[4], dowField); err != nil {
+		return nil, invalidExpr(expr, err.Error())
+	}
+	// Sunday may be written as 7.
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domStar = fields[2] == "*" || fields[2] == "?"
+	s.dowStar = fields[4] == "*" || fields[4] == "?"
+	return s, nil
+}
+
+func invalidExpr(expr, message string) error {
+	return &errdefs.ValidationError{Field: "schedule", Message: fmt.Sprintf("invalid cron expression %q: %s", expr, message)}
+}
+
+// parseField parses a comma separated cron field into a bit set.
+func parseField(field string, spec cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepPart, spec.name)
+			}
+		}
+
+		var low, high int
+		switch {
+		case rangePart == "*" || rangePart == "?":
+			low, high = spec.min, spec.max
+		default:
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = spec.value(lowPart); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = spec.value(highPart); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// "5/15" means every 15 starting at 5.
+				high = spec.max
+			}
+		}
+		if low > high {
+			return 0, fmt.Errorf("range %d-%d in %s field is reversed", low, high, spec.name)
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value parses a single value of the field, by number or name.
+func (spec cronField) value(s string) (int, error) {
+	if v, ok := spec.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s field", s, spec.name)
+	}
+	if v < spec.min || v > spec.max {
+		return 0, fmt.Errorf("value %d in %s field is out of range %d-%d", v, spec.name, spec.min, spec.max)
+	}
+	return v, nil
+}
+
+// Next returns the first minute after t matching the schedule, searching up to five years ahead.
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	original := t.Location()
+	t = t.In(s.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t.In(original)
+	}
+	return time.Time{}
+}
+
+// dayMatches applies the cron rule that a day matches either restricted day field.
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
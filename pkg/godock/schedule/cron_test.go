@@ -0,0 +1,77 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	start := time.Date(2026, time.January, 30, 10, 17, 42, 0, time.UTC)
+	tests := []struct {
+		expr string
+		next []time.Time
+	}{
+		{"CRON_TZ=UTC */15 * * * *", []time.Time{
+			time.Date(2026, time.January, 30, 10, 30, 0, 0, time.UTC),
+			time.Date(2026, time.January, 30, 10, 45, 0, 0, time.UTC),
+		}},
+		{"CRON_TZ=UTC 30 2 * * mon-fri", []time.Time{
+			time.Date(2026, time.February, 2, 2, 30, 0, 0, time.UTC),
+			time.Date(2026, time.February, 3, 2, 30, 0, 0, time.UTC),
+		}},
+		{"CRON_TZ=UTC 0 0 31 * *", []time.Time{
+			time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC),
+			time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC),
+		}},
+		// Both day fields restricted: either may match.
+		{"CRON_TZ=UTC 0 12 1 * 7", []time.Time{
+			time.Date(2026, time.February, 1, 12, 0, 0, 0, time.UTC),
+			time.Date(2026, time.February, 8, 12, 0, 0, 0, time.UTC),
+		}},
+		{"CRON_TZ=UTC @monthly", []time.Time{
+			time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+		}},
+		{"@every 90s", []time.Time{
+			start.Add(90 * time.Second),
+			start.Add(180 * time.Second),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			sched, err := Parse(tt.expr)
+			require.NoError(t, err)
+			at := start
+			for _, want := range tt.next {
+				at = sched.Next(at)
+				assert.Equal(t, want, at)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 5-2 * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"@every 10ms",
+		"CRON_TZ=Nowhere/City * * * * *",
+	} {
+		_, err := Parse(expr)
+		assert.True(t, errdefs.IsInvalidConfig(err), expr)
+	}
+}
+
+func TestNextFebruary30(t *testing.T) {
+	sched, err := Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, sched.Next(time.Now()).IsZero())
+}
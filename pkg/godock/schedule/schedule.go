@@ -0,0 +1,384 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/aptd3v/godock/pkg/godock/runoptions"
+)
+
+// LabelJob is the label set on every container run by the scheduler, with the job name.
+const LabelJob = "godock.schedule.job"
+
+// DefaultHistoryLimit is the number of runs kept per job by default.
+const DefaultHistoryLimit = 20
+
+// OverlapPolicy decides what happens when a job is due while its previous run is still running.
+type OverlapPolicy string
+
+const (
+	// OverlapSkip skips the run and records it as skipped. It is the default.
+	OverlapSkip OverlapPolicy = "skip"
+	// OverlapAllow starts the run next to the previous one.
+	OverlapAllow OverlapPolicy = "allow"
+	// OverlapReplace cancels the previous run, which removes its container, and starts the new one.
+	OverlapReplace OverlapPolicy = "replace"
+)
+
+/*
+Job is a container run on a schedule. Every run creates a new container from Container, named after the job
+and the scheduled time, and runs it to completion with Client.RunAndWait.
+
+Usage example:
+
+	backup := container.NewConfig("pg-backup")
+	backup.SetContainerOptions(
+		containeroptions.Image(image.NewConfig("postgres:16")),
+		containeroptions.CMD("sh", "-c", "pg_dump -h db app > /backups/app-$(date +%F).sql"),
+	)
+	err := scheduler.Add(&schedule.Job{
+		Name:      "pg-backup",
+		Schedule:  "0 3 * * *",
+		Container: backup,
+		Jitter:    5 * time.Minute,
+		Timeout:   time.Hour,
+		OnFailure: func(ctx context.Context, run schedule.Run) {
+			alert.Send(ctx, "backup failed: "+run.Err.Error())
+		},
+	})
+*/
+type Job struct {
+	Name string
+	// Schedule is a cron expression accepted by Parse.
+	Schedule  string
+	Container *container.ContainerConfig
+	Overlap   OverlapPolicy
+	// Jitter delays every run by a random duration up to Jitter, so jobs scheduled at the same time do not
+	// all start at once.
+	Jitter time.Duration
+	// Timeout limits a run; a run that exceeds it is killed and removed. Zero means no limit.
+	Timeout time.Duration
+	// KeepContainers keeps the containers of finished runs, e.g. to read their logs. By default they are
+	// removed once the run has been recorded.
+	KeepContainers bool
+	// OnFailure is called after a run fails, with the recorded run.
+	OnFailure func(ctx context.Context, run Run)
+}
+
+// Run is a recorded run of a job.
+type Run struct {
+	Job string
+	// ContainerID is empty for skipped runs and runs whose container could not be created.
+	ContainerID string
+	Scheduled   time.Time
+	Started     time.Time
+	Finished    time.Time
+	// ExitCode is the exit code of the container, or -1 when it did not exit on its own.
+	ExitCode int
+	Skipped  bool
+	Err      error
+}
+
+// jobState is a registered job and its runs.
+type jobState struct {
+	job      *Job
+	schedule Schedule
+	cancel   context.CancelFunc
+	// running holds the cancel functions of the runs in progress.
+	running map[int]context.CancelFunc
+	nextRun int
+	history []Run
+}
+
+/*
+Scheduler runs containers on cron schedules, replacing an external cron calling docker run.
+Jobs can be added and removed while the scheduler runs.
+
+Usage example:
+
+	scheduler := schedule.New(client, schedule.OnRun(func(ctx context.Context, run schedule.Run) {
+		log.Printf("%s finished with exit code %d", run.Job, run.ExitCode)
+	}))
+	if err := scheduler.Add(job); err != nil {
+		return err
+	}
+	err := scheduler.Run(ctx)
+*/
+type Scheduler struct {
+	client       *godock.Client
+	clock        clock.Clock
+	historyLimit int
+	onRun        func(ctx context.Context, run Run)
+	// run runs a container to completion and returns its exit code; replaced in tests.
+	run func(ctx context.Context, cfg *container.ContainerConfig, job *Job) (int, error)
+
+	mu   sync.Mutex
+	jobs map[string]*jobState
+	ctx  context.Context
+	wg   sync.WaitGroup
+}
+
+// SetSchedulerOptFn is a function type that configures a Scheduler.
+type SetSchedulerOptFn func(s *Scheduler)
+
+// New creates a Scheduler running containers through client.
+func New(client *godock.Client, setOptFns ...SetSchedulerOptFn) *Scheduler {
+	s := &Scheduler{
+		client:       client,
+		historyLimit: DefaultHistoryLimit,
+		jobs:         make(map[string]*jobState),
+	}
+	if client != nil {
+		s.clock = client.Clock()
+	} else {
+		s.clock = clock.Real()
+	}
+	s.run = s.runContainer
+	for _, set := range setOptFns {
+		if set != nil {
+			set(s)
+		}
+	}
+	return s
+}
+
+// WithClock sets the clock the scheduler waits on, e.g. a clock.Fake in tests. It defaults to the client's clock.
+func WithClock(c clock.Clock) SetSchedulerOptFn {
+	return func(s *Scheduler) {
+		if c != nil {
+			s.clock = c
+		}
+	}
+}
+
+// WithHistoryLimit sets the number of runs kept per job. The default is DefaultHistoryLimit.
+func WithHistoryLimit(n int) SetSchedulerOptFn {
+	return func(s *Scheduler) {
+		if n > 0 {
+			s.historyLimit = n
+		}
+	}
+}
+
+// OnRun sets a function called after every run of every job, including skipped runs.
+func OnRun(fn func(ctx context.Context, run Run)) SetSchedulerOptFn {
+	return func(s *Scheduler) {
+		s.onRun = fn
+	}
+}
+
+// Add registers a job. It returns a ValidationError when the job is incomplete, its schedule is invalid or
+// a job with the same name is registered.
+func (s *Scheduler) Add(job *Job) error {
+	if job == nil || job.Name == "" {
+		return &errdefs.ValidationError{Field: "job", Message: "job or job name cannot be empty"}
+	}
+	if job.Container == nil || job.Container.Options == nil {
+		return &errdefs.ValidationError{Field: "Container", Message: fmt.Sprintf("job %s has no container config", job.Name)}
+	}
+	switch job.Overlap {
+	case "", OverlapSkip, OverlapAllow, OverlapReplace:
+	default:
+		return &errdefs.ValidationError{Field: "Overlap", Message: "unknown overlap policy " + string(job.Overlap)}
+	}
+	sched, err := Parse(job.Schedule)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[job.Name]; ok {
+		return &errdefs.ValidationError{Field: "Name", Message: fmt.Sprintf("job %s is already registered", job.Name)}
+	}
+	state := &jobState{job: job, schedule: sched, running: make(map[int]context.CancelFunc)}
+	s.jobs[job.Name] = state
+	if s.ctx != nil {
+		s.startLocked(state)
+	}
+	return nil
+}
+
+// Remove unregisters a job. Runs in progress are left to finish.
+func (s *Scheduler) Remove(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state, ok := s.jobs[name]; ok {
+		if state.cancel != nil {
+			state.cancel()
+		}
+		delete(s.jobs, name)
+	}
+}
+
+// History returns the recorded runs of a job, oldest first.
+func (s *Scheduler) History(name string) []Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.jobs[name]
+	if !ok {
+		return nil
+	}
+	return append([]Run(nil), state.history...)
+}
+
+// Next returns the next scheduled time of a job, before jitter, or the zero time when it is not registered.
+func (s *Scheduler) Next(name string) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.jobs[name]
+	if !ok {
+		return time.Time{}
+	}
+	return state.schedule.Next(s.clock.Now())
+}
+
+// Run runs the jobs until ctx is done, then cancels the runs in progress, which removes their containers,
+// waits for them and returns the context error.
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.ctx != nil {
+		s.mu.Unlock()
+		return errors.New("scheduler is already running")
+	}
+	s.ctx = ctx
+	for _, state := range s.jobs {
+		s.startLocked(state)
+	}
+	s.mu.Unlock()
+
+	<-ctx.Done()
+	s.wg.Wait()
+	s.mu.Lock()
+	s.ctx = nil
+	s.mu.Unlock()
+	return ctx.Err()
+}
+
+// startLocked starts the loop of a job. s.mu must be held.
+func (s *Scheduler) startLocked(state *jobState) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	state.cancel = cancel
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.loop(ctx, state)
+	}()
+}
+
+// loop waits for each activation of a job and triggers it.
+func (s *Scheduler) loop(ctx context.Context, state *jobState) {
+	for {
+		scheduled := state.schedule.Next(s.clock.Now())
+		if scheduled.IsZero() {
+			return
+		}
+		wait := scheduled.Sub(s.clock.Now())
+		if state.job.Jitter > 0 {
+			wait += time.Duration(rand.Int63n(int64(state.job.Jitter)))
+		}
+		if err := s.clock.Sleep(ctx, wait); err != nil {
+			return
+		}
+		s.trigger(ctx, state, scheduled)
+	}
+}
+
+// trigger applies the overlap policy and starts a run in the background.
+func (s *Scheduler) trigger(ctx context.Context, state *jobState, scheduled time.Time) {
+	s.mu.Lock()
+	if len(state.running) > 0 {
+		switch state.job.Overlap {
+		case OverlapAllow:
+		case OverlapReplace:
+			for _, cancel := range state.running {
+				cancel()
+			}
+		default:
+			s.mu.Unlock()
+			s.record(ctx, state, Run{Job: state.job.Name, Scheduled: scheduled, ExitCode: -1, Skipped: true})
+			return
+		}
+	}
+	// Runs outlive the job loop so that removing a job lets them finish, but not the scheduler.
+	runCtx, cancel := context.WithCancel(s.ctx)
+	id := state.nextRun
+	state.nextRun++
+	state.running[id] = cancel
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+		run := s.execute(runCtx, state.job, scheduled)
+		s.mu.Lock()
+		delete(state.running, id)
+		s.mu.Unlock()
+		s.record(runCtx, state, run)
+	}()
+}
+
+// execute runs one activation of a job.
+func (s *Scheduler) execute(ctx context.Context, job *Job, scheduled time.Time) Run {
+	run := Run{Job: job.Name, Scheduled: scheduled, Started: s.clock.Now(), ExitCode: -1}
+	cfg := job.Container.Clone()
+	cfg.Id = ""
+	cfg.Name = fmt.Sprintf("%s-%s-%s", job.Name, scheduled.Format("20060102-150405"), godock.GenerateRandomString(4))
+	cfg.SetContainerOptions(containeroptions.Label(LabelJob, job.Name))
+
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+	run.ExitCode, run.Err = s.run(ctx, cfg, job)
+	run.ContainerID = cfg.Id
+	run.Finished = s.clock.Now()
+	return run
+}
+
+// runContainer runs the container of a job to completion and returns its exit code.
+func (s *Scheduler) runContainer(ctx context.Context, cfg *container.ContainerConfig, job *Job) (int, error) {
+	err := s.client.RunAndWait(ctx, cfg, runoptions.RemoveOnCancel())
+	if cfg.Id == "" || ctx.Err() != nil {
+		return -1, err
+	}
+	exitCode, inspectErr := s.client.GetContainerExitCode(context.WithoutCancel(ctx), cfg)
+	if inspectErr != nil {
+		exitCode = -1
+	}
+	if !job.KeepContainers {
+		s.client.ContainerRemove(context.WithoutCancel(ctx), cfg, removeoptions.Force())
+	}
+	return exitCode, err
+}
+
+// record adds a run to the history of its job and reports it. The callbacks get a context that is not
+// cancelled with the run, so they can still report a run cancelled by the scheduler stopping.
+func (s *Scheduler) record(ctx context.Context, state *jobState, run Run) {
+	ctx = context.WithoutCancel(ctx)
+	s.mu.Lock()
+	state.history = append(state.history, run)
+	if len(state.history) > s.historyLimit {
+		state.history = state.history[len(state.history)-s.historyLimit:]
+	}
+	s.mu.Unlock()
+
+	if s.onRun != nil {
+		s.onRun(ctx, run)
+	}
+	if run.Err != nil && state.job.OnFailure != nil {
+		state.job.OnFailure(ctx, run)
+	}
+}
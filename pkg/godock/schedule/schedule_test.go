@@ -0,0 +1,92 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddValidation(t *testing.T) {
+	s := New(nil)
+	assert.True(t, errdefs.IsInvalidConfig(s.Add(nil)))
+	assert.True(t, errdefs.IsInvalidConfig(s.Add(&Job{Name: "report", Schedule: "@daily"})))
+	assert.True(t, errdefs.IsInvalidConfig(s.Add(&Job{Name: "report", Schedule: "daily", Container: container.NewConfig("report")})))
+	assert.True(t, errdefs.IsInvalidConfig(s.Add(&Job{Name: "report", Schedule: "@daily", Container: container.NewConfig("report"), Overlap: "queue"})))
+
+	require.NoError(t, s.Add(&Job{Name: "report", Schedule: "@daily", Container: container.NewConfig("report")}))
+	assert.True(t, errdefs.IsInvalidConfig(s.Add(&Job{Name: "report", Schedule: "@hourly", Container: container.NewConfig("report")})))
+}
+
+func TestScheduler(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, time.March, 1, 8, 0, 30, 0, time.UTC))
+	runs := make(chan Run, 10)
+	failures := make(chan Run, 10)
+	s := New(nil, WithClock(fake), WithHistoryLimit(2), OnRun(func(ctx context.Context, run Run) {
+		runs <- run
+	}))
+
+	release := make(chan error)
+	s.run = func(ctx context.Context, cfg *container.ContainerConfig, job *Job) (int, error) {
+		assert.Equal(t, "report", cfg.Options.Labels[LabelJob])
+		cfg.Id = cfg.Name
+		if err := <-release; err != nil {
+			return 1, err
+		}
+		return 0, nil
+	}
+	require.NoError(t, s.Add(&Job{
+		Name:      "report",
+		Schedule:  "CRON_TZ=UTC * * * * *",
+		Container: container.NewConfig("report"),
+		OnFailure: func(ctx context.Context, run Run) {
+			failures <- run
+		},
+	}))
+	assert.Equal(t, time.Date(2026, time.March, 1, 8, 1, 0, 0, time.UTC), s.Next("report"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer waitCancel()
+
+	// The first run starts at 08:01 and is still running at 08:02, which is skipped.
+	require.NoError(t, fake.BlockUntil(waitCtx, 1))
+	fake.Advance(30 * time.Second)
+	require.NoError(t, fake.BlockUntil(waitCtx, 1))
+	fake.Advance(time.Minute)
+	skipped := <-runs
+	assert.True(t, skipped.Skipped)
+	assert.Equal(t, time.Date(2026, time.March, 1, 8, 2, 0, 0, time.UTC), skipped.Scheduled)
+
+	release <- nil
+	first := <-runs
+	assert.False(t, first.Skipped)
+	assert.Equal(t, 0, first.ExitCode)
+	assert.Contains(t, first.ContainerID, "report-20260301-080100-")
+
+	// A failed run is reported to OnFailure.
+	require.NoError(t, fake.BlockUntil(waitCtx, 1))
+	fake.Advance(time.Minute)
+	release <- errors.New("exited with code 1")
+	failed := <-runs
+	assert.Equal(t, 1, failed.ExitCode)
+	assert.Equal(t, failed, <-failures)
+
+	history := s.History("report")
+	require.Len(t, history, 2)
+	assert.Equal(t, first, history[0])
+	assert.Equal(t, failed, history[1])
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
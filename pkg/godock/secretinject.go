@@ -0,0 +1,93 @@
+package godock
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/secrets"
+	containerType "github.com/docker/docker/api/types/container"
+)
+
+// secretOptions returns the container options to create the container with: a copy of the
+// configured options with the environment secrets added, so they are not stored on the config.
+func secretOptions(containerConfig *container.ContainerConfig) (*containerType.Config, error) {
+	if len(containerConfig.Secrets) == 0 {
+		return containerConfig.Options, nil
+	}
+	options := *containerConfig.Options
+	options.Env = append([]string(nil), options.Env...)
+	for _, secret := range containerConfig.Secrets {
+		if err := secret.Validate(); err != nil {
+			return nil, err
+		}
+		if secret.IsEnv() {
+			options.Env = append(options.Env, secret.Name()+"="+string(secret.Value()))
+		}
+	}
+	return &options, nil
+}
+
+// injectSecretFiles copies the file secrets of a created container into it before it starts,
+// like docker compose does for secrets outside swarm. The files live in the container's writable
+// layer and are deleted with the container.
+func (c *Client) injectSecretFiles(ctx context.Context, containerConfig *container.ContainerConfig) error {
+	var files []*secrets.Secret
+	for _, secret := range containerConfig.Secrets {
+		if !secret.IsEnv() {
+			files = append(files, secret)
+		}
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	dirs := make(map[string]bool)
+	for _, secret := range files {
+		target := strings.TrimPrefix(path.Clean(secret.Target()), "/")
+		for dir := path.Dir(target); dir != "."; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+	for _, dir := range slices.Sorted(maps.Keys(dirs)) {
+		if err := tw.WriteHeader(&tar.Header{Name: dir + "/", Mode: 0o755, Typeflag: tar.TypeDir}); err != nil {
+			return err
+		}
+	}
+	for _, secret := range files {
+		header := &tar.Header{
+			Name:     strings.TrimPrefix(path.Clean(secret.Target()), "/"),
+			Mode:     int64(secret.Mode().Perm()),
+			Size:     int64(len(secret.Value())),
+			Uid:      secret.UID(),
+			Gid:      secret.GID(),
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(secret.Value()); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	if err := c.wrapped.CopyToContainer(ctx, containerConfig.Id, "/", &buf, containerType.CopyToContainerOptions{CopyUIDGID: true}); err != nil {
+		return &errdefs.ContainerError{
+			ID:      containerConfig.Id,
+			Op:      "inject secrets",
+			Message: secrets.Redact(err.Error(), files...),
+		}
+	}
+	return nil
+}
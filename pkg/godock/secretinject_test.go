@@ -0,0 +1,62 @@
+package godock
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/aptd3v/godock/pkg/godock/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretOptions(t *testing.T) {
+	ctr := container.NewConfig("secret-options")
+	ctr.SetContainerOptions(containeroptions.Env("MODE", "prod"))
+	ctr.SetSecrets(secrets.Env("API_TOKEN", "tok-123"), secrets.File("db_password", []byte("hunter2"), 0))
+
+	options, err := secretOptions(ctr)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"MODE=prod", "API_TOKEN=tok-123"}, options.Env)
+	assert.Equal(t, []string{"MODE=prod"}, ctr.Options.Env, "secrets must not be stored on the config")
+
+	ctr.SetSecrets(secrets.File("../escape", []byte("x"), 0))
+	_, err = secretOptions(ctr)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
+
+func TestSecretInjection(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	client := setupTestClient(t)
+	busybox := image.NewConfig(DefaultDoctorProbeImage)
+	require.NoError(t, client.EnsureImage(ctx, busybox, PullPolicyIfNotPresent))
+
+	ctr := container.NewConfig("godock-secrets-" + GenerateRandomString(8))
+	ctr.SetContainerOptions(
+		containeroptions.Image(busybox),
+		containeroptions.CMD("sh", "-c", `cat /run/secrets/db_password; stat -c %a /run/secrets/db_password; echo " $API_TOKEN"`),
+	)
+	ctr.SetSecrets(
+		secrets.File("db_password", []byte("hunter2"), 0o440),
+		secrets.Env("API_TOKEN", "tok-123"),
+	)
+	require.NoError(t, client.RunAndWait(ctx, ctr))
+	defer client.ContainerRemove(ctx, ctr, removeoptions.Force())
+
+	rc, err := client.ContainerLogs(ctx, ctr)
+	require.NoError(t, err)
+	defer rc.Close()
+	var out bytes.Buffer
+	_, err = NewLogCopier(&out, &out).Copy(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2440\n tok-123\n", out.String())
+}
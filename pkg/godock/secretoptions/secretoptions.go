@@ -0,0 +1,42 @@
+package secretoptions
+
+import (
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// SetSecretSpecFn is a function that configures a swarm secret before it is created.
+type SetSecretSpecFn func(spec *swarm.SecretSpec)
+
+// Label sets a label on the secret.
+func Label(key, value string) SetSecretSpecFn {
+	return func(spec *swarm.SecretSpec) {
+		if spec.Labels == nil {
+			spec.Labels = make(map[string]string)
+		}
+		spec.Labels[key] = value
+	}
+}
+
+/*
+Driver fetches the secret value from an external secret store through a secrets driver plugin,
+instead of the data passed to SecretCreate.
+
+Usage example:
+
+	id, err := client.SecretCreate(ctx, "db_password", nil,
+		secretoptions.Driver("vault", map[string]string{"path": "secret/db"}),
+	)
+*/
+func Driver(name string, options map[string]string) SetSecretSpecFn {
+	return func(spec *swarm.SecretSpec) {
+		spec.Driver = &swarm.Driver{Name: name, Options: options}
+	}
+}
+
+// GoTemplate evaluates the secret data as a Go template when the secret is mounted into a task,
+// e.g. to include {{ .Service.Name }}.
+func GoTemplate() SetSecretSpecFn {
+	return func(spec *swarm.SecretSpec) {
+		spec.Templating = &swarm.Driver{Name: "golang"}
+	}
+}
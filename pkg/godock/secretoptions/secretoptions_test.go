@@ -0,0 +1,19 @@
+package secretoptions
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretSpec(t *testing.T) {
+	spec := &swarm.SecretSpec{}
+	Label("app", "billing")(spec)
+	Driver("vault", map[string]string{"path": "secret/db"})(spec)
+	GoTemplate()(spec)
+
+	assert.Equal(t, map[string]string{"app": "billing"}, spec.Labels)
+	assert.Equal(t, &swarm.Driver{Name: "vault", Options: map[string]string{"path": "secret/db"}}, spec.Driver)
+	assert.Equal(t, "golang", spec.Templating.Name)
+}
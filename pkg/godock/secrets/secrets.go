@@ -0,0 +1,176 @@
+// Package secrets injects credentials into standalone containers, as files or environment variables,
+// without swarm. Secret values are redacted whenever a Secret is printed, formatted or marshaled.
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+)
+
+// DefaultDir is the directory secret files are written to unless a target is set with At.
+const DefaultDir = "/run/secrets"
+
+// DefaultFileMode is the mode of secret files created with File when mode is 0.
+const DefaultFileMode os.FileMode = 0o400
+
+// redacted replaces secret values in printed output.
+const redacted = "[REDACTED]"
+
+// Secret is a credential injected into a container, either as a file or as an environment variable.
+type Secret struct {
+	name   string
+	value  []byte
+	env    bool
+	target string
+	mode   os.FileMode
+	uid    int
+	gid    int
+}
+
+/*
+File creates a secret written to DefaultDir/name in the container before it starts.
+A mode of 0 uses DefaultFileMode. The file is owned by root unless Owner is set.
+
+Usage example:
+
+	password := secrets.File("db_password", []byte(os.Getenv("DB_PASSWORD")), 0o400)
+	myContainer.SetSecrets(password)
+	myContainer.SetContainerOptions(
+		containeroptions.Env("POSTGRES_PASSWORD_FILE", password.Target()),
+	)
+*/
+func File(name string, content []byte, mode os.FileMode) *Secret {
+	if mode == 0 {
+		mode = DefaultFileMode
+	}
+	return &Secret{
+		name:   name,
+		value:  append([]byte(nil), content...),
+		target: path.Join(DefaultDir, name),
+		mode:   mode,
+	}
+}
+
+/*
+Env creates a secret set as the environment variable name when the container is created.
+Prefer File where the application supports it: environment variables are visible to anyone who can
+inspect the container.
+
+Usage example:
+
+	myContainer.SetSecrets(secrets.Env("API_TOKEN", os.Getenv("API_TOKEN")))
+*/
+func Env(name, value string) *Secret {
+	return &Secret{
+		name:  name,
+		value: []byte(value),
+		env:   true,
+	}
+}
+
+// At sets the absolute path of a file secret in the container. It has no effect on environment secrets.
+func (s *Secret) At(target string) *Secret {
+	if !s.env {
+		s.target = target
+	}
+	return s
+}
+
+// Owner sets the user and group id owning a file secret, for images that do not run as root.
+func (s *Secret) Owner(uid, gid int) *Secret {
+	s.uid = uid
+	s.gid = gid
+	return s
+}
+
+// Name returns the name of the secret.
+func (s *Secret) Name() string {
+	return s.name
+}
+
+// Value returns the secret material.
+func (s *Secret) Value() []byte {
+	return s.value
+}
+
+// IsEnv reports whether the secret is injected as an environment variable.
+func (s *Secret) IsEnv() bool {
+	return s.env
+}
+
+// Target returns the path of a file secret in the container, or "" for environment secrets.
+func (s *Secret) Target() string {
+	return s.target
+}
+
+// Mode returns the file mode of a file secret.
+func (s *Secret) Mode() os.FileMode {
+	return s.mode
+}
+
+// UID returns the user id owning a file secret.
+func (s *Secret) UID() int {
+	return s.uid
+}
+
+// GID returns the group id owning a file secret.
+func (s *Secret) GID() int {
+	return s.gid
+}
+
+// Validate checks the name and target of the secret.
+func (s *Secret) Validate() error {
+	if s.env {
+		if s.name == "" || strings.ContainsAny(s.name, "= \t\n") {
+			return &errdefs.ValidationError{Field: "name", Message: fmt.Sprintf("invalid environment variable name %q", s.name)}
+		}
+		return nil
+	}
+	if s.name == "" || strings.Contains(s.name, "/") || s.name == "." || s.name == ".." {
+		return &errdefs.ValidationError{Field: "name", Message: fmt.Sprintf("invalid secret name %q", s.name)}
+	}
+	if !path.IsAbs(s.target) || path.Clean(s.target) == "/" {
+		return &errdefs.ValidationError{Field: "target", Message: fmt.Sprintf("secret target %q must be an absolute file path", s.target)}
+	}
+	return nil
+}
+
+// String describes the secret without its value.
+func (s *Secret) String() string {
+	if s.env {
+		return fmt.Sprintf("secret %s (env): %s", s.name, redacted)
+	}
+	return fmt.Sprintf("secret %s (%s): %s", s.name, s.target, redacted)
+}
+
+// GoString describes the secret without its value, for %#v.
+func (s *Secret) GoString() string {
+	return s.String()
+}
+
+// MarshalJSON encodes the secret without its value.
+func (s *Secret) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`{"name":%q,"env":%t,"target":%q,"value":%q}`, s.name, s.env, s.target, redacted)), nil
+}
+
+/*
+Redact replaces every occurrence of the secrets' values in text, so container output or errors
+can be logged safely.
+
+Usage example:
+
+	log.Println(secrets.Redact(output, password, token))
+*/
+func Redact(text string, secrets ...*Secret) string {
+	for _, s := range secrets {
+		if s == nil || len(s.value) == 0 {
+			continue
+		}
+		text = strings.ReplaceAll(text, string(s.value), redacted)
+	}
+	return text
+}
@@ -0,0 +1,47 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretRedaction(t *testing.T) {
+	password := File("db_password", []byte("hunter2"), 0)
+	token := Env("API_TOKEN", "tok-123")
+
+	assert.Equal(t, "/run/secrets/db_password", password.Target())
+	assert.Equal(t, DefaultFileMode, password.Mode())
+	assert.Empty(t, token.Target())
+
+	for _, out := range []string{
+		fmt.Sprint(password), fmt.Sprintf("%v %+v %#v %s", token, token, token, password),
+	} {
+		assert.NotContains(t, out, "hunter2")
+		assert.NotContains(t, out, "tok-123")
+		assert.Contains(t, out, "[REDACTED]")
+	}
+	data, err := json.Marshal(map[string]*Secret{"password": password})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+
+	assert.Equal(t, "login [REDACTED] with [REDACTED]", Redact("login hunter2 with tok-123", password, token, nil))
+}
+
+func TestSecretValidate(t *testing.T) {
+	assert.NoError(t, File("cert.pem", []byte("x"), 0o444).At("/etc/ssl/private/cert.pem").Owner(1000, 1000).Validate())
+	assert.NoError(t, Env("TOKEN", "x").Validate())
+
+	for _, secret := range []*Secret{
+		File("", []byte("x"), 0),
+		File("../escape", []byte("x"), 0),
+		File("relative", []byte("x"), 0).At("run/secrets/relative"),
+		Env("BAD=NAME", "x"),
+	} {
+		assert.True(t, errdefs.IsInvalidConfig(secret.Validate()), secret.Name())
+	}
+}
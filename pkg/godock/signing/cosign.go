@@ -0,0 +1,172 @@
+package signing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/imageoptions"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+)
+
+// DefaultCosignImage is the image used to run cosign when none is configured.
+const DefaultCosignImage = "gcr.io/projectsigstore/cosign:v2.4.1"
+
+// CosignSigner signs images by running cosign inside a container,
+// so signing does not require cosign to be installed on the host.
+type CosignSigner struct {
+	client *godock.Client
+
+	// Image is the cosign image to run.
+	Image string
+	// Repository is the repository the built image was pushed to, e.g. "registry.example.com/my-app".
+	// Signatures are attached to Repository@digest.
+	Repository string
+	// KeyDir is the host directory containing the signing key. It is mounted read-only at /keys.
+	KeyDir string
+	// KeyFile is the name of the signing key inside KeyDir.
+	KeyFile string
+	// DockerConfigDir is an optional host directory holding a Docker config.json with registry credentials.
+	// It is mounted read-only at /root/.docker.
+	DockerConfigDir string
+	// Env holds extra environment variables for cosign, such as COSIGN_PASSWORD.
+	Env map[string]string
+	// Annotations are added to the signature.
+	Annotations map[string]string
+	// TlogUpload controls whether the signature is uploaded to the transparency log.
+	TlogUpload bool
+}
+
+// NewCosignSigner creates a CosignSigner that signs with the key keyFile found in keyDir.
+func NewCosignSigner(client *godock.Client, repository, keyDir, keyFile string) *CosignSigner {
+	return &CosignSigner{
+		client:      client,
+		Image:       DefaultCosignImage,
+		Repository:  repository,
+		KeyDir:      keyDir,
+		KeyFile:     keyFile,
+		Env:         make(map[string]string),
+		Annotations: make(map[string]string),
+		TlogUpload:  true,
+	}
+}
+
+/*
+Hook returns the signer as a post-build hook. cosign signs registry references, so the hooks must push
+the image first with imageoptions.PushBeforeHooks, which passes the pushed digest to the signer.
+
+Usage example:
+
+	signer := signing.NewCosignSigner(client, "registry.example.com/my-app", "/secrets", "cosign.key")
+	signer.Env["COSIGN_PASSWORD"] = os.Getenv("COSIGN_PASSWORD")
+	img.SetBuildHooks(
+		imageoptions.PushBeforeHooks(),
+		imageoptions.OnBuilt(signer.Hook()),
+	)
+*/
+func (s *CosignSigner) Hook() imageoptions.BuiltHook {
+	return s.Sign
+}
+
+// Sign signs the image with the given repository digest.
+// The image must have been pushed, since cosign signs registry references; as a post-build hook this
+// requires imageoptions.PushBeforeHooks.
+func (s *CosignSigner) Sign(ctx context.Context, imageID string, digest string) error {
+	if digest == "" {
+		return fmt.Errorf("cannot sign image %s: no repository digest, push the image before signing with imageoptions.PushBeforeHooks", imageID)
+	}
+	if s.Repository == "" {
+		return fmt.Errorf("cannot sign image %s: no repository configured", imageID)
+	}
+
+	args := []string{"sign", "--yes", fmt.Sprintf("--tlog-upload=%t", s.TlogUpload)}
+	if s.KeyFile != "" {
+		args = append(args, "--key", "/keys/"+s.KeyFile)
+	}
+	keys := make([]string, 0, len(s.Annotations))
+	for key := range s.Annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "-a", fmt.Sprintf("%s=%s", key, s.Annotations[key]))
+	}
+	args = append(args, fmt.Sprintf("%s@%s", strings.TrimSuffix(s.Repository, "/"), digest))
+
+	return runCosign(ctx, s.client, cosignRun{
+		image:           s.Image,
+		name:            "cosign-sign-",
+		args:            args,
+		env:             s.Env,
+		keyDir:          s.KeyDir,
+		dockerConfigDir: s.DockerConfigDir,
+	})
+}
+
+// cosignRun is a cosign command and the host directories it needs.
+type cosignRun struct {
+	image           string
+	name            string
+	args            []string
+	env             map[string]string
+	keyDir          string
+	dockerConfigDir string
+}
+
+// runCosign runs cosign in a container and waits for it, returning its output on failure.
+func runCosign(ctx context.Context, client *godock.Client, run cosignRun) error {
+	cosignImage := image.NewConfig(run.image)
+	// The cosign image is not verified by cosign itself, which would need the image first.
+	cosignImage.SetPullChecks(imageoptions.SkipVerification())
+	if err := client.EnsureImage(ctx, cosignImage, godock.PullPolicyIfNotPresent); err != nil {
+		return fmt.Errorf("failed to pull cosign image: %w", err)
+	}
+
+	cosign := container.NewConfig(run.name + godock.GenerateRandomString(8))
+	cosign.SetContainerOptions(
+		containeroptions.Image(cosignImage),
+		containeroptions.CMD(run.args...),
+	)
+	for key, value := range run.env {
+		cosign.SetContainerOptions(containeroptions.Env(key, value))
+	}
+	if run.keyDir != "" {
+		cosign.SetHostOptions(hostoptions.Mount(hostoptions.MountType("bind"), run.keyDir, "/keys", true))
+	}
+	if run.dockerConfigDir != "" {
+		cosign.SetHostOptions(hostoptions.Mount(hostoptions.MountType("bind"), run.dockerConfigDir, "/root/.docker", true))
+	}
+
+	runErr := client.RunAndWait(ctx, cosign)
+	if cosign.Id == "" {
+		return runErr
+	}
+	defer client.ContainerRemove(context.WithoutCancel(ctx), cosign, removeoptions.Force(), removeoptions.RemoveVolumes())
+
+	if runErr != nil {
+		return fmt.Errorf("cosign %s failed: %w%s", run.args[0], runErr, containerOutput(ctx, client, cosign))
+	}
+	return nil
+}
+
+// containerOutput returns the logs of a finished container for inclusion in error messages.
+func containerOutput(ctx context.Context, client *godock.Client, containerConfig *container.ContainerConfig) string {
+	rc, err := client.ContainerLogs(ctx, containerConfig)
+	if err != nil {
+		return ""
+	}
+	defer rc.Close()
+
+	var out bytes.Buffer
+	if _, err := godock.NewLogCopier(&out, nil).Copy(rc); err != nil || out.Len() == 0 {
+		return ""
+	}
+	return ": " + strings.TrimSpace(out.String())
+}
@@ -0,0 +1,118 @@
+package signing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/distribution/reference"
+)
+
+// CosignVerifier verifies image signatures by running cosign verify inside a container. It implements
+// godock.ImageVerifier, and verifies either against a public key or, keyless, against the identity of the
+// certificate the image was signed with.
+type CosignVerifier struct {
+	client *godock.Client
+
+	// Image is the cosign image to run.
+	Image string
+	// KeyDir is the host directory containing the public key. It is mounted read-only at /keys.
+	KeyDir string
+	// KeyFile is the name of the public key inside KeyDir.
+	KeyFile string
+	// CertificateIdentity is the identity the keyless signing certificate must be issued to, e.g. the email
+	// address or workflow URL of the signer.
+	CertificateIdentity string
+	// CertificateOIDCIssuer is the OIDC issuer that must have issued the keyless signing certificate.
+	CertificateOIDCIssuer string
+	// DockerConfigDir is an optional host directory holding a Docker config.json with registry credentials.
+	// It is mounted read-only at /root/.docker.
+	DockerConfigDir string
+	// Env holds extra environment variables for cosign.
+	Env map[string]string
+	// IgnoreTlog skips checking the signature against the transparency log, for signatures made without
+	// uploading to it.
+	IgnoreTlog bool
+}
+
+/*
+NewCosignKeyVerifier creates a CosignVerifier that verifies against the public key keyFile found in keyDir.
+The verifier runs cosign through client, which should not have the verifier itself installed.
+
+Usage example:
+
+	runner, err := godock.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+	verifier := signing.NewCosignKeyVerifier(runner, "/etc/cosign", "cosign.pub")
+	client, err := godock.NewClient(ctx, godock.WithImageVerifiers(verifier))
+*/
+func NewCosignKeyVerifier(client *godock.Client, keyDir, keyFile string) *CosignVerifier {
+	return &CosignVerifier{
+		client:  client,
+		Image:   DefaultCosignImage,
+		KeyDir:  keyDir,
+		KeyFile: keyFile,
+		Env:     make(map[string]string),
+	}
+}
+
+/*
+NewCosignKeylessVerifier creates a CosignVerifier that verifies keyless signatures, whose certificate must be
+issued to identity by issuer. See NewCosignKeyVerifier for the client.
+
+Usage example:
+
+	verifier := signing.NewCosignKeylessVerifier(runner,
+		"https://github.com/example/app/.github/workflows/release.yml@refs/heads/main",
+		"https://token.actions.githubusercontent.com",
+	)
+*/
+func NewCosignKeylessVerifier(client *godock.Client, identity, issuer string) *CosignVerifier {
+	return &CosignVerifier{
+		client:                client,
+		Image:                 DefaultCosignImage,
+		CertificateIdentity:   identity,
+		CertificateOIDCIssuer: issuer,
+		Env:                   make(map[string]string),
+	}
+}
+
+// Verify verifies the signature of the image ref with the given repository digest.
+// cosign verifies registry references, so images without a repository digest, such as local builds, fail.
+func (v *CosignVerifier) Verify(ctx context.Context, ref string, digest string) error {
+	if digest == "" {
+		return fmt.Errorf("cannot verify image %s: no repository digest", ref)
+	}
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return fmt.Errorf("cannot verify image %s: %w", ref, err)
+	}
+
+	args := []string{"verify"}
+	switch {
+	case v.KeyFile != "":
+		args = append(args, "--key", "/keys/"+v.KeyFile)
+	case v.CertificateIdentity != "" && v.CertificateOIDCIssuer != "":
+		args = append(args,
+			"--certificate-identity", v.CertificateIdentity,
+			"--certificate-oidc-issuer", v.CertificateOIDCIssuer,
+		)
+	default:
+		return fmt.Errorf("cannot verify image %s: no key or certificate identity and issuer configured", ref)
+	}
+	if v.IgnoreTlog {
+		args = append(args, "--insecure-ignore-tlog=true")
+	}
+	args = append(args, fmt.Sprintf("%s@%s", named.Name(), digest))
+
+	return runCosign(ctx, v.client, cosignRun{
+		image:           v.Image,
+		name:            "cosign-verify-",
+		args:            args,
+		env:             v.Env,
+		keyDir:          v.KeyDir,
+		dockerConfigDir: v.DockerConfigDir,
+	})
+}
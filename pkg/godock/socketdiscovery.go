@@ -0,0 +1,57 @@
+package godock
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/docker/docker/client"
+)
+
+// defaultDaemonSocket is the socket the docker client connects to when DOCKER_HOST is unset.
+var defaultDaemonSocket = "/var/run/docker.sock"
+
+// daemonSocketCandidates returns the sockets of the common desktop daemons, in the order they are probed.
+func daemonSocketCandidates() []string {
+	var candidates []string
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates,
+			// Docker Desktop
+			filepath.Join(home, ".docker", "run", "docker.sock"),
+			filepath.Join(home, ".docker", "desktop", "docker.sock"),
+			// Colima
+			filepath.Join(home, ".colima", "default", "docker.sock"),
+			filepath.Join(home, ".colima", "docker.sock"),
+			// Rancher Desktop
+			filepath.Join(home, ".rd", "docker.sock"),
+			// OrbStack
+			filepath.Join(home, ".orbstack", "run", "docker.sock"),
+		)
+	}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		// Rootless docker
+		candidates = append(candidates, filepath.Join(runtimeDir, "docker.sock"))
+	}
+	return candidates
+}
+
+// discoverDaemonHost returns the host of the first daemon socket found when DOCKER_HOST is unset and the default
+// socket is missing, as with Colima, Rancher Desktop and recent Docker Desktop releases on macOS. It returns an
+// empty string when the default host should be used.
+func discoverDaemonHost() string {
+	if runtime.GOOS == "windows" || os.Getenv(client.EnvOverrideHost) != "" || isSocket(defaultDaemonSocket) {
+		return ""
+	}
+	for _, candidate := range daemonSocketCandidates() {
+		if isSocket(candidate) {
+			return "unix://" + candidate
+		}
+	}
+	return ""
+}
+
+// isSocket reports whether path is a unix socket.
+func isSocket(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().Type() == os.ModeSocket
+}
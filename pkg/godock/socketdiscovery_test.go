@@ -0,0 +1,53 @@
+package godock
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverDaemonHost(t *testing.T) {
+	// t.TempDir paths can exceed the length limit of unix socket paths.
+	home, err := os.MkdirTemp("", "home")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(home) })
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("DOCKER_HOST", "")
+
+	listen := func(path string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		l, err := net.Listen("unix", path)
+		require.NoError(t, err)
+		t.Cleanup(func() { l.Close() })
+	}
+	origDefault := defaultDaemonSocket
+	t.Cleanup(func() { defaultDaemonSocket = origDefault })
+	defaultDaemonSocket = filepath.Join(home, "var", "run", "docker.sock")
+
+	assert.Empty(t, discoverDaemonHost(), "nothing to discover")
+
+	colima := filepath.Join(home, ".colima", "default", "docker.sock")
+	listen(colima)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".rd"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".rd", "docker.sock"), nil, 0o644))
+	assert.Equal(t, "unix://"+colima, discoverDaemonHost())
+
+	desktop := filepath.Join(home, ".docker", "run", "docker.sock")
+	listen(desktop)
+	assert.Equal(t, "unix://"+desktop, discoverDaemonHost(), "candidates are probed in order")
+
+	t.Run("DOCKER_HOST is set", func(t *testing.T) {
+		t.Setenv("DOCKER_HOST", "tcp://10.0.0.5:2375")
+		assert.Empty(t, discoverDaemonHost())
+	})
+
+	t.Run("Default socket exists", func(t *testing.T) {
+		listen(defaultDaemonSocket)
+		assert.Empty(t, discoverDaemonHost())
+	})
+}
@@ -6,12 +6,16 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"strings"
 	"time"
-
-	"github.com/docker/docker/api/types/container"
 )
 
-type formater struct{ writer io.Writer }
+// StatsEncoder writes container stats in an output format, one call per stats sample.
+type StatsEncoder interface {
+	Encode(stats ContainerStats) error
+}
+
+type formater struct{ encoder StatsEncoder }
 
 func (f *formater) Write(p []byte) (n int, err error) {
 	var data ContainerStats
@@ -20,26 +24,50 @@ func (f *formater) Write(p []byte) (n int, err error) {
 	if err != nil {
 		return 0, err
 	}
-	b, err := json.Marshal(&FormatedContainerStats{
-		CpuUsage:    data.FormatCpuUsagePercentage(),
-		MemoryUsage: data.FormatMemoryUsage(),
-		NetworkIO:   data.FormatNetworkIO(),
-		DiskIO:      data.FormatDiskIO(),
-	})
-	if err != nil {
+	if err := f.encoder.Encode(data); err != nil {
 		return 0, err
 	}
-	if n, err = f.writer.Write(append(b, '\n')); err != nil {
-		return n, err
-	}
 
 	return len(p), nil
 }
 
 // Formats the incoming stats and passes it to the supplied writer
 func StatsFormatter(writer io.Writer) *formater {
-	// container.Stats{}
-	return &formater{writer: writer}
+	return &formater{encoder: &formattedEncoder{writer: writer}}
+}
+
+/*
+StatsFormatterWith decodes the incoming stats and passes them to the supplied encoder, such as
+StatsCSVWriter, StatsJSONLWriter or StatsTableWriter.
+
+Usage example:
+
+	stats, err := client.ContainerStats(ctx, app)
+	if err != nil {
+		return err
+	}
+	defer stats.Close()
+	io.Copy(godock.StatsFormatterWith(godock.StatsCSVWriter(os.Stdout)), stats)
+*/
+func StatsFormatterWith(encoder StatsEncoder) *formater {
+	return &formater{encoder: encoder}
+}
+
+// formattedEncoder writes stats as FormatedContainerStats JSON lines.
+type formattedEncoder struct{ writer io.Writer }
+
+func (e *formattedEncoder) Encode(data ContainerStats) error {
+	b, err := json.Marshal(&FormatedContainerStats{
+		CpuUsage:    data.FormatCpuUsagePercentage(),
+		MemoryUsage: data.FormatMemoryUsage(),
+		NetworkIO:   data.FormatNetworkIO(),
+		DiskIO:      data.FormatDiskIO(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = e.writer.Write(append(b, '\n'))
+	return err
 }
 
 type FormatedContainerStats struct {
@@ -49,20 +77,114 @@ type FormatedContainerStats struct {
 	DiskIO      string `json:"diskIO"`
 }
 
+/*
+ContainerStats is a stats sample of a container, as streamed by the daemon. Every section is a named type,
+so samples can be built and extended in tests and by stats processors.
+
+Usage example:
+
+	stats := godock.ContainerStats{
+		CpuStats: godock.CPUStats{CPUUsage: godock.CPUUsage{TotalUsage: 400}, SystemUsage: 2000, OnlineCPUs: 2},
+		Networks: godock.NetworkStats{
+			"eth0": {RxBytes: 1024, TxBytes: 2048},
+			"eth1": {RxBytes: 512},
+		},
+	}
+	fmt.Println(stats.FormatNetworkIO())
+*/
 type ContainerStats struct {
-	Read         time.Time               `json:"read"`
-	Preread      time.Time               `json:"preread"`
-	PidsStats    container.PidsStats     `json:"pids_stats"`
-	BlkioStats   container.BlkioStats    `json:"blkio_stats"`
-	NumProcs     int64                   `json:"num_procs"`
-	StorageStats container.StorageStats  `json:"storage_stats"`
-	CpuStats     container.CPUStats      `json:"cpu_stats"`
-	PreCPUStats  container.CPUStats      `json:"precpu_stats"`
-	MemoryStats  container.MemoryStats   `json:"memory_stats"`
-	Networks     map[string]NetworkStats `json:"networks"`
-}
-
-type NetworkStats struct {
+	// Name and ID identify the container. The daemon reports the name with a leading slash.
+	Name         string       `json:"name,omitempty"`
+	ID           string       `json:"id,omitempty"`
+	Read         time.Time    `json:"read"`
+	Preread      time.Time    `json:"preread"`
+	PidsStats    PidsStats    `json:"pids_stats"`
+	BlkioStats   BlkioStats   `json:"blkio_stats"`
+	NumProcs     uint32       `json:"num_procs"`
+	StorageStats StorageStats `json:"storage_stats"`
+	CpuStats     CPUStats     `json:"cpu_stats"`
+	PreCPUStats  CPUStats     `json:"precpu_stats"`
+	MemoryStats  MemoryStats  `json:"memory_stats"`
+	Networks     NetworkStats `json:"networks"`
+}
+
+// CPUStats is the CPU usage of a container, cumulative since it started.
+type CPUStats struct {
+	CPUUsage CPUUsage `json:"cpu_usage"`
+	// SystemUsage is the CPU time of the host, in nanoseconds. It is not reported on Windows.
+	SystemUsage    uint64         `json:"system_cpu_usage,omitempty"`
+	OnlineCPUs     uint32         `json:"online_cpus,omitempty"`
+	ThrottlingData ThrottlingData `json:"throttling_data"`
+}
+
+// CPUUsage is the CPU time used by a container, in nanoseconds on Linux and 100ns units on Windows.
+type CPUUsage struct {
+	TotalUsage        uint64   `json:"total_usage"`
+	PercpuUsage       []uint64 `json:"percpu_usage,omitempty"`
+	UsageInKernelmode uint64   `json:"usage_in_kernelmode"`
+	UsageInUsermode   uint64   `json:"usage_in_usermode"`
+}
+
+// ThrottlingData reports how often a container with a CPU limit was throttled.
+type ThrottlingData struct {
+	Periods          uint64 `json:"periods"`
+	ThrottledPeriods uint64 `json:"throttled_periods"`
+	ThrottledTime    uint64 `json:"throttled_time"`
+}
+
+// MemoryStats is the memory usage of a container, in bytes.
+type MemoryStats struct {
+	Usage    uint64 `json:"usage,omitempty"`
+	MaxUsage uint64 `json:"max_usage,omitempty"`
+	// Stats holds the raw counters of the memory cgroup, e.g. "cache" or "inactive_file".
+	Stats   map[string]uint64 `json:"stats,omitempty"`
+	Failcnt uint64            `json:"failcnt,omitempty"`
+	Limit   uint64            `json:"limit,omitempty"`
+	// Commit, CommitPeak and PrivateWorkingSet are only reported on Windows.
+	Commit            uint64 `json:"commitbytes,omitempty"`
+	CommitPeak        uint64 `json:"commitpeakbytes,omitempty"`
+	PrivateWorkingSet uint64 `json:"privateworkingset,omitempty"`
+}
+
+// BlkioStats is the block IO of a container, per device and operation. Most lists are only filled on cgroup v1.
+type BlkioStats struct {
+	IoServiceBytesRecursive []BlkioStatEntry `json:"io_service_bytes_recursive"`
+	IoServicedRecursive     []BlkioStatEntry `json:"io_serviced_recursive"`
+	IoQueuedRecursive       []BlkioStatEntry `json:"io_queue_recursive"`
+	IoServiceTimeRecursive  []BlkioStatEntry `json:"io_service_time_recursive"`
+	IoWaitTimeRecursive     []BlkioStatEntry `json:"io_wait_time_recursive"`
+	IoMergedRecursive       []BlkioStatEntry `json:"io_merged_recursive"`
+	IoTimeRecursive         []BlkioStatEntry `json:"io_time_recursive"`
+	SectorsRecursive        []BlkioStatEntry `json:"sectors_recursive"`
+}
+
+// BlkioStatEntry is a block IO counter of one device, for one operation such as "read" or "write".
+type BlkioStatEntry struct {
+	Major uint64 `json:"major"`
+	Minor uint64 `json:"minor"`
+	Op    string `json:"op"`
+	Value uint64 `json:"value"`
+}
+
+// PidsStats is the number of processes in a container.
+type PidsStats struct {
+	Current uint64 `json:"current,omitempty"`
+	Limit   uint64 `json:"limit,omitempty"`
+}
+
+// StorageStats is the storage IO of a container. It is only reported on Windows.
+type StorageStats struct {
+	ReadCountNormalized  uint64 `json:"read_count_normalized,omitempty"`
+	ReadSizeBytes        uint64 `json:"read_size_bytes,omitempty"`
+	WriteCountNormalized uint64 `json:"write_count_normalized,omitempty"`
+	WriteSizeBytes       uint64 `json:"write_size_bytes,omitempty"`
+}
+
+// NetworkStats is the network IO of a container per interface, keyed by interface name such as "eth0".
+type NetworkStats map[string]InterfaceStats
+
+// InterfaceStats is the network IO of one interface of a container.
+type InterfaceStats struct {
 	RxBytes   uint64 `json:"rx_bytes"`
 	RxDropped uint64 `json:"rx_dropped"`
 	RxErrors  uint64 `json:"rx_errors"`
@@ -73,23 +195,53 @@ type NetworkStats struct {
 	TxPackets uint64 `json:"tx_packets"`
 }
 
-func (stats *ContainerStats) FormatCpuUsagePercentage() string {
-	// Calculate the total CPU time used by the container
-	totalCPUUsage := float64(stats.CpuStats.CPUUsage.TotalUsage - stats.PreCPUStats.CPUUsage.TotalUsage)
+// CPUPercent returns the CPU usage since the previous stats sent by the daemon, where 100 is one full CPU,
+// computed like docker stats does. It is 0 for the first stats of a stream.
+func (stats *ContainerStats) CPUPercent() float64 {
+	cur, pre := stats.CpuStats, stats.PreCPUStats
+	if cur.SystemUsage <= pre.SystemUsage || cur.CPUUsage.TotalUsage < pre.CPUUsage.TotalUsage {
+		return 0
+	}
+	onlineCPUs := float64(cur.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(cur.CPUUsage.PercpuUsage))
+	}
+	cpuDelta := float64(cur.CPUUsage.TotalUsage - pre.CPUUsage.TotalUsage)
+	systemDelta := float64(cur.SystemUsage - pre.SystemUsage)
+	return cpuDelta / systemDelta * onlineCPUs * 100.0
+}
 
-	// Calculate the system CPU time
-	systemCPUUsage := float64(stats.CpuStats.SystemUsage - stats.PreCPUStats.SystemUsage)
+// MemoryUsageNoCache returns the memory usage without the inactive page cache, which the kernel reclaims
+// before it hits the limit, like docker stats does.
+func (stats *ContainerStats) MemoryUsageNoCache() uint64 {
+	usage := stats.MemoryStats.Usage
+	// cgroup v1 reports total_inactive_file, cgroup v2 inactive_file.
+	inactive, ok := stats.MemoryStats.Stats["total_inactive_file"]
+	if !ok {
+		inactive = stats.MemoryStats.Stats["inactive_file"]
+	}
+	if inactive < usage {
+		return usage - inactive
+	}
+	return usage
+}
 
-	// Calculate the number of online CPUs
-	onlineCPUs := float64(stats.CpuStats.OnlineCPUs)
+// MemoryPercent returns MemoryUsageNoCache as a percentage of the memory limit, or 0 when there is no limit.
+func (stats *ContainerStats) MemoryPercent() float64 {
+	if stats.MemoryStats.Limit == 0 {
+		return 0
+	}
+	return float64(stats.MemoryUsageNoCache()) / float64(stats.MemoryStats.Limit) * 100.0
+}
 
-	// Calculate the CPU usage percentage
-	cpuUsagePercentage := (totalCPUUsage / systemCPUUsage) * onlineCPUs * 100.0
+func (stats *ContainerStats) FormatCpuUsagePercentage() string {
+	cpuUsagePercentage := stats.CPUPercent()
 	if math.IsNaN(cpuUsagePercentage) {
 		return "0.00%"
 	}
 	return fmt.Sprintf("%.2f%%", cpuUsagePercentage)
 }
+
 func (stats *ContainerStats) FormatMemoryUsage() string {
 	// Get the memory usage and limit in bytes
 	memoryUsage := stats.MemoryStats.Usage
@@ -103,19 +255,31 @@ func (stats *ContainerStats) FormatMemoryUsage() string {
 	return fmt.Sprintf("%s / %s", memoryUsageStr, memoryLimitStr)
 }
 
-func (stats *ContainerStats) FormatDiskIO() string {
-	// Get the disk read/write values in bytes
-	var readBytes, writeBytes uint64
-
-	// Sum up all read and write operations
+// BlockIO returns the bytes read from and written to block devices, summed over all devices.
+func (stats *ContainerStats) BlockIO() (read, write uint64) {
 	for _, stat := range stats.BlkioStats.IoServiceBytesRecursive {
-		switch stat.Op {
-		case "Read":
-			readBytes += stat.Value
-		case "Write":
-			writeBytes += stat.Value
+		// cgroup v1 reports "Read" and "Write", cgroup v2 "read" and "write".
+		switch strings.ToLower(stat.Op) {
+		case "read":
+			read += stat.Value
+		case "write":
+			write += stat.Value
 		}
 	}
+	return read, write
+}
+
+// NetworkIO returns the bytes received and sent, summed over all interfaces.
+func (stats *ContainerStats) NetworkIO() (rx, tx uint64) {
+	for _, net := range stats.Networks {
+		rx += net.RxBytes
+		tx += net.TxBytes
+	}
+	return rx, tx
+}
+
+func (stats *ContainerStats) FormatDiskIO() string {
+	readBytes, writeBytes := stats.BlockIO()
 
 	// Convert the disk read/write values to human-readable strings
 	readBytesStr := bytesToHumanReadable(int64(readBytes))
@@ -126,13 +290,7 @@ func (stats *ContainerStats) FormatDiskIO() string {
 }
 
 func (stats *ContainerStats) FormatNetworkIO() string {
-	var totalRx, totalTx uint64
-
-	// Sum up all network interfaces
-	for _, net := range stats.Networks {
-		totalRx += net.RxBytes
-		totalTx += net.TxBytes
-	}
+	totalRx, totalTx := stats.NetworkIO()
 
 	// Convert to human readable format
 	rxStr := bytesToHumanReadable(int64(totalRx))
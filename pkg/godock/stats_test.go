@@ -0,0 +1,73 @@
+package godock
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerStatsFormat(t *testing.T) {
+	stats := ContainerStats{
+		CpuStats: CPUStats{
+			CPUUsage:    CPUUsage{TotalUsage: 3000},
+			SystemUsage: 20000,
+			OnlineCPUs:  4,
+		},
+		PreCPUStats: CPUStats{
+			CPUUsage:    CPUUsage{TotalUsage: 1000},
+			SystemUsage: 10000,
+		},
+		MemoryStats: MemoryStats{Usage: 512 * 1024 * 1024, Limit: 2 * 1024 * 1024 * 1024},
+		Networks: NetworkStats{
+			"eth0": {RxBytes: 1024, TxBytes: 2048},
+			"eth1": {RxBytes: 1024},
+		},
+		BlkioStats: BlkioStats{
+			IoServiceBytesRecursive: []BlkioStatEntry{
+				{Major: 8, Op: "Read", Value: 1024},
+				{Major: 8, Op: "read", Value: 1024},
+				{Major: 8, Op: "write", Value: 512},
+			},
+		},
+	}
+
+	assert.Equal(t, "80.00%", stats.FormatCpuUsagePercentage())
+	assert.Equal(t, "512.00 MB / 2.00 GB", stats.FormatMemoryUsage())
+	assert.Equal(t, "2.00 KB / 2.00 KB", stats.FormatNetworkIO())
+	assert.Equal(t, "2.00 KB / 512 B", stats.FormatDiskIO())
+	assert.Equal(t, "0.00%", (&ContainerStats{}).FormatCpuUsagePercentage())
+}
+
+func TestContainerStatsDecode(t *testing.T) {
+	sample := `{
+		"read": "2024-06-01T10:00:01Z",
+		"pids_stats": {"current": 3, "limit": 256},
+		"cpu_stats": {"cpu_usage": {"total_usage": 200}, "system_cpu_usage": 1000, "online_cpus": 2, "throttling_data": {"periods": 5}},
+		"memory_stats": {"usage": 100, "limit": 1000, "stats": {"inactive_file": 10}},
+		"networks": {"eth0": {"rx_bytes": 1, "tx_bytes": 2}, "eth1": {"rx_bytes": 3}}
+	}`
+	var stats ContainerStats
+	require.NoError(t, json.Unmarshal([]byte(sample), &stats))
+	assert.Equal(t, uint64(3), stats.PidsStats.Current)
+	assert.Equal(t, uint64(200), stats.CpuStats.CPUUsage.TotalUsage)
+	assert.Equal(t, uint32(2), stats.CpuStats.OnlineCPUs)
+	assert.Equal(t, uint64(5), stats.CpuStats.ThrottlingData.Periods)
+	assert.Equal(t, uint64(10), stats.MemoryStats.Stats["inactive_file"])
+	assert.Len(t, stats.Networks, 2)
+	assert.Equal(t, uint64(3), stats.Networks["eth1"].RxBytes)
+}
+
+func TestStatsFormatter(t *testing.T) {
+	var out bytes.Buffer
+	sample, err := json.Marshal(ContainerStats{Networks: NetworkStats{"eth0": {RxBytes: 2048}}})
+	require.NoError(t, err)
+	_, err = StatsFormatter(&out).Write(sample)
+	require.NoError(t, err)
+
+	var formatted FormatedContainerStats
+	require.NoError(t, json.Unmarshal(out.Bytes(), &formatted))
+	assert.Equal(t, "2.00 KB / 0 B", formatted.NetworkIO)
+}
@@ -0,0 +1,311 @@
+// Package statsmonitor turns the raw stats stream of a container into samples emitted at a fixed interval,
+// with CPU usage, memory trend and network and block IO throughput computed over a sliding window.
+package statsmonitor
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+)
+
+const (
+	// DefaultInterval is the time between two samples emitted by a Monitor.
+	DefaultInterval = time.Second
+	// DefaultWindow is the span of stats the rates and averages of a sample are computed over.
+	DefaultWindow = 10 * time.Second
+)
+
+// Sample is the resource usage of a container over a window of stats.
+type Sample struct {
+	// Time is the time the newest stats of the window were read by the daemon.
+	Time time.Time
+	// Window is the span of stats the sample was computed over. It is shorter than the configured window
+	// until enough stats were collected.
+	Window time.Duration
+	// CPUPercent is the CPU usage over the window, where 100 is one full CPU.
+	CPUPercent float64
+	// CPUPercentLatest is the CPU usage between the last two stats.
+	CPUPercentLatest float64
+	MemoryUsage      uint64
+	MemoryLimit      uint64
+	// MemoryPercent is MemoryUsage as a percentage of MemoryLimit.
+	MemoryPercent float64
+	// MemoryAverage is the mean memory usage over the window.
+	MemoryAverage float64
+	// MemoryTrend is the change of memory usage over the window, in bytes per second. A steadily positive
+	// trend hints at a leak.
+	MemoryTrend float64
+	// Network throughput summed over all interfaces, in bytes per second.
+	NetworkRxPerSecond float64
+	NetworkTxPerSecond float64
+	// Block IO throughput summed over all devices, in bytes per second.
+	BlkioReadPerSecond  float64
+	BlkioWritePerSecond float64
+	PIDs                uint64
+}
+
+// snapshot holds the counters of one stats sample the window computes over.
+type snapshot struct {
+	read        time.Time
+	cpuTotal    uint64
+	cpuSystem   uint64
+	onlineCPUs  uint32
+	memory      uint64
+	memoryLimit uint64
+	rx, tx      uint64
+	blkRead     uint64
+	blkWrite    uint64
+	pids        uint64
+}
+
+func newSnapshot(stats godock.ContainerStats) snapshot {
+	s := snapshot{
+		read:        stats.Read,
+		cpuTotal:    stats.CpuStats.CPUUsage.TotalUsage,
+		cpuSystem:   stats.CpuStats.SystemUsage,
+		onlineCPUs:  stats.CpuStats.OnlineCPUs,
+		memory:      stats.MemoryStats.Usage,
+		memoryLimit: stats.MemoryStats.Limit,
+		pids:        stats.PidsStats.Current,
+	}
+	if s.onlineCPUs == 0 {
+		s.onlineCPUs = uint32(len(stats.CpuStats.CPUUsage.PercpuUsage))
+	}
+	for _, iface := range stats.Networks {
+		s.rx += iface.RxBytes
+		s.tx += iface.TxBytes
+	}
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			s.blkRead += entry.Value
+		case "write":
+			s.blkWrite += entry.Value
+		}
+	}
+	return s
+}
+
+/*
+Window keeps the stats of a container over a sliding span of time and computes samples from them.
+Monitor uses a Window; use one directly to sample stats collected by other means.
+
+Usage example:
+
+	w := statsmonitor.NewWindow(30 * time.Second)
+	for stats := range statsCh {
+		w.Add(stats)
+	}
+	if sample, ok := w.Sample(); ok {
+		fmt.Printf("cpu %.1f%%, rx %.0f B/s\n", sample.CPUPercent, sample.NetworkRxPerSecond)
+	}
+*/
+type Window struct {
+	span      time.Duration
+	snapshots []snapshot
+}
+
+// NewWindow creates a Window spanning the given duration. A span of 0 or less uses DefaultWindow.
+func NewWindow(span time.Duration) *Window {
+	if span <= 0 {
+		span = DefaultWindow
+	}
+	return &Window{span: span}
+}
+
+// Add adds stats to the window and drops the stats that fell out of it. Stats read before the newest stats
+// of the window are ignored. When a counter went backwards, because the container restarted, the window
+// starts over.
+func (w *Window) Add(stats godock.ContainerStats) {
+	s := newSnapshot(stats)
+	if n := len(w.snapshots); n > 0 {
+		last := w.snapshots[n-1]
+		if !s.read.After(last.read) {
+			return
+		}
+		if s.cpuTotal < last.cpuTotal || s.rx < last.rx || s.tx < last.tx || s.blkRead < last.blkRead || s.blkWrite < last.blkWrite {
+			w.snapshots = w.snapshots[:0]
+		}
+	}
+	w.snapshots = append(w.snapshots, s)
+
+	// Keep the newest stats older than the span, so the window always covers the full span.
+	cutoff := s.read.Add(-w.span)
+	drop := 0
+	for drop+1 < len(w.snapshots) && !w.snapshots[drop+1].read.After(cutoff) {
+		drop++
+	}
+	w.snapshots = append(w.snapshots[:0], w.snapshots[drop:]...)
+}
+
+// Len returns the number of stats in the window.
+func (w *Window) Len() int {
+	return len(w.snapshots)
+}
+
+// Sample computes a sample over the window. It reports false until the window holds at least two stats.
+func (w *Window) Sample() (Sample, bool) {
+	n := len(w.snapshots)
+	if n < 2 {
+		return Sample{}, false
+	}
+	first, prev, last := w.snapshots[0], w.snapshots[n-2], w.snapshots[n-1]
+	elapsed := last.read.Sub(first.read)
+	seconds := elapsed.Seconds()
+
+	sample := Sample{
+		Time:                last.read,
+		Window:              elapsed,
+		CPUPercent:          cpuPercent(first, last),
+		CPUPercentLatest:    cpuPercent(prev, last),
+		MemoryUsage:         last.memory,
+		MemoryLimit:         last.memoryLimit,
+		MemoryTrend:         (float64(last.memory) - float64(first.memory)) / seconds,
+		NetworkRxPerSecond:  float64(last.rx-first.rx) / seconds,
+		NetworkTxPerSecond:  float64(last.tx-first.tx) / seconds,
+		BlkioReadPerSecond:  float64(last.blkRead-first.blkRead) / seconds,
+		BlkioWritePerSecond: float64(last.blkWrite-first.blkWrite) / seconds,
+		PIDs:                last.pids,
+	}
+	if last.memoryLimit > 0 {
+		sample.MemoryPercent = float64(last.memory) / float64(last.memoryLimit) * 100
+	}
+	var total float64
+	for _, s := range w.snapshots {
+		total += float64(s.memory)
+	}
+	sample.MemoryAverage = total / float64(n)
+	return sample, true
+}
+
+// cpuPercent computes the CPU usage between two stats the way docker stats does.
+func cpuPercent(from, to snapshot) float64 {
+	if to.cpuSystem <= from.cpuSystem || to.cpuTotal < from.cpuTotal {
+		return 0
+	}
+	cpuDelta := float64(to.cpuTotal - from.cpuTotal)
+	systemDelta := float64(to.cpuSystem - from.cpuSystem)
+	return cpuDelta / systemDelta * float64(to.onlineCPUs) * 100
+}
+
+// Monitor samples the stats of a container at a fixed interval.
+type Monitor struct {
+	client   *godock.Client
+	ctr      *container.ContainerConfig
+	interval time.Duration
+	window   time.Duration
+}
+
+// SetMonitorOptFn is a function type that configures a Monitor.
+type SetMonitorOptFn func(m *Monitor)
+
+// WithInterval sets the time between two samples. The default is DefaultInterval.
+func WithInterval(interval time.Duration) SetMonitorOptFn {
+	return func(m *Monitor) {
+		m.interval = interval
+	}
+}
+
+// WithWindow sets the span of stats rates and averages are computed over. The default is DefaultWindow.
+func WithWindow(window time.Duration) SetMonitorOptFn {
+	return func(m *Monitor) {
+		m.window = window
+	}
+}
+
+// New creates a Monitor for a container.
+func New(client *godock.Client, ctr *container.ContainerConfig, setOptFns ...SetMonitorOptFn) *Monitor {
+	m := &Monitor{
+		client:   client,
+		ctr:      ctr,
+		interval: DefaultInterval,
+		window:   DefaultWindow,
+	}
+	for _, set := range setOptFns {
+		if set != nil {
+			set(m)
+		}
+	}
+	if m.interval <= 0 {
+		m.interval = DefaultInterval
+	}
+	return m
+}
+
+/*
+Run follows the stats of the container and emits a sample every interval, once two stats were collected.
+Both channels are closed when ctx is done, the container stops or reading the stats fails; the error, if
+any, is sent on the error channel first.
+
+Usage example:
+
+	monitor := statsmonitor.New(client, app,
+		statsmonitor.WithInterval(5*time.Second),
+		statsmonitor.WithWindow(time.Minute),
+	)
+	samples, errCh := monitor.Run(ctx)
+	for sample := range samples {
+		log.Printf("cpu %.1f%% mem %s trend %+.0f B/s", sample.CPUPercent,
+			units.BytesSize(float64(sample.MemoryUsage)), sample.MemoryTrend)
+	}
+	if err := <-errCh; err != nil {
+		return err
+	}
+*/
+func (m *Monitor) Run(ctx context.Context) (<-chan Sample, <-chan error) {
+	sampleCh := make(chan Sample)
+	errCh := make(chan error, 1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	statsCh, statsErrCh := m.client.ContainerStatsChan(ctx, m.ctr)
+	go func() {
+		defer close(errCh)
+		defer close(sampleCh)
+		defer cancel()
+		if statsCh == nil {
+			if err := <-statsErrCh; err != nil {
+				errCh <- err
+			}
+			return
+		}
+		// Drain the stats stream so its reader can stop once ctx is cancelled.
+		defer func() {
+			go func() {
+				for range statsCh {
+				}
+			}()
+		}()
+
+		window := NewWindow(m.window)
+		tick := m.client.Clock().After(m.interval)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case stats, ok := <-statsCh:
+				if !ok {
+					if err, ok := <-statsErrCh; ok && err != nil {
+						errCh <- err
+					}
+					return
+				}
+				window.Add(stats)
+			case <-tick:
+				tick = m.client.Clock().After(m.interval)
+				sample, ok := window.Sample()
+				if !ok {
+					continue
+				}
+				select {
+				case sampleCh <- sample:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return sampleCh, errCh
+}
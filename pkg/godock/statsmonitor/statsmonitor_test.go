@@ -0,0 +1,99 @@
+package statsmonitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+
+// stats builds stats read at start+second, where every counter grows linearly.
+func stats(second int, memory uint64) godock.ContainerStats {
+	s := uint64(second)
+	return godock.ContainerStats{
+		Read: start.Add(time.Duration(second) * time.Second),
+		CpuStats: godock.CPUStats{
+			CPUUsage:    godock.CPUUsage{TotalUsage: s * 500_000_000},
+			SystemUsage: s * 2_000_000_000,
+			OnlineCPUs:  2,
+		},
+		MemoryStats: godock.MemoryStats{Usage: memory, Limit: 1000},
+		Networks: godock.NetworkStats{
+			"eth0": {RxBytes: s * 100, TxBytes: s * 10},
+			"eth1": {RxBytes: s * 100},
+		},
+		BlkioStats: godock.BlkioStats{
+			IoServiceBytesRecursive: []godock.BlkioStatEntry{
+				{Op: "read", Value: s * 4096},
+				{Op: "write", Value: s * 1024},
+			},
+		},
+		PidsStats: godock.PidsStats{Current: 3},
+	}
+}
+
+func TestWindowSample(t *testing.T) {
+	w := NewWindow(5 * time.Second)
+	_, ok := w.Sample()
+	assert.False(t, ok)
+
+	w.Add(stats(0, 100))
+	_, ok = w.Sample()
+	assert.False(t, ok)
+
+	for second := 1; second <= 10; second++ {
+		w.Add(stats(second, 100+uint64(second)*10))
+	}
+	sample, ok := w.Sample()
+	require.True(t, ok)
+	assert.Equal(t, 6, w.Len())
+	assert.Equal(t, 5*time.Second, sample.Window)
+	assert.Equal(t, start.Add(10*time.Second), sample.Time)
+	assert.InDelta(t, 50.0, sample.CPUPercent, 0.001)
+	assert.InDelta(t, 50.0, sample.CPUPercentLatest, 0.001)
+	assert.Equal(t, uint64(200), sample.MemoryUsage)
+	assert.InDelta(t, 20.0, sample.MemoryPercent, 0.001)
+	assert.InDelta(t, 175.0, sample.MemoryAverage, 0.001)
+	assert.InDelta(t, 10.0, sample.MemoryTrend, 0.001)
+	assert.InDelta(t, 200.0, sample.NetworkRxPerSecond, 0.001)
+	assert.InDelta(t, 10.0, sample.NetworkTxPerSecond, 0.001)
+	assert.InDelta(t, 4096.0, sample.BlkioReadPerSecond, 0.001)
+	assert.InDelta(t, 1024.0, sample.BlkioWritePerSecond, 0.001)
+	assert.Equal(t, uint64(3), sample.PIDs)
+}
+
+func TestWindowIgnoresStaleStats(t *testing.T) {
+	w := NewWindow(time.Minute)
+	w.Add(stats(1, 100))
+	w.Add(stats(2, 100))
+	w.Add(stats(2, 100))
+	w.Add(stats(1, 100))
+	assert.Equal(t, 2, w.Len())
+}
+
+func TestWindowRestartsOnCounterReset(t *testing.T) {
+	w := NewWindow(time.Minute)
+	w.Add(stats(10, 100))
+	w.Add(stats(11, 100))
+
+	restarted := stats(1, 50)
+	restarted.Read = start.Add(12 * time.Second)
+	w.Add(restarted)
+	assert.Equal(t, 1, w.Len())
+	_, ok := w.Sample()
+	assert.False(t, ok)
+}
+
+func TestNewDefaults(t *testing.T) {
+	m := New(nil, nil, WithInterval(0), nil)
+	assert.Equal(t, DefaultInterval, m.interval)
+	assert.Equal(t, DefaultWindow, m.window)
+
+	m = New(nil, nil, WithInterval(5*time.Second), WithWindow(time.Minute))
+	assert.Equal(t, 5*time.Second, m.interval)
+	assert.Equal(t, time.Minute, m.window)
+}
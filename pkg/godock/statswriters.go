@@ -0,0 +1,215 @@
+package godock
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// StatsRecord is a flat, machine-readable view of a stats sample, as written by StatsCSVWriter and
+// StatsJSONLWriter. Sizes are in bytes and percentages are where 100 is one full CPU or the whole memory limit.
+type StatsRecord struct {
+	Time       time.Time `json:"time"`
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	CPUPercent float64   `json:"cpu_percent"`
+	MemUsage   uint64    `json:"mem_usage"`
+	MemLimit   uint64    `json:"mem_limit"`
+	MemPercent float64   `json:"mem_percent"`
+	NetRx      uint64    `json:"net_rx"`
+	NetTx      uint64    `json:"net_tx"`
+	BlockRead  uint64    `json:"block_read"`
+	BlockWrite uint64    `json:"block_write"`
+	Pids       uint64    `json:"pids"`
+}
+
+// NewStatsRecord flattens a stats sample. The memory usage excludes the inactive page cache, like docker stats.
+func NewStatsRecord(stats ContainerStats) StatsRecord {
+	rx, tx := stats.NetworkIO()
+	read, write := stats.BlockIO()
+	return StatsRecord{
+		Time:       stats.Read,
+		ID:         stats.ID,
+		Name:       strings.TrimPrefix(stats.Name, "/"),
+		CPUPercent: stats.CPUPercent(),
+		MemUsage:   stats.MemoryUsageNoCache(),
+		MemLimit:   stats.MemoryStats.Limit,
+		MemPercent: stats.MemoryPercent(),
+		NetRx:      rx,
+		NetTx:      tx,
+		BlockRead:  read,
+		BlockWrite: write,
+		Pids:       stats.PidsStats.Current,
+	}
+}
+
+var statsCSVHeader = []string{
+	"time", "id", "name", "cpu_percent", "mem_usage", "mem_limit", "mem_percent",
+	"net_rx", "net_tx", "block_read", "block_write", "pids",
+}
+
+// StatsCSVEncoder writes stats as CSV rows, preceded by a header row. It is safe for concurrent use, so the
+// stats of several containers can be written to the same output.
+type StatsCSVEncoder struct {
+	mu          sync.Mutex
+	writer      *csv.Writer
+	wroteHeader bool
+}
+
+// StatsCSVWriter returns an encoder writing stats as CSV to writer.
+func StatsCSVWriter(writer io.Writer) *StatsCSVEncoder {
+	return &StatsCSVEncoder{writer: csv.NewWriter(writer)}
+}
+
+func (e *StatsCSVEncoder) Encode(stats ContainerStats) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.wroteHeader {
+		if err := e.writer.Write(statsCSVHeader); err != nil {
+			return err
+		}
+		e.wroteHeader = true
+	}
+	r := NewStatsRecord(stats)
+	row := []string{
+		r.Time.Format(time.RFC3339Nano),
+		r.ID,
+		r.Name,
+		strconv.FormatFloat(r.CPUPercent, 'f', 2, 64),
+		strconv.FormatUint(r.MemUsage, 10),
+		strconv.FormatUint(r.MemLimit, 10),
+		strconv.FormatFloat(r.MemPercent, 'f', 2, 64),
+		strconv.FormatUint(r.NetRx, 10),
+		strconv.FormatUint(r.NetTx, 10),
+		strconv.FormatUint(r.BlockRead, 10),
+		strconv.FormatUint(r.BlockWrite, 10),
+		strconv.FormatUint(r.Pids, 10),
+	}
+	if err := e.writer.Write(row); err != nil {
+		return err
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// StatsJSONLEncoder writes stats as one StatsRecord JSON object per line. It is safe for concurrent use.
+type StatsJSONLEncoder struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// StatsJSONLWriter returns an encoder writing stats as JSON lines to writer.
+func StatsJSONLWriter(writer io.Writer) *StatsJSONLEncoder {
+	return &StatsJSONLEncoder{encoder: json.NewEncoder(writer)}
+}
+
+func (e *StatsJSONLEncoder) Encode(stats ContainerStats) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.encoder.Encode(NewStatsRecord(stats))
+}
+
+/*
+StatsTableEncoder renders the latest stats of one or more containers as a table like docker stats, redrawn in
+place on a terminal at every sample. Containers are listed in the order their first stats arrived. It is safe
+for concurrent use, so the stats streams of several containers can feed the same table.
+
+Usage example:
+
+	table := godock.StatsTableWriter(os.Stdout)
+	for _, ctr := range []*container.ContainerConfig{web, db} {
+		statsCh, _ := client.ContainerStatsChan(ctx, ctr)
+		go func() {
+			for stats := range statsCh {
+				table.Encode(stats)
+			}
+		}()
+	}
+*/
+type StatsTableEncoder struct {
+	mu     sync.Mutex
+	writer io.Writer
+	order  []string
+	rows   map[string]StatsRecord
+	// lines is the number of lines drawn last time, to move the cursor back over them.
+	lines int
+}
+
+// StatsTableWriter returns an encoder rendering stats as a table on writer, which should be a terminal.
+func StatsTableWriter(writer io.Writer) *StatsTableEncoder {
+	return &StatsTableEncoder{writer: writer, rows: make(map[string]StatsRecord)}
+}
+
+func (e *StatsTableEncoder) Encode(stats ContainerStats) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	r := NewStatsRecord(stats)
+	key := r.ID
+	if key == "" {
+		key = r.Name
+	}
+	if _, ok := e.rows[key]; !ok {
+		e.order = append(e.order, key)
+	}
+	e.rows[key] = r
+	return e.draw()
+}
+
+// Remove drops a container, by id or by name when the daemon reported no id, from the table and redraws it.
+func (e *StatsTableEncoder) Remove(key string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.rows[key]; !ok {
+		return nil
+	}
+	delete(e.rows, key)
+	for i, k := range e.order {
+		if k == key {
+			e.order = append(e.order[:i], e.order[i+1:]...)
+			break
+		}
+	}
+	return e.draw()
+}
+
+func (e *StatsTableEncoder) draw() error {
+	var table bytes.Buffer
+	tw := tabwriter.NewWriter(&table, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "CONTAINER ID\tNAME\tCPU %\tMEM USAGE / LIMIT\tMEM %\tNET I/O\tBLOCK I/O\tPIDS")
+	for _, key := range e.order {
+		r := e.rows[key]
+		id := r.ID
+		if len(id) > 12 {
+			id = id[:12]
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.2f%%\t%s / %s\t%.2f%%\t%s / %s\t%s / %s\t%d\n",
+			id, r.Name, r.CPUPercent,
+			bytesToHumanReadable(int64(r.MemUsage)), bytesToHumanReadable(int64(r.MemLimit)), r.MemPercent,
+			bytesToHumanReadable(int64(r.NetRx)), bytesToHumanReadable(int64(r.NetTx)),
+			bytesToHumanReadable(int64(r.BlockRead)), bytesToHumanReadable(int64(r.BlockWrite)),
+			r.Pids,
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if e.lines > 0 {
+		// Move the cursor up to the first line of the previous table and clear everything below it.
+		fmt.Fprintf(&out, "\x1b[%dA\x1b[J", e.lines)
+	}
+	out.Write(table.Bytes())
+	if _, err := e.writer.Write(out.Bytes()); err != nil {
+		return err
+	}
+	e.lines = 1 + len(e.order)
+	return nil
+}
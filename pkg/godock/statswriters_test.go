@@ -0,0 +1,115 @@
+package godock
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writerStats(id, name string) ContainerStats {
+	return ContainerStats{
+		ID:   id,
+		Name: name,
+		Read: time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC),
+		CpuStats: CPUStats{
+			CPUUsage:    CPUUsage{TotalUsage: 3000},
+			SystemUsage: 20000,
+			OnlineCPUs:  4,
+		},
+		PreCPUStats: CPUStats{
+			CPUUsage:    CPUUsage{TotalUsage: 1000},
+			SystemUsage: 10000,
+		},
+		PidsStats:   PidsStats{Current: 3},
+		MemoryStats: MemoryStats{Usage: 600, Limit: 1000, Stats: map[string]uint64{"inactive_file": 100}},
+		Networks:    NetworkStats{"eth0": {RxBytes: 10, TxBytes: 20}, "eth1": {RxBytes: 5}},
+		BlkioStats: BlkioStats{
+			IoServiceBytesRecursive: []BlkioStatEntry{{Op: "read", Value: 7}, {Op: "Write", Value: 9}},
+		},
+	}
+}
+
+func TestNewStatsRecord(t *testing.T) {
+	r := NewStatsRecord(writerStats("abc", "/web"))
+	assert.Equal(t, "web", r.Name)
+	assert.InDelta(t, 80.0, r.CPUPercent, 0.001)
+	assert.Equal(t, uint64(500), r.MemUsage)
+	assert.InDelta(t, 50.0, r.MemPercent, 0.001)
+	assert.Equal(t, uint64(15), r.NetRx)
+	assert.Equal(t, uint64(20), r.NetTx)
+	assert.Equal(t, uint64(7), r.BlockRead)
+	assert.Equal(t, uint64(9), r.BlockWrite)
+	assert.Equal(t, uint64(3), r.Pids)
+}
+
+func TestStatsCSVWriter(t *testing.T) {
+	var out bytes.Buffer
+	enc := StatsCSVWriter(&out)
+	require.NoError(t, enc.Encode(writerStats("abc", "/web")))
+	require.NoError(t, enc.Encode(writerStats("def", "/db")))
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, statsCSVHeader, rows[0])
+	assert.Equal(t, []string{"2024-06-01T10:00:00Z", "abc", "web", "80.00", "500", "1000", "50.00", "15", "20", "7", "9", "3"}, rows[1])
+	assert.Equal(t, "db", rows[2][2])
+}
+
+func TestStatsJSONLWriter(t *testing.T) {
+	var out bytes.Buffer
+	enc := StatsJSONLWriter(&out)
+	require.NoError(t, enc.Encode(writerStats("abc", "/web")))
+	require.NoError(t, enc.Encode(writerStats("def", "/db")))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	var r StatsRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &r))
+	assert.Equal(t, "def", r.ID)
+	assert.Equal(t, uint64(500), r.MemUsage)
+}
+
+func TestStatsTableWriter(t *testing.T) {
+	var out bytes.Buffer
+	enc := StatsTableWriter(&out)
+	require.NoError(t, enc.Encode(writerStats("0123456789abcdef", "/web")))
+	first := out.String()
+	assert.True(t, strings.HasPrefix(first, "CONTAINER ID"))
+	assert.Contains(t, first, "0123456789ab   web")
+	assert.NotContains(t, first, "0123456789abcdef")
+	assert.Contains(t, first, "80.00%")
+
+	out.Reset()
+	require.NoError(t, enc.Encode(writerStats("def", "/db")))
+	require.NoError(t, enc.Encode(writerStats("0123456789abcdef", "/web")))
+	redraw := out.String()[strings.LastIndex(out.String(), "\x1b[J")+len("\x1b[J"):]
+	// The second redraw moves back over the header and both rows.
+	assert.Contains(t, out.String(), "\x1b[3A\x1b[J")
+	lines := strings.Split(strings.TrimSpace(redraw), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[1], "web")
+	assert.Contains(t, lines[2], "db")
+
+	out.Reset()
+	require.NoError(t, enc.Remove("def"))
+	assert.NotContains(t, out.String(), "db")
+}
+
+func TestStatsFormatterWith(t *testing.T) {
+	var out bytes.Buffer
+	sample, err := json.Marshal(writerStats("abc", "/web"))
+	require.NoError(t, err)
+	_, err = StatsFormatterWith(StatsJSONLWriter(&out)).Write(sample)
+	require.NoError(t, err)
+
+	var r StatsRecord
+	require.NoError(t, json.Unmarshal(out.Bytes(), &r))
+	assert.Equal(t, "web", r.Name)
+}
@@ -0,0 +1,167 @@
+package godock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/stopoptions"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// StopAll gracefully stops a group of containers in reverse dependency order.
+// A container is always stopped before the containers it depends on, so an application
+// is stopped before the database it talks to. Dependencies are detected from links,
+// volumes-from and container:<name> network, pid and ipc modes, and can be declared
+// explicitly with stopoptions.DependsOn. Containers without dependencies between them
+// are stopped in the reverse of the order they were given in.
+//
+// Each container receives the configured signal (SIGTERM by default) and is killed
+// with SIGKILL by the daemon if it has not exited once its timeout elapses.
+// Containers that no longer exist are skipped.
+func (c *Client) StopAll(ctx context.Context, containerConfigs []*container.ContainerConfig, stopOptions ...stopoptions.SetStopAllOptFn) error {
+	options := stopoptions.NewStopAllOptions()
+	for _, fn := range stopOptions {
+		if fn != nil {
+			fn(options)
+		}
+	}
+
+	ordered, err := stopOrder(containerConfigs, options.DependsOn)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, containerConfig := range ordered {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+
+		opts := containerType.StopOptions{
+			Signal:  options.Signal,
+			Timeout: options.Timeout,
+		}
+		if timeout, ok := options.Timeouts[containerConfig.Name]; ok {
+			opts.Timeout = &timeout
+		}
+
+		if err := c.wrapped.ContainerStop(ctx, containerConfig.Id, opts); err != nil {
+			if client.IsErrNotFound(err) {
+				continue
+			}
+			stopErr := &errdefs.ContainerError{
+				ID:      containerConfig.Name,
+				Op:      "stop",
+				Message: err.Error(),
+				Err:     err,
+			}
+			if !options.ContinueOnError {
+				return errors.Join(append(errs, stopErr)...)
+			}
+			errs = append(errs, stopErr)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stopOrder returns the containers ordered so that dependents come before their dependencies.
+func stopOrder(containerConfigs []*container.ContainerConfig, dependsOn map[string][]string) ([]*container.ContainerConfig, error) {
+	lookup := make(map[string]int, len(containerConfigs)*2)
+	for i, containerConfig := range containerConfigs {
+		if containerConfig == nil || containerConfig.Id == "" {
+			return nil, &errdefs.ValidationError{
+				Field:   "containerConfigs",
+				Message: fmt.Sprintf("container config at index %d is nil or has no ID", i),
+			}
+		}
+		lookup[containerConfig.Id] = i
+		if containerConfig.Name != "" {
+			lookup[containerConfig.Name] = i
+		}
+	}
+
+	edges := make([][]int, len(containerConfigs))
+	for i, containerConfig := range containerConfigs {
+		refs := append(containerDependencies(containerConfig), dependsOn[containerConfig.Name]...)
+		for _, ref := range refs {
+			if j, ok := lookup[strings.TrimPrefix(ref, "/")]; ok && j != i {
+				edges[i] = append(edges[i], j)
+			}
+		}
+	}
+
+	// Depth first search yields a start order where dependencies precede dependents.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(containerConfigs))
+	startOrder := make([]*container.ContainerConfig, 0, len(containerConfigs))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return &errdefs.ValidationError{
+				Field:   "containerConfigs",
+				Message: fmt.Sprintf("dependency cycle detected at container %s", containerConfigs[i].Name),
+			}
+		}
+		state[i] = visiting
+		for _, j := range edges[i] {
+			if err := visit(j); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		startOrder = append(startOrder, containerConfigs[i])
+		return nil
+	}
+	for i := range containerConfigs {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	ordered := make([]*container.ContainerConfig, len(startOrder))
+	for i, containerConfig := range startOrder {
+		ordered[len(startOrder)-1-i] = containerConfig
+	}
+	return ordered, nil
+}
+
+// containerDependencies returns the names or IDs of the containers the given container
+// depends on through its host configuration.
+func containerDependencies(containerConfig *container.ContainerConfig) []string {
+	hostConfig := containerConfig.HostOptions
+	if hostConfig == nil {
+		return nil
+	}
+
+	var deps []string
+	for _, link := range hostConfig.Links {
+		name, _, _ := strings.Cut(link, ":")
+		deps = append(deps, name)
+	}
+	for _, from := range hostConfig.VolumesFrom {
+		name, _, _ := strings.Cut(from, ":")
+		deps = append(deps, name)
+	}
+	if hostConfig.NetworkMode.IsContainer() {
+		deps = append(deps, hostConfig.NetworkMode.ConnectedContainer())
+	}
+	if hostConfig.PidMode.IsContainer() {
+		deps = append(deps, hostConfig.PidMode.Container())
+	}
+	if hostConfig.IpcMode.IsContainer() {
+		deps = append(deps, hostConfig.IpcMode.Container())
+	}
+	return deps
+}
@@ -0,0 +1,79 @@
+package godock
+
+import (
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStopTestContainer(name string) *container.ContainerConfig {
+	c := container.NewConfig(name)
+	c.Id = name + "-id"
+	return c
+}
+
+func containerNames(configs []*container.ContainerConfig) []string {
+	names := make([]string, 0, len(configs))
+	for _, c := range configs {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func TestStopOrder(t *testing.T) {
+	t.Run("Reverse of given order without dependencies", func(t *testing.T) {
+		db := newStopTestContainer("db")
+		cache := newStopTestContainer("cache")
+		app := newStopTestContainer("app")
+
+		ordered, err := stopOrder([]*container.ContainerConfig{db, cache, app}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"app", "cache", "db"}, containerNames(ordered))
+	})
+
+	t.Run("Explicit dependencies", func(t *testing.T) {
+		app := newStopTestContainer("app")
+		db := newStopTestContainer("db")
+		cache := newStopTestContainer("cache")
+
+		ordered, err := stopOrder([]*container.ContainerConfig{app, db, cache}, map[string][]string{
+			"app": {"db", "cache"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "app", ordered[0].Name)
+	})
+
+	t.Run("Detected dependencies", func(t *testing.T) {
+		db := newStopTestContainer("db")
+		sidecar := newStopTestContainer("sidecar")
+		sidecar.SetHostOptions(hostoptions.NetworkMode("container:app"))
+		app := newStopTestContainer("app")
+		app.SetHostOptions(hostoptions.VolumesFrom("db:ro"))
+
+		ordered, err := stopOrder([]*container.ContainerConfig{sidecar, db, app}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sidecar", "app", "db"}, containerNames(ordered))
+	})
+
+	t.Run("Dependency cycle", func(t *testing.T) {
+		a := newStopTestContainer("a")
+		b := newStopTestContainer("b")
+
+		_, err := stopOrder([]*container.ContainerConfig{a, b}, map[string][]string{
+			"a": {"b"},
+			"b": {"a"},
+		})
+		require.Error(t, err)
+		assert.True(t, errdefs.IsInvalidConfig(err))
+	})
+
+	t.Run("Missing ID", func(t *testing.T) {
+		_, err := stopOrder([]*container.ContainerConfig{container.NewConfig("no-id")}, nil)
+		require.Error(t, err)
+		assert.True(t, errdefs.IsInvalidConfig(err))
+	})
+}
@@ -0,0 +1,159 @@
+package stopoptions
+
+import (
+	"github.com/docker/docker/api/types/container"
+)
+
+// StopAllOptions configures how a group of containers is shut down by Client.StopAll.
+type StopAllOptions struct {
+	// Signal is sent to each container to request a graceful stop.
+	// When empty the container's configured StopSignal (or SIGTERM) is used.
+	Signal string
+	// Timeout is the default number of seconds to wait for a container to exit
+	// before the daemon escalates to SIGKILL. Nil uses the daemon default.
+	Timeout *int
+	// Timeouts holds per-container timeouts keyed by container name and overrides Timeout.
+	Timeouts map[string]int
+	// DependsOn holds explicit dependencies keyed by container name.
+	// A container is always stopped before the containers it depends on.
+	DependsOn map[string][]string
+	// ContinueOnError keeps stopping the remaining containers when one fails.
+	ContinueOnError bool
+}
+
+// SetStopAllOptFn is a function type that configures options for Client.StopAll.
+type SetStopAllOptFn func(options *StopAllOptions)
+
+// NewStopAllOptions returns StopAllOptions with all maps initialized.
+func NewStopAllOptions() *StopAllOptions {
+	return &StopAllOptions{
+		Timeouts:  make(map[string]int),
+		DependsOn: make(map[string][]string),
+	}
+}
+
+/*
+Signal sets the signal sent to every container to request a graceful stop.
+If the container is still running once its timeout elapses it is killed with SIGKILL.
+
+Usage example:
+
+	err := client.StopAll(ctx, containers,
+		stopoptions.Signal("SIGINT"),
+	)
+*/
+func Signal(signal string) SetStopAllOptFn {
+	return func(options *StopAllOptions) {
+		options.Signal = signal
+	}
+}
+
+/*
+Timeout sets the default number of seconds to wait for each container to exit
+before it is killed with SIGKILL.
+
+Usage example:
+
+	err := client.StopAll(ctx, containers,
+		stopoptions.Timeout(15),
+	)
+*/
+func Timeout(seconds int) SetStopAllOptFn {
+	return func(options *StopAllOptions) {
+		options.Timeout = &seconds
+	}
+}
+
+/*
+ContainerTimeout overrides the stop timeout for a single container, identified by name.
+
+Usage example:
+
+	err := client.StopAll(ctx, containers,
+		stopoptions.Timeout(10),
+		stopoptions.ContainerTimeout("mongodb", 60), // give the database time to flush
+	)
+*/
+func ContainerTimeout(name string, seconds int) SetStopAllOptFn {
+	return func(options *StopAllOptions) {
+		if options.Timeouts == nil {
+			options.Timeouts = make(map[string]int)
+		}
+		options.Timeouts[name] = seconds
+	}
+}
+
+/*
+DependsOn declares that the named container depends on the given containers.
+The dependent container is stopped before any of its dependencies.
+
+Dependencies expressed through links, volumes-from and container:<name> network,
+pid or ipc modes are detected automatically and do not need to be declared.
+
+Usage example:
+
+	err := client.StopAll(ctx, containers,
+		stopoptions.DependsOn("app", "mongodb", "redis"),
+	)
+*/
+func DependsOn(name string, dependencies ...string) SetStopAllOptFn {
+	return func(options *StopAllOptions) {
+		if options.DependsOn == nil {
+			options.DependsOn = make(map[string][]string)
+		}
+		options.DependsOn[name] = append(options.DependsOn[name], dependencies...)
+	}
+}
+
+/*
+ContinueOnError keeps stopping the remaining containers when stopping one of them fails.
+All errors are returned together once every container has been processed.
+
+Usage example:
+
+	err := client.StopAll(ctx, containers,
+		stopoptions.ContinueOnError(),
+	)
+*/
+func ContinueOnError() SetStopAllOptFn {
+	return func(options *StopAllOptions) {
+		options.ContinueOnError = true
+	}
+}
+
+// SetStopOptFn is a function type that configures how a single container is stopped or restarted
+// by Client.ContainerStop, Client.ContainerRestart and Client.ContainerRecreate.
+type SetStopOptFn func(options *container.StopOptions)
+
+/*
+StopTimeout sets the number of seconds to wait for the container to exit before it is killed.
+Use -1 to wait indefinitely. Without this option the daemon default of 10 seconds is used.
+
+Usage example:
+
+	err := client.ContainerStop(ctx, myContainer,
+		stopoptions.StopTimeout(30),
+	)
+*/
+func StopTimeout(seconds int) SetStopOptFn {
+	return func(options *container.StopOptions) {
+		options.Timeout = &seconds
+	}
+}
+
+/*
+StopSignal sets the signal sent to the container to request a stop, e.g. "SIGINT".
+Without this option the container's configured StopSignal (or SIGTERM) is used.
+
+Usage example:
+
+	err := client.ContainerRestart(ctx, myContainer,
+		stopoptions.StopSignal("SIGINT"),
+		stopoptions.StopTimeout(5),
+	)
+*/
+func StopSignal(signal string) SetStopOptFn {
+	return func(options *container.StopOptions) {
+		options.Signal = signal
+	}
+}
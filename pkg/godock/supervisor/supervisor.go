@@ -0,0 +1,510 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/client"
+)
+
+// LabelSupervisor is the label set on every supervised container, with its name.
+const LabelSupervisor = "godock.supervisor"
+
+const (
+	// DefaultCheckInterval is how often every supervised container is inspected by default, next to the
+	// event stream.
+	DefaultCheckInterval = 10 * time.Second
+	// DefaultInitialBackoff and DefaultMaxBackoff bound the wait before a container is recreated by default.
+	DefaultInitialBackoff = time.Second
+	DefaultMaxBackoff     = time.Minute
+	// DefaultMaxRestarts is the number of restarts within DefaultRestartWindow after which the supervisor
+	// gives up on a container by default.
+	DefaultMaxRestarts   = 5
+	DefaultRestartWindow = 10 * time.Minute
+)
+
+// State is the state of a supervised container.
+type State string
+
+const (
+	// Starting containers have not been checked yet.
+	Starting State = "starting"
+	// Running containers are running, or paused.
+	Running State = "running"
+	// Restarting containers exited or were removed and wait for the backoff before they are recreated.
+	Restarting State = "restarting"
+	// Stopped containers exited and were left stopped by the exit handler.
+	Stopped State = "stopped"
+	// GaveUp containers exceeded the restart limit and are no longer recreated.
+	GaveUp State = "gave up"
+)
+
+// Exit describes a supervised container that stopped running.
+type Exit struct {
+	Name        string
+	ContainerID string
+	// ExitCode is the exit code of the container, or -1 when it was removed.
+	ExitCode  int
+	OOMKilled bool
+	// Removed is set when the container no longer exists.
+	Removed bool
+	// Restarts is the number of times the container was recreated within the restart window.
+	Restarts int
+	// Config is the config the container is recreated from. An exit handler may change it, e.g. to raise
+	// the memory limit of an OOM-killed container; the change applies to every later restart.
+	Config *container.ContainerConfig
+}
+
+// ExitHandler decides whether a container that stopped running is recreated. Returning false leaves it
+// stopped until it is started again, or removed and added back.
+type ExitHandler func(ctx context.Context, exit *Exit) bool
+
+// Status is a snapshot of a supervised container.
+type Status struct {
+	Name        string
+	ContainerID string
+	State       State
+	// Restarts is the number of times the container was recreated since it was added.
+	Restarts int
+	// LastExit is the last time the container stopped running, if it did.
+	LastExit *Exit
+	// Err is the error of the last failed recreation, cleared once the container runs again.
+	Err error
+}
+
+// entry is a supervised container.
+type entry struct {
+	config   *container.ContainerConfig
+	status   Status
+	restarts []time.Time
+	busy     bool
+	removed  bool
+}
+
+/*
+Supervisor keeps a set of containers running. It watches container events and inspects every container
+periodically, and recreates a container from its config when it exits or is removed, waiting an exponential
+backoff between restarts and giving up after too many restarts in a short time. Unlike a restart policy it
+also brings back deleted containers and lets an exit handler decide, e.g. for OOM-killed containers.
+
+Every exit counts, including docker stop; remove a container from the supervisor before stopping it.
+
+Usage example:
+
+	sup := supervisor.New(client,
+		supervisor.WithMaxRestarts(10, 30*time.Minute),
+		supervisor.WithExitHandler(func(ctx context.Context, exit *supervisor.Exit) bool {
+			if exit.OOMKilled {
+				exit.Config.SetHostOptions(hostoptions.Memory(1 << 30))
+			}
+			return true
+		}),
+	)
+	if err := sup.Add(worker); err != nil {
+		return err
+	}
+	err := sup.Run(ctx)
+*/
+type Supervisor struct {
+	client         *godock.Client
+	clock          clock.Clock
+	checkInterval  time.Duration
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	maxRestarts    int
+	restartWindow  time.Duration
+	onExit         ExitHandler
+	onChange       func(Status)
+	// inspect and replace talk to the docker daemon; replaced in tests.
+	inspect func(ctx context.Context, id string) (types.ContainerJSON, error)
+	replace func(ctx context.Context, oldID string, cfg *container.ContainerConfig) error
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	ctx     context.Context
+	wg      sync.WaitGroup
+}
+
+// SetSupervisorOptFn is a function type that configures a Supervisor.
+type SetSupervisorOptFn func(s *Supervisor)
+
+// New creates a Supervisor managing containers through client.
+func New(client *godock.Client, setOptFns ...SetSupervisorOptFn) *Supervisor {
+	s := &Supervisor{
+		client:         client,
+		checkInterval:  DefaultCheckInterval,
+		initialBackoff: DefaultInitialBackoff,
+		maxBackoff:     DefaultMaxBackoff,
+		maxRestarts:    DefaultMaxRestarts,
+		restartWindow:  DefaultRestartWindow,
+		entries:        make(map[string]*entry),
+	}
+	if client != nil {
+		s.clock = client.Clock()
+	} else {
+		s.clock = clock.Real()
+	}
+	s.inspect = s.inspectContainer
+	s.replace = s.replaceContainer
+	for _, set := range setOptFns {
+		if set != nil {
+			set(s)
+		}
+	}
+	return s
+}
+
+// WithCheckInterval sets how often every container is inspected, which catches changes the event stream
+// missed. The default is DefaultCheckInterval.
+func WithCheckInterval(d time.Duration) SetSupervisorOptFn {
+	return func(s *Supervisor) {
+		if d > 0 {
+			s.checkInterval = d
+		}
+	}
+}
+
+// WithBackoff sets the wait before the first restart of a container, doubled for every further restart
+// within the restart window up to max. The defaults are DefaultInitialBackoff and DefaultMaxBackoff.
+func WithBackoff(initial, max time.Duration) SetSupervisorOptFn {
+	return func(s *Supervisor) {
+		if initial > 0 {
+			s.initialBackoff = initial
+		}
+		if max > 0 {
+			s.maxBackoff = max
+		}
+	}
+}
+
+// WithMaxRestarts gives up on a container once it has been restarted n times within window. A limit of 0
+// restarts containers indefinitely. The defaults are DefaultMaxRestarts and DefaultRestartWindow.
+func WithMaxRestarts(n int, window time.Duration) SetSupervisorOptFn {
+	return func(s *Supervisor) {
+		if n >= 0 {
+			s.maxRestarts = n
+		}
+		if window > 0 {
+			s.restartWindow = window
+		}
+	}
+}
+
+// WithExitHandler sets the handler deciding whether a container that stopped running is recreated.
+// By default every container is recreated.
+func WithExitHandler(fn ExitHandler) SetSupervisorOptFn {
+	return func(s *Supervisor) {
+		s.onExit = fn
+	}
+}
+
+// OnChange sets a function called with the status of a container every time its state changes.
+func OnChange(fn func(Status)) SetSupervisorOptFn {
+	return func(s *Supervisor) {
+		s.onChange = fn
+	}
+}
+
+// WithClock sets the clock the backoff and check interval wait on, e.g. a clock.Fake in tests. It defaults
+// to the client's clock.
+func WithClock(c clock.Clock) SetSupervisorOptFn {
+	return func(s *Supervisor) {
+		if c != nil {
+			s.clock = c
+		}
+	}
+}
+
+/*
+Add supervises a container. The config is copied and its name identifies the container; when its Id is
+empty, an existing container with the name is adopted, otherwise the container is created on the first
+check. Containers created by the supervisor carry LabelSupervisor. Adopted containers lack the label until
+they are replaced, so events are matched to supervised containers by label or by container name.
+*/
+func (s *Supervisor) Add(containerConfig *container.ContainerConfig) error {
+	if containerConfig == nil || containerConfig.Name == "" || containerConfig.Options == nil {
+		return &errdefs.ValidationError{Field: "containerConfig", Message: "container config, name or options cannot be empty"}
+	}
+	cfg := containerConfig.Clone()
+	cfg.SetContainerOptions(containeroptions.Label(LabelSupervisor, cfg.Name))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[cfg.Name]; ok {
+		return &errdefs.ValidationError{Field: "Name", Message: fmt.Sprintf("container %s is already supervised", cfg.Name)}
+	}
+	id := cfg.Id
+	if id == "" {
+		id = cfg.Name
+	}
+	e := &entry{config: cfg, status: Status{Name: cfg.Name, ContainerID: id, State: Starting}}
+	s.entries[cfg.Name] = e
+	if s.ctx != nil {
+		s.checkLocked(s.ctx, e, "")
+	}
+	return nil
+}
+
+// Remove stops supervising a container and leaves it as it is.
+func (s *Supervisor) Remove(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[name]; ok {
+		e.removed = true
+		delete(s.entries, name)
+	}
+}
+
+// Status returns a snapshot of a supervised container, and false when it is not supervised.
+func (s *Supervisor) Status(name string) (Status, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[name]
+	if !ok {
+		return Status{}, false
+	}
+	return e.status, true
+}
+
+// Statuses returns a snapshot of every supervised container, sorted by name.
+func (s *Supervisor) Statuses() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]Status, 0, len(s.entries))
+	for _, e := range s.entries {
+		statuses = append(statuses, e.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Run supervises the containers until ctx is done or the event stream fails. Pending restarts are abandoned
+// when it returns.
+func (s *Supervisor) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.ctx != nil {
+		s.mu.Unlock()
+		return errors.New("supervisor is already running")
+	}
+	s.ctx = ctx
+	s.mu.Unlock()
+	defer func() {
+		s.wg.Wait()
+		s.mu.Lock()
+		s.ctx = nil
+		s.mu.Unlock()
+	}()
+
+	// The stream is not filtered by LabelSupervisor, as adopted containers do not carry it.
+	msgCh, errCh := s.client.Events(ctx,
+		godock.WithEventFilter("type", string(events.ContainerEventType)),
+		godock.WithEventFilter("event", string(events.ActionDie)),
+		godock.WithEventFilter("event", string(events.ActionDestroy)),
+	)
+	s.checkAll(ctx)
+	tick := s.clock.After(s.checkInterval)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errCh:
+			if errors.Is(err, context.Canceled) {
+				return ctx.Err()
+			}
+			return fmt.Errorf("supervisor event stream failed: %w", err)
+		case msg := <-msgCh:
+			name := msg.Actor.Attributes[LabelSupervisor]
+			if name == "" {
+				name = msg.Actor.Attributes["name"]
+			}
+			s.mu.Lock()
+			if e, ok := s.entries[name]; ok {
+				s.checkLocked(ctx, e, msg.Actor.ID)
+			}
+			s.mu.Unlock()
+		case <-tick:
+			s.checkAll(ctx)
+			tick = s.clock.After(s.checkInterval)
+		}
+	}
+}
+
+// checkAll checks every supervised container.
+func (s *Supervisor) checkAll(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.entries {
+		s.checkLocked(ctx, e, "")
+	}
+}
+
+// checkLocked starts a check of a container in the background, unless one is in progress. When id is set,
+// the check only applies to that container, so events of replaced containers are ignored. s.mu must be held.
+func (s *Supervisor) checkLocked(ctx context.Context, e *entry, id string) {
+	if e.busy || (id != "" && id != e.status.ContainerID) {
+		return
+	}
+	e.busy = true
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.check(ctx, e)
+		s.mu.Lock()
+		e.busy = false
+		s.mu.Unlock()
+	}()
+}
+
+// check inspects a container and recreates it when it is not running.
+func (s *Supervisor) check(ctx context.Context, e *entry) {
+	s.mu.Lock()
+	id, state := e.status.ContainerID, e.status.State
+	s.mu.Unlock()
+	if state == Stopped || state == GaveUp {
+		// Only a container started again by someone else is picked up again.
+		if inspect, err := s.inspect(ctx, id); err == nil && inspect.State != nil && inspect.State.Running {
+			s.update(e, func(status *Status) { status.State = Running })
+		}
+		return
+	}
+
+	inspect, err := s.inspect(ctx, id)
+	if err != nil && !client.IsErrNotFound(err) {
+		// The daemon may be unavailable; the next check tries again.
+		return
+	}
+	exit := &Exit{Name: e.status.Name, ContainerID: id, ExitCode: -1, Removed: err != nil}
+	if err == nil {
+		if inspect.State != nil && (inspect.State.Running || inspect.State.Restarting) {
+			s.update(e, func(status *Status) {
+				status.State = Running
+				status.ContainerID = inspect.ID
+				status.Err = nil
+			})
+			return
+		}
+		if inspect.State != nil {
+			exit.ExitCode = inspect.State.ExitCode
+			exit.OOMKilled = inspect.State.OOMKilled
+		}
+	}
+	if state == Starting && (exit.Removed || inspect.State == nil || inspect.State.StartedAt == "" || inspect.State.StartedAt == "0001-01-01T00:00:00Z") {
+		// A container that never ran is started without counting as a restart.
+		s.restart(ctx, e, id, false)
+		return
+	}
+
+	now := s.clock.Now()
+	s.mu.Lock()
+	recent := e.restarts[:0]
+	for _, at := range e.restarts {
+		if now.Sub(at) < s.restartWindow {
+			recent = append(recent, at)
+		}
+	}
+	e.restarts = recent
+	exit.Restarts = len(recent)
+	exit.Config = e.config
+	s.mu.Unlock()
+
+	if s.maxRestarts > 0 && exit.Restarts >= s.maxRestarts {
+		s.update(e, func(status *Status) {
+			status.State = GaveUp
+			status.LastExit = exit
+		})
+		return
+	}
+	if s.onExit != nil && !s.onExit(ctx, exit) {
+		s.update(e, func(status *Status) {
+			status.State = Stopped
+			status.LastExit = exit
+		})
+		return
+	}
+	s.update(e, func(status *Status) {
+		status.State = Restarting
+		status.LastExit = exit
+	})
+	if err := s.clock.Sleep(ctx, s.backoff(exit.Restarts)); err != nil {
+		return
+	}
+	s.restart(ctx, e, id, true)
+}
+
+// backoff returns the wait before a restart, given the number of recent restarts.
+func (s *Supervisor) backoff(restarts int) time.Duration {
+	backoff := s.initialBackoff
+	for i := 0; i < restarts && backoff < s.maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, s.maxBackoff)
+}
+
+// restart replaces the container of an entry, unless it was removed from the supervisor meanwhile. count records
+// the replacement as a restart.
+func (s *Supervisor) restart(ctx context.Context, e *entry, oldID string, count bool) {
+	s.mu.Lock()
+	if e.removed {
+		s.mu.Unlock()
+		return
+	}
+	cfg := e.config.Clone()
+	s.mu.Unlock()
+
+	err := s.replace(ctx, oldID, cfg)
+	s.update(e, func(status *Status) {
+		if err != nil {
+			// The next check tries again.
+			status.Err = err
+			return
+		}
+		if count {
+			e.restarts = append(e.restarts, s.clock.Now())
+			status.Restarts++
+		}
+		status.State = Running
+		status.ContainerID = cfg.Id
+		status.Err = nil
+	})
+}
+
+// update changes the status of a container and reports state changes.
+func (s *Supervisor) update(e *entry, fn func(status *Status)) {
+	s.mu.Lock()
+	previous := e.status.State
+	fn(&e.status)
+	status := e.status
+	s.mu.Unlock()
+	if s.onChange != nil && status.State != previous {
+		s.onChange(status)
+	}
+}
+
+// inspectContainer inspects a container by ID or name.
+func (s *Supervisor) inspectContainer(ctx context.Context, id string) (types.ContainerJSON, error) {
+	return s.client.ContainerInspect(ctx, &container.ContainerConfig{Id: id})
+}
+
+// replaceContainer removes the old container, if it still exists, and creates and starts a new one.
+func (s *Supervisor) replaceContainer(ctx context.Context, oldID string, cfg *container.ContainerConfig) error {
+	if err := s.client.ContainerRemove(ctx, &container.ContainerConfig{Id: oldID}, removeoptions.Force()); err != nil && !client.IsErrNotFound(err) {
+		return &errdefs.ContainerError{ID: cfg.Name, Op: "remove", Message: err.Error(), Err: err}
+	}
+	cfg.Id = ""
+	if err := s.client.ContainerCreate(ctx, cfg); err != nil {
+		return err
+	}
+	return s.client.ContainerStart(ctx, cfg)
+}
@@ -0,0 +1,234 @@
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/clock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/docker/docker/api/types"
+	dockerErrdefs "github.com/docker/docker/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDaemon holds the containers seen by a supervisor under test.
+type fakeDaemon struct {
+	mu         sync.Mutex
+	containers map[string]*types.ContainerState
+	replaced   []*container.ContainerConfig
+}
+
+func newTestSupervisor(t *testing.T, setOptFns ...SetSupervisorOptFn) (*Supervisor, *fakeDaemon, *clock.Fake) {
+	fake := clock.NewFake(time.Date(2026, time.March, 1, 8, 0, 0, 0, time.UTC))
+	daemon := &fakeDaemon{containers: make(map[string]*types.ContainerState)}
+	s := New(nil, append([]SetSupervisorOptFn{WithClock(fake)}, setOptFns...)...)
+	s.inspect = func(ctx context.Context, id string) (types.ContainerJSON, error) {
+		daemon.mu.Lock()
+		defer daemon.mu.Unlock()
+		state, ok := daemon.containers[id]
+		if !ok {
+			return types.ContainerJSON{}, dockerErrdefs.NotFound(fmt.Errorf("no such container: %s", id))
+		}
+		return types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{ID: id, State: state}}, nil
+	}
+	s.replace = func(ctx context.Context, oldID string, cfg *container.ContainerConfig) error {
+		daemon.mu.Lock()
+		defer daemon.mu.Unlock()
+		delete(daemon.containers, oldID)
+		daemon.replaced = append(daemon.replaced, cfg)
+		cfg.Id = fmt.Sprintf("%s-%d", cfg.Name, len(daemon.replaced))
+		daemon.containers[cfg.Id] = &types.ContainerState{Running: true, StartedAt: "2026-03-01T08:00:00Z"}
+		return nil
+	}
+	return s, daemon, fake
+}
+
+// exit marks a container as exited.
+func (d *fakeDaemon) exit(id string, exitCode int, oomKilled bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.containers[id] = &types.ContainerState{ExitCode: exitCode, OOMKilled: oomKilled, StartedAt: "2026-03-01T08:00:00Z"}
+}
+
+// checkAfter runs a check that waits for a restart backoff, advancing the clock by backoff.
+func checkAfter(t *testing.T, s *Supervisor, fake *clock.Fake, name string, backoff time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.check(context.Background(), s.entries[name])
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, fake.BlockUntil(ctx, 1))
+	fake.Advance(backoff - time.Millisecond)
+	assert.Equal(t, 1, fake.Waiters())
+	fake.Advance(time.Millisecond)
+	<-done
+}
+
+func TestAddValidation(t *testing.T) {
+	s := New(nil)
+	assert.True(t, errdefs.IsInvalidConfig(s.Add(nil)))
+	assert.True(t, errdefs.IsInvalidConfig(s.Add(&container.ContainerConfig{Name: "worker"})))
+	require.NoError(t, s.Add(container.NewConfig("worker")))
+	assert.True(t, errdefs.IsInvalidConfig(s.Add(container.NewConfig("worker"))))
+}
+
+func TestSupervisorRestarts(t *testing.T) {
+	var states []State
+	s, daemon, fake := newTestSupervisor(t,
+		WithBackoff(time.Second, 3*time.Second),
+		WithMaxRestarts(3, time.Hour),
+		OnChange(func(status Status) { states = append(states, status.State) }),
+	)
+	require.NoError(t, s.Add(container.NewConfig("worker")))
+
+	// A missing container is created right away.
+	s.check(context.Background(), s.entries["worker"])
+	status, _ := s.Status("worker")
+	assert.Equal(t, Running, status.State)
+	assert.Equal(t, "worker-1", status.ContainerID)
+	assert.Equal(t, 0, status.Restarts)
+	assert.Equal(t, "worker", daemon.replaced[0].Options.Labels[LabelSupervisor])
+
+	// Running containers are left alone.
+	s.check(context.Background(), s.entries["worker"])
+	assert.Len(t, daemon.replaced, 1)
+
+	// Exits are restarted with a doubling backoff, up to the maximum.
+	daemon.exit("worker-1", 137, true)
+	checkAfter(t, s, fake, "worker", time.Second)
+	status, _ = s.Status("worker")
+	assert.Equal(t, "worker-2", status.ContainerID)
+	assert.Equal(t, 1, status.Restarts)
+	assert.True(t, status.LastExit.OOMKilled)
+	assert.Equal(t, 137, status.LastExit.ExitCode)
+
+	daemon.mu.Lock()
+	delete(daemon.containers, "worker-2")
+	daemon.mu.Unlock()
+	checkAfter(t, s, fake, "worker", 2*time.Second)
+	status, _ = s.Status("worker")
+	assert.True(t, status.LastExit.Removed)
+
+	daemon.exit("worker-3", 1, false)
+	checkAfter(t, s, fake, "worker", 3*time.Second)
+
+	// The fourth exit within the window gives up.
+	daemon.exit("worker-4", 1, false)
+	s.check(context.Background(), s.entries["worker"])
+	status, _ = s.Status("worker")
+	assert.Equal(t, GaveUp, status.State)
+	assert.Equal(t, 3, status.Restarts)
+	assert.Len(t, daemon.replaced, 4)
+	assert.Equal(t, []State{Running, Restarting, Running, Restarting, Running, Restarting, Running, GaveUp}, states)
+
+	// A container started again by someone else is supervised again.
+	daemon.mu.Lock()
+	daemon.containers["worker-4"].Running = true
+	daemon.mu.Unlock()
+	s.check(context.Background(), s.entries["worker"])
+	status, _ = s.Status("worker")
+	assert.Equal(t, Running, status.State)
+}
+
+func TestSupervisorExitHandler(t *testing.T) {
+	s, daemon, fake := newTestSupervisor(t, WithExitHandler(func(ctx context.Context, exit *Exit) bool {
+		if exit.OOMKilled {
+			exit.Config.SetHostOptions(hostoptions.Memory(1 << 30))
+			return true
+		}
+		return exit.ExitCode != 0
+	}))
+	require.NoError(t, s.Add(container.NewConfig("worker")))
+	s.check(context.Background(), s.entries["worker"])
+
+	daemon.exit("worker-1", 137, true)
+	checkAfter(t, s, fake, "worker", DefaultInitialBackoff)
+	assert.Equal(t, int64(1<<30), daemon.replaced[1].HostOptions.Memory)
+
+	daemon.exit("worker-2", 0, false)
+	s.check(context.Background(), s.entries["worker"])
+	status, _ := s.Status("worker")
+	assert.Equal(t, Stopped, status.State)
+	assert.Len(t, daemon.replaced, 2)
+}
+
+func TestSupervisorAdoptsContainer(t *testing.T) {
+	s, daemon, _ := newTestSupervisor(t)
+	daemon.containers["worker"] = &types.ContainerState{Running: true, StartedAt: "2026-03-01T07:00:00Z"}
+	require.NoError(t, s.Add(container.NewConfig("worker")))
+	s.check(context.Background(), s.entries["worker"])
+
+	statuses := s.Statuses()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, Running, statuses[0].State)
+	assert.Equal(t, "worker", statuses[0].ContainerID)
+	assert.Empty(t, daemon.replaced)
+
+	s.Remove("worker")
+	_, ok := s.Status("worker")
+	assert.False(t, ok)
+}
+
+func TestSupervisorRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	client, err := godock.NewClient(ctx)
+	if err != nil {
+		t.Skipf("Docker daemon is not running: %v", err)
+	}
+	img := image.NewConfig(godock.DefaultDoctorProbeImage)
+	require.NoError(t, client.EnsureImage(ctx, img, godock.PullPolicyIfNotPresent))
+
+	worker := container.NewConfig("godock-supervisor-" + godock.GenerateRandomString(8))
+	worker.SetContainerOptions(containeroptions.Image(img), containeroptions.CMD("sleep", "300"))
+	s := New(client, WithBackoff(100*time.Millisecond, time.Second), WithCheckInterval(time.Second))
+	require.NoError(t, s.Add(worker))
+	defer func() {
+		status, _ := s.Status(worker.Name)
+		client.ContainerRemove(context.WithoutCancel(ctx), &container.ContainerConfig{Id: status.ContainerID}, removeoptions.Force())
+	}()
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(runCtx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	running := func() Status {
+		var status Status
+		require.Eventually(t, func() bool {
+			status, _ = s.Status(worker.Name)
+			return status.State == Running && status.ContainerID != worker.Name
+		}, 30*time.Second, 100*time.Millisecond)
+		return status
+	}
+	first := running()
+
+	// A container removed behind the supervisor's back is recreated.
+	require.NoError(t, client.ContainerRemove(ctx, &container.ContainerConfig{Id: first.ContainerID}, removeoptions.Force()))
+	require.Eventually(t, func() bool {
+		status, _ := s.Status(worker.Name)
+		return status.Restarts == 1 && status.State == Running
+	}, 30*time.Second, 100*time.Millisecond)
+	second := running()
+	assert.NotEqual(t, first.ContainerID, second.ContainerID)
+	assert.True(t, second.LastExit.Removed)
+}
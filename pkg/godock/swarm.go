@@ -0,0 +1,207 @@
+package godock
+
+import (
+	"context"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/nodeoptions"
+	"github.com/aptd3v/godock/pkg/godock/swarmoptions"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/client"
+	dockerErrdefs "github.com/docker/docker/errdefs"
+)
+
+// DefaultSwarmListenAddr is the listen address used by SwarmInit and SwarmJoin when none is configured.
+const DefaultSwarmListenAddr = "0.0.0.0:2377"
+
+// swarmError wraps an error returned by a swarm or node operation.
+func swarmError(resourceType, id, op string, err error) error {
+	if id != "" && client.IsErrNotFound(err) {
+		return &errdefs.ResourceNotFoundError{ResourceType: resourceType, ID: id, Err: err}
+	}
+	if op == "create" && dockerErrdefs.IsConflict(err) {
+		return &errdefs.ResourceExistsError{ResourceType: resourceType, ID: id, Err: err}
+	}
+	return &errdefs.SwarmError{ID: id, Op: op, Message: err.Error(), Err: err}
+}
+
+/*
+SwarmInit turns the daemon into the first manager of a new swarm and returns the id of the node.
+
+Usage example:
+
+	nodeID, err := client.SwarmInit(ctx,
+		swarmoptions.AdvertiseAddr("192.168.1.10"),
+		swarmoptions.DefaultAddrPool(24, "10.20.0.0/16"),
+	)
+	if err != nil {
+		return err
+	}
+	sw, err := client.SwarmInspect(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Println("join workers with", sw.JoinTokens.Worker)
+*/
+func (c *Client) SwarmInit(ctx context.Context, initOptFns ...swarmoptions.InitOptFn) (string, error) {
+	req := swarm.InitRequest{
+		ListenAddr: DefaultSwarmListenAddr,
+	}
+	for _, fn := range initOptFns {
+		if fn != nil {
+			fn(&req)
+		}
+	}
+	nodeID, err := c.wrapped.SwarmInit(ctx, req)
+	if err != nil {
+		return "", swarmError("swarm", "", "init", err)
+	}
+	return nodeID, nil
+}
+
+/*
+SwarmJoin joins the daemon to an existing swarm, as a worker or manager depending on the join token.
+remoteAddrs are the addresses of one or more managers of the swarm.
+
+Usage example:
+
+	err := client.SwarmJoin(ctx, workerToken, []string{"192.168.1.10:2377"},
+		swarmoptions.JoinAdvertiseAddr("eth0"),
+	)
+*/
+func (c *Client) SwarmJoin(ctx context.Context, joinToken string, remoteAddrs []string, joinOptFns ...swarmoptions.JoinOptFn) error {
+	if joinToken == "" {
+		return &errdefs.ValidationError{Field: "joinToken", Message: "join token cannot be empty"}
+	}
+	if len(remoteAddrs) == 0 {
+		return &errdefs.ValidationError{Field: "remoteAddrs", Message: "at least one manager address is required"}
+	}
+	req := swarm.JoinRequest{
+		ListenAddr:  DefaultSwarmListenAddr,
+		RemoteAddrs: remoteAddrs,
+		JoinToken:   joinToken,
+	}
+	for _, fn := range joinOptFns {
+		if fn != nil {
+			fn(&req)
+		}
+	}
+	if err := c.wrapped.SwarmJoin(ctx, req); err != nil {
+		return swarmError("swarm", "", "join", err)
+	}
+	return nil
+}
+
+// SwarmLeave makes the daemon leave the swarm. Managers must set force, which can break the quorum of the swarm.
+func (c *Client) SwarmLeave(ctx context.Context, force bool) error {
+	if err := c.wrapped.SwarmLeave(ctx, force); err != nil {
+		return swarmError("swarm", "", "leave", err)
+	}
+	return nil
+}
+
+// SwarmInspect returns the swarm the daemon manages, including its join tokens. It fails on worker nodes.
+func (c *Client) SwarmInspect(ctx context.Context) (*swarm.Swarm, error) {
+	sw, err := c.wrapped.SwarmInspect(ctx)
+	if err != nil {
+		return nil, swarmError("swarm", "", "inspect", err)
+	}
+	return &sw, nil
+}
+
+// NodeListOptionFn is a function that filters the nodes returned by NodeList.
+type NodeListOptionFn func(*types.NodeListOptions)
+
+// WithNodeFilter adds a raw filter to the node list, see the docker node ls documentation for the keys.
+func WithNodeFilter(key, value string) NodeListOptionFn {
+	return func(opts *types.NodeListOptions) {
+		addFilters(&opts.Filters, []Filter{{Key: key, Value: value}})
+	}
+}
+
+// WithNodeRole lists only managers or only workers.
+func WithNodeRole(role swarm.NodeRole) NodeListOptionFn {
+	return WithNodeFilter("role", string(role))
+}
+
+// WithNodeLabel lists only nodes with the given node label. An empty value matches any value.
+func WithNodeLabel(key, value string) NodeListOptionFn {
+	if value == "" {
+		return WithNodeFilter("node.label", key)
+	}
+	return WithNodeFilter("node.label", key+"="+value)
+}
+
+// WithNodeName lists only nodes whose name or hostname matches.
+func WithNodeName(name string) NodeListOptionFn {
+	return WithNodeFilter("name", name)
+}
+
+/*
+NodeList lists the nodes of the swarm. It must be called on a manager.
+
+Usage example:
+
+	workers, err := client.NodeList(ctx, godock.WithNodeRole(swarm.NodeRoleWorker))
+*/
+func (c *Client) NodeList(ctx context.Context, nodeListOptionFns ...NodeListOptionFn) ([]swarm.Node, error) {
+	opts := types.NodeListOptions{
+		Filters: filters.NewArgs(),
+	}
+	for _, fn := range nodeListOptionFns {
+		if fn != nil {
+			fn(&opts)
+		}
+	}
+	nodes, err := c.wrapped.NodeList(ctx, opts)
+	if err != nil {
+		return nil, swarmError("node", "", "list", err)
+	}
+	return nodes, nil
+}
+
+// NodeInspect returns a node of the swarm by id or name.
+func (c *Client) NodeInspect(ctx context.Context, nodeID string) (*swarm.Node, error) {
+	node, _, err := c.wrapped.NodeInspectWithRaw(ctx, nodeID)
+	if err != nil {
+		return nil, swarmError("node", nodeID, "inspect", err)
+	}
+	return &node, nil
+}
+
+/*
+NodeUpdate changes the spec of a node, keeping the settings that are not changed by the options.
+
+Usage example:
+
+	err := client.NodeUpdate(ctx, nodeID,
+		nodeoptions.Drain(),
+		nodeoptions.Label("maintenance", "2024-06-01"),
+	)
+*/
+func (c *Client) NodeUpdate(ctx context.Context, nodeID string, setNodeSpecFns ...nodeoptions.SetNodeSpecFn) error {
+	node, err := c.NodeInspect(ctx, nodeID)
+	if err != nil {
+		return err
+	}
+	spec := node.Spec
+	for _, fn := range setNodeSpecFns {
+		if fn != nil {
+			fn(&spec)
+		}
+	}
+	if err := c.wrapped.NodeUpdate(ctx, node.ID, node.Version, spec); err != nil {
+		return swarmError("node", nodeID, "update", err)
+	}
+	return nil
+}
+
+// NodeRemove removes a node from the swarm. A node that is still up must be removed with force.
+func (c *Client) NodeRemove(ctx context.Context, nodeID string, force bool) error {
+	if err := c.wrapped.NodeRemove(ctx, nodeID, types.NodeRemoveOptions{Force: force}); err != nil {
+		return swarmError("node", nodeID, "remove", err)
+	}
+	return nil
+}
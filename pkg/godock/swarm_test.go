@@ -0,0 +1,37 @@
+package godock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwarmJoinValidation(t *testing.T) {
+	c := &Client{}
+	ctx := context.Background()
+
+	assert.True(t, errdefs.IsInvalidConfig(c.SwarmJoin(ctx, "", []string{"10.0.0.1:2377"})))
+	assert.True(t, errdefs.IsInvalidConfig(c.SwarmJoin(ctx, "SWMTKN-1-abc", nil)))
+}
+
+func TestSwarmError(t *testing.T) {
+	err := swarmError("node", "node1", "update", errors.New("update out of sequence"))
+	var swarmErr *errdefs.SwarmError
+	assert.ErrorAs(t, err, &swarmErr)
+	assert.Equal(t, "swarm node1: update failed: update out of sequence", err.Error())
+}
+
+func TestSecretAndConfigCreateValidation(t *testing.T) {
+	c := &Client{}
+	ctx := context.Background()
+
+	_, err := c.SecretCreate(ctx, "", []byte("x"))
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	_, err = c.SecretCreate(ctx, "db_password", nil)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	_, err = c.ConfigCreate(ctx, "", []byte("x"))
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
@@ -0,0 +1,117 @@
+package swarmoptions
+
+import (
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// InitOptFn is a function that configures the initialization of a new swarm.
+type InitOptFn func(req *swarm.InitRequest)
+
+// JoinOptFn is a function that configures joining an existing swarm.
+type JoinOptFn func(req *swarm.JoinRequest)
+
+// ListenAddr sets the address the node listens on for inter-manager communication, e.g. "0.0.0.0:2377".
+func ListenAddr(addr string) InitOptFn {
+	return func(req *swarm.InitRequest) {
+		req.ListenAddr = addr
+	}
+}
+
+// AdvertiseAddr sets the address advertised to other nodes, e.g. "192.168.1.10:2377" or an interface name such as "eth0".
+func AdvertiseAddr(addr string) InitOptFn {
+	return func(req *swarm.InitRequest) {
+		req.AdvertiseAddr = addr
+	}
+}
+
+// DataPathAddr sets the address or interface used for data path traffic, separating it from management traffic.
+func DataPathAddr(addr string) InitOptFn {
+	return func(req *swarm.InitRequest) {
+		req.DataPathAddr = addr
+	}
+}
+
+// DataPathPort sets the UDP port used for data path (VXLAN) traffic. The default is 4789.
+func DataPathPort(port uint32) InitOptFn {
+	return func(req *swarm.InitRequest) {
+		req.DataPathPort = port
+	}
+}
+
+// ForceNewCluster creates a new single-manager cluster from the current state, to recover from a lost quorum.
+func ForceNewCluster() InitOptFn {
+	return func(req *swarm.InitRequest) {
+		req.ForceNewCluster = true
+	}
+}
+
+// Autolock encrypts the manager keys at rest, so managers must be unlocked with the unlock key after a restart.
+func Autolock() InitOptFn {
+	return func(req *swarm.InitRequest) {
+		req.AutoLockManagers = true
+	}
+}
+
+// DefaultAddrPool sets the address pools overlay network subnets are allocated from, and the size of each subnet.
+// A subnetSize of 0 uses the default of 24.
+func DefaultAddrPool(subnetSize uint32, pools ...string) InitOptFn {
+	return func(req *swarm.InitRequest) {
+		req.DefaultAddrPool = append(req.DefaultAddrPool, pools...)
+		req.SubnetSize = subnetSize
+	}
+}
+
+// Availability sets the availability of the first manager node, e.g. swarm.NodeAvailabilityDrain
+// to keep tasks off a dedicated manager.
+func Availability(availability swarm.NodeAvailability) InitOptFn {
+	return func(req *swarm.InitRequest) {
+		req.Availability = availability
+	}
+}
+
+// TaskHistoryLimit sets how many finished tasks are kept per service slot.
+func TaskHistoryLimit(limit int64) InitOptFn {
+	return func(req *swarm.InitRequest) {
+		req.Spec.Orchestration.TaskHistoryRetentionLimit = &limit
+	}
+}
+
+// Labels sets labels on the swarm.
+func Labels(labels map[string]string) InitOptFn {
+	return func(req *swarm.InitRequest) {
+		if req.Spec.Labels == nil {
+			req.Spec.Labels = make(map[string]string)
+		}
+		for key, value := range labels {
+			req.Spec.Labels[key] = value
+		}
+	}
+}
+
+// JoinListenAddr sets the address the joining node listens on for inter-manager communication.
+func JoinListenAddr(addr string) JoinOptFn {
+	return func(req *swarm.JoinRequest) {
+		req.ListenAddr = addr
+	}
+}
+
+// JoinAdvertiseAddr sets the address the joining node advertises to other nodes.
+func JoinAdvertiseAddr(addr string) JoinOptFn {
+	return func(req *swarm.JoinRequest) {
+		req.AdvertiseAddr = addr
+	}
+}
+
+// JoinDataPathAddr sets the address or interface the joining node uses for data path traffic.
+func JoinDataPathAddr(addr string) JoinOptFn {
+	return func(req *swarm.JoinRequest) {
+		req.DataPathAddr = addr
+	}
+}
+
+// JoinAvailability sets the availability of the joining node.
+func JoinAvailability(availability swarm.NodeAvailability) JoinOptFn {
+	return func(req *swarm.JoinRequest) {
+		req.Availability = availability
+	}
+}
@@ -0,0 +1,48 @@
+package swarmoptions
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitOptions(t *testing.T) {
+	req := &swarm.InitRequest{}
+	for _, fn := range []InitOptFn{
+		ListenAddr("0.0.0.0:2377"),
+		AdvertiseAddr("eth0"),
+		DataPathPort(7789),
+		Autolock(),
+		DefaultAddrPool(26, "10.20.0.0/16", "10.30.0.0/16"),
+		Availability(swarm.NodeAvailabilityDrain),
+		TaskHistoryLimit(2),
+		Labels(map[string]string{"env": "prod"}),
+	} {
+		fn(req)
+	}
+
+	assert.Equal(t, "eth0", req.AdvertiseAddr)
+	assert.Equal(t, uint32(7789), req.DataPathPort)
+	assert.True(t, req.AutoLockManagers)
+	assert.Equal(t, []string{"10.20.0.0/16", "10.30.0.0/16"}, req.DefaultAddrPool)
+	assert.Equal(t, uint32(26), req.SubnetSize)
+	assert.Equal(t, swarm.NodeAvailabilityDrain, req.Availability)
+	assert.Equal(t, int64(2), *req.Spec.Orchestration.TaskHistoryRetentionLimit)
+	assert.Equal(t, "prod", req.Spec.Labels["env"])
+}
+
+func TestJoinOptions(t *testing.T) {
+	req := &swarm.JoinRequest{}
+	JoinListenAddr("0.0.0.0:2377")(req)
+	JoinAdvertiseAddr("10.0.0.2")(req)
+	JoinDataPathAddr("eth1")(req)
+	JoinAvailability(swarm.NodeAvailabilityPause)(req)
+
+	assert.Equal(t, swarm.JoinRequest{
+		ListenAddr:    "0.0.0.0:2377",
+		AdvertiseAddr: "10.0.0.2",
+		DataPathAddr:  "eth1",
+		Availability:  swarm.NodeAvailabilityPause,
+	}, *req)
+}
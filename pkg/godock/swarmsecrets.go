@@ -0,0 +1,175 @@
+package godock
+
+import (
+	"context"
+
+	"github.com/aptd3v/godock/pkg/godock/configoptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/secretoptions"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+/*
+SecretCreate creates a swarm secret and returns its id. Secrets are immutable: to rotate a secret,
+create a new one and update the services using it. It must be called on a manager.
+
+Usage example:
+
+	id, err := client.SecretCreate(ctx, "db_password", []byte(password),
+		secretoptions.Label("app", "billing"),
+	)
+*/
+func (c *Client) SecretCreate(ctx context.Context, name string, data []byte, setSecretSpecFns ...secretoptions.SetSecretSpecFn) (string, error) {
+	if name == "" {
+		return "", &errdefs.ValidationError{Field: "name", Message: "secret name cannot be empty"}
+	}
+	spec := swarm.SecretSpec{
+		Annotations: swarm.Annotations{Name: name},
+		Data:        data,
+	}
+	for _, fn := range setSecretSpecFns {
+		if fn != nil {
+			fn(&spec)
+		}
+	}
+	if spec.Driver == nil && len(spec.Data) == 0 {
+		return "", &errdefs.ValidationError{Field: "data", Message: "secret data cannot be empty unless a driver is set"}
+	}
+	res, err := c.wrapped.SecretCreate(ctx, spec)
+	if err != nil {
+		return "", swarmError("secret", name, "create", err)
+	}
+	return res.ID, nil
+}
+
+// SecretInspect returns a swarm secret by id or name. The secret data is never returned by the daemon.
+func (c *Client) SecretInspect(ctx context.Context, idOrName string) (*swarm.Secret, error) {
+	secret, _, err := c.wrapped.SecretInspectWithRaw(ctx, idOrName)
+	if err != nil {
+		return nil, swarmError("secret", idOrName, "inspect", err)
+	}
+	return &secret, nil
+}
+
+// SecretListOptionFn is a function that filters the secrets returned by SecretList.
+type SecretListOptionFn func(*types.SecretListOptions)
+
+// WithSecretFilter adds a raw filter to the secret list: "id", "label", "name" or "names".
+func WithSecretFilter(key, value string) SecretListOptionFn {
+	return func(opts *types.SecretListOptions) {
+		addFilters(&opts.Filters, []Filter{{Key: key, Value: value}})
+	}
+}
+
+// WithSecretLabel lists only secrets with the given label. An empty value matches any value.
+func WithSecretLabel(key, value string) SecretListOptionFn {
+	if value == "" {
+		return WithSecretFilter("label", key)
+	}
+	return WithSecretFilter("label", key+"="+value)
+}
+
+// SecretList lists the swarm secrets.
+func (c *Client) SecretList(ctx context.Context, secretListOptionFns ...SecretListOptionFn) ([]swarm.Secret, error) {
+	opts := types.SecretListOptions{Filters: filters.NewArgs()}
+	for _, fn := range secretListOptionFns {
+		if fn != nil {
+			fn(&opts)
+		}
+	}
+	secrets, err := c.wrapped.SecretList(ctx, opts)
+	if err != nil {
+		return nil, swarmError("secret", "", "list", err)
+	}
+	return secrets, nil
+}
+
+// SecretRemove removes a swarm secret by id or name. It fails while services still use the secret.
+func (c *Client) SecretRemove(ctx context.Context, idOrName string) error {
+	if err := c.wrapped.SecretRemove(ctx, idOrName); err != nil {
+		return swarmError("secret", idOrName, "remove", err)
+	}
+	return nil
+}
+
+/*
+ConfigCreate creates a swarm config and returns its id. Configs are immutable and, unlike secrets,
+are not encrypted at rest. It must be called on a manager.
+
+Usage example:
+
+	id, err := client.ConfigCreate(ctx, "nginx.conf", conf,
+		configoptions.Label("app", "frontend"),
+	)
+*/
+func (c *Client) ConfigCreate(ctx context.Context, name string, data []byte, setConfigSpecFns ...configoptions.SetConfigSpecFn) (string, error) {
+	if name == "" {
+		return "", &errdefs.ValidationError{Field: "name", Message: "config name cannot be empty"}
+	}
+	spec := swarm.ConfigSpec{
+		Annotations: swarm.Annotations{Name: name},
+		Data:        data,
+	}
+	for _, fn := range setConfigSpecFns {
+		if fn != nil {
+			fn(&spec)
+		}
+	}
+	res, err := c.wrapped.ConfigCreate(ctx, spec)
+	if err != nil {
+		return "", swarmError("config", name, "create", err)
+	}
+	return res.ID, nil
+}
+
+// ConfigInspect returns a swarm config by id or name, including its data.
+func (c *Client) ConfigInspect(ctx context.Context, idOrName string) (*swarm.Config, error) {
+	config, _, err := c.wrapped.ConfigInspectWithRaw(ctx, idOrName)
+	if err != nil {
+		return nil, swarmError("config", idOrName, "inspect", err)
+	}
+	return &config, nil
+}
+
+// ConfigListOptionFn is a function that filters the configs returned by ConfigList.
+type ConfigListOptionFn func(*types.ConfigListOptions)
+
+// WithConfigFilter adds a raw filter to the config list: "id", "label", "name" or "names".
+func WithConfigFilter(key, value string) ConfigListOptionFn {
+	return func(opts *types.ConfigListOptions) {
+		addFilters(&opts.Filters, []Filter{{Key: key, Value: value}})
+	}
+}
+
+// WithConfigLabel lists only configs with the given label. An empty value matches any value.
+func WithConfigLabel(key, value string) ConfigListOptionFn {
+	if value == "" {
+		return WithConfigFilter("label", key)
+	}
+	return WithConfigFilter("label", key+"="+value)
+}
+
+// ConfigList lists the swarm configs.
+func (c *Client) ConfigList(ctx context.Context, configListOptionFns ...ConfigListOptionFn) ([]swarm.Config, error) {
+	opts := types.ConfigListOptions{Filters: filters.NewArgs()}
+	for _, fn := range configListOptionFns {
+		if fn != nil {
+			fn(&opts)
+		}
+	}
+	configs, err := c.wrapped.ConfigList(ctx, opts)
+	if err != nil {
+		return nil, swarmError("config", "", "list", err)
+	}
+	return configs, nil
+}
+
+// ConfigRemove removes a swarm config by id or name. It fails while services still use the config.
+func (c *Client) ConfigRemove(ctx context.Context, idOrName string) error {
+	if err := c.wrapped.ConfigRemove(ctx, idOrName); err != nil {
+		return swarmError("config", idOrName, "remove", err)
+	}
+	return nil
+}
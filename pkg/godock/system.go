@@ -0,0 +1,72 @@
+package godock
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/system"
+)
+
+// SystemInfo returns system-wide information about the docker host,
+// such as the number of containers and images, the storage driver, cgroup version and available resources.
+func (c *Client) SystemInfo(ctx context.Context) (*system.Info, error) {
+	info, err := c.wrapped.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system info: %w", err)
+	}
+	return &info, nil
+}
+
+// SystemVersion returns version information about the docker daemon and its components.
+func (c *Client) SystemVersion(ctx context.Context) (*types.Version, error) {
+	version, err := c.wrapped.ServerVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system version: %w", err)
+	}
+	return &version, nil
+}
+
+// DiskUsageOptionFn is a function that configures the disk usage operation.
+type DiskUsageOptionFn func(*types.DiskUsageOptions)
+
+// WithDiskUsageType limits the disk usage report to the given object type.
+// Call it multiple times to include several types. Without it all object types are reported.
+func WithDiskUsageType(objectType types.DiskUsageObject) DiskUsageOptionFn {
+	return func(opts *types.DiskUsageOptions) {
+		opts.Types = append(opts.Types, objectType)
+	}
+}
+
+// DiskUsage returns the disk space used by images, containers, volumes and the build cache.
+func (c *Client) DiskUsage(ctx context.Context, diskUsageOptionFns ...DiskUsageOptionFn) (*types.DiskUsage, error) {
+	opts := types.DiskUsageOptions{}
+	for _, fn := range diskUsageOptionFns {
+		if fn != nil {
+			fn(&opts)
+		}
+	}
+	usage, err := c.wrapped.DiskUsage(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disk usage: %w", err)
+	}
+	return &usage, nil
+}
+
+// Ping pings the docker daemon and returns the details it reports,
+// including the API version, OS type, builder version and swarm status.
+func (c *Client) Ping(ctx context.Context) (*types.Ping, error) {
+	ping, err := c.wrapped.Ping(ctx)
+	if err != nil {
+		return nil, &errdefs.DaemonNotRunningError{
+			Message: err.Error(),
+		}
+	}
+	return &ping, nil
+}
+
+// ClientVersion returns the API version used by the client after negotiation with the daemon.
+func (c *Client) ClientVersion() string {
+	return c.wrapped.ClientVersion()
+}
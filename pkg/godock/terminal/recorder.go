@@ -0,0 +1,176 @@
+package terminal
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Recorder captures the input and output of a session, for audit or debugging. An error returned by a
+// recorder ends the session, so that a recording is never silently incomplete.
+type Recorder interface {
+	// Start is called once when the session starts, with the size of the terminal, or 0 when it has none.
+	Start(width, height int) error
+	// Input is called with the bytes read from stdin, before they are sent to the container.
+	Input(p []byte) error
+	// Output is called with the bytes received from the container, before they are written to stdout.
+	Output(p []byte) error
+}
+
+// SetSessionOptFn is a function type that configures a Session.
+type SetSessionOptFn func(s *Session)
+
+/*
+WithRecorder records the session with recorder, such as an AsciicastRecorder.
+
+Usage example:
+
+	cast, err := os.Create("session.cast")
+	if err != nil {
+		return err
+	}
+	defer cast.Close()
+	session, err := client.ContainerExecAttachTerminal(ctx, app, execConfig,
+		terminal.WithRecorder(terminal.NewAsciicastRecorder(cast)),
+	)
+*/
+func WithRecorder(recorder Recorder) SetSessionOptFn {
+	return func(s *Session) {
+		s.recorder = recorder
+	}
+}
+
+// Default size of an asciicast recording of a session without a terminal.
+const (
+	defaultCastWidth  = 80
+	defaultCastHeight = 24
+)
+
+/*
+AsciicastRecorder writes a session as an asciicast v2 recording, with the timing of every input and output,
+which can be replayed with asciinema. Input is recorded too, so recordings may contain secrets typed in the
+session.
+*/
+type AsciicastRecorder struct {
+	mu     sync.Mutex
+	writer io.Writer
+	start  time.Time
+	// pending holds the end of an incomplete UTF-8 sequence per stream, since events must be valid strings.
+	pending map[string][]byte
+	now     func() time.Time
+}
+
+// NewAsciicastRecorder returns a recorder writing an asciicast v2 recording to writer.
+func NewAsciicastRecorder(writer io.Writer) *AsciicastRecorder {
+	return &AsciicastRecorder{writer: writer, pending: make(map[string][]byte), now: time.Now}
+}
+
+type asciicastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+func (r *AsciicastRecorder) Start(width, height int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if width <= 0 || height <= 0 {
+		width, height = defaultCastWidth, defaultCastHeight
+	}
+	r.start = r.now()
+	header := asciicastHeader{Version: 2, Width: width, Height: height, Timestamp: r.start.Unix()}
+	if term := os.Getenv("TERM"); term != "" {
+		header.Env = map[string]string{"TERM": term}
+	}
+	return r.writeLine(header)
+}
+
+func (r *AsciicastRecorder) Input(p []byte) error {
+	return r.event("i", p)
+}
+
+func (r *AsciicastRecorder) Output(p []byte) error {
+	return r.event("o", p)
+}
+
+func (r *AsciicastRecorder) event(kind string, p []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data := append(r.pending[kind], p...)
+	complete := utf8Complete(data)
+	r.pending[kind] = append([]byte(nil), data[complete:]...)
+	if complete == 0 {
+		return nil
+	}
+	elapsed := r.now().Sub(r.start).Seconds()
+	return r.writeLine([]interface{}{elapsed, kind, string(data[:complete])})
+}
+
+func (r *AsciicastRecorder) writeLine(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = r.writer.Write(append(b, '\n'))
+	return err
+}
+
+// utf8Complete returns the length of p without an incomplete UTF-8 sequence at its end.
+func utf8Complete(p []byte) int {
+	// A sequence is at most utf8.UTFMax bytes long, so only its last bytes can start an incomplete one.
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(p[i]) {
+			continue
+		}
+		if !utf8.FullRune(p[i:]) {
+			return i
+		}
+		break
+	}
+	return len(p)
+}
+
+// TypescriptRecorder writes the raw output of a session, like script(1) without timing. Input is not
+// recorded; with a TTY its echo is part of the output.
+type TypescriptRecorder struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+// NewTypescriptRecorder returns a recorder writing the raw session output to writer.
+func NewTypescriptRecorder(writer io.Writer) *TypescriptRecorder {
+	return &TypescriptRecorder{writer: writer}
+}
+
+func (r *TypescriptRecorder) Start(width, height int) error {
+	return nil
+}
+
+func (r *TypescriptRecorder) Input(p []byte) error {
+	return nil
+}
+
+func (r *TypescriptRecorder) Output(p []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err := r.writer.Write(p)
+	return err
+}
+
+// recordingWriter passes writes through to the recorder before the wrapped writer.
+type recordingWriter struct {
+	writer io.Writer
+	record func(p []byte) error
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	if err := w.record(p); err != nil {
+		return 0, err
+	}
+	return w.writer.Write(p)
+}
@@ -0,0 +1,92 @@
+package terminal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsciicastRecorder(t *testing.T) {
+	var cast bytes.Buffer
+	rec := NewAsciicastRecorder(&cast)
+	start := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	now := start
+	rec.now = func() time.Time { return now }
+
+	require.NoError(t, rec.Start(0, 0))
+	now = start.Add(500 * time.Millisecond)
+	require.NoError(t, rec.Input([]byte("ls\r")))
+	// "é" split across two writes is recorded once it is complete.
+	require.NoError(t, rec.Output([]byte("caf\xc3")))
+	now = start.Add(time.Second)
+	require.NoError(t, rec.Output([]byte("\xa9\r\n")))
+
+	lines := strings.Split(strings.TrimSpace(cast.String()), "\n")
+	require.Len(t, lines, 4)
+	var header asciicastHeader
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &header))
+	assert.Equal(t, 2, header.Version)
+	assert.Equal(t, 80, header.Width)
+	assert.Equal(t, 24, header.Height)
+	assert.Equal(t, start.Unix(), header.Timestamp)
+
+	var event []interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &event))
+	assert.Equal(t, []interface{}{0.5, "i", "ls\r"}, event)
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &event))
+	assert.Equal(t, []interface{}{0.5, "o", "caf"}, event)
+	require.NoError(t, json.Unmarshal([]byte(lines[3]), &event))
+	assert.Equal(t, []interface{}{1.0, "o", "é\r\n"}, event)
+}
+
+func TestUTF8Complete(t *testing.T) {
+	assert.Equal(t, 3, utf8Complete([]byte("abc")))
+	assert.Equal(t, 1, utf8Complete([]byte("a\xe2\x82")))
+	assert.Equal(t, 4, utf8Complete([]byte("a\xe2\x82\xac")))
+	assert.Equal(t, 0, utf8Complete([]byte("\xf0\x9f")))
+	assert.Equal(t, 2, utf8Complete([]byte("a\xff")))
+}
+
+func TestSessionRecorder(t *testing.T) {
+	stdin, stdinWriter, err := os.Pipe()
+	require.NoError(t, err)
+	defer stdin.Close()
+	_, err = stdinWriter.WriteString("whoami\n")
+	require.NoError(t, err)
+	require.NoError(t, stdinWriter.Close())
+
+	var cast, typescript bytes.Buffer
+	output, outputWriter := io.Pipe()
+	session, err := NewSession(stdin, &fakeConn{output: outputWriter}, output, WithRecorder(NewAsciicastRecorder(&cast)))
+	require.NoError(t, err)
+	session.stdout = &typescript
+	require.NoError(t, session.Start())
+
+	scanner := bufio.NewScanner(&cast)
+	var kinds []string
+	for scanner.Scan() {
+		var event []interface{}
+		if json.Unmarshal(scanner.Bytes(), &event) == nil {
+			kinds = append(kinds, event[1].(string))
+		}
+	}
+	assert.Equal(t, []string{"i", "o"}, kinds)
+	assert.Equal(t, "received: whoami\n", typescript.String())
+}
+
+func TestTypescriptRecorder(t *testing.T) {
+	var out bytes.Buffer
+	rec := NewTypescriptRecorder(&out)
+	require.NoError(t, rec.Start(80, 24))
+	require.NoError(t, rec.Input([]byte("secret\n")))
+	require.NoError(t, rec.Output([]byte("$ ")))
+	assert.Equal(t, "$ ", out.String())
+}
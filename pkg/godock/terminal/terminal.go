@@ -11,57 +11,117 @@ import (
 // Session represents an interactive terminal session
 type Session struct {
 	stdin    *os.File
+	stdout   io.Writer
 	oldState *term.State
-	hijacked io.ReadWriteCloser
-	reader   io.Reader
-	resizeCh chan [2]uint
+	// restoreOutput restores the console mode of stdout changed by enableVirtualTerminal.
+	restoreOutput func() error
+	hijacked      io.ReadWriteCloser
+	reader        io.Reader
+	resizeCh      chan [2]uint
+	recorder      Recorder
 }
 
-// NewSession creates a new terminal session
-func NewSession(stdin *os.File, hijacked io.ReadWriteCloser, reader io.Reader) (*Session, error) {
+/*
+NewSession creates a new terminal session. When stdin is a terminal it is put in raw mode, and on Windows the
+console is set to interpret the escape sequences of the container's TTY. When stdin is not a terminal, such as
+a pipe in a CI pipeline, the streams are copied as they are.
+*/
+func NewSession(stdin *os.File, hijacked io.ReadWriteCloser, reader io.Reader, setOptFns ...SetSessionOptFn) (*Session, error) {
+	s := &Session{
+		stdin:    stdin,
+		stdout:   os.Stdout,
+		hijacked: hijacked,
+		reader:   reader,
+		resizeCh: make(chan [2]uint),
+	}
+	for _, set := range setOptFns {
+		if set != nil {
+			set(s)
+		}
+	}
+	if !term.IsTerminal(int(stdin.Fd())) {
+		return s, nil
+	}
+
 	oldState, err := term.MakeRaw(int(stdin.Fd()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to set terminal to raw mode: %w", err)
 	}
+	s.oldState = oldState
+	restoreOutput, err := enableVirtualTerminal(os.Stdout)
+	if err != nil {
+		term.Restore(int(stdin.Fd()), oldState)
+		return nil, fmt.Errorf("failed to set console mode: %w", err)
+	}
+	s.restoreOutput = restoreOutput
+	return s, nil
+}
 
-	return &Session{
-		stdin:    stdin,
-		oldState: oldState,
-		hijacked: hijacked,
-		reader:   reader,
-		resizeCh: make(chan [2]uint),
-	}, nil
+// IsTerminal reports whether the session runs on a terminal, in raw mode, rather than on plain streams.
+func (s *Session) IsTerminal() bool {
+	return s.oldState != nil
 }
 
-// Start begins the interactive session with bidirectional I/O
+// Start begins the session with bidirectional I/O. It returns once the container output ends. When stdin
+// ends first, the container sees end of file on its stdin and the output is still copied until it ends.
 func (s *Session) Start() error {
 	defer s.Close()
 
-	// Set up error channel
-	errCh := make(chan error, 1)
+	stdout, toContainer := s.stdout, io.Writer(s.hijacked)
+	if s.recorder != nil {
+		width, height, err := s.GetSize()
+		if err != nil {
+			width, height = 0, 0
+		}
+		if err := s.recorder.Start(width, height); err != nil {
+			return fmt.Errorf("failed to start recording: %w", err)
+		}
+		stdout = &recordingWriter{writer: stdout, record: s.recorder.Output}
+		toContainer = &recordingWriter{writer: toContainer, record: s.recorder.Input}
+	}
+
+	outCh := make(chan error, 1)
+	inCh := make(chan error, 1)
 
 	// Copy container output to stdout
 	go func() {
-		_, err := io.Copy(os.Stdout, s.reader)
-		errCh <- err
+		_, err := io.Copy(stdout, s.reader)
+		outCh <- err
 	}()
 
 	// Copy stdin to container
 	go func() {
-		_, err := io.Copy(s.hijacked, s.stdin)
-		errCh <- err
+		_, err := io.Copy(toContainer, s.stdin)
+		if cw, ok := s.hijacked.(interface{ CloseWrite() error }); ok && err == nil {
+			err = cw.CloseWrite()
+		}
+		inCh <- err
 	}()
 
-	// Wait for an error from either goroutine
-	if err := <-errCh; err != nil {
-		return fmt.Errorf("error during I/O: %w", err)
+	for {
+		select {
+		case err := <-outCh:
+			if err != nil {
+				return fmt.Errorf("error during I/O: %w", err)
+			}
+			return nil
+		case err := <-inCh:
+			if err != nil {
+				return fmt.Errorf("error during I/O: %w", err)
+			}
+			inCh = nil
+		}
 	}
-
-	return nil
 }
 
 // Close restores the terminal state and cleans up resources
 func (s *Session) Close() error {
+	if s.restoreOutput != nil {
+		if err := s.restoreOutput(); err != nil {
+			return fmt.Errorf("failed to restore console mode: %w", err)
+		}
+		s.restoreOutput = nil
+	}
 	if s.oldState != nil {
 		if err := term.Restore(int(s.stdin.Fd()), s.oldState); err != nil {
 			return fmt.Errorf("failed to restore terminal state: %w", err)
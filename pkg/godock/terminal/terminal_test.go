@@ -0,0 +1,61 @@
+package terminal
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConn is the container side of an exec: it echoes its stdin back on the output once stdin is closed.
+type fakeConn struct {
+	mu     sync.Mutex
+	stdin  bytes.Buffer
+	output *io.PipeWriter
+}
+
+func (c *fakeConn) Read(p []byte) (int, error) { return 0, io.EOF }
+
+func (c *fakeConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stdin.Write(p)
+}
+
+func (c *fakeConn) CloseWrite() error {
+	c.mu.Lock()
+	received := c.stdin.String()
+	c.mu.Unlock()
+	go func() {
+		c.output.Write([]byte("received: " + received))
+		c.output.Close()
+	}()
+	return nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func TestSessionWithoutTerminal(t *testing.T) {
+	stdin, stdinWriter, err := os.Pipe()
+	require.NoError(t, err)
+	defer stdin.Close()
+	_, err = stdinWriter.WriteString("SELECT 1;")
+	require.NoError(t, err)
+	require.NoError(t, stdinWriter.Close())
+
+	output, outputWriter := io.Pipe()
+	conn := &fakeConn{output: outputWriter}
+	session, err := NewSession(stdin, conn, output)
+	require.NoError(t, err)
+	assert.False(t, session.IsTerminal())
+
+	var stdout bytes.Buffer
+	session.stdout = &stdout
+	require.NoError(t, session.Start())
+	assert.Equal(t, "received: SELECT 1;", stdout.String())
+	assert.NoError(t, session.Close())
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package terminal
+
+import "os"
+
+// enableVirtualTerminal is a no-op, terminals other than the Windows console interpret escape sequences as is.
+func enableVirtualTerminal(out *os.File) (func() error, error) {
+	return func() error { return nil }, nil
+}
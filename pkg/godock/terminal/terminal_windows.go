@@ -0,0 +1,24 @@
+//go:build windows
+
+package terminal
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// enableVirtualTerminal makes the Windows console interpret the escape sequences written by the container's
+// TTY, and returns a function restoring its previous mode. term.MakeRaw already enables them on the input side.
+func enableVirtualTerminal(out *os.File) (func() error, error) {
+	handle := windows.Handle(out.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(handle, &mode); err != nil {
+		// stdout is redirected rather than a console, there is nothing to set.
+		return func() error { return nil }, nil
+	}
+	if err := windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING|windows.DISABLE_NEWLINE_AUTO_RETURN); err != nil {
+		return nil, err
+	}
+	return func() error { return windows.SetConsoleMode(handle, mode) }, nil
+}
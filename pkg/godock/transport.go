@@ -0,0 +1,120 @@
+package godock
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/docker/docker/client"
+)
+
+// Timeouts are the timeouts of the connections and requests to the daemon. A zero timeout is not applied.
+// Each call can still be bounded as a whole by the deadline of its context.
+type Timeouts struct {
+	// Dial limits connecting to the daemon.
+	Dial time.Duration
+	// TLSHandshake limits the TLS handshake with a daemon listening on TCP with TLS.
+	TLSHandshake time.Duration
+	// ResponseHeader limits waiting for the daemon to start its response once a request has been sent. Streaming
+	// calls such as pulls, logs and events start their response right away, so it does not cut them short.
+	ResponseHeader time.Duration
+	// IdleConn limits how long an unused connection is kept open for later requests.
+	IdleConn time.Duration
+}
+
+// transportOpts returns the docker client options that set up the connection to the daemon. The options overriding
+// the environment come after client.FromEnv, and the transport options after the host they configure.
+func transportOpts(opts *clientOptions) ([]client.Opt, error) {
+	var dockerOpts []client.Opt
+	if opts.httpClient != nil {
+		httpClient := *opts.httpClient
+		if httpClient.Transport == nil {
+			httpClient.Transport = http.DefaultTransport.(*http.Transport).Clone()
+		}
+		dockerOpts = append(dockerOpts, client.WithHTTPClient(&httpClient))
+	}
+	dockerOpts = append(dockerOpts, client.FromEnv, client.WithAPIVersionNegotiation())
+	daemonHost := opts.daemonHost
+	if daemonHost == "" && opts.httpClient == nil && !opts.noSocketDiscovery {
+		daemonHost = discoverDaemonHost()
+	}
+	if daemonHost != "" {
+		dockerOpts = append(dockerOpts, client.WithHost(daemonHost))
+	}
+
+	if opts.proxy != "" {
+		proxyURL, err := url.Parse(opts.proxy)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, &errdefs.ConfigError{Field: "proxy", Message: fmt.Sprintf("invalid proxy URL %q", opts.proxy)}
+		}
+		dockerOpts = append(dockerOpts, withTransport("proxy", func(c *client.Client, transport *http.Transport) error {
+			transport.Proxy = http.ProxyURL(proxyURL)
+			return nil
+		}))
+	}
+
+	if len(opts.customCA) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(opts.customCA) {
+			return nil, &errdefs.ConfigError{Field: "customCA", Message: "no certificates found in PEM data"}
+		}
+		dockerOpts = append(dockerOpts, withTransport("custom CA", func(c *client.Client, transport *http.Transport) error {
+			if !strings.HasPrefix(c.DaemonHost(), "tcp://") {
+				return fmt.Errorf("a custom CA requires a tcp:// daemon host, got %s", c.DaemonHost())
+			}
+			if transport.TLSClientConfig == nil {
+				transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+			}
+			transport.TLSClientConfig.RootCAs = pool
+			return nil
+		}))
+	}
+
+	if opts.timeouts != (Timeouts{}) {
+		timeouts := opts.timeouts
+		dockerOpts = append(dockerOpts, withTransport("timeouts", func(c *client.Client, transport *http.Transport) error {
+			if timeouts.Dial > 0 && transport.DialContext != nil {
+				dial := transport.DialContext
+				transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+					ctx, cancel := context.WithTimeout(ctx, timeouts.Dial)
+					defer cancel()
+					return dial(ctx, network, addr)
+				}
+			}
+			if timeouts.TLSHandshake > 0 {
+				transport.TLSHandshakeTimeout = timeouts.TLSHandshake
+			}
+			if timeouts.ResponseHeader > 0 {
+				transport.ResponseHeaderTimeout = timeouts.ResponseHeader
+			}
+			if timeouts.IdleConn > 0 {
+				transport.IdleConnTimeout = timeouts.IdleConn
+			}
+			return nil
+		}))
+	}
+	return dockerOpts, nil
+}
+
+// withTransport configures the HTTP transport of the docker client. It fails for transports that are not an
+// *http.Transport, such as a custom one set with WithHTTPClient.
+func withTransport(name string, fn func(c *client.Client, transport *http.Transport) error) client.Opt {
+	return func(c *client.Client) error {
+		// HTTPClient returns a copy of the HTTP client, which shares its transport.
+		transport, ok := c.HTTPClient().Transport.(*http.Transport)
+		if !ok {
+			return fmt.Errorf("cannot apply %s to transport %T", name, c.HTTPClient().Transport)
+		}
+		return fn(c, transport)
+	}
+}
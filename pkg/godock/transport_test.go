@@ -0,0 +1,114 @@
+package godock
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pingHandler answers the ping of NewClient like a daemon.
+func pingHandler(seen func(r *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if seen != nil {
+			seen(r)
+		}
+		w.Header().Set("Api-Version", "1.45")
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// roundTripperFunc is an http.RoundTripper that is not an *http.Transport.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestTransportOptions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Daemon host", func(t *testing.T) {
+		pinged := false
+		server := httptest.NewServer(pingHandler(func(r *http.Request) { pinged = true }))
+		defer server.Close()
+
+		c, err := NewClient(ctx, WithDaemonHost("tcp://"+strings.TrimPrefix(server.URL, "http://")))
+		require.NoError(t, err)
+		defer c.Close()
+		assert.True(t, pinged)
+		assert.Equal(t, "tcp://"+strings.TrimPrefix(server.URL, "http://"), c.String())
+	})
+
+	t.Run("Proxy", func(t *testing.T) {
+		var proxied string
+		proxy := httptest.NewServer(pingHandler(func(r *http.Request) { proxied = r.URL.Host }))
+		defer proxy.Close()
+
+		c, err := NewClient(ctx, WithDaemonHost("tcp://daemon.invalid:2375"), WithProxy(proxy.URL))
+		require.NoError(t, err)
+		defer c.Close()
+		assert.Equal(t, "daemon.invalid:2375", proxied)
+
+		_, err = NewClient(ctx, WithProxy("proxy.example.com"))
+		assert.True(t, errdefs.IsInvalidConfig(err))
+	})
+
+	t.Run("Custom CA", func(t *testing.T) {
+		server := httptest.NewTLSServer(pingHandler(nil))
+		defer server.Close()
+		ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		host := "tcp://" + strings.TrimPrefix(server.URL, "https://")
+
+		c, err := NewClient(ctx, WithDaemonHost(host), WithCustomCA(ca))
+		require.NoError(t, err)
+		defer c.Close()
+
+		_, err = NewClient(ctx, WithDaemonHost(host), WithCustomCA([]byte("not a certificate")))
+		assert.True(t, errdefs.IsInvalidConfig(err))
+
+		_, err = NewClient(ctx, WithDaemonHost("unix:///var/run/docker.sock"), WithCustomCA(ca))
+		assert.True(t, errdefs.IsInvalidConfig(err))
+	})
+
+	t.Run("Response header timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+			pingHandler(nil)(w, r)
+		}))
+		defer server.Close()
+
+		_, err := NewClient(ctx,
+			WithDaemonHost("tcp://"+strings.TrimPrefix(server.URL, "http://")),
+			WithTimeouts(Timeouts{ResponseHeader: 20 * time.Millisecond}),
+		)
+		assert.ErrorIs(t, err, errdefs.ErrDaemonNotRunning)
+	})
+
+	t.Run("HTTP client", func(t *testing.T) {
+		requests := 0
+		httpClient := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			requests++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Api-Version": []string{"1.45"}},
+				Body:       http.NoBody,
+				Request:    r,
+			}, nil
+		})}
+		c, err := NewClient(ctx, WithHTTPClient(httpClient))
+		require.NoError(t, err)
+		defer c.Close()
+		assert.Positive(t, requests)
+
+		_, err = NewClient(ctx, WithHTTPClient(httpClient), WithTimeouts(Timeouts{Dial: time.Second}))
+		assert.True(t, errdefs.IsInvalidConfig(err), "a custom transport cannot be configured")
+	})
+}
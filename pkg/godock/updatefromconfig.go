@@ -0,0 +1,102 @@
+package godock
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// ContainerUpdateResult is the outcome of ContainerUpdateFromConfig.
+type ContainerUpdateResult struct {
+	// Changed holds the names of the HostConfig fields that were updated, e.g. "Memory" or "RestartPolicy".
+	// It is empty when the container already matched its config and no update was issued.
+	Changed  []string
+	Warnings []string
+}
+
+/*
+ContainerUpdateFromConfig brings the resource limits and restart policy of a running container in line with
+its HostOptions, so the limits can be changed by editing the config rather than with update options. It
+compares the config with the live container and issues a single update with the fields that differ. Fields
+left at their zero value in the config are not changed; fields the daemon cannot change at runtime are
+rejected by it as with ContainerUpdate.
+
+Usage example:
+
+	app.SetHostOptions(hostoptions.Memory(512*1024*1024), hostoptions.CPUs(1.5))
+	res, err := client.ContainerUpdateFromConfig(ctx, app)
+	if err != nil {
+		return err
+	}
+	log.Printf("updated %v", res.Changed)
+*/
+func (c *Client) ContainerUpdateFromConfig(ctx context.Context, containerConfig *container.ContainerConfig) (*ContainerUpdateResult, error) {
+	if containerConfig == nil || containerConfig.Id == "" {
+		return nil, &errdefs.ValidationError{Field: "containerConfig", Message: "container config or id cannot be empty"}
+	}
+	if containerConfig.HostOptions == nil {
+		return &ContainerUpdateResult{}, nil
+	}
+
+	inspect, err := c.wrapped.ContainerInspect(ctx, containerConfig.Id)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return nil, &errdefs.ResourceNotFoundError{ResourceType: "container", ID: containerConfig.Id, Err: err}
+		}
+		return nil, &errdefs.ContainerError{ID: containerConfig.Name, Op: "update", Message: err.Error(), Err: err}
+	}
+	live := inspect.HostConfig
+	if live == nil {
+		live = &containerType.HostConfig{}
+	}
+
+	update, changed := updateConfigDiff(containerConfig.HostOptions, live)
+	if len(changed) == 0 {
+		return &ContainerUpdateResult{}, nil
+	}
+	if err := ValidateUpdateConfig(update); err != nil {
+		return nil, err
+	}
+	res, err := c.wrapped.ContainerUpdate(ctx, containerConfig.Id, update)
+	if err != nil {
+		return nil, &errdefs.ContainerError{ID: containerConfig.Name, Op: "update", Message: err.Error(), Err: err}
+	}
+	return &ContainerUpdateResult{Changed: changed, Warnings: res.Warnings}, nil
+}
+
+// updateConfigDiff returns an update setting the resources and restart policy of desired that are set and
+// differ from live, and the names of those fields.
+func updateConfigDiff(desired, live *containerType.HostConfig) (containerType.UpdateConfig, []string) {
+	var update containerType.UpdateConfig
+	var changed []string
+
+	want := reflect.ValueOf(desired.Resources)
+	have := reflect.ValueOf(live.Resources)
+	set := reflect.ValueOf(&update.Resources).Elem()
+	for i := 0; i < want.NumField(); i++ {
+		field := want.Field(i)
+		if unsetUpdateField(field) || reflect.DeepEqual(field.Interface(), have.Field(i).Interface()) {
+			continue
+		}
+		set.Field(i).Set(field)
+		changed = append(changed, want.Type().Field(i).Name)
+	}
+
+	if desired.RestartPolicy.Name != "" && desired.RestartPolicy != live.RestartPolicy {
+		update.RestartPolicy = desired.RestartPolicy
+		changed = append(changed, "RestartPolicy")
+	}
+	return update, changed
+}
+
+// unsetUpdateField reports whether a resource field is left unset in a config.
+func unsetUpdateField(field reflect.Value) bool {
+	if field.Kind() == reflect.Slice {
+		return field.Len() == 0
+	}
+	return field.IsZero()
+}
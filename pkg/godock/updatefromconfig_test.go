@@ -0,0 +1,58 @@
+package godock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/docker/docker/api/types/blkiodev"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateConfigDiff(t *testing.T) {
+	pids := int64(100)
+	desired := container.NewConfig("app")
+	desired.SetHostOptions(
+		hostoptions.Memory(512*1024*1024),
+		hostoptions.CPUs(1.5),
+		hostoptions.PidsLimit(&pids),
+		hostoptions.RestartPolicy("on-failure", 3),
+	)
+
+	livePids := int64(100)
+	live := &containerType.HostConfig{
+		Resources: containerType.Resources{
+			Memory:            256 * 1024 * 1024,
+			NanoCPUs:          1500000000,
+			PidsLimit:         &livePids,
+			CPUShares:         512,
+			BlkioWeightDevice: []*blkiodev.WeightDevice{},
+		},
+		RestartPolicy: containerType.RestartPolicy{Name: containerType.RestartPolicyAlways},
+	}
+
+	update, changed := updateConfigDiff(desired.HostOptions, live)
+	assert.Equal(t, []string{"Memory", "RestartPolicy"}, changed)
+	assert.Equal(t, int64(512*1024*1024), update.Memory)
+	assert.Zero(t, update.NanoCPUs)
+	assert.Nil(t, update.PidsLimit)
+	assert.Zero(t, update.CPUShares)
+	assert.Equal(t, containerType.RestartPolicyOnFailure, update.RestartPolicy.Name)
+	assert.Equal(t, 3, update.RestartPolicy.MaximumRetryCount)
+
+	live.Memory = 512 * 1024 * 1024
+	live.RestartPolicy = desired.HostOptions.RestartPolicy
+	_, changed = updateConfigDiff(desired.HostOptions, live)
+	assert.Empty(t, changed)
+}
+
+func TestContainerUpdateFromConfigValidation(t *testing.T) {
+	c := &Client{}
+	_, err := c.ContainerUpdateFromConfig(context.Background(), nil)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	_, err = c.ContainerUpdateFromConfig(context.Background(), container.NewConfig("no-id"))
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
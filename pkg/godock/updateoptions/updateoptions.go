@@ -1,12 +1,39 @@
 package updateoptions
 
 import (
+	"math"
+
 	"github.com/docker/docker/api/types/blkiodev"
 	containerType "github.com/docker/docker/api/types/container"
 
 	"github.com/aptd3v/godock/pkg/godock"
 )
 
+/*
+Apply builds an update from option functions and checks it with godock.ValidateUpdateConfig, so invalid
+values are reported before the update reaches the daemon.
+
+Usage example:
+
+	update, err := updateoptions.Apply(
+		updateoptions.WithMemory(512*1024*1024),
+		updateoptions.WithMemorySwap(1024*1024*1024),
+		updateoptions.WithRestartPolicy("on-failure", 5),
+	)
+	if err != nil {
+		return err
+	}
+*/
+func Apply(updateOptionFns ...godock.UpdateOptionFn) (containerType.UpdateConfig, error) {
+	var update containerType.UpdateConfig
+	for _, fn := range updateOptionFns {
+		if fn != nil {
+			fn(&update)
+		}
+	}
+	return update, godock.ValidateUpdateConfig(update)
+}
+
 // WithCPUShares updates the CPU shares for the container.
 func WithCPUShares(cpuShares int64) godock.UpdateOptionFn {
 	return func(options *containerType.UpdateConfig) {
@@ -28,6 +55,15 @@ func WithNanoCPUs(nanoCPUs int64) godock.UpdateOptionFn {
 	}
 }
 
+// WithCPUs updates the number of CPUs the container can use, like docker update --cpus.
+// Fractions are allowed, e.g. 0.5 for half a CPU. The daemon rejects it for containers
+// created with a CPU period or quota.
+func WithCPUs(cpus float64) godock.UpdateOptionFn {
+	return func(options *containerType.UpdateConfig) {
+		options.NanoCPUs = int64(math.Round(cpus * 1e9))
+	}
+}
+
 // WithCgroupParent updates the cgroup parent for the container.
 func WithCgroupParent(cgroupParent string) godock.UpdateOptionFn {
 	return func(options *containerType.UpdateConfig) {
@@ -74,6 +110,10 @@ func WithBlkioDeviceWriteBps(path string, rate uint64) godock.UpdateOptionFn {
 		if options.BlkioDeviceWriteBps == nil {
 			options.BlkioDeviceWriteBps = make([]*blkiodev.ThrottleDevice, 0)
 		}
+		options.BlkioDeviceWriteBps = append(options.BlkioDeviceWriteBps, &blkiodev.ThrottleDevice{
+			Path: path,
+			Rate: rate,
+		})
 	}
 }
 
@@ -83,6 +123,10 @@ func WithBlkioDeviceReadIOps(path string, rate uint64) godock.UpdateOptionFn {
 		if options.BlkioDeviceReadIOps == nil {
 			options.BlkioDeviceReadIOps = make([]*blkiodev.ThrottleDevice, 0)
 		}
+		options.BlkioDeviceReadIOps = append(options.BlkioDeviceReadIOps, &blkiodev.ThrottleDevice{
+			Path: path,
+			Rate: rate,
+		})
 	}
 }
 
@@ -92,6 +136,10 @@ func WithBlkioDeviceWriteIOps(path string, rate uint64) godock.UpdateOptionFn {
 		if options.BlkioDeviceWriteIOps == nil {
 			options.BlkioDeviceWriteIOps = make([]*blkiodev.ThrottleDevice, 0)
 		}
+		options.BlkioDeviceWriteIOps = append(options.BlkioDeviceWriteIOps, &blkiodev.ThrottleDevice{
+			Path: path,
+			Rate: rate,
+		})
 	}
 }
 
@@ -224,6 +272,11 @@ func WithPidsLimit(pidsLimit int64) godock.UpdateOptionFn {
 	}
 }
 
+// WithUnlimitedPids removes the pids limit of the container.
+func WithUnlimitedPids() godock.UpdateOptionFn {
+	return WithPidsLimit(-1)
+}
+
 // WithUlimits updates the ulimits for the container.
 func WithUlimits(name string, softLimit int64, hardLimit int64) godock.UpdateOptionFn {
 	return func(options *containerType.UpdateConfig) {
@@ -263,6 +316,8 @@ func WithIOMaximumBandwidth(ioMaximumBandwidth uint64) godock.UpdateOptionFn {
 	}
 }
 
+// WithRestartPolicy updates the restart policy for the container: "no", "always", "unless-stopped" or
+// "on-failure", the only policy that takes a maximum retry count.
 func WithRestartPolicy(name string, maximumRetryCount int) godock.UpdateOptionFn {
 	return func(options *containerType.UpdateConfig) {
 		options.RestartPolicy = containerType.RestartPolicy{
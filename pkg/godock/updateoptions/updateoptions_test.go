@@ -0,0 +1,49 @@
+package updateoptions
+
+import (
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/docker/docker/api/types/blkiodev"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlkioDeviceOptions(t *testing.T) {
+	options := &containerType.UpdateConfig{}
+	for _, fn := range []godock.UpdateOptionFn{
+		WithBlkioDeviceReadBps("/dev/sda", 1),
+		WithBlkioDeviceWriteBps("/dev/sda", 2),
+		WithBlkioDeviceReadIOps("/dev/sdb", 3),
+		WithBlkioDeviceWriteIOps("/dev/sdb", 4),
+	} {
+		fn(options)
+	}
+	require.Len(t, options.BlkioDeviceReadBps, 1)
+	assert.Equal(t, blkiodev.ThrottleDevice{Path: "/dev/sda", Rate: 1}, *options.BlkioDeviceReadBps[0])
+	require.Len(t, options.BlkioDeviceWriteBps, 1)
+	assert.Equal(t, blkiodev.ThrottleDevice{Path: "/dev/sda", Rate: 2}, *options.BlkioDeviceWriteBps[0])
+	require.Len(t, options.BlkioDeviceReadIOps, 1)
+	assert.Equal(t, blkiodev.ThrottleDevice{Path: "/dev/sdb", Rate: 3}, *options.BlkioDeviceReadIOps[0])
+	require.Len(t, options.BlkioDeviceWriteIOps, 1)
+	assert.Equal(t, blkiodev.ThrottleDevice{Path: "/dev/sdb", Rate: 4}, *options.BlkioDeviceWriteIOps[0])
+}
+
+func TestApply(t *testing.T) {
+	update, err := Apply(
+		WithMemory(512),
+		WithMemorySwap(1024),
+		WithRestartPolicy("on-failure", 5),
+		WithUnlimitedPids(),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, int64(512), update.Memory)
+	assert.Equal(t, int64(-1), *update.PidsLimit)
+
+	_, err = Apply(WithMemory(1024), WithMemorySwap(512))
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	_, err = Apply(WithRestartPolicy("sometimes", 0))
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
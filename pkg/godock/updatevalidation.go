@@ -0,0 +1,63 @@
+package godock
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	containerType "github.com/docker/docker/api/types/container"
+)
+
+/*
+ValidateUpdateConfig checks an update for values the daemon would reject, so they are reported with the
+field at fault rather than the daemon's message. It returns a ValidationError per invalid field, joined.
+ContainerUpdate and ContainerUpdateFromConfig validate their update before sending it.
+
+The swap limit is only checked against the memory limit when the update sets both, since the daemon
+compares it with the container's current memory limit otherwise.
+*/
+func ValidateUpdateConfig(update containerType.UpdateConfig) error {
+	var errs []error
+	invalid := func(field, format string, args ...interface{}) {
+		errs = append(errs, &errdefs.ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if update.Memory < 0 {
+		invalid("Memory", "memory limit %d cannot be negative", update.Memory)
+	}
+	if update.MemoryReservation < 0 {
+		invalid("MemoryReservation", "memory reservation %d cannot be negative", update.MemoryReservation)
+	}
+	if update.Memory > 0 && update.MemoryReservation > update.Memory {
+		invalid("MemoryReservation", "memory reservation %d must not exceed the memory limit %d", update.MemoryReservation, update.Memory)
+	}
+	// A swap limit of -1 is unlimited swap.
+	if update.MemorySwap < -1 {
+		invalid("MemorySwap", "swap limit %d cannot be negative, use -1 for unlimited swap", update.MemorySwap)
+	}
+	if update.MemorySwap > 0 && update.Memory > 0 && update.MemorySwap < update.Memory {
+		invalid("MemorySwap", "swap limit %d must be at least the memory limit %d, as it includes memory", update.MemorySwap, update.Memory)
+	}
+	if update.MemorySwappiness != nil && (*update.MemorySwappiness < 0 || *update.MemorySwappiness > 100) {
+		invalid("MemorySwappiness", "swappiness %d must be between 0 and 100", *update.MemorySwappiness)
+	}
+	if update.NanoCPUs < 0 {
+		invalid("NanoCPUs", "cpu limit %d cannot be negative", update.NanoCPUs)
+	}
+	if update.NanoCPUs > 0 && (update.CPUPeriod > 0 || update.CPUQuota > 0) {
+		invalid("NanoCPUs", "cpu limit cannot be combined with a cpu period or quota")
+	}
+	if update.CPUShares < 0 {
+		invalid("CPUShares", "cpu shares %d cannot be negative", update.CPUShares)
+	}
+	if update.BlkioWeight != 0 && (update.BlkioWeight < 10 || update.BlkioWeight > 1000) {
+		invalid("BlkioWeight", "blkio weight %d must be between 10 and 1000", update.BlkioWeight)
+	}
+	if update.PidsLimit != nil && *update.PidsLimit < -1 {
+		invalid("PidsLimit", "pids limit %d cannot be negative, use -1 for unlimited", *update.PidsLimit)
+	}
+	if err := containerType.ValidateRestartPolicy(update.RestartPolicy); err != nil {
+		invalid("RestartPolicy", "%s", err.Error())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,95 @@
+package godock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateUpdateConfig(t *testing.T) {
+	swappiness := int64(101)
+	pids := int64(-2)
+	unlimited := int64(-1)
+	tests := []struct {
+		name   string
+		update containerType.UpdateConfig
+		fields []string
+	}{
+		{name: "empty"},
+		{
+			name: "valid",
+			update: containerType.UpdateConfig{
+				Resources:     containerType.Resources{Memory: 512, MemorySwap: -1, PidsLimit: &unlimited, BlkioWeight: 500},
+				RestartPolicy: containerType.RestartPolicy{Name: containerType.RestartPolicyOnFailure, MaximumRetryCount: 3},
+			},
+		},
+		{
+			name:   "negative memory",
+			update: containerType.UpdateConfig{Resources: containerType.Resources{Memory: -1}},
+			fields: []string{"Memory"},
+		},
+		{
+			name:   "swap below memory",
+			update: containerType.UpdateConfig{Resources: containerType.Resources{Memory: 1024, MemorySwap: 512}},
+			fields: []string{"MemorySwap"},
+		},
+		{
+			name:   "reservation above memory",
+			update: containerType.UpdateConfig{Resources: containerType.Resources{Memory: 512, MemoryReservation: 1024}},
+			fields: []string{"MemoryReservation"},
+		},
+		{
+			name:   "several",
+			update: containerType.UpdateConfig{Resources: containerType.Resources{MemorySwappiness: &swappiness, PidsLimit: &pids, BlkioWeight: 5}},
+			fields: []string{"MemorySwappiness", "BlkioWeight", "PidsLimit"},
+		},
+		{
+			name:   "cpus with quota",
+			update: containerType.UpdateConfig{Resources: containerType.Resources{NanoCPUs: 1e9, CPUQuota: 50000}},
+			fields: []string{"NanoCPUs"},
+		},
+		{
+			name:   "unknown restart policy",
+			update: containerType.UpdateConfig{RestartPolicy: containerType.RestartPolicy{Name: "sometimes"}},
+			fields: []string{"RestartPolicy"},
+		},
+		{
+			name:   "retry count without on-failure",
+			update: containerType.UpdateConfig{RestartPolicy: containerType.RestartPolicy{Name: containerType.RestartPolicyAlways, MaximumRetryCount: 2}},
+			fields: []string{"RestartPolicy"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUpdateConfig(tt.update)
+			if len(tt.fields) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errdefs.IsInvalidConfig(err))
+			var fields []string
+			for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+				var validationErr *errdefs.ValidationError
+				if errors.As(e, &validationErr) {
+					fields = append(fields, validationErr.Field)
+				}
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+}
+
+func TestContainerUpdateValidation(t *testing.T) {
+	c := &Client{}
+	ctr := container.NewConfig("app")
+	ctr.Id = "abc"
+	_, err := c.ContainerUpdate(context.Background(), ctr, func(update *containerType.UpdateConfig) {
+		update.Memory = -1
+	})
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
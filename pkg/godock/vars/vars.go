@@ -0,0 +1,327 @@
+package vars
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+)
+
+/*
+Vars resolves ${VAR} placeholders in container configs, so one config set can drive dev, stage and prod variants.
+${VAR:-default} falls back to default when VAR is unset or empty, and $${VAR} is written as a literal ${VAR}.
+Placeholders that are not valid variable names, like the shell expansion ${PATH%:*}, are left alone. Unresolved
+variables are left in place too, unless the Vars are strict.
+
+Usage example:
+
+	v := vars.New(
+		vars.FromMap(map[string]string{"ENV": "stage", "TAG": "1.4.2"}),
+		vars.FromEnv(),
+		vars.Strict(),
+	)
+	client, err := godock.NewClient(ctx, godock.WithVars(v))
+	if err != nil {
+		return err
+	}
+	api := container.NewConfig("api-${ENV}")
+	api.SetContainerOptions(containeroptions.Image(image.NewConfig("registry.example.com/api:${TAG}")))
+	api.SetHostOptions(hostoptions.PortBindings("0.0.0.0", "${API_PORT:-8080}", "8080"))
+	err = client.ContainerCreate(ctx, api)
+*/
+type Vars struct {
+	sources []func(name string) (string, bool)
+	strict  bool
+}
+
+// SetVarsOptFn is a function type that configures Vars.
+type SetVarsOptFn func(v *Vars)
+
+// New creates Vars resolving variables from the given sources, the first source defining a variable wins.
+func New(setOptFns ...SetVarsOptFn) *Vars {
+	v := &Vars{}
+	for _, set := range setOptFns {
+		if set != nil {
+			set(v)
+		}
+	}
+	return v
+}
+
+// FromMap resolves variables from values. The map is copied.
+func FromMap(values map[string]string) SetVarsOptFn {
+	values = maps.Clone(values)
+	return func(v *Vars) {
+		v.sources = append(v.sources, func(name string) (string, bool) {
+			value, ok := values[name]
+			return value, ok
+		})
+	}
+}
+
+// FromEnv resolves variables from the environment of the process.
+func FromEnv() SetVarsOptFn {
+	return func(v *Vars) {
+		v.sources = append(v.sources, os.LookupEnv)
+	}
+}
+
+// Strict makes resolving a config fail when it has placeholders of undefined variables without a default.
+func Strict() SetVarsOptFn {
+	return func(v *Vars) {
+		v.strict = true
+	}
+}
+
+// Lookup returns the value of a variable and whether any source defines it.
+func (v *Vars) Lookup(name string) (string, bool) {
+	for _, source := range v.sources {
+		if value, ok := source(name); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// Expand resolves the placeholders in s. In strict mode it returns a ValidationError naming the unresolved
+// variables.
+func (v *Vars) Expand(s string) (string, error) {
+	e := &expander{vars: v}
+	expanded := e.str("value", s)
+	return expanded, e.err()
+}
+
+/*
+Apply resolves the placeholders in a container config in place: the name, image, command, entrypoint, environment,
+label values, working directory, user, hostname, exposed ports and volumes, and on the host side binds, mounts,
+port bindings, network mode, extra hosts and tmpfs mounts, as well as network names and aliases. Slices and maps
+are replaced rather than changed, so they may be shared with other configs. In strict mode the config is left
+unchanged when a variable is unresolved.
+*/
+func (v *Vars) Apply(containerConfig *container.ContainerConfig) error {
+	if containerConfig == nil {
+		return &errdefs.ValidationError{Field: "containerConfig", Message: "container config cannot be nil"}
+	}
+	e := &expander{vars: v}
+	resolved := containerConfig.Clone()
+	resolved.Name = e.str("Name", resolved.Name)
+	if options := resolved.Options; options != nil {
+		options.Image = e.str("Image", options.Image)
+		options.Cmd = e.strs("Cmd", options.Cmd)
+		options.Entrypoint = e.strs("Entrypoint", options.Entrypoint)
+		options.Env = e.strs("Env", options.Env)
+		options.Labels = e.values("Labels", options.Labels)
+		options.WorkingDir = e.str("WorkingDir", options.WorkingDir)
+		options.User = e.str("User", options.User)
+		options.Hostname = e.str("Hostname", options.Hostname)
+		options.Domainname = e.str("Domainname", options.Domainname)
+		options.ExposedPorts = e.portSet("ExposedPorts", options.ExposedPorts)
+		options.Volumes = e.keys("Volumes", options.Volumes)
+	}
+	if host := resolved.HostOptions; host != nil {
+		host.Binds = e.strs("Binds", host.Binds)
+		if host.Mounts != nil {
+			host.Mounts = slices.Clone(host.Mounts)
+			for i := range host.Mounts {
+				host.Mounts[i].Source = e.str("Mounts", host.Mounts[i].Source)
+				host.Mounts[i].Target = e.str("Mounts", host.Mounts[i].Target)
+			}
+		}
+		host.PortBindings = e.portMap("PortBindings", host.PortBindings)
+		host.NetworkMode = containerType.NetworkMode(e.str("NetworkMode", string(host.NetworkMode)))
+		host.ExtraHosts = e.strs("ExtraHosts", host.ExtraHosts)
+		if host.Tmpfs != nil {
+			tmpfs := make(map[string]string, len(host.Tmpfs))
+			for target, options := range host.Tmpfs {
+				tmpfs[e.str("Tmpfs", target)] = options
+			}
+			host.Tmpfs = tmpfs
+		}
+	}
+	if networking := resolved.NetworkingOptions; networking != nil && networking.EndpointsConfig != nil {
+		endpoints := make(map[string]*network.EndpointSettings, len(networking.EndpointsConfig))
+		for name, endpoint := range networking.EndpointsConfig {
+			if endpoint != nil {
+				endpoint.Aliases = e.strs("Aliases", endpoint.Aliases)
+			}
+			endpoints[e.str("Networks", name)] = endpoint
+		}
+		networking.EndpointsConfig = endpoints
+	}
+	if err := e.err(); err != nil {
+		return err
+	}
+	*containerConfig = *resolved
+	return nil
+}
+
+// expander resolves placeholders and records the unresolved variables with the first field they were found in.
+type expander struct {
+	vars       *Vars
+	unresolved map[string]string
+}
+
+// err returns a ValidationError listing the unresolved variables in strict mode.
+func (e *expander) err() error {
+	if !e.vars.strict || len(e.unresolved) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(e.unresolved))
+	for name := range e.unresolved {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	unresolved := make([]string, len(names))
+	for i, name := range names {
+		unresolved[i] = fmt.Sprintf("${%s} in %s", name, e.unresolved[name])
+	}
+	return &errdefs.ValidationError{Field: "vars", Message: "unresolved variables: " + strings.Join(unresolved, ", ")}
+}
+
+// str resolves the placeholders in s, found in field.
+func (e *expander) str(field, s string) string {
+	if !strings.Contains(s, "$") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		rest := s[i:]
+		if strings.HasPrefix(rest, "$${") {
+			b.WriteString("${")
+			i += 3
+			continue
+		}
+		end := strings.IndexByte(rest, '}')
+		if !strings.HasPrefix(rest, "${") || end < 0 {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		name, fallback, hasFallback := strings.Cut(rest[2:end], ":-")
+		if !validName(name) {
+			// Not a placeholder, e.g. a shell expansion in a command.
+			b.WriteString(rest[:end+1])
+		} else if value, ok := e.vars.Lookup(name); ok && (value != "" || !hasFallback) {
+			b.WriteString(value)
+		} else if hasFallback {
+			b.WriteString(fallback)
+		} else {
+			if e.unresolved == nil {
+				e.unresolved = make(map[string]string)
+			}
+			if _, seen := e.unresolved[name]; !seen {
+				e.unresolved[name] = field
+			}
+			b.WriteString(rest[:end+1])
+		}
+		i += end + 1
+	}
+	return b.String()
+}
+
+// strs resolves the placeholders in a copy of values.
+func (e *expander) strs(field string, values []string) []string {
+	if values == nil {
+		return nil
+	}
+	resolved := make([]string, len(values))
+	for i, value := range values {
+		resolved[i] = e.str(field, value)
+	}
+	return resolved
+}
+
+// values resolves the placeholders in the values of a copy of m.
+func (e *expander) values(field string, m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	resolved := make(map[string]string, len(m))
+	for key, value := range m {
+		resolved[key] = e.str(field, value)
+	}
+	return resolved
+}
+
+// keys resolves the placeholders in the keys of a copy of a set.
+func (e *expander) keys(field string, set map[string]struct{}) map[string]struct{} {
+	if set == nil {
+		return nil
+	}
+	resolved := make(map[string]struct{}, len(set))
+	for key := range set {
+		resolved[e.str(field, key)] = struct{}{}
+	}
+	return resolved
+}
+
+// portSet resolves the placeholders in a copy of ports.
+func (e *expander) portSet(field string, ports nat.PortSet) nat.PortSet {
+	if ports == nil {
+		return nil
+	}
+	resolved := make(nat.PortSet, len(ports))
+	for port := range ports {
+		resolved[e.port(field, port)] = struct{}{}
+	}
+	return resolved
+}
+
+// portMap resolves the placeholders in the ports and host addresses of a copy of bindings.
+func (e *expander) portMap(field string, bindings nat.PortMap) nat.PortMap {
+	if bindings == nil {
+		return nil
+	}
+	resolved := make(nat.PortMap, len(bindings))
+	for port, hostBindings := range bindings {
+		var resolvedBindings []nat.PortBinding
+		for _, binding := range hostBindings {
+			resolvedBindings = append(resolvedBindings, nat.PortBinding{
+				HostIP:   e.str(field, binding.HostIP),
+				HostPort: e.str(field, binding.HostPort),
+			})
+		}
+		resolved[e.port(field, port)] = resolvedBindings
+	}
+	return resolved
+}
+
+// port resolves the placeholders in a port key. A resolved port without a protocol is bound as TCP, like the
+// ports of hostoptions.PortBindings and containeroptions.Expose.
+func (e *expander) port(field string, port nat.Port) nat.Port {
+	resolved := e.str(field, string(port))
+	if resolved == string(port) {
+		return port
+	}
+	proto, number := nat.SplitProtoPort(resolved)
+	normalized, err := nat.NewPort(strings.ToLower(proto), number)
+	if err != nil {
+		return nat.Port(resolved)
+	}
+	return normalized
+}
+
+// validName reports whether name is a variable name: a letter or underscore followed by letters, digits and
+// underscores.
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case i > 0 && '0' <= r && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package vars
+
+import (
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpand(t *testing.T) {
+	t.Setenv("GODOCK_TEST_REGION", "eu-west-1")
+	v := New(FromMap(map[string]string{"ENV": "stage", "EMPTY": ""}), FromMap(map[string]string{"ENV": "prod"}), FromEnv())
+
+	tests := []struct {
+		in, want string
+	}{
+		{"api-${ENV}", "api-stage"},
+		{"${GODOCK_TEST_REGION}.${ENV}", "eu-west-1.stage"},
+		{"${PORT:-8080}", "8080"},
+		{"${EMPTY:-none}", "none"},
+		{"[${EMPTY}]", "[]"},
+		{"$${ENV} costs $5", "${ENV} costs $5"},
+		{"echo ${PATH%:*}", "echo ${PATH%:*}"},
+		{"${MISSING}-${ENV", "${MISSING}-${ENV"},
+	}
+	for _, tt := range tests {
+		got, err := v.Expand(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	_, err := New(Strict()).Expand("${MISSING} and ${ALSO_MISSING}")
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	assert.ErrorContains(t, err, "unresolved variables: ${ALSO_MISSING} in value, ${MISSING} in value")
+}
+
+func TestApply(t *testing.T) {
+	v := New(FromMap(map[string]string{"ENV": "stage", "TAG": "1.4.2", "PORT": "9090", "DATA": "/srv/data"}))
+	template := container.NewConfig("api-${ENV}")
+	template.SetContainerOptions(
+		containeroptions.Image(image.NewConfig("registry.example.com/api:${TAG}")),
+		containeroptions.Env("APP_ENV", "${ENV}"),
+		containeroptions.Label("env", "${ENV}"),
+		containeroptions.CMD("sh", "-c", "exec api --data ${DATA}"),
+		containeroptions.Expose("${PORT}"),
+	)
+	template.SetHostOptions(
+		hostoptions.PortBindings("0.0.0.0", "${PORT}", "${PORT}"),
+		hostoptions.Mount(hostoptions.MountType("bind"), "${DATA}", "/data", false),
+		hostoptions.Bind("${DATA}/cache:/cache"),
+	)
+	cfg := template.Clone()
+
+	require.NoError(t, v.Apply(cfg))
+	assert.Equal(t, "api-stage", cfg.Name)
+	assert.Equal(t, "registry.example.com/api:1.4.2", cfg.Options.Image)
+	assert.Equal(t, []string{"APP_ENV=stage"}, cfg.Options.Env)
+	assert.Equal(t, "stage", cfg.Options.Labels["env"])
+	assert.Equal(t, []string{"sh", "-c", "exec api --data /srv/data"}, []string(cfg.Options.Cmd))
+	assert.Contains(t, cfg.Options.ExposedPorts, nat.Port("9090/tcp"))
+	assert.Equal(t, []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "9090"}}, cfg.HostOptions.PortBindings["9090/tcp"])
+	assert.Equal(t, "/srv/data", cfg.HostOptions.Mounts[0].Source)
+	assert.Equal(t, []string{"/srv/data/cache:/cache"}, cfg.HostOptions.Binds)
+
+	// The template shares slices and maps with the clone and is left unresolved.
+	assert.Equal(t, "${DATA}", template.HostOptions.Mounts[0].Source)
+	assert.Equal(t, []string{"sh", "-c", "exec api --data ${DATA}"}, []string(template.Options.Cmd))
+	assert.Equal(t, "${DATA}/cache:/cache", template.HostOptions.Binds[0])
+}
+
+func TestApplyStrict(t *testing.T) {
+	cfg := container.NewConfig("api-${ENV}")
+	cfg.SetContainerOptions(containeroptions.Image(image.NewConfig("api:${TAG}")))
+	cfg.SetHostOptions(hostoptions.PortBindings("", "${PORT:-8080}", "8080"))
+
+	err := New(FromMap(map[string]string{"ENV": "dev"}), Strict()).Apply(cfg)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	assert.ErrorContains(t, err, "${TAG} in Image")
+	assert.Equal(t, "api-${ENV}", cfg.Name, "a failed apply leaves the config unchanged")
+
+	// Without strict mode unresolved placeholders are kept.
+	require.NoError(t, New(FromMap(map[string]string{"ENV": "dev"})).Apply(cfg))
+	assert.Equal(t, "api-dev", cfg.Name)
+	assert.Equal(t, "api:${TAG}", cfg.Options.Image)
+	assert.Equal(t, "8080", cfg.HostOptions.PortBindings["8080/tcp"][0].HostPort)
+
+	assert.True(t, errdefs.IsInvalidConfig(New().Apply(nil)))
+}
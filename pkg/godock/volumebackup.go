@@ -0,0 +1,235 @@
+package godock
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// volumeHelperMount is where the helper container mounts the volume.
+const volumeHelperMount = "/volume"
+
+type volumeTransferOptions struct {
+	helperImage string
+	onProgress  func(transferred int64)
+	progress    Progress
+	uid, gid    int
+}
+
+// VolumeTransferOptionFn is a function that configures the volume backup, restore, copy and populate operations.
+type VolumeTransferOptionFn func(*volumeTransferOptions)
+
+// WithVolumeHelperImage sets the image of the helper container that mounts the volumes.
+// VolumeBackup and VolumeRestore never start the container, so any image works for them;
+// VolumeCopy and VolumeClone need sh and cp. The default is DefaultDoctorProbeImage.
+func WithVolumeHelperImage(ref string) VolumeTransferOptionFn {
+	return func(opts *volumeTransferOptions) {
+		opts.helperImage = ref
+	}
+}
+
+// WithVolumeProgress sets a function called with the number of archive bytes transferred so far.
+func WithVolumeProgress(onProgress func(transferred int64)) VolumeTransferOptionFn {
+	return func(opts *volumeTransferOptions) {
+		opts.onProgress = onProgress
+	}
+}
+
+// WithVolumeReporter reports VolumeBackup and VolumeRestore to progress, as the archive bytes transferred.
+// The total is not known in advance.
+func WithVolumeReporter(progress Progress) VolumeTransferOptionFn {
+	return func(opts *volumeTransferOptions) {
+		opts.progress = progress
+	}
+}
+
+// WithVolumeOwner sets the owner of the files written by VolumePopulate, e.g. for containers running as a non-root user.
+func WithVolumeOwner(uid, gid int) VolumeTransferOptionFn {
+	return func(opts *volumeTransferOptions) {
+		opts.uid = uid
+		opts.gid = gid
+	}
+}
+
+func newVolumeTransferOptions(volumeTransferOptionFns []VolumeTransferOptionFn) *volumeTransferOptions {
+	opts := &volumeTransferOptions{
+		helperImage: DefaultDoctorProbeImage,
+	}
+	for _, fn := range volumeTransferOptionFns {
+		if fn != nil {
+			fn(opts)
+		}
+	}
+	return opts
+}
+
+/*
+VolumeBackup writes the contents of a volume to w as a tar archive, with paths relative to the volume root
+and ownership and permissions preserved. The archive can be restored with VolumeRestore, on this or another host.
+The volume is read through a helper container that mounts it read-only and is never started, so containers
+using the volume can keep running, though their writes during the backup may be only partially included.
+
+Usage example:
+
+	file, err := os.Create("pgdata.tar")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	err = client.VolumeBackup(ctx, "pgdata", file, godock.WithVolumeProgress(func(n int64) {
+		fmt.Printf("\r%s", units.HumanSize(float64(n)))
+	}))
+*/
+func (c *Client) VolumeBackup(ctx context.Context, volumeName string, w io.Writer, volumeTransferOptionFns ...VolumeTransferOptionFn) (err error) {
+	opts := newVolumeTransferOptions(volumeTransferOptionFns)
+	tracker := newProgressTracker(opts.progress, ProgressOpVolumeBackup, volumeName, 0)
+	tracker.start()
+	defer func() {
+		tracker.finish(err)
+	}()
+	if _, err := c.wrapped.VolumeInspect(ctx, volumeName); err != nil {
+		if client.IsErrNotFound(err) {
+			return &errdefs.ResourceNotFoundError{ResourceType: "volume", ID: volumeName, Err: err}
+		}
+		return err
+	}
+
+	helper, err := c.createVolumeHelper(ctx, volumeName, true, opts.helperImage)
+	if err != nil {
+		return err
+	}
+	defer c.ContainerRemove(context.WithoutCancel(ctx), helper, removeoptions.Force())
+
+	rc, _, err := c.wrapped.CopyFromContainer(ctx, helper.Id, volumeHelperMount)
+	if err != nil {
+		return &errdefs.ContainerError{ID: helper.Id, Op: "backup volume " + volumeName, Message: err.Error(), Err: err}
+	}
+	defer rc.Close()
+
+	if onProgress := tracker.chain(opts.onProgress); onProgress != nil {
+		w = &progressWriter{w: w, onProgress: onProgress}
+	}
+	tw := tar.NewWriter(w)
+	if err := stripTarPrefix(tar.NewReader(rc), tw, path.Base(volumeHelperMount)); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+/*
+VolumeRestore extracts a tar archive, such as one written by VolumeBackup, into a volume. The volume is created
+when it does not exist. Existing files are overwritten, other files in the volume are kept.
+
+Usage example:
+
+	file, err := os.Open("pgdata.tar")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	err = client.VolumeRestore(ctx, "pgdata", file)
+*/
+func (c *Client) VolumeRestore(ctx context.Context, volumeName string, r io.Reader, volumeTransferOptionFns ...VolumeTransferOptionFn) (err error) {
+	opts := newVolumeTransferOptions(volumeTransferOptionFns)
+	if volumeName == "" {
+		return &errdefs.ValidationError{Field: "volumeName", Message: "volume name cannot be empty"}
+	}
+	tracker := newProgressTracker(opts.progress, ProgressOpVolumeRestore, volumeName, 0)
+	tracker.start()
+	defer func() {
+		tracker.finish(err)
+	}()
+
+	helper, err := c.createVolumeHelper(ctx, volumeName, false, opts.helperImage)
+	if err != nil {
+		return err
+	}
+	defer c.ContainerRemove(context.WithoutCancel(ctx), helper, removeoptions.Force())
+
+	if onProgress := tracker.chain(opts.onProgress); onProgress != nil {
+		r = &progressReader{r: r, onProgress: onProgress}
+	}
+	err = c.wrapped.CopyToContainer(ctx, helper.Id, volumeHelperMount, r, containerType.CopyToContainerOptions{
+		CopyUIDGID: true,
+	})
+	if err != nil {
+		return &errdefs.ContainerError{ID: helper.Id, Op: "restore volume " + volumeName, Message: err.Error(), Err: err}
+	}
+	return nil
+}
+
+// createVolumeHelper creates, but does not start, a container with the volume mounted at volumeHelperMount.
+func (c *Client) createVolumeHelper(ctx context.Context, volumeName string, readOnly bool, helperImageRef string) (*container.ContainerConfig, error) {
+	helperImage := image.NewConfig(helperImageRef)
+	if err := c.EnsureImage(ctx, helperImage, PullPolicyIfNotPresent); err != nil {
+		return nil, err
+	}
+	helper := container.NewConfig("godock-volume-" + GenerateRandomString(8))
+	helper.SetContainerOptions(
+		containeroptions.Image(helperImage),
+		containeroptions.Label("godock.helper", "volume"),
+	)
+	helper.SetHostOptions(
+		hostoptions.Mount(hostoptions.MountType("volume"), volumeName, volumeHelperMount, readOnly),
+		hostoptions.NetworkMode("none"),
+	)
+	if err := c.ContainerCreate(ctx, helper); err != nil {
+		return nil, err
+	}
+	return helper, nil
+}
+
+// stripTarPrefix copies the entries of tr below the top-level directory dir to tw, relative to dir.
+// The entry for dir itself is dropped.
+func stripTarPrefix(tr *tar.Reader, tw *tar.Writer, dir string) error {
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name, ok := strings.CutPrefix(strings.TrimPrefix(header.Name, "./"), dir+"/")
+		if !ok || name == "" {
+			continue
+		}
+		header.Name = name
+		if header.Typeflag == tar.TypeLink {
+			header.Linkname = strings.TrimPrefix(strings.TrimPrefix(header.Linkname, "./"), dir+"/")
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// progressWriter reports the running number of bytes written.
+type progressWriter struct {
+	w          io.Writer
+	n          int64
+	onProgress func(int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	if n > 0 {
+		p.n += int64(n)
+		p.onProgress(p.n)
+	}
+	return n, err
+}
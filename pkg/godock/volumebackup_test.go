@@ -0,0 +1,94 @@
+package godock
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/volume"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripTarPrefix(t *testing.T) {
+	var src bytes.Buffer
+	tw := tar.NewWriter(&src)
+	for _, h := range []*tar.Header{
+		{Name: "volume/", Typeflag: tar.TypeDir, Mode: 0o755},
+		{Name: "volume/data/", Typeflag: tar.TypeDir, Mode: 0o700},
+		{Name: "volume/data/db", Typeflag: tar.TypeReg, Mode: 0o600, Size: 2, Uid: 999},
+		{Name: "volume/data/db.link", Typeflag: tar.TypeLink, Linkname: "volume/data/db"},
+	} {
+		require.NoError(t, tw.WriteHeader(h))
+		if h.Size > 0 {
+			_, err := tw.Write([]byte("ok"))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+
+	var dst bytes.Buffer
+	out := tar.NewWriter(&dst)
+	require.NoError(t, stripTarPrefix(tar.NewReader(&src), out, "volume"))
+	require.NoError(t, out.Close())
+
+	var headers []*tar.Header
+	tr := tar.NewReader(&dst)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		headers = append(headers, header)
+	}
+	require.Len(t, headers, 3)
+	assert.Equal(t, "data/", headers[0].Name)
+	assert.Equal(t, "data/db", headers[1].Name)
+	assert.Equal(t, 999, headers[1].Uid)
+	assert.Equal(t, "data/db", headers[2].Linkname)
+}
+
+func TestVolumeBackupRestore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	client := setupTestClient(t)
+
+	src := volume.NewConfig("godock-backup-src")
+	require.NoError(t, client.VolumeCreate(ctx, src))
+	defer client.VolumeRemove(ctx, src.Options.Name, true)
+
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "hello.txt", Typeflag: tar.TypeReg, Mode: 0o644, Size: 5}))
+	_, err := tw.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, client.VolumeRestore(ctx, src.Options.Name, &archive))
+
+	var backup bytes.Buffer
+	var progress int64
+	require.NoError(t, client.VolumeBackup(ctx, src.Options.Name, &backup, WithVolumeProgress(func(n int64) {
+		progress = n
+	})))
+	assert.Equal(t, int64(backup.Len()), progress)
+
+	dst := "godock-backup-dst"
+	defer client.VolumeRemove(ctx, dst, true)
+	require.NoError(t, client.VolumeRestore(ctx, dst, &backup))
+
+	var restored bytes.Buffer
+	require.NoError(t, client.VolumeBackup(ctx, dst, &restored))
+	tr := tar.NewReader(&restored)
+	header, err := tr.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "hello.txt", header.Name)
+	data, err := io.ReadAll(tr)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+}
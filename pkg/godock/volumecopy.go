@@ -0,0 +1,124 @@
+package godock
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/hostoptions"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	volumeType "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+)
+
+/*
+VolumeCopy copies the contents of one volume into another, preserving ownership, permissions and timestamps.
+The destination volume is created when it does not exist; files already in it are kept unless the source
+has a file with the same path. The copy runs in a short-lived container that mounts both volumes, so the
+helper image must provide sh and cp.
+
+Usage example:
+
+	if err := client.VolumeCopy(ctx, "pgdata-golden", "pgdata-test"); err != nil {
+		return err
+	}
+*/
+func (c *Client) VolumeCopy(ctx context.Context, srcVolume, dstVolume string, volumeTransferOptionFns ...VolumeTransferOptionFn) error {
+	opts := newVolumeTransferOptions(volumeTransferOptionFns)
+	if srcVolume == "" || dstVolume == "" {
+		return &errdefs.ValidationError{Field: "volume", Message: "source and destination volume names cannot be empty"}
+	}
+	if srcVolume == dstVolume {
+		return &errdefs.ValidationError{Field: "dstVolume", Message: "source and destination volume are the same"}
+	}
+	if _, err := c.wrapped.VolumeInspect(ctx, srcVolume); err != nil {
+		if client.IsErrNotFound(err) {
+			return &errdefs.ResourceNotFoundError{ResourceType: "volume", ID: srcVolume, Err: err}
+		}
+		return err
+	}
+
+	helperImage := image.NewConfig(opts.helperImage)
+	if err := c.EnsureImage(ctx, helperImage, PullPolicyIfNotPresent); err != nil {
+		return err
+	}
+	helper := container.NewConfig("godock-volume-copy-" + GenerateRandomString(8))
+	helper.SetContainerOptions(
+		containeroptions.Image(helperImage),
+		containeroptions.Entrypoint("sh", "-c", "cp -a /from/. /to/"),
+		containeroptions.Label("godock.helper", "volume"),
+	)
+	helper.SetHostOptions(
+		hostoptions.Mount(hostoptions.MountType("volume"), srcVolume, "/from", true),
+		hostoptions.Mount(hostoptions.MountType("volume"), dstVolume, "/to", false),
+		hostoptions.NetworkMode("none"),
+	)
+
+	runErr := c.RunAndWait(ctx, helper)
+	if helper.Id != "" {
+		defer c.ContainerRemove(context.WithoutCancel(ctx), helper, removeoptions.Force())
+	}
+	if runErr == nil {
+		return nil
+	}
+	message := runErr.Error()
+	if helper.Id != "" {
+		if rc, err := c.ContainerLogs(context.WithoutCancel(ctx), helper); err == nil {
+			var out bytes.Buffer
+			NewLogCopier(&out, &out).Copy(rc)
+			rc.Close()
+			if out.Len() > 0 {
+				message += ": " + string(bytes.TrimSpace(out.Bytes()))
+			}
+		}
+	}
+	return &errdefs.VolumeError{Name: dstVolume, Op: "copy from " + srcVolume, Message: message}
+}
+
+/*
+VolumeClone creates a new volume with the driver, driver options and labels of an existing volume
+and copies its contents into it. It fails with an *errdefs.ResourceExistsError when a volume named
+newName already exists, and removes the new volume again when the copy fails.
+
+Usage example:
+
+	if err := client.VolumeClone(ctx, "pgdata-golden", "pgdata-"+t.Name()); err != nil {
+		t.Fatal(err)
+	}
+	defer client.VolumeRemove(ctx, "pgdata-"+t.Name(), true)
+*/
+func (c *Client) VolumeClone(ctx context.Context, src, newName string, volumeTransferOptionFns ...VolumeTransferOptionFn) error {
+	if newName == "" {
+		return &errdefs.ValidationError{Field: "newName", Message: "volume name cannot be empty"}
+	}
+	source, err := c.wrapped.VolumeInspect(ctx, src)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return &errdefs.ResourceNotFoundError{ResourceType: "volume", ID: src, Err: err}
+		}
+		return err
+	}
+	if _, err := c.wrapped.VolumeInspect(ctx, newName); err == nil {
+		return &errdefs.ResourceExistsError{ResourceType: "volume", ID: newName}
+	} else if !client.IsErrNotFound(err) {
+		return err
+	}
+
+	_, err = c.wrapped.VolumeCreate(ctx, volumeType.CreateOptions{
+		Name:       newName,
+		Driver:     source.Driver,
+		DriverOpts: source.Options,
+		Labels:     source.Labels,
+	})
+	if err != nil {
+		return &errdefs.VolumeError{Name: newName, Op: "create", Message: err.Error(), Err: err}
+	}
+	if err := c.VolumeCopy(ctx, src, newName, volumeTransferOptionFns...); err != nil {
+		c.wrapped.VolumeRemove(context.WithoutCancel(ctx), newName, true)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package godock
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/volume"
+	"github.com/aptd3v/godock/pkg/godock/volumeoptions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVolumeCopyValidation(t *testing.T) {
+	c := &Client{}
+	ctx := context.Background()
+
+	assert.True(t, errdefs.IsInvalidConfig(c.VolumeCopy(ctx, "", "dst")))
+	assert.True(t, errdefs.IsInvalidConfig(c.VolumeCopy(ctx, "data", "data")))
+	assert.True(t, errdefs.IsInvalidConfig(c.VolumeClone(ctx, "data", "")))
+}
+
+func TestVolumeCopyClone(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	client := setupTestClient(t)
+
+	golden := volume.NewConfig("godock-golden")
+	golden.SetOptions(volumeoptions.AddLabel("purpose", "golden"))
+	require.NoError(t, client.VolumeCreate(ctx, golden))
+	defer client.VolumeRemove(ctx, golden.Options.Name, true)
+
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "seed.sql", Typeflag: tar.TypeReg, Mode: 0o600, Size: 6, Uid: 999}))
+	_, err := tw.Write([]byte("SEED;\n"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, client.VolumeRestore(ctx, golden.Options.Name, &archive))
+
+	require.NoError(t, client.VolumeClone(ctx, golden.Options.Name, "godock-clone"))
+	defer client.VolumeRemove(ctx, "godock-clone", true)
+
+	err = client.VolumeClone(ctx, golden.Options.Name, "godock-clone")
+	assert.True(t, errdefs.IsAlreadyExists(err))
+
+	vols, err := client.VolumeList(ctx, WithVolumeFilter("name", "godock-clone"))
+	require.NoError(t, err)
+	require.Len(t, vols.Volumes, 1)
+	assert.Equal(t, "golden", vols.Volumes[0].Labels["purpose"])
+
+	var backup bytes.Buffer
+	require.NoError(t, client.VolumeBackup(ctx, "godock-clone", &backup))
+	tr := tar.NewReader(&backup)
+	header, err := tr.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "seed.sql", header.Name)
+	assert.Equal(t, 999, header.Uid)
+	data, err := io.ReadAll(tr)
+	require.NoError(t, err)
+	assert.Equal(t, "SEED;\n", string(data))
+}
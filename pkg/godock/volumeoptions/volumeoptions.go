@@ -1,6 +1,9 @@
 package volumeoptions
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/docker/docker/api/types/volume"
 )
 
@@ -91,6 +94,80 @@ func SetDriverOpts(opts map[string]string) SetVolumeOptFn {
 	}
 }
 
+// localMount configures the local driver to mount a filesystem, replacing any previous type, o and device options.
+func localMount(fsType, device string, mountOpts []string) SetVolumeOptFn {
+	return func(options *volume.CreateOptions) {
+		options.Driver = string(LocalDriver)
+		options.DriverOpts = map[string]string{
+			"type":   fsType,
+			"device": device,
+		}
+		var o []string
+		for _, opt := range mountOpts {
+			if opt = strings.TrimSpace(opt); opt != "" {
+				o = append(o, opt)
+			}
+		}
+		if len(o) > 0 {
+			options.DriverOpts["o"] = strings.Join(o, ",")
+		}
+	}
+}
+
+/*
+NFSMount configures the volume as an NFS share mounted by the local driver, so no volume plugin is needed.
+The server address is passed as the addr mount option and the export path is prefixed with ":" as the
+kernel expects. Additional mount options such as "nfsvers=4", "rw" or "nolock" are appended to o.
+The share is mounted when a container first uses the volume, so mount errors surface at container start.
+
+Usage example:
+
+	volume.SetOptions(
+		volumeoptions.NFSMount("10.0.0.10", "/exports/data", "nfsvers=4", "rw"),
+	)
+*/
+func NFSMount(server, exportPath string, mountOpts ...string) SetVolumeOptFn {
+	if !strings.HasPrefix(exportPath, "/") {
+		exportPath = "/" + exportPath
+	}
+	return localMount("nfs", ":"+exportPath, append([]string{"addr=" + server}, mountOpts...))
+}
+
+/*
+CIFSMount configures the volume as a CIFS/SMB share mounted by the local driver. The device is built as
+"//server/share" and the server is also passed as the addr mount option, since the kernel does not resolve
+host names itself. Credentials and other options are passed as mount options.
+
+Usage example:
+
+	volume.SetOptions(
+		volumeoptions.CIFSMount("fileserver.local", "media",
+			"username=svc", "password="+os.Getenv("SMB_PASSWORD"), "vers=3.0", "uid=1000",
+		),
+	)
+*/
+func CIFSMount(server, share string, mountOpts ...string) SetVolumeOptFn {
+	return localMount("cifs", "//"+server+"/"+strings.TrimPrefix(share, "/"), append([]string{"addr=" + server}, mountOpts...))
+}
+
+/*
+TmpfsLocal configures the volume as an in-memory tmpfs of at most sizeBytes, mounted by the local driver.
+Unlike a tmpfs mount on a container, the volume can be shared between containers; its contents are lost
+when the last container using it stops. A sizeBytes of 0 leaves the size to the kernel default of half the RAM.
+
+Usage example:
+
+	volume.SetOptions(
+		volumeoptions.TmpfsLocal(64*1024*1024, "mode=1777"),
+	)
+*/
+func TmpfsLocal(sizeBytes int64, mountOpts ...string) SetVolumeOptFn {
+	if sizeBytes > 0 {
+		mountOpts = append([]string{"size=" + strconv.FormatInt(sizeBytes, 10)}, mountOpts...)
+	}
+	return localMount("tmpfs", "tmpfs", mountOpts)
+}
+
 /*
 SetName sets the name of the Docker volume.
 
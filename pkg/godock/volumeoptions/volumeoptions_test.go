@@ -0,0 +1,50 @@
+package volumeoptions
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/volume"
+	"github.com/stretchr/testify/assert"
+)
+
+func apply(fns ...SetVolumeOptFn) *volume.CreateOptions {
+	options := &volume.CreateOptions{}
+	for _, fn := range fns {
+		fn(options)
+	}
+	return options
+}
+
+func TestNFSMount(t *testing.T) {
+	options := apply(NFSMount("10.0.0.10", "exports/data", "nfsvers=4", " rw "))
+	assert.Equal(t, "local", options.Driver)
+	assert.Equal(t, map[string]string{
+		"type":   "nfs",
+		"device": ":/exports/data",
+		"o":      "addr=10.0.0.10,nfsvers=4,rw",
+	}, options.DriverOpts)
+}
+
+func TestCIFSMount(t *testing.T) {
+	options := apply(CIFSMount("fileserver.local", "/media", "username=svc", "vers=3.0"))
+	assert.Equal(t, map[string]string{
+		"type":   "cifs",
+		"device": "//fileserver.local/media",
+		"o":      "addr=fileserver.local,username=svc,vers=3.0",
+	}, options.DriverOpts)
+}
+
+func TestTmpfsLocal(t *testing.T) {
+	options := apply(
+		AddDriverOpt("stale", "value"),
+		TmpfsLocal(64*1024*1024, "mode=1777"),
+	)
+	assert.Equal(t, map[string]string{
+		"type":   "tmpfs",
+		"device": "tmpfs",
+		"o":      "size=67108864,mode=1777",
+	}, options.DriverOpts)
+
+	options = apply(TmpfsLocal(0))
+	assert.NotContains(t, options.DriverOpts, "o")
+}
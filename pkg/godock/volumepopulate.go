@@ -0,0 +1,108 @@
+package godock
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+)
+
+/*
+VolumePopulate copies the files of fsys into a volume, creating the volume when it does not exist.
+Unlike bind mounts this works with remote daemons, so configuration files can be seeded into a volume
+before a container mounts it. File permissions are kept; files are owned by root unless
+WithVolumeOwner is used. Symbolic links to files are copied as regular files, symbolic links to
+directories are skipped.
+
+Usage example:
+
+	//go:embed nginx
+	var nginxConfig embed.FS
+
+	sub, _ := fs.Sub(nginxConfig, "nginx")
+	if err := client.VolumePopulate(ctx, "nginx-conf", sub); err != nil {
+		return err
+	}
+*/
+func (c *Client) VolumePopulate(ctx context.Context, volumeName string, fsys fs.FS, volumeTransferOptionFns ...VolumeTransferOptionFn) error {
+	if fsys == nil {
+		return &errdefs.ValidationError{Field: "fsys", Message: "file system cannot be nil"}
+	}
+	opts := newVolumeTransferOptions(volumeTransferOptionFns)
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeFSTar(fsys, pw, opts.uid, opts.gid))
+	}()
+	defer pr.Close()
+	return c.VolumeRestore(ctx, volumeName, pr, volumeTransferOptionFns...)
+}
+
+// VolumePopulateFromDir copies the contents of a directory on the client host into a volume.
+// See VolumePopulate.
+func (c *Client) VolumePopulateFromDir(ctx context.Context, volumeName, dir string, volumeTransferOptionFns ...VolumeTransferOptionFn) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return &errdefs.ValidationError{Field: "dir", Message: dir + " is not a directory"}
+	}
+	return c.VolumePopulate(ctx, volumeName, os.DirFS(dir), volumeTransferOptionFns...)
+}
+
+// writeFSTar writes the files of fsys to w as a tar archive, owned by uid and gid.
+func writeFSTar(fsys fs.FS, w io.Writer, uid, gid int) error {
+	tw := tar.NewWriter(w)
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == "." {
+			return nil
+		}
+		// Stat follows symbolic links, so linked files are copied by content.
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 && info.IsDir() {
+			return nil
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+
+		header := &tar.Header{
+			Name:    name,
+			Mode:    int64(info.Mode().Perm()),
+			ModTime: info.ModTime(),
+			Uid:     uid,
+			Gid:     gid,
+		}
+		if info.IsDir() {
+			header.Name += "/"
+			header.Typeflag = tar.TypeDir
+			return tw.WriteHeader(header)
+		}
+		header.Typeflag = tar.TypeReg
+		header.Size = info.Size()
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		f, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
@@ -0,0 +1,52 @@
+package godock
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFSTar(t *testing.T) {
+	fsys := fstest.MapFS{
+		"nginx.conf":           {Data: []byte("events {}\n"), Mode: 0o644},
+		"conf.d/default.conf":  {Data: []byte("server {}\n"), Mode: 0o600},
+		"certs/server.key":     {Data: []byte("key"), Mode: 0o400},
+		"certs":                {Mode: fs.ModeDir | 0o700},
+		"unused/.gitkeep":      {},
+		"conf.d/empty.include": {Mode: 0o644},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeFSTar(fsys, &buf, 101, 101))
+
+	headers := map[string]*tar.Header{}
+	contents := map[string]string{}
+	tr := tar.NewReader(&buf)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		headers[header.Name] = header
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		contents[header.Name] = string(data)
+	}
+
+	require.Contains(t, headers, "certs/")
+	assert.Equal(t, byte(tar.TypeDir), headers["certs/"].Typeflag)
+	assert.Equal(t, int64(0o700), headers["certs/"].Mode)
+	assert.Equal(t, int64(0o400), headers["certs/server.key"].Mode)
+	assert.Equal(t, 101, headers["nginx.conf"].Uid)
+	assert.Equal(t, 101, headers["nginx.conf"].Gid)
+	assert.Equal(t, "server {}\n", contents["conf.d/default.conf"])
+	assert.Equal(t, "", contents["conf.d/empty.include"])
+	assert.Contains(t, headers, "unused/.gitkeep")
+}
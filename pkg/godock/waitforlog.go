@@ -0,0 +1,130 @@
+package godock
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// maxLogLineSize is the longest log line WaitForLog can match.
+const maxLogLineSize = 1024 * 1024
+
+// logLineMatcher returns a function reporting whether a log line matches pattern, either as a
+// substring or as a regular expression. Patterns that do not compile are matched as substrings only.
+func logLineMatcher(pattern string) func(string) bool {
+	re, err := regexp.Compile(pattern)
+	return func(line string) bool {
+		if strings.Contains(line, pattern) {
+			return true
+		}
+		return err == nil && re.MatchString(line)
+	}
+}
+
+/*
+WaitForLog follows the logs of a container, from its first line, until a line of stdout or stderr
+matches pattern, and returns that line. pattern matches as a substring or as a regular expression.
+It is the readiness signal for images without a healthcheck.
+
+A timeout of 0 waits until ctx is done. WaitForLog returns errdefs.ErrTimeout when the timeout
+expires, and a ContainerError when the container exits before a line matches.
+
+Usage example:
+
+	if err := client.ContainerStart(ctx, db); err != nil {
+		return err
+	}
+	line, err := client.WaitForLog(ctx, db, "database system is ready to accept connections", time.Minute)
+	if err != nil {
+		return err
+	}
+	log.Println("postgres is ready:", line)
+*/
+func (c *Client) WaitForLog(ctx context.Context, containerConfig *container.ContainerConfig, pattern string, timeout time.Duration) (string, error) {
+	if containerConfig == nil || containerConfig.Id == "" {
+		return "", &errdefs.ValidationError{Field: "containerConfig", Message: "container config or id cannot be empty"}
+	}
+	if pattern == "" {
+		return "", &errdefs.ValidationError{Field: "pattern", Message: "pattern cannot be empty"}
+	}
+
+	inspect, err := c.wrapped.ContainerInspect(ctx, containerConfig.Id)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return "", &errdefs.ResourceNotFoundError{ResourceType: "container", ID: containerConfig.Id, Err: err}
+		}
+		return "", &errdefs.ContainerError{ID: containerConfig.Id, Op: "wait for log", Message: err.Error(), Err: err}
+	}
+
+	logCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	rc, err := c.wrapped.ContainerLogs(logCtx, containerConfig.Id, containerType.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+	})
+	if err != nil {
+		return "", &errdefs.ContainerError{ID: containerConfig.Id, Op: "wait for log", Message: err.Error(), Err: err}
+	}
+	defer rc.Close()
+
+	var logs io.Reader = rc
+	if inspect.Config == nil || !inspect.Config.Tty {
+		pr, pw := io.Pipe()
+		defer pr.Close()
+		go func() {
+			_, err := stdcopy.StdCopy(pw, pw, rc)
+			pw.CloseWithError(err)
+		}()
+		logs = pr
+	}
+
+	type result struct {
+		line string
+		err  error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		match := logLineMatcher(pattern)
+		scanner := bufio.NewScanner(logs)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineSize)
+		for scanner.Scan() {
+			line := strings.TrimRight(scanner.Text(), "\r")
+			if match(line) {
+				resultCh <- result{line: line}
+				return
+			}
+		}
+		err := scanner.Err()
+		if err == nil {
+			err = fmt.Errorf("container exited before a log line matched %q", pattern)
+		}
+		resultCh <- result{err: &errdefs.ContainerError{ID: containerConfig.Id, Op: "wait for log", Message: err.Error(), Err: err}}
+	}()
+
+	var timeoutCh <-chan time.Time
+	if timeout > 0 {
+		timeoutCh = c.Clock().After(timeout)
+	}
+	select {
+	case res := <-resultCh:
+		if res.err != nil && ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return res.line, res.err
+	case <-timeoutCh:
+		return "", errdefs.ErrTimeout
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
@@ -0,0 +1,68 @@
+package godock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogLineMatcher(t *testing.T) {
+	ready := logLineMatcher("ready to accept connections")
+	assert.True(t, ready("2024-06-01 LOG:  database system is ready to accept connections"))
+	assert.False(t, ready("database system is starting up"))
+
+	listening := logLineMatcher(`listening on .*:\d+`)
+	assert.True(t, listening("server listening on 0.0.0.0:8080"))
+	assert.False(t, listening("server listening on"))
+
+	// Invalid expressions still match as substrings.
+	bracket := logLineMatcher("[ready")
+	assert.True(t, bracket("status [ready]"))
+	assert.False(t, bracket("status ready"))
+}
+
+func TestWaitForLogValidation(t *testing.T) {
+	c := &Client{}
+	_, err := c.WaitForLog(context.Background(), nil, "ready", time.Second)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+
+	ctr := container.NewConfig("unstarted")
+	ctr.Id = "abc"
+	_, err = c.WaitForLog(context.Background(), ctr, "", time.Second)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
+
+func TestWaitForLog(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	client := setupTestClient(t)
+	busybox := image.NewConfig(DefaultDoctorProbeImage)
+	require.NoError(t, client.EnsureImage(ctx, busybox, PullPolicyIfNotPresent))
+
+	ctr := container.NewConfig("godock-waitforlog-" + GenerateRandomString(8))
+	ctr.SetContainerOptions(
+		containeroptions.Image(busybox),
+		containeroptions.CMD("sh", "-c", "echo starting; sleep 1; echo 'listening on port 8080' >&2; sleep 30"),
+	)
+	require.NoError(t, client.ContainerCreate(ctx, ctr))
+	defer client.ContainerRemove(context.WithoutCancel(ctx), ctr, removeoptions.Force())
+	require.NoError(t, client.ContainerStart(ctx, ctr))
+
+	line, err := client.WaitForLog(ctx, ctr, `listening on port \d+`, 20*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "listening on port 8080", line)
+
+	_, err = client.WaitForLog(ctx, ctr, "never printed", 2*time.Second)
+	assert.True(t, errdefs.IsTimeout(err))
+}
@@ -0,0 +1,193 @@
+package godock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+)
+
+// waitForPortInterval is the time between two connection attempts of WaitForPort.
+const waitForPortInterval = 250 * time.Millisecond
+
+// waitForPortDialTimeout bounds a single connection attempt of WaitForPort.
+const waitForPortDialTimeout = time.Second
+
+// portDialHost returns the host to dial for a port published on bindingIP by the daemon at daemonHost.
+// Ports published on all interfaces are reached through the daemon's host, or loopback for a local daemon.
+func portDialHost(daemonHost, bindingIP string) string {
+	if ip := net.ParseIP(bindingIP); ip != nil && !ip.IsUnspecified() {
+		return bindingIP
+	}
+	if u, err := url.Parse(daemonHost); err == nil && (u.Scheme == "tcp" || u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "ssh") {
+		if host := u.Hostname(); host != "" {
+			return host
+		}
+	}
+	return "127.0.0.1"
+}
+
+// dialReady reports whether address accepts TCP connections. Docker's userland proxy accepts connections on
+// a published port even before the container listens, and closes them at once, so a connection that is
+// closed right after it was accepted does not count.
+func dialReady(ctx context.Context, address string) bool {
+	dialer := net.Dialer{Timeout: waitForPortDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	_, err = conn.Read(make([]byte, 1))
+	if err == nil {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+/*
+WaitForPort waits until the container accepts TCP connections on containerPort. A published port is polled
+from the host, through the address it is mapped to; an unpublished port is checked from inside the
+container's network namespace, like CheckConnectivity.
+
+A timeout of 0 waits until ctx is done. WaitForPort returns errdefs.ErrTimeout when the timeout expires,
+and a ContainerError when the container stops running before the port is ready.
+
+Usage example:
+
+	if err := client.ContainerStart(ctx, cache); err != nil {
+		return err
+	}
+	if err := client.WaitForPort(ctx, cache, 6379, 30*time.Second); err != nil {
+		return err
+	}
+*/
+func (c *Client) WaitForPort(ctx context.Context, containerConfig *container.ContainerConfig, containerPort int, timeout time.Duration) error {
+	if containerConfig == nil || containerConfig.Id == "" {
+		return &errdefs.ValidationError{Field: "containerConfig", Message: "container config or id cannot be empty"}
+	}
+	if containerPort < 1 || containerPort > 65535 {
+		return &errdefs.ValidationError{Field: "containerPort", Message: fmt.Sprintf("port %d is out of range", containerPort)}
+	}
+	port, err := nat.NewPort("tcp", strconv.Itoa(containerPort))
+	if err != nil {
+		return &errdefs.ValidationError{Field: "containerPort", Message: err.Error()}
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(ctx)
+		defer cancel(nil)
+		timer := c.Clock().After(timeout)
+		go func() {
+			select {
+			case <-timer:
+				cancel(errdefs.ErrTimeout)
+			case <-ctx.Done():
+			}
+		}()
+	}
+	done := func() error {
+		if errors.Is(context.Cause(ctx), errdefs.ErrTimeout) {
+			return errdefs.ErrTimeout
+		}
+		return ctx.Err()
+	}
+
+	for {
+		inspect, err := c.wrapped.ContainerInspect(ctx, containerConfig.Id)
+		if err != nil {
+			if client.IsErrNotFound(err) {
+				return &errdefs.ResourceNotFoundError{ResourceType: "container", ID: containerConfig.Id, Err: err}
+			}
+			if ctx.Err() != nil {
+				return done()
+			}
+			return &errdefs.ContainerError{ID: containerConfig.Id, Op: "wait for port", Message: err.Error(), Err: err}
+		}
+		if inspect.State == nil || !inspect.State.Running {
+			return &errdefs.ContainerError{ID: containerConfig.Id, Op: "wait for port", Message: fmt.Sprintf("container is not running, port %d never became ready", containerPort)}
+		}
+
+		var bindings []nat.PortBinding
+		if inspect.NetworkSettings != nil {
+			bindings = inspect.NetworkSettings.Ports[port]
+		}
+		ready := false
+		for _, binding := range bindings {
+			if binding.HostPort == "" {
+				continue
+			}
+			address := net.JoinHostPort(portDialHost(c.wrapped.DaemonHost(), binding.HostIP), binding.HostPort)
+			if dialReady(ctx, address) {
+				ready = true
+				break
+			}
+		}
+		if len(bindings) == 0 {
+			res, err := c.CheckConnectivity(ctx, containerConfig, "127.0.0.1", containerPort, waitForPortDialTimeout)
+			if err != nil && ctx.Err() == nil {
+				return err
+			}
+			ready = err == nil && res.Reachable
+		}
+		if ready {
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return done()
+		}
+		if err := c.Clock().Sleep(ctx, waitForPortInterval); err != nil {
+			return done()
+		}
+	}
+}
+
+/*
+PortAddress returns the host:port address a published TCP port of the container is reachable at from this
+client. Ports published on all interfaces of a remote daemon are reached through the daemon's host.
+It returns a ResourceNotFoundError when the port is not published. It is served from the inspect cache when the
+client was created WithInspectCache.
+
+Usage example:
+
+	addr, err := client.PortAddress(ctx, db, 5432)
+	if err != nil {
+		return err
+	}
+	dsn := fmt.Sprintf("postgres://app:secret@%s/app?sslmode=disable", addr)
+*/
+func (c *Client) PortAddress(ctx context.Context, containerConfig *container.ContainerConfig, containerPort int) (string, error) {
+	if containerConfig == nil || containerConfig.Id == "" {
+		return "", &errdefs.ValidationError{Field: "containerConfig", Message: "container config or id cannot be empty"}
+	}
+	port, err := nat.NewPort("tcp", strconv.Itoa(containerPort))
+	if err != nil || containerPort < 1 || containerPort > 65535 {
+		return "", &errdefs.ValidationError{Field: "containerPort", Message: fmt.Sprintf("port %d is out of range", containerPort)}
+	}
+	inspect, err := c.inspectContainer(ctx, containerConfig.Id)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return "", &errdefs.ResourceNotFoundError{ResourceType: "container", ID: containerConfig.Id, Err: err}
+		}
+		return "", &errdefs.ContainerError{ID: containerConfig.Id, Op: "port address", Message: err.Error(), Err: err}
+	}
+	if inspect.NetworkSettings != nil {
+		for _, binding := range inspect.NetworkSettings.Ports[port] {
+			if binding.HostPort != "" {
+				return net.JoinHostPort(portDialHost(c.wrapped.DaemonHost(), binding.HostIP), binding.HostPort), nil
+			}
+		}
+	}
+	return "", &errdefs.ResourceNotFoundError{ResourceType: "published port", ID: string(port)}
+}
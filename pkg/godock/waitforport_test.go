@@ -0,0 +1,99 @@
+package godock
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/aptd3v/godock/pkg/godock/containeroptions"
+	"github.com/aptd3v/godock/pkg/godock/errdefs"
+	"github.com/aptd3v/godock/pkg/godock/image"
+	"github.com/aptd3v/godock/pkg/godock/removeoptions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortDialHost(t *testing.T) {
+	assert.Equal(t, "127.0.0.1", portDialHost("unix:///var/run/docker.sock", "0.0.0.0"))
+	assert.Equal(t, "127.0.0.1", portDialHost("npipe:////./pipe/docker_engine", "::"))
+	assert.Equal(t, "10.0.0.5", portDialHost("tcp://10.0.0.5:2376", ""))
+	assert.Equal(t, "docker.example.com", portDialHost("ssh://me@docker.example.com", "0.0.0.0"))
+	assert.Equal(t, "192.168.1.20", portDialHost("tcp://10.0.0.5:2376", "192.168.1.20"))
+}
+
+func TestDialReady(t *testing.T) {
+	ctx := context.Background()
+
+	listening, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listening.Close()
+	go func() {
+		for {
+			conn, err := listening.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	assert.True(t, dialReady(ctx, listening.Addr().String()))
+
+	// Like the userland proxy in front of a container that does not listen yet.
+	closing, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer closing.Close()
+	go func() {
+		for {
+			conn, err := closing.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	assert.False(t, dialReady(ctx, closing.Addr().String()))
+
+	addr := closing.Addr().String()
+	closing.Close()
+	assert.False(t, dialReady(ctx, addr))
+}
+
+func TestWaitForPortValidation(t *testing.T) {
+	c := &Client{}
+	assert.True(t, errdefs.IsInvalidConfig(c.WaitForPort(context.Background(), nil, 80, time.Second)))
+
+	ctr := container.NewConfig("unstarted")
+	ctr.Id = "abc"
+	assert.True(t, errdefs.IsInvalidConfig(c.WaitForPort(context.Background(), ctr, 0, time.Second)))
+	assert.True(t, errdefs.IsInvalidConfig(c.WaitForPort(context.Background(), ctr, 70000, time.Second)))
+
+	_, err := c.PortAddress(context.Background(), nil, 80)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+	_, err = c.PortAddress(context.Background(), ctr, -1)
+	assert.True(t, errdefs.IsInvalidConfig(err))
+}
+
+func TestWaitForPort(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	client := setupTestClient(t)
+	busybox := image.NewConfig(DefaultDoctorProbeImage)
+	require.NoError(t, client.EnsureImage(ctx, busybox, PullPolicyIfNotPresent))
+
+	ctr := container.NewConfig("godock-waitforport-" + GenerateRandomString(8))
+	ctr.SetContainerOptions(
+		containeroptions.Image(busybox),
+		containeroptions.CMD("sh", "-c", "sleep 2; while true; do nc -l -p 8080 </dev/null; done"),
+	)
+	require.NoError(t, client.ContainerCreate(ctx, ctr))
+	defer client.ContainerRemove(context.WithoutCancel(ctx), ctr, removeoptions.Force())
+	require.NoError(t, client.ContainerStart(ctx, ctr))
+
+	require.NoError(t, client.WaitForPort(ctx, ctr, 8080, 30*time.Second))
+	assert.True(t, errdefs.IsTimeout(client.WaitForPort(ctx, ctr, 9090, 2*time.Second)))
+}
@@ -0,0 +1,296 @@
+package watchdog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/aptd3v/godock/pkg/godock/container"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+)
+
+// Policy is the action taken when a container created outside godock is detected.
+type Policy string
+
+const (
+	// PolicyAlert only reports the container to the violation handler.
+	PolicyAlert Policy = "alert"
+	// PolicyLabel records the container as unmanaged in the watchdog, see Watchdog.Labeled.
+	PolicyLabel Policy = "label"
+	// PolicyStop stops the container whenever it is started.
+	PolicyStop Policy = "stop"
+)
+
+// Violation describes a container that was not created through godock.
+type Violation struct {
+	ContainerID string
+	Name        string
+	Image       string
+	Labels      map[string]string
+	// Action is the policy that was applied.
+	Action Policy
+	// Err holds the error returned while applying the policy, if any.
+	Err error
+}
+
+// Watchdog watches a docker host for containers created outside godock and applies a policy to them,
+// for hosts that are meant to be managed exclusively by a godock based agent.
+// Docker labels are immutable, so PolicyLabel labels unmanaged containers in the watchdog's own state
+// rather than on the containers.
+type Watchdog struct {
+	client      *godock.Client
+	policy      Policy
+	onViolation func(ctx context.Context, violation Violation)
+	allowLabels map[string]string
+	allowNames  map[string]struct{}
+
+	mu sync.Mutex
+	// seen holds the unmanaged containers the policy was applied to, so they are reported once.
+	seen map[string]bool
+	// labeled holds the containers labeled by PolicyLabel.
+	labeled map[string]Violation
+
+	// Daemon operations, replaced in tests.
+	list   func(ctx context.Context) ([]types.Container, error)
+	events func(ctx context.Context) (<-chan events.Message, <-chan error)
+	stop   func(ctx context.Context, id string) error
+}
+
+// SetWatchdogOptFn is a function type that configures a Watchdog.
+type SetWatchdogOptFn func(w *Watchdog)
+
+// New creates a Watchdog that alerts on unmanaged containers by default.
+func New(client *godock.Client, setOptFns ...SetWatchdogOptFn) *Watchdog {
+	w := &Watchdog{
+		client:      client,
+		policy:      PolicyAlert,
+		allowLabels: map[string]string{godock.ManagedLabel: ""},
+		allowNames:  make(map[string]struct{}),
+		seen:        make(map[string]bool),
+		labeled:     make(map[string]Violation),
+	}
+	w.list = func(ctx context.Context) ([]types.Container, error) {
+		return w.client.ContainerList(ctx, godock.WithContainerAll(true))
+	}
+	w.events = func(ctx context.Context) (<-chan events.Message, <-chan error) {
+		return w.client.Events(ctx,
+			godock.WithEventFilter("type", string(events.ContainerEventType)),
+			godock.WithEventFilter("event", string(events.ActionCreate)),
+			godock.WithEventFilter("event", string(events.ActionStart)),
+			godock.WithEventFilter("event", string(events.ActionDestroy)),
+		)
+	}
+	w.stop = func(ctx context.Context, id string) error {
+		return w.client.ContainerStop(ctx, &container.ContainerConfig{Id: id})
+	}
+	for _, set := range setOptFns {
+		if set != nil {
+			set(w)
+		}
+	}
+	return w
+}
+
+/*
+WithPolicy sets the action taken on unmanaged containers.
+
+Usage example:
+
+	dog := watchdog.New(client,
+		watchdog.WithPolicy(watchdog.PolicyStop),
+	)
+*/
+func WithPolicy(policy Policy) SetWatchdogOptFn {
+	return func(w *Watchdog) {
+		w.policy = policy
+	}
+}
+
+/*
+OnViolation sets the handler called for every unmanaged container, after the policy has been applied.
+
+Usage example:
+
+	dog := watchdog.New(client,
+		watchdog.OnViolation(func(ctx context.Context, v watchdog.Violation) {
+			log.Printf("unmanaged container %s (%s): %s", v.Name, v.Image, v.Action)
+		}),
+	)
+*/
+func OnViolation(handler func(ctx context.Context, violation Violation)) SetWatchdogOptFn {
+	return func(w *Watchdog) {
+		w.onViolation = handler
+	}
+}
+
+/*
+AllowLabel treats containers carrying the label as managed. An empty value matches any value.
+Containers created through godock carry godock.ManagedLabel and are always allowed.
+
+Usage example:
+
+	dog := watchdog.New(client,
+		watchdog.AllowLabel("com.docker.compose.project", ""),
+	)
+*/
+func AllowLabel(key, value string) SetWatchdogOptFn {
+	return func(w *Watchdog) {
+		w.allowLabels[key] = value
+	}
+}
+
+/*
+AllowNames treats containers with the given names as managed.
+
+Usage example:
+
+	dog := watchdog.New(client,
+		watchdog.AllowNames("portainer", "watchtower"),
+	)
+*/
+func AllowNames(names ...string) SetWatchdogOptFn {
+	return func(w *Watchdog) {
+		for _, name := range names {
+			w.allowNames[strings.TrimPrefix(name, "/")] = struct{}{}
+		}
+	}
+}
+
+// Managed reports whether a container with the given name and labels is considered managed.
+func (w *Watchdog) Managed(name string, labels map[string]string) bool {
+	if _, ok := w.allowNames[strings.TrimPrefix(name, "/")]; ok {
+		return true
+	}
+	for key, value := range w.allowLabels {
+		if got, ok := labels[key]; ok && (value == "" || got == value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Labeled returns the containers labeled as unmanaged by PolicyLabel that still exist, sorted by name.
+func (w *Watchdog) Labeled() []Violation {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	labeled := make([]Violation, 0, len(w.labeled))
+	for _, violation := range w.labeled {
+		labeled = append(labeled, violation)
+	}
+	sort.Slice(labeled, func(i, j int) bool { return labeled[i].Name < labeled[j].Name })
+	return labeled
+}
+
+// Scan applies the policy to every existing container that is not managed and returns the violations found.
+// PolicyStop only applies to running containers.
+func (w *Watchdog) Scan(ctx context.Context) ([]Violation, error) {
+	containers, err := w.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []Violation
+	for _, c := range containers {
+		name := ""
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		if w.Managed(name, c.Labels) || (w.policy == PolicyStop && c.State != "running") {
+			continue
+		}
+		violations = append(violations, w.enforce(ctx, Violation{
+			ContainerID: c.ID,
+			Name:        name,
+			Image:       c.Image,
+			Labels:      c.Labels,
+		}))
+	}
+	return violations, nil
+}
+
+/*
+Run scans the existing containers and then watches container events, applying the policy to every unmanaged
+container. PolicyStop is applied whenever an unmanaged container starts, the other policies once per container
+when it is created. Run blocks until the context is cancelled or the event stream fails.
+*/
+func (w *Watchdog) Run(ctx context.Context) error {
+	// Subscribe first so containers created during the scan are not missed.
+	msgCh, errCh := w.events(ctx)
+	if _, err := w.Scan(ctx); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errCh:
+			if errors.Is(err, context.Canceled) {
+				return ctx.Err()
+			}
+			return fmt.Errorf("watchdog event stream failed: %w", err)
+		case msg := <-msgCh:
+			w.handle(ctx, msg)
+		}
+	}
+}
+
+// handle applies the policy for a container event.
+func (w *Watchdog) handle(ctx context.Context, msg events.Message) {
+	if msg.Action == events.ActionDestroy {
+		w.mu.Lock()
+		delete(w.seen, msg.Actor.ID)
+		delete(w.labeled, msg.Actor.ID)
+		w.mu.Unlock()
+		return
+	}
+	name := msg.Actor.Attributes["name"]
+	if w.Managed(name, msg.Actor.Attributes) {
+		return
+	}
+	if w.policy == PolicyStop {
+		// A created container is not running yet, so it is stopped once it starts.
+		if msg.Action != events.ActionStart {
+			return
+		}
+	} else {
+		// Containers are reported when they are created, or when they start if the creation was missed.
+		w.mu.Lock()
+		seen := w.seen[msg.Actor.ID]
+		w.mu.Unlock()
+		if seen {
+			return
+		}
+	}
+	w.enforce(ctx, Violation{
+		ContainerID: msg.Actor.ID,
+		Name:        name,
+		Image:       msg.Actor.Attributes["image"],
+		Labels:      msg.Actor.Attributes,
+	})
+}
+
+// enforce applies the policy to an unmanaged container and reports the violation.
+func (w *Watchdog) enforce(ctx context.Context, violation Violation) Violation {
+	violation.Action = w.policy
+	switch w.policy {
+	case PolicyStop:
+		violation.Err = w.stop(ctx, violation.ContainerID)
+	case PolicyLabel:
+		w.mu.Lock()
+		w.labeled[violation.ContainerID] = violation
+		w.mu.Unlock()
+	}
+	w.mu.Lock()
+	w.seen[violation.ContainerID] = true
+	w.mu.Unlock()
+	if w.onViolation != nil {
+		w.onViolation(ctx, violation)
+	}
+	return violation
+}
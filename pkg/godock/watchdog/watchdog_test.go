@@ -0,0 +1,155 @@
+package watchdog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aptd3v/godock/pkg/godock"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManaged(t *testing.T) {
+	w := New(nil,
+		WithPolicy(PolicyStop),
+		AllowLabel("com.docker.compose.project", ""),
+		AllowLabel("team", "platform"),
+		AllowNames("/portainer"),
+	)
+
+	assert.Equal(t, PolicyStop, w.policy)
+	assert.True(t, w.Managed("app", map[string]string{godock.ManagedLabel: "true"}))
+	assert.True(t, w.Managed("app", map[string]string{"com.docker.compose.project": "shop"}))
+	assert.True(t, w.Managed("app", map[string]string{"team": "platform"}))
+	assert.False(t, w.Managed("app", map[string]string{"team": "data"}))
+	assert.True(t, w.Managed("portainer", nil))
+	assert.False(t, w.Managed("rogue", nil))
+}
+
+// newTestWatchdog returns a Watchdog whose daemon operations are faked, recording the stopped containers.
+func newTestWatchdog(policy Policy, containers []types.Container) (*Watchdog, chan events.Message, *[]string) {
+	var stopped []string
+	msgCh := make(chan events.Message)
+	w := New(nil, WithPolicy(policy))
+	w.list = func(ctx context.Context) ([]types.Container, error) {
+		return containers, nil
+	}
+	w.events = func(ctx context.Context) (<-chan events.Message, <-chan error) {
+		return msgCh, make(chan error)
+	}
+	w.stop = func(ctx context.Context, id string) error {
+		stopped = append(stopped, id)
+		return nil
+	}
+	return w, msgCh, &stopped
+}
+
+func TestEnforce(t *testing.T) {
+	ctx := context.Background()
+	violation := Violation{ContainerID: "abc", Name: "rogue", Image: "nginx"}
+
+	t.Run("Alert", func(t *testing.T) {
+		var reported []Violation
+		w, _, stopped := newTestWatchdog(PolicyAlert, nil)
+		w.onViolation = func(ctx context.Context, v Violation) { reported = append(reported, v) }
+
+		got := w.enforce(ctx, violation)
+		assert.Equal(t, PolicyAlert, got.Action)
+		assert.Equal(t, []Violation{got}, reported)
+		assert.Empty(t, *stopped)
+		assert.Empty(t, w.Labeled())
+	})
+
+	t.Run("Label", func(t *testing.T) {
+		w, _, stopped := newTestWatchdog(PolicyLabel, nil)
+
+		got := w.enforce(ctx, violation)
+		assert.Equal(t, PolicyLabel, got.Action)
+		assert.Equal(t, []Violation{got}, w.Labeled())
+		assert.Empty(t, *stopped)
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		w, _, stopped := newTestWatchdog(PolicyStop, nil)
+		w.stop = func(ctx context.Context, id string) error {
+			*stopped = append(*stopped, id)
+			return errors.New("no such container")
+		}
+
+		got := w.enforce(ctx, violation)
+		assert.Equal(t, PolicyStop, got.Action)
+		assert.EqualError(t, got.Err, "no such container")
+		assert.Equal(t, []string{"abc"}, *stopped)
+	})
+}
+
+func TestScan(t *testing.T) {
+	containers := []types.Container{
+		{ID: "managed", Names: []string{"/app"}, State: "running", Labels: map[string]string{godock.ManagedLabel: "true"}},
+		{ID: "running", Names: []string{"/rogue"}, State: "running"},
+		{ID: "created", Names: []string{"/idle"}, State: "created"},
+	}
+
+	w, _, stopped := newTestWatchdog(PolicyStop, containers)
+	violations, err := w.Scan(context.Background())
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "rogue", violations[0].Name)
+	assert.Equal(t, []string{"running"}, *stopped)
+
+	w, _, _ = newTestWatchdog(PolicyLabel, containers)
+	violations, err = w.Scan(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, violations, 2)
+}
+
+func TestRun(t *testing.T) {
+	event := func(action events.Action, id, name string, labels map[string]string) events.Message {
+		attributes := map[string]string{"name": name, "image": "nginx"}
+		for k, v := range labels {
+			attributes[k] = v
+		}
+		return events.Message{Type: events.ContainerEventType, Action: action, Actor: events.Actor{ID: id, Attributes: attributes}}
+	}
+	run := func(t *testing.T, w *Watchdog, msgCh chan events.Message, msgs ...events.Message) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- w.Run(ctx) }()
+		for _, msg := range msgs {
+			msgCh <- msg
+		}
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+	}
+
+	t.Run("Stop on start", func(t *testing.T) {
+		w, msgCh, stopped := newTestWatchdog(PolicyStop, nil)
+		run(t, w, msgCh,
+			event(events.ActionCreate, "abc", "rogue", nil),
+			event(events.ActionCreate, "def", "app", map[string]string{godock.ManagedLabel: "true"}),
+			event(events.ActionStart, "def", "app", map[string]string{godock.ManagedLabel: "true"}),
+			event(events.ActionStart, "abc", "rogue", nil),
+			event(events.ActionStart, "abc", "rogue", nil),
+		)
+		assert.Equal(t, []string{"abc", "abc"}, *stopped)
+	})
+
+	t.Run("Label once until destroyed", func(t *testing.T) {
+		var reported []string
+		w, msgCh, _ := newTestWatchdog(PolicyLabel, nil)
+		w.onViolation = func(ctx context.Context, v Violation) { reported = append(reported, v.ContainerID) }
+		run(t, w, msgCh,
+			event(events.ActionCreate, "abc", "rogue", nil),
+			event(events.ActionStart, "abc", "rogue", nil),
+			event(events.ActionStart, "def", "missed", nil),
+			event(events.ActionDestroy, "abc", "rogue", nil),
+		)
+		assert.Equal(t, []string{"abc", "def"}, reported)
+		labeled := w.Labeled()
+		require.Len(t, labeled, 1)
+		assert.Equal(t, "missed", labeled[0].Name)
+	})
+}